@@ -17,6 +17,7 @@ export interface FeatureToggles {
   [name: string]: boolean | undefined; // support any string value
 
   trimDefaults?: boolean;
+  dashboardSchemaValidation?: boolean;
   envelopeEncryption?: boolean;
   httpclientprovider_azure_auth?: boolean;
   ['service-accounts']?: boolean;
@@ -40,5 +41,6 @@ export interface FeatureToggles {
   disable_http_request_histogram?: boolean;
   validatedQueries?: boolean;
   swaggerUi?: boolean;
+  scheduledReports?: boolean;
   featureHighlights?: boolean;
 }
@@ -5,6 +5,9 @@ import (
 	"net/http"
 	"strconv"
 	"strings"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend/gtime"
 
 	"github.com/grafana/grafana/pkg/api/dtos"
 	"github.com/grafana/grafana/pkg/api/response"
@@ -16,24 +19,18 @@ import (
 )
 
 func GetAnnotations(c *models.ReqContext) response.Response {
-	query := &annotations.ItemQuery{
-		From:        c.QueryInt64("from"),
-		To:          c.QueryInt64("to"),
-		OrgId:       c.OrgId,
-		UserId:      c.QueryInt64("userId"),
-		AlertId:     c.QueryInt64("alertId"),
-		DashboardId: c.QueryInt64("dashboardId"),
-		PanelId:     c.QueryInt64("panelId"),
-		Limit:       c.QueryInt64("limit"),
-		Tags:        c.QueryStrings("tags"),
-		Type:        c.Query("type"),
-		MatchAny:    c.QueryBool("matchAny"),
+	query, err := annotationItemQuery(c)
+	if err != nil {
+		return response.Error(http.StatusBadRequest, err.Error(), nil)
 	}
 
 	repo := annotations.GetRepository()
 
-	items, err := repo.Find(query)
+	items, err := repo.Find(c.Req.Context(), query)
 	if err != nil {
+		if errors.Is(err, annotations.ErrInvalidMatcher) || errors.Is(err, annotations.ErrInvalidCursor) {
+			return response.Error(http.StatusBadRequest, err.Error(), nil)
+		}
 		return response.Error(500, "Failed to get annotations", err)
 	}
 
@@ -43,7 +40,46 @@ func GetAnnotations(c *models.ReqContext) response.Response {
 		}
 	}
 
-	return response.JSON(200, items)
+	resp := response.JSON(200, items)
+	if cursor := annotations.NextCursor(items, query.Limit); cursor != "" {
+		resp.SetHeader(nextCursorHeader, cursor)
+	}
+	return resp
+}
+
+// nextCursorHeader is the header of annotation responses with the cursor of
+// the next page.
+const nextCursorHeader = "X-Grafana-Next-Cursor"
+
+// annotationItemQuery returns the query of annotations filtered by the query
+// parameters of the request.
+func annotationItemQuery(c *models.ReqContext) (*annotations.ItemQuery, error) {
+	query := &annotations.ItemQuery{
+		From:         c.QueryInt64("from"),
+		To:           c.QueryInt64("to"),
+		OrgId:        c.OrgId,
+		UserId:       c.QueryInt64("userId"),
+		AlertId:      c.QueryInt64("alertId"),
+		DashboardId:  c.QueryInt64("dashboardId"),
+		DashboardUid: c.Query("dashboardUID"),
+		PanelId:      c.QueryInt64("panelId"),
+		Limit:        c.QueryInt64("limit"),
+		Tags:         c.QueryStrings("tags"),
+		Type:         c.Query("type"),
+		MatchAny:     c.QueryBool("matchAny"),
+		Text:         c.Query("text"),
+		Cursor:       c.Query("cursor"),
+	}
+
+	for _, s := range c.QueryStrings("matcher") {
+		m, err := annotations.ParseTagMatcher(s)
+		if err != nil {
+			return nil, err
+		}
+		query.Matchers = append(query.Matchers, m)
+	}
+
+	return query, nil
 }
 
 type CreateAnnotationError struct {
@@ -82,7 +118,7 @@ func PostAnnotation(c *models.ReqContext) response.Response {
 		Tags:        cmd.Tags,
 	}
 
-	if err := repo.Save(&item); err != nil {
+	if err := repo.Save(c.Req.Context(), &item); err != nil {
 		if errors.Is(err, annotations.ErrTimerangeMissing) {
 			return response.Error(400, "Failed to save annotation", err)
 		}
@@ -150,7 +186,7 @@ func PostGraphiteAnnotation(c *models.ReqContext) response.Response {
 		Tags:   tagsArray,
 	}
 
-	if err := repo.Save(&item); err != nil {
+	if err := repo.Save(c.Req.Context(), &item); err != nil {
 		return response.Error(500, "Failed to save Graphite annotation", err)
 	}
 
@@ -187,7 +223,7 @@ func UpdateAnnotation(c *models.ReqContext) response.Response {
 		Tags:     cmd.Tags,
 	}
 
-	if err := repo.Update(&item); err != nil {
+	if err := repo.Update(c.Req.Context(), &item); err != nil {
 		return response.Error(500, "Failed to update annotation", err)
 	}
 
@@ -210,7 +246,7 @@ func PatchAnnotation(c *models.ReqContext) response.Response {
 		return resp
 	}
 
-	items, err := repo.Find(&annotations.ItemQuery{AnnotationId: annotationID, OrgId: c.OrgId})
+	items, err := repo.Find(c.Req.Context(), &annotations.ItemQuery{AnnotationId: annotationID, OrgId: c.OrgId})
 
 	if err != nil || len(items) == 0 {
 		return response.Error(404, "Could not find annotation to update", err)
@@ -242,7 +278,7 @@ func PatchAnnotation(c *models.ReqContext) response.Response {
 		existing.EpochEnd = cmd.TimeEnd
 	}
 
-	if err := repo.Update(&existing); err != nil {
+	if err := repo.Update(c.Req.Context(), &existing); err != nil {
 		return response.Error(500, "Failed to update annotation", err)
 	}
 
@@ -256,7 +292,7 @@ func DeleteAnnotations(c *models.ReqContext) response.Response {
 	}
 	repo := annotations.GetRepository()
 
-	err := repo.Delete(&annotations.DeleteParams{
+	err := repo.Delete(c.Req.Context(), &annotations.DeleteParams{
 		OrgId:       c.OrgId,
 		Id:          cmd.AnnotationId,
 		DashboardId: cmd.DashboardId,
@@ -281,7 +317,7 @@ func DeleteAnnotationByID(c *models.ReqContext) response.Response {
 		return resp
 	}
 
-	err = repo.Delete(&annotations.DeleteParams{
+	err = repo.Delete(c.Req.Context(), &annotations.DeleteParams{
 		OrgId: c.OrgId,
 		Id:    annotationID,
 	})
@@ -308,7 +344,7 @@ func canSaveByDashboardID(c *models.ReqContext, dashboardID int64) (bool, error)
 }
 
 func canSave(c *models.ReqContext, repo annotations.Repository, annotationID int64) response.Response {
-	items, err := repo.Find(&annotations.ItemQuery{AnnotationId: annotationID, OrgId: c.OrgId})
+	items, err := repo.Find(c.Req.Context(), &annotations.ItemQuery{AnnotationId: annotationID, OrgId: c.OrgId})
 	if err != nil || len(items) == 0 {
 		return response.Error(500, "Could not find annotation to update", err)
 	}
@@ -330,10 +366,40 @@ func GetAnnotationTags(c *models.ReqContext) response.Response {
 	}
 
 	repo := annotations.GetRepository()
-	result, err := repo.FindTags(query)
+	result, err := repo.FindTags(c.Req.Context(), query)
 	if err != nil {
 		return response.Error(500, "Failed to find annotation tags", err)
 	}
 
 	return response.JSON(200, annotations.GetAnnotationTagsResponse{Result: result})
 }
+
+func GetAnnotationTagCounts(c *models.ReqContext) response.Response {
+	itemQuery, err := annotationItemQuery(c)
+	if err != nil {
+		return response.Error(http.StatusBadRequest, err.Error(), nil)
+	}
+	// The limit applies to the counts.
+	itemQuery.Limit = c.QueryInt64("limit")
+
+	interval, err := gtime.ParseDuration(c.Query("interval"))
+	if err != nil || interval < time.Millisecond {
+		return response.Error(http.StatusBadRequest, "interval is invalid", err)
+	}
+
+	query := &annotations.TagCountsQuery{
+		ItemQuery: *itemQuery,
+		Interval:  interval.Milliseconds(),
+	}
+
+	repo := annotations.GetRepository()
+	result, err := repo.CountTags(c.Req.Context(), query)
+	if err != nil {
+		if errors.Is(err, annotations.ErrInvalidMatcher) || errors.Is(err, annotations.ErrInvalidCursor) {
+			return response.Error(http.StatusBadRequest, err.Error(), nil)
+		}
+		return response.Error(500, "Failed to count annotation tags", err)
+	}
+
+	return response.JSON(200, annotations.GetAnnotationTagCountsResponse{Result: result})
+}
@@ -245,29 +245,61 @@ func TestAnnotationsAPIEndpoint(t *testing.T) {
 	})
 }
 
+func TestGetAnnotations(t *testing.T) {
+	loggedInUserScenario(t, "When calling GET on", "/api/annotations", "/api/annotations", func(sc *scenarioContext) {
+		annotations.SetRepository(&fakeAnnotationsRepo{})
+		sc.handlerFunc = GetAnnotations
+
+		sc.fakeReqWithParams("GET", sc.url, map[string]string{"limit": "1"}).exec()
+		assert.Equal(t, 200, sc.resp.Code)
+		assert.NotEmpty(t, sc.resp.Header().Get(nextCursorHeader), "a full page has a next cursor")
+
+		sc.fakeReqWithParams("GET", sc.url, map[string]string{"limit": "2"}).exec()
+		assert.Equal(t, 200, sc.resp.Code)
+		assert.Empty(t, sc.resp.Header().Get(nextCursorHeader))
+
+		sc.fakeReqWithParams("GET", sc.url, map[string]string{"matcher": "env=~(prod"}).exec()
+		assert.Equal(t, 400, sc.resp.Code)
+	}, mockstore.NewSQLStoreMock())
+
+	loggedInUserScenario(t, "When calling GET on", "/api/annotations/tags/counts", "/api/annotations/tags/counts", func(sc *scenarioContext) {
+		annotations.SetRepository(&fakeAnnotationsRepo{})
+		sc.handlerFunc = GetAnnotationTagCounts
+
+		sc.fakeReqWithParams("GET", sc.url, map[string]string{}).exec()
+		assert.Equal(t, 400, sc.resp.Code, "interval is required")
+
+		sc.fakeReqWithParams("GET", sc.url, map[string]string{"interval": "1h", "matcher": "env=prod"}).exec()
+		assert.Equal(t, 200, sc.resp.Code)
+	}, mockstore.NewSQLStoreMock())
+}
+
 type fakeAnnotationsRepo struct {
 }
 
-func (repo *fakeAnnotationsRepo) Delete(params *annotations.DeleteParams) error {
+func (repo *fakeAnnotationsRepo) Delete(_ context.Context, params *annotations.DeleteParams) error {
 	return nil
 }
-func (repo *fakeAnnotationsRepo) Save(item *annotations.Item) error {
+func (repo *fakeAnnotationsRepo) Save(_ context.Context, item *annotations.Item) error {
 	item.Id = 1
 	return nil
 }
-func (repo *fakeAnnotationsRepo) Update(item *annotations.Item) error {
+func (repo *fakeAnnotationsRepo) Update(_ context.Context, item *annotations.Item) error {
 	return nil
 }
-func (repo *fakeAnnotationsRepo) Find(query *annotations.ItemQuery) ([]*annotations.ItemDTO, error) {
+func (repo *fakeAnnotationsRepo) Find(_ context.Context, query *annotations.ItemQuery) ([]*annotations.ItemDTO, error) {
 	annotations := []*annotations.ItemDTO{{Id: 1}}
 	return annotations, nil
 }
-func (repo *fakeAnnotationsRepo) FindTags(query *annotations.TagsQuery) (annotations.FindTagsResult, error) {
+func (repo *fakeAnnotationsRepo) FindTags(_ context.Context, query *annotations.TagsQuery) (annotations.FindTagsResult, error) {
 	result := annotations.FindTagsResult{
 		Tags: []*annotations.TagsDTO{},
 	}
 	return result, nil
 }
+func (repo *fakeAnnotationsRepo) CountTags(_ context.Context, query *annotations.TagCountsQuery) (annotations.TagCountsResult, error) {
+	return annotations.TagCountsResult{Counts: []*annotations.TagCountDTO{}}, nil
+}
 
 var fakeAnnoRepo *fakeAnnotationsRepo
 
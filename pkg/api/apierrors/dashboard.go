@@ -10,6 +10,7 @@ import (
 	"github.com/grafana/grafana/pkg/models"
 	"github.com/grafana/grafana/pkg/plugins"
 	"github.com/grafana/grafana/pkg/services/alerting"
+	"github.com/grafana/grafana/pkg/services/schemaloader"
 	"github.com/grafana/grafana/pkg/util"
 )
 
@@ -35,6 +36,16 @@ func ToDashboardErrorResponse(ctx context.Context, pluginStore plugins.Store, er
 		return response.Error(http.StatusUnprocessableEntity, validationErr.Error(), err)
 	}
 
+	var schemaErr *schemaloader.ValidationError
+	if ok := errors.As(err, &schemaErr); ok {
+		return response.JSON(http.StatusBadRequest, util.DynMap{
+			"status":  "invalid-schema",
+			"message": schemaErr.Error(),
+			"version": schemaErr.Version,
+			"errors":  schemaErr.Errors,
+		})
+	}
+
 	var pluginErr models.UpdatePluginDashboardError
 	if ok := errors.As(err, &pluginErr); ok {
 		message := fmt.Sprintf("The dashboard belongs to plugin %s.", pluginErr.PluginId)
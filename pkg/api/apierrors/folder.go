@@ -27,6 +27,10 @@ func ToFolderErrorResponse(err error) response.Response {
 		return response.Error(403, "Access denied", err)
 	}
 
+	if errors.Is(err, models.ErrFolderHasConnectedLibraryElements) {
+		return response.Error(403, "Folder could not be deleted because it contains library elements in use", err)
+	}
+
 	if errors.Is(err, models.ErrFolderNotFound) {
 		return response.JSON(404, util.DynMap{"status": "not-found", "message": models.ErrFolderNotFound.Error()})
 	}
@@ -180,6 +180,10 @@ func (hs *HTTPServer) GetDashboard(c *models.ReqContext) response.Response {
 		return response.Error(500, "Error while loading library panels", err)
 	}
 
+	if hs.ThumbService != nil {
+		hs.ThumbService.PrioritizeDashboard(c.Req.Context(), c.OrgId, dash.Uid)
+	}
+
 	dto := dtos.DashboardFullWithMeta{
 		Dashboard: dash.Data,
 		Meta:      meta,
@@ -228,13 +232,15 @@ func (hs *HTTPServer) deleteDashboard(c *models.ReqContext) response.Response {
 		return dashboardGuardianResponse(err)
 	}
 
-	// disconnect all library elements for this dashboard
-	err := hs.LibraryElementService.DisconnectElementsFromDashboard(c.Req.Context(), dash.Id)
-	if err != nil {
-		hs.log.Error("Failed to disconnect library elements", "dashboard", dash.Id, "user", c.SignedInUser.UserId, "error", err)
+	// disconnect all library elements for this dashboard, they are kept with the dashboard in the recycle bin
+	if hs.Cfg.DashboardTrashRetention == 0 {
+		err := hs.LibraryElementService.DisconnectElementsFromDashboard(c.Req.Context(), dash.Id)
+		if err != nil {
+			hs.log.Error("Failed to disconnect library elements", "dashboard", dash.Id, "user", c.SignedInUser.UserId, "error", err)
+		}
 	}
 	svc := dashboards.NewService(hs.SQLStore)
-	err = svc.DeleteDashboard(c.Req.Context(), dash.Id, c.OrgId)
+	err := svc.DeleteDashboard(c.Req.Context(), dash.Id, c.OrgId)
 	if err != nil {
 		var dashboardErr models.DashboardErr
 		if ok := errors.As(err, &dashboardErr); ok {
@@ -282,6 +288,13 @@ func (hs *HTTPServer) postDashboard(c *models.ReqContext, cmd models.SaveDashboa
 		cmd.FolderId = folder.Id
 	}
 
+	if hs.LoadSchemaService != nil && hs.LoadSchemaService.IsValidationEnabled() {
+		cmd.Dashboard, err = hs.LoadSchemaService.DashboardValidate(cmd.Dashboard)
+		if err != nil {
+			return apierrors.ToDashboardErrorResponse(ctx, hs.pluginStore, err)
+		}
+	}
+
 	dash := cmd.GetDashboardModel()
 	newDashboard := dash.Id == 0
 	if newDashboard {
@@ -548,6 +561,7 @@ func (hs *HTTPServer) GetDashboardVersion(c *models.ReqContext) response.Respons
 		Version:       query.Result.Version,
 		Created:       query.Result.Created,
 		Message:       query.Result.Message,
+		Changes:       query.Result.Changes,
 		CreatedBy:     creator,
 	}
 
@@ -673,6 +687,64 @@ func (hs *HTTPServer) RestoreDashboardVersion(c *models.ReqContext) response.Res
 	return hs.postDashboard(c, saveCmd)
 }
 
+// MergeDashboard saves a dashboard edited from an older version, when it has
+// been saved by someone else in the meantime. Their changes are merged with
+// the dashboard, conflicts which aren't resolved are returned with status 409.
+func (hs *HTTPServer) MergeDashboard(c *models.ReqContext) response.Response {
+	apiCmd := dtos.MergeDashboardCommand{}
+	if err := web.Bind(c.Req, &apiCmd); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+	dashboardId, err := strconv.ParseInt(web.Params(c.Req)[":dashboardId"], 10, 64)
+	if err != nil {
+		return response.Error(http.StatusBadRequest, "dashboardId is invalid", err)
+	}
+
+	dash, rsp := hs.getDashboardHelper(c.Req.Context(), c.OrgId, dashboardId, "")
+	if rsp != nil {
+		return rsp
+	}
+
+	guardian := guardian.New(c.Req.Context(), dash.Id, c.OrgId, c.SignedInUser)
+	if canSave, err := guardian.CanSave(); err != nil || !canSave {
+		return dashboardGuardianResponse(err)
+	}
+
+	versionQuery := models.GetDashboardVersionQuery{DashboardId: dash.Id, Version: apiCmd.BaseVersion, OrgId: c.OrgId}
+	if err := hs.SQLStore.GetDashboardVersion(c.Req.Context(), &versionQuery); err != nil {
+		if errors.Is(err, models.ErrDashboardVersionNotFound) {
+			return response.Error(http.StatusNotFound, "Dashboard version not found", nil)
+		}
+		return response.Error(http.StatusInternalServerError, "Unable to merge dashboard", err)
+	}
+
+	result, err := dashdiffs.Merge(versionQuery.Result.Data, dash.Data, apiCmd.Dashboard, apiCmd.Resolutions)
+	if err != nil {
+		return response.Error(http.StatusBadRequest, "Unable to merge dashboard", err)
+	}
+	if len(result.Conflicts) > 0 {
+		return response.JSON(http.StatusConflict, util.DynMap{
+			"status":    "conflict",
+			"message":   "The dashboard has conflicting changes",
+			"version":   dash.Version,
+			"conflicts": result.Conflicts,
+		})
+	}
+
+	saveCmd := models.SaveDashboardCommand{}
+	saveCmd.OrgId = c.OrgId
+	saveCmd.UserId = c.UserId
+	saveCmd.Dashboard = result.Dashboard
+	saveCmd.Dashboard.Set("id", dash.Id)
+	saveCmd.Dashboard.Set("uid", dash.Uid)
+	saveCmd.Dashboard.Set("version", dash.Version)
+	saveCmd.Message = apiCmd.Message
+	saveCmd.FolderId = dash.FolderId
+	saveCmd.FolderUid = apiCmd.FolderUid
+
+	return hs.postDashboard(c, saveCmd)
+}
+
 func (hs *HTTPServer) GetDashboardTags(c *models.ReqContext) {
 	query := models.GetDashboardTagsQuery{OrgId: c.OrgId}
 	err := hs.SQLStore.GetDashboardTags(c.Req.Context(), &query)
@@ -92,7 +92,7 @@ func newTestLive(t *testing.T) *live.GrafanaLive {
 		nil,
 		&usagestats.UsageStatsMock{T: t},
 		nil,
-		features)
+		features, nil, nil)
 	require.NoError(t, err)
 	return gLive
 }
@@ -850,6 +850,78 @@ func TestDashboardAPIEndpoint(t *testing.T) {
 			}, mockSQLStore)
 	})
 
+	t.Run("Given dashboard saved by someone else being merged", func(t *testing.T) {
+		baseData := simplejson.NewFromAny(map[string]interface{}{
+			"title":  "Dash",
+			"panels": []interface{}{map[string]interface{}{"id": 1, "title": "Requests"}, map[string]interface{}{"id": 2, "title": "Errors"}},
+		})
+		fakeDash := models.NewDashboardFromJson(simplejson.NewFromAny(map[string]interface{}{
+			"title":  "Dash",
+			"panels": []interface{}{map[string]interface{}{"id": 1, "title": "Request rate"}, map[string]interface{}{"id": 2, "title": "Errors"}},
+		}))
+		fakeDash.Id = 2
+		fakeDash.Uid = "uid"
+		fakeDash.Version = 2
+		fakeDash.HasAcl = false
+
+		mockSQLStore := mockstore.NewSQLStoreMock()
+		mockSQLStore.ExpectedDashboard = fakeDash
+		mockSQLStore.ExpectedDashboardVersions = []*models.DashboardVersion{
+			{
+				DashboardId: 2,
+				Version:     1,
+				Data:        baseData,
+			}}
+
+		t.Run("without conflicts should save the merged dashboard", func(t *testing.T) {
+			mock := &dashboards.FakeDashboardService{
+				SaveDashboardResult: &models.Dashboard{Id: 2, Uid: "uid", Title: "Dash", Slug: "dash", Version: 3},
+			}
+			cmd := dtos.MergeDashboardCommand{
+				BaseVersion: 1,
+				Message:     "Renamed errors panel",
+				Dashboard: simplejson.NewFromAny(map[string]interface{}{
+					"title":  "Dash",
+					"panels": []interface{}{map[string]interface{}{"id": 1, "title": "Requests"}, map[string]interface{}{"id": 2, "title": "Error rate"}},
+				}),
+			}
+			mergeDashboardScenario(t, "When calling POST on", "/api/dashboards/id/2/merge",
+				"/api/dashboards/id/:dashboardId/merge", mock, cmd, func(sc *scenarioContext) {
+					sc.fakeReqWithParams("POST", sc.url, map[string]string{}).exec()
+					assert.Equal(t, 200, sc.resp.Code)
+					dto := mock.SavedDashboards[0]
+
+					panels := dto.Dashboard.Data.Get("panels")
+					assert.Equal(t, "Request rate", panels.GetIndex(0).Get("title").MustString())
+					assert.Equal(t, "Error rate", panels.GetIndex(1).Get("title").MustString())
+					assert.Equal(t, 2, dto.Dashboard.Version)
+					assert.Equal(t, "Renamed errors panel", dto.Message)
+				}, mockSQLStore)
+		})
+
+		t.Run("with conflicts should return them", func(t *testing.T) {
+			mock := &dashboards.FakeDashboardService{}
+			cmd := dtos.MergeDashboardCommand{
+				BaseVersion: 1,
+				Dashboard: simplejson.NewFromAny(map[string]interface{}{
+					"title":  "Dash",
+					"panels": []interface{}{map[string]interface{}{"id": 1, "title": "Requests per second"}, map[string]interface{}{"id": 2, "title": "Errors"}},
+				}),
+			}
+			mergeDashboardScenario(t, "When calling POST on", "/api/dashboards/id/2/merge",
+				"/api/dashboards/id/:dashboardId/merge", mock, cmd, func(sc *scenarioContext) {
+					sc.fakeReqWithParams("POST", sc.url, map[string]string{}).exec()
+					assert.Equal(t, 409, sc.resp.Code)
+					assert.Empty(t, mock.SavedDashboards)
+
+					result := sc.ToJSON()
+					assert.Equal(t, "conflict", result.Get("status").MustString())
+					assert.Equal(t, 2, result.Get("version").MustInt())
+					assert.Equal(t, "panels/1", result.Get("conflicts").GetIndex(0).Get("path").MustString())
+				}, mockSQLStore)
+		})
+	})
+
 	t.Run("Given provisioned dashboard", func(t *testing.T) {
 		setUp := func() {
 			origGetProvisionedData := dashboards.GetProvisionedData
@@ -1142,6 +1214,56 @@ func restoreDashboardVersionScenario(t *testing.T, desc string, url string, rout
 	})
 }
 
+func mergeDashboardScenario(t *testing.T, desc string, url string, routePattern string,
+	mock *dashboards.FakeDashboardService, cmd dtos.MergeDashboardCommand, fn scenarioFunc,
+	sqlStore sqlstore.Store) {
+	t.Run(fmt.Sprintf("%s %s", desc, url), func(t *testing.T) {
+		defer bus.ClearBusHandlers()
+
+		cfg := setting.NewCfg()
+		hs := HTTPServer{
+			Cfg:                   cfg,
+			Bus:                   bus.GetBus(),
+			ProvisioningService:   provisioning.NewProvisioningServiceMock(context.Background()),
+			Live:                  newTestLive(t),
+			QuotaService:          &quota.QuotaService{Cfg: cfg},
+			LibraryPanelService:   &mockLibraryPanelService{},
+			LibraryElementService: &mockLibraryElementService{},
+			SQLStore:              sqlStore,
+		}
+
+		sc := setupScenarioContext(t, url)
+		sc.sqlStore = sqlStore
+		sc.defaultHandler = routing.Wrap(func(c *models.ReqContext) response.Response {
+			c.Req.Body = mockRequestBody(cmd)
+			c.Req.Header.Add("Content-Type", "application/json")
+			sc.context = c
+			sc.context.SignedInUser = &models.SignedInUser{
+				OrgId:  testOrgID,
+				UserId: testUserID,
+			}
+			sc.context.OrgRole = models.ROLE_ADMIN
+
+			return hs.MergeDashboard(c)
+		})
+
+		origProvisioningService := dashboards.NewProvisioningService
+		origNewDashboardService := dashboards.NewService
+		t.Cleanup(func() {
+			dashboards.NewService = origNewDashboardService
+			dashboards.NewProvisioningService = origProvisioningService
+		})
+		dashboards.NewProvisioningService = func(dboards.Store) dashboards.DashboardProvisioningService {
+			return mockDashboardProvisioningService{}
+		}
+		dashboards.MockDashboardService(mock)
+
+		sc.m.Post(routePattern, sc.defaultHandler)
+
+		fn(sc)
+	})
+}
+
 func (sc *scenarioContext) ToJSON() *simplejson.Json {
 	var result *simplejson.Json
 	err := json.NewDecoder(sc.resp.Body).Decode(&result)
@@ -0,0 +1,77 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/grafana/grafana/pkg/api/apierrors"
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/alerting"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/util"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+// GET /api/dashboards/trash
+func (hs *HTTPServer) GetTrashedDashboards(c *models.ReqContext) response.Response {
+	query := models.GetTrashedDashboardsQuery{OrgId: c.OrgId}
+	if err := hs.SQLStore.GetTrashedDashboards(c.Req.Context(), &query); err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to get deleted dashboards", err)
+	}
+
+	return response.JSON(http.StatusOK, query.Result)
+}
+
+// POST /api/dashboards/trash/:id/restore
+func (hs *HTTPServer) RestoreTrashedDashboard(c *models.ReqContext) response.Response {
+	id, err := strconv.ParseInt(web.Params(c.Req)[":id"], 10, 64)
+	if err != nil {
+		return response.Error(http.StatusBadRequest, "id is invalid", err)
+	}
+
+	ctx := c.Req.Context()
+	cmd := models.RestoreTrashedDashboardCommand{OrgId: c.OrgId, Id: id}
+	if err := hs.SQLStore.RestoreTrashedDashboard(ctx, &cmd); err != nil {
+		return apierrors.ToDashboardErrorResponse(ctx, hs.pluginStore, err)
+	}
+
+	// The alerts of the dashboards are extracted again as they were deleted with them.
+	alertCtx := alerting.WithUAEnabled(ctx, hs.Cfg.UnifiedAlerting.IsEnabled())
+	for _, dash := range cmd.Result {
+		if dash.IsFolder {
+			continue
+		}
+		if err := dashboards.UpdateAlerting(alertCtx, hs.SQLStore, c.OrgId, dash, c.SignedInUser); err != nil {
+			hs.log.Error("Failed to restore alerts of dashboard", "dashboard", dash.Id, "error", err)
+		}
+	}
+
+	dash := cmd.Result[0]
+	return response.JSON(http.StatusOK, util.DynMap{
+		"title":   dash.Title,
+		"message": fmt.Sprintf("Dashboard %s restored", dash.Title),
+		"id":      dash.Id,
+		"uid":     dash.Uid,
+	})
+}
+
+// DELETE /api/dashboards/trash/:id
+func (hs *HTTPServer) DeleteTrashedDashboard(c *models.ReqContext) response.Response {
+	id, err := strconv.ParseInt(web.Params(c.Req)[":id"], 10, 64)
+	if err != nil {
+		return response.Error(http.StatusBadRequest, "id is invalid", err)
+	}
+
+	cmd := models.DeleteTrashedDashboardCommand{OrgId: c.OrgId, Id: id}
+	if err := hs.SQLStore.DeleteTrashedDashboard(c.Req.Context(), &cmd); err != nil {
+		if errors.Is(err, models.ErrTrashedDashboardNotFound) {
+			return response.Error(http.StatusNotFound, "Deleted dashboard not found", nil)
+		}
+		return response.Error(http.StatusInternalServerError, "Failed to delete dashboard permanently", err)
+	}
+
+	return response.Success("Dashboard deleted permanently")
+}
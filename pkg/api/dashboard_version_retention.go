@@ -0,0 +1,91 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/guardian"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+// GET /api/dashboards/id/:dashboardId/retention
+func (hs *HTTPServer) GetDashboardVersionRetentionPolicy(c *models.ReqContext) response.Response {
+	dashID, err := strconv.ParseInt(web.Params(c.Req)[":dashboardId"], 10, 64)
+	if err != nil {
+		return response.Error(http.StatusBadRequest, "dashboardId is invalid", err)
+	}
+
+	g := guardian.New(c.Req.Context(), dashID, c.OrgId, c.SignedInUser)
+	if canView, err := g.CanView(); err != nil || !canView {
+		return dashboardGuardianResponse(err)
+	}
+
+	query := models.GetDashboardVersionRetentionPolicyQuery{OrgId: c.OrgId, DashboardId: dashID}
+	if err := hs.SQLStore.GetDashboardVersionRetentionPolicy(c.Req.Context(), &query); err != nil {
+		if errors.Is(err, models.ErrDashboardVersionRetentionPolicyNotFound) {
+			return response.Error(http.StatusNotFound, "Retention policy not found", nil)
+		}
+		return response.Error(http.StatusInternalServerError, "Failed to get retention policy", err)
+	}
+
+	return response.JSON(http.StatusOK, query.Result)
+}
+
+// PUT /api/dashboards/id/:dashboardId/retention
+func (hs *HTTPServer) SaveDashboardVersionRetentionPolicy(c *models.ReqContext) response.Response {
+	cmd := models.SaveDashboardVersionRetentionPolicyCommand{}
+	if err := web.Bind(c.Req, &cmd); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+	dashID, err := strconv.ParseInt(web.Params(c.Req)[":dashboardId"], 10, 64)
+	if err != nil {
+		return response.Error(http.StatusBadRequest, "dashboardId is invalid", err)
+	}
+
+	dash, rsp := hs.getDashboardHelper(c.Req.Context(), c.OrgId, dashID, "")
+	if rsp != nil {
+		return rsp
+	}
+
+	g := guardian.New(c.Req.Context(), dash.Id, c.OrgId, c.SignedInUser)
+	if canAdmin, err := g.CanAdmin(); err != nil || !canAdmin {
+		return dashboardGuardianResponse(err)
+	}
+
+	cmd.OrgId = c.OrgId
+	cmd.DashboardId = dash.Id
+	if err := hs.SQLStore.SaveDashboardVersionRetentionPolicy(c.Req.Context(), &cmd); err != nil {
+		if errors.Is(err, models.ErrDashboardVersionRetentionRulesEmpty) || errors.Is(err, models.ErrDashboardVersionRetentionRuleInvalid) {
+			return response.Error(http.StatusBadRequest, err.Error(), nil)
+		}
+		return response.Error(http.StatusInternalServerError, "Failed to save retention policy", err)
+	}
+
+	return response.JSON(http.StatusOK, cmd.Result)
+}
+
+// DELETE /api/dashboards/id/:dashboardId/retention
+func (hs *HTTPServer) DeleteDashboardVersionRetentionPolicy(c *models.ReqContext) response.Response {
+	dashID, err := strconv.ParseInt(web.Params(c.Req)[":dashboardId"], 10, 64)
+	if err != nil {
+		return response.Error(http.StatusBadRequest, "dashboardId is invalid", err)
+	}
+
+	g := guardian.New(c.Req.Context(), dashID, c.OrgId, c.SignedInUser)
+	if canAdmin, err := g.CanAdmin(); err != nil || !canAdmin {
+		return dashboardGuardianResponse(err)
+	}
+
+	cmd := models.DeleteDashboardVersionRetentionPolicyCommand{OrgId: c.OrgId, DashboardId: dashID}
+	if err := hs.SQLStore.DeleteDashboardVersionRetentionPolicy(c.Req.Context(), &cmd); err != nil {
+		if errors.Is(err, models.ErrDashboardVersionRetentionPolicyNotFound) {
+			return response.Error(http.StatusNotFound, "Retention policy not found", nil)
+		}
+		return response.Error(http.StatusInternalServerError, "Failed to delete retention policy", err)
+	}
+
+	return response.Success("Retention policy deleted")
+}
@@ -8,6 +8,7 @@ import (
 	"net/http"
 	"sort"
 	"strconv"
+	"strings"
 
 	"github.com/grafana/grafana-plugin-sdk-go/backend"
 	"github.com/grafana/grafana/pkg/api/datasource"
@@ -18,6 +19,7 @@ import (
 	"github.com/grafana/grafana/pkg/models"
 	"github.com/grafana/grafana/pkg/plugins/adapters"
 	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/datasources/usage"
 	"github.com/grafana/grafana/pkg/util"
 	"github.com/grafana/grafana/pkg/web"
 )
@@ -147,6 +149,10 @@ func (hs *HTTPServer) DeleteDataSourceById(c *models.ReqContext) response.Respon
 		return response.Error(403, "Cannot delete read-only data source", nil)
 	}
 
+	if resp := hs.checkDataSourceUsage(c, ds.Uid); resp != nil {
+		return resp
+	}
+
 	cmd := &models.DeleteDataSourceCommand{ID: id, OrgID: c.OrgId}
 
 	err = hs.SQLStore.DeleteDataSource(c.Req.Context(), cmd)
@@ -207,6 +213,10 @@ func (hs *HTTPServer) DeleteDataSourceByUID(c *models.ReqContext) response.Respo
 		return response.Error(403, "Cannot delete read-only data source", nil)
 	}
 
+	if resp := hs.checkDataSourceUsage(c, ds.Uid); resp != nil {
+		return resp
+	}
+
 	cmd := &models.DeleteDataSourceCommand{UID: uid, OrgID: c.OrgId}
 
 	err = hs.SQLStore.DeleteDataSource(c.Req.Context(), cmd)
@@ -242,6 +252,10 @@ func (hs *HTTPServer) DeleteDataSourceByName(c *models.ReqContext) response.Resp
 		return response.Error(403, "Cannot delete read-only data source", nil)
 	}
 
+	if resp := hs.checkDataSourceUsage(c, getCmd.Result.Uid); resp != nil {
+		return resp
+	}
+
 	cmd := &models.DeleteDataSourceCommand{Name: name, OrgID: c.OrgId}
 	err := hs.SQLStore.DeleteDataSource(c.Req.Context(), cmd)
 	if err != nil {
@@ -256,6 +270,72 @@ func (hs *HTTPServer) DeleteDataSourceByName(c *models.ReqContext) response.Resp
 	})
 }
 
+// GET /api/datasources/uid/:uid/usage
+func (hs *HTTPServer) GetDataSourceUsageByUID(c *models.ReqContext) response.Response {
+	ds, err := hs.getRawDataSourceByUID(c.Req.Context(), web.Params(c.Req)[":uid"], c.OrgId)
+	if err != nil {
+		if errors.Is(err, models.ErrDataSourceNotFound) {
+			return response.Error(http.StatusNotFound, "Data source not found", nil)
+		}
+		return response.Error(http.StatusInternalServerError, "Failed to query datasource", err)
+	}
+
+	dsUsage, err := hs.DataSourceUsageService.GetUsage(c.Req.Context(), c.SignedInUser, ds.Uid)
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to get data source usage", err)
+	}
+
+	return response.JSON(http.StatusOK, dsUsage)
+}
+
+// checkDataSourceUsage returns a conflict response if a data source to delete is
+// used by dashboards, library panels or alert rules, unless deletion is forced.
+// The usage is read from the database since the index may not yet reflect changes
+// made on other instances. The response only lists the dependents the user can
+// view, but the message counts all of them.
+func (hs *HTTPServer) checkDataSourceUsage(c *models.ReqContext, uid string) response.Response {
+	if c.QueryBool("force") {
+		return nil
+	}
+
+	dsUsage, err := hs.DataSourceUsageService.GetStoredUsage(c.Req.Context(), c.OrgId, uid)
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to get data source usage", err)
+	}
+	if dsUsage.IsEmpty() {
+		return nil
+	}
+
+	visibleUsage, err := hs.DataSourceUsageService.FilterUsage(c.Req.Context(), c.SignedInUser, dsUsage)
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to get data source usage", err)
+	}
+
+	return response.JSON(http.StatusConflict, util.DynMap{
+		"message": fmt.Sprintf("Data source is used by %s, use force=true to delete it anyway", usageSummary(dsUsage)),
+		"usage":   visibleUsage,
+	})
+}
+
+func usageSummary(u *usage.Usage) string {
+	var parts []string
+	count := func(n int, noun string) {
+		if n == 1 {
+			parts = append(parts, fmt.Sprintf("1 %s", noun))
+		} else if n > 1 {
+			parts = append(parts, fmt.Sprintf("%d %ss", n, noun))
+		}
+	}
+	count(len(u.Dashboards), "dashboard")
+	count(len(u.LibraryPanels), "library panel")
+	count(len(u.AlertRules), "alert rule")
+
+	if len(parts) == 1 {
+		return parts[0]
+	}
+	return strings.Join(parts[:len(parts)-1], ", ") + " and " + parts[len(parts)-1]
+}
+
 func validateURL(cmdType string, url string) response.Response {
 	if _, err := datasource.ValidateURL(cmdType, url); err != nil {
 		return response.Error(400, fmt.Sprintf("Validation error, invalid URL: %q", url), err)
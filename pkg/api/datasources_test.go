@@ -2,6 +2,7 @@ package api
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -13,6 +14,7 @@ import (
 	"github.com/grafana/grafana/pkg/api/routing"
 	"github.com/grafana/grafana/pkg/models"
 	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/datasources/usage"
 	"github.com/grafana/grafana/pkg/services/sqlstore/mockstore"
 	"github.com/grafana/grafana/pkg/setting"
 	"github.com/stretchr/testify/assert"
@@ -72,6 +74,98 @@ func TestDataSourcesProxy_userLoggedIn(t *testing.T) {
 		}, mockSQLStore)
 }
 
+func TestDeleteDataSource_InUse(t *testing.T) {
+	mockSQLStore := mockstore.NewSQLStoreMock()
+	mockSQLStore.ExpectedDatasource = &models.DataSource{Id: 1, Uid: "prom", OrgId: 1, Name: "Prometheus"}
+	hs := &HTTPServer{
+		Cfg:      setting.NewCfg(),
+		SQLStore: mockSQLStore,
+		Live:     newTestLive(t),
+		DataSourceUsageService: &fakeDataSourceUsageService{usage: &usage.Usage{
+			Dashboards:    []*usage.DashboardUsage{{UID: "a", Title: "A"}, {UID: "b", Title: "B"}},
+			LibraryPanels: []*usage.LibraryPanelUsage{},
+			AlertRules:    []*usage.AlertRuleUsage{{UID: "rule", Title: "Rule"}},
+		}, hiddenDashboards: map[string]bool{"b": true}},
+	}
+
+	loggedInUserScenarioWithRole(t, "When calling DELETE on a data source in use", "DELETE", "/api/datasources/uid/prom", "/api/datasources/uid/:uid", models.ROLE_ADMIN, func(sc *scenarioContext) {
+		sc.handlerFunc = hs.DeleteDataSourceByUID
+		sc.fakeReqWithParams("DELETE", sc.url, map[string]string{}).exec()
+		require.Equal(t, http.StatusConflict, sc.resp.Code)
+
+		var body struct {
+			Message string      `json:"message"`
+			Usage   usage.Usage `json:"usage"`
+		}
+		require.NoError(t, json.Unmarshal(sc.resp.Body.Bytes(), &body))
+		assert.Equal(t, "Data source is used by 2 dashboards and 1 alert rule, use force=true to delete it anyway", body.Message)
+		require.Len(t, body.Usage.Dashboards, 1)
+		assert.Equal(t, "a", body.Usage.Dashboards[0].UID)
+	}, mockSQLStore)
+
+	loggedInUserScenarioWithRole(t, "When calling DELETE with force on a data source in use", "DELETE", "/api/datasources/uid/prom", "/api/datasources/uid/:uid", models.ROLE_ADMIN, func(sc *scenarioContext) {
+		sc.handlerFunc = hs.DeleteDataSourceByUID
+		sc.fakeReqWithParams("DELETE", sc.url, map[string]string{"force": "true"}).exec()
+		require.Equal(t, http.StatusOK, sc.resp.Code)
+	}, mockSQLStore)
+}
+
+func TestGetDataSourceUsageByUID(t *testing.T) {
+	mockSQLStore := mockstore.NewSQLStoreMock()
+	mockSQLStore.ExpectedDatasource = &models.DataSource{Id: 1, Uid: "prom", OrgId: 1, Name: "Prometheus"}
+	usageService := &fakeDataSourceUsageService{}
+	hs := &HTTPServer{
+		Cfg:                    setting.NewCfg(),
+		SQLStore:               mockSQLStore,
+		DataSourceUsageService: usageService,
+	}
+
+	loggedInUserScenario(t, "When calling GET on usage", "/api/datasources/uid/prom/usage", "/api/datasources/uid/:uid/usage", func(sc *scenarioContext) {
+		sc.handlerFunc = hs.GetDataSourceUsageByUID
+		sc.fakeReqWithParams("GET", sc.url, map[string]string{}).exec()
+		require.Equal(t, http.StatusOK, sc.resp.Code)
+		assert.Equal(t, "prom", usageService.uid)
+		assert.JSONEq(t, `{"dashboards": [], "libraryPanels": [], "alertRules": []}`, sc.resp.Body.String())
+	}, mockSQLStore)
+}
+
+type fakeDataSourceUsageService struct {
+	usage            *usage.Usage
+	hiddenDashboards map[string]bool
+	uid              string
+}
+
+func (f *fakeDataSourceUsageService) GetUsage(ctx context.Context, user *models.SignedInUser, uid string) (*usage.Usage, error) {
+	u, err := f.GetStoredUsage(ctx, user.OrgId, uid)
+	if err != nil {
+		return nil, err
+	}
+	return f.FilterUsage(ctx, user, u)
+}
+
+func (f *fakeDataSourceUsageService) GetStoredUsage(_ context.Context, _ int64, uid string) (*usage.Usage, error) {
+	f.uid = uid
+	if f.usage != nil {
+		return f.usage, nil
+	}
+	return &usage.Usage{
+		Dashboards:    []*usage.DashboardUsage{},
+		LibraryPanels: []*usage.LibraryPanelUsage{},
+		AlertRules:    []*usage.AlertRuleUsage{},
+	}, nil
+}
+
+func (f *fakeDataSourceUsageService) FilterUsage(_ context.Context, _ *models.SignedInUser, u *usage.Usage) (*usage.Usage, error) {
+	filtered := *u
+	filtered.Dashboards = []*usage.DashboardUsage{}
+	for _, d := range u.Dashboards {
+		if !f.hiddenDashboards[d.UID] {
+			filtered.Dashboards = append(filtered.Dashboards, d)
+		}
+	}
+	return &filtered, nil
+}
+
 // Adding data sources with invalid URLs should lead to an error.
 func TestAddDataSource_InvalidURL(t *testing.T) {
 	sc := setupScenarioContext(t, "/api/datasources")
@@ -394,6 +488,30 @@ func TestAPI_Datasources_AccessControl(t *testing.T) {
 				permissions:  []*accesscontrol.Permission{{Action: "wrong"}},
 			},
 		},
+		{
+			accessControlTestCase: accessControlTestCase{
+				expectedCode: http.StatusOK,
+				desc:         "DatasourcesGetUsageByUID should return 200 for user with correct permissions",
+				url:          fmt.Sprintf("/api/datasources/uid/%v/usage", testDatasource.Uid),
+				method:       http.MethodGet,
+				permissions: []*accesscontrol.Permission{
+					{
+						Action: ActionDatasourcesRead,
+						Scope:  fmt.Sprintf("datasources:uid:%v", testDatasource.Uid),
+					},
+				},
+			},
+			expectedDS: &testDatasource,
+		},
+		{
+			accessControlTestCase: accessControlTestCase{
+				expectedCode: http.StatusForbidden,
+				desc:         "DatasourcesGetUsageByUID should return 403 for user without required permissions",
+				url:          fmt.Sprintf("/api/datasources/uid/%v/usage", testDatasource.Uid),
+				method:       http.MethodGet,
+				permissions:  []*accesscontrol.Permission{{Action: "wrong"}},
+			},
+		},
 		{
 			accessControlTestCase: accessControlTestCase{
 				expectedCode: http.StatusOK,
@@ -509,6 +627,7 @@ func TestAPI_Datasources_AccessControl(t *testing.T) {
 			sc.sqlStore = sqlStore
 			hs.SQLStore = sqlStore
 			hs.DatasourcePermissionsService = dsPermissionService
+			hs.DataSourceUsageService = &fakeDataSourceUsageService{}
 
 			// Create a middleware to pretend user is logged in
 			pretendSignInMiddleware := func(c *models.ReqContext) {
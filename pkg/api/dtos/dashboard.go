@@ -3,6 +3,7 @@ package dtos
 import (
 	"time"
 
+	"github.com/grafana/grafana/pkg/components/dashdiffs"
 	"github.com/grafana/grafana/pkg/components/simplejson"
 )
 
@@ -62,3 +63,14 @@ type CalculateDiffTarget struct {
 type RestoreDashboardVersionCommand struct {
 	Version int `json:"version" binding:"Required"`
 }
+
+// MergeDashboardCommand saves a dashboard edited from an older version. It is
+// merged with the changes saved since that version.
+type MergeDashboardCommand struct {
+	BaseVersion int              `json:"baseVersion" binding:"Required"`
+	Dashboard   *simplejson.Json `json:"dashboard" binding:"Required"`
+	Message     string           `json:"message"`
+	FolderUid   string           `json:"folderUid"`
+	// Resolutions resolves conflicts of a previous merge by their path.
+	Resolutions map[string]dashdiffs.Resolution `json:"resolutions"`
+}
@@ -47,18 +47,21 @@ func (hs *HTTPServer) Search(c *models.ReqContext) response.Response {
 	}
 
 	searchQuery := search.Query{
-		Title:        query,
-		Tags:         tags,
-		SignedInUser: c.SignedInUser,
-		Limit:        limit,
-		Page:         page,
-		IsStarred:    starred == "true",
-		OrgId:        c.OrgId,
-		DashboardIds: dbIDs,
-		Type:         dashboardType,
-		FolderIds:    folderIDs,
-		Permission:   permission,
-		Sort:         sort,
+		Title:          query,
+		FullText:       c.Query("fullText"),
+		DatasourceUIDs: c.QueryStrings("datasourceUid"),
+		Variables:      c.QueryStrings("variable"),
+		Tags:           tags,
+		SignedInUser:   c.SignedInUser,
+		Limit:          limit,
+		Page:           page,
+		IsStarred:      starred == "true",
+		OrgId:          c.OrgId,
+		DashboardIds:   dbIDs,
+		Type:           dashboardType,
+		FolderIds:      folderIDs,
+		Permission:     permission,
+		Sort:           sort,
 	}
 
 	err := hs.SearchService.SearchHandler(c.Req.Context(), &searchQuery)
@@ -0,0 +1,233 @@
+package dashdiffs
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/models"
+)
+
+// changelogIgnoredKeys change on every save or are covered by panel, variable
+// and annotation changes.
+var changelogIgnoredKeys = map[string]bool{
+	"id":          true,
+	"uid":         true,
+	"version":     true,
+	"iteration":   true,
+	"panels":      true,
+	"rows":        true,
+	"templating":  true,
+	"annotations": true,
+}
+
+// panelFields groups panel properties into the fields of panel changes.
+var panelFields = map[string]string{
+	"targets":         "queries",
+	"datasource":      "datasource",
+	"type":            "visualization",
+	"gridPos":         "layout",
+	"options":         "options",
+	"fieldConfig":     "options",
+	"transformations": "transformations",
+	"description":     "description",
+	"links":           "links",
+	"alert":           "alert",
+}
+
+// Changelog summarizes the changes of panels, template variables, annotations
+// and other properties of a dashboard between two versions. Panels are
+// matched by ID, variables and annotations by name.
+func Changelog(base, new *simplejson.Json) []*models.DashboardChange {
+	var changes []*models.DashboardChange
+	if base == nil || new == nil {
+		return changes
+	}
+
+	baseMap := base.MustMap()
+	newMap := new.MustMap()
+	for _, key := range unionKeys(baseMap, newMap) {
+		if changelogIgnoredKeys[key] || equal(baseMap[key], newMap[key]) {
+			continue
+		}
+		change := &models.DashboardChange{
+			Kind:    changeKind(baseMap[key], newMap[key]),
+			Element: "dashboard",
+			Field:   key,
+			Summary: fmt.Sprintf("Dashboard %s %s", key, changeKind(baseMap[key], newMap[key])),
+		}
+		if key == "title" && change.Kind == models.DashboardChangeChanged {
+			change.Summary = fmt.Sprintf("Dashboard renamed from '%s' to '%s'", base.Get("title").MustString(), new.Get("title").MustString())
+		}
+		changes = append(changes, change)
+	}
+
+	changes = append(changes, panelChanges(base, new)...)
+	changes = append(changes, namedChanges("variable", "Variable",
+		base.Get("templating").Get("list").MustArray(), new.Get("templating").Get("list").MustArray())...)
+	changes = append(changes, namedChanges("annotation", "Annotation",
+		base.Get("annotations").Get("list").MustArray(), new.Get("annotations").Get("list").MustArray())...)
+	return changes
+}
+
+func panelChanges(base, new *simplejson.Json) []*models.DashboardChange {
+	baseIDs, basePanels, baseOK := panelsByID(changelogPanels(base))
+	newIDs, newPanels, newOK := panelsByID(changelogPanels(new))
+	if !baseOK || !newOK {
+		if equal(changelogPanels(base), changelogPanels(new)) {
+			return nil
+		}
+		return []*models.DashboardChange{{
+			Kind:    models.DashboardChangeChanged,
+			Element: "panel",
+			Summary: "Panels changed",
+		}}
+	}
+
+	var changes []*models.DashboardChange
+	seen := map[int64]bool{}
+	for _, id := range append(baseIDs, newIDs...) {
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+
+		basePanel, newPanel := basePanels[id], newPanels[id]
+		switch {
+		case basePanel == nil:
+			name := panelName(id, newPanel)
+			changes = append(changes, &models.DashboardChange{
+				Kind:    models.DashboardChangeAdded,
+				Element: "panel",
+				Name:    name,
+				Summary: fmt.Sprintf("Panel '%s' added", name),
+			})
+		case newPanel == nil:
+			name := panelName(id, basePanel)
+			changes = append(changes, &models.DashboardChange{
+				Kind:    models.DashboardChangeRemoved,
+				Element: "panel",
+				Name:    name,
+				Summary: fmt.Sprintf("Panel '%s' removed", name),
+			})
+		default:
+			changes = append(changes, changedPanel(id, basePanel, newPanel)...)
+		}
+	}
+	return changes
+}
+
+// changedPanel returns a change per changed field of a panel.
+func changedPanel(id int64, base, new interface{}) []*models.DashboardChange {
+	baseMap := simplejson.NewFromAny(base).MustMap()
+	newMap := simplejson.NewFromAny(new).MustMap()
+	name := panelName(id, new)
+
+	var changes []*models.DashboardChange
+	fields := map[string]bool{}
+	for _, key := range unionKeys(baseMap, newMap) {
+		// Panels of collapsed rows are compared on their own.
+		if key == "panels" || equal(baseMap[key], newMap[key]) {
+			continue
+		}
+		if key == "title" {
+			changes = append(changes, &models.DashboardChange{
+				Kind:    models.DashboardChangeChanged,
+				Element: "panel",
+				Name:    name,
+				Field:   "title",
+				Summary: fmt.Sprintf("Panel '%s' renamed to '%s'", panelName(id, base), name),
+			})
+			continue
+		}
+		field, ok := panelFields[key]
+		if !ok {
+			field = "settings"
+		}
+		fields[field] = true
+	}
+
+	names := make([]string, 0, len(fields))
+	for field := range fields {
+		names = append(names, field)
+	}
+	sort.Strings(names)
+	for _, field := range names {
+		changes = append(changes, &models.DashboardChange{
+			Kind:    models.DashboardChangeChanged,
+			Element: "panel",
+			Name:    name,
+			Field:   field,
+			Summary: fmt.Sprintf("Panel '%s' %s changed", name, field),
+		})
+	}
+	return changes
+}
+
+// namedChanges compares lists of elements with a name, such as template
+// variables.
+func namedChanges(element, label string, base, new []interface{}) []*models.DashboardChange {
+	baseByName := map[string]interface{}{}
+	var names []string
+	for _, v := range base {
+		name := simplejson.NewFromAny(v).Get("name").MustString()
+		baseByName[name] = v
+		names = append(names, name)
+	}
+	newByName := map[string]interface{}{}
+	for _, v := range new {
+		name := simplejson.NewFromAny(v).Get("name").MustString()
+		newByName[name] = v
+		if _, ok := baseByName[name]; !ok {
+			names = append(names, name)
+		}
+	}
+
+	var changes []*models.DashboardChange
+	for _, name := range names {
+		baseValue, newValue := baseByName[name], newByName[name]
+		if equal(baseValue, newValue) {
+			continue
+		}
+		kind := changeKind(baseValue, newValue)
+		changes = append(changes, &models.DashboardChange{
+			Kind:    kind,
+			Element: element,
+			Name:    name,
+			Summary: fmt.Sprintf("%s '%s' %s", label, name, kind),
+		})
+	}
+	return changes
+}
+
+// changelogPanels returns the panels of a dashboard including panels of
+// collapsed rows.
+func changelogPanels(dash *simplejson.Json) interface{} {
+	panels := dash.Get("panels").MustArray()
+	if panels == nil {
+		return nil
+	}
+	var all []interface{}
+	for _, p := range panels {
+		all = append(all, p)
+		all = append(all, simplejson.NewFromAny(p).Get("panels").MustArray()...)
+	}
+	return all
+}
+
+func panelName(id int64, panel interface{}) string {
+	if title := simplejson.NewFromAny(panel).Get("title").MustString(); title != "" {
+		return title
+	}
+	return fmt.Sprintf("Panel %d", id)
+}
+
+func changeKind(base, new interface{}) models.DashboardChangeKind {
+	switch {
+	case base == nil:
+		return models.DashboardChangeAdded
+	case new == nil:
+		return models.DashboardChangeRemoved
+	}
+	return models.DashboardChangeChanged
+}
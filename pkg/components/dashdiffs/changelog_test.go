@@ -0,0 +1,74 @@
+package dashdiffs
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestChangelog(t *testing.T) {
+	base := mustJSON(t, `{
+		"id": 1,
+		"version": 1,
+		"title": "Services",
+		"time": {"from": "now-6h", "to": "now"},
+		"panels": [
+			{"id": 1, "type": "timeseries", "title": "Latency", "targets": [{"expr": "histogram_quantile(0.9, rate(latency_bucket[5m]))"}]},
+			{"id": 2, "type": "stat", "title": "Errors", "gridPos": {"x": 0, "y": 0}},
+			{"id": 3, "type": "row", "title": "Details", "collapsed": true, "panels": [
+				{"id": 4, "type": "table", "title": "Requests"}
+			]}
+		],
+		"templating": {"list": [{"name": "job", "query": "label_values(job)"}]},
+		"annotations": {"list": [{"name": "Deployments"}]}
+	}`)
+	new := mustJSON(t, `{
+		"id": 1,
+		"version": 2,
+		"title": "Service overview",
+		"time": {"from": "now-6h", "to": "now"},
+		"refresh": "1m",
+		"panels": [
+			{"id": 1, "type": "timeseries", "title": "Latency", "targets": [{"expr": "histogram_quantile(0.99, rate(latency_bucket[5m]))"}]},
+			{"id": 2, "type": "gauge", "title": "Error rate", "gridPos": {"x": 12, "y": 0}},
+			{"id": 3, "type": "row", "title": "Details", "collapsed": true, "panels": [
+				{"id": 5, "type": "logs", "title": "Logs"}
+			]}
+		],
+		"templating": {"list": [{"name": "job", "query": "label_values(job)"}, {"name": "instance"}]},
+		"annotations": {"list": []}
+	}`)
+
+	var summaries []string
+	for _, c := range Changelog(base, new) {
+		summaries = append(summaries, c.Summary)
+	}
+	require.Equal(t, []string{
+		"Dashboard refresh added",
+		"Dashboard renamed from 'Services' to 'Service overview'",
+		"Panel 'Latency' queries changed",
+		"Panel 'Errors' renamed to 'Error rate'",
+		"Panel 'Error rate' layout changed",
+		"Panel 'Error rate' visualization changed",
+		"Panel 'Requests' removed",
+		"Panel 'Logs' added",
+		"Variable 'instance' added",
+		"Annotation 'Deployments' removed",
+	}, summaries)
+
+	t.Run("returns structured changes", func(t *testing.T) {
+		changes := Changelog(base, new)
+		require.Equal(t, "panel", changes[2].Element)
+		require.Equal(t, "Latency", changes[2].Name)
+		require.Equal(t, "queries", changes[2].Field)
+		require.Equal(t, "changed", string(changes[2].Kind))
+	})
+
+	t.Run("returns no changes of identical versions", func(t *testing.T) {
+		require.Empty(t, Changelog(base, base))
+	})
+
+	t.Run("returns no changes without a base version", func(t *testing.T) {
+		require.Empty(t, Changelog(nil, new))
+	})
+}
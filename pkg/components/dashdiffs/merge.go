@@ -0,0 +1,320 @@
+package dashdiffs
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+)
+
+// Resolution selects the side of a conflict to keep.
+type Resolution string
+
+const (
+	ResolutionMine   Resolution = "mine"
+	ResolutionTheirs Resolution = "theirs"
+)
+
+// Conflict is a panel or a top level dashboard property which has been changed
+// differently by both sides of a merge. Base, Theirs and Mine are nil if the
+// panel or property doesn't exist in the version.
+type Conflict struct {
+	// Path is either the name of a top level property or panels/<id>.
+	Path    string      `json:"path"`
+	PanelID int64       `json:"panelId,omitempty"`
+	Base    interface{} `json:"base"`
+	Theirs  interface{} `json:"theirs"`
+	Mine    interface{} `json:"mine"`
+}
+
+// MergeResult is the outcome of a three-way merge. Unresolved conflicts keep
+// their side in Dashboard.
+type MergeResult struct {
+	Dashboard *simplejson.Json
+	Conflicts []*Conflict
+}
+
+// identityKeys are taken from their side of a merge, which is the latest saved
+// version of the dashboard.
+var identityKeys = map[string]bool{
+	"id":      true,
+	"uid":     true,
+	"version": true,
+}
+
+// Merge merges the changes made in mine and theirs since their common base
+// version. Panels are merged by ID, so that edits, additions and removals of
+// different panels don't conflict. Panels added on both sides with the same
+// ID are both kept, mine with a new ID below their panels. Other top level properties of the
+// dashboard, such as templating or time, are merged as a whole. Collapsed rows
+// are merged with their panels.
+//
+// Conflicts with a resolution for their path are resolved, others are
+// returned.
+func Merge(base, theirs, mine *simplejson.Json, resolutions map[string]Resolution) (*MergeResult, error) {
+	baseMap, err := base.Map()
+	if err != nil {
+		return nil, fmt.Errorf("invalid base dashboard: %w", err)
+	}
+	theirsMap, err := theirs.Map()
+	if err != nil {
+		return nil, fmt.Errorf("invalid their dashboard: %w", err)
+	}
+	mineMap, err := mine.Map()
+	if err != nil {
+		return nil, fmt.Errorf("invalid my dashboard: %w", err)
+	}
+
+	m := &merger{resolutions: resolutions}
+	merged := map[string]interface{}{}
+	for _, key := range unionKeys(baseMap, theirsMap, mineMap) {
+		if identityKeys[key] {
+			if v, ok := theirsMap[key]; ok {
+				merged[key] = v
+			}
+			continue
+		}
+
+		if key == "panels" {
+			if panels, ok := m.panels(baseMap[key], theirsMap[key], mineMap[key]); ok {
+				merged[key] = panels
+				continue
+			}
+		}
+
+		if v, ok := m.value(key, 0, baseMap[key], theirsMap[key], mineMap[key]); ok {
+			merged[key] = v
+		}
+	}
+
+	return &MergeResult{
+		Dashboard: simplejson.NewFromAny(merged),
+		Conflicts: m.conflicts,
+	}, nil
+}
+
+type merger struct {
+	resolutions map[string]Resolution
+	conflicts   []*Conflict
+}
+
+// value merges a single value, nil values are missing. It returns false if
+// the merged value is missing.
+func (m *merger) value(path string, panelID int64, base, theirs, mine interface{}) (interface{}, bool) {
+	switch {
+	case equal(theirs, mine), equal(base, mine):
+		return theirs, theirs != nil
+	case equal(base, theirs):
+		return mine, mine != nil
+	}
+
+	switch m.resolutions[path] {
+	case ResolutionMine:
+		return mine, mine != nil
+	case ResolutionTheirs:
+		return theirs, theirs != nil
+	}
+	m.conflicts = append(m.conflicts, &Conflict{
+		Path:    path,
+		PanelID: panelID,
+		Base:    base,
+		Theirs:  theirs,
+		Mine:    mine,
+	})
+	return theirs, theirs != nil
+}
+
+// panels merges panels by ID. Panels are ordered as in mine, followed by
+// panels only kept from theirs. It returns false if any panel has no ID, in
+// which case the panels are merged as a whole.
+func (m *merger) panels(base, theirs, mine interface{}) ([]interface{}, bool) {
+	baseIDs, basePanels, ok := panelsByID(base)
+	if !ok {
+		return nil, false
+	}
+	theirIDs, theirPanels, ok := panelsByID(theirs)
+	if !ok {
+		return nil, false
+	}
+	myIDs, myPanels, ok := panelsByID(mine)
+	if !ok {
+		return nil, false
+	}
+	myIDs, myPanels = renumberAddedPanels(basePanels, theirPanels, myIDs, myPanels, maxPanelID(base, theirs, mine)+1)
+
+	ids := append(append(append([]int64{}, myIDs...), theirIDs...), baseIDs...)
+	merged := []interface{}{}
+	seen := map[int64]bool{}
+	for _, id := range ids {
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+		path := fmt.Sprintf("panels/%d", id)
+		if panel, ok := m.value(path, id, basePanels[id], theirPanels[id], myPanels[id]); ok {
+			merged = append(merged, panel)
+		}
+	}
+	return merged, true
+}
+
+// renumberAddedPanels gives my panels, which have been added with the same ID
+// as a different panel added by them, new IDs starting at nextID. The panels
+// are moved below their panels, keeping their layout.
+func renumberAddedPanels(basePanels, theirPanels map[int64]interface{}, myIDs []int64, myPanels map[int64]interface{}, nextID int64) ([]int64, map[int64]interface{}) {
+	var added []int64
+	for _, id := range myIDs {
+		if _, ok := basePanels[id]; ok {
+			continue
+		}
+		if theirPanel, ok := theirPanels[id]; ok && !equal(theirPanel, myPanels[id]) {
+			added = append(added, id)
+		}
+	}
+	if len(added) == 0 {
+		return myIDs, myPanels
+	}
+
+	var top int64 = -1
+	for _, id := range added {
+		if y, ok := gridPosValue(myPanels[id], "y"); ok && (top < 0 || y < top) {
+			top = y
+		}
+	}
+	var bottom int64
+	for _, p := range theirPanels {
+		y, _ := gridPosValue(p, "y")
+		h, _ := gridPosValue(p, "h")
+		if y+h > bottom {
+			bottom = y + h
+		}
+	}
+	var offset int64
+	if top >= 0 && bottom > top {
+		offset = bottom - top
+	}
+
+	ids := append([]int64{}, myIDs...)
+	panels := make(map[int64]interface{}, len(myPanels))
+	for id, p := range myPanels {
+		panels[id] = p
+	}
+	for _, id := range added {
+		for i := range ids {
+			if ids[i] == id {
+				ids[i] = nextID
+			}
+		}
+		delete(panels, id)
+		panels[nextID] = movePanel(myPanels[id], nextID, offset)
+		nextID++
+	}
+	return ids, panels
+}
+
+// movePanel returns a copy of the panel with the given ID, moved down by offset
+// grid rows.
+func movePanel(p interface{}, id int64, offset int64) interface{} {
+	panel, ok := p.(map[string]interface{})
+	if !ok {
+		return p
+	}
+	moved := make(map[string]interface{}, len(panel))
+	for k, v := range panel {
+		moved[k] = v
+	}
+	moved["id"] = id
+	if gridPos, ok := panel["gridPos"].(map[string]interface{}); ok && offset > 0 {
+		movedPos := make(map[string]interface{}, len(gridPos))
+		for k, v := range gridPos {
+			movedPos[k] = v
+		}
+		y, _ := gridPosValue(p, "y")
+		movedPos["y"] = y + offset
+		moved["gridPos"] = movedPos
+	}
+	return moved
+}
+
+func gridPosValue(p interface{}, key string) (int64, bool) {
+	v, err := simplejson.NewFromAny(p).GetPath("gridPos", key).Int64()
+	return v, err == nil
+}
+
+// maxPanelID returns the highest panel ID of the panel lists, including the
+// panels of collapsed rows.
+func maxPanelID(lists ...interface{}) int64 {
+	var max int64
+	for _, list := range lists {
+		panels, _ := list.([]interface{})
+		for _, p := range panels {
+			panel := simplejson.NewFromAny(p)
+			if id, err := panel.Get("id").Int64(); err == nil && id > max {
+				max = id
+			}
+			if id := maxPanelID(panel.Get("panels").Interface()); id > max {
+				max = id
+			}
+		}
+	}
+	return max
+}
+
+// panelsByID returns the IDs of panels in order and the panels by ID.
+func panelsByID(v interface{}) ([]int64, map[int64]interface{}, bool) {
+	panels := map[int64]interface{}{}
+	if v == nil {
+		return nil, panels, true
+	}
+	list, ok := v.([]interface{})
+	if !ok {
+		return nil, nil, false
+	}
+	ids := make([]int64, 0, len(list))
+	for _, p := range list {
+		id, err := simplejson.NewFromAny(p).Get("id").Int64()
+		if err != nil {
+			return nil, nil, false
+		}
+		if _, exists := panels[id]; exists {
+			return nil, nil, false
+		}
+		ids = append(ids, id)
+		panels[id] = p
+	}
+	return ids, panels, true
+}
+
+// equal compares values by their JSON encoding, since numbers of dashboards
+// are either decoded as json.Number or float64.
+func equal(a, b interface{}) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	aJSON, err := json.Marshal(a)
+	if err != nil {
+		return false
+	}
+	bJSON, err := json.Marshal(b)
+	if err != nil {
+		return false
+	}
+	return bytes.Equal(aJSON, bJSON)
+}
+
+func unionKeys(maps ...map[string]interface{}) []string {
+	seen := map[string]bool{}
+	var keys []string
+	for _, m := range maps {
+		for k := range m {
+			if !seen[k] {
+				seen[k] = true
+				keys = append(keys, k)
+			}
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
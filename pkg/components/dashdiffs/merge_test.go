@@ -0,0 +1,179 @@
+package dashdiffs
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+)
+
+func mustJSON(t *testing.T, s string) *simplejson.Json {
+	t.Helper()
+	j, err := simplejson.NewJson([]byte(s))
+	require.NoError(t, err)
+	return j
+}
+
+const mergeBase = `{
+	"id": 1,
+	"uid": "dash",
+	"version": 1,
+	"title": "Services",
+	"time": {"from": "now-6h", "to": "now"},
+	"panels": [
+		{"id": 1, "title": "Requests", "gridPos": {"x": 0, "y": 0, "w": 12, "h": 8}},
+		{"id": 2, "title": "Errors", "gridPos": {"x": 12, "y": 0, "w": 12, "h": 8}}
+	]
+}`
+
+func TestMerge(t *testing.T) {
+	t.Run("merges edits of different panels and properties", func(t *testing.T) {
+		theirs := mustJSON(t, `{
+			"id": 1, "uid": "dash", "version": 2, "title": "Services",
+			"time": {"from": "now-1h", "to": "now"},
+			"panels": [
+				{"id": 1, "title": "Request rate", "gridPos": {"x": 0, "y": 0, "w": 12, "h": 8}},
+				{"id": 2, "title": "Errors", "gridPos": {"x": 12, "y": 0, "w": 12, "h": 8}},
+				{"id": 3, "title": "Latency", "gridPos": {"x": 0, "y": 8, "w": 24, "h": 8}}
+			]
+		}`)
+		mine := mustJSON(t, `{
+			"id": 1, "uid": "dash", "version": 1, "title": "Service overview",
+			"time": {"from": "now-6h", "to": "now"},
+			"panels": [
+				{"id": 1, "title": "Requests", "gridPos": {"x": 0, "y": 0, "w": 12, "h": 8}},
+				{"id": 4, "title": "Saturation", "gridPos": {"x": 12, "y": 0, "w": 12, "h": 8}}
+			]
+		}`)
+
+		result, err := Merge(mustJSON(t, mergeBase), theirs, mine, nil)
+		require.NoError(t, err)
+		require.Empty(t, result.Conflicts)
+
+		expected := mustJSON(t, `{
+			"id": 1, "uid": "dash", "version": 2, "title": "Service overview",
+			"time": {"from": "now-1h", "to": "now"},
+			"panels": [
+				{"id": 1, "title": "Request rate", "gridPos": {"x": 0, "y": 0, "w": 12, "h": 8}},
+				{"id": 4, "title": "Saturation", "gridPos": {"x": 12, "y": 0, "w": 12, "h": 8}},
+				{"id": 3, "title": "Latency", "gridPos": {"x": 0, "y": 8, "w": 24, "h": 8}}
+			]
+		}`)
+		assertJSONEqual(t, expected, result.Dashboard)
+	})
+
+	t.Run("returns conflicts of edits of the same panel or property", func(t *testing.T) {
+		theirs := mustJSON(t, `{
+			"id": 1, "uid": "dash", "version": 2, "title": "Services",
+			"time": {"from": "now-1h", "to": "now"},
+			"panels": [
+				{"id": 1, "title": "Request rate", "gridPos": {"x": 0, "y": 0, "w": 12, "h": 8}}
+			]
+		}`)
+		mine := mustJSON(t, `{
+			"id": 1, "uid": "dash", "version": 1, "title": "Services",
+			"time": {"from": "now-24h", "to": "now"},
+			"panels": [
+				{"id": 1, "title": "Requests per second", "gridPos": {"x": 0, "y": 0, "w": 12, "h": 8}},
+				{"id": 2, "title": "Error rate", "gridPos": {"x": 12, "y": 0, "w": 12, "h": 8}}
+			]
+		}`)
+
+		result, err := Merge(mustJSON(t, mergeBase), theirs, mine, nil)
+		require.NoError(t, err)
+		require.Len(t, result.Conflicts, 3)
+
+		assert.Equal(t, "panels/1", result.Conflicts[0].Path)
+		assert.Equal(t, int64(1), result.Conflicts[0].PanelID)
+		assert.Equal(t, "panels/2", result.Conflicts[1].Path)
+		assert.Nil(t, result.Conflicts[1].Theirs, "panel 2 was removed by them")
+		assert.NotNil(t, result.Conflicts[1].Mine)
+		assert.Equal(t, "time", result.Conflicts[2].Path)
+		assert.Equal(t, int64(0), result.Conflicts[2].PanelID)
+
+		// Conflicts keep their side.
+		assert.Equal(t, "now-1h", result.Dashboard.GetPath("time", "from").MustString())
+		assert.Len(t, result.Dashboard.Get("panels").MustArray(), 1)
+
+		t.Run("resolves conflicts", func(t *testing.T) {
+			result, err := Merge(mustJSON(t, mergeBase), theirs, mine, map[string]Resolution{
+				"panels/1": ResolutionTheirs,
+				"panels/2": ResolutionMine,
+				"time":     ResolutionMine,
+			})
+			require.NoError(t, err)
+			require.Empty(t, result.Conflicts)
+
+			panels := result.Dashboard.Get("panels")
+			assert.Equal(t, "Request rate", panels.GetIndex(0).Get("title").MustString())
+			assert.Equal(t, "Error rate", panels.GetIndex(1).Get("title").MustString())
+			assert.Equal(t, "now-24h", result.Dashboard.GetPath("time", "from").MustString())
+		})
+	})
+
+	t.Run("keeps both panels added with the same ID", func(t *testing.T) {
+		theirs := mustJSON(t, `{
+			"id": 1, "uid": "dash", "version": 2, "title": "Services",
+			"panels": [
+				{"id": 1, "title": "Requests", "gridPos": {"x": 0, "y": 0, "w": 12, "h": 8}},
+				{"id": 2, "title": "Errors", "gridPos": {"x": 12, "y": 0, "w": 12, "h": 8}},
+				{"id": 3, "title": "Latency", "gridPos": {"x": 0, "y": 8, "w": 24, "h": 8}}
+			]
+		}`)
+		mine := mustJSON(t, `{
+			"id": 1, "uid": "dash", "version": 1, "title": "Services",
+			"panels": [
+				{"id": 1, "title": "Requests", "gridPos": {"x": 0, "y": 0, "w": 12, "h": 8}},
+				{"id": 2, "title": "Errors", "gridPos": {"x": 12, "y": 0, "w": 12, "h": 8}},
+				{"id": 3, "title": "Saturation", "gridPos": {"x": 0, "y": 8, "w": 24, "h": 4}},
+				{"id": 4, "title": "CPU", "gridPos": {"x": 0, "y": 12, "w": 24, "h": 4}}
+			]
+		}`)
+
+		result, err := Merge(mustJSON(t, mergeBase), theirs, mine, nil)
+		require.NoError(t, err)
+		require.Empty(t, result.Conflicts)
+
+		expected := mustJSON(t, `{
+			"id": 1, "uid": "dash", "version": 2, "title": "Services",
+			"panels": [
+				{"id": 1, "title": "Requests", "gridPos": {"x": 0, "y": 0, "w": 12, "h": 8}},
+				{"id": 2, "title": "Errors", "gridPos": {"x": 12, "y": 0, "w": 12, "h": 8}},
+				{"id": 5, "title": "Saturation", "gridPos": {"x": 0, "y": 16, "w": 24, "h": 4}},
+				{"id": 4, "title": "CPU", "gridPos": {"x": 0, "y": 12, "w": 24, "h": 4}},
+				{"id": 3, "title": "Latency", "gridPos": {"x": 0, "y": 8, "w": 24, "h": 8}}
+			]
+		}`)
+		assertJSONEqual(t, expected, result.Dashboard)
+	})
+
+	t.Run("merges panels without IDs as a whole", func(t *testing.T) {
+		base := mustJSON(t, `{"panels": [{"title": "A"}]}`)
+		theirs := mustJSON(t, `{"panels": [{"title": "A"}, {"title": "B"}]}`)
+		mine := mustJSON(t, `{"panels": [{"title": "C"}]}`)
+
+		result, err := Merge(base, theirs, mine, nil)
+		require.NoError(t, err)
+		require.Len(t, result.Conflicts, 1)
+		assert.Equal(t, "panels", result.Conflicts[0].Path)
+	})
+
+	t.Run("doesn't conflict on identical changes", func(t *testing.T) {
+		changed := `{"id": 1, "uid": "dash", "version": 2, "title": "Renamed", "panels": [{"id": 1, "title": "Requests"}]}`
+		result, err := Merge(mustJSON(t, mergeBase), mustJSON(t, changed), mustJSON(t, changed), nil)
+		require.NoError(t, err)
+		require.Empty(t, result.Conflicts)
+		assertJSONEqual(t, mustJSON(t, changed), result.Dashboard)
+	})
+}
+
+func assertJSONEqual(t *testing.T, expected, actual *simplejson.Json) {
+	t.Helper()
+	expectedJSON, err := expected.MarshalJSON()
+	require.NoError(t, err)
+	actualJSON, err := actual.MarshalJSON()
+	require.NoError(t, err)
+	assert.JSONEq(t, string(expectedJSON), string(actualJSON))
+}
@@ -137,3 +137,74 @@ type UpdateThumbnailStateCommand struct {
 	State ThumbnailState
 	DashboardThumbnailMeta
 }
+
+type RenderJobState string
+
+const (
+	// RenderJobStatePending jobs are rendered from their next attempt on.
+	RenderJobStatePending RenderJobState = "pending"
+
+	// RenderJobStateRunning jobs are being rendered, they are retried if their renderer doesn't complete them in time.
+	RenderJobStateRunning RenderJobState = "running"
+
+	// RenderJobStateFailed jobs failed all their attempts. They are retried when the dashboard changes.
+	RenderJobStateFailed RenderJobState = "failed"
+)
+
+func (s RenderJobState) IsValid() bool {
+	return s == RenderJobStatePending || s == RenderJobStateRunning || s == RenderJobStateFailed
+}
+
+// A DashboardRenderJob is a queued rendering of a dashboard thumbnail. Jobs are deleted once the thumbnail is saved.
+type DashboardRenderJob struct {
+	Id               int64          `json:"id"`
+	OrgId            int64          `json:"orgId"`
+	DashboardId      int64          `json:"dashboardId"`
+	DashboardUID     string         `json:"dashboardUid" xorm:"dashboard_uid"`
+	DashboardSlug    string         `json:"dashboardSlug"`
+	DashboardVersion int            `json:"dashboardVersion"`
+	Kind             ThumbnailKind  `json:"kind"`
+	Theme            Theme          `json:"theme"`
+	State            RenderJobState `json:"state"`
+	// Priority orders pending jobs, highest first. It is the time a dashboard was last viewed, in seconds.
+	Priority int64 `json:"priority"`
+	// Renderer is the renderer of the last attempt.
+	Renderer    string    `json:"renderer"`
+	Attempts    int       `json:"attempts"`
+	LastError   string    `json:"lastError"`
+	NextAttempt time.Time `json:"nextAttempt"`
+	Created     time.Time `json:"created"`
+	Updated     time.Time `json:"updated"`
+}
+
+type EnqueueDashboardRenderJobsCommand struct {
+	Kind       ThumbnailKind
+	Theme      Theme
+	Dashboards []*DashboardWithStaleThumbnail
+}
+
+type PrioritizeDashboardRenderJobsCommand struct {
+	OrgId        int64
+	DashboardUID string
+	Priority     int64
+}
+
+type ClaimDashboardRenderJobsCommand struct {
+	Renderer string
+	// Concurrency is the maximum number of jobs running on the renderer.
+	Concurrency int
+	// Timeout is the time after which running jobs are considered abandoned.
+	Timeout time.Duration
+}
+
+type FailDashboardRenderJobCommand struct {
+	Id          int64
+	Error       string
+	State       RenderJobState
+	NextAttempt time.Time
+}
+
+type FindDashboardRenderJobsQuery struct {
+	State RenderJobState
+	Limit int
+}
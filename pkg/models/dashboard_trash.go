@@ -0,0 +1,72 @@
+package models
+
+import (
+	"time"
+)
+
+// Typed errors
+var (
+	ErrTrashedDashboardNotFound = DashboardErr{
+		Reason:     "Deleted dashboard not found",
+		StatusCode: 404,
+		Status:     "not-found",
+	}
+	ErrTrashedDashboardFolderDeleted = DashboardErr{
+		Reason:     "The folder of the dashboard is deleted, restore the folder first",
+		StatusCode: 412,
+		Status:     "folder-deleted",
+	}
+	ErrTrashedDashboardAlreadyExists = DashboardErr{
+		Reason:     "A dashboard with the same id already exists",
+		StatusCode: 412,
+		Status:     "id-exists",
+	}
+)
+
+// TrashedDashboard is a deleted dashboard or folder in the recycle bin. A
+// folder is trashed along with its dashboards and both are restored together.
+type TrashedDashboard struct {
+	Id           int64  `json:"id"`
+	OrgId        int64  `json:"-"`
+	DashboardId  int64  `json:"dashboardId"`
+	DashboardUid string `json:"uid"`
+	Title        string `json:"title"`
+	IsFolder     bool   `json:"isFolder"`
+	FolderId     int64  `json:"folderId"`
+	// Dashboards is the number of dashboards of a trashed folder.
+	Dashboards int       `json:"dashboards"`
+	Deleted    time.Time `json:"deleted"`
+	// Expires is when the dashboard is deleted permanently.
+	Expires time.Time `json:"expires" xorm:"-"`
+}
+
+//
+// Commands
+//
+
+type RestoreTrashedDashboardCommand struct {
+	Id    int64
+	OrgId int64
+
+	// Result is the restored dashboard, and the dashboards of a restored folder.
+	Result []*Dashboard
+}
+
+type DeleteTrashedDashboardCommand struct {
+	Id    int64
+	OrgId int64
+}
+
+type DeleteExpiredTrashedDashboardsCommand struct {
+	DeletedRows int64
+}
+
+//
+// Queries
+//
+
+type GetTrashedDashboardsQuery struct {
+	OrgId int64
+
+	Result []*TrashedDashboard
+}
@@ -26,34 +26,59 @@ type DashboardVersion struct {
 
 	Message string           `json:"message"`
 	Data    *simplejson.Json `json:"data"`
+	// Changes summarizes the changes from the parent version.
+	Changes []*DashboardChange `json:"changes"`
+}
+
+type DashboardChangeKind string
+
+const (
+	DashboardChangeAdded   DashboardChangeKind = "added"
+	DashboardChangeRemoved DashboardChangeKind = "removed"
+	DashboardChangeChanged DashboardChangeKind = "changed"
+)
+
+// DashboardChange is a change of a dashboard, panel, template variable or
+// annotation between two versions of a dashboard.
+type DashboardChange struct {
+	Kind DashboardChangeKind `json:"kind"`
+	// Element is either dashboard, panel, variable or annotation.
+	Element string `json:"element"`
+	// Name is the title of a panel or the name of a variable or annotation.
+	Name string `json:"name,omitempty"`
+	// Field is the changed property, such as queries of a panel.
+	Field   string `json:"field,omitempty"`
+	Summary string `json:"summary"`
 }
 
 // DashboardVersionMeta extends the dashboard version model with the names
 // associated with the UserIds, overriding the field with the same name from
 // the DashboardVersion model.
 type DashboardVersionMeta struct {
-	Id            int64            `json:"id"`
-	DashboardId   int64            `json:"dashboardId"`
-	ParentVersion int              `json:"parentVersion"`
-	RestoredFrom  int              `json:"restoredFrom"`
-	Version       int              `json:"version"`
-	Created       time.Time        `json:"created"`
-	Message       string           `json:"message"`
-	Data          *simplejson.Json `json:"data"`
-	CreatedBy     string           `json:"createdBy"`
+	Id            int64              `json:"id"`
+	DashboardId   int64              `json:"dashboardId"`
+	ParentVersion int                `json:"parentVersion"`
+	RestoredFrom  int                `json:"restoredFrom"`
+	Version       int                `json:"version"`
+	Created       time.Time          `json:"created"`
+	Message       string             `json:"message"`
+	Data          *simplejson.Json   `json:"data"`
+	Changes       []*DashboardChange `json:"changes"`
+	CreatedBy     string             `json:"createdBy"`
 }
 
 // DashboardVersionDTO represents a dashboard version, without the dashboard
 // map.
 type DashboardVersionDTO struct {
-	Id            int64     `json:"id"`
-	DashboardId   int64     `json:"dashboardId"`
-	ParentVersion int       `json:"parentVersion"`
-	RestoredFrom  int       `json:"restoredFrom"`
-	Version       int       `json:"version"`
-	Created       time.Time `json:"created"`
-	CreatedBy     string    `json:"createdBy"`
-	Message       string    `json:"message"`
+	Id            int64              `json:"id"`
+	DashboardId   int64              `json:"dashboardId"`
+	ParentVersion int                `json:"parentVersion"`
+	RestoredFrom  int                `json:"restoredFrom"`
+	Version       int                `json:"version"`
+	Created       time.Time          `json:"created"`
+	CreatedBy     string             `json:"createdBy"`
+	Message       string             `json:"message"`
+	Changes       []*DashboardChange `json:"changes"`
 }
 
 //
@@ -84,3 +109,54 @@ type GetDashboardVersionsQuery struct {
 type DeleteExpiredVersionsCommand struct {
 	DeletedRows int64
 }
+
+//
+// Retention policies
+//
+
+var (
+	ErrDashboardVersionRetentionPolicyNotFound = errors.New("dashboard version retention policy not found")
+	ErrDashboardVersionRetentionRulesEmpty     = errors.New("dashboard version retention policy requires at least one rule")
+	ErrDashboardVersionRetentionRuleInvalid    = errors.New("invalid dashboard version retention rule")
+)
+
+// DashboardVersionRetentionPolicy replaces the number of versions to keep of
+// a dashboard, or of the dashboards in a folder without a policy of their own.
+type DashboardVersionRetentionPolicy struct {
+	Id int64 `json:"-"`
+	// DashboardId is the ID of either a dashboard or a folder.
+	DashboardId int64                            `json:"dashboardId"`
+	OrgId       int64                            `json:"-"`
+	Rules       []*DashboardVersionRetentionRule `json:"rules"`
+	Created     time.Time                        `json:"created"`
+	Updated     time.Time                        `json:"updated"`
+}
+
+// DashboardVersionRetentionRule keeps versions up to an age, such as 30d or
+// 1y. With an interval, such as 1d, only the latest version of each interval
+// is kept. Versions older than the oldest rule are deleted, the latest version
+// of a dashboard is always kept.
+type DashboardVersionRetentionRule struct {
+	MaxAge   string `json:"maxAge"`
+	Interval string `json:"interval,omitempty"`
+}
+
+type GetDashboardVersionRetentionPolicyQuery struct {
+	DashboardId int64
+	OrgId       int64
+
+	Result *DashboardVersionRetentionPolicy
+}
+
+type SaveDashboardVersionRetentionPolicyCommand struct {
+	DashboardId int64                            `json:"-"`
+	OrgId       int64                            `json:"-"`
+	Rules       []*DashboardVersionRetentionRule `json:"rules" binding:"Required"`
+
+	Result *DashboardVersionRetentionPolicy
+}
+
+type DeleteDashboardVersionRetentionPolicyCommand struct {
+	DashboardId int64
+	OrgId       int64
+}
@@ -381,6 +381,8 @@ type DeleteDashboardCommand struct {
 	Id                     int64
 	OrgId                  int64
 	ForceDeleteFolderRules bool
+	// MoveToTrash keeps the dashboard in the recycle bin, when it is enabled, instead of deleting it permanently.
+	MoveToTrash bool
 }
 
 type DeleteOrphanedProvisionedDashboardsCommand struct {
@@ -16,6 +16,8 @@ var (
 	ErrFolderFailedGenerateUniqueUid = errors.New("failed to generate unique folder ID")
 	ErrFolderAccessDenied            = errors.New("access denied to folder")
 	ErrFolderContainsAlertRules      = errors.New("folder contains alert rules")
+	// ErrFolderHasConnectedLibraryElements is returned when a folder contains library elements connected to other dashboards.
+	ErrFolderHasConnectedLibraryElements = errors.New("folder contains library elements that are linked in use")
 )
 
 type Folder struct {
@@ -321,6 +321,13 @@ const (
 
 	// Team related scopes
 	ScopeTeamsAll = "teams:*"
+
+	// Live related actions
+	ActionLiveChannelsSubscribe = "live:channels:subscribe"
+	ActionLiveChannelsPublish   = "live:channels:publish"
+
+	// Live related scopes
+	ScopeLiveChannelsAll = "channels:*"
 )
 
 var (
@@ -328,6 +335,13 @@ var (
 	ScopeTeamsID = Scope("teams", "id", Parameter(":teamId"))
 )
 
+// ScopeLiveChannel returns the scope of a Live channel, e.g. channels:stream/iot/cpu.
+// Permissions can be granted on channel patterns ending with a wildcard, e.g.
+// channels:stream/iot/* for all channels of the stream/iot namespace.
+func ScopeLiveChannel(channel string) string {
+	return Scope("channels", channel)
+}
+
 const RoleGrafanaAdmin = "Grafana Admin"
 
 const FixedRolePrefix = "fixed:"
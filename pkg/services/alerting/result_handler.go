@@ -96,7 +96,7 @@ func (handler *defaultResultHandler) handle(evalContext *EvalContext) error {
 		}
 
 		annotationRepo := annotations.GetRepository()
-		if err := annotationRepo.Save(&item); err != nil {
+		if err := annotationRepo.Save(evalContext.Ctx, &item); err != nil {
 			handler.log.Error("Failed to save annotation for new alert state", "error", err)
 		}
 	}
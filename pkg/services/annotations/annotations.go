@@ -10,14 +10,18 @@ import (
 
 var (
 	ErrTimerangeMissing = errors.New("missing timerange")
+	ErrInvalidMatcher   = errors.New("invalid tag matcher")
+	ErrInvalidCursor    = errors.New("invalid cursor")
+	ErrInvalidInterval  = errors.New("interval must be positive")
 )
 
 type Repository interface {
-	Save(item *Item) error
-	Update(item *Item) error
-	Find(query *ItemQuery) ([]*ItemDTO, error)
-	Delete(params *DeleteParams) error
-	FindTags(query *TagsQuery) (FindTagsResult, error)
+	Save(ctx context.Context, item *Item) error
+	Update(ctx context.Context, item *Item) error
+	Find(ctx context.Context, query *ItemQuery) ([]*ItemDTO, error)
+	Delete(ctx context.Context, params *DeleteParams) error
+	FindTags(ctx context.Context, query *TagsQuery) (FindTagsResult, error)
+	CountTags(ctx context.Context, query *TagCountsQuery) (TagCountsResult, error)
 }
 
 // AnnotationCleaner is responsible for cleaning up old annotations
@@ -37,10 +41,44 @@ type ItemQuery struct {
 	Tags         []string `json:"tags"`
 	Type         string   `json:"type"`
 	MatchAny     bool     `json:"matchAny"`
+	DashboardUid string   `json:"dashboardUID"`
+	// Text finds annotations containing the text.
+	Text string `json:"text"`
+	// Matchers filter on tags, all of them have to match.
+	Matchers []*TagMatcher `json:"matchers"`
+	// Cursor continues a query after the last annotation of a previous page,
+	// see NextCursor.
+	Cursor string `json:"cursor"`
 
 	Limit int64 `json:"limit"`
 }
 
+// TagCountsQuery is the query for the number of annotations per tag and time
+// bucket, of the annotations matching the filters of ItemQuery.
+type TagCountsQuery struct {
+	ItemQuery
+	// Interval is the size of the time buckets in milliseconds.
+	Interval int64 `json:"interval"`
+}
+
+// TagCountDTO is the number of annotations with a tag in a time bucket
+// starting at Time.
+type TagCountDTO struct {
+	Tag   string `json:"tag"`
+	Time  int64  `json:"time"`
+	Count int64  `json:"count"`
+}
+
+// TagCountsResult is the result of a tag counts query.
+type TagCountsResult struct {
+	Counts []*TagCountDTO `json:"counts"`
+}
+
+// GetAnnotationTagCountsResponse is a response struct for TagCountsResult.
+type GetAnnotationTagCountsResponse struct {
+	Result TagCountsResult `json:"result"`
+}
+
 // TagsQuery is the query for a tags search.
 type TagsQuery struct {
 	OrgID int64  `json:"orgId"`
@@ -0,0 +1,146 @@
+package annotations
+
+import (
+	"context"
+	"sort"
+)
+
+// CompositeRepository stores alert state annotations in one repository and
+// other annotations in another, and finds annotations in both.
+type CompositeRepository struct {
+	primary Repository
+	alerts  Repository
+}
+
+func NewCompositeRepository(primary, alerts Repository) *CompositeRepository {
+	return &CompositeRepository{primary: primary, alerts: alerts}
+}
+
+func (r *CompositeRepository) Save(ctx context.Context, item *Item) error {
+	if item.AlertId != 0 {
+		return r.alerts.Save(ctx, item)
+	}
+	return r.primary.Save(ctx, item)
+}
+
+// Update updates annotations of the primary repository, as only annotations
+// of users can be updated.
+func (r *CompositeRepository) Update(ctx context.Context, item *Item) error {
+	return r.primary.Update(ctx, item)
+}
+
+func (r *CompositeRepository) Delete(ctx context.Context, params *DeleteParams) error {
+	return r.primary.Delete(ctx, params)
+}
+
+func (r *CompositeRepository) Find(ctx context.Context, query *ItemQuery) ([]*ItemDTO, error) {
+	items, err := r.primary.Find(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	// Only annotations of the primary repository have IDs.
+	if query.AnnotationId != 0 || query.Type == "annotation" {
+		return items, nil
+	}
+
+	alertQuery := *query
+	alertQuery.Type = "alert"
+	alertItems, err := r.alerts.Find(ctx, &alertQuery)
+	if err != nil {
+		return nil, err
+	}
+
+	items = append(items, alertItems...)
+	SortItems(items)
+	if query.Limit > 0 && int64(len(items)) > query.Limit {
+		items = items[:query.Limit]
+	}
+	return items, nil
+}
+
+func (r *CompositeRepository) FindTags(ctx context.Context, query *TagsQuery) (FindTagsResult, error) {
+	result, err := r.primary.FindTags(ctx, query)
+	if err != nil {
+		return result, err
+	}
+	alertResult, err := r.alerts.FindTags(ctx, query)
+	if err != nil {
+		return result, err
+	}
+
+	counts := map[string]*TagsDTO{}
+	tags := make([]*TagsDTO, 0, len(result.Tags)+len(alertResult.Tags))
+	for _, tag := range append(result.Tags, alertResult.Tags...) {
+		if existing, ok := counts[tag.Tag]; ok {
+			existing.Count += tag.Count
+			continue
+		}
+		counts[tag.Tag] = tag
+		tags = append(tags, tag)
+	}
+	sort.Slice(tags, func(i, j int) bool {
+		return tags[i].Tag < tags[j].Tag
+	})
+	if query.Limit > 0 && int64(len(tags)) > query.Limit {
+		tags = tags[:query.Limit]
+	}
+	return FindTagsResult{Tags: tags}, nil
+}
+
+func (r *CompositeRepository) CountTags(ctx context.Context, query *TagCountsQuery) (TagCountsResult, error) {
+	result, err := r.primary.CountTags(ctx, query)
+	if err != nil || query.Type == "annotation" {
+		return result, err
+	}
+	alertQuery := *query
+	alertQuery.Type = "alert"
+	alertResult, err := r.alerts.CountTags(ctx, &alertQuery)
+	if err != nil {
+		return result, err
+	}
+
+	type key struct {
+		tag  string
+		time int64
+	}
+	counts := map[key]*TagCountDTO{}
+	merged := make([]*TagCountDTO, 0, len(result.Counts)+len(alertResult.Counts))
+	for _, c := range append(result.Counts, alertResult.Counts...) {
+		k := key{tag: c.Tag, time: c.Time}
+		if existing, ok := counts[k]; ok {
+			existing.Count += c.Count
+			continue
+		}
+		counts[k] = c
+		merged = append(merged, c)
+	}
+	SortTagCounts(merged)
+	if query.Limit > 0 && int64(len(merged)) > query.Limit {
+		merged = merged[:query.Limit]
+	}
+	return TagCountsResult{Counts: merged}, nil
+}
+
+// SortItems sorts annotations in the order of Find, see Cursor.
+func SortItems(items []*ItemDTO) {
+	sort.SliceStable(items, func(i, j int) bool {
+		a, b := items[i], items[j]
+		if a.TimeEnd != b.TimeEnd {
+			return a.TimeEnd > b.TimeEnd
+		}
+		if a.Time != b.Time {
+			return a.Time > b.Time
+		}
+		return a.Id > b.Id
+	})
+}
+
+// SortTagCounts sorts tag counts in the order of CountTags, by time and tag.
+func SortTagCounts(counts []*TagCountDTO) {
+	sort.SliceStable(counts, func(i, j int) bool {
+		if counts[i].Time != counts[j].Time {
+			return counts[i].Time < counts[j].Time
+		}
+		return counts[i].Tag < counts[j].Tag
+	})
+}
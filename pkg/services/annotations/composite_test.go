@@ -0,0 +1,111 @@
+package annotations
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeRepository struct {
+	items   []*Item
+	updated []*Item
+	deleted []*DeleteParams
+	found   []*ItemDTO
+	tags    []*TagsDTO
+	counts  []*TagCountDTO
+}
+
+func (r *fakeRepository) Save(_ context.Context, item *Item) error {
+	r.items = append(r.items, item)
+	return nil
+}
+
+func (r *fakeRepository) Update(_ context.Context, item *Item) error {
+	r.updated = append(r.updated, item)
+	return nil
+}
+
+func (r *fakeRepository) Delete(_ context.Context, params *DeleteParams) error {
+	r.deleted = append(r.deleted, params)
+	return nil
+}
+
+func (r *fakeRepository) Find(_ context.Context, query *ItemQuery) ([]*ItemDTO, error) {
+	if query.Limit == 0 {
+		query.Limit = 100
+	}
+	return r.found, nil
+}
+
+func (r *fakeRepository) FindTags(_ context.Context, query *TagsQuery) (FindTagsResult, error) {
+	return FindTagsResult{Tags: r.tags}, nil
+}
+
+func (r *fakeRepository) CountTags(_ context.Context, query *TagCountsQuery) (TagCountsResult, error) {
+	return TagCountsResult{Counts: r.counts}, nil
+}
+
+func TestCompositeRepository(t *testing.T) {
+	primary := &fakeRepository{
+		found:  []*ItemDTO{{Id: 2, Time: 30, TimeEnd: 30}, {Id: 1, Time: 10, TimeEnd: 10}},
+		tags:   []*TagsDTO{{Tag: "env:prod", Count: 2}, {Tag: "outage", Count: 1}},
+		counts: []*TagCountDTO{{Tag: "env:prod", Time: 0, Count: 2}},
+	}
+	alerts := &fakeRepository{
+		found:  []*ItemDTO{{Id: -5, AlertId: 1, Time: 20, TimeEnd: 20}, {Id: -9, AlertId: 2, Time: 10, TimeEnd: 10}},
+		tags:   []*TagsDTO{{Tag: "env:prod", Count: 1}, {Tag: "alertname:cpu", Count: 1}},
+		counts: []*TagCountDTO{{Tag: "env:prod", Time: 0, Count: 1}, {Tag: "env:prod", Time: 60, Count: 1}},
+	}
+	repo := NewCompositeRepository(primary, alerts)
+
+	t.Run("saves alert annotations in the alerts repository", func(t *testing.T) {
+		require.NoError(t, repo.Save(context.Background(), &Item{AlertId: 1}))
+		require.NoError(t, repo.Save(context.Background(), &Item{DashboardId: 1}))
+		assert.Len(t, alerts.items, 1)
+		assert.Len(t, primary.items, 1)
+	})
+
+	t.Run("finds annotations in both repositories", func(t *testing.T) {
+		items, err := repo.Find(context.Background(), &ItemQuery{})
+		require.NoError(t, err)
+		require.Len(t, items, 4)
+		assert.Equal(t, int64(30), items[0].Time)
+		assert.Equal(t, int64(20), items[1].Time)
+		assert.Equal(t, int64(1), items[2].Id, "annotations of the database sort first at the same time")
+		assert.Equal(t, int64(-9), items[3].Id)
+
+		items, err = repo.Find(context.Background(), &ItemQuery{Limit: 2})
+		require.NoError(t, err)
+		assert.Len(t, items, 2)
+
+		items, err = repo.Find(context.Background(), &ItemQuery{Type: "annotation"})
+		require.NoError(t, err)
+		assert.Len(t, items, 2)
+	})
+
+	t.Run("updates and deletes annotations in the primary repository", func(t *testing.T) {
+		require.NoError(t, repo.Update(context.Background(), &Item{Id: 1}))
+		require.NoError(t, repo.Delete(context.Background(), &DeleteParams{Id: 1}))
+		assert.Len(t, primary.updated, 1)
+		assert.Len(t, primary.deleted, 1)
+		assert.Empty(t, alerts.updated)
+		assert.Empty(t, alerts.deleted)
+	})
+
+	t.Run("merges tags", func(t *testing.T) {
+		result, err := repo.FindTags(context.Background(), &TagsQuery{})
+		require.NoError(t, err)
+		require.Len(t, result.Tags, 3)
+		assert.Equal(t, &TagsDTO{Tag: "alertname:cpu", Count: 1}, result.Tags[0])
+		assert.Equal(t, &TagsDTO{Tag: "env:prod", Count: 3}, result.Tags[1])
+
+		counts, err := repo.CountTags(context.Background(), &TagCountsQuery{Interval: 60})
+		require.NoError(t, err)
+		assert.Equal(t, []*TagCountDTO{
+			{Tag: "env:prod", Time: 0, Count: 3},
+			{Tag: "env:prod", Time: 60, Count: 1},
+		}, counts.Counts)
+	})
+}
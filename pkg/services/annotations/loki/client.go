@@ -0,0 +1,164 @@
+package loki
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana/pkg/infra/httpclient"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+// DataSourceService resolves the Loki datasource storing annotations, and its
+// HTTP client with the authentication and headers of the datasource.
+type DataSourceService interface {
+	GetDataSource(ctx context.Context, query *models.GetDataSourceQuery) error
+	GetHTTPClient(ds *models.DataSource, provider httpclient.Provider) (*http.Client, error)
+}
+
+// client pushes and queries log entries with the HTTP API of Loki. The
+// datasource is resolved for every request, so that changes to it apply
+// without a restart.
+type client struct {
+	dataSources        DataSourceService
+	httpClientProvider httpclient.Provider
+	cfg                setting.AnnotationLokiSettings
+}
+
+type stream struct {
+	Stream map[string]string `json:"stream"`
+	Values [][2]string       `json:"values"`
+}
+
+type entry struct {
+	labels map[string]string
+	time   time.Time
+	line   string
+}
+
+func (c *client) push(ctx context.Context, e entry) error {
+	body, err := json.Marshal(map[string][]stream{
+		"streams": {{
+			Stream: e.labels,
+			Values: [][2]string{{strconv.FormatInt(e.time.UnixNano(), 10), e.line}},
+		}},
+	})
+	if err != nil {
+		return err
+	}
+
+	httpClient, req, err := c.newRequest(ctx, http.MethodPost, "/loki/api/v1/push", nil, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	_, err = c.do(httpClient, req)
+	return err
+}
+
+// queryRange returns the entries matching a LogQL query, latest first.
+func (c *client) queryRange(ctx context.Context, query string, start, end time.Time, limit int64) ([]entry, error) {
+	params := url.Values{}
+	params.Set("query", query)
+	params.Set("start", strconv.FormatInt(start.UnixNano(), 10))
+	params.Set("end", strconv.FormatInt(end.UnixNano(), 10))
+	params.Set("limit", strconv.FormatInt(limit, 10))
+	params.Set("direction", "backward")
+
+	httpClient, req, err := c.newRequest(ctx, http.MethodGet, "/loki/api/v1/query_range", params, nil)
+	if err != nil {
+		return nil, err
+	}
+	body, err := c.do(httpClient, req)
+	if err != nil {
+		return nil, err
+	}
+
+	var res struct {
+		Data struct {
+			ResultType string   `json:"resultType"`
+			Result     []stream `json:"result"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(body, &res); err != nil {
+		return nil, fmt.Errorf("failed to parse loki response: %w", err)
+	}
+	if res.Data.ResultType != "streams" {
+		return nil, fmt.Errorf("unexpected loki result type %q", res.Data.ResultType)
+	}
+
+	var entries []entry
+	for _, s := range res.Data.Result {
+		for _, v := range s.Values {
+			ns, err := strconv.ParseInt(v[0], 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid loki timestamp %q: %w", v[0], err)
+			}
+			entries = append(entries, entry{labels: s.Stream, time: time.Unix(0, ns), line: v[1]})
+		}
+	}
+	return entries, nil
+}
+
+func (c *client) newRequest(ctx context.Context, method, path string, params url.Values, body *bytes.Reader) (*http.Client, *http.Request, error) {
+	if c.cfg.DataSourceUID == "" {
+		return nil, nil, errors.New("no loki datasource configured for annotations")
+	}
+	query := &models.GetDataSourceQuery{Uid: c.cfg.DataSourceUID, OrgId: c.cfg.DataSourceOrgID}
+	if err := c.dataSources.GetDataSource(ctx, query); err != nil {
+		return nil, nil, fmt.Errorf("failed to get loki datasource %q: %w", c.cfg.DataSourceUID, err)
+	}
+	ds := query.Result
+	if ds.Type != models.DS_LOKI {
+		return nil, nil, fmt.Errorf("datasource %q is not a loki datasource", c.cfg.DataSourceUID)
+	}
+	httpClient, err := c.dataSources.GetHTTPClient(ds, c.httpClientProvider)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	u, err := url.Parse(strings.TrimSuffix(ds.Url, "/") + path)
+	if err != nil {
+		return nil, nil, err
+	}
+	u.RawQuery = params.Encode()
+
+	var req *http.Request
+	if body != nil {
+		req, err = http.NewRequestWithContext(ctx, method, u.String(), body)
+	} else {
+		req, err = http.NewRequestWithContext(ctx, method, u.String(), nil)
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	return httpClient, req, nil
+}
+
+func (c *client) do(httpClient *http.Client, req *http.Request) ([]byte, error) {
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode/100 != 2 {
+		return nil, fmt.Errorf("loki returned status %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+	return body, nil
+}
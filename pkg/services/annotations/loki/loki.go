@@ -0,0 +1,484 @@
+// Package loki stores alert state annotations as log entries in a Loki
+// datasource, so that busy organizations don't fill the database with them.
+package loki
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/infra/httpclient"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/annotations"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+var ErrNotSupported = errors.New("annotations in Loki can't be updated or deleted")
+
+const (
+	sourceLabel      = "source"
+	sourceValue      = "grafana_annotations"
+	orgLabel         = "org_id"
+	dashboardLabel   = "dashboard_id"
+	panelLabel       = "panel_id"
+	alertLabel       = "alert_id"
+	keyOnlyTagValue  = "true"
+	defaultLookback  = 30 * 24 * time.Hour
+	maxAggregateSize = 5000
+)
+
+var (
+	reservedLabels = map[string]bool{
+		sourceLabel:    true,
+		orgLabel:       true,
+		dashboardLabel: true,
+		panelLabel:     true,
+		alertLabel:     true,
+	}
+	invalidLabelChars = regexp.MustCompile(`[^a-zA-Z0-9_]`)
+)
+
+// DashboardIDFunc returns the ID of a dashboard by UID, or 0 if there is no
+// such dashboard.
+type DashboardIDFunc func(ctx context.Context, orgID int64, uid string) (int64, error)
+
+// Repository is an annotations.Repository storing annotations in Loki.
+// Annotations are labeled with their organization, dashboard, panel, alert
+// and tags, and the log line is a JSON object with the text and other fields.
+// Entries of Loki can't be updated or deleted, so the repository is only used
+// for alert state annotations by annotations.CompositeRepository.
+type Repository struct {
+	client      *client
+	dashboardID DashboardIDFunc
+	log         log.Logger
+}
+
+func NewRepository(cfg setting.AnnotationLokiSettings, dataSources DataSourceService,
+	httpClientProvider httpclient.Provider, dashboardID DashboardIDFunc) *Repository {
+	return &Repository{
+		client: &client{
+			dataSources:        dataSources,
+			httpClientProvider: httpClientProvider,
+			cfg:                cfg,
+		},
+		dashboardID: dashboardID,
+		log:         log.New("annotations.loki"),
+	}
+}
+
+// line is the log line of an annotation.
+type line struct {
+	Text      string           `json:"text"`
+	TimeEnd   int64            `json:"timeEnd"`
+	UserId    int64            `json:"userId,omitempty"`
+	PrevState string           `json:"prevState,omitempty"`
+	NewState  string           `json:"newState,omitempty"`
+	Tags      []string         `json:"tags,omitempty"`
+	Data      *simplejson.Json `json:"data,omitempty"`
+	Created   int64            `json:"created"`
+}
+
+func (r *Repository) Save(ctx context.Context, item *annotations.Item) error {
+	item.Tags = models.JoinTagPairs(models.ParseTagPairs(item.Tags))
+	item.Created = time.Now().UnixNano() / int64(time.Millisecond)
+	item.Updated = item.Created
+	if item.Epoch == 0 {
+		item.Epoch = item.Created
+	}
+	if item.EpochEnd == 0 {
+		item.EpochEnd = item.Epoch
+	}
+	if item.EpochEnd < item.Epoch {
+		item.Epoch, item.EpochEnd = item.EpochEnd, item.Epoch
+	}
+
+	l, err := json.Marshal(line{
+		Text:      item.Text,
+		TimeEnd:   item.EpochEnd,
+		UserId:    item.UserId,
+		PrevState: item.PrevState,
+		NewState:  item.NewState,
+		Tags:      item.Tags,
+		Data:      item.Data,
+		Created:   item.Created,
+	})
+	if err != nil {
+		return err
+	}
+
+	labels := map[string]string{
+		sourceLabel:    sourceValue,
+		orgLabel:       strconv.FormatInt(item.OrgId, 10),
+		dashboardLabel: strconv.FormatInt(item.DashboardId, 10),
+		panelLabel:     strconv.FormatInt(item.PanelId, 10),
+		alertLabel:     strconv.FormatInt(item.AlertId, 10),
+	}
+	values := map[string]int{}
+	tags := models.ParseTagPairs(item.Tags)
+	for _, tag := range tags {
+		values[tag.Key]++
+	}
+	for _, tag := range tags {
+		name := labelName(tag.Key)
+		if name == "" || values[tag.Key] > 1 {
+			continue
+		}
+		value := tag.Value
+		if value == "" {
+			value = keyOnlyTagValue
+		}
+		labels[name] = value
+	}
+
+	return r.client.push(ctx, entry{
+		labels: labels,
+		time:   time.UnixMilli(item.Epoch),
+		line:   string(l),
+	})
+}
+
+func (r *Repository) Update(ctx context.Context, item *annotations.Item) error {
+	return ErrNotSupported
+}
+
+func (r *Repository) Delete(ctx context.Context, params *annotations.DeleteParams) error {
+	return ErrNotSupported
+}
+
+func (r *Repository) Find(ctx context.Context, query *annotations.ItemQuery) ([]*annotations.ItemDTO, error) {
+	items := make([]*annotations.ItemDTO, 0)
+	// Annotations in Loki can't be found by ID, see entryID.
+	if query.AnnotationId != 0 {
+		return items, nil
+	}
+	if query.Limit == 0 {
+		query.Limit = 100
+	}
+
+	selector, err := r.selector(ctx, query)
+	if err != nil || selector == "" {
+		return items, err
+	}
+
+	var cursor *annotations.Cursor
+	if query.Cursor != "" {
+		if cursor, err = annotations.ParseCursor(query.Cursor); err != nil {
+			return nil, err
+		}
+	}
+
+	// Entries are at the start of annotations, regions starting before the
+	// time range aren't found.
+	end := time.Now()
+	start := end.Add(-defaultLookback)
+	if query.From > 0 && query.To > 0 {
+		start = time.UnixMilli(query.From)
+		end = time.UnixMilli(query.To + 1)
+	}
+	if cursor != nil && time.UnixMilli(cursor.TimeEnd+1).Before(end) {
+		end = time.UnixMilli(cursor.TimeEnd + 1)
+	}
+
+	entries, err := r.client.queryRange(ctx, selector, start, end, query.Limit)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, e := range entries {
+		item, err := toItem(e)
+		if err != nil {
+			r.log.Warn("Skipping invalid annotation entry", "error", err)
+			continue
+		}
+		if matches(query, cursor, item) {
+			items = append(items, item)
+		}
+	}
+
+	annotations.SortItems(items)
+	if int64(len(items)) > query.Limit {
+		items = items[:query.Limit]
+	}
+	return items, nil
+}
+
+func (r *Repository) FindTags(ctx context.Context, query *annotations.TagsQuery) (annotations.FindTagsResult, error) {
+	if query.Limit == 0 {
+		query.Limit = 100
+	}
+	result := annotations.FindTagsResult{Tags: []*annotations.TagsDTO{}}
+
+	items, err := r.Find(ctx, &annotations.ItemQuery{OrgId: query.OrgID, Limit: maxAggregateSize})
+	if err != nil {
+		return result, err
+	}
+
+	counts := map[string]*annotations.TagsDTO{}
+	filter := strings.ToLower(query.Tag)
+	for _, item := range items {
+		for _, tag := range item.Tags {
+			if !strings.Contains(strings.ToLower(tag), filter) {
+				continue
+			}
+			if _, ok := counts[tag]; !ok {
+				counts[tag] = &annotations.TagsDTO{Tag: tag}
+				result.Tags = append(result.Tags, counts[tag])
+			}
+			counts[tag].Count++
+		}
+	}
+
+	sort.Slice(result.Tags, func(i, j int) bool {
+		return result.Tags[i].Tag < result.Tags[j].Tag
+	})
+	if int64(len(result.Tags)) > query.Limit {
+		result.Tags = result.Tags[:query.Limit]
+	}
+	return result, nil
+}
+
+func (r *Repository) CountTags(ctx context.Context, query *annotations.TagCountsQuery) (annotations.TagCountsResult, error) {
+	result := annotations.TagCountsResult{Counts: []*annotations.TagCountDTO{}}
+	if query.Interval <= 0 {
+		return result, annotations.ErrInvalidInterval
+	}
+
+	itemQuery := query.ItemQuery
+	itemQuery.Limit = maxAggregateSize
+	items, err := r.Find(ctx, &itemQuery)
+	if err != nil {
+		return result, err
+	}
+
+	type key struct {
+		tag  string
+		time int64
+	}
+	counts := map[key]*annotations.TagCountDTO{}
+	for _, item := range items {
+		bucket := item.Time - item.Time%query.Interval
+		for _, tag := range item.Tags {
+			k := key{tag: tag, time: bucket}
+			if _, ok := counts[k]; !ok {
+				counts[k] = &annotations.TagCountDTO{Tag: tag, Time: bucket}
+				result.Counts = append(result.Counts, counts[k])
+			}
+			counts[k].Count++
+		}
+	}
+
+	annotations.SortTagCounts(result.Counts)
+	if query.Limit > 0 && int64(len(result.Counts)) > query.Limit {
+		result.Counts = result.Counts[:query.Limit]
+	}
+	return result, nil
+}
+
+// selector returns the LogQL stream selector of a query, or an empty string
+// if no annotations can match. Filters which labels can't express exactly are
+// applied to the entries by matches.
+func (r *Repository) selector(ctx context.Context, query *annotations.ItemQuery) (string, error) {
+	matchers := []string{
+		labelMatcher(sourceLabel, "=", sourceValue),
+		labelMatcher(orgLabel, "=", strconv.FormatInt(query.OrgId, 10)),
+	}
+
+	dashboardID := query.DashboardId
+	if query.DashboardUid != "" {
+		id, err := r.dashboardID(ctx, query.OrgId, query.DashboardUid)
+		if err != nil {
+			return "", err
+		}
+		if id == 0 || (dashboardID != 0 && dashboardID != id) {
+			return "", nil
+		}
+		dashboardID = id
+	}
+	if dashboardID != 0 {
+		matchers = append(matchers, labelMatcher(dashboardLabel, "=", strconv.FormatInt(dashboardID, 10)))
+	}
+	if query.PanelId != 0 {
+		matchers = append(matchers, labelMatcher(panelLabel, "=", strconv.FormatInt(query.PanelId, 10)))
+	}
+	if query.AlertId != 0 {
+		matchers = append(matchers, labelMatcher(alertLabel, "=", strconv.FormatInt(query.AlertId, 10)))
+	}
+	switch query.Type {
+	case "alert":
+		matchers = append(matchers, labelMatcher(alertLabel, "!=", "0"))
+	case "annotation":
+		matchers = append(matchers, labelMatcher(alertLabel, "=", "0"))
+	}
+
+	if !query.MatchAny {
+		for _, tag := range models.ParseTagPairs(query.Tags) {
+			if name := labelName(tag.Key); name != "" && tag.Value != "" {
+				matchers = append(matchers, labelMatcher(name, "=", tag.Value))
+			}
+		}
+	}
+	for _, m := range query.Matchers {
+		if name := labelName(m.Key); name != "" && labelMatchable(m) {
+			matchers = append(matchers, labelMatcher(name, string(m.Type), m.Value))
+		}
+	}
+
+	s := "{" + strings.Join(matchers, ", ") + "}"
+	if query.Text != "" {
+		s += " |~ " + strconv.Quote("(?i)"+regexp.QuoteMeta(query.Text))
+	}
+	return s, nil
+}
+
+// matches returns whether an annotation matches the filters of a query which
+// the selector doesn't apply exactly.
+func matches(query *annotations.ItemQuery, cursor *annotations.Cursor, item *annotations.ItemDTO) bool {
+	if query.UserId != 0 && item.UserId != query.UserId {
+		return false
+	}
+	if query.From > 0 && query.To > 0 && (item.Time > query.To || item.TimeEnd < query.From) {
+		return false
+	}
+	if query.Text != "" && !strings.Contains(strings.ToLower(item.Text), strings.ToLower(query.Text)) {
+		return false
+	}
+	if cursor != nil && !(item.TimeEnd < cursor.TimeEnd ||
+		(item.TimeEnd == cursor.TimeEnd && (item.Time < cursor.Time || (item.Time == cursor.Time && item.Id < cursor.Id)))) {
+		return false
+	}
+
+	tags := models.ParseTagPairs(item.Tags)
+	if len(query.Tags) > 0 {
+		found := 0
+		queryTags := models.ParseTagPairs(query.Tags)
+		for _, qt := range queryTags {
+			for _, t := range tags {
+				if t.Key == qt.Key && (qt.Value == "" || t.Value == qt.Value) {
+					found++
+					break
+				}
+			}
+		}
+		if (query.MatchAny && found == 0) || (!query.MatchAny && found < len(queryTags)) {
+			return false
+		}
+	}
+
+	for _, m := range query.Matchers {
+		matched := false
+		for _, t := range tags {
+			if t.Key != m.Key {
+				continue
+			}
+			if m.Type == annotations.MatchRegexp {
+				ok, err := m.Matches(t.Value)
+				matched = err == nil && ok
+			} else {
+				matched = t.Value == m.Value
+			}
+			if matched {
+				break
+			}
+		}
+		if matched == (m.Type == annotations.MatchNotEqual) {
+			return false
+		}
+	}
+	return true
+}
+
+func toItem(e entry) (*annotations.ItemDTO, error) {
+	var l line
+	if err := json.Unmarshal([]byte(e.line), &l); err != nil {
+		return nil, err
+	}
+
+	item := &annotations.ItemDTO{
+		Time:      e.time.UnixNano() / int64(time.Millisecond),
+		TimeEnd:   l.TimeEnd,
+		Text:      l.Text,
+		Tags:      l.Tags,
+		UserId:    l.UserId,
+		PrevState: l.PrevState,
+		NewState:  l.NewState,
+		Data:      l.Data,
+		Id:        entryID(e),
+		Created:   l.Created,
+		Updated:   l.Created,
+	}
+	if item.Tags == nil {
+		item.Tags = []string{}
+	}
+	if item.TimeEnd == 0 {
+		item.TimeEnd = item.Time
+	}
+
+	var err error
+	for label, target := range map[string]*int64{
+		dashboardLabel: &item.DashboardId,
+		panelLabel:     &item.PanelId,
+		alertLabel:     &item.AlertId,
+	} {
+		if v, ok := e.labels[label]; ok {
+			if *target, err = strconv.ParseInt(v, 10, 64); err != nil {
+				return nil, fmt.Errorf("invalid label %s: %w", label, err)
+			}
+		}
+	}
+	return item, nil
+}
+
+// entryID returns a negative ID derived from an entry, so that annotations of
+// Loki have distinct and stable IDs for the order of annotations and cursors,
+// and sort after annotations of the database with the same time.
+func entryID(e entry) int64 {
+	names := make([]string, 0, len(e.labels))
+	for name := range e.labels {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	h := fnv.New64a()
+	for _, name := range names {
+		_, _ = fmt.Fprintf(h, "%s=%q,", name, e.labels[name])
+	}
+	_, _ = fmt.Fprintf(h, "%d,%s", e.time.UnixNano(), e.line)
+	return -int64(h.Sum64()>>1) - 1
+}
+
+// labelMatchable returns whether a tag matcher can filter labels, which
+// don't exist for empty values.
+func labelMatchable(m *annotations.TagMatcher) bool {
+	switch m.Type {
+	case annotations.MatchEqual:
+		return m.Value != ""
+	case annotations.MatchRegexp:
+		ok, err := m.Matches("")
+		return err == nil && !ok
+	}
+	return false
+}
+
+// labelName returns the label of a tag key, or an empty string if the key
+// can't be a label. Keys with several values of an annotation aren't labels,
+// so tag filters don't find the annotation.
+func labelName(key string) string {
+	name := invalidLabelChars.ReplaceAllString(key, "_")
+	if name == "" || reservedLabels[name] || strings.HasPrefix(name, "__") || (name[0] >= '0' && name[0] <= '9') {
+		return ""
+	}
+	return name
+}
+
+func labelMatcher(name, op, value string) string {
+	return name + op + strconv.Quote(value)
+}
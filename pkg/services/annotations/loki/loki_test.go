@@ -0,0 +1,248 @@
+package loki
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/httpclient"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/annotations"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+// fakeLoki records pushed streams and responds to queries with them.
+type fakeLoki struct {
+	streams []stream
+	queries []*http.Request
+}
+
+func (f *fakeLoki) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch r.URL.Path {
+	case "/loki/api/v1/push":
+		body, _ := ioutil.ReadAll(r.Body)
+		var req struct {
+			Streams []stream `json:"streams"`
+		}
+		if err := json.Unmarshal(body, &req); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		f.streams = append(f.streams, req.Streams...)
+		w.WriteHeader(http.StatusNoContent)
+	case "/loki/api/v1/query_range":
+		f.queries = append(f.queries, r)
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"status": "success",
+			"data": map[string]interface{}{
+				"resultType": "streams",
+				"result":     f.streams,
+			},
+		})
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+// fakeDataSources returns a Loki datasource, with an HTTP client setting the
+// tenant header like custom headers of a datasource.
+type fakeDataSources struct {
+	url string
+}
+
+func (f *fakeDataSources) GetDataSource(ctx context.Context, query *models.GetDataSourceQuery) error {
+	if query.Uid != "loki" || query.OrgId != 2 {
+		return models.ErrDataSourceNotFound
+	}
+	query.Result = &models.DataSource{Uid: query.Uid, OrgId: query.OrgId, Type: models.DS_LOKI, Url: f.url}
+	return nil
+}
+
+func (f *fakeDataSources) GetHTTPClient(ds *models.DataSource, provider httpclient.Provider) (*http.Client, error) {
+	return &http.Client{Transport: roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		req.Header.Set("X-Scope-OrgID", "tenant")
+		return http.DefaultTransport.RoundTrip(req)
+	})}, nil
+}
+
+type roundTripperFunc func(req *http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+func setupRepository(t *testing.T) (*Repository, *fakeLoki) {
+	t.Helper()
+	loki := &fakeLoki{}
+	server := httptest.NewServer(loki)
+	t.Cleanup(server.Close)
+
+	repo := NewRepository(setting.AnnotationLokiSettings{DataSourceUID: "loki", DataSourceOrgID: 2},
+		&fakeDataSources{url: server.URL}, httpclient.NewProvider(),
+		func(ctx context.Context, orgID int64, uid string) (int64, error) {
+			if uid == "dash" {
+				return 3, nil
+			}
+			return 0, nil
+		})
+	return repo, loki
+}
+
+func TestRepository(t *testing.T) {
+	repo, loki := setupRepository(t)
+
+	for _, item := range []*annotations.Item{
+		{OrgId: 1, AlertId: 7, Epoch: 1000, Text: "CPU high - Alerting", PrevState: "Normal", NewState: "Alerting", Tags: []string{"env:prod", "outage"}},
+		{OrgId: 1, DashboardId: 3, PanelId: 2, Epoch: 2000, EpochEnd: 3000, Text: "Deployed checkout", Tags: []string{"env:staging", "server-name:a", "org_id:2"}},
+		{OrgId: 1, Epoch: 4000, Text: "Scaled payments", Tags: []string{"env:prod", "env:eu"}},
+	} {
+		require.NoError(t, repo.Save(context.Background(), item))
+	}
+
+	t.Run("pushes annotations with tags as labels", func(t *testing.T) {
+		require.Len(t, loki.streams, 3)
+		assert.Equal(t, map[string]string{
+			"source":       "grafana_annotations",
+			"org_id":       "1",
+			"dashboard_id": "0",
+			"panel_id":     "0",
+			"alert_id":     "7",
+			"env":          "prod",
+			"outage":       "true",
+		}, loki.streams[0].Stream)
+		assert.Equal(t, "1000000000", loki.streams[0].Values[0][0])
+
+		assert.Equal(t, "staging", loki.streams[1].Stream["env"])
+		assert.Equal(t, "a", loki.streams[1].Stream["server_name"])
+		assert.Equal(t, "1", loki.streams[1].Stream["org_id"], "tags can't replace reserved labels")
+		assert.NotContains(t, loki.streams[2].Stream, "env", "keys with several values aren't labels")
+	})
+
+	t.Run("finds annotations", func(t *testing.T) {
+		items, err := repo.Find(context.Background(), &annotations.ItemQuery{OrgId: 1, From: 1, To: 5000})
+		require.NoError(t, err)
+		require.Len(t, items, 3)
+
+		assert.Equal(t, "Scaled payments", items[0].Text)
+		assert.Equal(t, "Deployed checkout", items[1].Text)
+		assert.Equal(t, int64(2000), items[1].Time)
+		assert.Equal(t, int64(3000), items[1].TimeEnd)
+		assert.Equal(t, int64(3), items[1].DashboardId)
+		assert.Equal(t, int64(2), items[1].PanelId)
+		assert.Equal(t, []string{"env:staging", "server-name:a", "org_id:2"}, items[1].Tags)
+		assert.Equal(t, int64(7), items[2].AlertId)
+		assert.Equal(t, "Alerting", items[2].NewState)
+		assert.Less(t, items[2].Id, int64(0), "annotations of loki have negative IDs")
+
+		query := loki.queries[len(loki.queries)-1]
+		assert.Equal(t, `{source="grafana_annotations", org_id="1"}`, query.URL.Query().Get("query"))
+		assert.Equal(t, "1000000", query.URL.Query().Get("start"))
+		assert.Equal(t, "backward", query.URL.Query().Get("direction"))
+		assert.Equal(t, "tenant", query.Header.Get("X-Scope-OrgID"))
+	})
+
+	t.Run("filters annotations", func(t *testing.T) {
+		matcher, err := annotations.ParseTagMatcher("env=~prod|eu")
+		require.NoError(t, err)
+		items, err := repo.Find(context.Background(), &annotations.ItemQuery{
+			OrgId:        1,
+			DashboardUid: "dash",
+			Type:         "annotation",
+			Text:         "deployed",
+			Tags:         []string{"server-name:a"},
+			Matchers:     []*annotations.TagMatcher{matcher},
+		})
+		require.NoError(t, err)
+		assert.Empty(t, items, "the fake doesn't filter, so filters are applied to the entries")
+
+		query := loki.queries[len(loki.queries)-1]
+		assert.Equal(t, `{source="grafana_annotations", org_id="1", dashboard_id="3", alert_id="0", server_name="a", env=~"prod|eu"} |~ "(?i)deployed"`,
+			query.URL.Query().Get("query"))
+
+		items, err = repo.Find(context.Background(), &annotations.ItemQuery{OrgId: 1, Tags: []string{"outage", "server-name"}, MatchAny: true})
+		require.NoError(t, err)
+		require.Len(t, items, 2)
+
+		items, err = repo.Find(context.Background(), &annotations.ItemQuery{OrgId: 1, DashboardUid: "unknown"})
+		require.NoError(t, err)
+		assert.Empty(t, items)
+	})
+
+	t.Run("paginates annotations with a cursor", func(t *testing.T) {
+		query := &annotations.ItemQuery{OrgId: 1, Limit: 2}
+		items, err := repo.Find(context.Background(), query)
+		require.NoError(t, err)
+		require.Len(t, items, 2)
+
+		query.Cursor = annotations.NextCursor(items, query.Limit)
+		items, err = repo.Find(context.Background(), query)
+		require.NoError(t, err)
+		require.Len(t, items, 1)
+		assert.Equal(t, "CPU high - Alerting", items[0].Text)
+	})
+
+	t.Run("counts tags", func(t *testing.T) {
+		tags, err := repo.FindTags(context.Background(), &annotations.TagsQuery{OrgID: 1, Tag: "env"})
+		require.NoError(t, err)
+		require.Len(t, tags.Tags, 3)
+		assert.Equal(t, "env:eu", tags.Tags[0].Tag)
+		assert.Equal(t, "env:prod", tags.Tags[1].Tag)
+		assert.Equal(t, int64(2), tags.Tags[1].Count)
+
+		counts, err := repo.CountTags(context.Background(), &annotations.TagCountsQuery{
+			ItemQuery: annotations.ItemQuery{OrgId: 1, Tags: []string{"env:prod"}},
+			Interval:  3000,
+		})
+		require.NoError(t, err)
+		var result []annotations.TagCountDTO
+		for _, c := range counts.Counts {
+			result = append(result, *c)
+		}
+		assert.Equal(t, []annotations.TagCountDTO{
+			{Tag: "env:prod", Time: 0, Count: 1},
+			{Tag: "outage", Time: 0, Count: 1},
+			{Tag: "env:eu", Time: 3000, Count: 1},
+			{Tag: "env:prod", Time: 3000, Count: 1},
+		}, result)
+	})
+
+	t.Run("doesn't update or delete annotations", func(t *testing.T) {
+		require.ErrorIs(t, repo.Update(context.Background(), &annotations.Item{Id: 1}), ErrNotSupported)
+		require.ErrorIs(t, repo.Delete(context.Background(), &annotations.DeleteParams{Id: 1}), ErrNotSupported)
+	})
+}
+
+func TestRepositoryCursorWithSameTime(t *testing.T) {
+	repo, _ := setupRepository(t)
+	for _, text := range []string{"a", "b", "c"} {
+		require.NoError(t, repo.Save(context.Background(), &annotations.Item{OrgId: 1, AlertId: 7, Epoch: 1000, Text: text}))
+	}
+
+	query := &annotations.ItemQuery{OrgId: 1, Limit: 1}
+	seen := map[string]bool{}
+	for i := 0; i < 3; i++ {
+		items, err := repo.Find(context.Background(), query)
+		require.NoError(t, err)
+		require.Len(t, items, 1)
+		seen[items[0].Text] = true
+		query.Cursor = annotations.NextCursor(items, query.Limit)
+	}
+	assert.Len(t, seen, 3)
+
+	items, err := repo.Find(context.Background(), query)
+	require.NoError(t, err)
+	assert.Empty(t, items)
+}
+
+func TestRepositoryWithoutDataSource(t *testing.T) {
+	repo := NewRepository(setting.AnnotationLokiSettings{DataSourceUID: "missing", DataSourceOrgID: 2},
+		&fakeDataSources{}, httpclient.NewProvider(), nil)
+	require.Error(t, repo.Save(context.Background(), &annotations.Item{OrgId: 1, AlertId: 7, Epoch: 1000}))
+}
@@ -0,0 +1,118 @@
+package annotations
+
+import (
+	"encoding/base64"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+type MatchType string
+
+const (
+	MatchEqual    MatchType = "="
+	MatchNotEqual MatchType = "!="
+	MatchRegexp   MatchType = "=~"
+)
+
+// TagMatcher matches the value of tags with a key, such as env=prod for the
+// tag env:prod. A regexp has to match the whole value.
+type TagMatcher struct {
+	Key   string    `json:"key"`
+	Value string    `json:"value"`
+	Type  MatchType `json:"type"`
+
+	re *regexp.Regexp
+}
+
+// ParseTagMatcher parses a matcher such as env=prod, env!=dev or env=~prod|staging.
+func ParseTagMatcher(s string) (*TagMatcher, error) {
+	i := strings.Index(s, "=")
+	if i < 0 {
+		return nil, fmt.Errorf("%w: %q has no operator", ErrInvalidMatcher, s)
+	}
+
+	m := &TagMatcher{Key: s[:i], Value: s[i+1:], Type: MatchEqual}
+	switch {
+	case strings.HasSuffix(m.Key, "!"):
+		m.Key = strings.TrimSuffix(m.Key, "!")
+		m.Type = MatchNotEqual
+	case strings.HasPrefix(m.Value, "~"):
+		m.Value = strings.TrimPrefix(m.Value, "~")
+		m.Type = MatchRegexp
+	}
+
+	m.Key = strings.TrimSpace(m.Key)
+	m.Value = strings.TrimSpace(m.Value)
+	if m.Key == "" {
+		return nil, fmt.Errorf("%w: %q has no tag key", ErrInvalidMatcher, s)
+	}
+	if err := m.compile(); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (m *TagMatcher) compile() error {
+	if m.Type != MatchRegexp || m.re != nil {
+		return nil
+	}
+	re, err := regexp.Compile("^(?:" + m.Value + ")$")
+	if err != nil {
+		return fmt.Errorf("%w: %s", ErrInvalidMatcher, err)
+	}
+	m.re = re
+	return nil
+}
+
+// Matches returns whether a tag value matches a regexp matcher.
+func (m *TagMatcher) Matches(value string) (bool, error) {
+	if err := m.compile(); err != nil {
+		return false, err
+	}
+	return m.re.MatchString(value), nil
+}
+
+func (m *TagMatcher) String() string {
+	return m.Key + string(m.Type) + m.Value
+}
+
+// Cursor is the position of the last annotation of a page, annotations are
+// ordered by TimeEnd, Time and Id, all descending.
+type Cursor struct {
+	TimeEnd int64
+	Time    int64
+	Id      int64
+}
+
+// NextCursor returns the cursor of the page after items, or an empty string
+// if there are no more annotations.
+func NextCursor(items []*ItemDTO, limit int64) string {
+	if len(items) == 0 || int64(len(items)) < limit {
+		return ""
+	}
+	last := items[len(items)-1]
+	s := fmt.Sprintf("%d,%d,%d", last.TimeEnd, last.Time, last.Id)
+	return base64.RawURLEncoding.EncodeToString([]byte(s))
+}
+
+// ParseCursor parses a cursor returned by NextCursor.
+func ParseCursor(cursor string) (*Cursor, error) {
+	b, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	parts := strings.Split(string(b), ",")
+	if len(parts) != 3 {
+		return nil, ErrInvalidCursor
+	}
+
+	values := make([]int64, len(parts))
+	for i, part := range parts {
+		if values[i], err = strconv.ParseInt(part, 10, 64); err != nil {
+			return nil, ErrInvalidCursor
+		}
+	}
+	return &Cursor{TimeEnd: values[0], Time: values[1], Id: values[2]}, nil
+}
@@ -0,0 +1,55 @@
+package annotations
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseTagMatcher(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected TagMatcher
+	}{
+		{input: "env=prod", expected: TagMatcher{Key: "env", Value: "prod", Type: MatchEqual}},
+		{input: "env!=dev", expected: TagMatcher{Key: "env", Value: "dev", Type: MatchNotEqual}},
+		{input: "env=~prod|staging", expected: TagMatcher{Key: "env", Value: "prod|staging", Type: MatchRegexp}},
+		{input: " env = ", expected: TagMatcher{Key: "env", Value: "", Type: MatchEqual}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			m, err := ParseTagMatcher(tt.input)
+			require.NoError(t, err)
+			require.Equal(t, tt.expected.Key, m.Key)
+			require.Equal(t, tt.expected.Value, m.Value)
+			require.Equal(t, tt.expected.Type, m.Type)
+		})
+	}
+
+	for _, input := range []string{"env", "=prod", "env=~(prod"} {
+		_, err := ParseTagMatcher(input)
+		require.ErrorIs(t, err, ErrInvalidMatcher, input)
+	}
+
+	t.Run("regexp matches whole values", func(t *testing.T) {
+		m, err := ParseTagMatcher("env=~prod|stag.*")
+		require.NoError(t, err)
+		for value, expected := range map[string]bool{"prod": true, "staging": true, "production": false, "dev": false} {
+			ok, err := m.Matches(value)
+			require.NoError(t, err)
+			require.Equal(t, expected, ok, value)
+		}
+	})
+}
+
+func TestCursor(t *testing.T) {
+	items := []*ItemDTO{{Id: 2, Time: 20, TimeEnd: 30}, {Id: 1, Time: 10, TimeEnd: 10}}
+	require.Empty(t, NextCursor(items, 3), "last page")
+
+	cursor, err := ParseCursor(NextCursor(items, 2))
+	require.NoError(t, err)
+	require.Equal(t, &Cursor{TimeEnd: 10, Time: 10, Id: 1}, cursor)
+
+	_, err = ParseCursor("invalid")
+	require.ErrorIs(t, err, ErrInvalidCursor)
+}
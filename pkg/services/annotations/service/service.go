@@ -0,0 +1,54 @@
+// Package service sets up the annotations repository of the configured store.
+package service
+
+import (
+	"context"
+	"errors"
+
+	"github.com/grafana/grafana/pkg/infra/httpclient"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/annotations"
+	"github.com/grafana/grafana/pkg/services/annotations/loki"
+	"github.com/grafana/grafana/pkg/services/datasources"
+	"github.com/grafana/grafana/pkg/services/sqlstore"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+type Service struct {
+	SQLStore *sqlstore.SQLStore
+	log      log.Logger
+}
+
+// ProvideService replaces the database repository set up by the SQL store
+// with the composite repository, if alert state annotations are stored in
+// Loki. Annotations of users are always stored in the database, so that they
+// can be updated and deleted.
+func ProvideService(cfg *setting.Cfg, sqlStore *sqlstore.SQLStore, dataSources *datasources.Service,
+	httpClientProvider httpclient.Provider) *Service {
+	s := &Service{
+		SQLStore: sqlStore,
+		log:      log.New("annotations"),
+	}
+
+	if cfg.AnnotationStore == setting.AnnotationStoreComposite {
+		if cfg.AnnotationLoki.DataSourceUID == "" {
+			s.log.Warn("No Loki datasource configured for the composite annotation store, storing annotations in the database")
+			return s
+		}
+		lokiRepo := loki.NewRepository(cfg.AnnotationLoki, dataSources, httpClientProvider, s.dashboardIDByUID)
+		annotations.SetRepository(annotations.NewCompositeRepository(&sqlstore.SQLAnnotationRepo{}, lokiRepo))
+	}
+	return s
+}
+
+func (s *Service) dashboardIDByUID(ctx context.Context, orgID int64, uid string) (int64, error) {
+	query := models.GetDashboardQuery{OrgId: orgID, Uid: uid}
+	if err := s.SQLStore.GetDashboard(ctx, &query); err != nil {
+		if errors.Is(err, models.ErrDashboardNotFound) {
+			return 0, nil
+		}
+		return 0, err
+	}
+	return query.Result.Id, nil
+}
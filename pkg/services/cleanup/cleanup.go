@@ -49,6 +49,7 @@ func (srv *CleanUpService) Run(ctx context.Context) error {
 			srv.cleanUpTmpFiles()
 			srv.deleteExpiredSnapshots(ctx)
 			srv.deleteExpiredDashboardVersions(ctx)
+			srv.deleteExpiredTrashedDashboards(ctx)
 			srv.cleanUpOldAnnotations(ctxWithTimeout)
 			srv.expireOldUserInvites(ctx)
 			srv.deleteStaleShortURLs(ctx)
@@ -143,6 +144,15 @@ func (srv *CleanUpService) deleteExpiredDashboardVersions(ctx context.Context) {
 	}
 }
 
+func (srv *CleanUpService) deleteExpiredTrashedDashboards(ctx context.Context) {
+	cmd := models.DeleteExpiredTrashedDashboardsCommand{}
+	if err := bus.Dispatch(ctx, &cmd); err != nil {
+		srv.log.Error("Failed to delete expired dashboards from the recycle bin", "error", err.Error())
+	} else {
+		srv.log.Debug("Deleted expired dashboards from the recycle bin", "rows affected", cmd.DeletedRows)
+	}
+}
+
 func (srv *CleanUpService) deleteOldLoginAttempts(ctx context.Context) {
 	if srv.Cfg.DisableBruteForceLoginProtection {
 		return
@@ -24,6 +24,7 @@ func ProvideService(sqlStore *sqlstore.SQLStore, routeRegister routing.RouteRegi
 		pluginDashboardManager: pluginDashboardManager,
 		dashboardService:       dashboards.NewService(sqlStore),
 		libraryPanelService:    libraryPanelService,
+		schemaLoaderService:    schemaLoaderService,
 	}
 
 	dashboardImportAPI := api.New(s, quotaService, schemaLoaderService, pluginStore)
@@ -36,6 +37,7 @@ type ImportDashboardService struct {
 	pluginDashboardManager plugins.PluginDashboardManager
 	dashboardService       dashboards.DashboardService
 	libraryPanelService    librarypanels.Service
+	schemaLoaderService    *schemaloader.SchemaLoaderService
 }
 
 func (s *ImportDashboardService) ImportDashboard(ctx context.Context, req *dashboardimport.ImportDashboardRequest) (*dashboardimport.ImportDashboardResponse, error) {
@@ -55,6 +57,12 @@ func (s *ImportDashboardService) ImportDashboard(ctx context.Context, req *dashb
 		return nil, err
 	}
 
+	if s.schemaLoaderService != nil && s.schemaLoaderService.IsValidationEnabled() {
+		if generatedDash, err = s.schemaLoaderService.DashboardValidate(generatedDash); err != nil {
+			return nil, err
+		}
+	}
+
 	saveCmd := models.SaveDashboardCommand{
 		Dashboard: generatedDash,
 		OrgId:     req.User.OrgId,
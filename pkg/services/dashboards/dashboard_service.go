@@ -301,8 +301,9 @@ func (dr *dashboardServiceImpl) SaveDashboard(ctx context.Context, dto *SaveDash
 	return dash, nil
 }
 
-// DeleteDashboard removes dashboard from the DB. Errors out if the dashboard was provisioned. Should be used for
-// operations by the user where we want to make sure user does not delete provisioned dashboard.
+// DeleteDashboard removes dashboard from the DB, it is kept in the recycle bin when enabled. Errors out if the
+// dashboard was provisioned. Should be used for operations by the user where we want to make sure user does not
+// delete provisioned dashboard.
 func (dr *dashboardServiceImpl) DeleteDashboard(ctx context.Context, dashboardId int64, orgId int64) error {
 	return dr.deleteDashboard(ctx, dashboardId, orgId, true)
 }
@@ -323,7 +324,8 @@ func (dr *dashboardServiceImpl) deleteDashboard(ctx context.Context, dashboardId
 			return models.ErrDashboardCannotDeleteProvisionedDashboard
 		}
 	}
-	cmd := &models.DeleteDashboardCommand{OrgId: orgId, Id: dashboardId}
+	// Dashboards deleted by users are kept in the recycle bin, provisioned ones are deleted permanently.
+	cmd := &models.DeleteDashboardCommand{OrgId: orgId, Id: dashboardId, MoveToTrash: validateProvisionedDashboard}
 	return bus.Dispatch(ctx, cmd)
 }
 
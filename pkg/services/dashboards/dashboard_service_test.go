@@ -257,12 +257,14 @@ func TestDashboardService(t *testing.T) {
 				err := service.DeleteProvisionedDashboard(context.Background(), 1, 1)
 				require.NoError(t, err)
 				require.True(t, result.deleteWasCalled)
+				require.False(t, result.movedToTrash)
 			})
 
-			t.Run("DeleteDashboard should delete it", func(t *testing.T) {
+			t.Run("DeleteDashboard should move it to the trash", func(t *testing.T) {
 				err := service.DeleteDashboard(context.Background(), 1, 1)
 				require.NoError(t, err)
 				require.True(t, result.deleteWasCalled)
+				require.True(t, result.movedToTrash)
 			})
 		})
 	})
@@ -270,6 +272,7 @@ func TestDashboardService(t *testing.T) {
 
 type Result struct {
 	deleteWasCalled bool
+	movedToTrash    bool
 }
 
 func setupDeleteHandlers(t *testing.T, fakeStore *fakeDashboardStore, provisioned bool) *Result {
@@ -287,6 +290,7 @@ func setupDeleteHandlers(t *testing.T, fakeStore *fakeDashboardStore, provisione
 		require.Equal(t, cmd.Id, int64(1))
 		require.Equal(t, cmd.OrgId, int64(1))
 		result.deleteWasCalled = true
+		result.movedToTrash = cmd.MoveToTrash
 		return nil
 	})
 
@@ -0,0 +1,343 @@
+package usage
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/expr"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/sqlstore"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+// orgIndex maps datasource UIDs to their dependents in an organization.
+type orgIndex struct {
+	usage map[string]*Usage
+}
+
+func (idx *orgIndex) get(uid string) *Usage {
+	u, ok := idx.usage[uid]
+	if !ok {
+		u = &Usage{
+			Dashboards:    []*DashboardUsage{},
+			LibraryPanels: []*LibraryPanelUsage{},
+			AlertRules:    []*AlertRuleUsage{},
+		}
+		idx.usage[uid] = u
+	}
+	return u
+}
+
+// lookup returns the usage of a datasource.
+func (idx *orgIndex) lookup(uid string) *Usage {
+	if u, ok := idx.usage[uid]; ok {
+		return u
+	}
+	return &Usage{
+		Dashboards:    []*DashboardUsage{},
+		LibraryPanels: []*LibraryPanelUsage{},
+		AlertRules:    []*AlertRuleUsage{},
+	}
+}
+
+// indexState is the number and last update of the dashboards, library
+// elements, alert rules and datasources of an organization. Any change of them,
+// including changes made by other Grafana instances, changes the state.
+type indexState [4]tableState
+
+type tableState struct {
+	Count   int64
+	Updated int64
+}
+
+var indexedTables = [4]string{"dashboard", "library_element", "alert_rule", "data_source"}
+
+func (s *UsageService) getIndexState(ctx context.Context, orgID int64) (indexState, error) {
+	var state indexState
+	err := s.SQLStore.WithDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		for i, table := range indexedTables {
+			var row struct {
+				Count   int64
+				Updated time.Time
+			}
+			if _, err := sess.SQL("SELECT COUNT(*) AS count, MAX(updated) AS updated FROM "+table+" WHERE org_id = ?", orgID).Get(&row); err != nil {
+				return err
+			}
+			state[i] = tableState{Count: row.Count, Updated: row.Updated.Unix()}
+		}
+		return nil
+	})
+	if err != nil {
+		return state, fmt.Errorf("failed to get datasource usage index state: %w", err)
+	}
+	return state, nil
+}
+
+type dataSourceRow struct {
+	UID       string `xorm:"uid"`
+	Name      string
+	IsDefault bool
+}
+
+type libraryPanelRow struct {
+	UID      string `xorm:"uid"`
+	Name     string
+	FolderID int64 `xorm:"folder_id"`
+	Model    []byte
+}
+
+type alertRuleRow struct {
+	UID          string `xorm:"uid"`
+	Title        string
+	NamespaceUID string `xorm:"namespace_uid"`
+	NamespaceID  int64  `xorm:"namespace_id"`
+	RuleGroup    string
+	Data         string
+}
+
+func (s *UsageService) buildIndex(ctx context.Context, orgID int64) (*orgIndex, error) {
+	idx := &orgIndex{usage: map[string]*Usage{}}
+
+	err := s.SQLStore.WithDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		var dataSources []dataSourceRow
+		if err := sess.SQL("SELECT uid, name, is_default FROM data_source WHERE org_id = ?", orgID).Find(&dataSources); err != nil {
+			return err
+		}
+		resolver := newResolver(dataSources)
+
+		var libraryPanels []libraryPanelRow
+		if err := sess.SQL("SELECT uid, name, folder_id, model FROM library_element WHERE org_id = ? AND kind = ?",
+			orgID, models.PanelElement).Find(&libraryPanels); err != nil {
+			return err
+		}
+		// Dashboards contain references to library panels without their queries.
+		libraryPanelDataSources := map[string][]string{}
+		for _, p := range libraryPanels {
+			model, err := simplejson.NewJson(p.Model)
+			if err != nil {
+				s.log.Warn("Failed to parse library panel model", "orgId", orgID, "uid", p.UID, "error", err)
+				continue
+			}
+			uids := resolver.panelDataSources(model)
+			libraryPanelDataSources[p.UID] = uids
+			for _, uid := range uids {
+				u := idx.get(uid)
+				u.LibraryPanels = append(u.LibraryPanels, &LibraryPanelUsage{UID: p.UID, Name: p.Name, FolderID: p.FolderID})
+			}
+		}
+
+		err := sess.Table("dashboard").Where("org_id = ? AND is_folder = "+s.SQLStore.Dialect.BooleanStr(false), orgID).
+			Iterate(new(models.Dashboard), func(_ int, bean interface{}) error {
+				idx.addDashboard(resolver, libraryPanelDataSources, bean.(*models.Dashboard))
+				return nil
+			})
+		if err != nil {
+			return err
+		}
+
+		var rules []alertRuleRow
+		if err := sess.SQL(`SELECT alert_rule.uid, alert_rule.title, alert_rule.namespace_uid, alert_rule.rule_group, alert_rule.data,
+			COALESCE(dashboard.id, 0) AS namespace_id
+			FROM alert_rule LEFT JOIN dashboard ON dashboard.org_id = alert_rule.org_id AND dashboard.uid = alert_rule.namespace_uid
+			WHERE alert_rule.org_id = ?`, orgID).Find(&rules); err != nil {
+			return err
+		}
+		for _, r := range rules {
+			var queries []struct {
+				DatasourceUID string `json:"datasourceUid"`
+			}
+			if err := json.Unmarshal([]byte(r.Data), &queries); err != nil {
+				s.log.Warn("Failed to parse alert rule queries", "orgId", orgID, "uid", r.UID, "error", err)
+				continue
+			}
+			seen := map[string]bool{}
+			for _, q := range queries {
+				if q.DatasourceUID == "" || expr.IsDataSource(q.DatasourceUID) || seen[q.DatasourceUID] {
+					continue
+				}
+				seen[q.DatasourceUID] = true
+				u := idx.get(q.DatasourceUID)
+				u.AlertRules = append(u.AlertRules, &AlertRuleUsage{
+					UID:          r.UID,
+					Title:        r.Title,
+					URL:          fmt.Sprintf("%s/alerting/grafana/%s/view", setting.AppSubUrl, r.UID),
+					NamespaceUID: r.NamespaceUID,
+					RuleGroup:    r.RuleGroup,
+					namespaceID:  r.NamespaceID,
+				})
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to build datasource usage index: %w", err)
+	}
+
+	for _, u := range idx.usage {
+		sort.Slice(u.Dashboards, func(i, j int) bool {
+			return strings.ToLower(u.Dashboards[i].Title) < strings.ToLower(u.Dashboards[j].Title)
+		})
+		sort.Slice(u.LibraryPanels, func(i, j int) bool {
+			return strings.ToLower(u.LibraryPanels[i].Name) < strings.ToLower(u.LibraryPanels[j].Name)
+		})
+		sort.Slice(u.AlertRules, func(i, j int) bool {
+			return strings.ToLower(u.AlertRules[i].Title) < strings.ToLower(u.AlertRules[j].Title)
+		})
+	}
+	return idx, nil
+}
+
+func (idx *orgIndex) addDashboard(r *resolver, libraryPanelDataSources map[string][]string, dash *models.Dashboard) {
+	if dash.Data == nil {
+		return
+	}
+	url := models.GetDashboardUrl(dash.Uid, dash.Slug)
+	usages := map[string]*DashboardUsage{}
+	get := func(uid string) *DashboardUsage {
+		du, ok := usages[uid]
+		if !ok {
+			du = &DashboardUsage{
+				ID:        dash.Id,
+				UID:       dash.Uid,
+				Title:     dash.Title,
+				URL:       url,
+				Panels:    []*PanelUsage{},
+				Variables: []string{},
+			}
+			usages[uid] = du
+		}
+		return du
+	}
+
+	for _, p := range dashboardPanels(dash.Data) {
+		panel := simplejson.NewFromAny(p)
+		usage := &PanelUsage{
+			ID:    panel.Get("id").MustInt64(),
+			Title: panel.Get("title").MustString(),
+		}
+		usage.URL = fmt.Sprintf("%s?viewPanel=%d", url, usage.ID)
+		uids := r.panelDataSources(panel)
+		if libraryPanelUID := panel.Get("libraryPanel").Get("uid").MustString(); libraryPanelUID != "" {
+			usage.LibraryPanelUID = libraryPanelUID
+			uids = libraryPanelDataSources[libraryPanelUID]
+		}
+		for _, uid := range uids {
+			du := get(uid)
+			du.Panels = append(du.Panels, usage)
+		}
+	}
+
+	for _, v := range dash.Data.Get("templating").Get("list").MustArray() {
+		variable := simplejson.NewFromAny(v)
+		if variable.Get("type").MustString() != "query" {
+			continue
+		}
+		if uid, ok := r.resolve(variable.Get("datasource").Interface()); ok {
+			du := get(uid)
+			du.Variables = append(du.Variables, variable.Get("name").MustString())
+		}
+	}
+
+	for uid, du := range usages {
+		u := idx.get(uid)
+		u.Dashboards = append(u.Dashboards, du)
+	}
+}
+
+// dashboardPanels returns the panels of a dashboard including panels of
+// collapsed rows and of rows of dashboards before schema version 16.
+func dashboardPanels(data *simplejson.Json) []interface{} {
+	var panels []interface{}
+	for _, p := range data.Get("panels").MustArray() {
+		panels = append(panels, p)
+		panels = append(panels, simplejson.NewFromAny(p).Get("panels").MustArray()...)
+	}
+	for _, row := range data.Get("rows").MustArray() {
+		panels = append(panels, simplejson.NewFromAny(row).Get("panels").MustArray()...)
+	}
+	return panels
+}
+
+// resolver resolves datasource references of dashboards to datasource UIDs.
+type resolver struct {
+	uids       map[string]bool
+	nameToUID  map[string]string
+	defaultUID string
+}
+
+func newResolver(dataSources []dataSourceRow) *resolver {
+	r := &resolver{
+		uids:      map[string]bool{},
+		nameToUID: map[string]string{},
+	}
+	for _, ds := range dataSources {
+		r.uids[ds.UID] = true
+		r.nameToUID[ds.Name] = ds.UID
+		if ds.IsDefault {
+			r.defaultUID = ds.UID
+		}
+	}
+	return r
+}
+
+// resolve returns the UID of a datasource reference, which is an object with
+// a UID or a datasource name in older dashboards. A missing reference resolves
+// to the default datasource. References to template variables, mixed and
+// unknown datasources aren't resolved.
+func (r *resolver) resolve(ref interface{}) (string, bool) {
+	var ds string
+	switch v := ref.(type) {
+	case nil:
+		ds = r.defaultUID
+	case string:
+		ds = v
+	case map[string]interface{}:
+		ds, _ = v["uid"].(string)
+	}
+	if ds == "" || strings.HasPrefix(ds, "$") {
+		return "", false
+	}
+	if uid, ok := r.nameToUID[ds]; ok {
+		return uid, true
+	}
+	return ds, r.uids[ds]
+}
+
+// panelDataSources returns the UIDs of datasources queried by a panel. Queries
+// without a datasource use the datasource of the panel.
+func (r *resolver) panelDataSources(panel *simplejson.Json) []string {
+	if panel.Get("type").MustString() == "row" {
+		return nil
+	}
+	seen := map[string]bool{}
+	var uids []string
+	add := func(uid string, ok bool) {
+		if ok && !seen[uid] {
+			seen[uid] = true
+			uids = append(uids, uid)
+		}
+	}
+
+	panelRef := panel.Get("datasource").Interface()
+	panelUID, panelOK := r.resolve(panelRef)
+	targets := panel.Get("targets").MustArray()
+	// Panels without queries, such as text panels, don't use the default
+	// datasource.
+	if panelRef != nil && len(targets) == 0 {
+		add(panelUID, panelOK)
+	}
+	for _, t := range targets {
+		target := simplejson.NewFromAny(t)
+		if ref := target.Get("datasource").Interface(); ref != nil {
+			add(r.resolve(ref))
+			continue
+		}
+		add(panelUID, panelOK)
+	}
+	return uids
+}
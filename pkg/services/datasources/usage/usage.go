@@ -0,0 +1,278 @@
+// Package usage keeps track of the dashboards, library panels and alert rules
+// using each datasource, so that datasources aren't deleted or migrated without
+// knowing what depends on them.
+package usage
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/events"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/search"
+	"github.com/grafana/grafana/pkg/services/sqlstore"
+)
+
+// Usage lists the dependents of a datasource.
+type Usage struct {
+	Dashboards    []*DashboardUsage    `json:"dashboards"`
+	LibraryPanels []*LibraryPanelUsage `json:"libraryPanels"`
+	AlertRules    []*AlertRuleUsage    `json:"alertRules"`
+}
+
+// IsEmpty returns true if nothing uses the datasource.
+func (u *Usage) IsEmpty() bool {
+	return len(u.Dashboards) == 0 && len(u.LibraryPanels) == 0 && len(u.AlertRules) == 0
+}
+
+// DashboardUsage is a dashboard with panels or template variables querying
+// a datasource.
+type DashboardUsage struct {
+	ID        int64         `json:"id"`
+	UID       string        `json:"uid"`
+	Title     string        `json:"title"`
+	URL       string        `json:"url"`
+	Panels    []*PanelUsage `json:"panels"`
+	Variables []string      `json:"variables"`
+}
+
+// PanelUsage is a dashboard panel querying a datasource. LibraryPanelUID is set
+// for panels of library panels.
+type PanelUsage struct {
+	ID              int64  `json:"id"`
+	Title           string `json:"title"`
+	URL             string `json:"url"`
+	LibraryPanelUID string `json:"libraryPanelUid,omitempty"`
+}
+
+type LibraryPanelUsage struct {
+	UID      string `json:"uid"`
+	Name     string `json:"name"`
+	FolderID int64  `json:"folderId"`
+}
+
+type AlertRuleUsage struct {
+	UID          string `json:"uid"`
+	Title        string `json:"title"`
+	URL          string `json:"url"`
+	NamespaceUID string `json:"namespaceUid"`
+	RuleGroup    string `json:"ruleGroup"`
+
+	namespaceID int64
+}
+
+// Service returns the dependents of datasources.
+type Service interface {
+	// GetUsage returns the dependents of a datasource the user can view. The
+	// result is read from an index that follows changes of other Grafana
+	// instances with a small delay.
+	GetUsage(ctx context.Context, user *models.SignedInUser, datasourceUID string) (*Usage, error)
+	// GetStoredUsage reads the dependents of a datasource from the database,
+	// including those the user can't view.
+	GetStoredUsage(ctx context.Context, orgID int64, datasourceUID string) (*Usage, error)
+	// FilterUsage returns the dependents the user can view.
+	FilterUsage(ctx context.Context, user *models.SignedInUser, usage *Usage) (*Usage, error)
+}
+
+// UsageService indexes the datasources used by dashboards, library panels and
+// alert rules. The index of an organization is built when its usage is first
+// requested and is rebuilt when dashboards, library panels, alert rules or
+// datasources of the organization change, which is detected by comparing the
+// number and last update of each of them in the database.
+type UsageService struct {
+	SQLStore *sqlstore.SQLStore
+	log      log.Logger
+
+	mu   sync.Mutex
+	orgs map[int64]*orgEntry
+}
+
+// orgEntry holds the index of an organization, the mutex is held while the
+// index is built so that organizations are indexed independently.
+type orgEntry struct {
+	mu    sync.Mutex
+	index *orgIndex
+	state indexState
+}
+
+func ProvideService(bus bus.Bus, sqlStore *sqlstore.SQLStore) *UsageService {
+	s := &UsageService{
+		SQLStore: sqlStore,
+		log:      log.New("datasources.usage"),
+		orgs:     map[int64]*orgEntry{},
+	}
+
+	bus.AddEventListener(s.handleDashboardSaved)
+	bus.AddEventListener(s.handleDashboardDeleted)
+	bus.AddEventListener(s.handleDataSourceCreated)
+	bus.AddEventListener(s.handleDataSourceUpdated)
+	bus.AddEventListener(s.handleDataSourceDeleted)
+
+	return s
+}
+
+func (s *UsageService) GetUsage(ctx context.Context, user *models.SignedInUser, datasourceUID string) (*Usage, error) {
+	entry := s.org(user.OrgId)
+	entry.mu.Lock()
+	defer entry.mu.Unlock()
+
+	state, err := s.getIndexState(ctx, user.OrgId)
+	if err != nil {
+		return nil, err
+	}
+	if entry.index == nil || entry.state != state {
+		index, err := s.buildIndex(ctx, user.OrgId)
+		if err != nil {
+			return nil, err
+		}
+		entry.index, entry.state = index, state
+	}
+
+	return s.FilterUsage(ctx, user, entry.index.lookup(datasourceUID))
+}
+
+func (s *UsageService) GetStoredUsage(ctx context.Context, orgID int64, datasourceUID string) (*Usage, error) {
+	entry := s.org(orgID)
+	entry.mu.Lock()
+	defer entry.mu.Unlock()
+
+	state, err := s.getIndexState(ctx, orgID)
+	if err != nil {
+		return nil, err
+	}
+	index, err := s.buildIndex(ctx, orgID)
+	if err != nil {
+		return nil, err
+	}
+	entry.index, entry.state = index, state
+
+	return index.lookup(datasourceUID), nil
+}
+
+// FilterUsage keeps the dashboards the user can view, and the library panels
+// and alert rules in folders the user can view. Library panels in the General
+// folder are visible to everyone.
+func (s *UsageService) FilterUsage(ctx context.Context, user *models.SignedInUser, usage *Usage) (*Usage, error) {
+	var ids []int64
+	for _, d := range usage.Dashboards {
+		ids = append(ids, d.ID)
+	}
+	for _, p := range usage.LibraryPanels {
+		if p.FolderID != 0 {
+			ids = append(ids, p.FolderID)
+		}
+	}
+	for _, r := range usage.AlertRules {
+		if r.namespaceID != 0 {
+			ids = append(ids, r.namespaceID)
+		}
+	}
+
+	visible, err := s.visibleDashboards(ctx, user, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	filtered := &Usage{
+		Dashboards:    []*DashboardUsage{},
+		LibraryPanels: []*LibraryPanelUsage{},
+		AlertRules:    []*AlertRuleUsage{},
+	}
+	for _, d := range usage.Dashboards {
+		if visible[d.ID] {
+			filtered.Dashboards = append(filtered.Dashboards, d)
+		}
+	}
+	for _, p := range usage.LibraryPanels {
+		if p.FolderID == 0 || visible[p.FolderID] {
+			filtered.LibraryPanels = append(filtered.LibraryPanels, p)
+		}
+	}
+	for _, r := range usage.AlertRules {
+		if visible[r.namespaceID] {
+			filtered.AlertRules = append(filtered.AlertRules, r)
+		}
+	}
+	return filtered, nil
+}
+
+// visibleDashboards returns which of the dashboards and folders the user can
+// view. They are searched in batches to stay below the parameter limits of the
+// databases.
+func (s *UsageService) visibleDashboards(ctx context.Context, user *models.SignedInUser, ids []int64) (map[int64]bool, error) {
+	const batchSize = 500
+
+	visible := map[int64]bool{}
+	for len(ids) > 0 {
+		batch := ids
+		if len(batch) > batchSize {
+			batch = batch[:batchSize]
+		}
+		ids = ids[len(batch):]
+
+		query := &search.FindPersistedDashboardsQuery{
+			SignedInUser: user,
+			OrgId:        user.OrgId,
+			DashboardIds: batch,
+			Permission:   models.PERMISSION_VIEW,
+			Limit:        int64(len(batch)),
+		}
+		if err := s.SQLStore.SearchDashboards(ctx, query); err != nil {
+			return nil, fmt.Errorf("failed to check dashboard permissions: %w", err)
+		}
+		for _, hit := range query.Result {
+			visible[hit.ID] = true
+		}
+	}
+	return visible, nil
+}
+
+func (s *UsageService) org(orgID int64) *orgEntry {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entry, ok := s.orgs[orgID]
+	if !ok {
+		entry = &orgEntry{}
+		s.orgs[orgID] = entry
+	}
+	return entry
+}
+
+// invalidate drops the index of an organization, it is rebuilt on the next
+// request.
+func (s *UsageService) invalidate(orgID int64) {
+	entry := s.org(orgID)
+	entry.mu.Lock()
+	defer entry.mu.Unlock()
+	entry.index = nil
+}
+
+func (s *UsageService) handleDashboardSaved(_ context.Context, e *events.DashboardSaved) error {
+	s.invalidate(e.OrgID)
+	return nil
+}
+
+func (s *UsageService) handleDashboardDeleted(_ context.Context, e *events.DashboardDeleted) error {
+	s.invalidate(e.OrgID)
+	return nil
+}
+
+// Datasources referenced by name are resolved to UIDs when the index is built.
+func (s *UsageService) handleDataSourceCreated(_ context.Context, e *events.DataSourceCreated) error {
+	s.invalidate(e.OrgID)
+	return nil
+}
+
+func (s *UsageService) handleDataSourceUpdated(_ context.Context, e *events.DataSourceUpdated) error {
+	s.invalidate(e.OrgID)
+	return nil
+}
+
+func (s *UsageService) handleDataSourceDeleted(_ context.Context, e *events.DataSourceDeleted) error {
+	s.invalidate(e.OrgID)
+	return nil
+}
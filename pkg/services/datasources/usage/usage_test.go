@@ -0,0 +1,201 @@
+package usage
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/sqlstore"
+)
+
+func TestResolver(t *testing.T) {
+	r := newResolver([]dataSourceRow{
+		{UID: "prom", Name: "Prometheus", IsDefault: true},
+		{UID: "loki", Name: "Loki"},
+	})
+
+	for _, tc := range []struct {
+		desc string
+		ref  interface{}
+		uid  string
+		ok   bool
+	}{
+		{desc: "missing reference", ref: nil, uid: "prom", ok: true},
+		{desc: "uid reference", ref: map[string]interface{}{"uid": "loki", "type": "loki"}, uid: "loki", ok: true},
+		{desc: "name reference", ref: "Loki", uid: "loki", ok: true},
+		{desc: "uid string reference", ref: "loki", uid: "loki", ok: true},
+		{desc: "variable reference", ref: "${ds}", ok: false},
+		{desc: "variable uid reference", ref: map[string]interface{}{"uid": "$ds"}, ok: false},
+		{desc: "unknown reference", ref: "Elasticsearch", ok: false},
+	} {
+		t.Run(tc.desc, func(t *testing.T) {
+			uid, ok := r.resolve(tc.ref)
+			require.Equal(t, tc.ok, ok)
+			if ok {
+				require.Equal(t, tc.uid, uid)
+			}
+		})
+	}
+}
+
+func TestPanelDataSources(t *testing.T) {
+	r := newResolver([]dataSourceRow{
+		{UID: "prom", Name: "Prometheus", IsDefault: true},
+		{UID: "loki", Name: "Loki"},
+	})
+
+	for _, tc := range []struct {
+		desc  string
+		panel string
+		uids  []string
+	}{
+		{desc: "default datasource", panel: `{"targets": [{"refId": "A"}]}`, uids: []string{"prom"}},
+		{desc: "panel datasource", panel: `{"datasource": "Loki", "targets": [{"refId": "A"}]}`, uids: []string{"loki"}},
+		{desc: "mixed datasources", panel: `{"datasource": {"uid": "-- Mixed --"}, "targets": [{"datasource": {"uid": "loki"}}, {"datasource": {"uid": "prom"}}, {"datasource": "Loki"}]}`, uids: []string{"loki", "prom"}},
+		{desc: "panel without queries", panel: `{"type": "text"}`},
+		{desc: "panel without queries with a datasource", panel: `{"type": "news", "datasource": {"uid": "loki"}}`, uids: []string{"loki"}},
+		{desc: "row", panel: `{"type": "row", "datasource": {"uid": "loki"}}`},
+	} {
+		t.Run(tc.desc, func(t *testing.T) {
+			panel, err := simplejson.NewJson([]byte(tc.panel))
+			require.NoError(t, err)
+			require.Equal(t, tc.uids, r.panelDataSources(panel))
+		})
+	}
+}
+
+func TestGetUsage(t *testing.T) {
+	t.Cleanup(bus.ClearBusHandlers)
+	sqlStore := sqlstore.InitTestDB(t)
+	s := ProvideService(bus.GetBus(), sqlStore)
+	ctx := context.Background()
+
+	for _, cmd := range []*models.AddDataSourceCommand{
+		{OrgId: 1, Name: "Prometheus", Uid: "prom", Type: "prometheus", Access: models.DS_ACCESS_PROXY, IsDefault: true},
+		{OrgId: 1, Name: "Loki", Uid: "loki", Type: "loki", Access: models.DS_ACCESS_PROXY},
+	} {
+		require.NoError(t, sqlStore.AddDataSource(ctx, cmd))
+	}
+
+	folder, err := sqlStore.SaveDashboard(models.SaveDashboardCommand{
+		OrgId:    1,
+		IsFolder: true,
+		Dashboard: simplejson.NewFromAny(map[string]interface{}{
+			"uid":   "folder",
+			"title": "Alerts",
+		}),
+	})
+	require.NoError(t, err)
+
+	err = sqlStore.WithDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		if _, err := sess.Exec(`INSERT INTO library_element (org_id, folder_id, uid, name, kind, type, description, model, created, created_by, updated, updated_by, version)
+			VALUES (1, 0, 'logs', 'Logs', ?, 'logs', '', ?, ?, 1, ?, 1, 1)`,
+			models.PanelElement, `{"type": "logs", "datasource": {"uid": "loki"}, "targets": [{"refId": "A"}]}`,
+			"2021-01-01 00:00:00", "2021-01-01 00:00:00"); err != nil {
+			return err
+		}
+		_, err := sess.Exec(`INSERT INTO alert_rule (org_id, title, condition, data, updated, interval_seconds, version, uid, namespace_uid, rule_group)
+			VALUES (1, 'High error rate', 'B', ?, ?, 60, 1, 'rule', 'folder', 'group')`,
+			`[{"refId": "A", "datasourceUid": "loki"}, {"refId": "B", "datasourceUid": "-100"}]`, "2021-01-01 00:00:00")
+		return err
+	})
+	require.NoError(t, err)
+
+	dash, err := sqlStore.SaveDashboard(models.SaveDashboardCommand{
+		OrgId: 1,
+		Dashboard: simplejson.NewFromAny(map[string]interface{}{
+			"uid":   "services",
+			"title": "Services",
+			"panels": []interface{}{
+				map[string]interface{}{"id": 1, "title": "Requests", "targets": []interface{}{map[string]interface{}{"refId": "A"}}},
+				map[string]interface{}{"id": 2, "title": "Logs", "libraryPanel": map[string]interface{}{"uid": "logs", "name": "Logs"}},
+			},
+			"templating": map[string]interface{}{
+				"list": []interface{}{
+					map[string]interface{}{"name": "job", "type": "query", "datasource": "Prometheus"},
+					map[string]interface{}{"name": "interval", "type": "interval"},
+				},
+			},
+		}),
+	})
+	require.NoError(t, err)
+
+	admin := &models.SignedInUser{OrgId: 1, UserId: 1, OrgRole: models.ROLE_ADMIN}
+
+	t.Run("returns dashboards, library panels and alert rules", func(t *testing.T) {
+		u, err := s.GetUsage(ctx, admin, "prom")
+		require.NoError(t, err)
+		require.Len(t, u.Dashboards, 1)
+		require.Equal(t, "services", u.Dashboards[0].UID)
+		require.Equal(t, []*PanelUsage{{ID: 1, Title: "Requests", URL: "/d/services/services?viewPanel=1"}}, u.Dashboards[0].Panels)
+		require.Equal(t, []string{"job"}, u.Dashboards[0].Variables)
+		require.Empty(t, u.LibraryPanels)
+		require.Empty(t, u.AlertRules)
+
+		u, err = s.GetUsage(ctx, admin, "loki")
+		require.NoError(t, err)
+		require.Len(t, u.Dashboards, 1)
+		require.Equal(t, []*PanelUsage{{ID: 2, Title: "Logs", URL: "/d/services/services?viewPanel=2", LibraryPanelUID: "logs"}}, u.Dashboards[0].Panels)
+		require.Equal(t, []*LibraryPanelUsage{{UID: "logs", Name: "Logs"}}, u.LibraryPanels)
+		require.Len(t, u.AlertRules, 1)
+		require.Equal(t, "rule", u.AlertRules[0].UID)
+	})
+
+	t.Run("returns empty usage of unused datasources", func(t *testing.T) {
+		u, err := s.GetUsage(ctx, admin, "unknown")
+		require.NoError(t, err)
+		require.True(t, u.IsEmpty())
+		u, err = s.GetUsage(ctx, &models.SignedInUser{OrgId: 2, OrgRole: models.ROLE_ADMIN}, "prom")
+		require.NoError(t, err)
+		require.True(t, u.IsEmpty())
+	})
+
+	t.Run("filters dependents the user can't view", func(t *testing.T) {
+		require.NoError(t, sqlStore.UpdateDashboardACL(ctx, folder.Id, []*models.DashboardAcl{
+			{OrgID: 1, DashboardID: folder.Id, UserID: 1, Permission: models.PERMISSION_ADMIN, Created: folder.Created, Updated: folder.Updated},
+		}))
+
+		viewer := &models.SignedInUser{OrgId: 1, UserId: 2, OrgRole: models.ROLE_VIEWER}
+		u, err := s.GetUsage(ctx, viewer, "loki")
+		require.NoError(t, err)
+		require.Len(t, u.Dashboards, 1)
+		require.Len(t, u.LibraryPanels, 1)
+		require.Empty(t, u.AlertRules)
+
+		u, err = s.GetUsage(ctx, admin, "loki")
+		require.NoError(t, err)
+		require.Len(t, u.AlertRules, 1)
+	})
+
+	t.Run("rebuilds the index when other instances change dependents", func(t *testing.T) {
+		err := sqlStore.WithDbSession(ctx, func(sess *sqlstore.DBSession) error {
+			_, err := sess.Exec("DELETE FROM alert_rule WHERE uid = 'rule'")
+			return err
+		})
+		require.NoError(t, err)
+
+		u, err := s.GetUsage(ctx, admin, "loki")
+		require.NoError(t, err)
+		require.Empty(t, u.AlertRules)
+	})
+
+	t.Run("reads stored usage including hidden dependents", func(t *testing.T) {
+		u, err := s.GetStoredUsage(ctx, 1, "loki")
+		require.NoError(t, err)
+		require.Len(t, u.Dashboards, 1)
+		require.Len(t, u.LibraryPanels, 1)
+	})
+
+	t.Run("rebuilds the index when dashboards change", func(t *testing.T) {
+		err := sqlStore.DeleteDashboard(ctx, &models.DeleteDashboardCommand{OrgId: 1, Id: dash.Id})
+		require.NoError(t, err)
+
+		u, err := s.GetUsage(ctx, admin, "prom")
+		require.NoError(t, err)
+		require.True(t, u.IsEmpty())
+	})
+}
@@ -8,6 +8,11 @@ var (
 			Description: "Use cue schema to remove values that will be applied automatically",
 			State:       FeatureStateBeta,
 		},
+		{
+			Name:        "dashboardSchemaValidation",
+			Description: "Validate dashboards with the cue schema when they are saved, imported or provisioned",
+			State:       FeatureStateAlpha,
+		},
 		{
 			Name:        "envelopeEncryption",
 			Description: "encrypt secrets",
@@ -133,6 +138,11 @@ var (
 			Description: "Serves swagger UI",
 			State:       FeatureStateBeta,
 		},
+		{
+			Name:        "scheduledReports",
+			Description: "Email dashboards as PDF or PNG on a schedule",
+			State:       FeatureStateAlpha,
+		},
 		{
 			Name:        "featureHighlights",
 			Description: "Highlight Enterprise features",
@@ -7,6 +7,10 @@ const (
 	// Use cue schema to remove values that will be applied automatically
 	FlagTrimDefaults = "trimDefaults"
 
+	// FlagDashboardSchemaValidation
+	// Validate dashboards with the cue schema when they are saved, imported or provisioned
+	FlagDashboardSchemaValidation = "dashboardSchemaValidation"
+
 	// FlagEnvelopeEncryption
 	// encrypt secrets
 	FlagEnvelopeEncryption = "envelopeEncryption"
@@ -99,6 +103,10 @@ const (
 	// Serves swagger UI
 	FlagSwaggerUi = "swaggerUi"
 
+	// FlagScheduledReports
+	// Email dashboards as PDF or PNG on a schedule
+	FlagScheduledReports = "scheduledReports"
+
 	// FlagFeatureHighlights
 	// Highlight Enterprise features
 	FlagFeatureHighlights = "featureHighlights"
@@ -4,6 +4,8 @@ import (
 	"encoding/json"
 	"errors"
 	"time"
+
+	"github.com/grafana/grafana/pkg/models"
 )
 
 type LibraryConnectionKind int
@@ -148,7 +150,7 @@ var (
 	// errLibraryElementUnSupportedElementKind is an error for when the kind is unsupported.
 	errLibraryElementUnSupportedElementKind = errors.New("the element kind is not supported")
 	// ErrFolderHasConnectedLibraryElements is an error for when a user deletes a folder that contains connected library elements.
-	ErrFolderHasConnectedLibraryElements = errors.New("folder contains library elements that are linked in use")
+	ErrFolderHasConnectedLibraryElements = models.ErrFolderHasConnectedLibraryElements
 	// errLibraryElementInvalidUID is an error for when the uid of a library element is invalid
 	errLibraryElementInvalidUID = errors.New("uid contains illegal characters")
 	// errLibraryElementUIDTooLong is an error for when the uid of a library element is invalid
@@ -0,0 +1,25 @@
+package live
+
+import (
+	"errors"
+
+	"github.com/grafana/grafana/pkg/services/ngalert"
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+)
+
+// alertmanagerSender posts alerts of pipeline alert outputs into the embedded
+// Alertmanager of an organization.
+type alertmanagerSender struct {
+	alertNG *ngalert.AlertNG
+}
+
+func (s *alertmanagerSender) PutAlerts(orgID int64, alerts apimodels.PostableAlerts) error {
+	if s.alertNG == nil || s.alertNG.MultiOrgAlertmanager == nil {
+		return errors.New("unified alerting is disabled")
+	}
+	am, err := s.alertNG.MultiOrgAlertmanager.AlertmanagerFor(orgID)
+	if err != nil {
+		return err
+	}
+	return am.PutAlerts(alerts)
+}
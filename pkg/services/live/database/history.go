@@ -0,0 +1,131 @@
+package database
+
+import (
+	"context"
+	"time"
+
+	"github.com/grafana/grafana/pkg/services/live/history"
+	"github.com/grafana/grafana/pkg/services/sqlstore"
+)
+
+type liveHistoryMessage struct {
+	Id            int64  `xorm:"pk autoincr 'id'"`
+	OrgId         int64  `xorm:"'org_id'"`
+	Channel       string `xorm:"'channel'"`
+	MessageOffset uint64 `xorm:"'message_offset'"`
+	Data          string `xorm:"'data'"`
+	Published     time.Time
+}
+
+func (m liveHistoryMessage) TableName() string {
+	return "live_history"
+}
+
+// HistoryStorage keeps channel history in the Grafana database, shared between
+// Grafana server instances.
+type HistoryStorage struct {
+	store *sqlstore.SQLStore
+}
+
+// NewHistoryStorage creates a new HistoryStorage.
+func NewHistoryStorage(store *sqlstore.SQLStore) *HistoryStorage {
+	return &HistoryStorage{store: store}
+}
+
+// maxAddAttempts is how often adding a message is attempted, when concurrent
+// publications into the same channel take the same offset.
+const maxAddAttempts = 5
+
+// Add appends a message with the offset following the last message of the
+// channel. Concurrent publications into the same channel conflict on the unique
+// (org_id, channel, message_offset) index, the losing ones are retried with the
+// next offset.
+func (s *HistoryStorage) Add(ctx context.Context, orgID int64, channel string, data []byte, opts history.Options) (history.Message, error) {
+	var msg history.Message
+	var err error
+	for attempt := 0; attempt < maxAddAttempts; attempt++ {
+		msg, err = s.add(ctx, orgID, channel, data, opts)
+		if err == nil || !s.store.Dialect.IsUniqueConstraintViolation(err) {
+			return msg, err
+		}
+	}
+	return msg, err
+}
+
+func (s *HistoryStorage) add(ctx context.Context, orgID int64, channel string, data []byte, opts history.Options) (history.Message, error) {
+	var msg history.Message
+	err := s.store.WithTransactionalDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		var last liveHistoryMessage
+		ok, err := sess.Where("org_id=? AND channel=?", orgID, channel).Desc("message_offset").Limit(1).Get(&last)
+		if err != nil {
+			return err
+		}
+		offset := uint64(1)
+		if ok {
+			offset = last.MessageOffset + 1
+		}
+
+		row := liveHistoryMessage{
+			OrgId:         orgID,
+			Channel:       channel,
+			MessageOffset: offset,
+			Data:          string(data),
+			Published:     time.Now(),
+		}
+		if _, err := sess.Insert(&row); err != nil {
+			return err
+		}
+
+		// The last message is never removed, so that offsets keep increasing.
+		deleteQuery := "org_id=? AND channel=? AND message_offset<?"
+		args := []interface{}{orgID, channel, offset}
+		if offset > uint64(opts.Size) {
+			deleteQuery += " AND (message_offset<=? OR published<?)"
+			args = append(args, offset-uint64(opts.Size), expiredBefore(row.Published, opts))
+		} else {
+			deleteQuery += " AND published<?"
+			args = append(args, expiredBefore(row.Published, opts))
+		}
+		if _, err := sess.Where(deleteQuery, args...).Delete(&liveHistoryMessage{}); err != nil {
+			return err
+		}
+
+		msg = history.Message{
+			Offset: offset,
+			Time:   row.Published,
+			Data:   data,
+		}
+		return nil
+	})
+	return msg, err
+}
+
+func (s *HistoryStorage) Since(ctx context.Context, orgID int64, channel string, since uint64, opts history.Options) ([]history.Message, error) {
+	var rows []liveHistoryMessage
+	err := s.store.WithDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		return sess.Where("org_id=? AND channel=? AND message_offset>? AND published>=?",
+			orgID, channel, since, expiredBefore(time.Now(), opts)).
+			Desc("message_offset").Limit(opts.Size).Find(&rows)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	messages := make([]history.Message, 0, len(rows))
+	for i := len(rows) - 1; i >= 0; i-- {
+		messages = append(messages, history.Message{
+			Offset: rows[i].MessageOffset,
+			Time:   rows[i].Published,
+			Data:   []byte(rows[i].Data),
+		})
+	}
+	return messages, nil
+}
+
+// expiredBefore returns the time messages published before have expired at now.
+func expiredBefore(now time.Time, opts history.Options) time.Time {
+	if opts.TTL <= 0 {
+		return time.Time{}
+	}
+	return now.Add(-opts.TTL)
+}
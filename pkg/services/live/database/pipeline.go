@@ -0,0 +1,553 @@
+package database
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/grafana/grafana/pkg/services/live/pipeline"
+	"github.com/grafana/grafana/pkg/services/secrets"
+	"github.com/grafana/grafana/pkg/services/sqlstore"
+	"github.com/grafana/grafana/pkg/util"
+)
+
+type liveChannelRule struct {
+	Id        int64  `xorm:"pk autoincr 'id'"`
+	OrgId     int64  `xorm:"'org_id'"`
+	Pattern   string `xorm:"'pattern'"`
+	Settings  string `xorm:"'settings'"`
+	Version   int64  `xorm:"'version'"`
+	Created   time.Time
+	Updated   time.Time
+	CreatedBy int64 `xorm:"'created_by'"`
+	UpdatedBy int64 `xorm:"'updated_by'"`
+}
+
+func (r liveChannelRule) TableName() string {
+	return "live_channel_rule"
+}
+
+func (r liveChannelRule) toChannelRule() (pipeline.ChannelRule, error) {
+	rule := pipeline.ChannelRule{
+		OrgId:     r.OrgId,
+		Pattern:   r.Pattern,
+		Version:   r.Version,
+		Created:   &r.Created,
+		Updated:   &r.Updated,
+		CreatedBy: r.CreatedBy,
+		UpdatedBy: r.UpdatedBy,
+	}
+	if err := json.Unmarshal([]byte(r.Settings), &rule.Settings); err != nil {
+		return rule, fmt.Errorf("can't unmarshal channel rule %s settings: %w", r.Pattern, err)
+	}
+	return rule, nil
+}
+
+type liveWriteConfig struct {
+	Id             int64  `xorm:"pk autoincr 'id'"`
+	OrgId          int64  `xorm:"'org_id'"`
+	UID            string `xorm:"'uid'"`
+	Settings       string `xorm:"'settings'"`
+	SecureSettings string `xorm:"'secure_settings'"`
+	Version        int64  `xorm:"'version'"`
+	Created        time.Time
+	Updated        time.Time
+	CreatedBy      int64 `xorm:"'created_by'"`
+	UpdatedBy      int64 `xorm:"'updated_by'"`
+}
+
+func (c liveWriteConfig) TableName() string {
+	return "live_write_config"
+}
+
+func (c liveWriteConfig) toWriteConfig() (pipeline.WriteConfig, error) {
+	writeConfig := pipeline.WriteConfig{
+		OrgId:     c.OrgId,
+		UID:       c.UID,
+		Version:   c.Version,
+		Created:   &c.Created,
+		Updated:   &c.Updated,
+		CreatedBy: c.CreatedBy,
+		UpdatedBy: c.UpdatedBy,
+	}
+	if err := json.Unmarshal([]byte(c.Settings), &writeConfig.Settings); err != nil {
+		return writeConfig, fmt.Errorf("can't unmarshal write config %s settings: %w", c.UID, err)
+	}
+	if c.SecureSettings != "" {
+		if err := json.Unmarshal([]byte(c.SecureSettings), &writeConfig.SecureSettings); err != nil {
+			return writeConfig, fmt.Errorf("can't unmarshal write config %s secure settings: %w", c.UID, err)
+		}
+	}
+	return writeConfig, nil
+}
+
+// Kinds of entities in live_pipeline_version table.
+const (
+	pipelineKindChannelRule = "channel_rule"
+	pipelineKindWriteConfig = "write_config"
+)
+
+type livePipelineVersion struct {
+	Id        int64  `xorm:"pk autoincr 'id'"`
+	OrgId     int64  `xorm:"'org_id'"`
+	Kind      string `xorm:"'kind'"`
+	Name      string `xorm:"'name'"`
+	Version   int64  `xorm:"'version'"`
+	Action    string `xorm:"'action'"`
+	Data      string `xorm:"'data'"`
+	Created   time.Time
+	CreatedBy int64 `xorm:"'created_by'"`
+}
+
+func (v livePipelineVersion) TableName() string {
+	return "live_pipeline_version"
+}
+
+// PipelineStorage keeps Live pipeline channel rules and write configs in the
+// Grafana database, shared between Grafana server instances. Every change
+// increments the entity version and is recorded in the version history.
+type PipelineStorage struct {
+	store          *sqlstore.SQLStore
+	secretsService secrets.Service
+}
+
+// NewPipelineStorage creates a new PipelineStorage.
+func NewPipelineStorage(store *sqlstore.SQLStore, secretsService secrets.Service) *PipelineStorage {
+	return &PipelineStorage{store: store, secretsService: secretsService}
+}
+
+func (s *PipelineStorage) ListChannelRules(ctx context.Context, orgID int64) ([]pipeline.ChannelRule, error) {
+	var rows []liveChannelRule
+	err := s.store.WithDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		return sess.Where("org_id=?", orgID).Asc("pattern").Find(&rows)
+	})
+	if err != nil {
+		return nil, err
+	}
+	rules := make([]pipeline.ChannelRule, 0, len(rows))
+	for _, row := range rows {
+		rule, err := row.toChannelRule()
+		if err != nil {
+			return nil, err
+		}
+		rules = append(rules, rule)
+	}
+	return rules, nil
+}
+
+func (s *PipelineStorage) CreateChannelRule(ctx context.Context, orgID int64, cmd pipeline.ChannelRuleCreateCmd) (pipeline.ChannelRule, error) {
+	var result pipeline.ChannelRule
+	err := s.store.WithTransactionalDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		var err error
+		result, err = s.createChannelRule(sess, orgID, cmd.Pattern, cmd.Settings, cmd.UserID)
+		return err
+	})
+	return result, err
+}
+
+func (s *PipelineStorage) createChannelRule(sess *sqlstore.DBSession, orgID int64, pattern string, settings pipeline.ChannelRuleSettings, userID int64) (pipeline.ChannelRule, error) {
+	rule := pipeline.ChannelRule{OrgId: orgID, Pattern: pattern, Settings: settings}
+	ok, reason := rule.Valid()
+	if !ok {
+		return rule, fmt.Errorf("invalid channel rule: %s", reason)
+	}
+	ok, err := sess.Exist(&liveChannelRule{OrgId: orgID, Pattern: pattern})
+	if err != nil {
+		return rule, err
+	}
+	if ok {
+		return rule, fmt.Errorf("pattern already exists in org: %s", pattern)
+	}
+	if err := checkChannelRules(sess, orgID, rule); err != nil {
+		return rule, err
+	}
+
+	data, err := json.Marshal(settings)
+	if err != nil {
+		return rule, err
+	}
+	// Versions continue after a rule with the same pattern was deleted, so
+	// that the history stays ordered.
+	version, err := lastVersion(sess, orgID, pipelineKindChannelRule, pattern)
+	if err != nil {
+		return rule, err
+	}
+	now := time.Now()
+	row := liveChannelRule{
+		OrgId:     orgID,
+		Pattern:   pattern,
+		Settings:  string(data),
+		Version:   version + 1,
+		Created:   now,
+		Updated:   now,
+		CreatedBy: userID,
+		UpdatedBy: userID,
+	}
+	if _, err := sess.Insert(&row); err != nil {
+		return rule, err
+	}
+	if err := addVersion(sess, orgID, pipelineKindChannelRule, pattern, row.Version, pipeline.VersionActionCreate, row.Settings, userID, now); err != nil {
+		return rule, err
+	}
+	return row.toChannelRule()
+}
+
+// UpdateChannelRule updates a channel rule, creating it if it does not exist.
+func (s *PipelineStorage) UpdateChannelRule(ctx context.Context, orgID int64, cmd pipeline.ChannelRuleUpdateCmd) (pipeline.ChannelRule, error) {
+	var result pipeline.ChannelRule
+	err := s.store.WithTransactionalDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		var existing liveChannelRule
+		ok, err := sess.Where("org_id=? AND pattern=?", orgID, cmd.Pattern).Get(&existing)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			if cmd.Version != 0 {
+				return pipeline.ErrChannelRuleNotFound
+			}
+			result, err = s.createChannelRule(sess, orgID, cmd.Pattern, cmd.Settings, cmd.UserID)
+			return err
+		}
+		if cmd.Version != 0 && cmd.Version != existing.Version {
+			return pipeline.ErrVersionConflict
+		}
+
+		rule := pipeline.ChannelRule{OrgId: orgID, Pattern: cmd.Pattern, Settings: cmd.Settings}
+		ok, reason := rule.Valid()
+		if !ok {
+			return fmt.Errorf("invalid channel rule: %s", reason)
+		}
+		data, err := json.Marshal(cmd.Settings)
+		if err != nil {
+			return err
+		}
+		now := time.Now()
+		row := existing
+		row.Settings = string(data)
+		row.Version = existing.Version + 1
+		row.Updated = now
+		row.UpdatedBy = cmd.UserID
+		// Checking the version again in the update protects from concurrent
+		// transactions which read the same version.
+		affected, err := sess.Where("id=? AND version=?", existing.Id, existing.Version).
+			Cols("settings", "version", "updated", "updated_by").Update(&row)
+		if err != nil {
+			return err
+		}
+		if affected == 0 {
+			return pipeline.ErrVersionConflict
+		}
+		if err := addVersion(sess, orgID, pipelineKindChannelRule, cmd.Pattern, row.Version, pipeline.VersionActionUpdate, row.Settings, cmd.UserID, now); err != nil {
+			return err
+		}
+		result, err = row.toChannelRule()
+		return err
+	})
+	return result, err
+}
+
+func (s *PipelineStorage) DeleteChannelRule(ctx context.Context, orgID int64, cmd pipeline.ChannelRuleDeleteCmd) error {
+	return s.store.WithTransactionalDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		var existing liveChannelRule
+		ok, err := sess.Where("org_id=? AND pattern=?", orgID, cmd.Pattern).Get(&existing)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return pipeline.ErrChannelRuleNotFound
+		}
+		if cmd.Version != 0 && cmd.Version != existing.Version {
+			return pipeline.ErrVersionConflict
+		}
+		affected, err := sess.Where("id=? AND version=?", existing.Id, existing.Version).Delete(&liveChannelRule{})
+		if err != nil {
+			return err
+		}
+		if affected == 0 {
+			return pipeline.ErrVersionConflict
+		}
+		return addVersion(sess, orgID, pipelineKindChannelRule, cmd.Pattern, existing.Version+1, pipeline.VersionActionDelete, existing.Settings, cmd.UserID, time.Now())
+	})
+}
+
+func (s *PipelineStorage) ListChannelRuleVersions(ctx context.Context, orgID int64, cmd pipeline.ChannelRuleVersionsCmd) ([]pipeline.ChannelRuleVersion, error) {
+	rows, err := s.listVersions(ctx, orgID, pipelineKindChannelRule, cmd.Pattern)
+	if err != nil {
+		return nil, err
+	}
+	versions := make([]pipeline.ChannelRuleVersion, 0, len(rows))
+	for _, row := range rows {
+		v := pipeline.ChannelRuleVersion{
+			Pattern:   row.Name,
+			Version:   row.Version,
+			Action:    row.Action,
+			Created:   row.Created,
+			CreatedBy: row.CreatedBy,
+		}
+		if err := json.Unmarshal([]byte(row.Data), &v.Settings); err != nil {
+			return nil, fmt.Errorf("can't unmarshal channel rule %s version %d: %w", row.Name, row.Version, err)
+		}
+		versions = append(versions, v)
+	}
+	return versions, nil
+}
+
+func (s *PipelineStorage) ListWriteConfigs(ctx context.Context, orgID int64) ([]pipeline.WriteConfig, error) {
+	var rows []liveWriteConfig
+	err := s.store.WithDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		return sess.Where("org_id=?", orgID).Asc("uid").Find(&rows)
+	})
+	if err != nil {
+		return nil, err
+	}
+	writeConfigs := make([]pipeline.WriteConfig, 0, len(rows))
+	for _, row := range rows {
+		writeConfig, err := row.toWriteConfig()
+		if err != nil {
+			return nil, err
+		}
+		writeConfigs = append(writeConfigs, writeConfig)
+	}
+	return writeConfigs, nil
+}
+
+func (s *PipelineStorage) GetWriteConfig(ctx context.Context, orgID int64, cmd pipeline.WriteConfigGetCmd) (pipeline.WriteConfig, bool, error) {
+	var row liveWriteConfig
+	var ok bool
+	err := s.store.WithDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		var err error
+		ok, err = sess.Where("org_id=? AND uid=?", orgID, cmd.UID).Get(&row)
+		return err
+	})
+	if err != nil || !ok {
+		return pipeline.WriteConfig{}, false, err
+	}
+	writeConfig, err := row.toWriteConfig()
+	return writeConfig, err == nil, err
+}
+
+func (s *PipelineStorage) CreateWriteConfig(ctx context.Context, orgID int64, cmd pipeline.WriteConfigCreateCmd) (pipeline.WriteConfig, error) {
+	if cmd.UID == "" {
+		cmd.UID = util.GenerateShortUID()
+	}
+	secureSettings, err := s.encryptSecureSettings(ctx, cmd.SecureSettings)
+	if err != nil {
+		return pipeline.WriteConfig{}, err
+	}
+	var result pipeline.WriteConfig
+	err = s.store.WithTransactionalDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		var err error
+		result, err = s.createWriteConfig(sess, orgID, cmd.UID, cmd.Settings, secureSettings, cmd.UserID)
+		return err
+	})
+	return result, err
+}
+
+func (s *PipelineStorage) createWriteConfig(sess *sqlstore.DBSession, orgID int64, uid string, settings pipeline.WriteSettings, secureSettings string, userID int64) (pipeline.WriteConfig, error) {
+	writeConfig := pipeline.WriteConfig{OrgId: orgID, UID: uid, Settings: settings}
+	ok, reason := writeConfig.Valid()
+	if !ok {
+		return writeConfig, fmt.Errorf("invalid write config: %s", reason)
+	}
+	ok, err := sess.Exist(&liveWriteConfig{OrgId: orgID, UID: uid})
+	if err != nil {
+		return writeConfig, err
+	}
+	if ok {
+		return writeConfig, fmt.Errorf("write config already exists in org: %s", uid)
+	}
+
+	data, err := json.Marshal(settings)
+	if err != nil {
+		return writeConfig, err
+	}
+	version, err := lastVersion(sess, orgID, pipelineKindWriteConfig, uid)
+	if err != nil {
+		return writeConfig, err
+	}
+	now := time.Now()
+	row := liveWriteConfig{
+		OrgId:          orgID,
+		UID:            uid,
+		Settings:       string(data),
+		SecureSettings: secureSettings,
+		Version:        version + 1,
+		Created:        now,
+		Updated:        now,
+		CreatedBy:      userID,
+		UpdatedBy:      userID,
+	}
+	if _, err := sess.Insert(&row); err != nil {
+		return writeConfig, err
+	}
+	if err := addVersion(sess, orgID, pipelineKindWriteConfig, uid, row.Version, pipeline.VersionActionCreate, row.Settings, userID, now); err != nil {
+		return writeConfig, err
+	}
+	return row.toWriteConfig()
+}
+
+// UpdateWriteConfig updates a write config, creating it if it does not exist.
+// Secure settings are replaced with the ones in the command.
+func (s *PipelineStorage) UpdateWriteConfig(ctx context.Context, orgID int64, cmd pipeline.WriteConfigUpdateCmd) (pipeline.WriteConfig, error) {
+	secureSettings, err := s.encryptSecureSettings(ctx, cmd.SecureSettings)
+	if err != nil {
+		return pipeline.WriteConfig{}, err
+	}
+	var result pipeline.WriteConfig
+	err = s.store.WithTransactionalDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		var existing liveWriteConfig
+		ok, err := sess.Where("org_id=? AND uid=?", orgID, cmd.UID).Get(&existing)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			if cmd.Version != 0 {
+				return pipeline.ErrWriteConfigNotFound
+			}
+			result, err = s.createWriteConfig(sess, orgID, cmd.UID, cmd.Settings, secureSettings, cmd.UserID)
+			return err
+		}
+		if cmd.Version != 0 && cmd.Version != existing.Version {
+			return pipeline.ErrVersionConflict
+		}
+
+		writeConfig := pipeline.WriteConfig{OrgId: orgID, UID: cmd.UID, Settings: cmd.Settings}
+		ok, reason := writeConfig.Valid()
+		if !ok {
+			return fmt.Errorf("invalid write config: %s", reason)
+		}
+		data, err := json.Marshal(cmd.Settings)
+		if err != nil {
+			return err
+		}
+		now := time.Now()
+		row := existing
+		row.Settings = string(data)
+		row.SecureSettings = secureSettings
+		row.Version = existing.Version + 1
+		row.Updated = now
+		row.UpdatedBy = cmd.UserID
+		affected, err := sess.Where("id=? AND version=?", existing.Id, existing.Version).
+			Cols("settings", "secure_settings", "version", "updated", "updated_by").Update(&row)
+		if err != nil {
+			return err
+		}
+		if affected == 0 {
+			return pipeline.ErrVersionConflict
+		}
+		if err := addVersion(sess, orgID, pipelineKindWriteConfig, cmd.UID, row.Version, pipeline.VersionActionUpdate, row.Settings, cmd.UserID, now); err != nil {
+			return err
+		}
+		result, err = row.toWriteConfig()
+		return err
+	})
+	return result, err
+}
+
+func (s *PipelineStorage) DeleteWriteConfig(ctx context.Context, orgID int64, cmd pipeline.WriteConfigDeleteCmd) error {
+	return s.store.WithTransactionalDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		var existing liveWriteConfig
+		ok, err := sess.Where("org_id=? AND uid=?", orgID, cmd.UID).Get(&existing)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return pipeline.ErrWriteConfigNotFound
+		}
+		if cmd.Version != 0 && cmd.Version != existing.Version {
+			return pipeline.ErrVersionConflict
+		}
+		affected, err := sess.Where("id=? AND version=?", existing.Id, existing.Version).Delete(&liveWriteConfig{})
+		if err != nil {
+			return err
+		}
+		if affected == 0 {
+			return pipeline.ErrVersionConflict
+		}
+		return addVersion(sess, orgID, pipelineKindWriteConfig, cmd.UID, existing.Version+1, pipeline.VersionActionDelete, existing.Settings, cmd.UserID, time.Now())
+	})
+}
+
+func (s *PipelineStorage) ListWriteConfigVersions(ctx context.Context, orgID int64, cmd pipeline.WriteConfigVersionsCmd) ([]pipeline.WriteConfigVersion, error) {
+	rows, err := s.listVersions(ctx, orgID, pipelineKindWriteConfig, cmd.UID)
+	if err != nil {
+		return nil, err
+	}
+	versions := make([]pipeline.WriteConfigVersion, 0, len(rows))
+	for _, row := range rows {
+		v := pipeline.WriteConfigVersion{
+			UID:       row.Name,
+			Version:   row.Version,
+			Action:    row.Action,
+			Created:   row.Created,
+			CreatedBy: row.CreatedBy,
+		}
+		if err := json.Unmarshal([]byte(row.Data), &v.Settings); err != nil {
+			return nil, fmt.Errorf("can't unmarshal write config %s version %d: %w", row.Name, row.Version, err)
+		}
+		versions = append(versions, v)
+	}
+	return versions, nil
+}
+
+func (s *PipelineStorage) encryptSecureSettings(ctx context.Context, secureSettings map[string]string) (string, error) {
+	encrypted, err := s.secretsService.EncryptJsonData(ctx, secureSettings, secrets.WithoutScope())
+	if err != nil {
+		return "", fmt.Errorf("error encrypting data: %w", err)
+	}
+	data, err := json.Marshal(encrypted)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// listVersions returns versions of an entity, the latest first.
+func (s *PipelineStorage) listVersions(ctx context.Context, orgID int64, kind string, name string) ([]livePipelineVersion, error) {
+	var rows []livePipelineVersion
+	err := s.store.WithDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		return sess.Where("org_id=? AND kind=? AND name=?", orgID, kind, name).Desc("version").Find(&rows)
+	})
+	return rows, err
+}
+
+// checkChannelRules checks that a new rule does not conflict with existing
+// rules of the organization.
+func checkChannelRules(sess *sqlstore.DBSession, orgID int64, rule pipeline.ChannelRule) error {
+	var patterns []string
+	if err := sess.Table(&liveChannelRule{}).Where("org_id=?", orgID).Cols("pattern").Find(&patterns); err != nil {
+		return err
+	}
+	rules := make([]pipeline.ChannelRule, 0, len(patterns)+1)
+	for _, p := range patterns {
+		rules = append(rules, pipeline.ChannelRule{OrgId: orgID, Pattern: p})
+	}
+	rules = append(rules, rule)
+	ok, reason := pipeline.CheckRulesValid(orgID, rules)
+	if !ok {
+		return errors.New(reason)
+	}
+	return nil
+}
+
+func lastVersion(sess *sqlstore.DBSession, orgID int64, kind string, name string) (int64, error) {
+	var last livePipelineVersion
+	_, err := sess.Where("org_id=? AND kind=? AND name=?", orgID, kind, name).Desc("version").Limit(1).Get(&last)
+	return last.Version, err
+}
+
+func addVersion(sess *sqlstore.DBSession, orgID int64, kind string, name string, version int64, action string, data string, userID int64, created time.Time) error {
+	_, err := sess.Insert(&livePipelineVersion{
+		OrgId:     orgID,
+		Kind:      kind,
+		Name:      name,
+		Version:   version,
+		Action:    action,
+		Data:      data,
+		Created:   created,
+		CreatedBy: userID,
+	})
+	return err
+}
@@ -0,0 +1,156 @@
+//go:build integration
+// +build integration
+
+package tests
+
+import (
+	"context"
+	"testing"
+
+	"github.com/grafana/grafana/pkg/services/live/pipeline"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPipelineStorage_ChannelRules(t *testing.T) {
+	storage := SetupTestPipelineStorage(t)
+	ctx := context.Background()
+
+	rule, err := storage.CreateChannelRule(ctx, 1, pipeline.ChannelRuleCreateCmd{
+		Pattern: "stream/test/:name",
+		Settings: pipeline.ChannelRuleSettings{
+			Converter: &pipeline.ConverterConfig{Type: pipeline.ConverterTypeJsonAuto},
+		},
+		UserID: 10,
+	})
+	require.NoError(t, err)
+	require.Equal(t, int64(1), rule.Version)
+	require.Equal(t, int64(10), rule.CreatedBy)
+	require.NotNil(t, rule.Created)
+
+	_, err = storage.CreateChannelRule(ctx, 1, pipeline.ChannelRuleCreateCmd{Pattern: "stream/test/:name"})
+	require.Error(t, err)
+
+	rules, err := storage.ListChannelRules(ctx, 1)
+	require.NoError(t, err)
+	require.Len(t, rules, 1)
+	require.Equal(t, pipeline.ConverterTypeJsonAuto, rules[0].Settings.Converter.Type)
+
+	rules, err = storage.ListChannelRules(ctx, 2)
+	require.NoError(t, err)
+	require.Len(t, rules, 0)
+
+	rule, err = storage.UpdateChannelRule(ctx, 1, pipeline.ChannelRuleUpdateCmd{
+		Pattern: "stream/test/:name",
+		Settings: pipeline.ChannelRuleSettings{
+			Converter: &pipeline.ConverterConfig{Type: pipeline.ConverterTypeInfluxAuto},
+		},
+		Version: 1,
+		UserID:  20,
+	})
+	require.NoError(t, err)
+	require.Equal(t, int64(2), rule.Version)
+	require.Equal(t, int64(10), rule.CreatedBy)
+	require.Equal(t, int64(20), rule.UpdatedBy)
+
+	_, err = storage.UpdateChannelRule(ctx, 1, pipeline.ChannelRuleUpdateCmd{
+		Pattern: "stream/test/:name",
+		Version: 1,
+	})
+	require.ErrorIs(t, err, pipeline.ErrVersionConflict)
+
+	err = storage.DeleteChannelRule(ctx, 1, pipeline.ChannelRuleDeleteCmd{Pattern: "stream/test/:name", Version: 1})
+	require.ErrorIs(t, err, pipeline.ErrVersionConflict)
+	err = storage.DeleteChannelRule(ctx, 1, pipeline.ChannelRuleDeleteCmd{Pattern: "stream/test/:name", Version: 2, UserID: 30})
+	require.NoError(t, err)
+	err = storage.DeleteChannelRule(ctx, 1, pipeline.ChannelRuleDeleteCmd{Pattern: "stream/test/:name"})
+	require.ErrorIs(t, err, pipeline.ErrChannelRuleNotFound)
+
+	// Versions continue after the rule is created again.
+	rule, err = storage.UpdateChannelRule(ctx, 1, pipeline.ChannelRuleUpdateCmd{Pattern: "stream/test/:name", UserID: 40})
+	require.NoError(t, err)
+	require.Equal(t, int64(4), rule.Version)
+
+	versions, err := storage.ListChannelRuleVersions(ctx, 1, pipeline.ChannelRuleVersionsCmd{Pattern: "stream/test/:name"})
+	require.NoError(t, err)
+	require.Len(t, versions, 4)
+	var actions []string
+	var users []int64
+	for _, v := range versions {
+		actions = append(actions, v.Action)
+		users = append(users, v.CreatedBy)
+	}
+	require.Equal(t, []string{"create", "delete", "update", "create"}, actions)
+	require.Equal(t, []int64{40, 30, 20, 10}, users)
+	require.Equal(t, pipeline.ConverterTypeInfluxAuto, versions[1].Settings.Converter.Type)
+}
+
+func TestPipelineStorage_ChannelRulesConflict(t *testing.T) {
+	storage := SetupTestPipelineStorage(t)
+	ctx := context.Background()
+
+	_, err := storage.CreateChannelRule(ctx, 1, pipeline.ChannelRuleCreateCmd{Pattern: "stream/:id/cpu"})
+	require.NoError(t, err)
+	_, err = storage.CreateChannelRule(ctx, 1, pipeline.ChannelRuleCreateCmd{Pattern: "stream/:name/cpu"})
+	require.Error(t, err)
+	_, err = storage.CreateChannelRule(ctx, 2, pipeline.ChannelRuleCreateCmd{Pattern: "stream/:name/cpu"})
+	require.NoError(t, err)
+}
+
+func TestPipelineStorage_WriteConfigs(t *testing.T) {
+	storage := SetupTestPipelineStorage(t)
+	ctx := context.Background()
+
+	writeConfig, err := storage.CreateWriteConfig(ctx, 1, pipeline.WriteConfigCreateCmd{
+		UID:            "test",
+		Settings:       pipeline.WriteSettings{Endpoint: "http://localhost:9090/api/v1/write"},
+		SecureSettings: map[string]string{"password": "secret"},
+		UserID:         10,
+	})
+	require.NoError(t, err)
+	require.Equal(t, int64(1), writeConfig.Version)
+	require.NotEmpty(t, writeConfig.SecureSettings["password"])
+	require.NotEqual(t, "secret", string(writeConfig.SecureSettings["password"]))
+
+	writeConfig, ok, err := storage.GetWriteConfig(ctx, 1, pipeline.WriteConfigGetCmd{UID: "test"})
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.Equal(t, "http://localhost:9090/api/v1/write", writeConfig.Settings.Endpoint)
+	require.Contains(t, writeConfig.SecureSettings, "password")
+
+	_, ok, err = storage.GetWriteConfig(ctx, 2, pipeline.WriteConfigGetCmd{UID: "test"})
+	require.NoError(t, err)
+	require.False(t, ok)
+
+	writeConfig, err = storage.UpdateWriteConfig(ctx, 1, pipeline.WriteConfigUpdateCmd{
+		UID:      "test",
+		Settings: pipeline.WriteSettings{Endpoint: "http://localhost:9091/api/v1/write"},
+		Version:  1,
+		UserID:   20,
+	})
+	require.NoError(t, err)
+	require.Equal(t, int64(2), writeConfig.Version)
+	require.Equal(t, int64(20), writeConfig.UpdatedBy)
+
+	_, err = storage.UpdateWriteConfig(ctx, 1, pipeline.WriteConfigUpdateCmd{
+		UID:      "test",
+		Settings: pipeline.WriteSettings{Endpoint: "http://localhost:9092/api/v1/write"},
+		Version:  1,
+	})
+	require.ErrorIs(t, err, pipeline.ErrVersionConflict)
+
+	writeConfigs, err := storage.ListWriteConfigs(ctx, 1)
+	require.NoError(t, err)
+	require.Len(t, writeConfigs, 1)
+
+	err = storage.DeleteWriteConfig(ctx, 1, pipeline.WriteConfigDeleteCmd{UID: "test", Version: 2})
+	require.NoError(t, err)
+	err = storage.DeleteWriteConfig(ctx, 1, pipeline.WriteConfigDeleteCmd{UID: "test"})
+	require.ErrorIs(t, err, pipeline.ErrWriteConfigNotFound)
+
+	versions, err := storage.ListWriteConfigVersions(ctx, 1, pipeline.WriteConfigVersionsCmd{UID: "test"})
+	require.NoError(t, err)
+	require.Len(t, versions, 3)
+	require.Equal(t, pipeline.VersionActionDelete, versions[0].Action)
+	require.Equal(t, "http://localhost:9091/api/v1/write", versions[0].Settings.Endpoint)
+}
@@ -6,6 +6,8 @@ import (
 
 	"github.com/grafana/grafana/pkg/infra/localcache"
 	"github.com/grafana/grafana/pkg/services/live/database"
+	secretsDatabase "github.com/grafana/grafana/pkg/services/secrets/database"
+	secretsManager "github.com/grafana/grafana/pkg/services/secrets/manager"
 	"github.com/grafana/grafana/pkg/services/sqlstore"
 )
 
@@ -16,3 +18,16 @@ func SetupTestStorage(t *testing.T) *database.Storage {
 	localCache := localcache.New(time.Hour, time.Hour)
 	return database.NewStorage(sqlStore, localCache)
 }
+
+// SetupTestHistoryStorage initializes a history storage to used by the integration tests.
+func SetupTestHistoryStorage(t *testing.T) *database.HistoryStorage {
+	sqlStore := sqlstore.InitTestDB(t)
+	return database.NewHistoryStorage(sqlStore)
+}
+
+// SetupTestPipelineStorage initializes a pipeline storage to used by the integration tests.
+func SetupTestPipelineStorage(t *testing.T) *database.PipelineStorage {
+	sqlStore := sqlstore.InitTestDB(t)
+	secretsService := secretsManager.SetupTestService(t, secretsDatabase.ProvideSecretsStore(sqlStore))
+	return database.NewPipelineStorage(sqlStore, secretsService)
+}
@@ -4,10 +4,14 @@
 package tests
 
 import (
+	"context"
 	"encoding/json"
+	"fmt"
+	"sync"
 	"testing"
 
 	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/live/history"
 
 	"github.com/stretchr/testify/require"
 )
@@ -60,3 +64,64 @@ func TestLiveMessage(t *testing.T) {
 	require.Equal(t, json.RawMessage(`{"input": "hello"}`), msg2.Data)
 	require.NotZero(t, msg2.Published)
 }
+
+func TestLiveHistory(t *testing.T) {
+	storage := SetupTestHistoryStorage(t)
+	ctx := context.Background()
+	opts := history.Options{Size: 2}
+
+	messages, err := storage.Since(ctx, 1, "test_channel", 0, opts)
+	require.NoError(t, err)
+	require.Len(t, messages, 0)
+
+	for i := 1; i <= 3; i++ {
+		msg, err := storage.Add(ctx, 1, "test_channel", []byte(fmt.Sprintf(`{"n": %d}`, i)), opts)
+		require.NoError(t, err)
+		require.Equal(t, uint64(i), msg.Offset)
+	}
+
+	messages, err = storage.Since(ctx, 1, "test_channel", 0, opts)
+	require.NoError(t, err)
+	require.Len(t, messages, 2)
+	require.Equal(t, uint64(2), messages[0].Offset)
+	require.Equal(t, json.RawMessage(`{"n": 2}`), messages[0].Data)
+	require.Equal(t, uint64(3), messages[1].Offset)
+
+	messages, err = storage.Since(ctx, 1, "test_channel", 2, opts)
+	require.NoError(t, err)
+	require.Len(t, messages, 1)
+	require.Equal(t, uint64(3), messages[0].Offset)
+
+	msg, err := storage.Add(ctx, 2, "test_channel", []byte(`{}`), opts)
+	require.NoError(t, err)
+	require.Equal(t, uint64(1), msg.Offset)
+}
+
+func TestLiveHistoryConcurrentAdd(t *testing.T) {
+	storage := SetupTestHistoryStorage(t)
+	ctx := context.Background()
+	opts := history.Options{Size: 10}
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 5)
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := storage.Add(ctx, 1, "test_channel", []byte(`{}`), opts)
+			errs <- err
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		require.NoError(t, err)
+	}
+
+	messages, err := storage.Since(ctx, 1, "test_channel", 0, opts)
+	require.NoError(t, err)
+	require.Len(t, messages, 5)
+	for i, msg := range messages {
+		require.Equal(t, uint64(i+1), msg.Offset)
+	}
+}
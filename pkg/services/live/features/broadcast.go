@@ -2,9 +2,11 @@ package features
 
 import (
 	"context"
+	"encoding/json"
 
 	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/live/history"
 
 	"github.com/grafana/grafana-plugin-sdk-go/backend"
 )
@@ -24,10 +26,13 @@ type LiveMessageStore interface {
 // This assumes that data is a JSON object
 type BroadcastRunner struct {
 	liveMessageStore LiveMessageStore
+	history          *history.History
 }
 
-func NewBroadcastRunner(liveMessageStore LiveMessageStore) *BroadcastRunner {
-	return &BroadcastRunner{liveMessageStore: liveMessageStore}
+// NewBroadcastRunner creates a BroadcastRunner. History is optional, when set messages
+// published into channels with history can be replayed by subscribers.
+func NewBroadcastRunner(liveMessageStore LiveMessageStore, history *history.History) *BroadcastRunner {
+	return &BroadcastRunner{liveMessageStore: liveMessageStore, history: history}
 }
 
 // GetHandlerForPath called on init
@@ -35,12 +40,25 @@ func (b *BroadcastRunner) GetHandlerForPath(_ string) (models.ChannelHandler, er
 	return b, nil // all dashboards share the same handler
 }
 
-// OnSubscribe will let anyone connect to the path
-func (b *BroadcastRunner) OnSubscribe(_ context.Context, u *models.SignedInUser, e models.SubscribeEvent) (models.SubscribeReply, backend.SubscribeStreamStatus, error) {
+// OnSubscribe will let anyone connect to the path. Subscribers requesting a replay
+// get the messages kept in channel history instead of the last message.
+func (b *BroadcastRunner) OnSubscribe(ctx context.Context, u *models.SignedInUser, e models.SubscribeEvent) (models.SubscribeReply, backend.SubscribeStreamStatus, error) {
 	reply := models.SubscribeReply{
 		Presence:  true,
 		JoinLeave: true,
 	}
+	if req, ok := history.ParseReplayRequest(e.Data); ok && b.history.Enabled(e.Channel) {
+		messages, err := b.history.Since(ctx, u.OrgId, e.Channel, req.Since)
+		if err != nil {
+			return models.SubscribeReply{}, 0, err
+		}
+		data, err := json.Marshal(history.NewReplayReply(req, messages))
+		if err != nil {
+			return models.SubscribeReply{}, 0, err
+		}
+		reply.Data = data
+		return reply, backend.SubscribeStreamStatusOK, nil
+	}
 	query := &models.GetLiveMessageQuery{
 		OrgId:   u.OrgId,
 		Channel: e.Channel,
@@ -55,8 +73,9 @@ func (b *BroadcastRunner) OnSubscribe(_ context.Context, u *models.SignedInUser,
 	return reply, backend.SubscribeStreamStatusOK, nil
 }
 
-// OnPublish is called when a client wants to broadcast on the websocket
-func (b *BroadcastRunner) OnPublish(_ context.Context, u *models.SignedInUser, e models.PublishEvent) (models.PublishReply, backend.PublishStreamStatus, error) {
+// OnPublish is called when a client wants to broadcast on the websocket. Messages
+// of channels with history are wrapped with their offset.
+func (b *BroadcastRunner) OnPublish(ctx context.Context, u *models.SignedInUser, e models.PublishEvent) (models.PublishReply, backend.PublishStreamStatus, error) {
 	query := &models.SaveLiveMessageQuery{
 		OrgId:   u.OrgId,
 		Channel: e.Channel,
@@ -65,5 +84,19 @@ func (b *BroadcastRunner) OnPublish(_ context.Context, u *models.SignedInUser, e
 	if err := b.liveMessageStore.SaveLiveMessage(query); err != nil {
 		return models.PublishReply{}, 0, err
 	}
+	// History is best effort, subscribers still get the message when it can't be kept.
+	msg, kept, err := b.history.Add(ctx, u.OrgId, e.Channel, e.Data)
+	if err != nil {
+		logger.Error("Error adding message to channel history", "channel", e.Channel, "error", err)
+	}
+	if kept {
+		// Messages kept in history are published in the envelope of replayed messages,
+		// with their offset, so subscribers can resume from the last offset they got.
+		data, err := json.Marshal(msg)
+		if err != nil {
+			return models.PublishReply{}, 0, err
+		}
+		return models.PublishReply{Data: data}, backend.PublishStreamStatusOK, nil
+	}
 	return models.PublishReply{Data: e.Data}, backend.PublishStreamStatusOK, nil
 }
@@ -3,11 +3,14 @@ package features
 import (
 	"context"
 	"encoding/json"
+	"fmt"
 	"testing"
 
 	"github.com/golang/mock/gomock"
 	"github.com/grafana/grafana-plugin-sdk-go/backend"
 	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/live/history"
+	"github.com/grafana/grafana/pkg/setting"
 	"github.com/stretchr/testify/require"
 )
 
@@ -15,7 +18,7 @@ func TestNewBroadcastRunner(t *testing.T) {
 	mockCtrl := gomock.NewController(t)
 	defer mockCtrl.Finish()
 	d := NewMockLiveMessageStore(mockCtrl)
-	br := NewBroadcastRunner(d)
+	br := NewBroadcastRunner(d, nil)
 	require.NotNil(t, br)
 }
 
@@ -36,7 +39,7 @@ func TestBroadcastRunner_OnSubscribe(t *testing.T) {
 		}, true, nil
 	}).Times(1)
 
-	br := NewBroadcastRunner(mockDispatcher)
+	br := NewBroadcastRunner(mockDispatcher, nil)
 	require.NotNil(t, br)
 	handler, err := br.GetHandlerForPath("test")
 	require.NoError(t, err)
@@ -70,7 +73,7 @@ func TestBroadcastRunner_OnPublish(t *testing.T) {
 		return nil
 	}).Times(1)
 
-	br := NewBroadcastRunner(mockDispatcher)
+	br := NewBroadcastRunner(mockDispatcher, nil)
 	require.NotNil(t, br)
 	handler, err := br.GetHandlerForPath("test")
 	require.NoError(t, err)
@@ -83,3 +86,44 @@ func TestBroadcastRunner_OnPublish(t *testing.T) {
 	require.Equal(t, backend.PublishStreamStatusOK, status)
 	require.Equal(t, data, reply.Data)
 }
+
+func TestBroadcastRunner_Replay(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	mockDispatcher := NewMockLiveMessageStore(mockCtrl)
+	mockDispatcher.EXPECT().SaveLiveMessage(gomock.Any()).Return(nil).Times(3)
+
+	hist := history.New(history.NewMemoryStorage(), &setting.Cfg{
+		LiveHistoryChannels: []setting.LiveHistoryChannel{{Pattern: "grafana/broadcast/*", Size: 10}},
+	})
+	br := NewBroadcastRunner(mockDispatcher, hist)
+	user := &models.SignedInUser{OrgId: 1, UserId: 2}
+	channel := "grafana/broadcast/test"
+
+	var lastSeen history.Message
+	for _, data := range []string{`{"n":1}`, `{"n":2}`, `{"n":3}`} {
+		reply, _, err := br.OnPublish(context.Background(), user, models.PublishEvent{Channel: channel, Path: "test", Data: json.RawMessage(data)})
+		require.NoError(t, err)
+		// A subscriber sees the first message only and resubscribes from its offset.
+		if lastSeen.Offset == 0 {
+			require.NoError(t, json.Unmarshal(reply.Data, &lastSeen))
+		}
+	}
+	require.Equal(t, uint64(1), lastSeen.Offset)
+	require.JSONEq(t, `{"n":1}`, string(lastSeen.Data))
+
+	reply, status, err := br.OnSubscribe(context.Background(), user, models.SubscribeEvent{
+		Channel: channel,
+		Path:    "test",
+		Data:    json.RawMessage(fmt.Sprintf(`{"replay": {"since": %d}}`, lastSeen.Offset)),
+	})
+	require.NoError(t, err)
+	require.Equal(t, backend.SubscribeStreamStatusOK, status)
+
+	var replay history.ReplayReply
+	require.NoError(t, json.Unmarshal(reply.Data, &replay))
+	require.Equal(t, uint64(3), replay.Offset)
+	require.Len(t, replay.Messages, 2)
+	require.JSONEq(t, `{"n":2}`, string(replay.Messages[0].Data))
+	require.JSONEq(t, `{"n":3}`, string(replay.Messages[1].Data))
+}
@@ -0,0 +1,140 @@
+package history
+
+import (
+	"context"
+	"encoding/json"
+	"path"
+	"time"
+
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+// Message is a message published into a channel, kept in channel history.
+type Message struct {
+	// Offset is the position of a message in channel history. Offsets start from 1
+	// and increase with every message published into a channel.
+	Offset uint64          `json:"offset"`
+	Time   time.Time       `json:"time"`
+	Data   json.RawMessage `json:"data"`
+}
+
+// Options describe how much history is kept for a channel.
+type Options struct {
+	// Size is the maximum number of kept messages, 0 disables history.
+	Size int
+	// TTL is the maximum age of kept messages, 0 means messages don't expire.
+	TTL time.Duration
+}
+
+// Enabled returns true if messages are kept with these options.
+func (o Options) Enabled() bool {
+	return o.Size > 0
+}
+
+// Storage keeps channel history.
+type Storage interface {
+	// Add appends a message to channel history and trims the history according to opts.
+	Add(ctx context.Context, orgID int64, channel string, data []byte, opts Options) (Message, error)
+	// Since returns the kept messages of a channel with an offset greater than since,
+	// oldest first.
+	Since(ctx context.Context, orgID int64, channel string, since uint64, opts Options) ([]Message, error)
+}
+
+// History keeps the history of the channels it's configured for.
+type History struct {
+	storage  Storage
+	defaults Options
+	channels []setting.LiveHistoryChannel
+}
+
+// New creates History keeping messages in storage, with the default options and
+// channel overrides from cfg.
+func New(storage Storage, cfg *setting.Cfg) *History {
+	return &History{
+		storage:  storage,
+		defaults: Options{Size: cfg.LiveHistorySize, TTL: cfg.LiveHistoryTTL},
+		channels: cfg.LiveHistoryChannels,
+	}
+}
+
+// Options returns the history options of a channel. The first matching channel
+// override wins.
+func (h *History) Options(channel string) Options {
+	for _, c := range h.channels {
+		if ok, _ := path.Match(c.Pattern, channel); ok {
+			return Options{Size: c.Size, TTL: c.TTL}
+		}
+	}
+	return h.defaults
+}
+
+// Enabled returns true if history is kept for a channel.
+func (h *History) Enabled(channel string) bool {
+	return h != nil && h.Options(channel).Enabled()
+}
+
+// Add appends a message to channel history. It's a no-op for channels without history.
+func (h *History) Add(ctx context.Context, orgID int64, channel string, data []byte) (Message, bool, error) {
+	if !h.Enabled(channel) {
+		return Message{}, false, nil
+	}
+	msg, err := h.storage.Add(ctx, orgID, channel, data, h.Options(channel))
+	if err != nil {
+		return Message{}, false, err
+	}
+	return msg, true, nil
+}
+
+// Since returns channel messages published after offset since.
+func (h *History) Since(ctx context.Context, orgID int64, channel string, since uint64) ([]Message, error) {
+	if !h.Enabled(channel) {
+		return nil, nil
+	}
+	return h.storage.Since(ctx, orgID, channel, since, h.Options(channel))
+}
+
+// ReplayRequest is sent by a client in subscribe data to get the messages of a
+// channel published after an offset.
+type ReplayRequest struct {
+	Since uint64 `json:"since"`
+}
+
+// ParseReplayRequest extracts a replay request from subscribe data. It returns
+// false when no replay was requested.
+func ParseReplayRequest(data json.RawMessage) (ReplayRequest, bool) {
+	if len(data) == 0 {
+		return ReplayRequest{}, false
+	}
+	var req struct {
+		Replay *ReplayRequest `json:"replay"`
+	}
+	if err := json.Unmarshal(data, &req); err != nil || req.Replay == nil {
+		return ReplayRequest{}, false
+	}
+	return *req.Replay, true
+}
+
+// ReplayReply is returned in subscribe data for a replay request. Offset is the offset
+// of the last message, which a client can use to request a replay on resubscribe.
+type ReplayReply struct {
+	Offset   uint64    `json:"offset"`
+	Messages []Message `json:"messages"`
+}
+
+// NewReplayReply creates a ReplayReply from messages. When there are no messages,
+// the offset of the request is kept.
+func NewReplayReply(req ReplayRequest, messages []Message) ReplayReply {
+	reply := ReplayReply{Offset: req.Since, Messages: messages}
+	if reply.Messages == nil {
+		reply.Messages = []Message{}
+	}
+	if len(messages) > 0 {
+		reply.Offset = messages[len(messages)-1].Offset
+	}
+	return reply
+}
+
+// expired returns true if a message published at t has expired at now.
+func expired(t time.Time, now time.Time, opts Options) bool {
+	return opts.TTL > 0 && now.Sub(t) > opts.TTL
+}
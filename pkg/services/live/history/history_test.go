@@ -0,0 +1,118 @@
+package history
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHistoryOptions(t *testing.T) {
+	h := New(NewMemoryStorage(), &setting.Cfg{
+		LiveHistorySize: 10,
+		LiveHistoryChannels: []setting.LiveHistoryChannel{
+			{Pattern: "grafana/broadcast/*", Size: 100, TTL: time.Hour},
+			{Pattern: "stream/telegraf/cpu", Size: 0},
+		},
+	})
+
+	require.Equal(t, Options{Size: 100, TTL: time.Hour}, h.Options("grafana/broadcast/test"))
+	require.Equal(t, Options{Size: 10}, h.Options("grafana/broadcast/test/nested"))
+	require.Equal(t, Options{Size: 10}, h.Options("stream/telegraf/mem"))
+	require.False(t, h.Enabled("stream/telegraf/cpu"))
+
+	var nilHistory *History
+	require.False(t, nilHistory.Enabled("stream/telegraf/mem"))
+	_, ok, err := nilHistory.Add(context.Background(), 1, "stream/telegraf/mem", []byte(`{}`))
+	require.NoError(t, err)
+	require.False(t, ok)
+}
+
+func TestParseReplayRequest(t *testing.T) {
+	req, ok := ParseReplayRequest(json.RawMessage(`{"replay": {"since": 5}}`))
+	require.True(t, ok)
+	require.Equal(t, uint64(5), req.Since)
+
+	_, ok = ParseReplayRequest(json.RawMessage(`{"replay": {}}`))
+	require.True(t, ok)
+
+	for _, data := range []string{``, `{}`, `{"other": 1}`, `[1]`} {
+		_, ok = ParseReplayRequest(json.RawMessage(data))
+		require.False(t, ok, data)
+	}
+}
+
+func TestNewReplayReply(t *testing.T) {
+	reply := NewReplayReply(ReplayRequest{Since: 3}, nil)
+	require.Equal(t, uint64(3), reply.Offset)
+	require.NotNil(t, reply.Messages)
+
+	reply = NewReplayReply(ReplayRequest{Since: 3}, []Message{{Offset: 4}, {Offset: 5}})
+	require.Equal(t, uint64(5), reply.Offset)
+}
+
+func testStorage(t *testing.T, s Storage) {
+	ctx := context.Background()
+	opts := Options{Size: 3}
+
+	messages, err := s.Since(ctx, 1, "test", 0, opts)
+	require.NoError(t, err)
+	require.Len(t, messages, 0)
+
+	for i := 1; i <= 5; i++ {
+		msg, err := s.Add(ctx, 1, "test", []byte(`{}`), opts)
+		require.NoError(t, err)
+		require.Equal(t, uint64(i), msg.Offset)
+	}
+
+	messages, err = s.Since(ctx, 1, "test", 0, opts)
+	require.NoError(t, err)
+	require.Len(t, messages, 3)
+	require.Equal(t, uint64(3), messages[0].Offset)
+	require.Equal(t, uint64(5), messages[2].Offset)
+
+	messages, err = s.Since(ctx, 1, "test", 4, opts)
+	require.NoError(t, err)
+	require.Len(t, messages, 1)
+	require.Equal(t, uint64(5), messages[0].Offset)
+
+	// Other orgs and channels have their own history.
+	msg, err := s.Add(ctx, 2, "test", []byte(`{}`), opts)
+	require.NoError(t, err)
+	require.Equal(t, uint64(1), msg.Offset)
+	messages, err = s.Since(ctx, 1, "other", 0, opts)
+	require.NoError(t, err)
+	require.Len(t, messages, 0)
+}
+
+func TestMemoryStorage(t *testing.T) {
+	testStorage(t, NewMemoryStorage())
+}
+
+func TestMemoryStorageTTL(t *testing.T) {
+	s := NewMemoryStorage()
+	now := time.Now()
+	s.now = func() time.Time { return now }
+	opts := Options{Size: 10, TTL: time.Minute}
+
+	_, err := s.Add(context.Background(), 1, "test", []byte(`{}`), opts)
+	require.NoError(t, err)
+	now = now.Add(30 * time.Second)
+	_, err = s.Add(context.Background(), 1, "test", []byte(`{}`), opts)
+	require.NoError(t, err)
+
+	now = now.Add(45 * time.Second)
+	messages, err := s.Since(context.Background(), 1, "test", 0, opts)
+	require.NoError(t, err)
+	require.Len(t, messages, 1)
+	require.Equal(t, uint64(2), messages[0].Offset)
+
+	// Offsets keep increasing when all messages expired.
+	now = now.Add(time.Hour)
+	msg, err := s.Add(context.Background(), 1, "test", []byte(`{}`), opts)
+	require.NoError(t, err)
+	require.Equal(t, uint64(3), msg.Offset)
+}
@@ -0,0 +1,86 @@
+package history
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// MemoryStorage keeps channel history in memory, so it's only available on the
+// Grafana server instance the messages were published on.
+type MemoryStorage struct {
+	mu       sync.Mutex
+	channels map[int64]map[string]*memoryChannel
+	now      func() time.Time
+}
+
+type memoryChannel struct {
+	offset   uint64
+	messages []Message
+}
+
+// NewMemoryStorage creates a new MemoryStorage.
+func NewMemoryStorage() *MemoryStorage {
+	return &MemoryStorage{
+		channels: map[int64]map[string]*memoryChannel{},
+		now:      time.Now,
+	}
+}
+
+func (s *MemoryStorage) Add(_ context.Context, orgID int64, channel string, data []byte, opts Options) (Message, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.channels[orgID]; !ok {
+		s.channels[orgID] = map[string]*memoryChannel{}
+	}
+	ch, ok := s.channels[orgID][channel]
+	if !ok {
+		ch = &memoryChannel{}
+		s.channels[orgID][channel] = ch
+	}
+
+	now := s.now()
+	ch.offset++
+	msg := Message{
+		Offset: ch.offset,
+		Time:   now,
+		Data:   append([]byte(nil), data...),
+	}
+	ch.messages = append(ch.messages, msg)
+	ch.trim(now, opts)
+	return msg, nil
+}
+
+func (s *MemoryStorage) Since(_ context.Context, orgID int64, channel string, since uint64, opts Options) ([]Message, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	ch, ok := s.channels[orgID][channel]
+	if !ok {
+		return nil, nil
+	}
+	ch.trim(s.now(), opts)
+
+	var messages []Message
+	for _, msg := range ch.messages {
+		if msg.Offset > since {
+			messages = append(messages, msg)
+		}
+	}
+	return messages, nil
+}
+
+// trim removes the messages exceeding the history size and the expired ones.
+func (ch *memoryChannel) trim(now time.Time, opts Options) {
+	start := 0
+	if len(ch.messages) > opts.Size {
+		start = len(ch.messages) - opts.Size
+	}
+	for start < len(ch.messages) && expired(ch.messages[start].Time, now, opts) {
+		start++
+	}
+	if start > 0 {
+		ch.messages = append([]Message(nil), ch.messages[start:]...)
+	}
+}
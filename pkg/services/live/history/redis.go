@@ -0,0 +1,109 @@
+package history
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/grafana/grafana/pkg/services/live/orgchannel"
+)
+
+// redisHistoryTTL is the expiration of history keys for channels without TTL, so
+// that history of abandoned channels is eventually removed.
+const redisHistoryTTL = 7 * 24 * time.Hour
+
+// RedisStorage keeps channel history in Redis, shared between Grafana server
+// instances. Messages are kept in a list per channel, with the offset of the last
+// message in a separate counter that is only updated along with the list.
+type RedisStorage struct {
+	redisClient *redis.Client
+	now         func() time.Time
+}
+
+// NewRedisStorage creates a new RedisStorage.
+func NewRedisStorage(redisClient *redis.Client) *RedisStorage {
+	return &RedisStorage{
+		redisClient: redisClient,
+		now:         time.Now,
+	}
+}
+
+// addScript increments the offset of a channel, appends the message with that
+// offset to the history list and trims the list in one atomic step, so that
+// concurrent publications keep the list in offset order. KEYS are the list and
+// the offset counter. ARGV are the message encoded without offset, the history
+// size, and the expiration of the list and of the counter in seconds.
+var addScript = redis.NewScript(`
+local offset = redis.call("INCR", KEYS[2])
+redis.call("RPUSH", KEYS[1], '{"offset":' .. offset .. ',' .. string.sub(ARGV[1], 2))
+redis.call("LTRIM", KEYS[1], -tonumber(ARGV[2]), -1)
+redis.call("EXPIRE", KEYS[1], ARGV[3])
+redis.call("EXPIRE", KEYS[2], ARGV[4])
+return offset
+`)
+
+func (s *RedisStorage) Add(ctx context.Context, orgID int64, channel string, data []byte, opts Options) (Message, error) {
+	key := getHistoryKey(orgchannel.PrependOrgID(orgID, channel))
+
+	msg := Message{
+		Time: s.now(),
+		Data: append([]byte(nil), data...),
+	}
+	encoded, err := json.Marshal(struct {
+		Time time.Time       `json:"time"`
+		Data json.RawMessage `json:"data"`
+	}{msg.Time, msg.Data})
+	if err != nil {
+		return Message{}, err
+	}
+
+	ttl := redisHistoryTTL
+	if opts.TTL > 0 {
+		ttl = opts.TTL
+	}
+
+	// The offset must outlive messages, otherwise offsets would restart while
+	// clients could still have a greater one.
+	offset, err := addScript.Run(ctx, s.redisClient, []string{key, key + ".offset"},
+		encoded, opts.Size, expireSeconds(ttl), expireSeconds(redisHistoryTTL)).Int64()
+	if err != nil {
+		return Message{}, err
+	}
+
+	msg.Offset = uint64(offset)
+	return msg, nil
+}
+
+func (s *RedisStorage) Since(ctx context.Context, orgID int64, channel string, since uint64, opts Options) ([]Message, error) {
+	key := getHistoryKey(orgchannel.PrependOrgID(orgID, channel))
+
+	result, err := s.redisClient.LRange(ctx, key, int64(-opts.Size), -1).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	now := s.now()
+	var messages []Message
+	for _, item := range result {
+		var msg Message
+		if err := json.Unmarshal([]byte(item), &msg); err != nil {
+			return nil, fmt.Errorf("error decoding history message of channel %s: %w", channel, err)
+		}
+		if msg.Offset <= since || expired(msg.Time, now, opts) {
+			continue
+		}
+		messages = append(messages, msg)
+	}
+	return messages, nil
+}
+
+func getHistoryKey(channelID string) string {
+	return "gf_live.history." + channelID
+}
+
+// expireSeconds rounds a TTL up to whole seconds as expected by EXPIRE.
+func expireSeconds(ttl time.Duration) int64 {
+	return int64((ttl + time.Second - 1) / time.Second)
+}
@@ -0,0 +1,19 @@
+//go:build redis
+// +build redis
+
+package history
+
+import (
+	"testing"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRedisStorage(t *testing.T) {
+	redisClient := redis.NewClient(&redis.Options{
+		Addr: "localhost:6379",
+	})
+	require.NoError(t, redisClient.FlushDB(redisClient.Context()).Err())
+	testStorage(t, NewRedisStorage(redisClient))
+}
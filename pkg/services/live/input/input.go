@@ -0,0 +1,150 @@
+package input
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/live"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+// Handler is called for every message received by a Subscriber. Filter is the
+// subscribed topic filter the message topic matched.
+type Handler func(filter string, topic string, payload []byte)
+
+// Subscriber subscribes to the topics of a message broker.
+type Subscriber interface {
+	// Subscribe connects to the broker and subscribes to topics, calling handler
+	// for every received message. It calls connected once subscribed and blocks
+	// until ctx is done or the connection is lost.
+	Subscribe(ctx context.Context, topics []string, handler Handler, connected func()) error
+	// TopicSeparator returns the separator of topic levels.
+	TopicSeparator() string
+}
+
+// Processor processes the messages received from a broker, usually the Live pipeline.
+type Processor interface {
+	ProcessInput(ctx context.Context, orgID int64, channelID string, body []byte) (bool, error)
+}
+
+// Input subscribes to broker topics and passes the received messages to a Processor.
+type Input struct {
+	cfg        setting.LiveInput
+	subscriber Subscriber
+	processor  Processor
+}
+
+// NewInput creates a new Input.
+func NewInput(cfg setting.LiveInput, subscriber Subscriber, processor Processor) (*Input, error) {
+	if _, err := live.ParseChannel(channelForTopic(cfg.Channel, "topic", "/")); err != nil {
+		return nil, fmt.Errorf("invalid channel %q of live input %s: %w", cfg.Channel, cfg.Name, err)
+	}
+	return &Input{
+		cfg:        cfg,
+		subscriber: subscriber,
+		processor:  processor,
+	}, nil
+}
+
+// Run subscribes to the input topics until ctx is done, reconnecting with an
+// exponential backoff when the connection is lost or can't be established.
+func (i *Input) Run(ctx context.Context) error {
+	b := newBackoff(i.cfg.ReconnectMinBackoff, i.cfg.ReconnectMaxBackoff)
+	for {
+		connected := false
+		err := i.subscriber.Subscribe(ctx, i.cfg.Topics, i.handle(ctx), func() {
+			connected = true
+			b.reset()
+			connectionStatus.WithLabelValues(i.cfg.Name).Set(1)
+			logger.Info("Live input connected", "input", i.cfg.Name, "url", i.cfg.URL)
+		})
+		connectionStatus.WithLabelValues(i.cfg.Name).Set(0)
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+
+		delay := b.next()
+		if connected {
+			logger.Warn("Live input connection lost", "input", i.cfg.Name, "error", err, "reconnectIn", delay)
+		} else {
+			logger.Error("Error connecting live input", "input", i.cfg.Name, "error", err, "reconnectIn", delay)
+		}
+		reconnectsTotal.WithLabelValues(i.cfg.Name).Inc()
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(delay):
+		}
+	}
+}
+
+func (i *Input) handle(ctx context.Context) Handler {
+	return func(filter string, topic string, payload []byte) {
+		messagesTotal.WithLabelValues(i.cfg.Name, filter).Inc()
+		channel := channelForTopic(i.cfg.Channel, topic, i.subscriber.TopicSeparator())
+		ok, err := i.processor.ProcessInput(ctx, i.cfg.OrgID, channel, payload)
+		if err != nil {
+			processingErrorsTotal.WithLabelValues(i.cfg.Name, filter).Inc()
+			logger.Error("Error processing live input message", "input", i.cfg.Name, "topic", topic, "channel", channel, "error", err)
+			return
+		}
+		if !ok {
+			droppedTotal.WithLabelValues(i.cfg.Name, filter).Inc()
+			logger.Debug("No channel rule for live input message", "input", i.cfg.Name, "topic", topic, "channel", channel)
+		}
+	}
+}
+
+var invalidPathSymbols = regexp.MustCompile(`[^A-Za-z0-9_\-=.]`)
+
+// channelForTopic replaces ${topic} in a channel template with the path of a topic,
+// where topic levels are path segments. Symbols not allowed in channel paths are
+// replaced with underscores.
+func channelForTopic(template string, topic string, separator string) string {
+	levels := strings.Split(topic, separator)
+	for i, level := range levels {
+		if level == "" {
+			level = "_"
+		}
+		levels[i] = invalidPathSymbols.ReplaceAllString(level, "_")
+	}
+	return strings.ReplaceAll(template, "${topic}", strings.Join(levels, "/"))
+}
+
+// backoff is an exponential backoff with jitter.
+type backoff struct {
+	min     time.Duration
+	max     time.Duration
+	attempt int
+}
+
+func newBackoff(min time.Duration, max time.Duration) *backoff {
+	return &backoff{min: min, max: max}
+}
+
+// next returns the delay before the next attempt, doubling with every attempt up
+// to max. Up to a quarter of the delay is randomized, so that inputs losing their
+// connection at the same time don't reconnect at the same time.
+func (b *backoff) next() time.Duration {
+	delay := b.min
+	for i := 0; i < b.attempt && delay < b.max; i++ {
+		delay *= 2
+	}
+	if delay > b.max {
+		delay = b.max
+	}
+	b.attempt++
+	if jitter := int64(delay / 4); jitter > 0 {
+		delay = delay - time.Duration(jitter) + time.Duration(rand.Int63n(jitter))
+	}
+	return delay
+}
+
+func (b *backoff) reset() {
+	b.attempt = 0
+}
@@ -0,0 +1,123 @@
+package input
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/stretchr/testify/require"
+)
+
+type testMessage struct {
+	orgID   int64
+	channel string
+	body    string
+}
+
+type testProcessor struct {
+	mu       sync.Mutex
+	messages []testMessage
+}
+
+func (p *testProcessor) ProcessInput(_ context.Context, orgID int64, channelID string, body []byte) (bool, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.messages = append(p.messages, testMessage{orgID: orgID, channel: channelID, body: string(body)})
+	return true, nil
+}
+
+// testSubscriber fails the first connection attempts, then delivers a message and
+// loses the connection.
+type testSubscriber struct {
+	failures int
+	attempts int
+	cancel   context.CancelFunc
+}
+
+func (s *testSubscriber) TopicSeparator() string {
+	return "/"
+}
+
+func (s *testSubscriber) Subscribe(ctx context.Context, topics []string, handler Handler, connected func()) error {
+	s.attempts++
+	if s.attempts <= s.failures {
+		return errors.New("connection refused")
+	}
+	connected()
+	handler(topics[0], "sensors/room 1/temp", []byte(`{"value": 1}`))
+	if s.attempts == s.failures+2 {
+		s.cancel()
+		<-ctx.Done()
+		return ctx.Err()
+	}
+	return errors.New("connection lost")
+}
+
+func testInputConfig() setting.LiveInput {
+	return setting.LiveInput{
+		Name:                "test",
+		Type:                "mqtt",
+		URL:                 "tcp://localhost:1883",
+		Topics:              []string{"sensors/#"},
+		Channel:             "stream/mqtt/${topic}",
+		OrgID:               2,
+		ReconnectMinBackoff: time.Millisecond,
+		ReconnectMaxBackoff: 4 * time.Millisecond,
+	}
+}
+
+func TestInputRun(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	processor := &testProcessor{}
+	subscriber := &testSubscriber{failures: 3, cancel: cancel}
+	input, err := NewInput(testInputConfig(), subscriber, processor)
+	require.NoError(t, err)
+
+	err = input.Run(ctx)
+	require.ErrorIs(t, err, context.Canceled)
+	require.Equal(t, 5, subscriber.attempts)
+	require.Equal(t, []testMessage{
+		{orgID: 2, channel: "stream/mqtt/sensors/room_1/temp", body: `{"value": 1}`},
+		{orgID: 2, channel: "stream/mqtt/sensors/room_1/temp", body: `{"value": 1}`},
+	}, processor.messages)
+}
+
+func TestNewInputInvalidChannel(t *testing.T) {
+	cfg := testInputConfig()
+	cfg.Channel = "stream/${topic}"
+	_, err := NewInput(cfg, &testSubscriber{}, &testProcessor{})
+	require.Error(t, err)
+}
+
+func TestChannelForTopic(t *testing.T) {
+	tests := []struct {
+		template  string
+		topic     string
+		separator string
+		expected  string
+	}{
+		{"stream/mqtt/${topic}", "sensors/room1/temp", "/", "stream/mqtt/sensors/room1/temp"},
+		{"stream/nats/${topic}", "sensors.room1.temp", ".", "stream/nats/sensors/room1/temp"},
+		{"stream/mqtt/${topic}", "/sensors/temp+°C", "/", "stream/mqtt/_/sensors/temp__C"},
+		{"stream/mqtt/all", "sensors/room1", "/", "stream/mqtt/all"},
+	}
+	for _, tt := range tests {
+		require.Equal(t, tt.expected, channelForTopic(tt.template, tt.topic, tt.separator), tt.topic)
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	b := newBackoff(time.Second, 10*time.Second)
+	for _, expected := range []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 8 * time.Second, 10 * time.Second, 10 * time.Second} {
+		delay := b.next()
+		require.LessOrEqual(t, delay, expected)
+		require.GreaterOrEqual(t, delay, expected*3/4)
+	}
+	b.reset()
+	require.LessOrEqual(t, b.next(), time.Second)
+}
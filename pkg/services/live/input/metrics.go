@@ -0,0 +1,51 @@
+package input
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var (
+	messagesTotal         *prometheus.CounterVec
+	processingErrorsTotal *prometheus.CounterVec
+	droppedTotal          *prometheus.CounterVec
+	reconnectsTotal       *prometheus.CounterVec
+	connectionStatus      *prometheus.GaugeVec
+)
+
+func init() {
+	messagesTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "grafana",
+		Subsystem: "live_input",
+		Name:      "messages_total",
+		Help:      "Number of messages received by a Live input, per subscribed topic.",
+	}, []string{"input", "topic"})
+
+	processingErrorsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "grafana",
+		Subsystem: "live_input",
+		Name:      "processing_errors_total",
+		Help:      "Number of messages received by a Live input the pipeline failed to process, per subscribed topic.",
+	}, []string{"input", "topic"})
+
+	droppedTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "grafana",
+		Subsystem: "live_input",
+		Name:      "dropped_total",
+		Help:      "Number of messages received by a Live input without a matching channel rule, per subscribed topic.",
+	}, []string{"input", "topic"})
+
+	reconnectsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "grafana",
+		Subsystem: "live_input",
+		Name:      "reconnects_total",
+		Help:      "Number of times a Live input reconnected to its broker.",
+	}, []string{"input"})
+
+	connectionStatus = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: "grafana",
+		Subsystem: "live_input",
+		Name:      "connected",
+		Help:      "Whether a Live input is connected to its broker.",
+	}, []string{"input"})
+}
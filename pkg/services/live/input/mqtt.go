@@ -0,0 +1,79 @@
+package input
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	mqtt "github.com/eclipse/paho.mqtt.golang"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+const mqttTimeout = 10 * time.Second
+
+// MQTTSubscriber subscribes to the topics of an MQTT broker.
+type MQTTSubscriber struct {
+	cfg setting.LiveInput
+}
+
+// NewMQTTSubscriber creates a new MQTTSubscriber.
+func NewMQTTSubscriber(cfg setting.LiveInput) *MQTTSubscriber {
+	return &MQTTSubscriber{cfg: cfg}
+}
+
+func (s *MQTTSubscriber) TopicSeparator() string {
+	return "/"
+}
+
+func (s *MQTTSubscriber) Subscribe(ctx context.Context, topics []string, handler Handler, connected func()) error {
+	lost := make(chan error, 1)
+	opts := mqtt.NewClientOptions().
+		AddBroker(s.cfg.URL).
+		SetClientID(s.cfg.ClientID).
+		SetUsername(s.cfg.Username).
+		SetPassword(s.cfg.Password).
+		SetConnectTimeout(mqttTimeout).
+		// Reconnects are handled by Input, with its backoff policy.
+		SetAutoReconnect(false).
+		SetConnectionLostHandler(func(_ mqtt.Client, err error) {
+			lost <- err
+		})
+
+	client := mqtt.NewClient(opts)
+	if err := waitToken(client.Connect()); err != nil {
+		return fmt.Errorf("error connecting to MQTT broker: %w", err)
+	}
+	defer client.Disconnect(250)
+
+	for _, topic := range topics {
+		filter := topic
+		subscription := filter
+		if s.cfg.Group != "" {
+			// Shared subscriptions deliver each message to one of the group's
+			// clients, i.e. one of the Grafana instances.
+			subscription = "$share/" + s.cfg.Group + "/" + filter
+		}
+		token := client.Subscribe(subscription, s.cfg.QoS, func(_ mqtt.Client, msg mqtt.Message) {
+			handler(filter, msg.Topic(), msg.Payload())
+		})
+		if err := waitToken(token); err != nil {
+			return fmt.Errorf("error subscribing to MQTT topic %s: %w", subscription, err)
+		}
+	}
+	connected()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case err := <-lost:
+		return fmt.Errorf("MQTT connection lost: %w", err)
+	}
+}
+
+func waitToken(token mqtt.Token) error {
+	if !token.WaitTimeout(mqttTimeout) {
+		return errors.New("timeout")
+	}
+	return token.Error()
+}
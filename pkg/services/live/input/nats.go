@@ -0,0 +1,78 @@
+package input
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/nats-io/nats.go"
+)
+
+// NATSSubscriber subscribes to the subjects of a NATS server.
+type NATSSubscriber struct {
+	cfg setting.LiveInput
+}
+
+// NewNATSSubscriber creates a new NATSSubscriber.
+func NewNATSSubscriber(cfg setting.LiveInput) *NATSSubscriber {
+	return &NATSSubscriber{cfg: cfg}
+}
+
+func (s *NATSSubscriber) TopicSeparator() string {
+	return "."
+}
+
+func (s *NATSSubscriber) Subscribe(ctx context.Context, topics []string, handler Handler, connected func()) error {
+	closed := make(chan error, 1)
+	opts := []nats.Option{
+		nats.Name(s.cfg.ClientID),
+		// Reconnects are handled by Input, with its backoff policy.
+		nats.NoReconnect(),
+		nats.ClosedHandler(func(conn *nats.Conn) {
+			err := conn.LastError()
+			if err == nil {
+				err = errors.New("connection closed")
+			}
+			closed <- err
+		}),
+	}
+	if s.cfg.Username != "" {
+		opts = append(opts, nats.UserInfo(s.cfg.Username, s.cfg.Password))
+	}
+
+	conn, err := nats.Connect(s.cfg.URL, opts...)
+	if err != nil {
+		return fmt.Errorf("error connecting to NATS server: %w", err)
+	}
+	defer conn.Close()
+
+	for _, topic := range topics {
+		filter := topic
+		cb := func(msg *nats.Msg) {
+			handler(filter, msg.Subject, msg.Data)
+		}
+		var err error
+		if s.cfg.Group != "" {
+			// Queue subscribers deliver each message to one member of the
+			// queue group, i.e. one of the Grafana instances.
+			_, err = conn.QueueSubscribe(filter, s.cfg.Group, cb)
+		} else {
+			_, err = conn.Subscribe(filter, cb)
+		}
+		if err != nil {
+			return fmt.Errorf("error subscribing to NATS subject %s: %w", filter, err)
+		}
+	}
+	if err := conn.Flush(); err != nil {
+		return fmt.Errorf("error subscribing to NATS subjects: %w", err)
+	}
+	connected()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case err := <-closed:
+		return fmt.Errorf("NATS connection lost: %w", err)
+	}
+}
@@ -0,0 +1,69 @@
+package input
+
+import (
+	"context"
+	"errors"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/live"
+	"github.com/grafana/grafana/pkg/setting"
+	"golang.org/x/sync/errgroup"
+)
+
+var (
+	logger = log.New("live.input")
+)
+
+func ProvideService(cfg *setting.Cfg, live *live.GrafanaLive) *Service {
+	return &Service{
+		Cfg:         cfg,
+		GrafanaLive: live,
+	}
+}
+
+// Service runs the message broker inputs configured for Live, which feed the
+// received messages to the Live pipeline.
+type Service struct {
+	Cfg         *setting.Cfg
+	GrafanaLive *live.GrafanaLive
+}
+
+// Run Service.
+func (s *Service) Run(ctx context.Context) error {
+	if len(s.Cfg.LiveInputs) == 0 {
+		<-ctx.Done()
+		return ctx.Err()
+	}
+	if s.GrafanaLive.Pipeline == nil {
+		logger.Warn("Live inputs are configured but not started, they require the live pipeline feature")
+		<-ctx.Done()
+		return ctx.Err()
+	}
+
+	inputs := make([]*Input, 0, len(s.Cfg.LiveInputs))
+	for _, cfg := range s.Cfg.LiveInputs {
+		var subscriber Subscriber
+		switch cfg.Type {
+		case "mqtt":
+			subscriber = NewMQTTSubscriber(cfg)
+		case "nats":
+			subscriber = NewNATSSubscriber(cfg)
+		default:
+			return errors.New("unsupported live input type: " + cfg.Type)
+		}
+		input, err := NewInput(cfg, subscriber, s.GrafanaLive.Pipeline)
+		if err != nil {
+			return err
+		}
+		inputs = append(inputs, input)
+	}
+
+	group, ctx := errgroup.WithContext(ctx)
+	for _, input := range inputs {
+		input := input
+		group.Go(func() error {
+			return input.Run(ctx)
+		})
+	}
+	return group.Wait()
+}
@@ -33,9 +33,11 @@ import (
 	"github.com/grafana/grafana/pkg/models"
 	"github.com/grafana/grafana/pkg/plugins"
 	"github.com/grafana/grafana/pkg/plugins/plugincontext"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
 	"github.com/grafana/grafana/pkg/services/datasources"
 	"github.com/grafana/grafana/pkg/services/live/database"
 	"github.com/grafana/grafana/pkg/services/live/features"
+	"github.com/grafana/grafana/pkg/services/live/history"
 	"github.com/grafana/grafana/pkg/services/live/livecontext"
 	"github.com/grafana/grafana/pkg/services/live/liveplugin"
 	"github.com/grafana/grafana/pkg/services/live/managedstream"
@@ -44,6 +46,7 @@ import (
 	"github.com/grafana/grafana/pkg/services/live/pushws"
 	"github.com/grafana/grafana/pkg/services/live/runstream"
 	"github.com/grafana/grafana/pkg/services/live/survey"
+	"github.com/grafana/grafana/pkg/services/ngalert"
 	"github.com/grafana/grafana/pkg/services/secrets"
 	"github.com/grafana/grafana/pkg/services/sqlstore"
 	"github.com/grafana/grafana/pkg/setting"
@@ -68,7 +71,8 @@ type CoreGrafanaScope struct {
 func ProvideService(plugCtxProvider *plugincontext.Provider, cfg *setting.Cfg, routeRegister routing.RouteRegister,
 	pluginStore plugins.Store, cacheService *localcache.CacheService,
 	dataSourceCache datasources.CacheService, sqlStore *sqlstore.SQLStore, secretsService secrets.Service,
-	usageStatsService usagestats.Service, queryDataService *query.Service, toggles featuremgmt.FeatureToggles) (*GrafanaLive, error) {
+	usageStatsService usagestats.Service, queryDataService *query.Service, toggles featuremgmt.FeatureToggles,
+	alertNG *ngalert.AlertNG, accessControl accesscontrol.AccessControl) (*GrafanaLive, error) {
 	g := &GrafanaLive{
 		Cfg:                   cfg,
 		Features:              toggles,
@@ -80,6 +84,7 @@ func ProvideService(plugCtxProvider *plugincontext.Provider, cfg *setting.Cfg, r
 		SQLStore:              sqlStore,
 		SecretsService:        secretsService,
 		queryDataService:      queryDataService,
+		AccessControl:         accessControl,
 		channels:              make(map[string]models.ChannelHandler),
 		GrafanaScope: CoreGrafanaScope{
 			Features: make(map[string]models.ChannelHandlerFactory),
@@ -89,6 +94,10 @@ func ProvideService(plugCtxProvider *plugincontext.Provider, cfg *setting.Cfg, r
 
 	logger.Debug("GrafanaLive initialization", "ha", g.IsHA())
 
+	if err := registerRoles(accessControl); err != nil {
+		return nil, err
+	}
+
 	// We use default config here as starting point. Default config contains
 	// reasonable values for available options.
 	scfg := centrifuge.DefaultConfig
@@ -153,25 +162,49 @@ func ProvideService(plugCtxProvider *plugincontext.Provider, cfg *setting.Cfg, r
 
 	channelLocalPublisher := liveplugin.NewChannelLocalPublisher(node, nil)
 
-	var managedStreamRunner *managedstream.Runner
-	if g.IsHA() {
-		redisClient := redis.NewClient(&redis.Options{
+	var redisClient *redis.Client
+	if g.IsHA() || g.Cfg.LiveHistoryEngine == "redis" {
+		redisClient = redis.NewClient(&redis.Options{
 			Addr: g.Cfg.LiveHAEngineAddress,
 		})
 		cmd := redisClient.Ping(context.Background())
 		if _, err := cmd.Result(); err != nil {
 			return nil, fmt.Errorf("error pinging Redis: %v", err)
 		}
+	}
+
+	var historyStorage history.Storage
+	switch g.Cfg.LiveHistoryEngine {
+	case "redis":
+		historyStorage = history.NewRedisStorage(redisClient)
+	case "database":
+		historyStorage = database.NewHistoryStorage(g.SQLStore)
+	default:
+		historyStorage = history.NewMemoryStorage()
+	}
+	g.history = history.New(historyStorage, g.Cfg)
+
+	limiter := managedstream.NewLimiter(g.Cfg.LiveLimits)
+	// Channel rules are set once the pipeline is initialized below.
+	backfiller := &pipeline.Backfiller{QueryDataService: g.queryDataService}
+	var managedStreamRunner *managedstream.Runner
+	if g.IsHA() {
 		managedStreamRunner = managedstream.NewRunner(
 			g.Publish,
 			channelLocalPublisher,
 			managedstream.NewRedisFrameCache(redisClient),
+			g.history,
+			limiter,
+			backfiller,
 		)
 	} else {
 		managedStreamRunner = managedstream.NewRunner(
 			g.Publish,
 			channelLocalPublisher,
 			managedstream.NewMemoryFrameCache(),
+			g.history,
+			limiter,
+			backfiller,
 		)
 	}
 
@@ -186,9 +219,14 @@ func ProvideService(plugCtxProvider *plugincontext.Provider, cfg *setting.Cfg, r
 				ChannelHandlerGetter: g,
 			}
 		} else {
-			storage := &pipeline.FileStorage{
-				DataPath:       cfg.DataPath,
-				SecretsService: g.SecretsService,
+			var storage pipeline.Storage
+			if cfg.LivePipelineStorage == "database" {
+				storage = database.NewPipelineStorage(sqlStore, g.SecretsService)
+			} else {
+				storage = &pipeline.FileStorage{
+					DataPath:       cfg.DataPath,
+					SecretsService: g.SecretsService,
+				}
 			}
 			g.pipelineStorage = storage
 			builder = &pipeline.StorageRuleBuilder{
@@ -198,9 +236,12 @@ func ProvideService(plugCtxProvider *plugincontext.Provider, cfg *setting.Cfg, r
 				Storage:              storage,
 				ChannelHandlerGetter: g,
 				SecretsService:       g.SecretsService,
+				AlertSender:          &alertmanagerSender{alertNG: alertNG},
 			}
 		}
 		channelRuleGetter := pipeline.NewCacheSegmentedTree(builder)
+		g.channelRuleCache = channelRuleGetter
+		backfiller.ChannelRuleGetter = channelRuleGetter
 
 		// Pre-build/validate channel rules for all organizations on start.
 		// This can be unreasonable to have in production scenario with many
@@ -237,7 +278,7 @@ func ProvideService(plugCtxProvider *plugincontext.Provider, cfg *setting.Cfg, r
 	g.storage = database.NewStorage(g.SQLStore, g.CacheService)
 	g.GrafanaScope.Dashboards = dash
 	g.GrafanaScope.Features["dashboard"] = dash
-	g.GrafanaScope.Features["broadcast"] = features.NewBroadcastRunner(g.storage)
+	g.GrafanaScope.Features["broadcast"] = features.NewBroadcastRunner(g.storage, g.history)
 
 	g.surveyCaller = survey.NewCaller(managedStreamRunner, node)
 	err = g.surveyCaller.SetupHandlers()
@@ -245,6 +286,9 @@ func ProvideService(plugCtxProvider *plugincontext.Provider, cfg *setting.Cfg, r
 		return nil, err
 	}
 
+	// Notifications are sent to all nodes, including the current one.
+	node.OnNotification(g.handleNotification)
+
 	// Set ConnectHandler called when client successfully connected to Node. Your code
 	// inside handler must be synchronized since it will be called concurrently from
 	// different goroutines (belonging to different client connections). This is also
@@ -358,6 +402,11 @@ func ProvideService(plugCtxProvider *plugincontext.Provider, cfg *setting.Cfg, r
 
 	g.pushWebsocketHandler = func(ctx *models.ReqContext) {
 		user := ctx.SignedInUser
+		// Pushing into a stream requires publish access to all its channels.
+		channel := live.Channel{Scope: live.ScopeStream, Namespace: web.Params(ctx.Req)[":streamId"], Path: "*"}.String()
+		if !g.canPushWebsocket(ctx, channel) {
+			return
+		}
 		newCtx := livecontext.SetContextSignedUser(ctx.Req.Context(), user)
 		newCtx = livecontext.SetContextStreamID(newCtx, web.Params(ctx.Req)[":streamId"])
 		r := ctx.Req.WithContext(newCtx)
@@ -366,6 +415,9 @@ func ProvideService(plugCtxProvider *plugincontext.Provider, cfg *setting.Cfg, r
 
 	g.pushPipelineWebsocketHandler = func(ctx *models.ReqContext) {
 		user := ctx.SignedInUser
+		if !g.canPushWebsocket(ctx, web.Params(ctx.Req)["*"]) {
+			return
+		}
 		newCtx := livecontext.SetContextSignedUser(ctx.Req.Context(), user)
 		newCtx = livecontext.SetContextChannelID(newCtx, web.Params(ctx.Req)["*"])
 		r := ctx.Req.WithContext(newCtx)
@@ -379,7 +431,7 @@ func ProvideService(plugCtxProvider *plugincontext.Provider, cfg *setting.Cfg, r
 	g.RouteRegister.Group("/api/live", func(group routing.RouteRegister) {
 		group.Get("/push/:streamId", g.pushWebsocketHandler)
 		group.Get("/pipeline/push/*", g.pushPipelineWebsocketHandler)
-	}, middleware.ReqOrgAdmin)
+	}, middleware.ReqSignedIn)
 
 	g.registerUsageMetrics()
 
@@ -402,6 +454,7 @@ type GrafanaLive struct {
 	SecretsService        secrets.Service
 	pluginStore           plugins.Store
 	queryDataService      *query.Service
+	AccessControl         accesscontrol.AccessControl
 
 	node         *centrifuge.Node
 	surveyCaller *survey.Caller
@@ -421,10 +474,12 @@ type GrafanaLive struct {
 	ManagedStreamRunner *managedstream.Runner
 	Pipeline            *pipeline.Pipeline
 	pipelineStorage     pipeline.Storage
+	channelRuleCache    *pipeline.CacheSegmentedTree
 
 	contextGetter    *liveplugin.ContextGetter
 	runStreamManager *runstream.Manager
 	storage          *database.Storage
+	history          *history.History
 
 	usageStatsService usagestats.Service
 	usageStats        usageStats
@@ -626,6 +681,18 @@ func (g *GrafanaLive) handleOnSubscribe(ctx context.Context, client *centrifuge.
 		return centrifuge.SubscribeReply{}, centrifuge.ErrorPermissionDenied
 	}
 
+	// Everyone can subscribe unless restricted by channel rules or access control.
+	canSubscribe, err := g.HasChannelAccess(client.Context(), user, accesscontrol.ActionLiveChannelsSubscribe, channel, func() bool { return true })
+	if err != nil {
+		logger.Error("Error checking subscribe permissions", "user", client.UserID(), "client", client.ID(), "channel", e.Channel, "error", err)
+		return centrifuge.SubscribeReply{}, centrifuge.ErrorInternal
+	}
+	if !canSubscribe {
+		// using HTTP error codes for WS errors too.
+		code, text := subscribeStatusToHTTPError(backend.SubscribeStreamStatusPermissionDenied)
+		return centrifuge.SubscribeReply{}, &centrifuge.Error{Code: uint32(code), Message: text}
+	}
+
 	var reply models.SubscribeReply
 	var status backend.SubscribeStreamStatus
 	var ruleFound bool
@@ -651,11 +718,18 @@ func (g *GrafanaLive) handleOnSubscribe(ctx context.Context, client *centrifuge.
 				}
 			}
 			if len(rule.Subscribers) > 0 {
-				var err error
+				addr, err := live.ParseChannel(channel)
+				if err != nil {
+					logger.Info("Invalid channel ID", "user", client.UserID(), "client", client.ID(), "channel", e.Channel)
+					return centrifuge.SubscribeReply{}, &centrifuge.Error{Code: uint32(http.StatusBadRequest), Message: "invalid channel ID"}
+				}
 				for _, sub := range rule.Subscribers {
 					reply, status, err = sub.Subscribe(client.Context(), pipeline.Vars{
-						OrgID:   orgID,
-						Channel: channel,
+						OrgID:     orgID,
+						Channel:   channel,
+						Scope:     addr.Scope,
+						Namespace: addr.Namespace,
+						Path:      addr.Path,
 					}, e.Data)
 					if err != nil {
 						logger.Error("Error channel rule subscribe", "user", client.UserID(), "client", client.ID(), "channel", e.Channel, "error", err)
@@ -744,14 +818,18 @@ func (g *GrafanaLive) handleOnPublish(ctx context.Context, client *centrifuge.Cl
 					code, text := publishStatusToHTTPError(backend.PublishStreamStatusPermissionDenied)
 					return centrifuge.PublishReply{}, &centrifuge.Error{Code: uint32(code), Message: text}
 				}
-			} else {
-				if !user.HasRole(models.ROLE_ADMIN) {
-					// using HTTP error codes for WS errors too.
-					code, text := publishStatusToHTTPError(backend.PublishStreamStatusPermissionDenied)
-					return centrifuge.PublishReply{}, &centrifuge.Error{Code: uint32(code), Message: text}
-				}
 			}
-			_, err := g.Pipeline.ProcessInput(client.Context(), user.OrgId, channel, e.Data)
+			ok, err := g.canPublishRuleChannel(client.Context(), user, rule, channel)
+			if err != nil {
+				logger.Error("Error checking publish permissions", "user", client.UserID(), "client", client.ID(), "channel", e.Channel, "error", err)
+				return centrifuge.PublishReply{}, centrifuge.ErrorInternal
+			}
+			if !ok {
+				// using HTTP error codes for WS errors too.
+				code, text := publishStatusToHTTPError(backend.PublishStreamStatusPermissionDenied)
+				return centrifuge.PublishReply{}, &centrifuge.Error{Code: uint32(code), Message: text}
+			}
+			_, err = g.Pipeline.ProcessInput(client.Context(), user.OrgId, channel, e.Data)
 			if err != nil {
 				logger.Error("Error processing input", "user", client.UserID(), "client", client.ID(), "channel", e.Channel, "error", err)
 				return centrifuge.PublishReply{}, centrifuge.ErrorInternal
@@ -976,12 +1054,16 @@ func (g *GrafanaLive) HandleHTTPPublish(ctx *models.ReqContext) response.Respons
 				if !ok {
 					return response.Error(http.StatusForbidden, http.StatusText(http.StatusForbidden), nil)
 				}
-			} else {
-				if !user.HasRole(models.ROLE_ADMIN) {
-					return response.Error(http.StatusForbidden, http.StatusText(http.StatusForbidden), nil)
-				}
 			}
-			_, err := g.Pipeline.ProcessInput(ctx.Req.Context(), user.OrgId, channel, cmd.Data)
+			ok, err := g.canPublishRuleChannel(ctx.Req.Context(), user, rule, channel)
+			if err != nil {
+				logger.Error("Error checking publish permissions", "user", user, "channel", channel, "error", err)
+				return response.Error(http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError), nil)
+			}
+			if !ok {
+				return response.Error(http.StatusForbidden, http.StatusText(http.StatusForbidden), nil)
+			}
+			_, err = g.Pipeline.ProcessInput(ctx.Req.Context(), user.OrgId, channel, cmd.Data)
 			if err != nil {
 				logger.Error("Error processing input", "user", user, "channel", channel, "error", err)
 				return response.Error(http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError), nil)
@@ -1105,6 +1187,14 @@ func (s *DryRunRuleStorage) DeleteChannelRule(_ context.Context, _ int64, _ pipe
 	return errors.New("not implemented by dry run rule storage")
 }
 
+func (s *DryRunRuleStorage) ListWriteConfigVersions(_ context.Context, _ int64, _ pipeline.WriteConfigVersionsCmd) ([]pipeline.WriteConfigVersion, error) {
+	return nil, errors.New("not implemented by dry run rule storage")
+}
+
+func (s *DryRunRuleStorage) ListChannelRuleVersions(_ context.Context, _ int64, _ pipeline.ChannelRuleVersionsCmd) ([]pipeline.ChannelRuleVersion, error) {
+	return nil, errors.New("not implemented by dry run rule storage")
+}
+
 func (s *DryRunRuleStorage) ListWriteConfigs(_ context.Context, _ int64) ([]pipeline.WriteConfig, error) {
 	return nil, nil
 }
@@ -1169,10 +1259,12 @@ func (g *GrafanaLive) HandleChannelRulesPostHTTP(c *models.ReqContext) response.
 	if err != nil {
 		return response.Error(http.StatusBadRequest, "Error decoding channel rule", err)
 	}
+	cmd.UserID = c.UserId
 	rule, err := g.pipelineStorage.CreateChannelRule(c.Req.Context(), c.OrgId, cmd)
 	if err != nil {
-		return response.Error(http.StatusInternalServerError, "Failed to create channel rule", err)
+		return pipelineStorageError("Failed to create channel rule", err)
 	}
+	g.notifyPipelineChanged(c.OrgId)
 	return response.JSON(http.StatusOK, util.DynMap{
 		"rule": rule,
 	})
@@ -1192,10 +1284,12 @@ func (g *GrafanaLive) HandleChannelRulesPutHTTP(c *models.ReqContext) response.R
 	if cmd.Pattern == "" {
 		return response.Error(http.StatusBadRequest, "Rule pattern required", nil)
 	}
+	cmd.UserID = c.UserId
 	rule, err := g.pipelineStorage.UpdateChannelRule(c.Req.Context(), c.OrgId, cmd)
 	if err != nil {
-		return response.Error(http.StatusInternalServerError, "Failed to update channel rule", err)
+		return pipelineStorageError("Failed to update channel rule", err)
 	}
+	g.notifyPipelineChanged(c.OrgId)
 	return response.JSON(http.StatusOK, util.DynMap{
 		"rule": rule,
 	})
@@ -1215,13 +1309,32 @@ func (g *GrafanaLive) HandleChannelRulesDeleteHTTP(c *models.ReqContext) respons
 	if cmd.Pattern == "" {
 		return response.Error(http.StatusBadRequest, "Rule pattern required", nil)
 	}
+	cmd.UserID = c.UserId
 	err = g.pipelineStorage.DeleteChannelRule(c.Req.Context(), c.OrgId, cmd)
 	if err != nil {
-		return response.Error(http.StatusInternalServerError, "Failed to delete channel rule", err)
+		return pipelineStorageError("Failed to delete channel rule", err)
 	}
+	g.notifyPipelineChanged(c.OrgId)
 	return response.JSON(http.StatusOK, util.DynMap{})
 }
 
+// HandleChannelRuleVersionsListHTTP ...
+func (g *GrafanaLive) HandleChannelRuleVersionsListHTTP(c *models.ReqContext) response.Response {
+	pattern := c.Query("pattern")
+	if pattern == "" {
+		return response.Error(http.StatusBadRequest, "Rule pattern required", nil)
+	}
+	versions, err := g.pipelineStorage.ListChannelRuleVersions(c.Req.Context(), c.OrgId, pipeline.ChannelRuleVersionsCmd{
+		Pattern: pattern,
+	})
+	if err != nil {
+		return pipelineStorageError("Failed to get channel rule versions", err)
+	}
+	return response.JSON(http.StatusOK, util.DynMap{
+		"versions": versions,
+	})
+}
+
 // HandlePipelineEntitiesListHTTP ...
 func (g *GrafanaLive) HandlePipelineEntitiesListHTTP(_ *models.ReqContext) response.Response {
 	return response.JSON(http.StatusOK, util.DynMap{
@@ -1259,10 +1372,12 @@ func (g *GrafanaLive) HandleWriteConfigsPostHTTP(c *models.ReqContext) response.
 	if err != nil {
 		return response.Error(http.StatusBadRequest, "Error decoding write config create command", err)
 	}
+	cmd.UserID = c.UserId
 	result, err := g.pipelineStorage.CreateWriteConfig(c.Req.Context(), c.OrgId, cmd)
 	if err != nil {
-		return response.Error(http.StatusInternalServerError, "Failed to create write config", err)
+		return pipelineStorageError("Failed to create write config", err)
 	}
+	g.notifyPipelineChanged(c.OrgId)
 	return response.JSON(http.StatusOK, util.DynMap{
 		"writeConfig": pipeline.WriteConfigToDto(result),
 	})
@@ -1286,7 +1401,7 @@ func (g *GrafanaLive) HandleWriteConfigsPutHTTP(c *models.ReqContext) response.R
 		UID: cmd.UID,
 	})
 	if err != nil {
-		return response.Error(http.StatusInternalServerError, "Failed to get write config", err)
+		return pipelineStorageError("Failed to get write config", err)
 	}
 	if ok {
 		if cmd.SecureSettings == nil {
@@ -1303,10 +1418,12 @@ func (g *GrafanaLive) HandleWriteConfigsPutHTTP(c *models.ReqContext) response.R
 			}
 		}
 	}
+	cmd.UserID = c.UserId
 	result, err := g.pipelineStorage.UpdateWriteConfig(c.Req.Context(), c.OrgId, cmd)
 	if err != nil {
-		return response.Error(http.StatusInternalServerError, "Failed to update write config", err)
+		return pipelineStorageError("Failed to update write config", err)
 	}
+	g.notifyPipelineChanged(c.OrgId)
 	return response.JSON(http.StatusOK, util.DynMap{
 		"writeConfig": pipeline.WriteConfigToDto(result),
 	})
@@ -1326,13 +1443,80 @@ func (g *GrafanaLive) HandleWriteConfigsDeleteHTTP(c *models.ReqContext) respons
 	if cmd.UID == "" {
 		return response.Error(http.StatusBadRequest, "UID required", nil)
 	}
+	cmd.UserID = c.UserId
 	err = g.pipelineStorage.DeleteWriteConfig(c.Req.Context(), c.OrgId, cmd)
 	if err != nil {
-		return response.Error(http.StatusInternalServerError, "Failed to delete write config", err)
+		return pipelineStorageError("Failed to delete write config", err)
 	}
+	g.notifyPipelineChanged(c.OrgId)
 	return response.JSON(http.StatusOK, util.DynMap{})
 }
 
+// HandleWriteConfigVersionsListHTTP ...
+func (g *GrafanaLive) HandleWriteConfigVersionsListHTTP(c *models.ReqContext) response.Response {
+	uid := c.Query("uid")
+	if uid == "" {
+		return response.Error(http.StatusBadRequest, "UID required", nil)
+	}
+	versions, err := g.pipelineStorage.ListWriteConfigVersions(c.Req.Context(), c.OrgId, pipeline.WriteConfigVersionsCmd{
+		UID: uid,
+	})
+	if err != nil {
+		return pipelineStorageError("Failed to get write config versions", err)
+	}
+	return response.JSON(http.StatusOK, util.DynMap{
+		"versions": versions,
+	})
+}
+
+func pipelineStorageError(message string, err error) response.Response {
+	switch {
+	case errors.Is(err, pipeline.ErrVersionConflict):
+		return response.Error(http.StatusConflict, "Changed since the version the request is based on", err)
+	case errors.Is(err, pipeline.ErrChannelRuleNotFound), errors.Is(err, pipeline.ErrWriteConfigNotFound):
+		return response.Error(http.StatusNotFound, message, err)
+	case errors.Is(err, pipeline.ErrVersionsNotSupported):
+		return response.Error(http.StatusNotImplemented, message, err)
+	}
+	return response.Error(http.StatusInternalServerError, message, err)
+}
+
+const notificationOpPipelineChanged = "pipeline_changed"
+
+type pipelineChangedNotification struct {
+	OrgID int64 `json:"orgId"`
+}
+
+// notifyPipelineChanged makes all Grafana server instances reload channel
+// rules of an organization after pipeline storage changes.
+func (g *GrafanaLive) notifyPipelineChanged(orgID int64) {
+	data, err := json.Marshal(pipelineChangedNotification{OrgID: orgID})
+	if err != nil {
+		logger.Error("Error encoding pipeline change notification", "error", err)
+		return
+	}
+	if err := g.node.Notify(notificationOpPipelineChanged, data, ""); err != nil {
+		logger.Error("Error sending pipeline change notification", "error", err, "orgId", orgID)
+	}
+}
+
+func (g *GrafanaLive) handleNotification(e centrifuge.NotificationEvent) {
+	switch e.Op {
+	case notificationOpPipelineChanged:
+		if g.channelRuleCache == nil {
+			return
+		}
+		var n pipelineChangedNotification
+		if err := json.Unmarshal(e.Data, &n); err != nil {
+			logger.Error("Error decoding pipeline change notification", "error", err, "fromNode", e.FromNodeID)
+			return
+		}
+		if err := g.channelRuleCache.Reload(n.OrgID); err != nil {
+			logger.Error("Error reloading channel rules", "error", err, "orgId", n.OrgID)
+		}
+	}
+}
+
 // Write to the standard log15 logger
 func handleLog(msg centrifuge.LogEntry) {
 	arr := make([]interface{}, 0)
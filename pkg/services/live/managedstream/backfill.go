@@ -0,0 +1,234 @@
+package managedstream
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"sort"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/grafana/grafana/pkg/models"
+)
+
+// BackfillRequest is sent by a client in subscribe data to get the history of a
+// channel for the time range of a panel, stitched with the live frames.
+type BackfillRequest struct {
+	From time.Time
+	To   time.Time
+}
+
+// ParseBackfillRequest extracts a backfill request from subscribe data in the
+// {"backfill":{"from":<ms>,"to":<ms>}} format, a missing to means now. It returns
+// false when no backfill was requested.
+func ParseBackfillRequest(data json.RawMessage, now time.Time) (BackfillRequest, bool) {
+	if len(data) == 0 {
+		return BackfillRequest{}, false
+	}
+	var req struct {
+		Backfill *struct {
+			From int64 `json:"from"`
+			To   int64 `json:"to"`
+		} `json:"backfill"`
+	}
+	if err := json.Unmarshal(data, &req); err != nil || req.Backfill == nil || req.Backfill.From <= 0 {
+		return BackfillRequest{}, false
+	}
+	to := now
+	if req.Backfill.To > 0 && req.Backfill.To < now.UnixNano()/int64(time.Millisecond) {
+		to = time.Unix(0, req.Backfill.To*int64(time.Millisecond))
+	}
+	from := time.Unix(0, req.Backfill.From*int64(time.Millisecond))
+	if !from.Before(to) {
+		return BackfillRequest{}, false
+	}
+	return BackfillRequest{From: from, To: to}, true
+}
+
+// Backfiller queries the history of channels.
+type Backfiller interface {
+	// Backfill returns frames with the history of a channel for the requested time
+	// range. It returns false if history can't be queried for a channel.
+	Backfill(ctx context.Context, u *models.SignedInUser, channel string, req BackfillRequest) ([]*data.Frame, bool, error)
+}
+
+// stitchBackfill puts the values of backfill frames before the rows of the live
+// frame, so that a subscriber gets a single frame with the live frame schema and
+// later frames are appended to it. Live fields are matched with backfill fields
+// of the same name or display name, compatible type and labels. Backfill rows at
+// or after the first live row are skipped. When there is no live frame yet, the
+// first backfill frame is returned as is.
+func stitchBackfill(frames []*data.Frame, liveFrame *data.Frame) (*data.Frame, error) {
+	if liveFrame == nil {
+		if len(frames) == 0 {
+			return nil, nil
+		}
+		return frames[0], nil
+	}
+	liveTimeIndex := timeFieldIndex(liveFrame)
+	if liveTimeIndex < 0 {
+		return liveFrame, nil
+	}
+	liveRows, err := liveFrame.RowLen()
+	if err != nil {
+		return nil, err
+	}
+	var cutoff time.Time
+	for i := 0; i < liveRows; i++ {
+		if t, ok := timeAt(liveFrame.Fields[liveTimeIndex], i); ok && (cutoff.IsZero() || t.Before(cutoff)) {
+			cutoff = t
+		}
+	}
+
+	type source struct {
+		times  *data.Field
+		values *data.Field
+	}
+	sources := make([]*source, len(liveFrame.Fields))
+	timeSet := map[int64]time.Time{}
+	for i, field := range liveFrame.Fields {
+		if i == liveTimeIndex {
+			continue
+		}
+		for _, frame := range frames {
+			timeIndex := timeFieldIndex(frame)
+			if timeIndex < 0 {
+				continue
+			}
+			values := matchBackfillField(frame, field)
+			if values == nil {
+				continue
+			}
+			sources[i] = &source{times: frame.Fields[timeIndex], values: values}
+			for j := 0; j < values.Len(); j++ {
+				if t, ok := timeAt(frame.Fields[timeIndex], j); ok && (cutoff.IsZero() || t.Before(cutoff)) {
+					timeSet[t.UnixNano()] = t
+				}
+			}
+			break
+		}
+	}
+	if len(timeSet) == 0 {
+		return liveFrame, nil
+	}
+
+	times := make([]time.Time, 0, len(timeSet))
+	for _, t := range timeSet {
+		times = append(times, t)
+	}
+	sort.Slice(times, func(i, j int) bool {
+		return times[i].Before(times[j])
+	})
+	rowIndex := make(map[int64]int, len(times))
+	for i, t := range times {
+		rowIndex[t.UnixNano()] = i
+	}
+
+	fields := make([]*data.Field, len(liveFrame.Fields))
+	for i, liveField := range liveFrame.Fields {
+		field := data.NewFieldFromFieldType(liveField.Type(), len(times)+liveRows)
+		field.Name = liveField.Name
+		field.Labels = liveField.Labels
+		field.Config = liveField.Config
+		if i == liveTimeIndex {
+			for j, t := range times {
+				setConcrete(field, j, t)
+			}
+		} else if src := sources[i]; src != nil {
+			for j := 0; j < src.values.Len(); j++ {
+				t, ok := timeAt(src.times, j)
+				if !ok {
+					continue
+				}
+				row, ok := rowIndex[t.UnixNano()]
+				if !ok {
+					continue
+				}
+				if v, ok := backfillValue(field.Type(), src.values, j); ok {
+					setConcrete(field, row, v)
+				}
+			}
+		}
+		for j := 0; j < liveRows; j++ {
+			field.Set(len(times)+j, liveField.At(j))
+		}
+		fields[i] = field
+	}
+	stitched := data.NewFrame(liveFrame.Name, fields...)
+	stitched.RefID = liveFrame.RefID
+	stitched.Meta = liveFrame.Meta
+	return stitched, nil
+}
+
+func timeFieldIndex(frame *data.Frame) int {
+	for i, field := range frame.Fields {
+		if field.Type().Time() {
+			return i
+		}
+	}
+	return -1
+}
+
+func timeAt(field *data.Field, idx int) (time.Time, bool) {
+	v, ok := field.ConcreteAt(idx)
+	if !ok {
+		return time.Time{}, false
+	}
+	t, ok := v.(time.Time)
+	return t, ok
+}
+
+func matchBackfillField(frame *data.Frame, liveField *data.Field) *data.Field {
+	for _, field := range frame.Fields {
+		if field.Type().Time() {
+			continue
+		}
+		sameName := field.Name == liveField.Name ||
+			(field.Config != nil && field.Config.DisplayNameFromDS == liveField.Name)
+		if !sameName || !compatibleBackfillType(liveField.Type(), field.Type()) {
+			continue
+		}
+		if !containsLabels(field.Labels, liveField.Labels) {
+			continue
+		}
+		return field
+	}
+	return nil
+}
+
+func compatibleBackfillType(live data.FieldType, backfill data.FieldType) bool {
+	if live.NonNullableType() == backfill.NonNullableType() {
+		return true
+	}
+	return live.NonNullableType() == data.FieldTypeFloat64 && backfill.Numeric()
+}
+
+func containsLabels(labels data.Labels, subset data.Labels) bool {
+	for k, v := range subset {
+		if labels[k] != v {
+			return false
+		}
+	}
+	return true
+}
+
+func backfillValue(liveType data.FieldType, field *data.Field, idx int) (interface{}, bool) {
+	if liveType.NonNullableType() == data.FieldTypeFloat64 && field.Type().NonNullableType() != data.FieldTypeFloat64 {
+		v, err := field.NullableFloatAt(idx)
+		if err != nil || v == nil {
+			return nil, false
+		}
+		return *v, true
+	}
+	return field.ConcreteAt(idx)
+}
+
+// setConcrete sets a non-nil value, taking a pointer to it for nullable fields.
+func setConcrete(field *data.Field, idx int, v interface{}) {
+	if field.Type().Nullable() {
+		p := reflect.New(reflect.TypeOf(v))
+		p.Elem().Set(reflect.ValueOf(v))
+		v = p.Interface()
+	}
+	field.Set(idx, v)
+}
@@ -0,0 +1,139 @@
+package managedstream
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseBackfillRequest(t *testing.T) {
+	now := time.Unix(100, 0)
+
+	req, ok := ParseBackfillRequest(json.RawMessage(`{"backfill":{"from":10000,"to":20000}}`), now)
+	require.True(t, ok)
+	require.Equal(t, time.Unix(10, 0), req.From)
+	require.Equal(t, time.Unix(20, 0), req.To)
+
+	// Missing or future to is replaced with now.
+	req, ok = ParseBackfillRequest(json.RawMessage(`{"backfill":{"from":10000,"to":200000}}`), now)
+	require.True(t, ok)
+	require.Equal(t, now, req.To)
+
+	for _, d := range []string{``, `{}`, `{"replay":{"since":1}}`, `{"backfill":{}}`, `{"backfill":{"from":200000}}`, `invalid`} {
+		_, ok := ParseBackfillRequest(json.RawMessage(d), now)
+		require.False(t, ok, d)
+	}
+}
+
+func TestStitchBackfill(t *testing.T) {
+	liveFrame := data.NewFrame("cpu",
+		data.NewField("time", nil, []time.Time{time.Unix(30, 0), time.Unix(40, 0)}),
+		data.NewField("usage", data.Labels{"host": "a"}, []*float64{pointer(3), pointer(4)}),
+		data.NewField("state", nil, []string{"c", "d"}),
+	)
+	backfill := []*data.Frame{
+		data.NewFrame("A",
+			data.NewField("Time", nil, []time.Time{time.Unix(10, 0), time.Unix(20, 0), time.Unix(30, 0)}),
+			data.NewField("Value", data.Labels{"host": "a", "job": "telegraf"}, []int64{1, 2, 3}).SetConfig(&data.FieldConfig{
+				DisplayNameFromDS: "usage",
+			}),
+		),
+	}
+
+	stitched, err := stitchBackfill(backfill, liveFrame)
+	require.NoError(t, err)
+	require.Equal(t, 4, stitched.Rows())
+	require.Equal(t, data.FieldTypeNullableFloat64, stitched.Fields[1].Type())
+	require.Equal(t, data.Labels{"host": "a"}, stitched.Fields[1].Labels)
+	require.Equal(t, time.Unix(10, 0), stitched.Fields[0].At(0))
+	require.Equal(t, time.Unix(40, 0), stitched.Fields[0].At(3))
+	for i, v := range []float64{1, 2, 3, 4} {
+		require.Equal(t, v, *stitched.Fields[1].At(i).(*float64))
+	}
+	// Fields without backfill values have zero values.
+	require.Equal(t, "", stitched.Fields[2].At(0))
+	require.Equal(t, "c", stitched.Fields[2].At(2))
+
+	// Without live frame the backfill frame is used as is.
+	stitched, err = stitchBackfill(backfill, nil)
+	require.NoError(t, err)
+	require.Equal(t, backfill[0], stitched)
+
+	// Without matching fields the live frame is kept.
+	stitched, err = stitchBackfill([]*data.Frame{
+		data.NewFrame("A",
+			data.NewField("Time", nil, []time.Time{time.Unix(10, 0)}),
+			data.NewField("Value", data.Labels{"host": "b"}, []float64{1}).SetConfig(&data.FieldConfig{
+				DisplayNameFromDS: "usage",
+			}),
+		),
+	}, liveFrame)
+	require.NoError(t, err)
+	require.Equal(t, liveFrame, stitched)
+}
+
+func pointer(v float64) *float64 {
+	return &v
+}
+
+type testBackfiller struct {
+	frames []*data.Frame
+}
+
+func (b *testBackfiller) Backfill(_ context.Context, _ *models.SignedInUser, channel string, _ BackfillRequest) ([]*data.Frame, bool, error) {
+	if channel != "stream/test/cpu" {
+		return nil, false, nil
+	}
+	return b.frames, true, nil
+}
+
+func TestManagedStreamBackfill(t *testing.T) {
+	publisher := &testPublisher{t: t}
+	backfiller := &testBackfiller{frames: []*data.Frame{
+		data.NewFrame("A",
+			data.NewField("time", nil, []time.Time{time.Unix(1, 0), time.Unix(2, 0)}),
+			data.NewField("value", nil, []float64{1, 2}),
+		),
+	}}
+	s := NewNamespaceStream(1, "stream", "test", publisher.publish, nil, NewMemoryFrameCache(), nil, nil, backfiller)
+
+	for _, path := range []string{"cpu", "mem"} {
+		err := s.Push(context.Background(), path, data.NewFrame(path,
+			data.NewField("time", nil, []time.Time{time.Unix(3, 0)}),
+			data.NewField("value", nil, []float64{3}),
+		))
+		require.NoError(t, err)
+	}
+
+	user := &models.SignedInUser{OrgId: 1}
+	backfillData := json.RawMessage(`{"backfill":{"from":1000}}`)
+	reply, status, err := s.OnSubscribe(context.Background(), user, models.SubscribeEvent{
+		Channel: "stream/test/cpu",
+		Data:    backfillData,
+	})
+	require.NoError(t, err)
+	require.Equal(t, backend.SubscribeStreamStatusOK, status)
+
+	var frame data.Frame
+	require.NoError(t, json.Unmarshal(reply.Data, &frame))
+	require.Equal(t, "cpu", frame.Name)
+	require.Equal(t, 3, frame.Rows())
+	require.Equal(t, []float64{1, 2, 3}, []float64{
+		frame.Fields[1].At(0).(float64), frame.Fields[1].At(1).(float64), frame.Fields[1].At(2).(float64),
+	})
+
+	// Channels without history get the last frame.
+	reply, _, err = s.OnSubscribe(context.Background(), user, models.SubscribeEvent{
+		Channel: "stream/test/mem",
+		Data:    backfillData,
+	})
+	require.NoError(t, err)
+	require.NoError(t, json.Unmarshal(reply.Data, &frame))
+	require.Equal(t, 1, frame.Rows())
+}
@@ -0,0 +1,123 @@
+package managedstream
+
+import (
+	"errors"
+	"path"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/live"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+// ErrRateLimited is returned by NamespaceStream.Push when a frame exceeds a
+// rate limit with the reject policy.
+var ErrRateLimited = errors.New("rate limit exceeded")
+
+// Limiter applies [live.limits] rate limits to frames pushed into managed
+// streams. Rates are counted in one second windows per organization, namespace
+// or channel, depending on the limit level.
+type Limiter struct {
+	limits []setting.LiveLimit
+
+	mu          sync.Mutex
+	windows     map[string]*limitWindow
+	lastCleanup int64
+}
+
+type limitWindow struct {
+	second   int64
+	messages int
+	bytes    int
+}
+
+// NewLimiter creates a new Limiter.
+func NewLimiter(limits []setting.LiveLimit) *Limiter {
+	return &Limiter{limits: limits, windows: map[string]*limitWindow{}}
+}
+
+// Allow counts a frame of size bytes pushed into the channel. When the frame
+// exceeds a limit it is not counted, and the exceeded limit is returned with
+// false.
+func (l *Limiter) Allow(orgID int64, channel live.Channel, size int, now time.Time) (setting.LiveLimit, bool) {
+	if l == nil || len(l.limits) == 0 {
+		return setting.LiveLimit{}, true
+	}
+	limits := l.match(channel)
+	if len(limits) == 0 {
+		return setting.LiveLimit{}, true
+	}
+
+	second := now.Unix()
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if second != l.lastCleanup {
+		for key, w := range l.windows {
+			if w.second < second {
+				delete(l.windows, key)
+			}
+		}
+		l.lastCleanup = second
+	}
+
+	windows := make([]*limitWindow, 0, len(limits))
+	for _, m := range limits {
+		key := strconv.FormatInt(orgID, 10) + "/" + m.limit.Level + "/" + m.key
+		w, ok := l.windows[key]
+		if !ok {
+			w = &limitWindow{second: second}
+			l.windows[key] = w
+		}
+		if m.limit.MessagesPerSecond > 0 && w.messages+1 > m.limit.MessagesPerSecond {
+			return m.limit, false
+		}
+		if m.limit.BytesPerSecond > 0 && w.bytes+size > m.limit.BytesPerSecond {
+			return m.limit, false
+		}
+		windows = append(windows, w)
+	}
+	for _, w := range windows {
+		w.messages++
+		w.bytes += size
+	}
+	return setting.LiveLimit{}, true
+}
+
+type limitMatch struct {
+	limit setting.LiveLimit
+	// key is what the limit is counted for at its level.
+	key string
+}
+
+// match returns the first matching limit of every level.
+func (l *Limiter) match(channel live.Channel) []limitMatch {
+	namespace := channel.Scope + "/" + channel.Namespace
+	ch := channel.String()
+	var matches []limitMatch
+	seen := map[string]bool{}
+	for _, limit := range l.limits {
+		if seen[limit.Level] {
+			continue
+		}
+		var key string
+		switch limit.Level {
+		case setting.LiveLimitLevelOrg:
+		case setting.LiveLimitLevelNamespace:
+			if ok, _ := path.Match(limit.Pattern, namespace); !ok {
+				continue
+			}
+			key = namespace
+		case setting.LiveLimitLevelChannel:
+			if ok, _ := path.Match(limit.Pattern, ch); !ok {
+				continue
+			}
+			key = ch
+		default:
+			continue
+		}
+		seen[limit.Level] = true
+		matches = append(matches, limitMatch{limit: limit, key: key})
+	}
+	return matches
+}
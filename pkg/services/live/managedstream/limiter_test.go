@@ -0,0 +1,75 @@
+package managedstream
+
+import (
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/live"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLimiter(t *testing.T) {
+	limiter := NewLimiter([]setting.LiveLimit{
+		{Pattern: "org", Level: setting.LiveLimitLevelOrg, MessagesPerSecond: 5, Policy: setting.LiveLimitPolicyReject},
+		{Pattern: "stream/*", Level: setting.LiveLimitLevelNamespace, MessagesPerSecond: 3, Policy: setting.LiveLimitPolicyReject},
+		{Pattern: "stream/test/*", Level: setting.LiveLimitLevelChannel, MessagesPerSecond: 2, BytesPerSecond: 100, Policy: setting.LiveLimitPolicyDrop},
+	})
+	now := time.Unix(100, 0)
+	cpu := live.Channel{Scope: "stream", Namespace: "test", Path: "cpu"}
+	mem := live.Channel{Scope: "stream", Namespace: "test", Path: "mem"}
+	other := live.Channel{Scope: "stream", Namespace: "other", Path: "cpu"}
+
+	// Channel limit.
+	_, ok := limiter.Allow(1, cpu, 10, now)
+	require.True(t, ok)
+	_, ok = limiter.Allow(1, cpu, 10, now)
+	require.True(t, ok)
+	limit, ok := limiter.Allow(1, cpu, 10, now)
+	require.False(t, ok)
+	require.Equal(t, setting.LiveLimitLevelChannel, limit.Level)
+	require.Equal(t, setting.LiveLimitPolicyDrop, limit.Policy)
+
+	// Namespace limit is shared by channels of the namespace.
+	_, ok = limiter.Allow(1, mem, 10, now)
+	require.True(t, ok)
+	limit, ok = limiter.Allow(1, mem, 10, now)
+	require.False(t, ok)
+	require.Equal(t, setting.LiveLimitLevelNamespace, limit.Level)
+
+	// Org limit is shared by all namespaces.
+	_, ok = limiter.Allow(1, other, 10, now)
+	require.True(t, ok)
+	_, ok = limiter.Allow(1, other, 10, now)
+	require.True(t, ok)
+	limit, ok = limiter.Allow(1, other, 10, now)
+	require.False(t, ok)
+	require.Equal(t, setting.LiveLimitLevelOrg, limit.Level)
+
+	// Other organizations are counted separately.
+	_, ok = limiter.Allow(2, cpu, 10, now)
+	require.True(t, ok)
+
+	// Counters are reset every second.
+	_, ok = limiter.Allow(1, cpu, 10, now.Add(time.Second))
+	require.True(t, ok)
+
+	// Bytes limit.
+	limit, ok = limiter.Allow(1, mem, 101, now.Add(2*time.Second))
+	require.False(t, ok)
+	require.Equal(t, setting.LiveLimitLevelChannel, limit.Level)
+}
+
+func TestLimiter_NoLimits(t *testing.T) {
+	var limiter *Limiter
+	_, ok := limiter.Allow(1, live.Channel{Scope: "stream", Namespace: "test", Path: "cpu"}, 10, time.Now())
+	require.True(t, ok)
+
+	limiter = NewLimiter([]setting.LiveLimit{
+		{Pattern: "plugin/*", Level: setting.LiveLimitLevelNamespace, MessagesPerSecond: 1, Policy: setting.LiveLimitPolicyReject},
+	})
+	for i := 0; i < 10; i++ {
+		_, ok := limiter.Allow(1, live.Channel{Scope: "stream", Namespace: "test", Path: "cpu"}, 10, time.Now())
+		require.True(t, ok)
+	}
+}
@@ -0,0 +1,17 @@
+package managedstream
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var limitedTotal *prometheus.CounterVec
+
+func init() {
+	limitedTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "grafana",
+		Subsystem: "live_managed_stream",
+		Name:      "limited_total",
+		Help:      "Number of frames pushed into managed streams over a rate limit, per limit level and policy.",
+	}, []string{"level", "policy"})
+}
@@ -8,6 +8,7 @@ import (
 	"sync"
 	"time"
 
+	"github.com/grafana/grafana/pkg/services/live/history"
 	"github.com/grafana/grafana/pkg/services/live/orgchannel"
 
 	"github.com/grafana/grafana-plugin-sdk-go/backend"
@@ -15,6 +16,7 @@ import (
 	"github.com/grafana/grafana-plugin-sdk-go/live"
 	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/setting"
 )
 
 var (
@@ -41,19 +43,28 @@ type Runner struct {
 	publisher      models.ChannelPublisher
 	localPublisher LocalPublisher
 	frameCache     FrameCache
+	history        *history.History
+	limiter        *Limiter
+	backfiller     Backfiller
 }
 
 type LocalPublisher interface {
 	PublishLocal(channel string, data []byte) error
 }
 
-// NewRunner creates new Runner.
-func NewRunner(publisher models.ChannelPublisher, localPublisher LocalPublisher, frameCache FrameCache) *Runner {
+// NewRunner creates new Runner. History is optional, when set frames pushed into
+// channels with history can be replayed by subscribers. Limiter is optional, when
+// set frames over rate limits are rejected or dropped. Backfiller is optional, when
+// set subscribers can request channel history for the time range of a panel.
+func NewRunner(publisher models.ChannelPublisher, localPublisher LocalPublisher, frameCache FrameCache, history *history.History, limiter *Limiter, backfiller Backfiller) *Runner {
 	return &Runner{
 		publisher:      publisher,
 		localPublisher: localPublisher,
 		streams:        map[int64]map[string]*NamespaceStream{},
 		frameCache:     frameCache,
+		history:        history,
+		limiter:        limiter,
+		backfiller:     backfiller,
 	}
 }
 
@@ -74,6 +85,7 @@ func (r *Runner) GetManagedChannels(orgID int64) ([]*ManagedChannel, error) {
 		namespaceStream, ok := r.streams[orgID][prefix]
 		if ok {
 			managedChannel.MinuteRate = namespaceStream.minuteRate(channel.Path)
+			managedChannel.MinuteLimited = namespaceStream.minuteLimited(channel.Path)
 		}
 		channels = append(channels, managedChannel)
 	}
@@ -124,7 +136,7 @@ func (r *Runner) GetOrCreateStream(orgID int64, scope string, namespace string)
 	prefix := scope + "/" + namespace
 	s, ok := r.streams[orgID][prefix]
 	if !ok {
-		s = NewNamespaceStream(orgID, scope, namespace, r.publisher, r.localPublisher, r.frameCache)
+		s = NewNamespaceStream(orgID, scope, namespace, r.publisher, r.localPublisher, r.frameCache, r.history, r.limiter, r.backfiller)
 		r.streams[orgID][prefix] = s
 	}
 	return s, nil
@@ -138,8 +150,12 @@ type NamespaceStream struct {
 	publisher      models.ChannelPublisher
 	localPublisher LocalPublisher
 	frameCache     FrameCache
+	history        *history.History
+	limiter        *Limiter
+	backfiller     Backfiller
 	rateMu         sync.RWMutex
 	rates          map[string][60]rateEntry
+	limitedRates   map[string][60]rateEntry
 }
 
 type rateEntry struct {
@@ -149,13 +165,15 @@ type rateEntry struct {
 
 // ManagedChannel represents a managed stream.
 type ManagedChannel struct {
-	Channel    string          `json:"channel"`
-	MinuteRate int64           `json:"minute_rate"`
-	Data       json.RawMessage `json:"data"`
+	Channel    string `json:"channel"`
+	MinuteRate int64  `json:"minute_rate"`
+	// MinuteLimited is the number of frames over rate limits in the last minute.
+	MinuteLimited int64           `json:"minute_limited"`
+	Data          json.RawMessage `json:"data"`
 }
 
 // NewNamespaceStream creates new NamespaceStream.
-func NewNamespaceStream(orgID int64, scope string, namespace string, publisher models.ChannelPublisher, localPublisher LocalPublisher, schemaUpdater FrameCache) *NamespaceStream {
+func NewNamespaceStream(orgID int64, scope string, namespace string, publisher models.ChannelPublisher, localPublisher LocalPublisher, schemaUpdater FrameCache, history *history.History, limiter *Limiter, backfiller Backfiller) *NamespaceStream {
 	return &NamespaceStream{
 		orgID:          orgID,
 		scope:          scope,
@@ -163,12 +181,17 @@ func NewNamespaceStream(orgID int64, scope string, namespace string, publisher m
 		publisher:      publisher,
 		localPublisher: localPublisher,
 		frameCache:     schemaUpdater,
+		history:        history,
+		limiter:        limiter,
+		backfiller:     backfiller,
 		rates:          map[string][60]rateEntry{},
+		limitedRates:   map[string][60]rateEntry{},
 	}
 }
 
 // Push sends frame to the stream and saves it for later retrieval by subscribers.
-// * Saves the entire frame to cache.
+// * Frames over rate limits are dropped, or rejected with ErrRateLimited.
+// * Saves the entire frame to cache, and to history if kept for the channel.
 // * If schema has been changed sends entire frame to channel, otherwise only data.
 func (s *NamespaceStream) Push(ctx context.Context, path string, frame *data.Frame) error {
 	jsonFrameCache, err := data.FrameToJSONCache(frame)
@@ -177,7 +200,19 @@ func (s *NamespaceStream) Push(ctx context.Context, path string, frame *data.Fra
 	}
 
 	// The channel this will be posted into.
-	channel := live.Channel{Scope: s.scope, Namespace: s.namespace, Path: path}.String()
+	liveChannel := live.Channel{Scope: s.scope, Namespace: s.namespace, Path: path}
+	channel := liveChannel.String()
+
+	now := time.Now()
+	if limit, ok := s.limiter.Allow(s.orgID, liveChannel, len(jsonFrameCache.Bytes(data.IncludeAll)), now); !ok {
+		s.incLimited(path, now.Unix())
+		limitedTotal.WithLabelValues(limit.Level, limit.Policy).Inc()
+		if limit.Policy == setting.LiveLimitPolicyDrop {
+			logger.Debug("Frame dropped by rate limit", "channel", channel, "limit", limit.Pattern)
+			return nil
+		}
+		return fmt.Errorf("%w: %s limit %s", ErrRateLimited, limit.Level, limit.Pattern)
+	}
 
 	isUpdated, err := s.frameCache.Update(ctx, s.orgID, channel, jsonFrameCache)
 	if err != nil {
@@ -185,6 +220,12 @@ func (s *NamespaceStream) Push(ctx context.Context, path string, frame *data.Fra
 		return err
 	}
 
+	// History is best effort, subscribers still get the frame when it can't be kept.
+	msg, kept, err := s.history.Add(ctx, s.orgID, channel, jsonFrameCache.Bytes(data.IncludeAll))
+	if err != nil {
+		logger.Error("Error adding frame to managed stream history", "channel", channel, "error", err)
+	}
+
 	// When the schema has not changed, just send the data.
 	include := data.IncludeDataOnly
 	if isUpdated {
@@ -192,9 +233,17 @@ func (s *NamespaceStream) Push(ctx context.Context, path string, frame *data.Fra
 		include = data.IncludeAll
 	}
 	frameJSON := jsonFrameCache.Bytes(include)
+	if kept {
+		// The offset is part of the frame meta, so frames kept in history are sent
+		// with their schema for subscribers to resume from the last offset they got.
+		frameJSON, err = historyOffsetFrameJSON(frame, msg.Offset)
+		if err != nil {
+			return err
+		}
+	}
 
 	logger.Debug("Publish data to channel", "channel", channel, "dataLength", len(frameJSON))
-	s.incRate(path, time.Now().Unix())
+	s.incRate(path, now.Unix())
 	if s.scope == live.ScopeDatasource || s.scope == live.ScopePlugin {
 		return s.localPublisher.PublishLocal(orgchannel.PrependOrgID(s.orgID, channel), frameJSON)
 	}
@@ -203,7 +252,30 @@ func (s *NamespaceStream) Push(ctx context.Context, path string, frame *data.Fra
 
 func (s *NamespaceStream) incRate(path string, nowUnix int64) {
 	s.rateMu.Lock()
-	pathRate, ok := s.rates[path]
+	addRate(s.rates, path, nowUnix)
+	s.rateMu.Unlock()
+}
+
+func (s *NamespaceStream) minuteRate(path string) int64 {
+	s.rateMu.RLock()
+	defer s.rateMu.RUnlock()
+	return sumRate(s.rates, path)
+}
+
+func (s *NamespaceStream) incLimited(path string, nowUnix int64) {
+	s.rateMu.Lock()
+	addRate(s.limitedRates, path, nowUnix)
+	s.rateMu.Unlock()
+}
+
+func (s *NamespaceStream) minuteLimited(path string) int64 {
+	s.rateMu.RLock()
+	defer s.rateMu.RUnlock()
+	return sumRate(s.limitedRates, path)
+}
+
+func addRate(rates map[string][60]rateEntry, path string, nowUnix int64) {
+	pathRate, ok := rates[path]
 	if !ok {
 		pathRate = [60]rateEntry{}
 	}
@@ -214,15 +286,12 @@ func (s *NamespaceStream) incRate(path string, nowUnix int64) {
 	}
 	pathRate[slot].time = uint32(nowUnix)
 	pathRate[slot].count += 1
-	s.rates[path] = pathRate
-	s.rateMu.Unlock()
+	rates[path] = pathRate
 }
 
-func (s *NamespaceStream) minuteRate(path string) int64 {
+func sumRate(rates map[string][60]rateEntry, path string) int64 {
 	var total int64
-	s.rateMu.RLock()
-	defer s.rateMu.RUnlock()
-	pathRate, ok := s.rates[path]
+	pathRate, ok := rates[path]
 	if !ok {
 		return 0
 	}
@@ -240,16 +309,127 @@ func (s *NamespaceStream) GetHandlerForPath(_ string) (models.ChannelHandler, er
 
 func (s *NamespaceStream) OnSubscribe(ctx context.Context, u *models.SignedInUser, e models.SubscribeEvent) (models.SubscribeReply, backend.SubscribeStreamStatus, error) {
 	reply := models.SubscribeReply{}
+	if req, ok := history.ParseReplayRequest(e.Data); ok && s.history.Enabled(e.Channel) {
+		messages, err := s.history.Since(ctx, u.OrgId, e.Channel, req.Since)
+		if err != nil {
+			return reply, 0, err
+		}
+		frameJSON, err := replayFrame(messages)
+		if err != nil {
+			return reply, 0, err
+		}
+		reply.Data = frameJSON
+		return reply, backend.SubscribeStreamStatusOK, nil
+	}
 	frameJSON, ok, err := s.frameCache.GetFrame(ctx, u.OrgId, e.Channel)
 	if err != nil {
 		return reply, 0, err
 	}
+	if req, backfill := ParseBackfillRequest(e.Data, time.Now()); backfill && s.backfiller != nil {
+		backfillJSON, err := s.backfill(ctx, u, e.Channel, req, frameJSON)
+		if err == nil && backfillJSON != nil {
+			reply.Data = backfillJSON
+			return reply, backend.SubscribeStreamStatusOK, nil
+		}
+		if err != nil {
+			// Live frames are still useful without history.
+			logger.Warn("Error backfilling managed stream", "channel", e.Channel, "error", err)
+		}
+	}
 	if ok {
 		reply.Data = frameJSON
 	}
 	return reply, backend.SubscribeStreamStatusOK, nil
 }
 
+// backfill returns the history of a channel stitched with the cached frame. It
+// returns nil when the channel has no history.
+func (s *NamespaceStream) backfill(ctx context.Context, u *models.SignedInUser, channel string, req BackfillRequest, frameJSON json.RawMessage) (json.RawMessage, error) {
+	frames, ok, err := s.backfiller.Backfill(ctx, u, channel, req)
+	if err != nil || !ok {
+		return nil, err
+	}
+	var liveFrame *data.Frame
+	if len(frameJSON) > 0 {
+		liveFrame = &data.Frame{}
+		if err := json.Unmarshal(frameJSON, liveFrame); err != nil {
+			return nil, err
+		}
+	}
+	stitched, err := stitchBackfill(frames, liveFrame)
+	if err != nil || stitched == nil {
+		return nil, err
+	}
+	return data.FrameToJSON(stitched, data.IncludeAll)
+}
+
 func (s *NamespaceStream) OnPublish(_ context.Context, _ *models.SignedInUser, _ models.PublishEvent) (models.PublishReply, backend.PublishStreamStatus, error) {
 	return models.PublishReply{}, backend.PublishStreamStatusPermissionDenied, nil
 }
+
+// replayFrame merges the frames kept in history into a single frame, so that a
+// subscriber receives all of them at once. When the schema changed, only the frames
+// with the latest schema are kept. The offset of the last frame is set in the
+// historyOffset custom meta, unless the frames have their own custom meta.
+func replayFrame(messages []history.Message) (json.RawMessage, error) {
+	if len(messages) == 0 {
+		return nil, nil
+	}
+
+	var merged *data.Frame
+	for _, msg := range messages {
+		var frame data.Frame
+		if err := json.Unmarshal(msg.Data, &frame); err != nil {
+			return nil, fmt.Errorf("error decoding managed stream history frame: %w", err)
+		}
+		if merged == nil || !sameSchema(merged, &frame) {
+			merged = &frame
+			continue
+		}
+		for i, field := range frame.Fields {
+			for j := 0; j < field.Len(); j++ {
+				merged.Fields[i].Append(field.At(j))
+			}
+		}
+	}
+
+	if merged.Meta == nil {
+		merged.Meta = &data.FrameMeta{}
+	}
+	if merged.Meta.Custom == nil {
+		merged.Meta.Custom = map[string]interface{}{
+			"historyOffset": messages[len(messages)-1].Offset,
+		}
+	}
+	return data.FrameToJSON(merged, data.IncludeAll)
+}
+
+// historyOffsetFrameJSON encodes frame with the offset in history set in the
+// historyOffset custom meta, like replayed frames, unless the frame has its own
+// custom meta.
+func historyOffsetFrameJSON(frame *data.Frame, offset uint64) (json.RawMessage, error) {
+	f := *frame
+	meta := data.FrameMeta{}
+	if f.Meta != nil {
+		meta = *f.Meta
+	}
+	if meta.Custom == nil {
+		meta.Custom = map[string]interface{}{
+			"historyOffset": offset,
+		}
+	}
+	f.Meta = &meta
+	return data.FrameToJSON(&f, data.IncludeAll)
+}
+
+func sameSchema(a *data.Frame, b *data.Frame) bool {
+	if a.Name != b.Name || len(a.Fields) != len(b.Fields) {
+		return false
+	}
+	for i := range a.Fields {
+		if a.Fields[i].Name != b.Fields[i].Name || a.Fields[i].Type() != b.Fields[i].Type() {
+			return false
+		}
+	}
+	return true
+}
@@ -2,10 +2,17 @@ package managedstream
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
 	"testing"
 	"time"
 
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
 	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/live/history"
+	"github.com/grafana/grafana/pkg/setting"
 	"github.com/stretchr/testify/require"
 )
 
@@ -19,13 +26,13 @@ func (p *testPublisher) publish(_ int64, _ string, _ []byte) error {
 
 func TestNewManagedStream(t *testing.T) {
 	publisher := &testPublisher{t: t}
-	c := NewNamespaceStream(1, "stream", "a", publisher.publish, nil, NewMemoryFrameCache())
+	c := NewNamespaceStream(1, "stream", "a", publisher.publish, nil, NewMemoryFrameCache(), nil, nil, nil)
 	require.NotNil(t, c)
 }
 
 func TestManagedStreamMinuteRate(t *testing.T) {
 	publisher := &testPublisher{t: t}
-	c := NewNamespaceStream(1, "stream", "a", publisher.publish, nil, NewMemoryFrameCache())
+	c := NewNamespaceStream(1, "stream", "a", publisher.publish, nil, NewMemoryFrameCache(), nil, nil, nil)
 	require.NotNil(t, c)
 
 	c.incRate("test1", time.Now().Unix())
@@ -48,7 +55,7 @@ func TestManagedStreamMinuteRate(t *testing.T) {
 func TestGetManagedStreams(t *testing.T) {
 	publisher := &testPublisher{t: t}
 	frameCache := NewMemoryFrameCache()
-	runner := NewRunner(publisher.publish, nil, frameCache)
+	runner := NewRunner(publisher.publish, nil, frameCache, nil, nil, nil)
 	s1, err := runner.GetOrCreateStream(1, "stream", "test1")
 	require.NoError(t, err)
 	s2, err := runner.GetOrCreateStream(1, "stream", "test2")
@@ -83,3 +90,135 @@ func TestGetManagedStreams(t *testing.T) {
 	require.NoError(t, err)
 	require.Len(t, managedChannels, 7) // Not affected by other org.
 }
+
+func TestManagedStreamReplay(t *testing.T) {
+	publisher := &testPublisher{t: t}
+	hist := history.New(history.NewMemoryStorage(), &setting.Cfg{LiveHistorySize: 2})
+	s := NewNamespaceStream(1, "stream", "test", publisher.publish, nil, NewMemoryFrameCache(), hist, nil, nil)
+
+	for i := 1; i <= 3; i++ {
+		err := s.Push(context.Background(), "cpu", data.NewFrame("cpu",
+			data.NewField("time", nil, []time.Time{time.Unix(int64(i), 0)}),
+			data.NewField("value", nil, []float64{float64(i)}),
+		))
+		require.NoError(t, err)
+	}
+
+	user := &models.SignedInUser{OrgId: 1}
+	reply, status, err := s.OnSubscribe(context.Background(), user, models.SubscribeEvent{
+		Channel: "stream/test/cpu",
+		Data:    json.RawMessage(`{"replay": {"since": 0}}`),
+	})
+	require.NoError(t, err)
+	require.Equal(t, backend.SubscribeStreamStatusOK, status)
+
+	var frame data.Frame
+	require.NoError(t, json.Unmarshal(reply.Data, &frame))
+	require.Equal(t, 2, frame.Rows())
+	require.Equal(t, 2., frame.Fields[1].At(0))
+	require.Equal(t, 3., frame.Fields[1].At(1))
+	require.Equal(t, map[string]interface{}{"historyOffset": 3.}, frame.Meta.Custom)
+
+	reply, _, err = s.OnSubscribe(context.Background(), user, models.SubscribeEvent{
+		Channel: "stream/test/cpu",
+		Data:    json.RawMessage(`{"replay": {"since": 3}}`),
+	})
+	require.NoError(t, err)
+	require.Nil(t, reply.Data)
+
+	// Without a replay request only the last frame is returned.
+	reply, _, err = s.OnSubscribe(context.Background(), user, models.SubscribeEvent{Channel: "stream/test/cpu"})
+	require.NoError(t, err)
+	require.NoError(t, json.Unmarshal(reply.Data, &frame))
+	require.Equal(t, 1, frame.Rows())
+}
+
+func TestManagedStreamResubscribeFromOffset(t *testing.T) {
+	var published []json.RawMessage
+	publisher := func(_ int64, _ string, data []byte) error {
+		published = append(published, data)
+		return nil
+	}
+	hist := history.New(history.NewMemoryStorage(), &setting.Cfg{LiveHistorySize: 10})
+	s := NewNamespaceStream(1, "stream", "test", publisher, nil, NewMemoryFrameCache(), hist, nil, nil)
+
+	for i := 1; i <= 3; i++ {
+		err := s.Push(context.Background(), "cpu", data.NewFrame("cpu",
+			data.NewField("time", nil, []time.Time{time.Unix(int64(i), 0)}),
+			data.NewField("value", nil, []float64{float64(i)}),
+		))
+		require.NoError(t, err)
+	}
+	require.Len(t, published, 3)
+
+	// A subscriber got the first two frames and resubscribes from the offset of the last one.
+	var lastSeen data.Frame
+	require.NoError(t, json.Unmarshal(published[1], &lastSeen))
+	require.Equal(t, 1, lastSeen.Rows())
+	require.Equal(t, 2., lastSeen.Fields[1].At(0))
+	offset, ok := lastSeen.Meta.Custom.(map[string]interface{})["historyOffset"].(float64)
+	require.True(t, ok)
+	require.Equal(t, 2., offset)
+
+	reply, _, err := s.OnSubscribe(context.Background(), &models.SignedInUser{OrgId: 1}, models.SubscribeEvent{
+		Channel: "stream/test/cpu",
+		Data:    json.RawMessage(fmt.Sprintf(`{"replay": {"since": %d}}`, int(offset))),
+	})
+	require.NoError(t, err)
+
+	var frame data.Frame
+	require.NoError(t, json.Unmarshal(reply.Data, &frame))
+	require.Equal(t, 1, frame.Rows())
+	require.Equal(t, 3., frame.Fields[1].At(0))
+	require.Equal(t, map[string]interface{}{"historyOffset": 3.}, frame.Meta.Custom)
+}
+
+type failingHistoryStorage struct{}
+
+func (failingHistoryStorage) Add(context.Context, int64, string, []byte, history.Options) (history.Message, error) {
+	return history.Message{}, errors.New("storage unavailable")
+}
+
+func (failingHistoryStorage) Since(context.Context, int64, string, uint64, history.Options) ([]history.Message, error) {
+	return nil, errors.New("storage unavailable")
+}
+
+func TestManagedStreamPushWithHistoryError(t *testing.T) {
+	var published int
+	publisher := func(_ int64, _ string, _ []byte) error {
+		published++
+		return nil
+	}
+	hist := history.New(failingHistoryStorage{}, &setting.Cfg{LiveHistorySize: 2})
+	s := NewNamespaceStream(1, "stream", "test", publisher, nil, NewMemoryFrameCache(), hist, nil, nil)
+
+	require.NoError(t, s.Push(context.Background(), "cpu", data.NewFrame("cpu")))
+	require.Equal(t, 1, published)
+}
+
+func TestManagedStreamLimits(t *testing.T) {
+	publisher := &testPublisher{t: t}
+	limiter := NewLimiter([]setting.LiveLimit{
+		{Pattern: "stream/test/cpu", Level: setting.LiveLimitLevelChannel, MessagesPerSecond: 1, Policy: setting.LiveLimitPolicyReject},
+		{Pattern: "stream/test/*", Level: setting.LiveLimitLevelChannel, MessagesPerSecond: 1, Policy: setting.LiveLimitPolicyDrop},
+	})
+	runner := NewRunner(publisher.publish, nil, NewMemoryFrameCache(), nil, limiter, nil)
+	s, err := runner.GetOrCreateStream(1, "stream", "test")
+	require.NoError(t, err)
+
+	require.NoError(t, s.Push(context.Background(), "cpu", data.NewFrame("cpu")))
+	err = s.Push(context.Background(), "cpu", data.NewFrame("cpu"))
+	require.ErrorIs(t, err, ErrRateLimited)
+
+	require.NoError(t, s.Push(context.Background(), "mem", data.NewFrame("mem")))
+	require.NoError(t, s.Push(context.Background(), "mem", data.NewFrame("mem")))
+
+	managedChannels, err := runner.GetManagedChannels(1)
+	require.NoError(t, err)
+	limited := map[string]int64{}
+	for _, ch := range managedChannels {
+		limited[ch.Channel] = ch.MinuteLimited
+	}
+	require.Equal(t, int64(1), limited["stream/test/cpu"])
+	require.Equal(t, int64(1), limited["stream/test/mem"])
+}
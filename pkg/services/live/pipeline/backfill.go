@@ -0,0 +1,127 @@
+package pipeline
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/grafana/grafana-plugin-sdk-go/live"
+	"github.com/grafana/grafana/pkg/api/dtos"
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/live/managedstream"
+)
+
+// BackfillConfig describes a datasource query returning the history of channels
+// matching a rule, for example from the remote write target frames are written to.
+type BackfillConfig struct {
+	// DatasourceUID is the UID of a datasource to query.
+	DatasourceUID string `json:"datasourceUid"`
+	// Query is a datasource query model. In string values ${scope}, ${namespace},
+	// ${path} and ${channel} are replaced with parts of the channel.
+	Query map[string]interface{} `json:"query"`
+	// MaxDataPoints limits the number of returned points, 1000 by default.
+	MaxDataPoints int64 `json:"maxDataPoints,omitempty"`
+}
+
+const defaultBackfillMaxDataPoints = 1000
+
+// QueryDataService runs datasource queries on behalf of a user.
+type QueryDataService interface {
+	QueryData(ctx context.Context, user *models.SignedInUser, skipCache bool, reqDTO dtos.MetricRequest, handleExpressions bool) (*backend.QueryDataResponse, error)
+}
+
+// Backfiller queries the history of channels with the backfill settings of their
+// rules. It implements managedstream.Backfiller.
+type Backfiller struct {
+	// ChannelRuleGetter is set once rules are loaded, channels have no history
+	// until then.
+	ChannelRuleGetter ChannelRuleGetter
+	QueryDataService  QueryDataService
+}
+
+func (b *Backfiller) Backfill(ctx context.Context, u *models.SignedInUser, channel string, req managedstream.BackfillRequest) ([]*data.Frame, bool, error) {
+	if b.ChannelRuleGetter == nil {
+		return nil, false, nil
+	}
+	rule, ok, err := b.ChannelRuleGetter.Get(u.OrgId, channel)
+	if err != nil || !ok || rule.Backfill == nil {
+		return nil, false, err
+	}
+	ch, err := live.ParseChannel(channel)
+	if err != nil {
+		return nil, false, err
+	}
+	vars := Vars{
+		OrgID:     u.OrgId,
+		Channel:   channel,
+		Scope:     ch.Scope,
+		Namespace: ch.Namespace,
+		Path:      ch.Path,
+	}
+	resp, err := b.QueryDataService.QueryData(ctx, u, false, backfillMetricRequest(*rule.Backfill, vars, req), false)
+	if err != nil {
+		return nil, false, fmt.Errorf("error querying backfill: %w", err)
+	}
+	res, ok := resp.Responses[backfillRefID]
+	if !ok {
+		return nil, true, nil
+	}
+	if res.Error != nil {
+		return nil, false, fmt.Errorf("error querying backfill: %w", res.Error)
+	}
+	return res.Frames, true, nil
+}
+
+const backfillRefID = "A"
+
+func backfillMetricRequest(config BackfillConfig, vars Vars, req managedstream.BackfillRequest) dtos.MetricRequest {
+	maxDataPoints := config.MaxDataPoints
+	if maxDataPoints <= 0 {
+		maxDataPoints = defaultBackfillMaxDataPoints
+	}
+	intervalMs := req.To.Sub(req.From).Milliseconds() / maxDataPoints
+	if intervalMs < 1 {
+		intervalMs = 1
+	}
+	query := simplejson.NewFromAny(expandQueryTemplates(config.Query, vars))
+	query.Set("refId", backfillRefID)
+	query.Set("datasource", map[string]interface{}{"uid": config.DatasourceUID})
+	query.Set("maxDataPoints", maxDataPoints)
+	query.Set("intervalMs", intervalMs)
+	return dtos.MetricRequest{
+		From:    strconv.FormatInt(req.From.UnixNano()/int64(time.Millisecond), 10),
+		To:      strconv.FormatInt(req.To.UnixNano()/int64(time.Millisecond), 10),
+		Queries: []*simplejson.Json{query},
+	}
+}
+
+// expandQueryTemplates returns a copy of a query model with channel templates
+// replaced in string values.
+func expandQueryTemplates(query map[string]interface{}, vars Vars) map[string]interface{} {
+	expanded := make(map[string]interface{}, len(query))
+	for k, v := range query {
+		expanded[k] = expandQueryValue(v, vars)
+	}
+	return expanded
+}
+
+func expandQueryValue(v interface{}, vars Vars) interface{} {
+	switch value := v.(type) {
+	case string:
+		return expandChannelTemplate(value, vars)
+	case map[string]interface{}:
+		return expandQueryTemplates(value, vars)
+	case []interface{}:
+		expanded := make([]interface{}, len(value))
+		for i, item := range value {
+			expanded[i] = expandQueryValue(item, vars)
+		}
+		return expanded
+	default:
+		return v
+	}
+}
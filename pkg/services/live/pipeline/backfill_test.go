@@ -0,0 +1,87 @@
+package pipeline
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/grafana/grafana/pkg/api/dtos"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/live/managedstream"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeQueryDataService struct {
+	requests []dtos.MetricRequest
+	frames   data.Frames
+}
+
+func (s *fakeQueryDataService) QueryData(_ context.Context, _ *models.SignedInUser, _ bool, reqDTO dtos.MetricRequest, _ bool) (*backend.QueryDataResponse, error) {
+	s.requests = append(s.requests, reqDTO)
+	return &backend.QueryDataResponse{Responses: backend.Responses{
+		"A": {Frames: s.frames},
+	}}, nil
+}
+
+type fakeChannelRuleGetter struct {
+	rules map[string]*LiveChannelRule
+}
+
+func (g *fakeChannelRuleGetter) Get(_ int64, channel string) (*LiveChannelRule, bool, error) {
+	rule, ok := g.rules[channel]
+	return rule, ok, nil
+}
+
+func TestBackfiller_Backfill(t *testing.T) {
+	frame := data.NewFrame("A",
+		data.NewField("Time", nil, []time.Time{time.Unix(1, 0)}),
+		data.NewField("Value", nil, []float64{1}),
+	)
+	queryService := &fakeQueryDataService{frames: data.Frames{frame}}
+	backfiller := &Backfiller{
+		ChannelRuleGetter: &fakeChannelRuleGetter{rules: map[string]*LiveChannelRule{
+			"stream/telegraf/cpu": {
+				Backfill: &BackfillConfig{
+					DatasourceUID: "prometheus",
+					Query: map[string]interface{}{
+						"expr":    `${namespace}_usage{channel="${channel}"}`,
+						"options": map[string]interface{}{"legend": []interface{}{"${path}", 1.}},
+					},
+					MaxDataPoints: 100,
+				},
+			},
+			"stream/telegraf/mem": {},
+		}},
+		QueryDataService: queryService,
+	}
+
+	user := &models.SignedInUser{OrgId: 1}
+	req := managedstream.BackfillRequest{From: time.Unix(0, 0), To: time.Unix(1000, 0)}
+	frames, ok, err := backfiller.Backfill(context.Background(), user, "stream/telegraf/cpu", req)
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.Equal(t, []*data.Frame{frame}, frames)
+
+	require.Len(t, queryService.requests, 1)
+	metricRequest := queryService.requests[0]
+	require.Equal(t, "0", metricRequest.From)
+	require.Equal(t, "1000000", metricRequest.To)
+	require.Len(t, metricRequest.Queries, 1)
+	query := metricRequest.Queries[0]
+	require.Equal(t, `telegraf_usage{channel="stream/telegraf/cpu"}`, query.Get("expr").MustString())
+	require.Equal(t, "cpu", query.GetPath("options", "legend").GetIndex(0).MustString())
+	require.Equal(t, "prometheus", query.GetPath("datasource", "uid").MustString())
+	require.Equal(t, "A", query.Get("refId").MustString())
+	require.Equal(t, int64(100), query.Get("maxDataPoints").MustInt64())
+	require.Equal(t, int64(10000), query.Get("intervalMs").MustInt64())
+
+	// Channels without backfill settings or rules have no history.
+	for _, channel := range []string{"stream/telegraf/mem", "stream/telegraf/disk"} {
+		_, ok, err = backfiller.Backfill(context.Background(), user, channel, req)
+		require.NoError(t, err)
+		require.False(t, ok)
+	}
+	require.Len(t, queryService.requests, 1)
+}
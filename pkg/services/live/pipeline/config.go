@@ -1,6 +1,8 @@
 package pipeline
 
 import (
+	"time"
+
 	"github.com/grafana/grafana-plugin-sdk-go/data"
 	"github.com/grafana/grafana/pkg/models"
 )
@@ -25,20 +27,31 @@ type ChannelRuleSettings struct {
 	Converter       *ConverterConfig        `json:"converter,omitempty"`
 	FrameProcessors []*FrameProcessorConfig `json:"frameProcessors,omitempty"`
 	FrameOutputters []*FrameOutputterConfig `json:"frameOutputs,omitempty"`
+	Backfill        *BackfillConfig         `json:"backfill,omitempty"`
 }
 
 type ChannelRule struct {
 	OrgId    int64               `json:"-"`
 	Pattern  string              `json:"pattern"`
 	Settings ChannelRuleSettings `json:"settings"`
+
+	// Version and change metadata are only kept by database storage.
+	Version   int64      `json:"version,omitempty"`
+	Created   *time.Time `json:"created,omitempty"`
+	Updated   *time.Time `json:"updated,omitempty"`
+	CreatedBy int64      `json:"createdBy,omitempty"`
+	UpdatedBy int64      `json:"updatedBy,omitempty"`
 }
 
 type ConverterConfig struct {
-	Type                      string                     `json:"type" ts_type:"Omit<keyof ConverterConfig, 'type'>"`
-	AutoJsonConverterConfig   *AutoJsonConverterConfig   `json:"jsonAuto,omitempty"`
-	ExactJsonConverterConfig  *ExactJsonConverterConfig  `json:"jsonExact,omitempty"`
-	AutoInfluxConverterConfig *AutoInfluxConverterConfig `json:"influxAuto,omitempty"`
-	JsonFrameConverterConfig  *JsonFrameConverterConfig  `json:"jsonFrame,omitempty"`
+	Type                                 string                                `json:"type" ts_type:"Omit<keyof ConverterConfig, 'type'>"`
+	AutoJsonConverterConfig              *AutoJsonConverterConfig              `json:"jsonAuto,omitempty"`
+	ExactJsonConverterConfig             *ExactJsonConverterConfig             `json:"jsonExact,omitempty"`
+	AutoInfluxConverterConfig            *AutoInfluxConverterConfig            `json:"influxAuto,omitempty"`
+	JsonFrameConverterConfig             *JsonFrameConverterConfig             `json:"jsonFrame,omitempty"`
+	PrometheusTextConverterConfig        *PrometheusTextConverterConfig        `json:"prometheusText,omitempty"`
+	PrometheusRemoteWriteConverterConfig *PrometheusRemoteWriteConverterConfig `json:"prometheusRemoteWrite,omitempty"`
+	ProtobufConverterConfig              *ProtobufConverterConfig              `json:"protobuf,omitempty"`
 }
 
 type DropFieldsFrameProcessorConfig struct {
@@ -49,11 +62,50 @@ type KeepFieldsFrameProcessorConfig struct {
 	FieldNames []string `json:"fieldNames"`
 }
 
+type AggregateReducer string
+
+const (
+	AggregateReducerMean  AggregateReducer = "mean"
+	AggregateReducerMin   AggregateReducer = "min"
+	AggregateReducerMax   AggregateReducer = "max"
+	AggregateReducerSum   AggregateReducer = "sum"
+	AggregateReducerCount AggregateReducer = "count"
+	AggregateReducerLast  AggregateReducer = "last"
+)
+
+type AggregateField struct {
+	FieldName string             `json:"fieldName"`
+	Reducers  []AggregateReducer `json:"reducers"`
+}
+
+type AggregateFrameProcessorConfig struct {
+	// IntervalMilliseconds is the duration of aggregation windows.
+	IntervalMilliseconds int64            `json:"intervalMilliseconds"`
+	Fields               []AggregateField `json:"fields"`
+}
+
+type DerivativeFrameProcessorConfig struct {
+	FieldNames []string `json:"fieldNames"`
+	// Counter treats decreasing values as counter resets.
+	Counter bool `json:"counter,omitempty"`
+}
+
+type CalculatedFieldFrameProcessorConfig struct {
+	FieldName string         `json:"fieldName"`
+	Type      data.FieldType `json:"type"`
+	// Expression is a JavaScript expression, where x holds the row values.
+	Expression string            `json:"expression"`
+	Config     *data.FieldConfig `json:"config,omitempty" ts_type:"FieldConfig"`
+}
+
 type FrameProcessorConfig struct {
-	Type                      string                          `json:"type" ts_type:"Omit<keyof FrameProcessorConfig, 'type'>"`
-	DropFieldsProcessorConfig *DropFieldsFrameProcessorConfig `json:"dropFields,omitempty"`
-	KeepFieldsProcessorConfig *KeepFieldsFrameProcessorConfig `json:"keepFields,omitempty"`
-	MultipleProcessorConfig   *MultipleFrameProcessorConfig   `json:"multiple,omitempty"`
+	Type                           string                               `json:"type" ts_type:"Omit<keyof FrameProcessorConfig, 'type'>"`
+	DropFieldsProcessorConfig      *DropFieldsFrameProcessorConfig      `json:"dropFields,omitempty"`
+	KeepFieldsProcessorConfig      *KeepFieldsFrameProcessorConfig      `json:"keepFields,omitempty"`
+	MultipleProcessorConfig        *MultipleFrameProcessorConfig        `json:"multiple,omitempty"`
+	AggregateProcessorConfig       *AggregateFrameProcessorConfig       `json:"aggregate,omitempty"`
+	DerivativeProcessorConfig      *DerivativeFrameProcessorConfig      `json:"derivative,omitempty"`
+	CalculatedFieldProcessorConfig *CalculatedFieldFrameProcessorConfig `json:"calculatedField,omitempty"`
 }
 
 type MultipleFrameProcessorConfig struct {
@@ -96,6 +148,8 @@ type DataOutputterConfig struct {
 	Type                     string                    `json:"type" ts_type:"Omit<keyof DataOutputterConfig, 'type'>"`
 	RedirectDataOutputConfig *RedirectDataOutputConfig `json:"redirect,omitempty"`
 	LokiOutputConfig         *LokiOutputConfig         `json:"loki,omitempty"`
+	KafkaOutputConfig        *KafkaOutputConfig        `json:"kafka,omitempty"`
+	WebhookOutputConfig      *WebhookOutputConfig      `json:"webhook,omitempty"`
 }
 
 type FrameOutputterConfig struct {
@@ -108,6 +162,9 @@ type FrameOutputterConfig struct {
 	RemoteWriteOutputConfig *RemoteWriteOutputConfig   `json:"remoteWrite,omitempty"`
 	LokiOutputConfig        *LokiOutputConfig          `json:"loki,omitempty"`
 	ChangeLogOutputConfig   *ChangeLogOutputConfig     `json:"changeLog,omitempty"`
+	AlertOutputConfig       *AlertOutputConfig         `json:"alert,omitempty"`
+	KafkaOutputConfig       *KafkaOutputConfig         `json:"kafka,omitempty"`
+	WebhookOutputConfig     *WebhookOutputConfig       `json:"webhook,omitempty"`
 }
 
 type MultipleFrameConditionCheckerConfig struct {
@@ -151,4 +208,25 @@ type AutoInfluxConverterConfig struct {
 
 type JsonFrameConverterConfig struct{}
 
+type PrometheusTextConverterConfig struct {
+	// OpenMetrics enables OpenMetrics format instead of Prometheus text format.
+	OpenMetrics bool `json:"openMetrics,omitempty"`
+	// FrameFormat is wide or labels_column (default).
+	FrameFormat string `json:"frameFormat,omitempty"`
+}
+
+type PrometheusRemoteWriteConverterConfig struct {
+	// FrameFormat is wide or labels_column (default).
+	FrameFormat string `json:"frameFormat,omitempty"`
+}
+
+type ProtobufConverterConfig struct {
+	// DescriptorSet is a base64 encoded FileDescriptorSet including message type
+	// dependencies, as written by protoc --include_imports --descriptor_set_out.
+	DescriptorSet string `json:"descriptorSet"`
+	// MessageType is a fully qualified message type name.
+	MessageType string           `json:"messageType"`
+	FieldTips   map[string]Field `json:"fieldTips,omitempty"`
+}
+
 type ManagedStreamOutputConfig struct{}
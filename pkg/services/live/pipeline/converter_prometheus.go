@@ -0,0 +1,73 @@
+package pipeline
+
+import (
+	"context"
+
+	"github.com/grafana/grafana/pkg/services/live/telemetry"
+	"github.com/grafana/grafana/pkg/services/live/telemetry/prometheus"
+)
+
+// PrometheusTextConverter decodes metrics in Prometheus text exposition or
+// OpenMetrics format and transforms them to several ChannelFrame objects where
+// Channel is constructed from original channel + / + <metric_name>.
+type PrometheusTextConverter struct {
+	config    PrometheusTextConverterConfig
+	converter telemetry.Converter
+}
+
+func NewPrometheusTextConverter(config PrometheusTextConverterConfig) *PrometheusTextConverter {
+	return &PrometheusTextConverter{
+		config:    config,
+		converter: prometheus.NewTextConverter(config.OpenMetrics, config.FrameFormat),
+	}
+}
+
+const ConverterTypePrometheusText = "prometheusText"
+
+func (c *PrometheusTextConverter) Type() string {
+	return ConverterTypePrometheusText
+}
+
+func (c *PrometheusTextConverter) Convert(_ context.Context, vars Vars, body []byte) ([]*ChannelFrame, error) {
+	return convertMetricFrames(c.converter, vars, body)
+}
+
+// PrometheusRemoteWriteConverter decodes Prometheus remote write requests and
+// transforms them to several ChannelFrame objects where Channel is constructed
+// from original channel + / + <metric_name>.
+type PrometheusRemoteWriteConverter struct {
+	config    PrometheusRemoteWriteConverterConfig
+	converter telemetry.Converter
+}
+
+func NewPrometheusRemoteWriteConverter(config PrometheusRemoteWriteConverterConfig) *PrometheusRemoteWriteConverter {
+	return &PrometheusRemoteWriteConverter{
+		config:    config,
+		converter: prometheus.NewRemoteWriteConverter(config.FrameFormat),
+	}
+}
+
+const ConverterTypePrometheusRemoteWrite = "prometheusRemoteWrite"
+
+func (c *PrometheusRemoteWriteConverter) Type() string {
+	return ConverterTypePrometheusRemoteWrite
+}
+
+func (c *PrometheusRemoteWriteConverter) Convert(_ context.Context, vars Vars, body []byte) ([]*ChannelFrame, error) {
+	return convertMetricFrames(c.converter, vars, body)
+}
+
+func convertMetricFrames(converter telemetry.Converter, vars Vars, body []byte) ([]*ChannelFrame, error) {
+	frameWrappers, err := converter.Convert(body)
+	if err != nil {
+		return nil, err
+	}
+	channelFrames := make([]*ChannelFrame, 0, len(frameWrappers))
+	for _, fw := range frameWrappers {
+		channelFrames = append(channelFrames, &ChannelFrame{
+			Channel: vars.Channel + "/" + fw.Key(),
+			Frame:   fw.Frame(),
+		})
+	}
+	return channelFrames, nil
+}
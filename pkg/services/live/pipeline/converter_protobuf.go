@@ -0,0 +1,123 @@
+package pipeline
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+// ProtobufConverter decodes protobuf messages of a type described by a
+// FileDescriptorSet and converts them to a single data.Frame the same way
+// AutoJsonConverter converts JSON documents.
+type ProtobufConverter struct {
+	config      ProtobufConverterConfig
+	descriptor  protoreflect.MessageDescriptor
+	nowTimeFunc func() time.Time
+}
+
+func NewProtobufConverter(c ProtobufConverterConfig) (*ProtobufConverter, error) {
+	descriptorSet, err := base64.StdEncoding.DecodeString(c.DescriptorSet)
+	if err != nil {
+		return nil, fmt.Errorf("error decoding descriptor set: %w", err)
+	}
+	var fds descriptorpb.FileDescriptorSet
+	if err := proto.Unmarshal(descriptorSet, &fds); err != nil {
+		return nil, fmt.Errorf("error decoding descriptor set: %w", err)
+	}
+	files, err := protodesc.NewFiles(&fds)
+	if err != nil {
+		return nil, fmt.Errorf("invalid descriptor set: %w", err)
+	}
+	d, err := files.FindDescriptorByName(protoreflect.FullName(c.MessageType))
+	if err != nil {
+		return nil, fmt.Errorf("message type %s not found: %w", c.MessageType, err)
+	}
+	md, ok := d.(protoreflect.MessageDescriptor)
+	if !ok {
+		return nil, fmt.Errorf("%s is not a message type", c.MessageType)
+	}
+	return &ProtobufConverter{config: c, descriptor: md}, nil
+}
+
+const ConverterTypeProtobuf = "protobuf"
+
+func (c *ProtobufConverter) Type() string {
+	return ConverterTypeProtobuf
+}
+
+func (c *ProtobufConverter) Convert(_ context.Context, vars Vars, body []byte) ([]*ChannelFrame, error) {
+	msg := dynamicpb.NewMessage(c.descriptor)
+	if err := proto.Unmarshal(body, msg); err != nil {
+		return nil, fmt.Errorf("error decoding %s message: %w", c.config.MessageType, err)
+	}
+	// Not using protojson here since it encodes 64-bit integers as strings.
+	doc, err := json.Marshal(protoMessageToMap(msg))
+	if err != nil {
+		return nil, err
+	}
+	nowTimeFunc := c.nowTimeFunc
+	if nowTimeFunc == nil {
+		nowTimeFunc = time.Now
+	}
+	frame, err := jsonDocToFrame(vars.Path, doc, c.config.FieldTips, nowTimeFunc)
+	if err != nil {
+		return nil, err
+	}
+	return []*ChannelFrame{
+		{Channel: "", Frame: frame},
+	}, nil
+}
+
+// protoMessageToMap converts a message to a map by field name. Unset scalar
+// fields are included with default values, so that frame schema stays the same.
+func protoMessageToMap(msg protoreflect.Message) map[string]interface{} {
+	fields := msg.Descriptor().Fields()
+	result := make(map[string]interface{}, fields.Len())
+	for i := 0; i < fields.Len(); i++ {
+		fd := fields.Get(i)
+		name := string(fd.Name())
+		switch {
+		case fd.IsList():
+			list := msg.Get(fd).List()
+			values := make([]interface{}, list.Len())
+			for j := 0; j < list.Len(); j++ {
+				values[j] = protoValue(fd, list.Get(j))
+			}
+			result[name] = values
+		case fd.IsMap():
+			values := map[string]interface{}{}
+			msg.Get(fd).Map().Range(func(k protoreflect.MapKey, v protoreflect.Value) bool {
+				values[k.String()] = protoValue(fd.MapValue(), v)
+				return true
+			})
+			result[name] = values
+		case fd.HasPresence() && !msg.Has(fd):
+			result[name] = nil
+		default:
+			result[name] = protoValue(fd, msg.Get(fd))
+		}
+	}
+	return result
+}
+
+func protoValue(fd protoreflect.FieldDescriptor, v protoreflect.Value) interface{} {
+	switch fd.Kind() {
+	case protoreflect.MessageKind, protoreflect.GroupKind:
+		return protoMessageToMap(v.Message())
+	case protoreflect.EnumKind:
+		if ev := fd.Enum().Values().ByNumber(v.Enum()); ev != nil {
+			return string(ev.Name())
+		}
+		return int32(v.Enum())
+	default:
+		return v.Interface()
+	}
+}
@@ -0,0 +1,130 @@
+package pipeline
+
+import (
+	"context"
+	"encoding/base64"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+// readingDescriptor describes:
+//
+//	message Reading {
+//	  string sensor = 1;
+//	  double value = 2;
+//	  int64 count = 3;
+//	  Status status = 4;
+//	  Location location = 5;
+//	}
+//	message Location { double lat = 1; double lon = 2; }
+//	enum Status { UNKNOWN = 0; OK = 1; }
+func readingDescriptor() *descriptorpb.FileDescriptorProto {
+	field := func(name string, number int32, t descriptorpb.FieldDescriptorProto_Type, typeName string) *descriptorpb.FieldDescriptorProto {
+		f := &descriptorpb.FieldDescriptorProto{
+			Name:   proto.String(name),
+			Number: proto.Int32(number),
+			Label:  descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum(),
+			Type:   t.Enum(),
+		}
+		if typeName != "" {
+			f.TypeName = proto.String(typeName)
+		}
+		return f
+	}
+	return &descriptorpb.FileDescriptorProto{
+		Name:    proto.String("sensors.proto"),
+		Package: proto.String("sensors"),
+		Syntax:  proto.String("proto3"),
+		MessageType: []*descriptorpb.DescriptorProto{
+			{
+				Name: proto.String("Reading"),
+				Field: []*descriptorpb.FieldDescriptorProto{
+					field("sensor", 1, descriptorpb.FieldDescriptorProto_TYPE_STRING, ""),
+					field("value", 2, descriptorpb.FieldDescriptorProto_TYPE_DOUBLE, ""),
+					field("count", 3, descriptorpb.FieldDescriptorProto_TYPE_INT64, ""),
+					field("status", 4, descriptorpb.FieldDescriptorProto_TYPE_ENUM, ".sensors.Status"),
+					field("location", 5, descriptorpb.FieldDescriptorProto_TYPE_MESSAGE, ".sensors.Location"),
+				},
+			},
+			{
+				Name: proto.String("Location"),
+				Field: []*descriptorpb.FieldDescriptorProto{
+					field("lat", 1, descriptorpb.FieldDescriptorProto_TYPE_DOUBLE, ""),
+					field("lon", 2, descriptorpb.FieldDescriptorProto_TYPE_DOUBLE, ""),
+				},
+			},
+		},
+		EnumType: []*descriptorpb.EnumDescriptorProto{
+			{
+				Name: proto.String("Status"),
+				Value: []*descriptorpb.EnumValueDescriptorProto{
+					{Name: proto.String("UNKNOWN"), Number: proto.Int32(0)},
+					{Name: proto.String("OK"), Number: proto.Int32(1)},
+				},
+			},
+		},
+	}
+}
+
+func TestProtobufConverter(t *testing.T) {
+	fdp := readingDescriptor()
+	descriptorSet, err := proto.Marshal(&descriptorpb.FileDescriptorSet{File: []*descriptorpb.FileDescriptorProto{fdp}})
+	require.NoError(t, err)
+
+	c, err := NewProtobufConverter(ProtobufConverterConfig{
+		DescriptorSet: base64.StdEncoding.EncodeToString(descriptorSet),
+		MessageType:   "sensors.Reading",
+	})
+	require.NoError(t, err)
+	now := time.Now()
+	c.nowTimeFunc = func() time.Time { return now }
+
+	fd, err := protodesc.NewFile(fdp, nil)
+	require.NoError(t, err)
+	msg := dynamicpb.NewMessage(fd.Messages().ByName("Reading"))
+	msg.Set(msg.Descriptor().Fields().ByName("sensor"), protoreflect.ValueOfString("s1"))
+	msg.Set(msg.Descriptor().Fields().ByName("count"), protoreflect.ValueOfInt64(42))
+	msg.Set(msg.Descriptor().Fields().ByName("status"), protoreflect.ValueOfEnum(1))
+	body, err := proto.Marshal(msg)
+	require.NoError(t, err)
+
+	channelFrames, err := c.Convert(context.Background(), Vars{Path: "reading"}, body)
+	require.NoError(t, err)
+	require.Len(t, channelFrames, 1)
+
+	frame := channelFrames[0].Frame
+	require.Equal(t, "reading", frame.Name)
+	values := map[string]interface{}{}
+	for _, f := range frame.Fields {
+		values[f.Name] = f.At(0)
+	}
+	require.Equal(t, now, values["Time"])
+	require.Equal(t, "s1", *values["sensor"].(*string))
+	require.Equal(t, 0.0, *values["value"].(*float64))
+	require.Equal(t, 42.0, *values["count"].(*float64))
+	require.Equal(t, "OK", *values["status"].(*string))
+	require.NotContains(t, values, "location", "unset messages are skipped like JSON nulls")
+
+	_, err = c.Convert(context.Background(), Vars{}, []byte("not a message"))
+	require.Error(t, err)
+}
+
+func TestNewProtobufConverter_Invalid(t *testing.T) {
+	_, err := NewProtobufConverter(ProtobufConverterConfig{DescriptorSet: "%", MessageType: "sensors.Reading"})
+	require.Error(t, err)
+
+	descriptorSet, err := proto.Marshal(&descriptorpb.FileDescriptorSet{File: []*descriptorpb.FileDescriptorProto{readingDescriptor()}})
+	require.NoError(t, err)
+	_, err = NewProtobufConverter(ProtobufConverterConfig{
+		DescriptorSet: base64.StdEncoding.EncodeToString(descriptorSet),
+		MessageType:   "sensors.Unknown",
+	})
+	require.Error(t, err)
+}
@@ -0,0 +1,29 @@
+package pipeline
+
+import (
+	"context"
+	"fmt"
+)
+
+// KafkaDataOutput writes raw data into Kafka topics.
+type KafkaDataOutput struct {
+	producer KafkaProducer
+	config   KafkaOutputConfig
+}
+
+func NewKafkaDataOutput(producer KafkaProducer, config KafkaOutputConfig) *KafkaDataOutput {
+	return &KafkaDataOutput{producer: producer, config: config}
+}
+
+const DataOutputTypeKafka = "kafka"
+
+func (out *KafkaDataOutput) Type() string {
+	return DataOutputTypeKafka
+}
+
+func (out *KafkaDataOutput) OutputData(ctx context.Context, vars Vars, data []byte) ([]*ChannelData, error) {
+	if err := out.producer.WriteMessages(ctx, kafkaMessage(out.config, vars, data)); err != nil {
+		return nil, fmt.Errorf("error writing to Kafka: %w", err)
+	}
+	return nil, nil
+}
@@ -0,0 +1,39 @@
+package pipeline
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+)
+
+// WebhookDataOutput sends raw data to a webhook in batches.
+type WebhookDataOutput struct {
+	writer *webhookWriter
+}
+
+func NewWebhookDataOutput(writer *webhookWriter) *WebhookDataOutput {
+	return &WebhookDataOutput{writer: writer}
+}
+
+const DataOutputTypeWebhook = "webhook"
+
+func (out *WebhookDataOutput) Type() string {
+	return DataOutputTypeWebhook
+}
+
+func (out *WebhookDataOutput) OutputData(_ context.Context, vars Vars, data []byte) ([]*ChannelData, error) {
+	msgData := json.RawMessage(data)
+	if !json.Valid(data) {
+		var err error
+		msgData, err = json.Marshal(string(data))
+		if err != nil {
+			return nil, err
+		}
+	}
+	out.writer.write(WebhookMessage{
+		Channel: vars.Channel,
+		Time:    time.Now().UnixNano() / int64(time.Millisecond),
+		Data:    msgData,
+	})
+	return nil, nil
+}
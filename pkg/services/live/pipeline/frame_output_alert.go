@@ -0,0 +1,239 @@
+package pipeline
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/go-openapi/strfmt"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/grafana/grafana/pkg/services/live/orgchannel"
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	"github.com/prometheus/alertmanager/api/v2/models"
+	"github.com/prometheus/common/model"
+)
+
+type AlertOutputConfig struct {
+	// FieldName is a field with threshold config to alert on.
+	FieldName   string            `json:"fieldName,omitempty"`
+	AlertName   string            `json:"alertName,omitempty"`
+	Labels      map[string]string `json:"labels,omitempty"`
+	Annotations map[string]string `json:"annotations,omitempty"`
+	// ResolveTimeoutSeconds is the time after which firing alerts are resolved
+	// unless sent again, 300 by default.
+	ResolveTimeoutSeconds int64 `json:"resolveTimeoutSeconds,omitempty"`
+}
+
+// AlertSender posts alerts into the Alertmanager of an organization.
+type AlertSender interface {
+	PutAlerts(orgID int64, alerts apimodels.PostableAlerts) error
+}
+
+const (
+	defaultAlertName      = "LiveChannelAlert"
+	defaultResolveTimeout = 5 * time.Minute
+)
+
+// AlertOutput posts alerts into the organization Alertmanager.
+//
+// With FieldName set an alert fires when the field value crosses a threshold
+// step above the base one, with the threshold state as the state label. The
+// alert is resolved when the value returns to the base step.
+//
+// Without FieldName every frame fires an alert, so the output is supposed to
+// be used in a conditional output. The alert is resolved once frames stop
+// arriving for the resolve timeout.
+//
+// Firing alerts are re-sent at least every half of the resolve timeout.
+type AlertOutput struct {
+	sender AlertSender
+	config AlertOutputConfig
+
+	mu     sync.Mutex
+	active map[string]*activeAlert
+}
+
+type activeAlert struct {
+	alert    *models.PostableAlert
+	state    string
+	lastSent time.Time
+}
+
+func NewAlertOutput(sender AlertSender, config AlertOutputConfig) *AlertOutput {
+	return &AlertOutput{sender: sender, config: config, active: map[string]*activeAlert{}}
+}
+
+const FrameOutputTypeAlert = "alert"
+
+func (out *AlertOutput) Type() string {
+	return FrameOutputTypeAlert
+}
+
+func (out *AlertOutput) OutputFrame(_ context.Context, vars Vars, frame *data.Frame) ([]*ChannelFrame, error) {
+	if frame == nil || out.sender == nil {
+		return nil, nil
+	}
+	now := time.Now()
+
+	var alerts []*models.PostableAlert
+	var err error
+	out.mu.Lock()
+	if out.config.FieldName != "" {
+		alerts, err = out.thresholdAlerts(vars, frame, now)
+	} else {
+		alerts = out.frameAlerts(vars, frame, now)
+	}
+	out.mu.Unlock()
+	if err != nil || len(alerts) == 0 {
+		return nil, err
+	}
+
+	postableAlerts := apimodels.PostableAlerts{PostableAlerts: make([]models.PostableAlert, 0, len(alerts))}
+	for _, a := range alerts {
+		postableAlerts.PostableAlerts = append(postableAlerts.PostableAlerts, *a)
+	}
+	if err := out.sender.PutAlerts(vars.OrgID, postableAlerts); err != nil {
+		return nil, fmt.Errorf("error sending alerts: %w", err)
+	}
+	return nil, nil
+}
+
+func (out *AlertOutput) frameAlerts(vars Vars, frame *data.Frame, now time.Time) []*models.PostableAlert {
+	labels := data.Labels{}
+	for _, f := range frame.Fields {
+		for k, v := range f.Labels {
+			labels[k] = v
+		}
+	}
+	key := orgchannel.PrependOrgID(vars.OrgID, vars.Channel) + labels.String()
+	active, ok := out.active[key]
+	if ok && now.Sub(active.lastSent) < out.resolveTimeout()/2 {
+		return nil
+	}
+	alert := out.newAlert(vars, labels, nil, "", now)
+	if ok {
+		alert.StartsAt = active.alert.StartsAt
+	}
+	out.active[key] = &activeAlert{alert: alert, lastSent: now}
+	out.cleanup(now)
+	return []*models.PostableAlert{alert}
+}
+
+func (out *AlertOutput) thresholdAlerts(vars Vars, frame *data.Frame, now time.Time) ([]*models.PostableAlert, error) {
+	field, _ := frame.FieldByName(out.config.FieldName)
+	if field == nil || field.Config == nil || field.Config.Thresholds == nil || len(field.Config.Thresholds.Steps) == 0 {
+		return nil, nil
+	}
+	if mode := field.Config.Thresholds.Mode; mode != data.ThresholdsModeAbsolute {
+		return nil, fmt.Errorf("unsupported threshold mode: %s", mode)
+	}
+	steps := field.Config.Thresholds.Steps
+
+	key := orgchannel.PrependOrgID(vars.OrgID, vars.Channel) + field.Labels.String()
+	active := out.active[key]
+
+	var alerts []*models.PostableAlert
+	for i := 0; i < field.Len(); i++ {
+		value, err := field.NullableFloatAt(i)
+		if err != nil {
+			return nil, err
+		}
+		if value == nil {
+			continue
+		}
+		stepIndex := 0
+		for j, step := range steps {
+			if *value >= float64(step.Value) {
+				stepIndex = j
+				continue
+			}
+			break
+		}
+		state := steps[stepIndex].State
+
+		if active != nil && active.state == state {
+			continue
+		}
+		if active != nil {
+			// State changed, resolve the alert of the previous state.
+			resolved := *active.alert
+			resolved.EndsAt = strfmt.DateTime(now)
+			alerts = append(alerts, &resolved)
+			active = nil
+		}
+		if stepIndex > 0 {
+			active = &activeAlert{
+				alert: out.newAlert(vars, field.Labels, value, state, now),
+				state: state,
+			}
+			alerts = append(alerts, active.alert)
+		}
+	}
+
+	if active != nil && len(alerts) == 0 && now.Sub(active.lastSent) >= out.resolveTimeout()/2 {
+		active.alert.EndsAt = strfmt.DateTime(now.Add(out.resolveTimeout()))
+		alerts = append(alerts, active.alert)
+	}
+	if active == nil {
+		delete(out.active, key)
+	} else {
+		if len(alerts) > 0 {
+			active.lastSent = now
+		}
+		out.active[key] = active
+	}
+	return alerts, nil
+}
+
+func (out *AlertOutput) newAlert(vars Vars, fieldLabels data.Labels, value *float64, state string, now time.Time) *models.PostableAlert {
+	labels := models.LabelSet{}
+	for k, v := range fieldLabels {
+		labels[k] = v
+	}
+	for k, v := range out.config.Labels {
+		labels[k] = v
+	}
+	labels[model.AlertNameLabel] = out.config.AlertName
+	if labels[model.AlertNameLabel] == "" {
+		labels[model.AlertNameLabel] = defaultAlertName
+	}
+	labels["channel"] = vars.Channel
+	if state != "" {
+		labels["state"] = state
+	}
+
+	annotations := models.LabelSet{}
+	for k, v := range out.config.Annotations {
+		annotations[k] = v
+	}
+	if value != nil {
+		annotations["value"] = strconv.FormatFloat(*value, 'f', -1, 64)
+	}
+
+	return &models.PostableAlert{
+		Annotations: annotations,
+		StartsAt:    strfmt.DateTime(now),
+		EndsAt:      strfmt.DateTime(now.Add(out.resolveTimeout())),
+		Alert: models.Alert{
+			Labels: labels,
+		},
+	}
+}
+
+// cleanup removes alerts resolved by timeout.
+func (out *AlertOutput) cleanup(now time.Time) {
+	for key, a := range out.active {
+		if now.After(time.Time(a.alert.EndsAt)) {
+			delete(out.active, key)
+		}
+	}
+}
+
+func (out *AlertOutput) resolveTimeout() time.Duration {
+	if out.config.ResolveTimeoutSeconds <= 0 {
+		return defaultResolveTimeout
+	}
+	return time.Duration(out.config.ResolveTimeoutSeconds) * time.Second
+}
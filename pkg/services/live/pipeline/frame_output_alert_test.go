@@ -0,0 +1,122 @@
+package pipeline
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeAlertSender struct {
+	orgIDs []int64
+	alerts []apimodels.PostableAlerts
+}
+
+func (s *fakeAlertSender) PutAlerts(orgID int64, alerts apimodels.PostableAlerts) error {
+	s.orgIDs = append(s.orgIDs, orgID)
+	s.alerts = append(s.alerts, alerts)
+	return nil
+}
+
+func thresholdFrame(values ...float64) *data.Frame {
+	f := data.NewField("value", data.Labels{"host": "a"}, values)
+	f.Config = &data.FieldConfig{
+		Thresholds: &data.ThresholdsConfig{
+			Mode: data.ThresholdsModeAbsolute,
+			Steps: []data.Threshold{
+				{Value: data.ConfFloat64(0), State: "normal"},
+				{Value: data.ConfFloat64(50), State: "warning"},
+				{Value: data.ConfFloat64(90), State: "critical"},
+			},
+		},
+	}
+	return data.NewFrame("test", f)
+}
+
+func TestAlertOutput_Threshold(t *testing.T) {
+	sender := &fakeAlertSender{}
+	out := NewAlertOutput(sender, AlertOutputConfig{
+		FieldName:   "value",
+		AlertName:   "HighValue",
+		Labels:      map[string]string{"team": "ops"},
+		Annotations: map[string]string{"summary": "value is high"},
+	})
+	vars := Vars{OrgID: 2, Channel: "stream/test/alert"}
+
+	_, err := out.OutputFrame(context.Background(), vars, thresholdFrame(10, 20))
+	require.NoError(t, err)
+	require.Empty(t, sender.alerts, "no alerts must be sent for the base threshold")
+
+	_, err = out.OutputFrame(context.Background(), vars, thresholdFrame(60))
+	require.NoError(t, err)
+	require.Len(t, sender.alerts, 1)
+	require.Equal(t, int64(2), sender.orgIDs[0])
+	fired := sender.alerts[0].PostableAlerts
+	require.Len(t, fired, 1)
+	require.Equal(t, "HighValue", fired[0].Labels["alertname"])
+	require.Equal(t, "stream/test/alert", fired[0].Labels["channel"])
+	require.Equal(t, "warning", fired[0].Labels["state"])
+	require.Equal(t, "a", fired[0].Labels["host"])
+	require.Equal(t, "ops", fired[0].Labels["team"])
+	require.Equal(t, "60", fired[0].Annotations["value"])
+	require.Equal(t, "value is high", fired[0].Annotations["summary"])
+	require.True(t, time.Time(fired[0].EndsAt).After(time.Now()))
+
+	// Same state, nothing to send.
+	_, err = out.OutputFrame(context.Background(), vars, thresholdFrame(70))
+	require.NoError(t, err)
+	require.Len(t, sender.alerts, 1)
+
+	// Transition to another state resolves the previous alert.
+	_, err = out.OutputFrame(context.Background(), vars, thresholdFrame(95))
+	require.NoError(t, err)
+	require.Len(t, sender.alerts, 2)
+	alerts := sender.alerts[1].PostableAlerts
+	require.Len(t, alerts, 2)
+	require.Equal(t, "warning", alerts[0].Labels["state"])
+	require.False(t, time.Time(alerts[0].EndsAt).After(time.Now()))
+	require.Equal(t, "critical", alerts[1].Labels["state"])
+
+	// Back to the base threshold.
+	_, err = out.OutputFrame(context.Background(), vars, thresholdFrame(5))
+	require.NoError(t, err)
+	require.Len(t, sender.alerts, 3)
+	alerts = sender.alerts[2].PostableAlerts
+	require.Len(t, alerts, 1)
+	require.Equal(t, "critical", alerts[0].Labels["state"])
+	require.False(t, time.Time(alerts[0].EndsAt).After(time.Now()))
+}
+
+func TestAlertOutput_Frame(t *testing.T) {
+	sender := &fakeAlertSender{}
+	out := NewAlertOutput(sender, AlertOutputConfig{ResolveTimeoutSeconds: 60})
+	vars := Vars{OrgID: 1, Channel: "stream/test/alert"}
+
+	_, err := out.OutputFrame(context.Background(), vars, data.NewFrame("test", data.NewField("value", data.Labels{"host": "a"}, []float64{1})))
+	require.NoError(t, err)
+	require.Len(t, sender.alerts, 1)
+	alert := sender.alerts[0].PostableAlerts[0]
+	require.Equal(t, defaultAlertName, alert.Labels["alertname"])
+	require.Equal(t, "a", alert.Labels["host"])
+	require.WithinDuration(t, time.Now().Add(time.Minute), time.Time(alert.EndsAt), time.Second)
+
+	// Firing alert is not re-sent until half of the resolve timeout passes.
+	_, err = out.OutputFrame(context.Background(), vars, data.NewFrame("test", data.NewField("value", data.Labels{"host": "a"}, []float64{2})))
+	require.NoError(t, err)
+	require.Len(t, sender.alerts, 1)
+
+	// Other labels fire another alert.
+	_, err = out.OutputFrame(context.Background(), vars, data.NewFrame("test", data.NewField("value", data.Labels{"host": "b"}, []float64{2})))
+	require.NoError(t, err)
+	require.Len(t, sender.alerts, 2)
+}
+
+func TestAlertOutput_NoSender(t *testing.T) {
+	out := NewAlertOutput(nil, AlertOutputConfig{})
+	frames, err := out.OutputFrame(context.Background(), Vars{}, data.NewFrame("test", data.NewField("value", nil, []float64{1})))
+	require.NoError(t, err)
+	require.Nil(t, frames)
+}
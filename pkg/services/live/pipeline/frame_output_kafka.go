@@ -0,0 +1,124 @@
+package pipeline
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/segmentio/kafka-go"
+	"github.com/segmentio/kafka-go/sasl/plain"
+)
+
+type KafkaOutputConfig struct {
+	// UID of a write config with a comma separated list of brokers as endpoint.
+	// Basic auth is used for SASL PLAIN authentication.
+	UID string `json:"uid"`
+	// Topic is a template of the topic to write into, where ${scope},
+	// ${namespace}, ${path} and ${channel} are replaced with parts of the
+	// channel. Slashes are replaced with dots. Default is ${scope}.${namespace}.
+	Topic string `json:"topic,omitempty"`
+	// Key is a template of the message key, so that messages of a channel
+	// keep the order in a single partition. Default is ${path}.
+	Key string `json:"key,omitempty"`
+}
+
+const (
+	defaultKafkaTopic = "${scope}.${namespace}"
+	defaultKafkaKey   = "${path}"
+)
+
+// KafkaProducer writes messages to Kafka.
+type KafkaProducer interface {
+	WriteMessages(ctx context.Context, msgs ...kafka.Message) error
+}
+
+// newKafkaProducer creates an asynchronous Kafka writer, which batches
+// messages and retries failed writes in the background.
+func newKafkaProducer(brokers string, basicAuth *BasicAuth) *kafka.Writer {
+	var addrs []string
+	for _, broker := range strings.Split(brokers, ",") {
+		if broker = strings.TrimSpace(broker); broker != "" {
+			addrs = append(addrs, broker)
+		}
+	}
+	transport := &kafka.Transport{}
+	if basicAuth != nil {
+		transport.SASL = plain.Mechanism{Username: basicAuth.User, Password: basicAuth.Password}
+	}
+	return &kafka.Writer{
+		Addr:         kafka.TCP(addrs...),
+		Balancer:     &kafka.Hash{},
+		BatchTimeout: 100 * time.Millisecond,
+		RequiredAcks: kafka.RequireAll,
+		Async:        true,
+		Transport:    transport,
+		Completion: func(messages []kafka.Message, err error) {
+			if err != nil {
+				logger.Error("Error writing to Kafka", "error", err, "numMessages", len(messages))
+			}
+		},
+	}
+}
+
+var kafkaTopicReplacer = regexp.MustCompile(`[^a-zA-Z0-9._-]`)
+
+func kafkaMessage(config KafkaOutputConfig, vars Vars, value []byte) kafka.Message {
+	topic := config.Topic
+	if topic == "" {
+		topic = defaultKafkaTopic
+	}
+	topic = strings.ReplaceAll(expandChannelTemplate(topic, vars), "/", ".")
+	key := config.Key
+	if key == "" {
+		key = defaultKafkaKey
+	}
+	return kafka.Message{
+		Topic: kafkaTopicReplacer.ReplaceAllString(topic, "_"),
+		Key:   []byte(expandChannelTemplate(key, vars)),
+		Value: value,
+		Headers: []kafka.Header{
+			{Key: "channel", Value: []byte(vars.Channel)},
+		},
+	}
+}
+
+// expandChannelTemplate replaces ${scope}, ${namespace}, ${path} and ${channel}
+// in a template.
+func expandChannelTemplate(template string, vars Vars) string {
+	return strings.NewReplacer(
+		"${scope}", vars.Scope,
+		"${namespace}", vars.Namespace,
+		"${path}", vars.Path,
+		"${channel}", vars.Channel,
+	).Replace(template)
+}
+
+// KafkaFrameOutput writes frames encoded to JSON into Kafka topics.
+type KafkaFrameOutput struct {
+	producer KafkaProducer
+	config   KafkaOutputConfig
+}
+
+func NewKafkaFrameOutput(producer KafkaProducer, config KafkaOutputConfig) *KafkaFrameOutput {
+	return &KafkaFrameOutput{producer: producer, config: config}
+}
+
+const FrameOutputTypeKafka = "kafka"
+
+func (out *KafkaFrameOutput) Type() string {
+	return FrameOutputTypeKafka
+}
+
+func (out *KafkaFrameOutput) OutputFrame(ctx context.Context, vars Vars, frame *data.Frame) ([]*ChannelFrame, error) {
+	frameJSON, err := data.FrameToJSON(frame, data.IncludeAll)
+	if err != nil {
+		return nil, err
+	}
+	if err := out.producer.WriteMessages(ctx, kafkaMessage(out.config, vars, frameJSON)); err != nil {
+		return nil, fmt.Errorf("error writing to Kafka: %w", err)
+	}
+	return nil, nil
+}
@@ -0,0 +1,75 @@
+package pipeline
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/segmentio/kafka-go"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeKafkaProducer is a local broker stand-in collecting written messages.
+type fakeKafkaProducer struct {
+	mu       sync.Mutex
+	messages []kafka.Message
+}
+
+func (p *fakeKafkaProducer) WriteMessages(_ context.Context, msgs ...kafka.Message) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.messages = append(p.messages, msgs...)
+	return nil
+}
+
+func TestKafkaFrameOutput_DefaultTemplates(t *testing.T) {
+	producer := &fakeKafkaProducer{}
+	out := NewKafkaFrameOutput(producer, KafkaOutputConfig{UID: "kafka"})
+
+	vars := Vars{
+		OrgID:     1,
+		Channel:   "stream/telegraf/cpu/host1",
+		Scope:     "stream",
+		Namespace: "telegraf",
+		Path:      "cpu/host1",
+	}
+	frame := data.NewFrame("cpu", data.NewField("value", nil, []float64{1}))
+	_, err := out.OutputFrame(context.Background(), vars, frame)
+	require.NoError(t, err)
+
+	require.Len(t, producer.messages, 1)
+	msg := producer.messages[0]
+	require.Equal(t, "stream.telegraf", msg.Topic)
+	require.Equal(t, "cpu/host1", string(msg.Key))
+	require.Equal(t, []kafka.Header{{Key: "channel", Value: []byte("stream/telegraf/cpu/host1")}}, msg.Headers)
+
+	frameJSON, err := data.FrameToJSON(frame, data.IncludeAll)
+	require.NoError(t, err)
+	require.JSONEq(t, string(frameJSON), string(msg.Value))
+}
+
+func TestKafkaDataOutput_Templates(t *testing.T) {
+	producer := &fakeKafkaProducer{}
+	out := NewKafkaDataOutput(producer, KafkaOutputConfig{
+		UID:   "kafka",
+		Topic: "live-${namespace}/${path}",
+		Key:   "${channel}",
+	})
+
+	vars := Vars{
+		OrgID:     1,
+		Channel:   "stream/telegraf/cpu:host 1",
+		Scope:     "stream",
+		Namespace: "telegraf",
+		Path:      "cpu:host 1",
+	}
+	_, err := out.OutputData(context.Background(), vars, []byte(`{"value":1}`))
+	require.NoError(t, err)
+
+	require.Len(t, producer.messages, 1)
+	msg := producer.messages[0]
+	require.Equal(t, "live-telegraf.cpu_host_1", msg.Topic)
+	require.Equal(t, "stream/telegraf/cpu:host 1", string(msg.Key))
+	require.Equal(t, `{"value":1}`, string(msg.Value))
+}
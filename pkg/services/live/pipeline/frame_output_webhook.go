@@ -0,0 +1,228 @@
+package pipeline
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+)
+
+type WebhookOutputConfig struct {
+	// UID of a write config with the webhook URL as endpoint.
+	UID string `json:"uid"`
+	// BatchSize is a maximum number of messages sent in one request, 100 by
+	// default.
+	BatchSize int `json:"batchSize,omitempty"`
+	// FlushIntervalMilliseconds is how often collected messages are sent,
+	// 1000 by default.
+	FlushIntervalMilliseconds int64 `json:"flushIntervalMilliseconds,omitempty"`
+	// MaxRetries is a number of retries of a failed request before the batch
+	// is dropped, 3 by default.
+	MaxRetries int `json:"maxRetries,omitempty"`
+}
+
+const (
+	defaultWebhookBatchSize     = 100
+	defaultWebhookFlushInterval = time.Second
+	defaultWebhookMaxRetries    = 3
+	// maxWebhookBufferSize limits messages kept while the webhook is failing,
+	// the oldest messages are dropped first.
+	maxWebhookBufferSize = 10000
+)
+
+// WebhookMessage is a message sent to a webhook. Data is a JSON encoded frame
+// for frame outputs and raw data for data outputs, encoded as JSON string
+// unless it is valid JSON.
+type WebhookMessage struct {
+	Channel string          `json:"channel"`
+	Time    int64           `json:"time"`
+	Data    json.RawMessage `json:"data"`
+}
+
+// WebhookRequest is a body of requests sent to webhooks.
+type WebhookRequest struct {
+	Messages []WebhookMessage `json:"messages"`
+}
+
+// webhookWriter sends messages to a webhook in batches. Requests failed with
+// network errors, 429 or 5xx responses are retried with exponential backoff.
+type webhookWriter struct {
+	endpoint      string
+	basicAuth     *BasicAuth
+	batchSize     int
+	flushInterval time.Duration
+	maxRetries    int
+	retryBackoff  time.Duration
+	httpClient    *http.Client
+
+	mu      sync.Mutex
+	buffer  []WebhookMessage
+	flushCh chan struct{}
+
+	closeOnce sync.Once
+	closeCh   chan struct{}
+	doneCh    chan struct{}
+}
+
+func newWebhookWriter(endpoint string, basicAuth *BasicAuth, config WebhookOutputConfig) *webhookWriter {
+	w := &webhookWriter{
+		endpoint:      endpoint,
+		basicAuth:     basicAuth,
+		batchSize:     config.BatchSize,
+		flushInterval: time.Duration(config.FlushIntervalMilliseconds) * time.Millisecond,
+		maxRetries:    config.MaxRetries,
+		retryBackoff:  500 * time.Millisecond,
+		httpClient:    &http.Client{Timeout: 5 * time.Second},
+		flushCh:       make(chan struct{}, 1),
+		closeCh:       make(chan struct{}),
+		doneCh:        make(chan struct{}),
+	}
+	if w.batchSize <= 0 {
+		w.batchSize = defaultWebhookBatchSize
+	}
+	if w.flushInterval <= 0 {
+		w.flushInterval = defaultWebhookFlushInterval
+	}
+	if w.maxRetries <= 0 {
+		w.maxRetries = defaultWebhookMaxRetries
+	}
+	go w.run()
+	return w
+}
+
+func (w *webhookWriter) write(msg WebhookMessage) {
+	w.mu.Lock()
+	w.buffer = append(w.buffer, msg)
+	if len(w.buffer) > maxWebhookBufferSize {
+		logger.Warn("Webhook buffer is full, dropping messages", "url", w.endpoint)
+		w.buffer = w.buffer[len(w.buffer)-maxWebhookBufferSize:]
+	}
+	full := len(w.buffer) >= w.batchSize
+	w.mu.Unlock()
+	if full {
+		select {
+		case w.flushCh <- struct{}{}:
+		default:
+		}
+	}
+}
+
+func (w *webhookWriter) run() {
+	defer close(w.doneCh)
+	ticker := time.NewTicker(w.flushInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-w.closeCh:
+			w.flush()
+			return
+		case <-ticker.C:
+		case <-w.flushCh:
+		}
+		w.flush()
+	}
+}
+
+// Close sends buffered messages and stops the writer.
+func (w *webhookWriter) Close() error {
+	w.closeOnce.Do(func() {
+		close(w.closeCh)
+	})
+	<-w.doneCh
+	return nil
+}
+
+func (w *webhookWriter) flush() {
+	for {
+		w.mu.Lock()
+		n := len(w.buffer)
+		if n > w.batchSize {
+			n = w.batchSize
+		}
+		if n == 0 {
+			w.mu.Unlock()
+			return
+		}
+		batch := make([]WebhookMessage, n)
+		copy(batch, w.buffer)
+		w.buffer = w.buffer[n:]
+		w.mu.Unlock()
+
+		if err := w.send(batch); err != nil {
+			logger.Error("Error sending to webhook, messages dropped", "error", err, "url", w.endpoint, "numMessages", len(batch))
+		}
+	}
+}
+
+func (w *webhookWriter) send(batch []WebhookMessage) error {
+	body, err := json.Marshal(WebhookRequest{Messages: batch})
+	if err != nil {
+		return err
+	}
+	for attempt := 0; ; attempt++ {
+		var retry bool
+		retry, err = w.post(body)
+		if err == nil || !retry || attempt >= w.maxRetries {
+			return err
+		}
+		time.Sleep(w.retryBackoff << attempt)
+	}
+}
+
+// post sends a request and returns whether it can be retried on error.
+func (w *webhookWriter) post(body []byte) (bool, error) {
+	req, err := http.NewRequest(http.MethodPost, w.endpoint, bytes.NewReader(body))
+	if err != nil {
+		return false, fmt.Errorf("error constructing webhook request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if w.basicAuth != nil {
+		req.SetBasicAuth(w.basicAuth.User, w.basicAuth.Password)
+	}
+	resp, err := w.httpClient.Do(req)
+	if err != nil {
+		return true, fmt.Errorf("error sending webhook request: %w", err)
+	}
+	_, _ = io.Copy(ioutil.Discard, resp.Body)
+	_ = resp.Body.Close()
+	if resp.StatusCode/100 == 2 {
+		return false, nil
+	}
+	retry := resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500
+	return retry, fmt.Errorf("unexpected response code from webhook: %d", resp.StatusCode)
+}
+
+// WebhookFrameOutput sends frames encoded to JSON to a webhook in batches.
+type WebhookFrameOutput struct {
+	writer *webhookWriter
+}
+
+func NewWebhookFrameOutput(writer *webhookWriter) *WebhookFrameOutput {
+	return &WebhookFrameOutput{writer: writer}
+}
+
+const FrameOutputTypeWebhook = "webhook"
+
+func (out *WebhookFrameOutput) Type() string {
+	return FrameOutputTypeWebhook
+}
+
+func (out *WebhookFrameOutput) OutputFrame(_ context.Context, vars Vars, frame *data.Frame) ([]*ChannelFrame, error) {
+	frameJSON, err := data.FrameToJSON(frame, data.IncludeAll)
+	if err != nil {
+		return nil, err
+	}
+	out.writer.write(WebhookMessage{
+		Channel: vars.Channel,
+		Time:    time.Now().UnixNano() / int64(time.Millisecond),
+		Data:    frameJSON,
+	})
+	return nil, nil
+}
@@ -0,0 +1,134 @@
+package pipeline
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+type webhookTestServer struct {
+	mu       sync.Mutex
+	requests []WebhookRequest
+	failures int
+	authUser string
+}
+
+func (s *webhookTestServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.failures > 0 {
+		s.failures--
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	s.authUser, _, _ = r.BasicAuth()
+	var req WebhookRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	s.requests = append(s.requests, req)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *webhookTestServer) getRequests() []WebhookRequest {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]WebhookRequest(nil), s.requests...)
+}
+
+func newTestWebhookWriter(t *testing.T, server *webhookTestServer, config WebhookOutputConfig) *webhookWriter {
+	t.Helper()
+	ts := httptest.NewServer(server)
+	t.Cleanup(ts.Close)
+	w := newWebhookWriter(ts.URL, &BasicAuth{User: "user", Password: "pass"}, config)
+	w.retryBackoff = time.Millisecond
+	t.Cleanup(func() { _ = w.Close() })
+	return w
+}
+
+func TestWebhookDataOutput_Batching(t *testing.T) {
+	server := &webhookTestServer{}
+	writer := newTestWebhookWriter(t, server, WebhookOutputConfig{
+		UID:                       "webhook",
+		BatchSize:                 2,
+		FlushIntervalMilliseconds: 60000,
+	})
+	out := NewWebhookDataOutput(writer)
+
+	vars := Vars{Channel: "stream/test/a"}
+	for _, d := range []string{`{"value":1}`, `plain text`} {
+		_, err := out.OutputData(context.Background(), vars, []byte(d))
+		require.NoError(t, err)
+	}
+
+	// Full batch is sent without waiting for flush interval.
+	require.Eventually(t, func() bool {
+		return len(server.getRequests()) == 1
+	}, 5*time.Second, 10*time.Millisecond)
+
+	_, err := out.OutputData(context.Background(), vars, []byte(`{"value":3}`))
+	require.NoError(t, err)
+	require.Len(t, server.getRequests(), 1)
+
+	// Remaining message is sent on close.
+	require.NoError(t, writer.Close())
+	requests := server.getRequests()
+	require.Len(t, requests, 2)
+	require.Len(t, requests[0].Messages, 2)
+	require.Equal(t, "stream/test/a", requests[0].Messages[0].Channel)
+	require.JSONEq(t, `{"value":1}`, string(requests[0].Messages[0].Data))
+	require.JSONEq(t, `"plain text"`, string(requests[0].Messages[1].Data))
+	require.Len(t, requests[1].Messages, 1)
+	require.JSONEq(t, `{"value":3}`, string(requests[1].Messages[0].Data))
+	require.Equal(t, "user", server.authUser)
+}
+
+func TestWebhookDataOutput_Retry(t *testing.T) {
+	server := &webhookTestServer{failures: 2}
+	writer := newTestWebhookWriter(t, server, WebhookOutputConfig{
+		UID:                       "webhook",
+		FlushIntervalMilliseconds: 10,
+		MaxRetries:                3,
+	})
+	out := NewWebhookDataOutput(writer)
+
+	_, err := out.OutputData(context.Background(), Vars{Channel: "stream/test/a"}, []byte(`{"value":1}`))
+	require.NoError(t, err)
+
+	require.Eventually(t, func() bool {
+		return len(server.getRequests()) == 1
+	}, 5*time.Second, 10*time.Millisecond)
+	require.Len(t, server.getRequests()[0].Messages, 1)
+}
+
+func TestWebhookDataOutput_DropAfterRetries(t *testing.T) {
+	server := &webhookTestServer{failures: 2}
+	writer := newTestWebhookWriter(t, server, WebhookOutputConfig{
+		UID:                       "webhook",
+		FlushIntervalMilliseconds: 10,
+		MaxRetries:                1,
+	})
+	out := NewWebhookDataOutput(writer)
+
+	_, err := out.OutputData(context.Background(), Vars{Channel: "stream/test/a"}, []byte(`{"value":1}`))
+	require.NoError(t, err)
+	require.Eventually(t, func() bool {
+		server.mu.Lock()
+		defer server.mu.Unlock()
+		return server.failures == 0
+	}, 5*time.Second, 10*time.Millisecond)
+
+	_, err = out.OutputData(context.Background(), Vars{Channel: "stream/test/a"}, []byte(`{"value":2}`))
+	require.NoError(t, err)
+	require.Eventually(t, func() bool {
+		return len(server.getRequests()) == 1
+	}, 5*time.Second, 10*time.Millisecond)
+	require.JSONEq(t, `{"value":2}`, string(server.getRequests()[0].Messages[0].Data))
+}
@@ -0,0 +1,224 @@
+package pipeline
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/grafana/grafana/pkg/services/live/orgchannel"
+)
+
+// AggregateFrameProcessor aggregates rows into tumbling time windows. A window is
+// closed and outputted as a single row once a row of a later window arrives, until
+// then frames are dropped. Rows arriving late are added to the current window.
+// Windows of channels which did not receive data for processorStateIdleTimeout
+// are evicted.
+type AggregateFrameProcessor struct {
+	config   AggregateFrameProcessorConfig
+	interval time.Duration
+
+	mu        sync.Mutex
+	windows   map[string]*aggregateWindow
+	lastPrune time.Time
+}
+
+type aggregateWindow struct {
+	start    time.Time
+	fields   []aggregateFieldState
+	lastSeen time.Time
+}
+
+const (
+	// processorStateIdleTimeout is how long stateful processors keep the state
+	// of a channel which does not receive any data.
+	processorStateIdleTimeout = 30 * time.Minute
+	// processorStatePruneInterval is how often idle channel state is looked for.
+	processorStatePruneInterval = time.Minute
+)
+
+type aggregateFieldState struct {
+	labels data.Labels
+	count  int
+	sum    float64
+	min    float64
+	max    float64
+	last   *float64
+}
+
+func NewAggregateFrameProcessor(config AggregateFrameProcessorConfig) (*AggregateFrameProcessor, error) {
+	if config.IntervalMilliseconds <= 0 {
+		return nil, errors.New("aggregation interval must be positive")
+	}
+	for _, f := range config.Fields {
+		for _, reducer := range f.Reducers {
+			switch reducer {
+			case AggregateReducerMean, AggregateReducerMin, AggregateReducerMax,
+				AggregateReducerSum, AggregateReducerCount, AggregateReducerLast:
+			default:
+				return nil, fmt.Errorf("unknown reducer %q for field %s", reducer, f.FieldName)
+			}
+		}
+	}
+	return &AggregateFrameProcessor{
+		config:   config,
+		interval: time.Duration(config.IntervalMilliseconds) * time.Millisecond,
+		windows:  map[string]*aggregateWindow{},
+	}, nil
+}
+
+const FrameProcessorTypeAggregate = "aggregate"
+
+func (p *AggregateFrameProcessor) Type() string {
+	return FrameProcessorTypeAggregate
+}
+
+func (p *AggregateFrameProcessor) ProcessFrame(_ context.Context, vars Vars, frame *data.Frame) (*data.Frame, error) {
+	timeField := frameTimeField(frame)
+	if timeField == nil {
+		return nil, errors.New("aggregation requires a time field")
+	}
+
+	fields := make([]*data.Field, len(p.config.Fields))
+	for i, f := range p.config.Fields {
+		fields[i], _ = frame.FieldByName(f.FieldName)
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	now := time.Now()
+	p.pruneIdle(now)
+
+	key := orgchannel.PrependOrgID(vars.OrgID, vars.Channel)
+	window := p.windows[key]
+
+	var closed []*aggregateWindow
+	for row := 0; row < timeField.Len(); row++ {
+		t, ok := timeField.ConcreteAt(row)
+		if !ok {
+			continue
+		}
+		start := t.(time.Time).Truncate(p.interval)
+		if window == nil || start.After(window.start) {
+			if window != nil {
+				closed = append(closed, window)
+			}
+			window = &aggregateWindow{
+				start:  start,
+				fields: make([]aggregateFieldState, len(p.config.Fields)),
+			}
+		}
+		for i, field := range fields {
+			if field == nil {
+				continue
+			}
+			value, err := field.NullableFloatAt(row)
+			if err != nil {
+				return nil, fmt.Errorf("error aggregating field %s: %w", field.Name, err)
+			}
+			window.fields[i].add(value, field.Labels)
+		}
+	}
+	if window == nil {
+		return nil, nil
+	}
+	window.lastSeen = now
+	p.windows[key] = window
+
+	if len(closed) == 0 {
+		return nil, nil
+	}
+	return p.windowsFrame(frame.Name, closed), nil
+}
+
+// pruneIdle removes windows of channels which were idle for too long. Must be
+// called with mutex held.
+func (p *AggregateFrameProcessor) pruneIdle(now time.Time) {
+	if now.Sub(p.lastPrune) < processorStatePruneInterval {
+		return
+	}
+	p.lastPrune = now
+	for key, w := range p.windows {
+		if now.Sub(w.lastSeen) > processorStateIdleTimeout {
+			delete(p.windows, key)
+		}
+	}
+}
+
+func (p *AggregateFrameProcessor) windowsFrame(name string, windows []*aggregateWindow) *data.Frame {
+	timeField := data.NewFieldFromFieldType(data.FieldTypeTime, len(windows))
+	timeField.Name = "time"
+	fields := []*data.Field{timeField}
+	for row, w := range windows {
+		timeField.Set(row, w.start)
+	}
+	for i, f := range p.config.Fields {
+		for _, reducer := range f.Reducers {
+			field := data.NewFieldFromFieldType(data.FieldTypeNullableFloat64, len(windows))
+			field.Name = f.FieldName + "_" + string(reducer)
+			field.Labels = windows[len(windows)-1].fields[i].labels
+			for row, w := range windows {
+				field.Set(row, w.fields[i].reduce(reducer))
+			}
+			fields = append(fields, field)
+		}
+	}
+	return data.NewFrame(name, fields...)
+}
+
+func (s *aggregateFieldState) add(value *float64, labels data.Labels) {
+	if labels != nil {
+		s.labels = labels
+	}
+	if value == nil || math.IsNaN(*value) {
+		return
+	}
+	v := *value
+	if s.count == 0 || v < s.min {
+		s.min = v
+	}
+	if s.count == 0 || v > s.max {
+		s.max = v
+	}
+	s.count++
+	s.sum += v
+	s.last = &v
+}
+
+func (s *aggregateFieldState) reduce(reducer AggregateReducer) *float64 {
+	if reducer == AggregateReducerCount {
+		count := float64(s.count)
+		return &count
+	}
+	if s.count == 0 {
+		return nil
+	}
+	var v float64
+	switch reducer {
+	case AggregateReducerMean:
+		v = s.sum / float64(s.count)
+	case AggregateReducerMin:
+		v = s.min
+	case AggregateReducerMax:
+		v = s.max
+	case AggregateReducerSum:
+		v = s.sum
+	case AggregateReducerLast:
+		v = *s.last
+	}
+	return &v
+}
+
+// frameTimeField returns the first time field of a frame.
+func frameTimeField(frame *data.Frame) *data.Field {
+	for _, f := range frame.Fields {
+		if f.Type() == data.FieldTypeTime || f.Type() == data.FieldTypeNullableTime {
+			return f
+		}
+	}
+	return nil
+}
@@ -0,0 +1,109 @@
+package pipeline
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAggregateFrameProcessor(t *testing.T) {
+	p, err := NewAggregateFrameProcessor(AggregateFrameProcessorConfig{
+		IntervalMilliseconds: 10000,
+		Fields: []AggregateField{
+			{FieldName: "value", Reducers: []AggregateReducer{AggregateReducerMean, AggregateReducerMax, AggregateReducerCount}},
+		},
+	})
+	require.NoError(t, err)
+
+	start := time.Unix(1000, 0)
+	vars := Vars{OrgID: 1, Channel: "stream/test/aggregate"}
+
+	frame, err := p.ProcessFrame(context.Background(), vars, data.NewFrame("test",
+		data.NewField("time", nil, []time.Time{start, start.Add(time.Second), start.Add(2 * time.Second)}),
+		data.NewField("value", data.Labels{"host": "a"}, []float64{1, 5, 3}),
+	))
+	require.NoError(t, err)
+	require.Nil(t, frame, "window must not be outputted until it's closed")
+
+	frame, err = p.ProcessFrame(context.Background(), vars, data.NewFrame("test",
+		data.NewField("time", nil, []time.Time{start.Add(5 * time.Second), start.Add(10 * time.Second)}),
+		data.NewField("value", data.Labels{"host": "a"}, []float64{7, 100}),
+	))
+	require.NoError(t, err)
+	require.NotNil(t, frame)
+	require.Equal(t, "test", frame.Name)
+	require.Len(t, frame.Fields, 4)
+	require.Equal(t, 1, frame.Fields[0].Len())
+	require.Equal(t, start, frame.Fields[0].At(0))
+
+	require.Equal(t, "value_mean", frame.Fields[1].Name)
+	require.Equal(t, data.Labels{"host": "a"}, frame.Fields[1].Labels)
+	require.Equal(t, 4.0, *frame.Fields[1].At(0).(*float64))
+	require.Equal(t, "value_max", frame.Fields[2].Name)
+	require.Equal(t, 7.0, *frame.Fields[2].At(0).(*float64))
+	require.Equal(t, "value_count", frame.Fields[3].Name)
+	require.Equal(t, 4.0, *frame.Fields[3].At(0).(*float64))
+
+	// Windows are kept per channel.
+	frame, err = p.ProcessFrame(context.Background(), Vars{OrgID: 1, Channel: "stream/test/other"}, data.NewFrame("test",
+		data.NewField("time", nil, []time.Time{start.Add(20 * time.Second)}),
+		data.NewField("value", nil, []float64{1}),
+	))
+	require.NoError(t, err)
+	require.Nil(t, frame)
+}
+
+func TestAggregateFrameProcessor_MissingValues(t *testing.T) {
+	p, err := NewAggregateFrameProcessor(AggregateFrameProcessorConfig{
+		IntervalMilliseconds: 1000,
+		Fields: []AggregateField{
+			{FieldName: "value", Reducers: []AggregateReducer{AggregateReducerLast, AggregateReducerCount}},
+		},
+	})
+	require.NoError(t, err)
+
+	start := time.Unix(1000, 0)
+	frame, err := p.ProcessFrame(context.Background(), Vars{}, data.NewFrame("test",
+		data.NewField("time", nil, []time.Time{start, start.Add(time.Second), start.Add(2 * time.Second)}),
+		data.NewField("value", nil, []*float64{nil, nil, nil}),
+	))
+	require.NoError(t, err)
+	require.Equal(t, 2, frame.Fields[0].Len())
+	require.Nil(t, frame.Fields[1].At(0))
+	require.Equal(t, 0.0, *frame.Fields[2].At(0).(*float64))
+}
+
+func TestNewAggregateFrameProcessor_Invalid(t *testing.T) {
+	_, err := NewAggregateFrameProcessor(AggregateFrameProcessorConfig{})
+	require.Error(t, err)
+
+	_, err = NewAggregateFrameProcessor(AggregateFrameProcessorConfig{
+		IntervalMilliseconds: 1000,
+		Fields:               []AggregateField{{FieldName: "value", Reducers: []AggregateReducer{"median"}}},
+	})
+	require.Error(t, err)
+}
+
+func TestAggregateFrameProcessor_PruneIdle(t *testing.T) {
+	p, err := NewAggregateFrameProcessor(AggregateFrameProcessorConfig{
+		IntervalMilliseconds: 10000,
+		Fields:               []AggregateField{{FieldName: "value", Reducers: []AggregateReducer{AggregateReducerCount}}},
+	})
+	require.NoError(t, err)
+
+	_, err = p.ProcessFrame(context.Background(), Vars{OrgID: 1, Channel: "stream/test/idle"}, data.NewFrame("test",
+		data.NewField("time", nil, []time.Time{time.Unix(1000, 0)}),
+		data.NewField("value", nil, []float64{1}),
+	))
+	require.NoError(t, err)
+	require.Len(t, p.windows, 1)
+
+	p.pruneIdle(time.Now().Add(processorStateIdleTimeout / 2))
+	require.Len(t, p.windows, 1)
+
+	p.pruneIdle(time.Now().Add(2 * processorStateIdleTimeout))
+	require.Len(t, p.windows, 0)
+}
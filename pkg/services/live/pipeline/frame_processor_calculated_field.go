@@ -0,0 +1,112 @@
+package pipeline
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/dop251/goja"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+)
+
+// CalculatedFieldFrameProcessor adds a field calculated with a JavaScript
+// expression for every frame row. Row values are available by field name as
+// x.<fieldName>, time values are Unix timestamps in milliseconds.
+type CalculatedFieldFrameProcessor struct {
+	config  CalculatedFieldFrameProcessorConfig
+	program *goja.Program
+}
+
+func NewCalculatedFieldFrameProcessor(config CalculatedFieldFrameProcessorConfig) (*CalculatedFieldFrameProcessor, error) {
+	switch config.Type {
+	case data.FieldTypeNullableFloat64, data.FieldTypeNullableString, data.FieldTypeNullableBool:
+	default:
+		return nil, fmt.Errorf("unsupported field type: %s (%s)", config.Type, config.FieldName)
+	}
+	program, err := goja.Compile(config.FieldName, config.Expression, true)
+	if err != nil {
+		return nil, fmt.Errorf("invalid expression for %s: %w", config.FieldName, err)
+	}
+	return &CalculatedFieldFrameProcessor{config: config, program: program}, nil
+}
+
+const FrameProcessorTypeCalculatedField = "calculatedField"
+
+func (p *CalculatedFieldFrameProcessor) Type() string {
+	return FrameProcessorTypeCalculatedField
+}
+
+func (p *CalculatedFieldFrameProcessor) ProcessFrame(_ context.Context, _ Vars, frame *data.Frame) (*data.Frame, error) {
+	rowLen, err := frame.RowLen()
+	if err != nil {
+		return nil, err
+	}
+
+	field := data.NewFieldFromFieldType(p.config.Type, rowLen)
+	field.Name = p.config.FieldName
+	field.Config = p.config.Config
+
+	r := newRuntime()
+	for row := 0; row < rowLen; row++ {
+		if err := r.setRow(frameRow(frame, row)); err != nil {
+			return nil, err
+		}
+		v, err := r.runProgram(p.program)
+		if err != nil {
+			return nil, fmt.Errorf("error calculating %s: %w", p.config.FieldName, err)
+		}
+		if goja.IsNull(v) || goja.IsUndefined(v) {
+			continue
+		}
+		exported := v.Export()
+		switch p.config.Type {
+		case data.FieldTypeNullableFloat64:
+			switch val := exported.(type) {
+			case float64:
+				field.SetConcrete(row, val)
+			case int64:
+				field.SetConcrete(row, float64(val))
+			default:
+				return nil, fmt.Errorf("unexpected value for %s: %v (%T)", p.config.FieldName, exported, exported)
+			}
+		case data.FieldTypeNullableString:
+			val, ok := exported.(string)
+			if !ok {
+				return nil, fmt.Errorf("unexpected value for %s: %v (%T)", p.config.FieldName, exported, exported)
+			}
+			field.SetConcrete(row, val)
+		case data.FieldTypeNullableBool:
+			val, ok := exported.(bool)
+			if !ok {
+				return nil, fmt.Errorf("unexpected value for %s: %v (%T)", p.config.FieldName, exported, exported)
+			}
+			field.SetConcrete(row, val)
+		}
+	}
+
+	fields := make([]*data.Field, 0, len(frame.Fields)+1)
+	for _, f := range frame.Fields {
+		if f.Name != p.config.FieldName {
+			fields = append(fields, f)
+		}
+	}
+	fields = append(fields, field)
+	return data.NewFrame(frame.Name, fields...).SetMeta(frame.Meta), nil
+}
+
+// frameRow returns the values of a frame row by field name.
+func frameRow(frame *data.Frame, row int) map[string]interface{} {
+	values := make(map[string]interface{}, len(frame.Fields))
+	for _, f := range frame.Fields {
+		v, ok := f.ConcreteAt(row)
+		if !ok {
+			values[f.Name] = nil
+			continue
+		}
+		if t, ok := v.(time.Time); ok {
+			v = t.UnixNano() / int64(time.Millisecond)
+		}
+		values[f.Name] = v
+	}
+	return values
+}
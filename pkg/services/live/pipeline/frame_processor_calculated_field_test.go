@@ -0,0 +1,74 @@
+package pipeline
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCalculatedFieldFrameProcessor(t *testing.T) {
+	p, err := NewCalculatedFieldFrameProcessor(CalculatedFieldFrameProcessorConfig{
+		FieldName:  "total",
+		Type:       data.FieldTypeNullableFloat64,
+		Expression: "x.read === null ? null : x.read + x.write",
+	})
+	require.NoError(t, err)
+
+	read := 1.0
+	frame, err := p.ProcessFrame(context.Background(), Vars{}, data.NewFrame("test",
+		data.NewField("time", nil, []time.Time{time.Unix(1, 0), time.Unix(2, 0)}),
+		data.NewField("read", nil, []*float64{&read, nil}),
+		data.NewField("write", nil, []int64{2, 3}),
+	))
+	require.NoError(t, err)
+	require.Len(t, frame.Fields, 4)
+	require.Equal(t, "total", frame.Fields[3].Name)
+	require.Equal(t, 3.0, *frame.Fields[3].At(0).(*float64))
+	require.Nil(t, frame.Fields[3].At(1))
+}
+
+func TestCalculatedFieldFrameProcessor_Types(t *testing.T) {
+	p, err := NewCalculatedFieldFrameProcessor(CalculatedFieldFrameProcessorConfig{
+		FieldName:  "state",
+		Type:       data.FieldTypeNullableString,
+		Expression: "x.time >= 2000 ? 'late' : 'early'",
+	})
+	require.NoError(t, err)
+
+	frame, err := p.ProcessFrame(context.Background(), Vars{}, data.NewFrame("test",
+		data.NewField("time", nil, []time.Time{time.Unix(1, 0), time.Unix(2, 0)}),
+	))
+	require.NoError(t, err)
+	require.Equal(t, "early", *frame.Fields[1].At(0).(*string))
+	require.Equal(t, "late", *frame.Fields[1].At(1).(*string))
+
+	p, err = NewCalculatedFieldFrameProcessor(CalculatedFieldFrameProcessorConfig{
+		FieldName:  "state",
+		Type:       data.FieldTypeNullableBool,
+		Expression: "'not a bool'",
+	})
+	require.NoError(t, err)
+	_, err = p.ProcessFrame(context.Background(), Vars{}, data.NewFrame("test",
+		data.NewField("time", nil, []time.Time{time.Unix(1, 0)}),
+	))
+	require.Error(t, err)
+}
+
+func TestNewCalculatedFieldFrameProcessor_Invalid(t *testing.T) {
+	_, err := NewCalculatedFieldFrameProcessor(CalculatedFieldFrameProcessorConfig{
+		FieldName:  "total",
+		Type:       data.FieldTypeNullableFloat64,
+		Expression: "x.read +",
+	})
+	require.Error(t, err)
+
+	_, err = NewCalculatedFieldFrameProcessor(CalculatedFieldFrameProcessorConfig{
+		FieldName:  "total",
+		Type:       data.FieldTypeTime,
+		Expression: "x.read",
+	})
+	require.Error(t, err)
+}
@@ -0,0 +1,130 @@
+package pipeline
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/grafana/grafana/pkg/services/live/orgchannel"
+)
+
+// DerivativeFrameProcessor adds a <field>_rate field with the per-second rate of
+// change for each of the specified fields. The last value of every field is kept
+// between frames, so the first row of a frame is computed against the previous frame.
+// Values of channels which did not receive data for processorStateIdleTimeout are
+// evicted.
+type DerivativeFrameProcessor struct {
+	config DerivativeFrameProcessorConfig
+
+	mu        sync.Mutex
+	channels  map[string]*derivativeChannel
+	lastPrune time.Time
+}
+
+type derivativeChannel struct {
+	points   map[string]derivativePoint
+	lastSeen time.Time
+}
+
+type derivativePoint struct {
+	time  time.Time
+	value float64
+}
+
+func NewDerivativeFrameProcessor(config DerivativeFrameProcessorConfig) *DerivativeFrameProcessor {
+	return &DerivativeFrameProcessor{
+		config:   config,
+		channels: map[string]*derivativeChannel{},
+	}
+}
+
+const FrameProcessorTypeDerivative = "derivative"
+
+func (p *DerivativeFrameProcessor) Type() string {
+	return FrameProcessorTypeDerivative
+}
+
+func (p *DerivativeFrameProcessor) ProcessFrame(_ context.Context, vars Vars, frame *data.Frame) (*data.Frame, error) {
+	timeField := frameTimeField(frame)
+	if timeField == nil {
+		return nil, errors.New("derivative requires a time field")
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	now := time.Now()
+	p.pruneIdle(now)
+
+	key := orgchannel.PrependOrgID(vars.OrgID, vars.Channel)
+	channel, ok := p.channels[key]
+	if !ok {
+		channel = &derivativeChannel{points: map[string]derivativePoint{}}
+		p.channels[key] = channel
+	}
+	channel.lastSeen = now
+	points := channel.points
+
+	fields := make([]*data.Field, 0, len(frame.Fields)+len(p.config.FieldNames))
+	for _, field := range frame.Fields {
+		fields = append(fields, field)
+		if !stringInSlice(field.Name, p.config.FieldNames) {
+			continue
+		}
+		rateField := data.NewFieldFromFieldType(data.FieldTypeNullableFloat64, field.Len())
+		rateField.Name = field.Name + "_rate"
+		rateField.Labels = field.Labels
+
+		previous, hasPrevious := points[field.Name]
+		for row := 0; row < field.Len(); row++ {
+			t, ok := timeField.ConcreteAt(row)
+			if !ok {
+				continue
+			}
+			value, err := field.NullableFloatAt(row)
+			if err != nil {
+				return nil, fmt.Errorf("error computing derivative of field %s: %w", field.Name, err)
+			}
+			if value == nil {
+				continue
+			}
+			current := derivativePoint{time: t.(time.Time), value: *value}
+			if hasPrevious && current.time.After(previous.time) {
+				rateField.Set(row, p.rate(previous, current))
+			}
+			previous, hasPrevious = current, true
+		}
+		if hasPrevious {
+			points[field.Name] = previous
+		}
+		fields = append(fields, rateField)
+	}
+	return data.NewFrame(frame.Name, fields...).SetMeta(frame.Meta), nil
+}
+
+// pruneIdle removes values of channels which were idle for too long. Must be
+// called with mutex held.
+func (p *DerivativeFrameProcessor) pruneIdle(now time.Time) {
+	if now.Sub(p.lastPrune) < processorStatePruneInterval {
+		return
+	}
+	p.lastPrune = now
+	for key, ch := range p.channels {
+		if now.Sub(ch.lastSeen) > processorStateIdleTimeout {
+			delete(p.channels, key)
+		}
+	}
+}
+
+func (p *DerivativeFrameProcessor) rate(previous, current derivativePoint) *float64 {
+	delta := current.value - previous.value
+	if p.config.Counter && delta < 0 {
+		// Counter was reset, assume it started from zero.
+		delta = current.value
+	}
+	rate := delta / current.time.Sub(previous.time).Seconds()
+	return &rate
+}
@@ -0,0 +1,62 @@
+package pipeline
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDerivativeFrameProcessor(t *testing.T) {
+	p := NewDerivativeFrameProcessor(DerivativeFrameProcessorConfig{FieldNames: []string{"value"}})
+
+	start := time.Unix(1000, 0)
+	vars := Vars{OrgID: 1, Channel: "stream/test/derivative"}
+
+	frame, err := p.ProcessFrame(context.Background(), vars, data.NewFrame("test",
+		data.NewField("time", nil, []time.Time{start, start.Add(2 * time.Second)}),
+		data.NewField("value", nil, []float64{10, 20}),
+	))
+	require.NoError(t, err)
+	require.Len(t, frame.Fields, 3)
+	require.Equal(t, "value_rate", frame.Fields[2].Name)
+	require.Nil(t, frame.Fields[2].At(0))
+	require.Equal(t, 5.0, *frame.Fields[2].At(1).(*float64))
+
+	// The previous frame value is used for the first row.
+	frame, err = p.ProcessFrame(context.Background(), vars, data.NewFrame("test",
+		data.NewField("time", nil, []time.Time{start.Add(4 * time.Second)}),
+		data.NewField("value", nil, []float64{10}),
+	))
+	require.NoError(t, err)
+	require.Equal(t, -5.0, *frame.Fields[2].At(0).(*float64))
+}
+
+func TestDerivativeFrameProcessor_CounterReset(t *testing.T) {
+	p := NewDerivativeFrameProcessor(DerivativeFrameProcessorConfig{FieldNames: []string{"requests"}, Counter: true})
+
+	start := time.Unix(1000, 0)
+	frame, err := p.ProcessFrame(context.Background(), Vars{}, data.NewFrame("test",
+		data.NewField("time", nil, []time.Time{start, start.Add(time.Second), start.Add(2 * time.Second)}),
+		data.NewField("requests", nil, []int64{100, 110, 4}),
+	))
+	require.NoError(t, err)
+	require.Equal(t, 10.0, *frame.Fields[2].At(1).(*float64))
+	require.Equal(t, 4.0, *frame.Fields[2].At(2).(*float64))
+}
+
+func TestDerivativeFrameProcessor_PruneIdle(t *testing.T) {
+	p := NewDerivativeFrameProcessor(DerivativeFrameProcessorConfig{FieldNames: []string{"value"}})
+
+	_, err := p.ProcessFrame(context.Background(), Vars{OrgID: 1, Channel: "stream/test/idle"}, data.NewFrame("test",
+		data.NewField("time", nil, []time.Time{time.Unix(1000, 0)}),
+		data.NewField("value", nil, []float64{1}),
+	))
+	require.NoError(t, err)
+	require.Len(t, p.channels, 1)
+
+	p.pruneIdle(time.Now().Add(2 * processorStateIdleTimeout))
+	require.Len(t, p.channels, 0)
+}
@@ -26,6 +26,11 @@ func (p *MultipleFrameProcessor) ProcessFrame(ctx context.Context, vars Vars, fr
 			logger.Error("Error processing frame", "error", err)
 			return nil, err
 		}
+		if frame == nil {
+			// Processor dropped the frame (for example an aggregation
+			// window is still open), nothing left to pass on.
+			return nil, nil
+		}
 	}
 	return frame, nil
 }
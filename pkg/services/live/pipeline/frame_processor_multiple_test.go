@@ -0,0 +1,44 @@
+package pipeline
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMultipleFrameProcessor_StopsOnDroppedFrame(t *testing.T) {
+	aggregate, err := NewAggregateFrameProcessor(AggregateFrameProcessorConfig{
+		IntervalMilliseconds: 10000,
+		Fields: []AggregateField{
+			{FieldName: "value", Reducers: []AggregateReducer{AggregateReducerMax}},
+		},
+	})
+	require.NoError(t, err)
+	p := NewMultipleFrameProcessor(
+		aggregate,
+		NewKeepFieldsFrameProcessor(KeepFieldsFrameProcessorConfig{FieldNames: []string{"time", "value_max"}}),
+	)
+
+	start := time.Unix(1000, 0)
+	vars := Vars{OrgID: 1, Channel: "stream/test/multiple"}
+
+	frame, err := p.ProcessFrame(context.Background(), vars, data.NewFrame("test",
+		data.NewField("time", nil, []time.Time{start, start.Add(time.Second)}),
+		data.NewField("value", nil, []float64{1, 5}),
+	))
+	require.NoError(t, err)
+	require.Nil(t, frame)
+
+	frame, err = p.ProcessFrame(context.Background(), vars, data.NewFrame("test",
+		data.NewField("time", nil, []time.Time{start.Add(10 * time.Second)}),
+		data.NewField("value", nil, []float64{3}),
+	))
+	require.NoError(t, err)
+	require.NotNil(t, frame)
+	require.Len(t, frame.Fields, 2)
+	require.Equal(t, "value_max", frame.Fields[1].Name)
+	require.Equal(t, 5.0, *frame.Fields[1].At(0).(*float64))
+}
@@ -9,11 +9,15 @@ import (
 	"github.com/dop251/goja/parser"
 )
 
-func getRuntime(payload []byte) (*gojaRuntime, error) {
+func newRuntime() *gojaRuntime {
 	vm := goja.New()
 	vm.SetMaxCallStackSize(64)
 	vm.SetParserOptions(parser.WithDisableSourceMaps)
-	r := &gojaRuntime{vm}
+	return &gojaRuntime{vm}
+}
+
+func getRuntime(payload []byte) (*gojaRuntime, error) {
+	r := newRuntime()
 	err := r.init(payload)
 	if err != nil {
 		return nil, err
@@ -35,7 +39,24 @@ func (r *gojaRuntime) init(payload []byte) error {
 	return err
 }
 
+// setRow sets x to a frame row, so that scripts can access row values by field name.
+func (r *gojaRuntime) setRow(row map[string]interface{}) error {
+	return r.vm.Set("x", row)
+}
+
 func (r *gojaRuntime) runString(script string) (goja.Value, error) {
+	return r.run(func() (goja.Value, error) {
+		return r.vm.RunString(script)
+	})
+}
+
+func (r *gojaRuntime) runProgram(program *goja.Program) (goja.Value, error) {
+	return r.run(func() (goja.Value, error) {
+		return r.vm.RunProgram(program)
+	})
+}
+
+func (r *gojaRuntime) run(fn func() (goja.Value, error)) (goja.Value, error) {
 	doneCh := make(chan struct{})
 	go func() {
 		select {
@@ -52,7 +73,7 @@ func (r *gojaRuntime) runString(script string) (goja.Value, error) {
 		}
 	}()
 	defer close(doneCh)
-	return r.vm.RunString(script)
+	return fn()
 }
 
 func (r *gojaRuntime) getBool(script string) (bool, error) {
@@ -2,6 +2,7 @@ package pipeline
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/grafana/grafana/pkg/services/live/pipeline/pattern"
 	"github.com/grafana/grafana/pkg/services/live/pipeline/tree"
@@ -59,6 +60,11 @@ func WriteConfigToDto(b WriteConfig) WriteConfigDto {
 		UID:          b.UID,
 		Settings:     b.Settings,
 		SecureFields: secureFields,
+		Version:      b.Version,
+		Created:      b.Created,
+		Updated:      b.Updated,
+		CreatedBy:    b.CreatedBy,
+		UpdatedBy:    b.UpdatedBy,
 	}
 }
 
@@ -66,6 +72,11 @@ type WriteConfigDto struct {
 	UID          string          `json:"uid"`
 	Settings     WriteSettings   `json:"settings"`
 	SecureFields map[string]bool `json:"secureFields"`
+	Version      int64           `json:"version,omitempty"`
+	Created      *time.Time      `json:"created,omitempty"`
+	Updated      *time.Time      `json:"updated,omitempty"`
+	CreatedBy    int64           `json:"createdBy,omitempty"`
+	UpdatedBy    int64           `json:"updatedBy,omitempty"`
 }
 
 type WriteConfigGetCmd struct {
@@ -76,17 +87,24 @@ type WriteConfigCreateCmd struct {
 	UID            string            `json:"uid"`
 	Settings       WriteSettings     `json:"settings"`
 	SecureSettings map[string]string `json:"secureSettings"`
+	UserID         int64             `json:"-"`
 }
 
-// TODO: add version field later.
 type WriteConfigUpdateCmd struct {
 	UID            string            `json:"uid"`
 	Settings       WriteSettings     `json:"settings"`
 	SecureSettings map[string]string `json:"secureSettings"`
+	// Version is the version the update is based on. Storages keeping
+	// versions reject the update with ErrVersionConflict if it is not the
+	// current one. 0 overwrites any version.
+	Version int64 `json:"version,omitempty"`
+	UserID  int64 `json:"-"`
 }
 
 type WriteConfigDeleteCmd struct {
-	UID string `json:"uid"`
+	UID     string `json:"uid"`
+	Version int64  `json:"version,omitempty"`
+	UserID  int64  `json:"-"`
 }
 
 type WriteConfig struct {
@@ -94,6 +112,13 @@ type WriteConfig struct {
 	UID            string            `json:"uid"`
 	Settings       WriteSettings     `json:"settings"`
 	SecureSettings map[string][]byte `json:"secureSettings,omitempty"`
+
+	// Version and change metadata are only kept by database storage.
+	Version   int64      `json:"version,omitempty"`
+	Created   *time.Time `json:"created,omitempty"`
+	Updated   *time.Time `json:"updated,omitempty"`
+	CreatedBy int64      `json:"createdBy,omitempty"`
+	UpdatedBy int64      `json:"updatedBy,omitempty"`
 }
 
 func (r WriteConfig) Valid() (bool, string) {
@@ -129,7 +154,9 @@ type ChannelRules struct {
 	Rules []ChannelRule `json:"rules"`
 }
 
-func checkRulesValid(orgID int64, rules []ChannelRule) (ok bool, reason string) {
+// CheckRulesValid checks that rules of an organization can be added into
+// the same routing tree, so that patterns do not conflict.
+func CheckRulesValid(orgID int64, rules []ChannelRule) (ok bool, reason string) {
 	t := tree.New()
 	defer func() {
 		if r := recover(); r != nil {
@@ -149,13 +176,58 @@ func checkRulesValid(orgID int64, rules []ChannelRule) (ok bool, reason string)
 type ChannelRuleCreateCmd struct {
 	Pattern  string              `json:"pattern"`
 	Settings ChannelRuleSettings `json:"settings"`
+	UserID   int64               `json:"-"`
 }
 
 type ChannelRuleUpdateCmd struct {
 	Pattern  string              `json:"pattern"`
 	Settings ChannelRuleSettings `json:"settings"`
+	// Version is the version the update is based on. Storages keeping
+	// versions reject the update with ErrVersionConflict if it is not the
+	// current one. 0 overwrites any version.
+	Version int64 `json:"version,omitempty"`
+	UserID  int64 `json:"-"`
 }
 
 type ChannelRuleDeleteCmd struct {
 	Pattern string `json:"pattern"`
+	Version int64  `json:"version,omitempty"`
+	UserID  int64  `json:"-"`
+}
+
+// Actions of pipeline entity versions.
+const (
+	VersionActionCreate = "create"
+	VersionActionUpdate = "update"
+	VersionActionDelete = "delete"
+)
+
+type ChannelRuleVersionsCmd struct {
+	Pattern string `json:"pattern"`
+}
+
+// ChannelRuleVersion is a channel rule state after a change.
+type ChannelRuleVersion struct {
+	Pattern  string              `json:"pattern"`
+	Settings ChannelRuleSettings `json:"settings"`
+	Version  int64               `json:"version"`
+	Action   string              `json:"action"`
+	Created  time.Time           `json:"created"`
+	// CreatedBy is the user who made the change.
+	CreatedBy int64 `json:"createdBy"`
+}
+
+type WriteConfigVersionsCmd struct {
+	UID string `json:"uid"`
+}
+
+// WriteConfigVersion is a write config state after a change. Secure settings
+// are not kept in versions.
+type WriteConfigVersion struct {
+	UID       string        `json:"uid"`
+	Settings  WriteSettings `json:"settings"`
+	Version   int64         `json:"version"`
+	Action    string        `json:"action"`
+	Created   time.Time     `json:"created"`
+	CreatedBy int64         `json:"createdBy"`
 }
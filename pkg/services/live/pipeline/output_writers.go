@@ -0,0 +1,44 @@
+package pipeline
+
+import (
+	"io"
+	"sync"
+)
+
+// outputWriters keeps writers of outputs holding connections or background
+// goroutines, so that they are shared by channel rules which are rebuilt
+// periodically.
+type outputWriters struct {
+	mu      sync.Mutex
+	writers map[string]outputWriter
+}
+
+type outputWriter struct {
+	fingerprint string
+	writer      io.Closer
+}
+
+// get returns the writer for key, replacing it when the fingerprint of its
+// settings changed.
+func (w *outputWriters) get(key string, fingerprint string, create func() io.Closer) io.Closer {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.writers == nil {
+		w.writers = map[string]outputWriter{}
+	}
+	existing, ok := w.writers[key]
+	if ok && existing.fingerprint == fingerprint {
+		return existing.writer
+	}
+	if ok {
+		// Closing flushes buffered messages, so not blocking rule building.
+		go func() {
+			if err := existing.writer.Close(); err != nil {
+				logger.Error("Error closing output writer", "error", err)
+			}
+		}()
+	}
+	writer := create()
+	w.writers[key] = outputWriter{fingerprint: fingerprint, writer: writer}
+	return writer
+}
@@ -161,6 +161,9 @@ type LiveChannelRule struct {
 	// can optionally return a slice of ChannelFrame to pass the control to a rule defined
 	// by ChannelFrame.Channel.
 	FrameOutputters []FrameOutputter
+	// Backfill if set describes a datasource query returning the history of a channel
+	// for subscribers requesting it.
+	Backfill *BackfillConfig
 }
 
 // Label ...
@@ -1,5 +1,7 @@
 package pipeline
 
+import "github.com/grafana/grafana-plugin-sdk-go/data"
+
 type EntityInfo struct {
 	Type        string      `json:"type"`
 	Description string      `json:"description"`
@@ -48,6 +50,31 @@ var FrameOutputsRegistry = []EntityInfo{
 		Type:        FrameOutputTypeLoki,
 		Description: "output frame as JSON to Loki",
 	},
+	{
+		Type:        FrameOutputTypeAlert,
+		Description: "send alerts to the organization Alertmanager",
+		Example: AlertOutputConfig{
+			FieldName: "value",
+			AlertName: "HighValue",
+		},
+	},
+	{
+		Type:        FrameOutputTypeKafka,
+		Description: "output frame as JSON to Kafka topic",
+		Example: KafkaOutputConfig{
+			UID:   "kafka",
+			Topic: "${scope}.${namespace}",
+			Key:   "${path}",
+		},
+	},
+	{
+		Type:        FrameOutputTypeWebhook,
+		Description: "output frame as JSON to HTTP webhook in batches",
+		Example: WebhookOutputConfig{
+			UID:       "webhook",
+			BatchSize: 100,
+		},
+	},
 }
 
 var ConvertersRegistry = []EntityInfo{
@@ -70,6 +97,27 @@ var ConvertersRegistry = []EntityInfo{
 		Type:        ConverterTypeJsonFrame,
 		Description: "JSON-encoded Grafana data frame",
 	},
+	{
+		Type:        ConverterTypePrometheusText,
+		Description: "accept Prometheus text exposition or OpenMetrics format",
+		Example: PrometheusTextConverterConfig{
+			FrameFormat: "labels_column",
+		},
+	},
+	{
+		Type:        ConverterTypePrometheusRemoteWrite,
+		Description: "accept Prometheus remote write requests",
+		Example: PrometheusRemoteWriteConverterConfig{
+			FrameFormat: "labels_column",
+		},
+	},
+	{
+		Type:        ConverterTypeProtobuf,
+		Description: "protobuf messages described by a FileDescriptorSet",
+		Example: ProtobufConverterConfig{
+			MessageType: "sensors.Reading",
+		},
+	},
 }
 
 var FrameProcessorsRegistry = []EntityInfo{
@@ -83,6 +131,32 @@ var FrameProcessorsRegistry = []EntityInfo{
 		Description: "list the fields that should be removed",
 		Example:     DropFieldsFrameProcessorConfig{},
 	},
+	{
+		Type:        FrameProcessorTypeAggregate,
+		Description: "aggregate fields over time windows",
+		Example: AggregateFrameProcessorConfig{
+			IntervalMilliseconds: 10000,
+			Fields: []AggregateField{
+				{FieldName: "value", Reducers: []AggregateReducer{AggregateReducerMean, AggregateReducerMax}},
+			},
+		},
+	},
+	{
+		Type:        FrameProcessorTypeDerivative,
+		Description: "add per-second rate of change of fields",
+		Example: DerivativeFrameProcessorConfig{
+			FieldNames: []string{"value"},
+		},
+	},
+	{
+		Type:        FrameProcessorTypeCalculatedField,
+		Description: "add a field calculated with a JavaScript expression",
+		Example: CalculatedFieldFrameProcessorConfig{
+			FieldName:  "total",
+			Type:       data.FieldTypeNullableFloat64,
+			Expression: "x.read + x.write",
+		},
+	},
 }
 
 var DataOutputsRegistry = []EntityInfo{
@@ -98,4 +172,19 @@ var DataOutputsRegistry = []EntityInfo{
 		Type:        DataOutputTypeLoki,
 		Description: "output data to Loki as logs",
 	},
+	{
+		Type:        DataOutputTypeKafka,
+		Description: "output data to Kafka topic",
+		Example: KafkaOutputConfig{
+			UID:   "kafka",
+			Topic: "${scope}.${namespace}",
+		},
+	},
+	{
+		Type:        DataOutputTypeWebhook,
+		Description: "output data to HTTP webhook in batches",
+		Example: WebhookOutputConfig{
+			UID: "webhook",
+		},
+	},
 }
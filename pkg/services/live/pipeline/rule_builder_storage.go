@@ -3,6 +3,7 @@ package pipeline
 import (
 	"context"
 	"fmt"
+	"io"
 
 	"github.com/centrifugal/centrifuge"
 	"github.com/grafana/grafana/pkg/services/live/managedstream"
@@ -16,6 +17,9 @@ type StorageRuleBuilder struct {
 	Storage              Storage
 	ChannelHandlerGetter ChannelHandlerGetter
 	SecretsService       secrets.Service
+	AlertSender          AlertSender
+
+	writers outputWriters
 }
 
 func (f *StorageRuleBuilder) extractSubscriber(config *SubscriberConfig) (Subscriber, error) {
@@ -73,6 +77,21 @@ func (f *StorageRuleBuilder) extractConverter(config *ConverterConfig) (Converte
 			return nil, missingConfiguration
 		}
 		return NewAutoInfluxConverter(*config.AutoInfluxConverterConfig), nil
+	case ConverterTypePrometheusText:
+		if config.PrometheusTextConverterConfig == nil {
+			config.PrometheusTextConverterConfig = &PrometheusTextConverterConfig{}
+		}
+		return NewPrometheusTextConverter(*config.PrometheusTextConverterConfig), nil
+	case ConverterTypePrometheusRemoteWrite:
+		if config.PrometheusRemoteWriteConverterConfig == nil {
+			config.PrometheusRemoteWriteConverterConfig = &PrometheusRemoteWriteConverterConfig{}
+		}
+		return NewPrometheusRemoteWriteConverter(*config.PrometheusRemoteWriteConverterConfig), nil
+	case ConverterTypeProtobuf:
+		if config.ProtobufConverterConfig == nil {
+			return nil, missingConfiguration
+		}
+		return NewProtobufConverter(*config.ProtobufConverterConfig)
 	default:
 		return nil, fmt.Errorf("unknown converter type: %s", config.Type)
 	}
@@ -94,6 +113,21 @@ func (f *StorageRuleBuilder) extractFrameProcessor(config *FrameProcessorConfig)
 			return nil, missingConfiguration
 		}
 		return NewKeepFieldsFrameProcessor(*config.KeepFieldsProcessorConfig), nil
+	case FrameProcessorTypeAggregate:
+		if config.AggregateProcessorConfig == nil {
+			return nil, missingConfiguration
+		}
+		return NewAggregateFrameProcessor(*config.AggregateProcessorConfig)
+	case FrameProcessorTypeDerivative:
+		if config.DerivativeProcessorConfig == nil {
+			return nil, missingConfiguration
+		}
+		return NewDerivativeFrameProcessor(*config.DerivativeProcessorConfig), nil
+	case FrameProcessorTypeCalculatedField:
+		if config.CalculatedFieldProcessorConfig == nil {
+			return nil, missingConfiguration
+		}
+		return NewCalculatedFieldFrameProcessor(*config.CalculatedFieldProcessorConfig)
 	case FrameProcessorTypeMultiple:
 		if config.MultipleProcessorConfig == nil {
 			return nil, missingConfiguration
@@ -253,6 +287,29 @@ func (f *StorageRuleBuilder) extractFrameOutputter(config *FrameOutputterConfig,
 			return nil, missingConfiguration
 		}
 		return NewChangeLogFrameOutput(f.FrameStorage, *config.ChangeLogOutputConfig), nil
+	case FrameOutputTypeAlert:
+		if config.AlertOutputConfig == nil {
+			return nil, missingConfiguration
+		}
+		return NewAlertOutput(f.AlertSender, *config.AlertOutputConfig), nil
+	case FrameOutputTypeKafka:
+		if config.KafkaOutputConfig == nil {
+			return nil, missingConfiguration
+		}
+		producer, err := f.kafkaProducer(config.KafkaOutputConfig.UID, writeConfigs)
+		if err != nil {
+			return nil, err
+		}
+		return NewKafkaFrameOutput(producer, *config.KafkaOutputConfig), nil
+	case FrameOutputTypeWebhook:
+		if config.WebhookOutputConfig == nil {
+			return nil, missingConfiguration
+		}
+		writer, err := f.webhookWriter(*config.WebhookOutputConfig, writeConfigs)
+		if err != nil {
+			return nil, err
+		}
+		return NewWebhookFrameOutput(writer), nil
 	default:
 		return nil, fmt.Errorf("unknown output type: %s", config.Type)
 	}
@@ -285,6 +342,24 @@ func (f *StorageRuleBuilder) extractDataOutputter(config *DataOutputterConfig, w
 			writeConfig.Settings.Endpoint,
 			basicAuth,
 		), nil
+	case DataOutputTypeKafka:
+		if config.KafkaOutputConfig == nil {
+			return nil, missingConfiguration
+		}
+		producer, err := f.kafkaProducer(config.KafkaOutputConfig.UID, writeConfigs)
+		if err != nil {
+			return nil, err
+		}
+		return NewKafkaDataOutput(producer, *config.KafkaOutputConfig), nil
+	case DataOutputTypeWebhook:
+		if config.WebhookOutputConfig == nil {
+			return nil, missingConfiguration
+		}
+		writer, err := f.webhookWriter(*config.WebhookOutputConfig, writeConfigs)
+		if err != nil {
+			return nil, err
+		}
+		return NewWebhookDataOutput(writer), nil
 	case DataOutputTypeBuiltin:
 		return NewBuiltinDataOutput(f.ChannelHandlerGetter), nil
 	case DataOutputTypeLocalSubscribers:
@@ -294,6 +369,44 @@ func (f *StorageRuleBuilder) extractDataOutputter(config *DataOutputterConfig, w
 	}
 }
 
+// kafkaProducer returns a producer shared by all Kafka outputs using the
+// write config, so that connections survive rebuilding of rules.
+func (f *StorageRuleBuilder) kafkaProducer(uid string, writeConfigs []WriteConfig) (KafkaProducer, error) {
+	writeConfig, ok := f.getWriteConfig(uid, writeConfigs)
+	if !ok {
+		return nil, fmt.Errorf("unknown kafka write config uid: %s", uid)
+	}
+	basicAuth, err := f.constructBasicAuth(writeConfig)
+	if err != nil {
+		return nil, fmt.Errorf("error constructing basicAuth: %w", err)
+	}
+	key := fmt.Sprintf("kafka/%d/%s", writeConfig.OrgId, uid)
+	fingerprint := fmt.Sprintf("%s/%v", writeConfig.Settings.Endpoint, basicAuth)
+	writer := f.writers.get(key, fingerprint, func() io.Closer {
+		return newKafkaProducer(writeConfig.Settings.Endpoint, basicAuth)
+	})
+	return writer.(KafkaProducer), nil
+}
+
+// webhookWriter returns a writer shared by webhook outputs with the same
+// configuration, so that batches survive rebuilding of rules.
+func (f *StorageRuleBuilder) webhookWriter(config WebhookOutputConfig, writeConfigs []WriteConfig) (*webhookWriter, error) {
+	writeConfig, ok := f.getWriteConfig(config.UID, writeConfigs)
+	if !ok {
+		return nil, fmt.Errorf("unknown webhook write config uid: %s", config.UID)
+	}
+	basicAuth, err := f.constructBasicAuth(writeConfig)
+	if err != nil {
+		return nil, fmt.Errorf("error constructing basicAuth: %w", err)
+	}
+	key := fmt.Sprintf("webhook/%d/%+v", writeConfig.OrgId, config)
+	fingerprint := fmt.Sprintf("%s/%v", writeConfig.Settings.Endpoint, basicAuth)
+	writer := f.writers.get(key, fingerprint, func() io.Closer {
+		return newWebhookWriter(writeConfig.Settings.Endpoint, basicAuth, config)
+	})
+	return writer.(*webhookWriter), nil
+}
+
 func (f *StorageRuleBuilder) getWriteConfig(uid string, writeConfigs []WriteConfig) (WriteConfig, bool) {
 	for _, rwb := range writeConfigs {
 		if rwb.UID == uid {
@@ -377,6 +490,13 @@ func (f *StorageRuleBuilder) BuildRules(ctx context.Context, orgID int64) ([]*Li
 		}
 		rule.Subscribers = subscribers
 
+		if backfill := ruleConfig.Settings.Backfill; backfill != nil {
+			if backfill.DatasourceUID == "" {
+				return nil, fmt.Errorf("missing backfill datasource uid for %s", rule.Pattern)
+			}
+			rule.Backfill = backfill
+		}
+
 		rules = append(rules, rule)
 	}
 
@@ -1,6 +1,19 @@
 package pipeline
 
-import "context"
+import (
+	"context"
+	"errors"
+)
+
+var (
+	ErrChannelRuleNotFound = errors.New("channel rule not found")
+	ErrWriteConfigNotFound = errors.New("write config not found")
+	// ErrVersionConflict is returned when an entity was changed since the
+	// version an update or delete is based on.
+	ErrVersionConflict = errors.New("version conflict")
+	// ErrVersionsNotSupported is returned by storages not keeping versions.
+	ErrVersionsNotSupported = errors.New("storage does not keep versions")
+)
 
 // Storage describes all methods to manage Live pipeline persistent data.
 type Storage interface {
@@ -9,8 +22,10 @@ type Storage interface {
 	CreateWriteConfig(_ context.Context, orgID int64, cmd WriteConfigCreateCmd) (WriteConfig, error)
 	UpdateWriteConfig(_ context.Context, orgID int64, cmd WriteConfigUpdateCmd) (WriteConfig, error)
 	DeleteWriteConfig(_ context.Context, orgID int64, cmd WriteConfigDeleteCmd) error
+	ListWriteConfigVersions(_ context.Context, orgID int64, cmd WriteConfigVersionsCmd) ([]WriteConfigVersion, error)
 	ListChannelRules(_ context.Context, orgID int64) ([]ChannelRule, error)
 	CreateChannelRule(_ context.Context, orgID int64, cmd ChannelRuleCreateCmd) (ChannelRule, error)
 	UpdateChannelRule(_ context.Context, orgID int64, cmd ChannelRuleUpdateCmd) (ChannelRule, error)
 	DeleteChannelRule(_ context.Context, orgID int64, cmd ChannelRuleDeleteCmd) error
+	ListChannelRuleVersions(_ context.Context, orgID int64, cmd ChannelRuleVersionsCmd) ([]ChannelRuleVersion, error)
 }
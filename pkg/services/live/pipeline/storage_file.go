@@ -115,7 +115,11 @@ func (f *FileStorage) UpdateWriteConfig(ctx context.Context, orgID int64, cmd Wr
 	if index > -1 {
 		writeConfigs.Configs[index] = backend
 	} else {
-		return f.CreateWriteConfig(ctx, orgID, WriteConfigCreateCmd(cmd))
+		return f.CreateWriteConfig(ctx, orgID, WriteConfigCreateCmd{
+			UID:            cmd.UID,
+			Settings:       cmd.Settings,
+			SecureSettings: cmd.SecureSettings,
+		})
 	}
 
 	err = f.saveWriteConfigs(orgID, writeConfigs)
@@ -139,7 +143,7 @@ func (f *FileStorage) DeleteWriteConfig(_ context.Context, orgID int64, cmd Writ
 	if index > -1 {
 		writeConfigs.Configs = removeWriteConfigByIndex(writeConfigs.Configs, index)
 	} else {
-		return fmt.Errorf("write config not found")
+		return ErrWriteConfigNotFound
 	}
 
 	return f.saveWriteConfigs(orgID, writeConfigs)
@@ -221,7 +225,10 @@ func (f *FileStorage) UpdateChannelRule(ctx context.Context, orgID int64, cmd Ch
 	if index > -1 {
 		channelRules.Rules[index] = rule
 	} else {
-		return f.CreateChannelRule(ctx, orgID, ChannelRuleCreateCmd(cmd))
+		return f.CreateChannelRule(ctx, orgID, ChannelRuleCreateCmd{
+			Pattern:  cmd.Pattern,
+			Settings: cmd.Settings,
+		})
 	}
 
 	err = f.saveChannelRules(orgID, channelRules)
@@ -253,7 +260,7 @@ func (f *FileStorage) readRules() (ChannelRules, error) {
 }
 
 func (f *FileStorage) saveChannelRules(orgID int64, rules ChannelRules) error {
-	ok, reason := checkRulesValid(orgID, rules.Rules)
+	ok, reason := CheckRulesValid(orgID, rules.Rules)
 	if !ok {
 		return errors.New(reason)
 	}
@@ -291,12 +298,20 @@ func (f *FileStorage) DeleteChannelRule(_ context.Context, orgID int64, cmd Chan
 	if index > -1 {
 		channelRules.Rules = removeChannelRuleByIndex(channelRules.Rules, index)
 	} else {
-		return fmt.Errorf("rule not found")
+		return ErrChannelRuleNotFound
 	}
 
 	return f.saveChannelRules(orgID, channelRules)
 }
 
+func (f *FileStorage) ListWriteConfigVersions(_ context.Context, _ int64, _ WriteConfigVersionsCmd) ([]WriteConfigVersion, error) {
+	return nil, ErrVersionsNotSupported
+}
+
+func (f *FileStorage) ListChannelRuleVersions(_ context.Context, _ int64, _ ChannelRuleVersionsCmd) ([]ChannelRuleVersion, error) {
+	return nil, ErrVersionsNotSupported
+}
+
 func removeWriteConfigByIndex(s []WriteConfig, index int) []WriteConfig {
 	return append(s[:index], s[index+1:]...)
 }
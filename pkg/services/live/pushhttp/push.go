@@ -8,8 +8,10 @@ import (
 
 	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
 	"github.com/grafana/grafana/pkg/services/live"
 	"github.com/grafana/grafana/pkg/services/live/convert"
+	"github.com/grafana/grafana/pkg/services/live/managedstream"
 	"github.com/grafana/grafana/pkg/services/live/pushurl"
 	"github.com/grafana/grafana/pkg/setting"
 
@@ -83,12 +85,25 @@ func (g *Gateway) Handle(ctx *models.ReqContext) {
 		return
 	}
 
+	// Everyone can push unless restricted by access control.
+	for _, mf := range metricFrames {
+		channel := liveDto.Channel{Scope: liveDto.ScopeStream, Namespace: streamID, Path: mf.Key()}.String()
+		if !g.canPublish(ctx, channel) {
+			return
+		}
+	}
+
 	// TODO -- make sure all packets are combined together!
 	// interval = "1s" vs flush_interval = "5s"
 
 	for _, mf := range metricFrames {
 		err := stream.Push(ctx.Req.Context(), mf.Key(), mf.Frame())
 		if err != nil {
+			if errors.Is(err, managedstream.ErrRateLimited) {
+				logger.Debug("Frame rejected by rate limit", "error", err, "streamId", streamID)
+				ctx.Resp.WriteHeader(http.StatusTooManyRequests)
+				return
+			}
 			logger.Error("Error pushing frame", "error", err, "data", string(body))
 			ctx.Resp.WriteHeader(http.StatusInternalServerError)
 			return
@@ -98,6 +113,9 @@ func (g *Gateway) Handle(ctx *models.ReqContext) {
 
 func (g *Gateway) HandlePipelinePush(ctx *models.ReqContext) {
 	channelID := web.Params(ctx.Req)["*"]
+	if !g.canPublish(ctx, channelID) {
+		return
+	}
 
 	body, err := io.ReadAll(ctx.Req.Body)
 	if err != nil {
@@ -116,6 +134,8 @@ func (g *Gateway) HandlePipelinePush(ctx *models.ReqContext) {
 		logger.Error("Pipeline input processing error", "error", err, "body", string(body))
 		if errors.Is(err, liveDto.ErrInvalidChannelID) {
 			ctx.Resp.WriteHeader(http.StatusBadRequest)
+		} else if errors.Is(err, managedstream.ErrRateLimited) {
+			ctx.Resp.WriteHeader(http.StatusTooManyRequests)
 		} else {
 			ctx.Resp.WriteHeader(http.StatusInternalServerError)
 		}
@@ -127,3 +147,19 @@ func (g *Gateway) HandlePipelinePush(ctx *models.ReqContext) {
 		return
 	}
 }
+
+// canPublish checks publish access to a channel with fine-grained access control
+// and responds with an error when access is denied.
+func (g *Gateway) canPublish(ctx *models.ReqContext, channel string) bool {
+	ok, err := g.GrafanaLive.HasChannelAccess(ctx.Req.Context(), ctx.SignedInUser, accesscontrol.ActionLiveChannelsPublish, channel, func() bool { return true })
+	if err != nil {
+		logger.Error("Error checking push permissions", "error", err, "channel", channel)
+		ctx.Resp.WriteHeader(http.StatusInternalServerError)
+		return false
+	}
+	if !ok {
+		ctx.Resp.WriteHeader(http.StatusForbidden)
+		return false
+	}
+	return true
+}
@@ -0,0 +1,107 @@
+package live
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/live/pipeline"
+)
+
+// registerRoles declares fixed roles keeping the default Live permissions:
+// everyone in an organization can subscribe to channels, editors can push data
+// into stream channels over HTTP, e.g. from Telegraf, and admins can publish
+// into all channels.
+func registerRoles(ac accesscontrol.AccessControl) error {
+	if ac == nil {
+		return nil
+	}
+	subscriber := accesscontrol.RoleRegistration{
+		Role: accesscontrol.RoleDTO{
+			Version:     1,
+			Name:        "fixed:live.channels:subscriber",
+			DisplayName: "Live channels subscriber",
+			Description: "Subscribe to all Live channels.",
+			Group:       "Live",
+			Permissions: []accesscontrol.Permission{
+				{
+					Action: accesscontrol.ActionLiveChannelsSubscribe,
+					Scope:  accesscontrol.ScopeLiveChannelsAll,
+				},
+			},
+		},
+		Grants: []string{string(models.ROLE_VIEWER)},
+	}
+	streamPublisher := accesscontrol.RoleRegistration{
+		Role: accesscontrol.RoleDTO{
+			Version:     1,
+			Name:        "fixed:live.channels.stream:publisher",
+			DisplayName: "Live stream channels publisher",
+			Description: "Publish and push data into all stream Live channels.",
+			Group:       "Live",
+			Permissions: []accesscontrol.Permission{
+				{
+					Action: accesscontrol.ActionLiveChannelsPublish,
+					Scope:  accesscontrol.ScopeLiveChannel("stream/*"),
+				},
+			},
+		},
+		Grants: []string{string(models.ROLE_EDITOR)},
+	}
+	publisher := accesscontrol.RoleRegistration{
+		Role: accesscontrol.RoleDTO{
+			Version:     1,
+			Name:        "fixed:live.channels:publisher",
+			DisplayName: "Live channels publisher",
+			Description: "Publish and push data into all Live channels.",
+			Group:       "Live",
+			Permissions: []accesscontrol.Permission{
+				{
+					Action: accesscontrol.ActionLiveChannelsPublish,
+					Scope:  accesscontrol.ScopeLiveChannelsAll,
+				},
+			},
+		},
+		Grants: []string{string(models.ROLE_ADMIN)},
+	}
+	return ac.DeclareFixedRoles(subscriber, streamPublisher, publisher)
+}
+
+// HasChannelAccess evaluates access to a channel for the live:channels:subscribe
+// or live:channels:publish action. The fallback is used when fine-grained access
+// control is disabled.
+func (g *GrafanaLive) HasChannelAccess(ctx context.Context, user *models.SignedInUser, action string, channel string, fallback func() bool) (bool, error) {
+	if g.AccessControl == nil || g.AccessControl.IsDisabled() {
+		return fallback(), nil
+	}
+	return g.AccessControl.Evaluate(ctx, user, accesscontrol.EvalPermission(action, accesscontrol.ScopeLiveChannel(channel)))
+}
+
+// canPublishRuleChannel checks publish access to a channel with a pipeline rule.
+// Without access control admin role is required, unless the rule has its own
+// publish auth checked by the caller.
+func (g *GrafanaLive) canPublishRuleChannel(ctx context.Context, user *models.SignedInUser, rule *pipeline.LiveChannelRule, channel string) (bool, error) {
+	return g.HasChannelAccess(ctx, user, accesscontrol.ActionLiveChannelsPublish, channel, func() bool {
+		return rule.PublishAuth != nil || user.HasRole(models.ROLE_ADMIN)
+	})
+}
+
+// canPushWebsocket checks publish access before opening a push connection and
+// responds with an error when access is denied. Without access control admin
+// role is required.
+func (g *GrafanaLive) canPushWebsocket(ctx *models.ReqContext, channel string) bool {
+	ok, err := g.HasChannelAccess(ctx.Req.Context(), ctx.SignedInUser, accesscontrol.ActionLiveChannelsPublish, channel, func() bool {
+		return ctx.SignedInUser.HasRole(models.ROLE_ADMIN)
+	})
+	if err != nil {
+		logger.Error("Error checking push permissions", "user", ctx.SignedInUser.UserId, "channel", channel, "error", err)
+		ctx.Resp.WriteHeader(http.StatusInternalServerError)
+		return false
+	}
+	if !ok {
+		ctx.Resp.WriteHeader(http.StatusForbidden)
+		return false
+	}
+	return true
+}
@@ -0,0 +1,76 @@
+package live
+
+import (
+	"context"
+	"testing"
+
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	accesscontrolmock "github.com/grafana/grafana/pkg/services/accesscontrol/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHasChannelAccess(t *testing.T) {
+	user := &models.SignedInUser{OrgId: 1, OrgRole: models.ROLE_VIEWER}
+	denyFallback := func() bool { return false }
+
+	t.Run("fallback without access control", func(t *testing.T) {
+		g := &GrafanaLive{}
+		ok, err := g.HasChannelAccess(context.Background(), user, accesscontrol.ActionLiveChannelsPublish, "stream/iot/cpu", denyFallback)
+		require.NoError(t, err)
+		require.False(t, ok)
+
+		g.AccessControl = accesscontrolmock.New().WithDisabled()
+		ok, err = g.HasChannelAccess(context.Background(), user, accesscontrol.ActionLiveChannelsPublish, "stream/iot/cpu", func() bool { return true })
+		require.NoError(t, err)
+		require.True(t, ok)
+	})
+
+	t.Run("channel patterns", func(t *testing.T) {
+		g := &GrafanaLive{AccessControl: accesscontrolmock.New().WithPermissions([]*accesscontrol.Permission{
+			{Action: accesscontrol.ActionLiveChannelsSubscribe, Scope: accesscontrol.ScopeLiveChannelsAll},
+			{Action: accesscontrol.ActionLiveChannelsPublish, Scope: accesscontrol.ScopeLiveChannel("stream/iot/*")},
+			{Action: accesscontrol.ActionLiveChannelsPublish, Scope: accesscontrol.ScopeLiveChannel("stream/app/events")},
+		})}
+		tests := []struct {
+			action  string
+			channel string
+			allowed bool
+		}{
+			{accesscontrol.ActionLiveChannelsSubscribe, "grafana/dashboard/uid/abc", true},
+			{accesscontrol.ActionLiveChannelsPublish, "stream/iot/cpu", true},
+			{accesscontrol.ActionLiveChannelsPublish, "stream/iot/*", true},
+			{accesscontrol.ActionLiveChannelsPublish, "stream/iot", false},
+			{accesscontrol.ActionLiveChannelsPublish, "stream/iota/cpu", false},
+			{accesscontrol.ActionLiveChannelsPublish, "stream/app/events", true},
+			{accesscontrol.ActionLiveChannelsPublish, "stream/app/*", false},
+			{accesscontrol.ActionLiveChannelsPublish, "stream/app/events2", false},
+		}
+		for _, tt := range tests {
+			ok, err := g.HasChannelAccess(context.Background(), user, tt.action, tt.channel, denyFallback)
+			require.NoError(t, err)
+			require.Equal(t, tt.allowed, ok, tt.action+" "+tt.channel)
+		}
+	})
+}
+
+func TestRegisterRoles(t *testing.T) {
+	ac := accesscontrolmock.New()
+	var registrations []accesscontrol.RoleRegistration
+	ac.DeclareFixedRolesFunc = func(r ...accesscontrol.RoleRegistration) error {
+		registrations = append(registrations, r...)
+		return nil
+	}
+	require.NoError(t, registerRoles(ac))
+	require.Len(t, registrations, 3)
+	require.Equal(t, []string{"Viewer"}, registrations[0].Grants)
+	require.Equal(t, accesscontrol.ActionLiveChannelsSubscribe, registrations[0].Role.Permissions[0].Action)
+	require.Equal(t, []string{"Editor"}, registrations[1].Grants)
+	require.Equal(t, accesscontrol.ActionLiveChannelsPublish, registrations[1].Role.Permissions[0].Action)
+	require.Equal(t, "channels:stream/*", registrations[1].Role.Permissions[0].Scope)
+	require.Equal(t, []string{"Admin"}, registrations[2].Grants)
+	require.Equal(t, accesscontrol.ActionLiveChannelsPublish, registrations[2].Role.Permissions[0].Action)
+	require.Equal(t, accesscontrol.ScopeLiveChannelsAll, registrations[2].Role.Permissions[0].Scope)
+
+	require.NoError(t, registerRoles(nil))
+}
@@ -0,0 +1,266 @@
+package prometheus
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gogo/protobuf/proto"
+	"github.com/golang/snappy"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/grafana/grafana/pkg/services/live/telemetry"
+	"github.com/prometheus/prometheus/pkg/labels"
+	"github.com/prometheus/prometheus/pkg/textparse"
+	"github.com/prometheus/prometheus/prompb"
+)
+
+const (
+	// FrameFormatWide puts every series of a metric into a separate field with
+	// series labels, rows are aligned by timestamp.
+	FrameFormatWide = "wide"
+	// FrameFormatLabelsColumn puts every sample into a separate row with series
+	// labels in the labels column.
+	FrameFormatLabelsColumn = "labels_column"
+)
+
+var ErrUnsupportedFrameFormat = errors.New("unsupported frame format")
+
+// TextConverter converts metrics in Prometheus text exposition or OpenMetrics
+// format to frames, one frame per metric name.
+type TextConverter struct {
+	openMetrics bool
+	frameFormat string
+	now         func() time.Time
+}
+
+// NewTextConverter creates a new TextConverter.
+func NewTextConverter(openMetrics bool, frameFormat string) *TextConverter {
+	return &TextConverter{openMetrics: openMetrics, frameFormat: frameFormat, now: time.Now}
+}
+
+func (c *TextConverter) Convert(body []byte) ([]telemetry.FrameWrapper, error) {
+	var parser textparse.Parser
+	if c.openMetrics {
+		parser = textparse.NewOpenMetricsParser(body)
+	} else {
+		parser = textparse.NewPromParser(body)
+	}
+
+	// Samples without timestamp are scraped at the same time.
+	defaultTimestamp := c.now().UnixNano() / int64(time.Millisecond)
+	m := newMetrics()
+	for {
+		entry, err := parser.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("error parsing metrics: %w", err)
+		}
+		if entry != textparse.EntrySeries {
+			continue
+		}
+		_, ts, value := parser.Series()
+		timestamp := defaultTimestamp
+		if ts != nil {
+			timestamp = *ts
+		}
+		var lbls labels.Labels
+		parser.Metric(&lbls)
+		m.add(lbls, timestamp, value)
+	}
+	return m.frames(c.frameFormat)
+}
+
+// RemoteWriteConverter converts Prometheus remote write requests, snappy
+// compressed protobuf payloads, to frames, one frame per metric name.
+type RemoteWriteConverter struct {
+	frameFormat string
+}
+
+// NewRemoteWriteConverter creates a new RemoteWriteConverter.
+func NewRemoteWriteConverter(frameFormat string) *RemoteWriteConverter {
+	return &RemoteWriteConverter{frameFormat: frameFormat}
+}
+
+func (c *RemoteWriteConverter) Convert(body []byte) ([]telemetry.FrameWrapper, error) {
+	decoded, err := snappy.Decode(nil, body)
+	if err != nil {
+		return nil, fmt.Errorf("error decompressing remote write request: %w", err)
+	}
+	var req prompb.WriteRequest
+	if err := proto.Unmarshal(decoded, &req); err != nil {
+		return nil, fmt.Errorf("error decoding remote write request: %w", err)
+	}
+
+	m := newMetrics()
+	for _, ts := range req.Timeseries {
+		lbls := make(labels.Labels, 0, len(ts.Labels))
+		for _, l := range ts.Labels {
+			lbls = append(lbls, labels.Label{Name: l.Name, Value: l.Value})
+		}
+		sort.Sort(lbls)
+		for _, s := range ts.Samples {
+			m.add(lbls, s.Timestamp, s.Value)
+		}
+	}
+	return m.frames(c.frameFormat)
+}
+
+type sample struct {
+	timestamp int64
+	value     float64
+}
+
+type series struct {
+	labels  data.Labels
+	samples []sample
+}
+
+type metric struct {
+	name   string
+	series []*series
+	index  map[string]int
+}
+
+// metrics collects samples by metric name and series, keeping the order of the
+// input.
+type metrics struct {
+	order  []string
+	byName map[string]*metric
+}
+
+func newMetrics() *metrics {
+	return &metrics{byName: map[string]*metric{}}
+}
+
+func (m *metrics) add(lbls labels.Labels, timestamp int64, value float64) {
+	name := lbls.Get(labels.MetricName)
+	if name == "" {
+		return
+	}
+	mt, ok := m.byName[name]
+	if !ok {
+		mt = &metric{name: name, index: map[string]int{}}
+		m.byName[name] = mt
+		m.order = append(m.order, name)
+	}
+	key := lbls.String()
+	i, ok := mt.index[key]
+	if !ok {
+		seriesLabels := data.Labels{}
+		for _, l := range lbls {
+			if l.Name != labels.MetricName {
+				seriesLabels[l.Name] = l.Value
+			}
+		}
+		mt.series = append(mt.series, &series{labels: seriesLabels})
+		i = len(mt.series) - 1
+		mt.index[key] = i
+	}
+	mt.series[i].samples = append(mt.series[i].samples, sample{timestamp: timestamp, value: value})
+}
+
+func (m *metrics) frames(frameFormat string) ([]telemetry.FrameWrapper, error) {
+	frameWrappers := make([]telemetry.FrameWrapper, 0, len(m.order))
+	for _, name := range m.order {
+		mt := m.byName[name]
+		var frame *data.Frame
+		switch frameFormat {
+		case FrameFormatWide:
+			frame = mt.wideFrame()
+		case FrameFormatLabelsColumn, "":
+			frame = mt.labelsColumnFrame()
+		default:
+			return nil, ErrUnsupportedFrameFormat
+		}
+		frameWrappers = append(frameWrappers, &metricFrame{key: frameKey(name), frame: frame})
+	}
+	return frameWrappers, nil
+}
+
+func (mt *metric) wideFrame() *data.Frame {
+	var timestamps []int64
+	seen := map[int64]struct{}{}
+	for _, s := range mt.series {
+		for _, smp := range s.samples {
+			if _, ok := seen[smp.timestamp]; !ok {
+				seen[smp.timestamp] = struct{}{}
+				timestamps = append(timestamps, smp.timestamp)
+			}
+		}
+	}
+	sort.Slice(timestamps, func(i, j int) bool { return timestamps[i] < timestamps[j] })
+	rows := make(map[int64]int, len(timestamps))
+	timeField := data.NewFieldFromFieldType(data.FieldTypeTime, len(timestamps))
+	timeField.Name = "time"
+	for i, ts := range timestamps {
+		rows[ts] = i
+		timeField.Set(i, millisToTime(ts))
+	}
+
+	fields := []*data.Field{timeField}
+	for _, s := range mt.series {
+		field := data.NewFieldFromFieldType(data.FieldTypeNullableFloat64, len(timestamps))
+		field.Name = "value"
+		field.Labels = s.labels
+		for _, smp := range s.samples {
+			field.Set(rows[smp.timestamp], sampleValue(smp.value))
+		}
+		fields = append(fields, field)
+	}
+	return data.NewFrame(mt.name, fields...)
+}
+
+func (mt *metric) labelsColumnFrame() *data.Frame {
+	labelsField := data.NewField("labels", nil, []string{})
+	timeField := data.NewField("time", nil, []time.Time{})
+	valueField := data.NewField("value", nil, []*float64{})
+	for _, s := range mt.series {
+		lbls := s.labels.String()
+		for _, smp := range s.samples {
+			labelsField.Append(lbls)
+			timeField.Append(millisToTime(smp.timestamp))
+			valueField.Append(sampleValue(smp.value))
+		}
+	}
+	return data.NewFrame(mt.name, labelsField, timeField, valueField)
+}
+
+// sampleValue returns nil for NaN values, since those are not supported by
+// frame JSON encoding.
+func sampleValue(v float64) *float64 {
+	if math.IsNaN(v) {
+		return nil
+	}
+	return &v
+}
+
+func millisToTime(ms int64) time.Time {
+	return time.Unix(0, ms*int64(time.Millisecond))
+}
+
+// frameKey returns the metric name with symbols not allowed in channel paths
+// replaced.
+func frameKey(name string) string {
+	return strings.ReplaceAll(name, ":", "_")
+}
+
+type metricFrame struct {
+	key   string
+	frame *data.Frame
+}
+
+// Key returns a key which describes Frame metrics.
+func (f *metricFrame) Key() string {
+	return f.key
+}
+
+// Frame returns the metric data.Frame.
+func (f *metricFrame) Frame() *data.Frame {
+	return f.frame
+}
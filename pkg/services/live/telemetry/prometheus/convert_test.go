@@ -0,0 +1,130 @@
+package prometheus
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gogo/protobuf/proto"
+	"github.com/golang/snappy"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/prometheus/prometheus/prompb"
+	"github.com/stretchr/testify/require"
+)
+
+const textMetrics = `# HELP http_requests_total The total number of HTTP requests.
+# TYPE http_requests_total counter
+http_requests_total{method="post",code="200"} 1027 1395066363000
+http_requests_total{method="post",code="400"} 3 1395066363000
+# TYPE node:cpu:ratio gauge
+node:cpu:ratio 0.5
+`
+
+func TestTextConverter_LabelsColumn(t *testing.T) {
+	now := time.Unix(1600000000, 0)
+	c := NewTextConverter(false, FrameFormatLabelsColumn)
+	c.now = func() time.Time { return now }
+
+	frameWrappers, err := c.Convert([]byte(textMetrics))
+	require.NoError(t, err)
+	require.Len(t, frameWrappers, 2)
+
+	require.Equal(t, "http_requests_total", frameWrappers[0].Key())
+	frame := frameWrappers[0].Frame()
+	require.Equal(t, "http_requests_total", frame.Name)
+	require.Len(t, frame.Fields, 3)
+	require.Equal(t, 2, frame.Fields[0].Len())
+	require.Equal(t, "code=200, method=post", frame.Fields[0].At(0))
+	require.Equal(t, time.Unix(1395066363, 0), frame.Fields[1].At(0))
+	require.Equal(t, 1027.0, *frame.Fields[2].At(0).(*float64))
+	require.Equal(t, "code=400, method=post", frame.Fields[0].At(1))
+	require.Equal(t, 3.0, *frame.Fields[2].At(1).(*float64))
+
+	require.Equal(t, "node_cpu_ratio", frameWrappers[1].Key())
+	frame = frameWrappers[1].Frame()
+	require.Equal(t, "node:cpu:ratio", frame.Name)
+	require.Equal(t, now, frame.Fields[1].At(0))
+}
+
+func TestTextConverter_Wide(t *testing.T) {
+	c := NewTextConverter(false, FrameFormatWide)
+
+	frameWrappers, err := c.Convert([]byte(textMetrics))
+	require.NoError(t, err)
+	require.Len(t, frameWrappers, 2)
+
+	frame := frameWrappers[0].Frame()
+	require.Len(t, frame.Fields, 3)
+	require.Equal(t, 1, frame.Fields[0].Len())
+	require.Equal(t, data.Labels{"method": "post", "code": "200"}, frame.Fields[1].Labels)
+	require.Equal(t, 1027.0, *frame.Fields[1].At(0).(*float64))
+	require.Equal(t, data.Labels{"method": "post", "code": "400"}, frame.Fields[2].Labels)
+	require.Equal(t, 3.0, *frame.Fields[2].At(0).(*float64))
+}
+
+func TestTextConverter_OpenMetrics(t *testing.T) {
+	c := NewTextConverter(true, FrameFormatLabelsColumn)
+
+	frameWrappers, err := c.Convert([]byte(`# TYPE temperature gauge
+# UNIT temperature_celsius celsius
+temperature_celsius{room="a"} 21.5 1600000000.5
+# EOF
+`))
+	require.NoError(t, err)
+	require.Len(t, frameWrappers, 1)
+	frame := frameWrappers[0].Frame()
+	require.Equal(t, "room=a", frame.Fields[0].At(0))
+	require.Equal(t, time.Unix(1600000000, 500000000), frame.Fields[1].At(0))
+	require.Equal(t, 21.5, *frame.Fields[2].At(0).(*float64))
+
+	_, err = c.Convert([]byte(`temperature{room="a"} 21.5`))
+	require.Error(t, err, "OpenMetrics requires # EOF")
+}
+
+func TestTextConverter_UnsupportedFrameFormat(t *testing.T) {
+	_, err := NewTextConverter(false, "long").Convert([]byte(textMetrics))
+	require.ErrorIs(t, err, ErrUnsupportedFrameFormat)
+}
+
+func TestRemoteWriteConverter(t *testing.T) {
+	req := &prompb.WriteRequest{
+		Timeseries: []prompb.TimeSeries{
+			{
+				Labels: []prompb.Label{
+					{Name: "job", Value: "node"},
+					{Name: "__name__", Value: "up"},
+				},
+				Samples: []prompb.Sample{{Timestamp: 1000, Value: 1}, {Timestamp: 2000, Value: 0}},
+			},
+			{
+				Labels: []prompb.Label{
+					{Name: "__name__", Value: "up"},
+					{Name: "job", Value: "grafana"},
+				},
+				Samples: []prompb.Sample{{Timestamp: 2000, Value: 1}},
+			},
+		},
+	}
+	encoded, err := proto.Marshal(req)
+	require.NoError(t, err)
+	body := snappy.Encode(nil, encoded)
+
+	frameWrappers, err := NewRemoteWriteConverter(FrameFormatWide).Convert(body)
+	require.NoError(t, err)
+	require.Len(t, frameWrappers, 1)
+	require.Equal(t, "up", frameWrappers[0].Key())
+
+	frame := frameWrappers[0].Frame()
+	require.Len(t, frame.Fields, 3)
+	require.Equal(t, []time.Time{time.Unix(1, 0), time.Unix(2, 0)}, []time.Time{
+		frame.Fields[0].At(0).(time.Time), frame.Fields[0].At(1).(time.Time),
+	})
+	require.Equal(t, data.Labels{"job": "node"}, frame.Fields[1].Labels)
+	require.Equal(t, 1.0, *frame.Fields[1].At(0).(*float64))
+	require.Equal(t, 0.0, *frame.Fields[1].At(1).(*float64))
+	require.Equal(t, data.Labels{"job": "grafana"}, frame.Fields[2].Labels)
+	require.Nil(t, frame.Fields[2].At(0))
+	require.Equal(t, 1.0, *frame.Fields[2].At(1).(*float64))
+
+	_, err = NewRemoteWriteConverter(FrameFormatWide).Convert(encoded)
+	require.Error(t, err, "remote write requests must be snappy compressed")
+}
@@ -444,29 +444,33 @@ func (repo *FakeAnnotationsRepo) Len() int {
 	return len(repo.items)
 }
 
-func (repo *FakeAnnotationsRepo) Delete(params *annotations.DeleteParams) error {
+func (repo *FakeAnnotationsRepo) Delete(_ context.Context, params *annotations.DeleteParams) error {
 	return nil
 }
 
-func (repo *FakeAnnotationsRepo) Save(item *annotations.Item) error {
+func (repo *FakeAnnotationsRepo) Save(_ context.Context, item *annotations.Item) error {
 	repo.mtx.Lock()
 	defer repo.mtx.Unlock()
 	repo.items = append(repo.items, item)
 
 	return nil
 }
-func (repo *FakeAnnotationsRepo) Update(item *annotations.Item) error {
+func (repo *FakeAnnotationsRepo) Update(_ context.Context, item *annotations.Item) error {
 	return nil
 }
 
-func (repo *FakeAnnotationsRepo) Find(query *annotations.ItemQuery) ([]*annotations.ItemDTO, error) {
+func (repo *FakeAnnotationsRepo) Find(_ context.Context, query *annotations.ItemQuery) ([]*annotations.ItemDTO, error) {
 	annotations := []*annotations.ItemDTO{{Id: 1}}
 	return annotations, nil
 }
 
-func (repo *FakeAnnotationsRepo) FindTags(query *annotations.TagsQuery) (annotations.FindTagsResult, error) {
+func (repo *FakeAnnotationsRepo) FindTags(_ context.Context, query *annotations.TagsQuery) (annotations.FindTagsResult, error) {
 	result := annotations.FindTagsResult{
 		Tags: []*annotations.TagsDTO{},
 	}
 	return result, nil
 }
+
+func (repo *FakeAnnotationsRepo) CountTags(_ context.Context, query *annotations.TagCountsQuery) (annotations.TagCountsResult, error) {
+	return annotations.TagCountsResult{Counts: []*annotations.TagCountDTO{}}, nil
+}
@@ -276,7 +276,7 @@ func (st *Manager) createAlertAnnotation(ctx context.Context, new eval.State, al
 	}
 
 	annotationRepo := annotations.GetRepository()
-	if err := annotationRepo.Save(item); err != nil {
+	if err := annotationRepo.Save(ctx, item); err != nil {
 		st.log.Error("error saving alert annotation", "alertRuleUID", alertRule.UID, "error", err.Error())
 		return
 	}
@@ -9,6 +9,7 @@ import (
 	"github.com/grafana/grafana/pkg/dashboards"
 	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/schemaloader"
 	"github.com/grafana/grafana/pkg/util/errutil"
 )
 
@@ -23,7 +24,7 @@ type DashboardProvisioner interface {
 }
 
 // DashboardProvisionerFactory creates DashboardProvisioners based on input
-type DashboardProvisionerFactory func(context.Context, string, dashboards.Store) (DashboardProvisioner, error)
+type DashboardProvisionerFactory func(context.Context, string, dashboards.Store, *schemaloader.SchemaLoaderService) (DashboardProvisioner, error)
 
 // Provisioner is responsible for syncing dashboard from disk to Grafana's database.
 type Provisioner struct {
@@ -33,8 +34,9 @@ type Provisioner struct {
 	duplicateValidator duplicateValidator
 }
 
-// New returns a new DashboardProvisioner
-func New(ctx context.Context, configDirectory string, store dashboards.Store) (DashboardProvisioner, error) {
+// New returns a new DashboardProvisioner. When schema validation is enabled, dashboards that don't match the
+// dashboard schema are not provisioned.
+func New(ctx context.Context, configDirectory string, store dashboards.Store, schemaLoaderService *schemaloader.SchemaLoaderService) (DashboardProvisioner, error) {
 	logger := log.New("provisioning.dashboard")
 	cfgReader := &configReader{path: configDirectory, log: logger}
 	configs, err := cfgReader.readConfig(ctx)
@@ -42,7 +44,7 @@ func New(ctx context.Context, configDirectory string, store dashboards.Store) (D
 		return nil, errutil.Wrap("Failed to read dashboards config", err)
 	}
 
-	fileReaders, err := getFileReaders(configs, logger, store)
+	fileReaders, err := getFileReaders(configs, logger, store, schemaLoaderService)
 	if err != nil {
 		return nil, errutil.Wrap("Failed to initialize file readers", err)
 	}
@@ -120,7 +122,8 @@ func (provider *Provisioner) GetAllowUIUpdatesFromConfig(name string) bool {
 	return false
 }
 
-func getFileReaders(configs []*config, logger log.Logger, store dashboards.Store) ([]*FileReader, error) {
+func getFileReaders(configs []*config, logger log.Logger, store dashboards.Store,
+	schemaLoaderService *schemaloader.SchemaLoaderService) ([]*FileReader, error) {
 	var readers []*FileReader
 
 	for _, config := range configs {
@@ -131,6 +134,7 @@ func getFileReaders(configs []*config, logger log.Logger, store dashboards.Store
 			if err != nil {
 				return nil, errutil.Wrapf(err, "Failed to create file reader for config %v", config.Name)
 			}
+			fileReader.schemaLoaderService = schemaLoaderService
 			readers = append(readers, fileReader)
 		default:
 			return nil, fmt.Errorf("type %s is not supported", config.Type)
@@ -17,6 +17,7 @@ import (
 	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/models"
 	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/schemaloader"
 	"github.com/grafana/grafana/pkg/util"
 )
 
@@ -33,6 +34,7 @@ type FileReader struct {
 	Path                         string
 	log                          log.Logger
 	dashboardProvisioningService dashboards.DashboardProvisioningService
+	schemaLoaderService          *schemaloader.SchemaLoaderService
 	FoldersFromFilesStructure    bool
 
 	mux                     sync.RWMutex
@@ -406,6 +408,12 @@ func (fr *FileReader) readDashboardFromFile(path string, lastModified time.Time,
 		return nil, err
 	}
 
+	if fr.schemaLoaderService != nil && fr.schemaLoaderService.IsValidationEnabled() {
+		if data, err = fr.schemaLoaderService.DashboardValidate(data); err != nil {
+			return nil, err
+		}
+	}
+
 	dash, err := createDashboardJSON(data, lastModified, fr.Cfg, folderID)
 	if err != nil {
 		return nil, err
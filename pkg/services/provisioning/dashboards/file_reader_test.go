@@ -14,6 +14,8 @@ import (
 	dboards "github.com/grafana/grafana/pkg/dashboards"
 	"github.com/grafana/grafana/pkg/models"
 	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/featuremgmt"
+	"github.com/grafana/grafana/pkg/services/schemaloader"
 	"github.com/grafana/grafana/pkg/util"
 
 	"github.com/grafana/grafana/pkg/infra/log"
@@ -28,6 +30,7 @@ const (
 	containingID              = "testdata/test-dashboards/containing-id"
 	unprovision               = "testdata/test-dashboards/unprovision"
 	foldersFromFilesStructure = "testdata/test-dashboards/folders-from-files-structure"
+	schemaValidation          = "testdata/test-dashboards/schema-validation"
 )
 
 var fakeService *fakeDashboardProvisioningService
@@ -362,6 +365,24 @@ func TestDashboardFileReader(t *testing.T) {
 			require.NoError(t, err)
 		})
 
+		t.Run("Dashboards not matching the schema should not be provisioned when validation is enabled", func(t *testing.T) {
+			setup()
+			cfg.Options["path"] = schemaValidation
+
+			schemaLoaderService, err := schemaloader.ProvideService(featuremgmt.WithFeatures(featuremgmt.FlagDashboardSchemaValidation))
+			require.NoError(t, err)
+
+			reader, err := NewDashboardFileReader(cfg, logger, nil)
+			require.NoError(t, err)
+			reader.schemaLoaderService = schemaLoaderService
+
+			err = reader.walkDisk(context.Background())
+			require.NoError(t, err)
+
+			require.Len(t, fakeService.inserted, 1)
+			require.Equal(t, "Valid", fakeService.inserted[0].Dashboard.Title)
+		})
+
 		t.Run("Two dashboard providers should be able to provisioned the same dashboard without uid", func(t *testing.T) {
 			setup()
 			cfg1 := &config{Name: "1", Type: "file", OrgID: 1, Folder: "f1", Options: map[string]interface{}{"path": containingID}}
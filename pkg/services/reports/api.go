@@ -0,0 +1,150 @@
+package reports
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/middleware"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	acmiddleware "github.com/grafana/grafana/pkg/services/accesscontrol/middleware"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+const maxDeliveries = 100
+
+func (s *ReportService) registerAPIEndpoints() {
+	auth := acmiddleware.Middleware(s.AccessControl)
+	s.RouteRegister.Group("/api/scheduled-reports", func(reports routing.RouteRegister) {
+		reports.Get("/", auth(middleware.ReqOrgAdmin, accesscontrol.EvalPermission(ActionRead, ScopeAll)), routing.Wrap(s.listHandler))
+		reports.Post("/", auth(middleware.ReqOrgAdmin, accesscontrol.EvalPermission(ActionCreate)), routing.Wrap(s.createHandler))
+		reports.Get("/:id", auth(middleware.ReqOrgAdmin, accesscontrol.EvalPermission(ActionRead, ScopeID)), routing.Wrap(s.getHandler))
+		reports.Put("/:id", auth(middleware.ReqOrgAdmin, accesscontrol.EvalPermission(ActionWrite, ScopeID)), routing.Wrap(s.updateHandler))
+		reports.Delete("/:id", auth(middleware.ReqOrgAdmin, accesscontrol.EvalPermission(ActionDelete, ScopeID)), routing.Wrap(s.deleteHandler))
+		reports.Post("/:id/send", auth(middleware.ReqOrgAdmin, accesscontrol.EvalPermission(ActionSend, ScopeID)), routing.Wrap(s.sendHandler))
+		reports.Get("/:id/deliveries", auth(middleware.ReqOrgAdmin, accesscontrol.EvalPermission(ActionRead, ScopeID)), routing.Wrap(s.deliveriesHandler))
+	})
+}
+
+func (s *ReportService) listHandler(c *models.ReqContext) response.Response {
+	reports, err := s.findReports(c.Req.Context(), c.OrgId)
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to list reports", err)
+	}
+	return response.JSON(http.StatusOK, ReportsResponse{Result: reports})
+}
+
+func (s *ReportService) getHandler(c *models.ReqContext) response.Response {
+	id, err := strconv.ParseInt(web.Params(c.Req)[":id"], 10, 64)
+	if err != nil {
+		return response.Error(http.StatusBadRequest, "id is invalid", err)
+	}
+	report, err := s.getReport(c.Req.Context(), c.OrgId, id)
+	if err != nil {
+		return errorResponse("Failed to get report", err)
+	}
+	return response.JSON(http.StatusOK, ReportResponse{Result: report})
+}
+
+func (s *ReportService) createHandler(c *models.ReqContext) response.Response {
+	cmd := CreateOrUpdateReportCommand{}
+	if err := web.Bind(c.Req, &cmd); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+	if err := s.checkDashboard(c, cmd.DashboardUID); err != nil {
+		return errorResponse("Failed to create report", err)
+	}
+
+	report, err := s.CreateReport(c.Req.Context(), c.SignedInUser, cmd)
+	if err != nil {
+		return errorResponse("Failed to create report", err)
+	}
+	return response.JSON(http.StatusOK, ReportResponse{Result: report})
+}
+
+func (s *ReportService) updateHandler(c *models.ReqContext) response.Response {
+	id, err := strconv.ParseInt(web.Params(c.Req)[":id"], 10, 64)
+	if err != nil {
+		return response.Error(http.StatusBadRequest, "id is invalid", err)
+	}
+	cmd := CreateOrUpdateReportCommand{}
+	if err := web.Bind(c.Req, &cmd); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+	if err := s.checkDashboard(c, cmd.DashboardUID); err != nil {
+		return errorResponse("Failed to update report", err)
+	}
+
+	report, err := s.UpdateReport(c.Req.Context(), c.SignedInUser, id, cmd)
+	if err != nil {
+		return errorResponse("Failed to update report", err)
+	}
+	return response.JSON(http.StatusOK, ReportResponse{Result: report})
+}
+
+func (s *ReportService) deleteHandler(c *models.ReqContext) response.Response {
+	id, err := strconv.ParseInt(web.Params(c.Req)[":id"], 10, 64)
+	if err != nil {
+		return response.Error(http.StatusBadRequest, "id is invalid", err)
+	}
+	if err := s.DeleteReport(c.Req.Context(), c.OrgId, id); err != nil {
+		return errorResponse("Failed to delete report", err)
+	}
+	return response.Success("Report deleted")
+}
+
+func (s *ReportService) sendHandler(c *models.ReqContext) response.Response {
+	id, err := strconv.ParseInt(web.Params(c.Req)[":id"], 10, 64)
+	if err != nil {
+		return response.Error(http.StatusBadRequest, "id is invalid", err)
+	}
+	delivery, err := s.SendReport(c.Req.Context(), c.OrgId, id)
+	if errors.Is(err, ErrReportNotFound) {
+		return errorResponse("Failed to send report", err)
+	}
+	if err != nil {
+		// The failed delivery is in the history of the report.
+		return response.JSON(http.StatusInternalServerError, delivery)
+	}
+	return response.JSON(http.StatusOK, delivery)
+}
+
+func (s *ReportService) deliveriesHandler(c *models.ReqContext) response.Response {
+	id, err := strconv.ParseInt(web.Params(c.Req)[":id"], 10, 64)
+	if err != nil {
+		return response.Error(http.StatusBadRequest, "id is invalid", err)
+	}
+	if _, err := s.getReport(c.Req.Context(), c.OrgId, id); err != nil {
+		return errorResponse("Failed to get report deliveries", err)
+	}
+
+	limit := c.QueryInt("limit")
+	if limit <= 0 || limit > maxDeliveries {
+		limit = maxDeliveries
+	}
+	deliveries, err := s.findDeliveries(c.Req.Context(), c.OrgId, id, limit)
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to get report deliveries", err)
+	}
+	return response.JSON(http.StatusOK, DeliveriesResponse{Result: deliveries})
+}
+
+func errorResponse(message string, err error) response.Response {
+	switch {
+	case errors.Is(err, ErrReportNotFound):
+		return response.Error(http.StatusNotFound, err.Error(), err)
+	case errors.Is(err, ErrDashboardNotFound):
+		return response.Error(http.StatusBadRequest, err.Error(), err)
+	case errors.Is(err, ErrDashboardAccessDenied):
+		return response.Error(http.StatusForbidden, err.Error(), err)
+	case errors.Is(err, ErrReportNameTaken):
+		return response.Error(http.StatusConflict, err.Error(), err)
+	case errors.Is(err, ErrInvalidSchedule), errors.Is(err, ErrInvalidTimezone), errors.Is(err, ErrInvalidFormat),
+		errors.Is(err, ErrInvalidRecipient), errors.Is(err, ErrNoRecipients):
+		return response.Error(http.StatusBadRequest, err.Error(), err)
+	}
+	return response.Error(http.StatusInternalServerError, message, err)
+}
@@ -0,0 +1,129 @@
+package reports
+
+import (
+	"context"
+
+	"github.com/grafana/grafana/pkg/services/sqlstore"
+)
+
+func (s *ReportService) getReport(ctx context.Context, orgID, id int64) (*Report, error) {
+	report := &Report{}
+	err := s.SQLStore.WithDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		exists, err := sess.Where("org_id = ? AND id = ?", orgID, id).Get(report)
+		if err != nil {
+			return err
+		}
+		if !exists {
+			return ErrReportNotFound
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return report, nil
+}
+
+func (s *ReportService) findReports(ctx context.Context, orgID int64) ([]*Report, error) {
+	reports := make([]*Report, 0)
+	err := s.SQLStore.WithDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		return sess.Where("org_id = ?", orgID).Asc("name").Find(&reports)
+	})
+	return reports, err
+}
+
+// findDueReports returns the enabled reports of all organizations scheduled at or before a time.
+func (s *ReportService) findDueReports(ctx context.Context, now int64) ([]*Report, error) {
+	reports := make([]*Report, 0)
+	err := s.SQLStore.WithDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		return sess.Where("enabled = ? AND next_run > 0 AND next_run <= ?", true, now).Asc("next_run").Find(&reports)
+	})
+	return reports, err
+}
+
+func (s *ReportService) insertReport(ctx context.Context, report *Report) error {
+	return s.SQLStore.WithTransactionalDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		if err := checkReportName(sess, report); err != nil {
+			return err
+		}
+		_, err := sess.Insert(report)
+		return err
+	})
+}
+
+func (s *ReportService) updateReport(ctx context.Context, report *Report) error {
+	return s.SQLStore.WithTransactionalDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		if err := checkReportName(sess, report); err != nil {
+			return err
+		}
+		affected, err := sess.Where("org_id = ? AND id = ?", report.OrgId, report.Id).AllCols().Update(report)
+		if err != nil {
+			return err
+		}
+		if affected == 0 {
+			return ErrReportNotFound
+		}
+		return nil
+	})
+}
+
+func checkReportName(sess *sqlstore.DBSession, report *Report) error {
+	existing := &Report{}
+	exists, err := sess.Where("org_id = ? AND name = ? AND id != ?", report.OrgId, report.Name, report.Id).Get(existing)
+	if err != nil {
+		return err
+	}
+	if exists {
+		return ErrReportNameTaken
+	}
+	return nil
+}
+
+// deleteReport deletes a report with its delivery history.
+func (s *ReportService) deleteReport(ctx context.Context, orgID, id int64) error {
+	return s.SQLStore.WithTransactionalDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		affected, err := sess.Where("org_id = ? AND id = ?", orgID, id).Delete(&Report{})
+		if err != nil {
+			return err
+		}
+		if affected == 0 {
+			return ErrReportNotFound
+		}
+		_, err = sess.Where("org_id = ? AND report_id = ?", orgID, id).Delete(&Delivery{})
+		return err
+	})
+}
+
+// claimRun moves the next run of a report from the scheduled one to the following one. Only one of the instances
+// running the scheduler succeeds, and delivers the report.
+func (s *ReportService) claimRun(ctx context.Context, report *Report, nextRun int64) (bool, error) {
+	claimed := false
+	err := s.SQLStore.WithDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		res, err := sess.Exec("UPDATE report SET next_run = ? WHERE id = ? AND next_run = ?", nextRun, report.Id, report.NextRun)
+		if err != nil {
+			return err
+		}
+		affected, err := res.RowsAffected()
+		claimed = affected == 1
+		return err
+	})
+	return claimed, err
+}
+
+func (s *ReportService) insertDelivery(ctx context.Context, delivery *Delivery) error {
+	return s.SQLStore.WithDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		_, err := sess.Insert(delivery)
+		return err
+	})
+}
+
+func (s *ReportService) findDeliveries(ctx context.Context, orgID, reportID int64, limit int) ([]*Delivery, error) {
+	deliveries := make([]*Delivery, 0)
+	err := s.SQLStore.WithDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		return sess.Where("org_id = ? AND report_id = ?", orgID, reportID).
+			Desc("started").Desc("id").
+			Limit(limit).
+			Find(&deliveries)
+	})
+	return deliveries, err
+}
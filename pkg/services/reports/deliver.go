@@ -0,0 +1,176 @@
+package reports
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/mail"
+	"net/url"
+	"time"
+
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/rendering"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+const tmplReport = "report"
+
+// deliver renders a report and emails it to its recipients, and records the delivery in the history of the report.
+func (s *ReportService) deliver(ctx context.Context, report *Report, kind DeliveryKind) (*Delivery, error) {
+	delivery := &Delivery{
+		OrgId:    report.OrgId,
+		ReportId: report.Id,
+		Kind:     kind,
+		State:    DeliveryStateSent,
+		Started:  time.Now(),
+	}
+
+	err := s.send(ctx, report, delivery)
+	delivery.Finished = time.Now()
+	if err != nil {
+		delivery.State = DeliveryStateFailed
+		delivery.Error = err.Error()
+	}
+
+	if err := s.insertDelivery(ctx, delivery); err != nil {
+		s.log.Error("Failed to save report delivery", "reportId", report.Id, "err", err)
+	}
+	return delivery, err
+}
+
+func (s *ReportService) send(ctx context.Context, report *Report, delivery *Delivery) error {
+	owner, err := s.owner(ctx, report)
+	if err != nil {
+		return err
+	}
+
+	to, err := s.recipientAddresses(ctx, report, owner)
+	if err != nil {
+		return err
+	}
+	delivery.Recipients = len(to)
+	if len(to) == 0 {
+		return ErrNoRecipients
+	}
+
+	query := models.GetDashboardQuery{Uid: report.DashboardUID, OrgId: report.OrgId}
+	if err := s.SQLStore.GetDashboard(ctx, &query); err != nil {
+		if errors.Is(err, models.ErrDashboardNotFound) {
+			return ErrDashboardNotFound
+		}
+		return err
+	}
+	dash := query.Result
+
+	// Panels are rendered as the owner of the report, so that recipients only see what the owner can see.
+	panels, err := s.renderPanels(ctx, report, dash, rendering.AuthOpts{
+		OrgID:   report.OrgId,
+		UserID:  owner.UserId,
+		OrgRole: owner.OrgRole,
+	})
+	if err != nil {
+		return err
+	}
+	files, err := attachments(report, dash, panels)
+	if err != nil {
+		return err
+	}
+	delivery.Files = len(files)
+
+	return s.NotificationService.SendEmailCommandHandlerSync(ctx, &models.SendEmailCommandSync{
+		SendEmailCommand: models.SendEmailCommand{
+			To:       to,
+			Template: tmplReport,
+			Data: map[string]interface{}{
+				"ReportName":     report.Name,
+				"DashboardTitle": dash.Title,
+				"DashboardUrl":   dashboardURL(report, dash),
+				"TimeRange":      timeRangeText(report),
+				"Message":        report.Message,
+			},
+			AttachedFiles: files,
+		},
+	})
+}
+
+// owner returns the user who created the report, as a member of the organization of the report.
+func (s *ReportService) owner(ctx context.Context, report *Report) (*models.SignedInUser, error) {
+	query := models.GetSignedInUserQuery{UserId: report.UserId, OrgId: report.OrgId}
+	if err := s.SQLStore.GetSignedInUser(ctx, &query); err != nil {
+		if errors.Is(err, models.ErrUserNotFound) {
+			return nil, fmt.Errorf("report owner not found")
+		}
+		return nil, err
+	}
+	if query.Result.OrgId != report.OrgId {
+		return nil, fmt.Errorf("report owner is not a member of the organization")
+	}
+	return query.Result, nil
+}
+
+// recipientAddresses returns the unique addresses of the users, the members of the teams and the addresses of
+// a report. Users who aren't members of the organization of the report are skipped.
+func (s *ReportService) recipientAddresses(ctx context.Context, report *Report, owner *models.SignedInUser) ([]string, error) {
+	seen := map[string]bool{}
+	var addresses []string
+	add := func(address string) {
+		if address != "" && !seen[address] {
+			seen[address] = true
+			addresses = append(addresses, address)
+		}
+	}
+
+	for _, id := range report.Recipients.UserIDs {
+		query := models.GetSignedInUserQuery{UserId: id, OrgId: report.OrgId}
+		if err := s.SQLStore.GetSignedInUser(ctx, &query); err != nil {
+			if errors.Is(err, models.ErrUserNotFound) {
+				continue
+			}
+			return nil, err
+		}
+		if query.Result.OrgId == report.OrgId {
+			add(query.Result.Email)
+		}
+	}
+
+	for _, id := range report.Recipients.TeamIDs {
+		query := models.GetTeamMembersQuery{OrgId: report.OrgId, TeamId: id, SignedInUser: owner}
+		if err := s.SQLStore.GetTeamMembers(ctx, &query); err != nil {
+			return nil, err
+		}
+		for _, member := range query.Result {
+			add(member.Email)
+		}
+	}
+
+	for _, address := range report.Recipients.Addresses {
+		add(address)
+	}
+	return addresses, nil
+}
+
+func validateAddresses(addresses []string) error {
+	for _, address := range addresses {
+		if _, err := mail.ParseAddress(address); err != nil {
+			return fmt.Errorf("%w: %s", ErrInvalidRecipient, address)
+		}
+	}
+	return nil
+}
+
+func dashboardURL(report *Report, dash *models.Dashboard) string {
+	params := url.Values{}
+	params.Set("orgId", fmt.Sprint(report.OrgId))
+	if report.TimeFrom != "" {
+		params.Set("from", report.TimeFrom)
+	}
+	if report.TimeTo != "" {
+		params.Set("to", report.TimeTo)
+	}
+	for name, values := range report.Variables {
+		for _, value := range values {
+			params.Add("var-"+name, value)
+		}
+	}
+	return setting.ToAbsUrl(fmt.Sprintf("d/%s/%s?%s", dash.Uid, dash.Slug, params.Encode()))
+}
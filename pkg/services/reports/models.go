@@ -0,0 +1,157 @@
+package reports
+
+import (
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+)
+
+var (
+	ErrReportNotFound        = errors.New("report not found")
+	ErrReportNameTaken       = errors.New("a report with the same name already exists")
+	ErrInvalidSchedule       = errors.New("invalid schedule")
+	ErrInvalidTimezone       = errors.New("invalid timezone")
+	ErrInvalidFormat         = errors.New("invalid format, must be pdf or png")
+	ErrInvalidRecipient      = errors.New("invalid recipient address")
+	ErrNoRecipients          = errors.New("report has no recipients")
+	ErrDashboardNotFound     = errors.New("dashboard not found")
+	ErrDashboardAccessDenied = errors.New("access denied to the dashboard")
+	ErrNoPanelsToRender      = errors.New("dashboard has no panels to render")
+	ErrRendererNotEnabled    = errors.New("no image renderer available")
+)
+
+// Actions and scopes are distinct from those of reporting in Grafana Enterprise.
+var (
+	ScopeAll = "scheduled-reports:*"
+	ScopeID  = accesscontrol.Scope("scheduled-reports", "id", accesscontrol.Parameter(":id"))
+)
+
+const (
+	ActionCreate = "scheduled-reports:create"
+	ActionWrite  = "scheduled-reports:write"
+	ActionDelete = "scheduled-reports:delete"
+	ActionRead   = "scheduled-reports:read"
+	ActionSend   = "scheduled-reports:send"
+)
+
+type Format string
+
+const (
+	// FormatPDF is a single PDF file with a page for each panel.
+	FormatPDF Format = "pdf"
+
+	// FormatPNG is a PNG file for each panel.
+	FormatPNG Format = "png"
+)
+
+func (f Format) IsValid() bool {
+	return f == FormatPDF || f == FormatPNG
+}
+
+type Recipients struct {
+	UserIDs   []int64  `json:"userIds"`
+	TeamIDs   []int64  `json:"teamIds"`
+	Addresses []string `json:"addresses"`
+}
+
+func (r Recipients) IsEmpty() bool {
+	return len(r.UserIDs) == 0 && len(r.TeamIDs) == 0 && len(r.Addresses) == 0
+}
+
+// FromDB implements xorm's Conversion interface, recipients are stored as JSON.
+func (r *Recipients) FromDB(data []byte) error {
+	*r = Recipients{}
+	if len(data) == 0 {
+		return nil
+	}
+	return json.Unmarshal(data, r)
+}
+
+// ToDB implements xorm's Conversion interface.
+func (r *Recipients) ToDB() ([]byte, error) {
+	return json.Marshal(r)
+}
+
+// Report is a dashboard rendered and emailed on a schedule.
+type Report struct {
+	Id           int64  `json:"id"`
+	OrgId        int64  `json:"orgId"`
+	UserId       int64  `json:"userId"`
+	Name         string `json:"name"`
+	DashboardUID string `json:"dashboardUid" xorm:"dashboard_uid"`
+	TimeFrom     string `json:"timeFrom"`
+	TimeTo       string `json:"timeTo"`
+	// Variables are the values of the dashboard template variables.
+	Variables map[string][]string `json:"variables"`
+	Format    Format              `json:"format"`
+	// Schedule is a cron expression, e.g. "0 8 * * 1" for Mondays at 8:00, or a descriptor such as "@daily".
+	Schedule   string     `json:"schedule"`
+	Timezone   string     `json:"timezone"`
+	Recipients Recipients `json:"recipients"`
+	Message    string     `json:"message"`
+	Enabled    bool       `json:"enabled"`
+	// NextRun is the time of the next scheduled delivery in seconds, it is 0 when the report is disabled.
+	NextRun int64     `json:"nextRun"`
+	Created time.Time `json:"created"`
+	Updated time.Time `json:"updated"`
+}
+
+type DeliveryKind string
+
+const (
+	DeliveryKindScheduled DeliveryKind = "scheduled"
+	DeliveryKindManual    DeliveryKind = "manual"
+)
+
+type DeliveryState string
+
+const (
+	DeliveryStateSent   DeliveryState = "sent"
+	DeliveryStateFailed DeliveryState = "failed"
+)
+
+// Delivery records a sent or failed delivery of a report.
+type Delivery struct {
+	Id         int64         `json:"id"`
+	OrgId      int64         `json:"orgId"`
+	ReportId   int64         `json:"reportId"`
+	Kind       DeliveryKind  `json:"kind"`
+	State      DeliveryState `json:"state"`
+	Error      string        `json:"error"`
+	Recipients int           `json:"recipients"`
+	Files      int           `json:"files"`
+	Started    time.Time     `json:"started"`
+	Finished   time.Time     `json:"finished"`
+}
+
+func (d Delivery) TableName() string {
+	return "report_delivery"
+}
+
+type CreateOrUpdateReportCommand struct {
+	Name         string              `json:"name" binding:"Required"`
+	DashboardUID string              `json:"dashboardUid" binding:"Required"`
+	TimeFrom     string              `json:"timeFrom"`
+	TimeTo       string              `json:"timeTo"`
+	Variables    map[string][]string `json:"variables"`
+	Format       Format              `json:"format"`
+	Schedule     string              `json:"schedule" binding:"Required"`
+	Timezone     string              `json:"timezone"`
+	Recipients   Recipients          `json:"recipients"`
+	Message      string              `json:"message"`
+	Enabled      bool                `json:"enabled"`
+}
+
+type ReportResponse struct {
+	Result *Report `json:"result"`
+}
+
+type ReportsResponse struct {
+	Result []*Report `json:"result"`
+}
+
+type DeliveriesResponse struct {
+	Result []*Delivery `json:"result"`
+}
@@ -0,0 +1,224 @@
+package reports
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"image/png"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jung-kurt/gofpdf"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/rendering"
+)
+
+const (
+	panelWidth    = 1000
+	panelHeight   = 500
+	renderTimeout = time.Minute
+)
+
+type panel struct {
+	id    int64
+	title string
+}
+
+// dashboardPanels returns the panels of a dashboard in their order, including those in collapsed rows.
+func dashboardPanels(data *simplejson.Json) []panel {
+	var panels []panel
+	var add func(list []interface{})
+	add = func(list []interface{}) {
+		for _, item := range list {
+			p := simplejson.NewFromAny(item)
+			if p.Get("type").MustString() == "row" {
+				add(p.Get("panels").MustArray())
+				continue
+			}
+			id, err := p.Get("id").Int64()
+			if err != nil {
+				continue
+			}
+			panels = append(panels, panel{id: id, title: p.Get("title").MustString()})
+		}
+	}
+	add(data.Get("panels").MustArray())
+	return panels
+}
+
+type renderedPanel struct {
+	panel
+	image []byte
+}
+
+// renderPanels renders the panels of a dashboard with the time range and variables of a report.
+func (s *ReportService) renderPanels(ctx context.Context, report *Report, dash *models.Dashboard, auth rendering.AuthOpts) ([]renderedPanel, error) {
+	if !s.RenderService.IsAvailable() {
+		return nil, ErrRendererNotEnabled
+	}
+	panels := dashboardPanels(dash.Data)
+	if len(panels) == 0 {
+		return nil, ErrNoPanelsToRender
+	}
+
+	rendered := make([]renderedPanel, 0, len(panels))
+	for _, p := range panels {
+		res, err := s.RenderService.Render(ctx, rendering.Opts{
+			TimeoutOpts: rendering.TimeoutOpts{
+				Timeout: renderTimeout,
+			},
+			AuthOpts:        auth,
+			Width:           panelWidth,
+			Height:          panelHeight,
+			Path:            panelPath(report, dash, p.id),
+			Timezone:        report.Timezone,
+			ConcurrentLimit: s.Cfg.RendererConcurrentRequestLimit,
+			Theme:           models.ThemeLight,
+		}, nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to render panel %d: %w", p.id, err)
+		}
+		image, err := readRenderedImage(res.FilePath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to render panel %d: %w", p.id, err)
+		}
+		rendered = append(rendered, renderedPanel{panel: p, image: image})
+	}
+	return rendered, nil
+}
+
+func panelPath(report *Report, dash *models.Dashboard, panelID int64) string {
+	params := url.Values{}
+	params.Set("orgId", strconv.FormatInt(report.OrgId, 10))
+	params.Set("panelId", strconv.FormatInt(panelID, 10))
+	if report.TimeFrom != "" {
+		params.Set("from", report.TimeFrom)
+	}
+	if report.TimeTo != "" {
+		params.Set("to", report.TimeTo)
+	}
+	if report.Timezone != "" {
+		params.Set("tz", report.Timezone)
+	}
+	for name, values := range report.Variables {
+		for _, value := range values {
+			params.Add("var-"+name, value)
+		}
+	}
+	return fmt.Sprintf("d-solo/%s/%s?%s", dash.Uid, dash.Slug, params.Encode())
+}
+
+// readRenderedImage reads and removes an image rendered in a temporary file.
+func readRenderedImage(filePath string) ([]byte, error) {
+	if filePath == "" {
+		return nil, fmt.Errorf("no image returned by the renderer")
+	}
+	if strings.Contains(filePath, "public/img") {
+		// rendering service returned a static error image - we should not remove that file
+		return nil, fmt.Errorf("renderer returned an error image %s", filePath)
+	}
+	defer func() {
+		_ = os.Remove(filePath)
+	}()
+	return os.ReadFile(filepath.Clean(filePath))
+}
+
+// buildPDF assembles the rendered panels in a landscape A4 document, with a page for each panel.
+func buildPDF(title, subtitle string, panels []renderedPanel) ([]byte, error) {
+	pdf := gofpdf.New("L", "mm", "A4", "")
+	tr := pdf.UnicodeTranslatorFromDescriptor("")
+	pdf.SetTitle(title, true)
+	pdf.SetCreator("Grafana", true)
+	pdf.SetAutoPageBreak(false, 0)
+
+	pageWidth, pageHeight := pdf.GetPageSize()
+	left, top, right, _ := pdf.GetMargins()
+	width := pageWidth - left - right
+
+	for i, p := range panels {
+		pdf.AddPage()
+		if i == 0 {
+			pdf.SetFont("Helvetica", "B", 16)
+			pdf.CellFormat(0, 10, tr(title), "", 1, "L", false, 0, "")
+			pdf.SetFont("Helvetica", "", 10)
+			pdf.CellFormat(0, 6, tr(subtitle), "", 1, "L", false, 0, "")
+			pdf.Ln(4)
+		}
+		pdf.SetFont("Helvetica", "B", 12)
+		pdf.CellFormat(0, 8, tr(p.title), "", 1, "L", false, 0, "")
+
+		opts := gofpdf.ImageOptions{ImageType: "PNG"}
+		name := fmt.Sprintf("panel-%d", i)
+		info := pdf.RegisterImageOptionsReader(name, opts, bytes.NewReader(p.image))
+		if pdf.Err() {
+			return nil, pdf.Error()
+		}
+
+		y := pdf.GetY() + 2
+		w := width
+		h := w * info.Height() / info.Width()
+		// keep the top margin at the bottom of the page, automatic page breaks being disabled
+		if available := pageHeight - y - top; h > available {
+			w = w * available / h
+			h = available
+		}
+		pdf.ImageOptions(name, left, y, w, h, false, opts, 0, "")
+	}
+
+	var buf bytes.Buffer
+	if err := pdf.Output(&buf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// attachments returns the files attached to the emails of a report, a PDF document or a PNG image for each panel.
+func attachments(report *Report, dash *models.Dashboard, panels []renderedPanel) ([]*models.SendEmailAttachFile, error) {
+	if report.Format == FormatPNG {
+		files := make([]*models.SendEmailAttachFile, 0, len(panels))
+		for i, p := range panels {
+			if _, err := png.DecodeConfig(bytes.NewReader(p.image)); err != nil {
+				return nil, fmt.Errorf("invalid image of panel %d: %w", p.id, err)
+			}
+			name := models.SlugifyTitle(p.title)
+			if name == "" {
+				name = fmt.Sprintf("panel-%d", p.id)
+			}
+			files = append(files, &models.SendEmailAttachFile{
+				Name:    fmt.Sprintf("%02d-%s.png", i+1, name),
+				Content: p.image,
+			})
+		}
+		return files, nil
+	}
+
+	content, err := buildPDF(dash.Title, timeRangeText(report), panels)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build PDF: %w", err)
+	}
+	return []*models.SendEmailAttachFile{{
+		Name:    models.SlugifyTitle(report.Name) + ".pdf",
+		Content: content,
+	}}, nil
+}
+
+func timeRangeText(report *Report) string {
+	from, to := report.TimeFrom, report.TimeTo
+	if from == "" {
+		from = "dashboard default"
+	}
+	if to == "" {
+		to = "dashboard default"
+	}
+	text := fmt.Sprintf("From %s to %s", from, to)
+	if report.Timezone != "" {
+		text += " (" + report.Timezone + ")"
+	}
+	return text
+}
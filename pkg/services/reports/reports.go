@@ -0,0 +1,173 @@
+package reports
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/featuremgmt"
+	"github.com/grafana/grafana/pkg/services/guardian"
+	"github.com/grafana/grafana/pkg/services/notifications"
+	"github.com/grafana/grafana/pkg/services/rendering"
+	"github.com/grafana/grafana/pkg/services/sqlstore"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+func ProvideService(cfg *setting.Cfg, features featuremgmt.FeatureToggles, sqlStore *sqlstore.SQLStore, routeRegister routing.RouteRegister,
+	renderService rendering.Service, notificationService notifications.EmailSender, ac accesscontrol.AccessControl) (*ReportService, error) {
+	s := &ReportService{
+		Cfg:                 cfg,
+		features:            features,
+		SQLStore:            sqlStore,
+		RouteRegister:       routeRegister,
+		RenderService:       renderService,
+		NotificationService: notificationService,
+		AccessControl:       ac,
+		tickInterval:        time.Minute,
+		log:                 log.New("reports"),
+	}
+	if s.IsDisabled() {
+		return s, nil
+	}
+
+	if err := registerRoles(ac); err != nil {
+		return nil, err
+	}
+	s.registerAPIEndpoints()
+
+	return s, nil
+}
+
+type Service interface {
+	CreateReport(ctx context.Context, user *models.SignedInUser, cmd CreateOrUpdateReportCommand) (*Report, error)
+	UpdateReport(ctx context.Context, user *models.SignedInUser, id int64, cmd CreateOrUpdateReportCommand) (*Report, error)
+	DeleteReport(ctx context.Context, orgID, id int64) error
+	SendReport(ctx context.Context, orgID, id int64) (*Delivery, error)
+}
+
+// ReportService renders dashboards and emails them on the schedules of reports.
+type ReportService struct {
+	Cfg                 *setting.Cfg
+	SQLStore            *sqlstore.SQLStore
+	RouteRegister       routing.RouteRegister
+	RenderService       rendering.Service
+	NotificationService notifications.EmailSender
+	AccessControl       accesscontrol.AccessControl
+	features            featuremgmt.FeatureToggles
+	tickInterval        time.Duration
+	log                 log.Logger
+}
+
+// IsDisabled returns true unless the scheduledReports feature toggle is enabled, then neither the API nor the
+// scheduler run.
+func (s *ReportService) IsDisabled() bool {
+	return s.features == nil || !s.features.IsEnabled(featuremgmt.FlagScheduledReports)
+}
+
+func (s *ReportService) Run(ctx context.Context) error {
+	s.runScheduler(ctx)
+	return nil
+}
+
+func (s *ReportService) CreateReport(ctx context.Context, user *models.SignedInUser, cmd CreateOrUpdateReportCommand) (*Report, error) {
+	now := time.Now()
+	report := &Report{
+		OrgId:   user.OrgId,
+		UserId:  user.UserId,
+		Created: now,
+	}
+	if err := applyCommand(report, cmd, now); err != nil {
+		return nil, err
+	}
+	if err := s.insertReport(ctx, report); err != nil {
+		return nil, err
+	}
+	return report, nil
+}
+
+// UpdateReport updates a report, it keeps being rendered as the user who created it.
+func (s *ReportService) UpdateReport(ctx context.Context, user *models.SignedInUser, id int64, cmd CreateOrUpdateReportCommand) (*Report, error) {
+	report, err := s.getReport(ctx, user.OrgId, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := applyCommand(report, cmd, time.Now()); err != nil {
+		return nil, err
+	}
+	if err := s.updateReport(ctx, report); err != nil {
+		return nil, err
+	}
+	return report, nil
+}
+
+func (s *ReportService) DeleteReport(ctx context.Context, orgID, id int64) error {
+	return s.deleteReport(ctx, orgID, id)
+}
+
+// SendReport delivers a report now, without changing its schedule.
+func (s *ReportService) SendReport(ctx context.Context, orgID, id int64) (*Delivery, error) {
+	report, err := s.getReport(ctx, orgID, id)
+	if err != nil {
+		return nil, err
+	}
+	return s.deliver(ctx, report, DeliveryKindManual)
+}
+
+// checkDashboard checks that the user creating or updating a report can view its dashboard.
+func (s *ReportService) checkDashboard(c *models.ReqContext, uid string) error {
+	query := models.GetDashboardQuery{Uid: uid, OrgId: c.OrgId}
+	if err := s.SQLStore.GetDashboard(c.Req.Context(), &query); err != nil {
+		if errors.Is(err, models.ErrDashboardNotFound) {
+			return ErrDashboardNotFound
+		}
+		return err
+	}
+
+	g := guardian.New(c.Req.Context(), query.Result.Id, c.OrgId, c.SignedInUser)
+	if canView, err := g.CanView(); err != nil || !canView {
+		return ErrDashboardAccessDenied
+	}
+	return nil
+}
+
+// applyCommand validates a command and sets the fields of a report, including its next run.
+func applyCommand(report *Report, cmd CreateOrUpdateReportCommand, now time.Time) error {
+	if cmd.Format == "" {
+		cmd.Format = FormatPDF
+	}
+	if !cmd.Format.IsValid() {
+		return ErrInvalidFormat
+	}
+	if err := validateAddresses(cmd.Recipients.Addresses); err != nil {
+		return err
+	}
+	if cmd.Recipients.IsEmpty() {
+		return ErrNoRecipients
+	}
+	next, err := nextRun(cmd.Schedule, cmd.Timezone, now)
+	if err != nil {
+		return err
+	}
+
+	report.Name = cmd.Name
+	report.DashboardUID = cmd.DashboardUID
+	report.TimeFrom = cmd.TimeFrom
+	report.TimeTo = cmd.TimeTo
+	report.Variables = cmd.Variables
+	report.Format = cmd.Format
+	report.Schedule = cmd.Schedule
+	report.Timezone = cmd.Timezone
+	report.Recipients = cmd.Recipients
+	report.Message = cmd.Message
+	report.Enabled = cmd.Enabled
+	report.NextRun = 0
+	if cmd.Enabled {
+		report.NextRun = next.Unix()
+	}
+	report.Updated = now
+	return nil
+}
@@ -0,0 +1,280 @@
+package reports
+
+import (
+	"bytes"
+	"context"
+	"image"
+	"image/png"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/featuremgmt"
+	"github.com/grafana/grafana/pkg/services/notifications"
+	"github.com/grafana/grafana/pkg/services/rendering"
+	"github.com/grafana/grafana/pkg/services/sqlstore"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+func TestNextRun(t *testing.T) {
+	now := time.Date(2022, 3, 7, 10, 30, 0, 0, time.UTC) // a Monday
+
+	next, err := nextRun("0 8 * * 1", "", now)
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2022, 3, 14, 8, 0, 0, 0, time.UTC), next.UTC())
+
+	next, err = nextRun("0 8 * * *", "Europe/Paris", now)
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2022, 3, 8, 7, 0, 0, 0, time.UTC), next.UTC())
+
+	next, err = nextRun("@daily", "", now)
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2022, 3, 8, 0, 0, 0, 0, time.UTC), next.UTC())
+
+	_, err = nextRun("every monday", "", now)
+	require.ErrorIs(t, err, ErrInvalidSchedule)
+
+	_, err = nextRun("0 8 30 2 *", "", now)
+	require.ErrorIs(t, err, ErrInvalidSchedule)
+
+	_, err = nextRun("0 8 * * 1", "Mars/Olympus", now)
+	require.ErrorIs(t, err, ErrInvalidTimezone)
+}
+
+func TestDashboardPanels(t *testing.T) {
+	data, err := simplejson.NewJson([]byte(`{
+		"panels": [
+			{"id": 1, "type": "graph", "title": "CPU"},
+			{"id": 2, "type": "row", "title": "Collapsed", "panels": [
+				{"id": 3, "type": "stat", "title": "Memory"}
+			]},
+			{"type": "text", "title": "No id"},
+			{"id": 4, "type": "table", "title": "Hosts"}
+		]
+	}`))
+	require.NoError(t, err)
+
+	panels := dashboardPanels(data)
+	assert.Equal(t, []panel{{id: 1, title: "CPU"}, {id: 3, title: "Memory"}, {id: 4, title: "Hosts"}}, panels)
+}
+
+func TestPanelPath(t *testing.T) {
+	report := &Report{
+		OrgId:     2,
+		TimeFrom:  "now-7d",
+		TimeTo:    "now",
+		Timezone:  "Europe/Paris",
+		Variables: map[string][]string{"host": {"a", "b"}},
+	}
+	dash := &models.Dashboard{Uid: "abc", Slug: "servers"}
+
+	assert.Equal(t, "d-solo/abc/servers?from=now-7d&orgId=2&panelId=3&to=now&tz=Europe%2FParis&var-host=a&var-host=b",
+		panelPath(report, dash, 3))
+}
+
+func TestAttachments(t *testing.T) {
+	panels := []renderedPanel{
+		{panel: panel{id: 1, title: "CPU usage"}, image: testImage(t)},
+		{panel: panel{id: 2}, image: testImage(t)},
+	}
+	dash := &models.Dashboard{Title: "Servers"}
+
+	t.Run("pdf", func(t *testing.T) {
+		files, err := attachments(&Report{Name: "Weekly servers", Format: FormatPDF}, dash, panels)
+		require.NoError(t, err)
+		require.Len(t, files, 1)
+		assert.Equal(t, "weekly-servers.pdf", files[0].Name)
+		assert.True(t, bytes.HasPrefix(files[0].Content, []byte("%PDF")))
+	})
+
+	t.Run("png", func(t *testing.T) {
+		files, err := attachments(&Report{Name: "Weekly servers", Format: FormatPNG}, dash, panels)
+		require.NoError(t, err)
+		require.Len(t, files, 2)
+		assert.Equal(t, "01-cpu-usage.png", files[0].Name)
+		assert.Equal(t, "02-panel-2.png", files[1].Name)
+	})
+}
+
+func TestApplyCommand(t *testing.T) {
+	cmd := CreateOrUpdateReportCommand{
+		Name:         "Weekly",
+		DashboardUID: "abc",
+		Schedule:     "@weekly",
+		Recipients:   Recipients{Addresses: []string{"ops@example.com"}},
+	}
+
+	report := &Report{}
+	require.NoError(t, applyCommand(report, cmd, time.Now()))
+	assert.Equal(t, FormatPDF, report.Format)
+	assert.Zero(t, report.NextRun, "disabled reports have no next run")
+
+	cmd.Enabled = true
+	require.NoError(t, applyCommand(report, cmd, time.Now()))
+	assert.NotZero(t, report.NextRun)
+
+	invalid := cmd
+	invalid.Format = "svg"
+	require.ErrorIs(t, applyCommand(report, invalid, time.Now()), ErrInvalidFormat)
+
+	invalid = cmd
+	invalid.Recipients = Recipients{Addresses: []string{"not an address"}}
+	require.ErrorIs(t, applyCommand(report, invalid, time.Now()), ErrInvalidRecipient)
+
+	invalid = cmd
+	invalid.Recipients = Recipients{}
+	require.ErrorIs(t, applyCommand(report, invalid, time.Now()), ErrNoRecipients)
+}
+
+func TestIsDisabled(t *testing.T) {
+	assert.True(t, (&ReportService{}).IsDisabled())
+	assert.True(t, (&ReportService{features: featuremgmt.WithFeatures()}).IsDisabled())
+	assert.False(t, (&ReportService{features: featuremgmt.WithFeatures(featuremgmt.FlagScheduledReports)}).IsDisabled())
+}
+
+func TestReportService(t *testing.T) {
+	ctx := context.Background()
+	sqlStore := sqlstore.InitTestDB(t)
+	notifier := notifications.MockNotificationService()
+	s := &ReportService{
+		Cfg:                 setting.NewCfg(),
+		SQLStore:            sqlStore,
+		RenderService:       &fakeRenderService{t: t},
+		NotificationService: notifier,
+		log:                 log.New("reports.test"),
+	}
+
+	owner, err := sqlStore.CreateUser(ctx, models.CreateUserCommand{Login: "owner", Email: "owner@example.com"})
+	require.NoError(t, err)
+	user := &models.SignedInUser{UserId: owner.Id, OrgId: owner.OrgId, OrgRole: models.ROLE_ADMIN}
+
+	dash, err := sqlStore.SaveDashboard(models.SaveDashboardCommand{
+		OrgId: owner.OrgId,
+		Dashboard: simplejson.NewFromAny(map[string]interface{}{
+			"title":  "Servers",
+			"panels": []interface{}{map[string]interface{}{"id": 1, "title": "CPU"}},
+		}),
+	})
+	require.NoError(t, err)
+
+	cmd := CreateOrUpdateReportCommand{
+		Name:         "Weekly",
+		DashboardUID: dash.Uid,
+		Schedule:     "0 8 * * 1",
+		Recipients:   Recipients{UserIDs: []int64{owner.Id}, Addresses: []string{"ops@example.com", "owner@example.com"}},
+		Enabled:      true,
+	}
+	report, err := s.CreateReport(ctx, user, cmd)
+	require.NoError(t, err)
+	require.NotZero(t, report.NextRun)
+
+	_, err = s.CreateReport(ctx, user, cmd)
+	require.ErrorIs(t, err, ErrReportNameTaken)
+
+	t.Run("sends a report now", func(t *testing.T) {
+		delivery, err := s.SendReport(ctx, user.OrgId, report.Id)
+		require.NoError(t, err)
+		assert.Equal(t, DeliveryStateSent, delivery.State)
+		assert.Equal(t, DeliveryKindManual, delivery.Kind)
+		assert.Equal(t, 2, delivery.Recipients)
+		assert.Equal(t, 1, delivery.Files)
+
+		sent := notifier.EmailSync.SendEmailCommand
+		assert.Equal(t, []string{"owner@example.com", "ops@example.com"}, sent.To)
+		assert.Equal(t, tmplReport, sent.Template)
+		require.Len(t, sent.AttachedFiles, 1)
+		assert.Equal(t, "weekly.pdf", sent.AttachedFiles[0].Name)
+	})
+
+	t.Run("claims a due report once", func(t *testing.T) {
+		due, err := s.findDueReports(ctx, report.NextRun)
+		require.NoError(t, err)
+		require.Len(t, due, 1)
+
+		claimed, err := s.claimRun(ctx, due[0], report.NextRun+60)
+		require.NoError(t, err)
+		assert.True(t, claimed)
+
+		claimed, err = s.claimRun(ctx, due[0], report.NextRun+120)
+		require.NoError(t, err)
+		assert.False(t, claimed, "the run was claimed by another instance")
+
+		due, err = s.findDueReports(ctx, report.NextRun)
+		require.NoError(t, err)
+		assert.Empty(t, due)
+	})
+
+	t.Run("records failed deliveries", func(t *testing.T) {
+		s.RenderService = &fakeRenderService{t: t, unavailable: true}
+		defer func() { s.RenderService = &fakeRenderService{t: t} }()
+
+		delivery, err := s.SendReport(ctx, user.OrgId, report.Id)
+		require.ErrorIs(t, err, ErrRendererNotEnabled)
+		assert.Equal(t, DeliveryStateFailed, delivery.State)
+		assert.Equal(t, ErrRendererNotEnabled.Error(), delivery.Error)
+
+		deliveries, err := s.findDeliveries(ctx, user.OrgId, report.Id, maxDeliveries)
+		require.NoError(t, err)
+		require.Len(t, deliveries, 2)
+		assert.Equal(t, DeliveryStateFailed, deliveries[0].State)
+		assert.Equal(t, DeliveryStateSent, deliveries[1].State)
+	})
+
+	t.Run("keeps the owner when another user updates a report", func(t *testing.T) {
+		editor, err := sqlStore.CreateUser(ctx, models.CreateUserCommand{Login: "editor", Email: "editor@example.com"})
+		require.NoError(t, err)
+
+		updateCmd := cmd
+		updateCmd.Message = "Updated"
+		updated, err := s.UpdateReport(ctx, &models.SignedInUser{UserId: editor.Id, OrgId: user.OrgId, OrgRole: models.ROLE_ADMIN}, report.Id, updateCmd)
+		require.NoError(t, err)
+		assert.Equal(t, owner.Id, updated.UserId)
+
+		stored, err := s.getReport(ctx, user.OrgId, report.Id)
+		require.NoError(t, err)
+		assert.Equal(t, owner.Id, stored.UserId)
+		assert.Equal(t, "Updated", stored.Message)
+	})
+
+	t.Run("deletes a report with its deliveries", func(t *testing.T) {
+		require.NoError(t, s.DeleteReport(ctx, user.OrgId, report.Id))
+		_, err := s.getReport(ctx, user.OrgId, report.Id)
+		require.ErrorIs(t, err, ErrReportNotFound)
+
+		deliveries, err := s.findDeliveries(ctx, user.OrgId, report.Id, maxDeliveries)
+		require.NoError(t, err)
+		assert.Empty(t, deliveries)
+	})
+}
+
+type fakeRenderService struct {
+	rendering.Service
+	t           *testing.T
+	unavailable bool
+}
+
+func (r *fakeRenderService) IsAvailable() bool {
+	return !r.unavailable
+}
+
+func (r *fakeRenderService) Render(_ context.Context, _ rendering.Opts, _ rendering.Session) (*rendering.RenderResult, error) {
+	filePath := filepath.Join(r.t.TempDir(), "panel.png")
+	if err := os.WriteFile(filePath, testImage(r.t), 0600); err != nil {
+		return nil, err
+	}
+	return &rendering.RenderResult{FilePath: filePath}, nil
+}
+
+func testImage(t *testing.T) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	require.NoError(t, png.Encode(&buf, image.NewRGBA(image.Rect(0, 0, 200, 100))))
+	return buf.Bytes()
+}
@@ -0,0 +1,43 @@
+package reports
+
+import (
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+)
+
+// registerRoles declares fixed roles keeping the default permissions: organization admins manage reports.
+func registerRoles(ac accesscontrol.AccessControl) error {
+	if ac == nil {
+		return nil
+	}
+	reader := accesscontrol.RoleRegistration{
+		Role: accesscontrol.RoleDTO{
+			Version:     1,
+			Name:        "fixed:scheduled-reports:reader",
+			DisplayName: "Scheduled reports reader",
+			Description: "Read all reports and their delivery history, and send reports.",
+			Group:       "Scheduled reports",
+			Permissions: []accesscontrol.Permission{
+				{Action: ActionRead, Scope: ScopeAll},
+				{Action: ActionSend, Scope: ScopeAll},
+			},
+		},
+		Grants: []string{string(models.ROLE_ADMIN)},
+	}
+	writer := accesscontrol.RoleRegistration{
+		Role: accesscontrol.RoleDTO{
+			Version:     1,
+			Name:        "fixed:scheduled-reports:writer",
+			DisplayName: "Scheduled reports writer",
+			Description: "Create, read, update, or delete all reports.",
+			Group:       "Scheduled reports",
+			Permissions: accesscontrol.ConcatPermissions(reader.Role.Permissions, []accesscontrol.Permission{
+				{Action: ActionCreate},
+				{Action: ActionWrite, Scope: ScopeAll},
+				{Action: ActionDelete, Scope: ScopeAll},
+			}),
+		},
+		Grants: []string{string(models.ROLE_ADMIN)},
+	}
+	return ac.DeclareFixedRoles(reader, writer)
+}
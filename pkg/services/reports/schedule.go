@@ -0,0 +1,82 @@
+package reports
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/robfig/cron/v3"
+)
+
+var scheduleParser = cron.NewParser(cron.Minute | cron.Hour | cron.Dom | cron.Month | cron.Dow | cron.Descriptor)
+
+// nextRun returns the first time after t the schedule of a report runs, in the timezone of the report.
+func nextRun(schedule, timezone string, t time.Time) (time.Time, error) {
+	loc := time.UTC
+	if timezone != "" {
+		var err error
+		if loc, err = time.LoadLocation(timezone); err != nil {
+			return time.Time{}, fmt.Errorf("%w: %s", ErrInvalidTimezone, timezone)
+		}
+	}
+
+	sched, err := scheduleParser.Parse(schedule)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%w: %s", ErrInvalidSchedule, err)
+	}
+	next := sched.Next(t.In(loc))
+	if next.IsZero() {
+		return time.Time{}, fmt.Errorf("%w: %q never runs", ErrInvalidSchedule, schedule)
+	}
+	return next, nil
+}
+
+// runScheduler delivers the due reports at every tick.
+func (s *ReportService) runScheduler(ctx context.Context) {
+	ticker := time.NewTicker(s.tickInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			s.deliverDueReports(ctx, time.Now())
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// deliverDueReports delivers the reports scheduled before now, once even if they missed several runs, e.g. while
+// Grafana was stopped.
+func (s *ReportService) deliverDueReports(ctx context.Context, now time.Time) {
+	reports, err := s.findDueReports(ctx, now.Unix())
+	if err != nil {
+		s.log.Error("Failed to find due reports", "err", err)
+		return
+	}
+
+	for _, report := range reports {
+		var next int64
+		if t, err := nextRun(report.Schedule, report.Timezone, now); err != nil {
+			// Schedules are validated on save, so this only stops reports saved by older versions.
+			s.log.Warn("Invalid report schedule, the report won't be delivered again", "reportId", report.Id, "schedule", report.Schedule, "err", err)
+		} else {
+			next = t.Unix()
+		}
+
+		claimed, err := s.claimRun(ctx, report, next)
+		if err != nil {
+			s.log.Error("Failed to schedule the next report run", "reportId", report.Id, "err", err)
+			continue
+		}
+		if !claimed {
+			continue
+		}
+
+		go func(report *Report) {
+			if _, err := s.deliver(ctx, report, DeliveryKindScheduled); err != nil {
+				s.log.Warn("Failed to deliver report", "reportId", report.Id, "orgId", report.OrgId, "err", err)
+			}
+		}(report)
+	}
+}
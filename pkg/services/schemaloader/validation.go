@@ -0,0 +1,147 @@
+package schemaloader
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"cuelang.org/go/cue"
+	errs "cuelang.org/go/cue/errors"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/schema"
+	"github.com/grafana/grafana/pkg/services/featuremgmt"
+)
+
+// FieldError is an error at a path of a dashboard, e.g. "panels.0.gridPos".
+type FieldError struct {
+	Path    string `json:"path"`
+	Message string `json:"message"`
+}
+
+func (e FieldError) String() string {
+	if e.Path == "" {
+		return e.Message
+	}
+	return e.Path + ": " + e.Message
+}
+
+// ValidationError is returned when a dashboard doesn't match any version of the dashboard schema. Its errors are
+// the ones of the latest version, which dashboards are expected to follow.
+type ValidationError struct {
+	Version string
+	Errors  []FieldError
+}
+
+func (e *ValidationError) Error() string {
+	messages := make([]string, 0, len(e.Errors))
+	for _, fieldErr := range e.Errors {
+		messages = append(messages, fieldErr.String())
+	}
+	return fmt.Sprintf("dashboard does not match schema version %s: %s", e.Version, strings.Join(messages, "; "))
+}
+
+// IsValidationEnabled returns true when dashboards must be validated with DashboardValidate before they are saved.
+func (rs *SchemaLoaderService) IsValidationEnabled() bool {
+	if rs.features == nil {
+		return false
+	}
+	return rs.features.IsEnabled(featuremgmt.FlagDashboardSchemaValidation)
+}
+
+// DashboardValidate validates a dashboard with the versions of the dashboard schema, from the latest to the oldest.
+// A dashboard matching an older version is migrated to the latest one, otherwise it is returned unchanged.
+func (rs *SchemaLoaderService) DashboardValidate(input *simplejson.Json) (*simplejson.Json, error) {
+	val, _ := input.Map()
+	data, err := json.Marshal(removeNils(val))
+	if err != nil {
+		return input, err
+	}
+
+	latest := schema.Find(rs.DashFamily, schema.Latest())
+	latestErr := latest.Validate(schema.Resource{Value: string(data)})
+	if latestErr == nil {
+		return input, nil
+	}
+
+	arr := schema.AsArray(rs.DashFamily)
+	for o := len(arr) - 1; o >= 0; o-- {
+		for i := len(arr[o]) - 1; i >= 0; i-- {
+			sch := arr[o][i]
+			if sch == latest {
+				continue
+			}
+			if err := sch.Validate(schema.Resource{Value: string(data)}); err == nil {
+				return rs.migrateDashboard(sch, data)
+			}
+		}
+	}
+
+	return input, &ValidationError{
+		Version: schemaVersion(latest),
+		Errors:  fieldErrors(latest, latestErr),
+	}
+}
+
+// migrateDashboard migrates a dashboard valid with respect to a schema to the latest version of the schema.
+func (rs *SchemaLoaderService) migrateDashboard(sch schema.VersionedCueSchema, data []byte) (*simplejson.Json, error) {
+	rs.log.Debug("Migrating dashboard to the latest schema", "from", schemaVersion(sch))
+
+	res := schema.Resource{Value: sch.CUE().Context().CompileBytes(data)}
+	for sch.Successor() != nil {
+		var next schema.VersionedCueSchema
+		var err error
+		res, next, err = sch.Migrate(res)
+		if err != nil {
+			return nil, fmt.Errorf("failed to migrate dashboard from schema version %s: %w", schemaVersion(sch), err)
+		}
+		if next == nil {
+			return nil, fmt.Errorf("failed to migrate dashboard from schema version %s: no migration to the next version", schemaVersion(sch))
+		}
+		sch = next
+	}
+
+	value, ok := res.Value.(cue.Value)
+	if !ok {
+		return nil, fmt.Errorf("unexpected migrated dashboard of type %T", res.Value)
+	}
+	out, err := value.MarshalJSON()
+	if err != nil {
+		return nil, fmt.Errorf("failed to migrate dashboard to schema version %s: %w", schemaVersion(sch), err)
+	}
+	return simplejson.NewJson(out)
+}
+
+// fieldErrors converts the errors of a validation to errors at the paths of the dashboard, relative to the schema.
+// The summaries of disjunctions are skipped as each of their branches is reported.
+func fieldErrors(sch schema.VersionedCueSchema, err error) []FieldError {
+	prefix := len(sch.CUE().Path().Selectors())
+	seen := map[FieldError]bool{}
+	var result []FieldError
+	for _, e := range errs.Errors(err) {
+		format, args := e.Msg()
+		message := fmt.Sprintf(format, args...)
+		if strings.HasSuffix(message, "errors in empty disjunction:") {
+			continue
+		}
+
+		path := e.Path()
+		if len(path) >= prefix {
+			path = path[prefix:]
+		}
+		fieldErr := FieldError{Path: strings.Join(path, "."), Message: message}
+		if !seen[fieldErr] {
+			seen[fieldErr] = true
+			result = append(result, fieldErr)
+		}
+	}
+	if len(result) == 0 {
+		result = append(result, FieldError{Message: err.Error()})
+	}
+	return result
+}
+
+func schemaVersion(sch schema.VersionedCueSchema) string {
+	major, minor := sch.Version()
+	return fmt.Sprintf("%d.%d", major, minor)
+}
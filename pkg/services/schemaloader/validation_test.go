@@ -0,0 +1,129 @@
+package schemaloader
+
+import (
+	"errors"
+	"testing"
+
+	"cuelang.org/go/cue"
+	"cuelang.org/go/cue/cuecontext"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/schema"
+	"github.com/grafana/grafana/pkg/services/featuremgmt"
+)
+
+func TestDashboardValidate(t *testing.T) {
+	s, err := ProvideService(featuremgmt.WithFeatures(featuremgmt.FlagDashboardSchemaValidation))
+	require.NoError(t, err)
+	require.True(t, s.IsValidationEnabled())
+
+	t.Run("valid dashboard is unchanged", func(t *testing.T) {
+		input := simplejson.NewFromAny(map[string]interface{}{
+			"title":         "Servers",
+			"schemaVersion": 30,
+			"graphTooltip":  1,
+			"description":   nil,
+		})
+		output, err := s.DashboardValidate(input)
+		require.NoError(t, err)
+		assert.Same(t, input, output)
+	})
+
+	t.Run("invalid dashboard has errors for each field", func(t *testing.T) {
+		input := simplejson.NewFromAny(map[string]interface{}{
+			"title":        3,
+			"style":        "blue",
+			"graphTooltip": 5,
+		})
+		_, err := s.DashboardValidate(input)
+
+		var validationErr *ValidationError
+		require.True(t, errors.As(err, &validationErr))
+		assert.Equal(t, "0.0", validationErr.Version)
+
+		paths := map[string]bool{}
+		for _, fieldErr := range validationErr.Errors {
+			paths[fieldErr.Path] = true
+			assert.NotContains(t, fieldErr.Message, "empty disjunction")
+		}
+		assert.Equal(t, map[string]bool{"title": true, "style": true, "graphTooltip": true}, paths)
+		assert.Contains(t, err.Error(), "graphTooltip: invalid value 5 (out of bound <=2)")
+	})
+
+	t.Run("disabled without the feature toggle", func(t *testing.T) {
+		s, err := ProvideService(featuremgmt.WithFeatures())
+		require.NoError(t, err)
+		assert.False(t, s.IsValidationEnabled())
+	})
+}
+
+func TestDashboardValidateMigratesOlderVersions(t *testing.T) {
+	ctx := cuecontext.New()
+	v1 := &testSchema{actual: ctx.CompileString(`{title: string, schemaVersion: >=2}`), minor: 1}
+	v0 := &testSchema{
+		actual: ctx.CompileString(`{title: string, schemaVersion: 1}`),
+		next:   v1,
+		migrate: func(fields map[string]interface{}) {
+			fields["schemaVersion"] = 2
+		},
+	}
+	s := &SchemaLoaderService{DashFamily: v0, log: log.New("schemaloader.test")}
+
+	output, err := s.DashboardValidate(simplejson.NewFromAny(map[string]interface{}{"title": "Servers", "schemaVersion": 1}))
+	require.NoError(t, err)
+	assert.Equal(t, "Servers", output.Get("title").MustString())
+	assert.Equal(t, int64(2), output.Get("schemaVersion").MustInt64())
+
+	_, err = s.DashboardValidate(simplejson.NewFromAny(map[string]interface{}{"title": "Servers", "schemaVersion": 0}))
+	var validationErr *ValidationError
+	require.True(t, errors.As(err, &validationErr))
+	assert.Equal(t, "0.1", validationErr.Version)
+	assert.Equal(t, "schemaVersion", validationErr.Errors[0].Path)
+}
+
+// testSchema is a schema version with an explicit migration to the next version.
+type testSchema struct {
+	actual  cue.Value
+	minor   int
+	next    *testSchema
+	migrate func(fields map[string]interface{})
+}
+
+func (ts *testSchema) Validate(r schema.Resource) error {
+	rv := ts.actual.Context().CompileString(r.Value.(string))
+	if rv.Err() != nil {
+		return rv.Err()
+	}
+	return ts.actual.Unify(rv).Validate(cue.Concrete(true))
+}
+
+func (ts *testSchema) Migrate(r schema.Resource) (schema.Resource, schema.VersionedCueSchema, error) {
+	if ts.next == nil {
+		return r, nil, nil
+	}
+	var fields map[string]interface{}
+	if err := r.Value.(cue.Value).Decode(&fields); err != nil {
+		return r, nil, err
+	}
+	ts.migrate(fields)
+	v := ts.actual.Context().Encode(fields)
+	return schema.Resource{Value: v}, ts.next, v.Err()
+}
+
+func (ts *testSchema) Successor() schema.VersionedCueSchema {
+	if ts.next == nil {
+		return nil
+	}
+	return ts.next
+}
+
+func (ts *testSchema) CUE() cue.Value {
+	return ts.actual
+}
+
+func (ts *testSchema) Version() (int, int) {
+	return 0, ts.minor
+}
@@ -0,0 +1,136 @@
+package fulltext
+
+import (
+	"sort"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/models"
+)
+
+// Document is the indexed content of a dashboard.
+type Document struct {
+	Title             string   `json:"title"`
+	Description       string   `json:"description"`
+	PanelTitles       []string `json:"panelTitles"`
+	PanelDescriptions []string `json:"panelDescriptions"`
+	// DatasourceUIDs contains datasources referenced by panels, queries and
+	// template variables. Dashboards referencing datasources by name contain
+	// the name instead.
+	DatasourceUIDs []string `json:"datasourceUids"`
+	// Queries contains the string values of panel queries, such as
+	// expressions and raw SQL, and queries of template variables.
+	Queries   []string `json:"queries"`
+	Variables []string `json:"variables"`
+}
+
+// NewDocument extracts the content of a dashboard to index.
+func NewDocument(dash *models.Dashboard) Document {
+	e := &extractor{
+		datasources: map[string]struct{}{},
+		queries:     map[string]struct{}{},
+	}
+	doc := Document{Title: dash.Title}
+	if dash.Data == nil {
+		return doc
+	}
+	doc.Description = dash.Data.Get("description").MustString()
+
+	panels := dash.Data.Get("panels").MustArray()
+	// Dashboards of schema version before 16 keep panels in rows.
+	for _, row := range dash.Data.Get("rows").MustArray() {
+		panels = append(panels, simplejson.NewFromAny(row).Get("panels").MustArray()...)
+	}
+	for _, p := range panels {
+		e.panel(simplejson.NewFromAny(p))
+	}
+
+	for _, v := range dash.Data.Get("templating").Get("list").MustArray() {
+		variable := simplejson.NewFromAny(v)
+		if name := variable.Get("name").MustString(); name != "" {
+			doc.Variables = append(doc.Variables, name)
+		}
+		e.datasource(variable.Get("datasource"))
+		e.query(variable.Get("query").Interface())
+		e.query(variable.Get("definition").MustString())
+	}
+
+	doc.PanelTitles = e.panelTitles
+	doc.PanelDescriptions = e.panelDescriptions
+	doc.DatasourceUIDs = sortedKeys(e.datasources)
+	doc.Queries = sortedKeys(e.queries)
+	return doc
+}
+
+type extractor struct {
+	panelTitles       []string
+	panelDescriptions []string
+	datasources       map[string]struct{}
+	queries           map[string]struct{}
+}
+
+func (e *extractor) panel(panel *simplejson.Json) {
+	if title := panel.Get("title").MustString(); title != "" {
+		e.panelTitles = append(e.panelTitles, title)
+	}
+	if description := panel.Get("description").MustString(); description != "" {
+		e.panelDescriptions = append(e.panelDescriptions, description)
+	}
+	e.datasource(panel.Get("datasource"))
+	for _, t := range panel.Get("targets").MustArray() {
+		target := simplejson.NewFromAny(t)
+		e.datasource(target.Get("datasource"))
+		e.query(target.Interface())
+	}
+	// Collapsed rows contain their panels.
+	for _, p := range panel.Get("panels").MustArray() {
+		e.panel(simplejson.NewFromAny(p))
+	}
+}
+
+// datasource adds a datasource reference, which is either an object with a
+// UID or a datasource name in older dashboards.
+func (e *extractor) datasource(ref *simplejson.Json) {
+	uid, ok := ref.Interface().(string)
+	if !ok {
+		uid = ref.Get("uid").MustString()
+	}
+	// Template variables such as ${ds} are resolved at query time.
+	if uid == "" || uid[0] == '$' {
+		return
+	}
+	e.datasources[uid] = struct{}{}
+}
+
+// query adds the string values of a query model, skipping datasource
+// references and fields which aren't part of the query.
+func (e *extractor) query(v interface{}) {
+	switch value := v.(type) {
+	case string:
+		if value != "" {
+			e.queries[value] = struct{}{}
+		}
+	case map[string]interface{}:
+		for k, item := range value {
+			if k == "datasource" || k == "refId" || k == "key" {
+				continue
+			}
+			e.query(item)
+		}
+	case []interface{}:
+		for _, item := range value {
+			e.query(item)
+		}
+	}
+}
+
+func sortedKeys(m map[string]struct{}) []string {
+	if len(m) == 0 {
+		return nil
+	}
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,324 @@
+// Package fulltext provides a full-text index over the content of dashboards,
+// such as panel titles, descriptions, queries and template variables, which
+// can't be searched efficiently in SQL.
+//
+// The index is kept in memory, one bleve index per organization. The index of
+// an organization is built on its first search from all its dashboards. It is
+// updated when dashboards are saved or deleted on this instance, and from the
+// dashboards updated in the database since the last sync before a search, so
+// that saves on other instances are picked up. It is rebuilt periodically to
+// drop the dashboards deleted on other instances.
+package fulltext
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/blevesearch/bleve/v2"
+	"github.com/blevesearch/bleve/v2/mapping"
+	"github.com/blevesearch/bleve/v2/search/query"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/models"
+)
+
+var logger = log.New("search.fulltext")
+
+const (
+	// BatchSize is the maximum number of dashboards returned by a search. More
+	// results are fetched by paging.
+	BatchSize = 500
+
+	// syncInterval is how often an organization index is synced with the
+	// dashboards updated in the database.
+	syncInterval = 10 * time.Second
+	// syncOverlap is subtracted from the time of the last sync when looking
+	// for updated dashboards, to account for clock skew between instances and
+	// transactions committed after the sync.
+	syncOverlap = time.Minute
+	// rebuildInterval is how often an organization index is rebuilt.
+	rebuildInterval = time.Hour
+)
+
+// LoadFunc returns the dashboards of an organization updated after since,
+// excluding folders. All dashboards are returned when since is zero.
+type LoadFunc func(ctx context.Context, orgID int64, since time.Time) ([]*models.Dashboard, error)
+
+// Query is a full-text search. Filters limit results to dashboards matching
+// any of their values and are combined with the text query.
+type Query struct {
+	// Text is matched against dashboard titles, descriptions, panel titles,
+	// panel descriptions, queries and template variable names. Words in titles
+	// are also matched by prefix.
+	Text           string
+	DatasourceUIDs []string
+	Variables      []string
+}
+
+// IsEmpty returns true if the query has neither text nor filters.
+func (q Query) IsEmpty() bool {
+	return strings.TrimSpace(q.Text) == "" && len(q.DatasourceUIDs) == 0 && len(q.Variables) == 0
+}
+
+// Result is a dashboard matching a search.
+type Result struct {
+	ID    int64
+	Score float64
+}
+
+// Index is a full-text index of dashboards.
+type Index struct {
+	load LoadFunc
+	now  func() time.Time
+
+	mu   sync.Mutex
+	orgs map[int64]*orgIndex
+}
+
+// orgIndex is the index of an organization. Its mutex is held while the index
+// is built or synced, so that updates following concurrent dashboard saves in
+// the organization wait for it.
+type orgIndex struct {
+	mu     sync.Mutex
+	index  bleve.Index
+	built  time.Time
+	synced time.Time
+}
+
+func NewIndex(load LoadFunc) *Index {
+	return &Index{
+		load: load,
+		now:  time.Now,
+		orgs: map[int64]*orgIndex{},
+	}
+}
+
+// Search returns the IDs of dashboards matching a query, ordered by relevance.
+// At most size results are returned, starting with the from-th one.
+func (i *Index) Search(ctx context.Context, orgID int64, q Query, from, size int) ([]Result, error) {
+	if q.IsEmpty() {
+		return nil, nil
+	}
+	index, err := i.orgIndex(ctx, orgID)
+	if err != nil {
+		return nil, err
+	}
+	res, err := index.SearchInContext(ctx, bleve.NewSearchRequestOptions(buildQuery(q), size, from, false))
+	if err != nil {
+		return nil, fmt.Errorf("failed to search dashboards: %w", err)
+	}
+	results := make([]Result, 0, len(res.Hits))
+	for _, hit := range res.Hits {
+		id, err := strconv.ParseInt(hit.ID, 10, 64)
+		if err != nil {
+			continue
+		}
+		results = append(results, Result{ID: id, Score: hit.Score})
+	}
+	return results, nil
+}
+
+// Update indexes a saved dashboard. Dashboards of organizations which haven't
+// been searched yet are indexed when the organization index is built.
+func (i *Index) Update(dash *models.Dashboard) {
+	if dash == nil || dash.IsFolder {
+		return
+	}
+	org := i.get(dash.OrgId)
+	if org == nil {
+		return
+	}
+	org.mu.Lock()
+	defer org.mu.Unlock()
+	if org.index == nil {
+		return
+	}
+	if err := org.index.Index(strconv.FormatInt(dash.Id, 10), NewDocument(dash)); err != nil {
+		logger.Error("Failed to index dashboard", "error", err, "orgId", dash.OrgId, "dashboardId", dash.Id)
+	}
+}
+
+// Delete removes dashboards from the index.
+func (i *Index) Delete(orgID int64, ids ...int64) {
+	org := i.get(orgID)
+	if org == nil {
+		return
+	}
+	org.mu.Lock()
+	defer org.mu.Unlock()
+	if org.index == nil {
+		return
+	}
+	for _, id := range ids {
+		if err := org.index.Delete(strconv.FormatInt(id, 10)); err != nil {
+			logger.Error("Failed to delete dashboard from index", "error", err, "orgId", orgID, "dashboardId", id)
+		}
+	}
+}
+
+func (i *Index) get(orgID int64) *orgIndex {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	return i.orgs[orgID]
+}
+
+// orgIndex returns the index of an organization, built or synced with the
+// database if needed.
+func (i *Index) orgIndex(ctx context.Context, orgID int64) (bleve.Index, error) {
+	i.mu.Lock()
+	org, ok := i.orgs[orgID]
+	if !ok {
+		org = &orgIndex{}
+		i.orgs[orgID] = org
+	}
+	i.mu.Unlock()
+
+	org.mu.Lock()
+	defer org.mu.Unlock()
+
+	now := i.now()
+	switch {
+	case org.index == nil || now.Sub(org.built) > rebuildInterval:
+		if err := i.build(ctx, orgID, org, now); err != nil {
+			return nil, err
+		}
+	case now.Sub(org.synced) > syncInterval:
+		if err := i.sync(ctx, orgID, org, now); err != nil {
+			return nil, err
+		}
+	}
+	return org.index, nil
+}
+
+// build indexes all dashboards of an organization. Must be called with the
+// organization mutex held.
+func (i *Index) build(ctx context.Context, orgID int64, org *orgIndex, now time.Time) error {
+	dashboards, err := i.load(ctx, orgID, time.Time{})
+	if err != nil {
+		return fmt.Errorf("failed to load dashboards to index: %w", err)
+	}
+	index, err := bleve.NewMemOnly(indexMapping())
+	if err != nil {
+		return err
+	}
+	if err := indexDashboards(index, dashboards); err != nil {
+		return err
+	}
+	logger.Debug("Built dashboard index", "orgId", orgID, "dashboards", len(dashboards))
+	// The previous index isn't closed as searches may still use it, in-memory
+	// indexes don't hold any resources besides memory.
+	org.index, org.built, org.synced = index, now, now
+	return nil
+}
+
+// sync indexes the dashboards of an organization updated in the database
+// since the last sync. Must be called with the organization mutex held.
+func (i *Index) sync(ctx context.Context, orgID int64, org *orgIndex, now time.Time) error {
+	dashboards, err := i.load(ctx, orgID, org.synced.Add(-syncOverlap))
+	if err != nil {
+		return fmt.Errorf("failed to load dashboards to index: %w", err)
+	}
+	if err := indexDashboards(org.index, dashboards); err != nil {
+		return err
+	}
+	org.synced = now
+	return nil
+}
+
+func indexDashboards(index bleve.Index, dashboards []*models.Dashboard) error {
+	batch := index.NewBatch()
+	for _, dash := range dashboards {
+		if err := batch.Index(strconv.FormatInt(dash.Id, 10), NewDocument(dash)); err != nil {
+			return err
+		}
+	}
+	if err := index.Batch(batch); err != nil {
+		return fmt.Errorf("failed to index dashboards: %w", err)
+	}
+	return nil
+}
+
+func indexMapping() mapping.IndexMapping {
+	text := bleve.NewTextFieldMapping()
+	text.Store = false
+	keyword := bleve.NewKeywordFieldMapping()
+	keyword.Store = false
+
+	doc := bleve.NewDocumentStaticMapping()
+	doc.AddFieldMappingsAt("title", text)
+	doc.AddFieldMappingsAt("description", text)
+	doc.AddFieldMappingsAt("panelTitles", text)
+	doc.AddFieldMappingsAt("panelDescriptions", text)
+	doc.AddFieldMappingsAt("queries", text)
+	doc.AddFieldMappingsAt("datasourceUids", keyword)
+	doc.AddFieldMappingsAt("variables", keyword)
+
+	m := bleve.NewIndexMapping()
+	m.DefaultMapping = doc
+	return m
+}
+
+// textFields are fields matched by the text of a query with their boosts.
+var textFields = []struct {
+	name   string
+	boost  float64
+	prefix bool
+}{
+	{name: "title", boost: 4, prefix: true},
+	{name: "panelTitles", boost: 2, prefix: true},
+	{name: "description", boost: 1.5},
+	{name: "panelDescriptions", boost: 1},
+	{name: "queries", boost: 1},
+}
+
+func buildQuery(q Query) query.Query {
+	var conjuncts []query.Query
+
+	if text := strings.TrimSpace(q.Text); text != "" {
+		var disjuncts []query.Query
+		for _, f := range textFields {
+			match := bleve.NewMatchQuery(text)
+			match.SetField(f.name)
+			match.SetBoost(f.boost)
+			disjuncts = append(disjuncts, match)
+			if !f.prefix {
+				continue
+			}
+			// Prefix queries aren't analyzed, so terms are lower cased like
+			// by the standard analyzer.
+			for _, word := range strings.Fields(strings.ToLower(text)) {
+				prefix := bleve.NewPrefixQuery(word)
+				prefix.SetField(f.name)
+				prefix.SetBoost(f.boost / 2)
+				disjuncts = append(disjuncts, prefix)
+			}
+		}
+		variable := bleve.NewTermQuery(text)
+		variable.SetField("variables")
+		disjuncts = append(disjuncts, variable)
+		conjuncts = append(conjuncts, bleve.NewDisjunctionQuery(disjuncts...))
+	}
+
+	if len(q.DatasourceUIDs) > 0 {
+		conjuncts = append(conjuncts, termsQuery("datasourceUids", q.DatasourceUIDs))
+	}
+	if len(q.Variables) > 0 {
+		conjuncts = append(conjuncts, termsQuery("variables", q.Variables))
+	}
+
+	return bleve.NewConjunctionQuery(conjuncts...)
+}
+
+func termsQuery(field string, terms []string) query.Query {
+	disjuncts := make([]query.Query, 0, len(terms))
+	for _, term := range terms {
+		q := bleve.NewTermQuery(term)
+		q.SetField(field)
+		disjuncts = append(disjuncts, q)
+	}
+	return bleve.NewDisjunctionQuery(disjuncts...)
+}
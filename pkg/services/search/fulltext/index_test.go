@@ -0,0 +1,186 @@
+package fulltext
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/models"
+)
+
+func testDashboard(id int64, data string) *models.Dashboard {
+	json, err := simplejson.NewJson([]byte(data))
+	if err != nil {
+		panic(err)
+	}
+	dash := models.NewDashboardFromJson(json)
+	dash.Id = id
+	dash.OrgId = 1
+	return dash
+}
+
+func TestNewDocument(t *testing.T) {
+	dash := testDashboard(1, `{
+		"title": "Services",
+		"description": "Service health",
+		"panels": [
+			{
+				"title": "Requests",
+				"description": "Requests per second",
+				"datasource": {"type": "prometheus", "uid": "prom"},
+				"targets": [{"refId": "A", "expr": "rate(http_requests_total[5m])", "datasource": {"uid": "prom"}}]
+			},
+			{
+				"type": "row",
+				"title": "Logs",
+				"collapsed": true,
+				"panels": [
+					{"title": "Errors", "datasource": "Loki", "targets": [{"refId": "A", "expr": "{job=\"api\"} |= \"error\""}]}
+				]
+			},
+			{"title": "By variable", "datasource": "${ds}"}
+		],
+		"rows": [
+			{"panels": [{"title": "Legacy", "targets": [{"refId": "A", "rawSql": "SELECT 1"}]}]}
+		],
+		"templating": {
+			"list": [
+				{"name": "job", "datasource": {"uid": "prom"}, "query": {"query": "label_values(job)"}},
+				{"name": "ds", "type": "datasource", "query": "prometheus"}
+			]
+		}
+	}`)
+
+	require.Equal(t, Document{
+		Title:             "Services",
+		Description:       "Service health",
+		PanelTitles:       []string{"Requests", "Logs", "Errors", "By variable", "Legacy"},
+		PanelDescriptions: []string{"Requests per second"},
+		DatasourceUIDs:    []string{"Loki", "prom"},
+		Queries: []string{
+			"SELECT 1",
+			"label_values(job)",
+			"prometheus",
+			"rate(http_requests_total[5m])",
+			`{job="api"} |= "error"`,
+		},
+		Variables: []string{"job", "ds"},
+	}, NewDocument(dash))
+}
+
+func TestIndex(t *testing.T) {
+	dashboards := []*models.Dashboard{
+		testDashboard(1, `{"title": "Kubernetes cluster", "panels": [{"title": "CPU usage", "targets": [{"expr": "node_cpu_seconds_total"}]}]}`),
+		testDashboard(2, `{"title": "Node exporter", "description": "CPU, memory and disk usage of nodes", "panels": [{"title": "Memory", "datasource": {"uid": "prom"}}]}`),
+		testDashboard(3, `{"title": "Logs", "panels": [{"title": "Errors", "datasource": {"uid": "loki"}}], "templating": {"list": [{"name": "namespace"}]}}`),
+	}
+	loads := 0
+	index := NewIndex(func(_ context.Context, orgID int64, since time.Time) ([]*models.Dashboard, error) {
+		loads++
+		require.Equal(t, int64(1), orgID)
+		require.True(t, since.IsZero())
+		return dashboards, nil
+	})
+
+	ids := func(results []Result) []int64 {
+		var ids []int64
+		for _, r := range results {
+			ids = append(ids, r.ID)
+		}
+		return ids
+	}
+	search := func(q Query) []int64 {
+		results, err := index.Search(context.Background(), 1, q, 0, BatchSize)
+		require.NoError(t, err)
+		return ids(results)
+	}
+
+	t.Run("ranks title matches first", func(t *testing.T) {
+		require.Equal(t, []int64{1, 2}, search(Query{Text: "cpu"}))
+		require.Equal(t, []int64{2, 1}, search(Query{Text: "exporter usage"}))
+	})
+
+	t.Run("matches title prefixes", func(t *testing.T) {
+		require.Equal(t, []int64{1}, search(Query{Text: "kube"}))
+	})
+
+	t.Run("matches queries", func(t *testing.T) {
+		require.Equal(t, []int64{1}, search(Query{Text: "node_cpu_seconds_total"}))
+	})
+
+	t.Run("filters", func(t *testing.T) {
+		require.Equal(t, []int64{2}, search(Query{DatasourceUIDs: []string{"prom"}}))
+		require.ElementsMatch(t, []int64{2, 3}, search(Query{DatasourceUIDs: []string{"prom", "loki"}}))
+		require.Equal(t, []int64{3}, search(Query{Variables: []string{"namespace"}}))
+		require.Empty(t, search(Query{Text: "cpu", DatasourceUIDs: []string{"loki"}}))
+	})
+
+	t.Run("empty query", func(t *testing.T) {
+		require.Empty(t, search(Query{Text: " "}))
+	})
+
+	t.Run("update and delete", func(t *testing.T) {
+		index.Update(testDashboard(4, `{"title": "Network", "panels": [{"title": "Bandwidth"}]}`))
+		require.Equal(t, []int64{4}, search(Query{Text: "bandwidth"}))
+
+		index.Update(testDashboard(4, `{"title": "Network", "panels": [{"title": "Packets"}]}`))
+		require.Empty(t, search(Query{Text: "bandwidth"}))
+		require.Equal(t, []int64{4}, search(Query{Text: "packets"}))
+
+		index.Update(testDashboard(5, `{"title": "Network packets"}`))
+		index.Delete(1, 4, 5)
+		require.Empty(t, search(Query{Text: "packets"}))
+	})
+
+	t.Run("pages results", func(t *testing.T) {
+		results, err := index.Search(context.Background(), 1, Query{Text: "cpu"}, 1, 1)
+		require.NoError(t, err)
+		require.Equal(t, []int64{2}, ids(results))
+	})
+
+	require.Equal(t, 1, loads)
+
+	t.Run("ignores organizations which weren't searched", func(t *testing.T) {
+		dash := testDashboard(5, `{"title": "Other"}`)
+		dash.OrgId = 2
+		index.Update(dash)
+		index.Delete(2, 5)
+		require.Equal(t, 1, loads)
+	})
+}
+
+func TestIndex_Sync(t *testing.T) {
+	now := time.Unix(100000, 0)
+	updated := []*models.Dashboard{testDashboard(1, `{"title": "Kubernetes cluster"}`)}
+	var since []time.Time
+	index := NewIndex(func(_ context.Context, _ int64, s time.Time) ([]*models.Dashboard, error) {
+		since = append(since, s)
+		return updated, nil
+	})
+	index.now = func() time.Time { return now }
+
+	search := func(text string) int {
+		results, err := index.Search(context.Background(), 1, Query{Text: text}, 0, BatchSize)
+		require.NoError(t, err)
+		return len(results)
+	}
+
+	require.Equal(t, 1, search("kubernetes"))
+	require.Equal(t, []time.Time{{}}, since)
+
+	// Dashboards saved on other instances are indexed on the next sync.
+	updated = []*models.Dashboard{testDashboard(2, `{"title": "Kubernetes nodes"}`)}
+	require.Equal(t, 1, search("kubernetes"))
+	now = now.Add(2 * syncInterval)
+	require.Equal(t, 2, search("kubernetes"))
+	require.Equal(t, time.Unix(100000, 0).Add(-syncOverlap), since[1])
+
+	// Dashboards deleted on other instances are dropped when the index is rebuilt.
+	updated = []*models.Dashboard{testDashboard(2, `{"title": "Kubernetes nodes"}`)}
+	now = now.Add(rebuildInterval)
+	require.Equal(t, 1, search("kubernetes"))
+	require.True(t, since[2].IsZero())
+}
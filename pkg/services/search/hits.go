@@ -26,6 +26,8 @@ type Hit struct {
 	FolderURL    string   `json:"folderUrl,omitempty"`
 	SortMeta     int64    `json:"sortMeta"`
 	SortMetaName string   `json:"sortMetaName,omitempty"`
+	// Score is the relevance of a full-text search result.
+	Score float64 `json:"score,omitempty"`
 }
 
 type HitList []*Hit
@@ -24,24 +24,35 @@ func ProvideService(cfg *setting.Cfg, bus bus.Bus) *SearchService {
 }
 
 type Query struct {
-	Title        string
-	Tags         []string
-	OrgId        int64
-	SignedInUser *models.SignedInUser
-	Limit        int64
-	Page         int64
-	IsStarred    bool
-	Type         string
-	DashboardIds []int64
-	FolderIds    []int64
-	Permission   models.PermissionType
-	Sort         string
+	Title          string
+	FullText       string
+	DatasourceUIDs []string
+	Variables      []string
+	Tags           []string
+	OrgId          int64
+	SignedInUser   *models.SignedInUser
+	Limit          int64
+	Page           int64
+	IsStarred      bool
+	Type           string
+	DashboardIds   []int64
+	FolderIds      []int64
+	Permission     models.PermissionType
+	Sort           string
 
 	Result HitList
 }
 
 type FindPersistedDashboardsQuery struct {
-	Title        string
+	Title string
+	// FullText is matched against the content of dashboards, such as panel
+	// titles, descriptions, queries and template variables. Results are
+	// ordered by relevance unless Sort is set.
+	FullText string
+	// DatasourceUIDs limits results to dashboards using any of the datasources.
+	DatasourceUIDs []string
+	// Variables limits results to dashboards with any of the template variables.
+	Variables    []string
 	OrgId        int64
 	SignedInUser *models.SignedInUser
 	IsStarred    bool
@@ -72,16 +83,19 @@ type SearchService struct {
 
 func (s *SearchService) SearchHandler(ctx context.Context, query *Query) error {
 	dashboardQuery := FindPersistedDashboardsQuery{
-		Title:        query.Title,
-		SignedInUser: query.SignedInUser,
-		IsStarred:    query.IsStarred,
-		DashboardIds: query.DashboardIds,
-		Type:         query.Type,
-		FolderIds:    query.FolderIds,
-		Tags:         query.Tags,
-		Limit:        query.Limit,
-		Page:         query.Page,
-		Permission:   query.Permission,
+		Title:          query.Title,
+		FullText:       query.FullText,
+		DatasourceUIDs: query.DatasourceUIDs,
+		Variables:      query.Variables,
+		SignedInUser:   query.SignedInUser,
+		IsStarred:      query.IsStarred,
+		DashboardIds:   query.DashboardIds,
+		Type:           query.Type,
+		FolderIds:      query.FolderIds,
+		Tags:           query.Tags,
+		Limit:          query.Limit,
+		Page:           query.Page,
+		Permission:     query.Permission,
 	}
 
 	if sortOpt, exists := s.sortOptions[query.Sort]; exists {
@@ -93,7 +107,8 @@ func (s *SearchService) SearchHandler(ctx context.Context, query *Query) error {
 	}
 
 	hits := dashboardQuery.Result
-	if query.Sort == "" {
+	// Full-text results are ordered by relevance.
+	if query.Sort == "" && query.FullText == "" {
 		hits = sortedHits(hits)
 	}
 
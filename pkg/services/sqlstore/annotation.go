@@ -2,8 +2,10 @@ package sqlstore
 
 import (
 	"bytes"
+	"context"
 	"errors"
 	"fmt"
+	"strconv"
 	"strings"
 	"time"
 
@@ -31,8 +33,8 @@ func validateTimeRange(item *annotations.Item) error {
 type SQLAnnotationRepo struct {
 }
 
-func (r *SQLAnnotationRepo) Save(item *annotations.Item) error {
-	return inTransaction(func(sess *DBSession) error {
+func (r *SQLAnnotationRepo) Save(ctx context.Context, item *annotations.Item) error {
+	return inTransactionCtx(ctx, func(sess *DBSession) error {
 		tags := models.ParseTagPairs(item.Tags)
 		item.Tags = models.JoinTagPairs(tags)
 		item.Created = timeNow().UnixNano() / int64(time.Millisecond)
@@ -64,8 +66,8 @@ func (r *SQLAnnotationRepo) Save(item *annotations.Item) error {
 	})
 }
 
-func (r *SQLAnnotationRepo) Update(item *annotations.Item) error {
-	return inTransaction(func(sess *DBSession) error {
+func (r *SQLAnnotationRepo) Update(ctx context.Context, item *annotations.Item) error {
+	return inTransactionCtx(ctx, func(sess *DBSession) error {
 		var (
 			isExist bool
 			err     error
@@ -117,7 +119,7 @@ func (r *SQLAnnotationRepo) Update(item *annotations.Item) error {
 	})
 }
 
-func (r *SQLAnnotationRepo) Find(query *annotations.ItemQuery) ([]*annotations.ItemDTO, error) {
+func (r *SQLAnnotationRepo) Find(ctx context.Context, query *annotations.ItemQuery) ([]*annotations.ItemDTO, error) {
 	var sql bytes.Buffer
 	params := make([]interface{}, 0)
 
@@ -146,6 +148,39 @@ func (r *SQLAnnotationRepo) Find(query *annotations.ItemQuery) ([]*annotations.I
 			SELECT a.id from annotation a
 		`)
 
+	filter, filterParams, err := annotationFilter(query)
+	if err != nil {
+		return nil, err
+	}
+	sql.WriteString(filter)
+	params = append(params, filterParams...)
+
+	if query.Limit == 0 {
+		query.Limit = 100
+	}
+
+	// order of ORDER BY arguments match the order of a sql index for performance
+	sql.WriteString(" ORDER BY a.org_id, a.epoch_end DESC, a.epoch DESC, a.id DESC" + dialect.Limit(query.Limit) + " ) dt on dt.id = annotation.id")
+	sql.WriteString(" ORDER BY annotation.epoch_end DESC, annotation.epoch DESC, annotation.id DESC")
+
+	items := make([]*annotations.ItemDTO, 0)
+
+	err = withDbSession(ctx, x, func(sess *DBSession) error {
+		return sess.SQL(sql.String(), params...).Find(&items)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return items, nil
+}
+
+// annotationFilter returns the WHERE clause of annotations, aliased a,
+// matching the query.
+func annotationFilter(query *annotations.ItemQuery) (string, []interface{}, error) {
+	var sql bytes.Buffer
+	params := make([]interface{}, 0)
+
 	sql.WriteString(`WHERE a.org_id = ?`)
 	params = append(params, query.OrgId)
 
@@ -218,24 +253,124 @@ func (r *SQLAnnotationRepo) Find(query *annotations.ItemQuery) ([]*annotations.I
 		}
 	}
 
-	if query.Limit == 0 {
-		query.Limit = 100
+	if query.DashboardUid != "" {
+		sql.WriteString(` AND a.dashboard_id = (SELECT id FROM dashboard WHERE dashboard.org_id = ? AND dashboard.uid = ?)`)
+		params = append(params, query.OrgId, query.DashboardUid)
 	}
 
-	// order of ORDER BY arguments match the order of a sql index for performance
-	sql.WriteString(" ORDER BY a.org_id, a.epoch_end DESC, a.epoch DESC" + dialect.Limit(query.Limit) + " ) dt on dt.id = annotation.id")
+	if query.Text != "" {
+		sql.WriteString(` AND a.text ` + dialect.LikeStr() + ` ? ESCAPE '!'`)
+		params = append(params, "%"+likeEscaper.Replace(query.Text)+"%")
+	}
 
-	items := make([]*annotations.ItemDTO, 0)
+	for _, m := range query.Matchers {
+		matcherSQL, matcherParams, err := tagMatcherFilter(m)
+		if err != nil {
+			return "", nil, err
+		}
+		sql.WriteString(" AND " + matcherSQL)
+		params = append(params, matcherParams...)
+	}
 
-	if err := x.SQL(sql.String(), params...).Find(&items); err != nil {
-		return nil, err
+	if query.Cursor != "" {
+		cursor, err := annotations.ParseCursor(query.Cursor)
+		if err != nil {
+			return "", nil, err
+		}
+		sql.WriteString(` AND (a.epoch_end < ? OR (a.epoch_end = ? AND (a.epoch < ? OR (a.epoch = ? AND a.id < ?))))`)
+		params = append(params, cursor.TimeEnd, cursor.TimeEnd, cursor.Time, cursor.Time, cursor.Id)
 	}
 
-	return items, nil
+	return sql.String(), params, nil
+}
+
+// likeEscaper escapes the wildcards of a LIKE pattern with the escape
+// character '!', which unlike '\' is no escape in string literals of MySQL.
+// '[' is a wildcard of MSSQL.
+var likeEscaper = strings.NewReplacer("!", "!!", "%", "!%", "_", "!_", "[", "![")
+
+// tagMatcherBatchSize is the number of tag IDs of an IN list of a regexp
+// matcher, and of tags loaded at once to match their values.
+const tagMatcherBatchSize = 1000
+
+// matchingTagIDs returns the IDs of the tags with the key of a regexp matcher
+// and a matching value. Tags are loaded in batches, rather than all values of
+// the key at once.
+func matchingTagIDs(m *annotations.TagMatcher) ([]int64, error) {
+	var ids []int64
+	var lastID int64
+	for {
+		var tags []*models.Tag
+		err := x.Table("tag").Cols("id", "value").
+			Where(dialect.Quote("key")+" = ? AND id > ?", m.Key, lastID).
+			OrderBy("id").Limit(tagMatcherBatchSize).Find(&tags)
+		if err != nil {
+			return nil, err
+		}
+		for _, tag := range tags {
+			ok, err := m.Matches(tag.Value)
+			if err != nil {
+				return nil, err
+			}
+			if ok {
+				ids = append(ids, tag.Id)
+			}
+		}
+		if len(tags) < tagMatcherBatchSize {
+			return ids, nil
+		}
+		lastID = tags[len(tags)-1].Id
+	}
 }
 
-func (r *SQLAnnotationRepo) Delete(params *annotations.DeleteParams) error {
-	return inTransaction(func(sess *DBSession) error {
+func joinIDs(ids []int64) string {
+	var sb strings.Builder
+	for i, id := range ids {
+		if i > 0 {
+			sb.WriteString(",")
+		}
+		sb.WriteString(strconv.FormatInt(id, 10))
+	}
+	return sb.String()
+}
+
+// tagMatcherFilter returns the condition of annotations, aliased a, matching m.
+func tagMatcherFilter(m *annotations.TagMatcher) (string, []interface{}, error) {
+	tagKey := `tag.` + dialect.Quote("key")
+	tagValue := `tag.` + dialect.Quote("value")
+	exists := `EXISTS (SELECT 1 FROM annotation_tag at INNER JOIN tag ON tag.id = at.tag_id WHERE at.annotation_id = a.id AND %s)`
+
+	switch m.Type {
+	case annotations.MatchEqual:
+		return fmt.Sprintf(exists, tagKey+` = ? AND `+tagValue+` = ?`), []interface{}{m.Key, m.Value}, nil
+	case annotations.MatchNotEqual:
+		return "NOT " + fmt.Sprintf(exists, tagKey+` = ? AND `+tagValue+` = ?`), []interface{}{m.Key, m.Value}, nil
+	case annotations.MatchRegexp:
+		// Regular expressions differ between databases, so they are matched
+		// against the values of the key here. The IDs of the matching tags are
+		// inlined, as there can be more than the databases allow parameters.
+		ids, err := matchingTagIDs(m)
+		if err != nil {
+			return "", nil, err
+		}
+		if len(ids) == 0 {
+			return "1 = 0", nil, nil
+		}
+		conditions := make([]string, 0, len(ids)/tagMatcherBatchSize+1)
+		for start := 0; start < len(ids); start += tagMatcherBatchSize {
+			end := start + tagMatcherBatchSize
+			if end > len(ids) {
+				end = len(ids)
+			}
+			conditions = append(conditions, `at.tag_id IN (`+joinIDs(ids[start:end])+`)`)
+		}
+		return fmt.Sprintf(exists, `(`+strings.Join(conditions, ` OR `)+`)`), nil, nil
+	}
+	return "", nil, fmt.Errorf("%w: unknown type %q", annotations.ErrInvalidMatcher, m.Type)
+}
+
+func (r *SQLAnnotationRepo) Delete(ctx context.Context, params *annotations.DeleteParams) error {
+	return inTransactionCtx(ctx, func(sess *DBSession) error {
 		var (
 			sql        string
 			annoTagSQL string
@@ -270,7 +405,7 @@ func (r *SQLAnnotationRepo) Delete(params *annotations.DeleteParams) error {
 	})
 }
 
-func (r *SQLAnnotationRepo) FindTags(query *annotations.TagsQuery) (annotations.FindTagsResult, error) {
+func (r *SQLAnnotationRepo) FindTags(ctx context.Context, query *annotations.TagsQuery) (annotations.FindTagsResult, error) {
 	if query.Limit == 0 {
 		query.Limit = 100
 	}
@@ -300,7 +435,10 @@ func (r *SQLAnnotationRepo) FindTags(query *annotations.TagsQuery) (annotations.
 	sql.WriteString(` ` + dialect.Limit(query.Limit))
 
 	var items []*annotations.Tag
-	if err := x.SQL(sql.String(), params...).Find(&items); err != nil {
+	err := withDbSession(ctx, x, func(sess *DBSession) error {
+		return sess.SQL(sql.String(), params...).Find(&items)
+	})
+	if err != nil {
 		return annotations.FindTagsResult{Tags: []*annotations.TagsDTO{}}, err
 	}
 
@@ -318,3 +456,71 @@ func (r *SQLAnnotationRepo) FindTags(query *annotations.TagsQuery) (annotations.
 
 	return annotations.FindTagsResult{Tags: tags}, nil
 }
+
+func (r *SQLAnnotationRepo) CountTags(ctx context.Context, query *annotations.TagCountsQuery) (annotations.TagCountsResult, error) {
+	result := annotations.TagCountsResult{Counts: []*annotations.TagCountDTO{}}
+	if query.Interval <= 0 {
+		return result, annotations.ErrInvalidInterval
+	}
+
+	var sql bytes.Buffer
+	params := []interface{}{query.Interval}
+	tagKey := dialect.Quote("key")
+	tagValue := dialect.Quote("value")
+
+	sql.WriteString(`
+		SELECT
+			counts.` + tagKey + `,
+			counts.` + tagValue + `,
+			counts.bucket,
+			count(*) as count
+		FROM (
+			SELECT
+				count_tag.` + tagKey + `,
+				count_tag.` + tagValue + `,
+				a.epoch - (a.epoch % ?) as bucket
+			FROM annotation a
+			INNER JOIN annotation_tag count_at ON count_at.annotation_id = a.id
+			INNER JOIN tag count_tag ON count_tag.id = count_at.tag_id
+		`)
+
+	filter, filterParams, err := annotationFilter(&query.ItemQuery)
+	if err != nil {
+		return result, err
+	}
+	sql.WriteString(filter)
+	params = append(params, filterParams...)
+
+	sql.WriteString(`) counts GROUP BY counts.` + tagKey + `, counts.` + tagValue + `, counts.bucket`)
+	sql.WriteString(` ORDER BY counts.bucket, counts.` + tagKey + `, counts.` + tagValue)
+	if query.Limit > 0 {
+		sql.WriteString(` ` + dialect.Limit(query.Limit))
+	}
+
+	var items []*struct {
+		Key    string
+		Value  string
+		Bucket int64
+		Count  int64
+	}
+	err = withDbSession(ctx, x, func(sess *DBSession) error {
+		return sess.SQL(sql.String(), params...).Find(&items)
+	})
+	if err != nil {
+		return result, err
+	}
+
+	for _, item := range items {
+		tag := item.Key
+		if len(item.Value) > 0 {
+			tag = item.Key + ":" + item.Value
+		}
+		result.Counts = append(result.Counts, &annotations.TagCountDTO{
+			Tag:   tag,
+			Time:  item.Bucket,
+			Count: item.Count,
+		})
+	}
+
+	return result, nil
+}
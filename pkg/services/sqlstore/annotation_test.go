@@ -4,11 +4,14 @@
 package sqlstore
 
 import (
+	"context"
+	"strconv"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
+	"github.com/grafana/grafana/pkg/models"
 	"github.com/grafana/grafana/pkg/services/annotations"
 )
 
@@ -35,7 +38,7 @@ func TestAnnotations(t *testing.T) {
 			Epoch:       10,
 			Tags:        []string{"outage", "error", "type:outage", "server:server-1"},
 		}
-		err := repo.Save(annotation)
+		err := repo.Save(context.Background(), annotation)
 		require.NoError(t, err)
 		assert.Greater(t, annotation.Id, int64(0))
 		assert.Equal(t, annotation.Epoch, annotation.EpochEnd)
@@ -50,7 +53,7 @@ func TestAnnotations(t *testing.T) {
 			EpochEnd:    20,
 			Tags:        []string{"outage", "error", "type:outage", "server:server-1"},
 		}
-		err = repo.Save(annotation2)
+		err = repo.Save(context.Background(), annotation2)
 		require.NoError(t, err)
 		assert.Greater(t, annotation2.Id, int64(0))
 		assert.Equal(t, int64(20), annotation2.Epoch)
@@ -64,7 +67,7 @@ func TestAnnotations(t *testing.T) {
 			Epoch:  15,
 			Tags:   []string{"deploy"},
 		}
-		err = repo.Save(globalAnnotation1)
+		err = repo.Save(context.Background(), globalAnnotation1)
 		require.NoError(t, err)
 		assert.Greater(t, globalAnnotation1.Id, int64(0))
 
@@ -76,12 +79,12 @@ func TestAnnotations(t *testing.T) {
 			Epoch:  17,
 			Tags:   []string{"rollback"},
 		}
-		err = repo.Save(globalAnnotation2)
+		err = repo.Save(context.Background(), globalAnnotation2)
 		require.NoError(t, err)
 		assert.Greater(t, globalAnnotation2.Id, int64(0))
 
 		t.Run("Can query for annotation by dashboard id", func(t *testing.T) {
-			items, err := repo.Find(&annotations.ItemQuery{
+			items, err := repo.Find(context.Background(), &annotations.ItemQuery{
 				OrgId:       1,
 				DashboardId: 1,
 				From:        0,
@@ -99,7 +102,7 @@ func TestAnnotations(t *testing.T) {
 		})
 
 		t.Run("Can query for annotation by id", func(t *testing.T) {
-			items, err := repo.Find(&annotations.ItemQuery{
+			items, err := repo.Find(context.Background(), &annotations.ItemQuery{
 				OrgId:        1,
 				AnnotationId: annotation2.Id,
 			})
@@ -109,7 +112,7 @@ func TestAnnotations(t *testing.T) {
 		})
 
 		t.Run("Should not find any when item is outside time range", func(t *testing.T) {
-			items, err := repo.Find(&annotations.ItemQuery{
+			items, err := repo.Find(context.Background(), &annotations.ItemQuery{
 				OrgId:       1,
 				DashboardId: 1,
 				From:        12,
@@ -120,7 +123,7 @@ func TestAnnotations(t *testing.T) {
 		})
 
 		t.Run("Should not find one when tag filter does not match", func(t *testing.T) {
-			items, err := repo.Find(&annotations.ItemQuery{
+			items, err := repo.Find(context.Background(), &annotations.ItemQuery{
 				OrgId:       1,
 				DashboardId: 1,
 				From:        1,
@@ -132,7 +135,7 @@ func TestAnnotations(t *testing.T) {
 		})
 
 		t.Run("Should not find one when type filter does not match", func(t *testing.T) {
-			items, err := repo.Find(&annotations.ItemQuery{
+			items, err := repo.Find(context.Background(), &annotations.ItemQuery{
 				OrgId:       1,
 				DashboardId: 1,
 				From:        1,
@@ -144,7 +147,7 @@ func TestAnnotations(t *testing.T) {
 		})
 
 		t.Run("Should find one when all tag filters does match", func(t *testing.T) {
-			items, err := repo.Find(&annotations.ItemQuery{
+			items, err := repo.Find(context.Background(), &annotations.ItemQuery{
 				OrgId:       1,
 				DashboardId: 1,
 				From:        1,
@@ -156,7 +159,7 @@ func TestAnnotations(t *testing.T) {
 		})
 
 		t.Run("Should find two annotations using partial match", func(t *testing.T) {
-			items, err := repo.Find(&annotations.ItemQuery{
+			items, err := repo.Find(context.Background(), &annotations.ItemQuery{
 				OrgId:    1,
 				From:     1,
 				To:       25,
@@ -168,7 +171,7 @@ func TestAnnotations(t *testing.T) {
 		})
 
 		t.Run("Should find one when all key value tag filters does match", func(t *testing.T) {
-			items, err := repo.Find(&annotations.ItemQuery{
+			items, err := repo.Find(context.Background(), &annotations.ItemQuery{
 				OrgId:       1,
 				DashboardId: 1,
 				From:        1,
@@ -186,11 +189,11 @@ func TestAnnotations(t *testing.T) {
 				From:        0,
 				To:          15,
 			}
-			items, err := repo.Find(query)
+			items, err := repo.Find(context.Background(), query)
 			require.NoError(t, err)
 
 			annotationId := items[0].Id
-			err = repo.Update(&annotations.Item{
+			err = repo.Update(context.Background(), &annotations.Item{
 				Id:    annotationId,
 				OrgId: 1,
 				Text:  "something new",
@@ -198,7 +201,7 @@ func TestAnnotations(t *testing.T) {
 			})
 			require.NoError(t, err)
 
-			items, err = repo.Find(query)
+			items, err = repo.Find(context.Background(), query)
 			require.NoError(t, err)
 
 			assert.Equal(t, annotationId, items[0].Id)
@@ -213,11 +216,11 @@ func TestAnnotations(t *testing.T) {
 				From:        0,
 				To:          15,
 			}
-			items, err := repo.Find(query)
+			items, err := repo.Find(context.Background(), query)
 			require.NoError(t, err)
 
 			annotationId := items[0].Id
-			err = repo.Update(&annotations.Item{
+			err = repo.Update(context.Background(), &annotations.Item{
 				Id:    annotationId,
 				OrgId: 1,
 				Text:  "something new",
@@ -225,7 +228,7 @@ func TestAnnotations(t *testing.T) {
 			})
 			require.NoError(t, err)
 
-			items, err = repo.Find(query)
+			items, err = repo.Find(context.Background(), query)
 			require.NoError(t, err)
 
 			assert.Equal(t, annotationId, items[0].Id)
@@ -241,14 +244,14 @@ func TestAnnotations(t *testing.T) {
 				From:        0,
 				To:          15,
 			}
-			items, err := repo.Find(query)
+			items, err := repo.Find(context.Background(), query)
 			require.NoError(t, err)
 
 			annotationId := items[0].Id
-			err = repo.Delete(&annotations.DeleteParams{Id: annotationId, OrgId: 1})
+			err = repo.Delete(context.Background(), &annotations.DeleteParams{Id: annotationId, OrgId: 1})
 			require.NoError(t, err)
 
-			items, err = repo.Find(query)
+			items, err = repo.Find(context.Background(), query)
 			require.NoError(t, err)
 			assert.Empty(t, items)
 		})
@@ -263,28 +266,28 @@ func TestAnnotations(t *testing.T) {
 				Epoch:       11,
 				Tags:        []string{"test"},
 			}
-			err = repo.Save(annotation3)
+			err = repo.Save(context.Background(), annotation3)
 			require.NoError(t, err)
 
 			query := &annotations.ItemQuery{
 				OrgId:        1,
 				AnnotationId: annotation3.Id,
 			}
-			items, err := repo.Find(query)
+			items, err := repo.Find(context.Background(), query)
 			require.NoError(t, err)
 
 			dashboardId := items[0].DashboardId
 			panelId := items[0].PanelId
-			err = repo.Delete(&annotations.DeleteParams{DashboardId: dashboardId, PanelId: panelId, OrgId: 1})
+			err = repo.Delete(context.Background(), &annotations.DeleteParams{DashboardId: dashboardId, PanelId: panelId, OrgId: 1})
 			require.NoError(t, err)
 
-			items, err = repo.Find(query)
+			items, err = repo.Find(context.Background(), query)
 			require.NoError(t, err)
 			assert.Empty(t, items)
 		})
 
 		t.Run("Should find tags by key", func(t *testing.T) {
-			result, err := repo.FindTags(&annotations.TagsQuery{
+			result, err := repo.FindTags(context.Background(), &annotations.TagsQuery{
 				OrgID: 1,
 				Tag:   "server",
 			})
@@ -295,7 +298,7 @@ func TestAnnotations(t *testing.T) {
 		})
 
 		t.Run("Should find tags by value", func(t *testing.T) {
-			result, err := repo.FindTags(&annotations.TagsQuery{
+			result, err := repo.FindTags(context.Background(), &annotations.TagsQuery{
 				OrgID: 1,
 				Tag:   "outage",
 			})
@@ -308,7 +311,7 @@ func TestAnnotations(t *testing.T) {
 		})
 
 		t.Run("Should not find tags in other org", func(t *testing.T) {
-			result, err := repo.FindTags(&annotations.TagsQuery{
+			result, err := repo.FindTags(context.Background(), &annotations.TagsQuery{
 				OrgID: 0,
 				Tag:   "server-1",
 			})
@@ -317,7 +320,7 @@ func TestAnnotations(t *testing.T) {
 		})
 
 		t.Run("Should not find tags that do not exist", func(t *testing.T) {
-			result, err := repo.FindTags(&annotations.TagsQuery{
+			result, err := repo.FindTags(context.Background(), &annotations.TagsQuery{
 				OrgID: 0,
 				Tag:   "unknown:tag",
 			})
@@ -326,3 +329,145 @@ func TestAnnotations(t *testing.T) {
 		})
 	})
 }
+
+func TestAnnotationSearch(t *testing.T) {
+	mockTimeNow()
+	defer resetTimeNow()
+	sqlStore := InitTestDB(t)
+	repo := SQLAnnotationRepo{}
+
+	dash := insertTestDashboard(t, sqlStore, "annotation search", 1, 0, false)
+	for i, item := range []*annotations.Item{
+		{DashboardId: dash.Id, Text: "Deployed checkout v1.2", Tags: []string{"env:prod", "service:checkout"}},
+		{DashboardId: dash.Id, Text: "Deployed checkout v1.3", Tags: []string{"env:staging", "service:checkout"}},
+		{Text: "Rolled back payments", Tags: []string{"env:prod", "service:payments"}},
+		{Text: "Scaled payments", Tags: []string{"env:dev", "service:payments"}},
+		{Text: "Maintenance"},
+	} {
+		item.OrgId = 1
+		item.Epoch = int64(i+1) * 1000
+		require.NoError(t, repo.Save(context.Background(), item))
+	}
+
+	texts := func(items []*annotations.ItemDTO) []string {
+		var texts []string
+		for _, item := range items {
+			texts = append(texts, item.Text)
+		}
+		return texts
+	}
+	matchers := func(ss ...string) []*annotations.TagMatcher {
+		var matchers []*annotations.TagMatcher
+		for _, s := range ss {
+			m, err := annotations.ParseTagMatcher(s)
+			require.NoError(t, err)
+			matchers = append(matchers, m)
+		}
+		return matchers
+	}
+
+	t.Run("Should find annotations by text", func(t *testing.T) {
+		items, err := repo.Find(context.Background(), &annotations.ItemQuery{OrgId: 1, Text: "payments"})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"Scaled payments", "Rolled back payments"}, texts(items))
+	})
+
+	t.Run("Should find annotations by dashboard uid", func(t *testing.T) {
+		items, err := repo.Find(context.Background(), &annotations.ItemQuery{OrgId: 1, DashboardUid: dash.Uid})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"Deployed checkout v1.3", "Deployed checkout v1.2"}, texts(items))
+	})
+
+	t.Run("Should find annotations by tag matchers", func(t *testing.T) {
+		items, err := repo.Find(context.Background(), &annotations.ItemQuery{OrgId: 1, Matchers: matchers("env=prod")})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"Rolled back payments", "Deployed checkout v1.2"}, texts(items))
+
+		items, err = repo.Find(context.Background(), &annotations.ItemQuery{OrgId: 1, Matchers: matchers("service=payments", "env!=prod")})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"Scaled payments"}, texts(items))
+
+		items, err = repo.Find(context.Background(), &annotations.ItemQuery{OrgId: 1, Matchers: matchers("env=~prod|stag.*")})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"Rolled back payments", "Deployed checkout v1.3", "Deployed checkout v1.2"}, texts(items))
+
+		items, err = repo.Find(context.Background(), &annotations.ItemQuery{OrgId: 1, Matchers: matchers("env=~pro")})
+		require.NoError(t, err)
+		assert.Empty(t, items, "regexp matches whole values")
+	})
+
+	t.Run("Should paginate annotations with a cursor", func(t *testing.T) {
+		query := &annotations.ItemQuery{OrgId: 1, Limit: 2}
+		var pages [][]string
+		for {
+			items, err := repo.Find(context.Background(), query)
+			require.NoError(t, err)
+			pages = append(pages, texts(items))
+			query.Cursor = annotations.NextCursor(items, query.Limit)
+			if query.Cursor == "" {
+				break
+			}
+		}
+		assert.Equal(t, [][]string{
+			{"Maintenance", "Scaled payments"},
+			{"Rolled back payments", "Deployed checkout v1.3"},
+			{"Deployed checkout v1.2"},
+		}, pages)
+
+		_, err := repo.Find(context.Background(), &annotations.ItemQuery{OrgId: 1, Cursor: "invalid"})
+		require.ErrorIs(t, err, annotations.ErrInvalidCursor)
+	})
+
+	t.Run("Should count tags per time bucket", func(t *testing.T) {
+		result, err := repo.CountTags(context.Background(), &annotations.TagCountsQuery{
+			ItemQuery: annotations.ItemQuery{OrgId: 1, Matchers: matchers("service=payments")},
+			Interval:  4000,
+		})
+		require.NoError(t, err)
+
+		var counts []annotations.TagCountDTO
+		for _, c := range result.Counts {
+			counts = append(counts, *c)
+		}
+		assert.Equal(t, []annotations.TagCountDTO{
+			{Tag: "env:prod", Time: 0, Count: 1},
+			{Tag: "service:payments", Time: 0, Count: 1},
+			{Tag: "env:dev", Time: 4000, Count: 1},
+			{Tag: "service:payments", Time: 4000, Count: 1},
+		}, counts)
+	})
+	t.Run("Should find text with LIKE wildcards literally", func(t *testing.T) {
+		for i, text := range []string{"CPU at 100% on web_1", "CPU at 1000 on web-1", "Disk [sda] full!"} {
+			require.NoError(t, repo.Save(context.Background(), &annotations.Item{OrgId: 2, Epoch: int64(i+1) * 1000, Text: text}))
+		}
+
+		items, err := repo.Find(context.Background(), &annotations.ItemQuery{OrgId: 2, Text: "100%"})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"CPU at 100% on web_1"}, texts(items))
+
+		items, err = repo.Find(context.Background(), &annotations.ItemQuery{OrgId: 2, Text: "web_1"})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"CPU at 100% on web_1"}, texts(items))
+
+		items, err = repo.Find(context.Background(), &annotations.ItemQuery{OrgId: 2, Text: "[sda] full!"})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"Disk [sda] full!"}, texts(items))
+	})
+
+	t.Run("Should match more tags than the databases allow parameters", func(t *testing.T) {
+		tags := make([]*models.Tag, 0, 2*tagMatcherBatchSize)
+		for i := 0; i < 2*tagMatcherBatchSize; i++ {
+			tags = append(tags, &models.Tag{Key: "build", Value: strconv.Itoa(i)})
+		}
+		err := sqlStore.WithDbSession(context.Background(), func(sess *DBSession) error {
+			_, err := sess.Insert(tags)
+			return err
+		})
+		require.NoError(t, err)
+		require.NoError(t, repo.Save(context.Background(), &annotations.Item{OrgId: 3, Epoch: 1000, Text: "Last build", Tags: []string{"build:1999"}}))
+
+		items, err := repo.Find(context.Background(), &annotations.ItemQuery{OrgId: 3, Matchers: matchers("build=~[0-9]+")})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"Last build"}, texts(items))
+	})
+}
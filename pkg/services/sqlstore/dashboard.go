@@ -12,9 +12,13 @@ import (
 	"github.com/grafana/grafana/pkg/services/sqlstore/searchstore"
 
 	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/components/dashdiffs"
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/events"
 	"github.com/grafana/grafana/pkg/infra/metrics"
 	"github.com/grafana/grafana/pkg/models"
 	"github.com/grafana/grafana/pkg/services/search"
+	"github.com/grafana/grafana/pkg/services/search/fulltext"
 	"github.com/grafana/grafana/pkg/util"
 )
 
@@ -43,6 +47,7 @@ func (ss *SQLStore) addDashboardQueryAndCommandHandlers() {
 	bus.AddHandler("sql", ss.DeleteDashboard)
 	bus.AddHandler("sql", ss.GetDashboards)
 	bus.AddHandler("sql", ss.HasEditPermissionInFolders)
+	bus.AddHandler("sql", ss.DeleteExpiredTrashedDashboards)
 }
 
 var generateNewUid func() string = util.GenerateShortUID
@@ -51,6 +56,9 @@ func (ss *SQLStore) SaveDashboard(cmd models.SaveDashboardCommand) (*models.Dash
 	err := ss.WithTransactionalDbSession(context.Background(), func(sess *DBSession) error {
 		return saveDashboard(sess, &cmd)
 	})
+	if err == nil {
+		ss.dashboardIndex.Update(cmd.Result)
+	}
 	return cmd.Result, err
 }
 
@@ -63,6 +71,7 @@ func saveDashboard(sess *DBSession, cmd *models.SaveDashboardCommand) error {
 		userId = -1
 	}
 
+	var previousData *simplejson.Json
 	if dash.Id > 0 {
 		var existing models.Dashboard
 		dashWithIdExists, err := sess.Where("id=? AND org_id=?", dash.Id, dash.OrgId).Get(&existing)
@@ -72,6 +81,7 @@ func saveDashboard(sess *DBSession, cmd *models.SaveDashboardCommand) error {
 		if !dashWithIdExists {
 			return models.ErrDashboardNotFound
 		}
+		previousData = existing.Data
 
 		// check for is someone else has written in between
 		if dash.Version != existing.Version {
@@ -139,6 +149,7 @@ func saveDashboard(sess *DBSession, cmd *models.SaveDashboardCommand) error {
 		CreatedBy:     dash.UpdatedBy,
 		Message:       cmd.Message,
 		Data:          dash.Data,
+		Changes:       dashdiffs.Changelog(previousData, dash.Data),
 	}
 
 	// insert version entry
@@ -166,6 +177,14 @@ func saveDashboard(sess *DBSession, cmd *models.SaveDashboardCommand) error {
 
 	cmd.Result = dash
 
+	sess.publishAfterCommit(&events.DashboardSaved{
+		Timestamp: dash.Updated,
+		ID:        dash.Id,
+		UID:       dash.Uid,
+		OrgID:     dash.OrgId,
+		IsFolder:  dash.IsFolder,
+	})
+
 	return nil
 }
 
@@ -243,7 +262,7 @@ type DashboardSearchProjection struct {
 	SortMeta    int64
 }
 
-func (ss *SQLStore) findDashboards(ctx context.Context, query *search.FindPersistedDashboardsQuery) ([]DashboardSearchProjection, error) {
+func (ss *SQLStore) findDashboards(ctx context.Context, query *search.FindPersistedDashboardsQuery) ([]DashboardSearchProjection, map[int64]float64, error) {
 	filters := []interface{}{
 		permissions.DashboardPermissionFilter{
 			OrgRole:         query.SignedInUser.OrgRole,
@@ -258,10 +277,12 @@ func (ss *SQLStore) findDashboards(ctx context.Context, query *search.FindPersis
 		filters = append(filters, filter)
 	}
 
-	if query.OrgId != 0 {
-		filters = append(filters, searchstore.OrgFilter{OrgId: query.OrgId})
-	} else if query.SignedInUser.OrgId != 0 {
-		filters = append(filters, searchstore.OrgFilter{OrgId: query.SignedInUser.OrgId})
+	orgID := query.OrgId
+	if orgID == 0 {
+		orgID = query.SignedInUser.OrgId
+	}
+	if orgID != 0 {
+		filters = append(filters, searchstore.OrgFilter{OrgId: orgID})
 	}
 
 	if len(query.Tags) > 0 {
@@ -288,9 +309,6 @@ func (ss *SQLStore) findDashboards(ctx context.Context, query *search.FindPersis
 		filters = append(filters, searchstore.FolderFilter{IDs: query.FolderIds})
 	}
 
-	var res []DashboardSearchProjection
-	sb := &searchstore.Builder{Dialect: dialect, Filters: filters}
-
 	limit := query.Limit
 	if limit < 1 {
 		limit = 1000
@@ -301,6 +319,34 @@ func (ss *SQLStore) findDashboards(ctx context.Context, query *search.FindPersis
 		page = 1
 	}
 
+	var scores map[int64]float64
+	fullTextQuery := fulltext.Query{Text: query.FullText, DatasourceUIDs: query.DatasourceUIDs, Variables: query.Variables}
+	if !fullTextQuery.IsEmpty() {
+		// Results ordered by relevance only need the matches up to the requested page,
+		// any other order needs all of them.
+		byRelevance := query.Sort.Name == "" && query.FullText != ""
+		var need int64
+		if byRelevance {
+			need = limit * page
+		}
+		ids, s, err := ss.findFullTextDashboardIDs(ctx, orgID, fullTextQuery, filters, need)
+		if err != nil {
+			return nil, nil, err
+		}
+		if len(ids) == 0 {
+			return nil, nil, nil
+		}
+
+		scores = s
+		filters = append(filters, searchstore.DashboardIDsFilter{IDs: ids})
+		if byRelevance {
+			filters = append(filters, searchstore.RankSorter{IDs: ids})
+		}
+	}
+
+	var res []DashboardSearchProjection
+	sb := &searchstore.Builder{Dialect: dialect, Filters: filters}
+
 	sql, params := sb.ToSQL(limit, page)
 
 	err := ss.WithDbSession(ctx, func(dbSession *DBSession) error {
@@ -308,19 +354,67 @@ func (ss *SQLStore) findDashboards(ctx context.Context, query *search.FindPersis
 	})
 
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
-	return res, nil
+	return res, scores, nil
+}
+
+// findFullTextDashboardIDs returns the IDs of the dashboards matching a full-text query and the filters of a search,
+// such as permissions, in relevance order along with their scores. Index results are fetched in batches until at least
+// need dashboards are found, or all of them when need is 0, so that filtered out results don't hide other matches.
+func (ss *SQLStore) findFullTextDashboardIDs(ctx context.Context, orgID int64, q fulltext.Query, filters []interface{}, need int64) ([]int64, map[int64]float64, error) {
+	var ids []int64
+	scores := map[int64]float64{}
+	for from := 0; ; from += fulltext.BatchSize {
+		results, err := ss.dashboardIndex.Search(ctx, orgID, q, from, fulltext.BatchSize)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		if len(results) > 0 {
+			batch := make([]int64, 0, len(results))
+			for _, r := range results {
+				batch = append(batch, r.ID)
+				scores[r.ID] = r.Score
+			}
+
+			batchFilters := make([]interface{}, 0, len(filters)+2)
+			batchFilters = append(batchFilters, filters...)
+			batchFilters = append(batchFilters, searchstore.DashboardIDsFilter{IDs: batch}, searchstore.RankSorter{IDs: batch})
+			sb := &searchstore.Builder{Dialect: dialect, Filters: batchFilters}
+			sql, params := sb.ToSQL(int64(len(batch)), 1)
+
+			var res []DashboardSearchProjection
+			err := ss.WithDbSession(ctx, func(dbSession *DBSession) error {
+				return dbSession.SQL(sql, params...).Find(&res)
+			})
+			if err != nil {
+				return nil, nil, err
+			}
+
+			seen := make(map[int64]bool, len(res))
+			for _, item := range res {
+				if !seen[item.ID] {
+					seen[item.ID] = true
+					ids = append(ids, item.ID)
+				}
+			}
+		}
+
+		if len(results) < fulltext.BatchSize || (need > 0 && int64(len(ids)) >= need) {
+			return ids, scores, nil
+		}
+	}
 }
 
 func (ss *SQLStore) SearchDashboards(ctx context.Context, query *search.FindPersistedDashboardsQuery) error {
-	res, err := ss.findDashboards(ctx, query)
+	res, scores, err := ss.findDashboards(ctx, query)
 	if err != nil {
 		return err
 	}
 
-	makeQueryResult(query, res)
+	makeQueryResult(query, res, scores)
 
 	return nil
 }
@@ -336,7 +430,7 @@ func getHitType(item DashboardSearchProjection) search.HitType {
 	return hitType
 }
 
-func makeQueryResult(query *search.FindPersistedDashboardsQuery, res []DashboardSearchProjection) {
+func makeQueryResult(query *search.FindPersistedDashboardsQuery, res []DashboardSearchProjection, scores map[int64]float64) {
 	query.Result = make([]*search.Hit, 0)
 	hits := make(map[int64]*search.Hit)
 
@@ -354,6 +448,7 @@ func makeQueryResult(query *search.FindPersistedDashboardsQuery, res []Dashboard
 				FolderUID:   item.FolderUID,
 				FolderTitle: item.FolderTitle,
 				Tags:        []string{},
+				Score:       scores[item.ID],
 			}
 
 			if item.FolderID > 0 {
@@ -391,9 +486,36 @@ func (ss *SQLStore) GetDashboardTags(ctx context.Context, query *models.GetDashb
 }
 
 func (ss *SQLStore) DeleteDashboard(ctx context.Context, cmd *models.DeleteDashboardCommand) error {
-	return ss.WithTransactionalDbSession(ctx, func(sess *DBSession) error {
+	var childIDs []int64
+	err := ss.WithTransactionalDbSession(ctx, func(sess *DBSession) error {
+		// The dashboards of a folder are deleted with it.
+		if err := sess.Table("dashboard").Where("org_id = ? AND folder_id = ?", cmd.OrgId, cmd.Id).
+			Cols("id").Find(&childIDs); err != nil {
+			return err
+		}
+		if cmd.MoveToTrash && ss.Cfg.DashboardTrashRetention > 0 {
+			return trashDashboard(cmd, sess)
+		}
 		return deleteDashboard(cmd, sess)
 	})
+	if err == nil {
+		ss.dashboardIndex.Delete(cmd.OrgId, append(childIDs, cmd.Id)...)
+	}
+	return err
+}
+
+// getDashboardsToIndex loads the dashboards of an organization for the
+// full-text search index.
+func (ss *SQLStore) getDashboardsToIndex(ctx context.Context, orgID int64, since time.Time) ([]*models.Dashboard, error) {
+	var dashboards []*models.Dashboard
+	err := ss.WithDbSession(ctx, func(sess *DBSession) error {
+		sess.Where("org_id = ? AND is_folder = "+ss.Dialect.BooleanStr(false), orgID)
+		if !since.IsZero() {
+			sess.And("updated > ?", since)
+		}
+		return sess.Find(&dashboards)
+	})
+	return dashboards, err
 }
 
 func deleteDashboard(cmd *models.DeleteDashboardCommand, sess *DBSession) error {
@@ -486,6 +608,14 @@ func deleteDashboard(cmd *models.DeleteDashboardCommand, sess *DBSession) error
 		}
 	}
 
+	sess.publishAfterCommit(&events.DashboardDeleted{
+		Timestamp: time.Now(),
+		ID:        dashboard.Id,
+		UID:       dashboard.Uid,
+		OrgID:     dashboard.OrgId,
+		IsFolder:  dashboard.IsFolder,
+	})
+
 	return nil
 }
 
@@ -69,6 +69,9 @@ func (ss *SQLStore) SaveProvisionedDashboard(cmd models.SaveDashboardCommand,
 
 		return saveProvisionedData(sess, provisioning, cmd.Result)
 	})
+	if err == nil {
+		ss.dashboardIndex.Update(cmd.Result)
+	}
 
 	return cmd.Result, err
 }
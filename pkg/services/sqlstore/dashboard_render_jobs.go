@@ -0,0 +1,162 @@
+package sqlstore
+
+import (
+	"context"
+	"time"
+
+	"github.com/grafana/grafana/pkg/models"
+)
+
+// EnqueueDashboardRenderJobs adds a pending job for each dashboard without one. Pending jobs render the latest
+// version of their dashboard, and failed jobs are retried when their dashboard changed.
+func (ss *SQLStore) EnqueueDashboardRenderJobs(ctx context.Context, cmd *models.EnqueueDashboardRenderJobsCommand) (int, error) {
+	enqueued := 0
+	err := ss.WithTransactionalDbSession(ctx, func(sess *DBSession) error {
+		existing := make([]*models.DashboardRenderJob, 0)
+		if err := sess.Where("kind = ? AND theme = ?", cmd.Kind, cmd.Theme).Find(&existing); err != nil {
+			return err
+		}
+		jobs := make(map[int64]*models.DashboardRenderJob, len(existing))
+		for _, job := range existing {
+			jobs[job.DashboardId] = job
+		}
+
+		now := time.Now()
+		for _, dash := range cmd.Dashboards {
+			job, ok := jobs[dash.Id]
+			if !ok {
+				job = &models.DashboardRenderJob{
+					OrgId:            dash.OrgId,
+					DashboardId:      dash.Id,
+					DashboardUID:     dash.Uid,
+					DashboardSlug:    dash.Slug,
+					DashboardVersion: dash.Version,
+					Kind:             cmd.Kind,
+					Theme:            cmd.Theme,
+					State:            models.RenderJobStatePending,
+					NextAttempt:      now,
+					Created:          now,
+					Updated:          now,
+				}
+				if _, err := sess.Insert(job); err != nil {
+					return err
+				}
+				enqueued++
+				continue
+			}
+
+			if job.DashboardVersion == dash.Version && job.DashboardSlug == dash.Slug {
+				continue
+			}
+			if job.State == models.RenderJobStateFailed {
+				_, err := sess.Exec("UPDATE dashboard_render_job SET state = ?, attempts = 0, last_error = '', next_attempt = ?, "+
+					"dashboard_slug = ?, dashboard_version = ?, updated = ? WHERE id = ?",
+					models.RenderJobStatePending, now, dash.Slug, dash.Version, now, job.Id)
+				if err != nil {
+					return err
+				}
+				enqueued++
+				continue
+			}
+			_, err := sess.Exec("UPDATE dashboard_render_job SET dashboard_slug = ?, dashboard_version = ? WHERE id = ?",
+				dash.Slug, dash.Version, job.Id)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+
+	return enqueued, err
+}
+
+// PrioritizeDashboardRenderJobs sets the priority of the pending jobs of a dashboard.
+func (ss *SQLStore) PrioritizeDashboardRenderJobs(ctx context.Context, cmd *models.PrioritizeDashboardRenderJobsCommand) error {
+	return ss.WithDbSession(ctx, func(sess *DBSession) error {
+		_, err := sess.Exec("UPDATE dashboard_render_job SET priority = ? WHERE org_id = ? AND dashboard_uid = ? AND state = ?",
+			cmd.Priority, cmd.OrgId, cmd.DashboardUID, models.RenderJobStatePending)
+		return err
+	})
+}
+
+// ClaimDashboardRenderJobs marks the pending jobs with the highest priority as running on a renderer, without
+// exceeding its concurrency. Running jobs not completed before the timeout are pending again.
+func (ss *SQLStore) ClaimDashboardRenderJobs(ctx context.Context, cmd *models.ClaimDashboardRenderJobsCommand) ([]*models.DashboardRenderJob, error) {
+	claimed := make([]*models.DashboardRenderJob, 0)
+	err := ss.WithTransactionalDbSession(ctx, func(sess *DBSession) error {
+		now := time.Now()
+		_, err := sess.Exec("UPDATE dashboard_render_job SET state = ?, last_error = ?, updated = ? WHERE state = ? AND updated < ?",
+			models.RenderJobStatePending, "rendering timed out", now, models.RenderJobStateRunning, now.Add(-cmd.Timeout))
+		if err != nil {
+			return err
+		}
+
+		running, err := sess.Where("state = ? AND renderer = ?", models.RenderJobStateRunning, cmd.Renderer).Count(&models.DashboardRenderJob{})
+		if err != nil {
+			return err
+		}
+		available := cmd.Concurrency - int(running)
+		if available <= 0 {
+			return nil
+		}
+
+		candidates := make([]*models.DashboardRenderJob, 0)
+		err = sess.Where("state = ? AND next_attempt <= ?", models.RenderJobStatePending, now).
+			OrderBy("priority DESC, id ASC").
+			Limit(available).
+			Find(&candidates)
+		if err != nil {
+			return err
+		}
+
+		for _, job := range candidates {
+			// Other instances may claim the same jobs, only the first update succeeds.
+			res, err := sess.Exec("UPDATE dashboard_render_job SET state = ?, renderer = ?, updated = ? WHERE id = ? AND state = ?",
+				models.RenderJobStateRunning, cmd.Renderer, now, job.Id, models.RenderJobStatePending)
+			if err != nil {
+				return err
+			}
+			if rows, err := res.RowsAffected(); err != nil || rows == 0 {
+				continue
+			}
+			job.State = models.RenderJobStateRunning
+			job.Renderer = cmd.Renderer
+			job.Updated = now
+			claimed = append(claimed, job)
+		}
+		return nil
+	})
+
+	return claimed, err
+}
+
+// CompleteDashboardRenderJob deletes a job once its thumbnail is saved.
+func (ss *SQLStore) CompleteDashboardRenderJob(ctx context.Context, id int64) error {
+	return ss.WithDbSession(ctx, func(sess *DBSession) error {
+		_, err := sess.Exec("DELETE FROM dashboard_render_job WHERE id = ?", id)
+		return err
+	})
+}
+
+func (ss *SQLStore) FailDashboardRenderJob(ctx context.Context, cmd *models.FailDashboardRenderJobCommand) error {
+	return ss.WithDbSession(ctx, func(sess *DBSession) error {
+		_, err := sess.Exec("UPDATE dashboard_render_job SET state = ?, attempts = attempts + 1, last_error = ?, next_attempt = ?, updated = ? WHERE id = ?",
+			cmd.State, cmd.Error, cmd.NextAttempt, time.Now(), cmd.Id)
+		return err
+	})
+}
+
+func (ss *SQLStore) FindDashboardRenderJobs(ctx context.Context, query *models.FindDashboardRenderJobsQuery) ([]*models.DashboardRenderJob, error) {
+	jobs := make([]*models.DashboardRenderJob, 0)
+	err := ss.WithDbSession(ctx, func(sess *DBSession) error {
+		if query.State != "" {
+			sess.Where("state = ?", query.State)
+		}
+		if query.Limit > 0 {
+			sess.Limit(query.Limit)
+		}
+		return sess.OrderBy("updated DESC, id DESC").Find(&jobs)
+	})
+
+	return jobs, err
+}
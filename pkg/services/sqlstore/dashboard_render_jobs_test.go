@@ -0,0 +1,126 @@
+//go:build integration
+// +build integration
+
+package sqlstore
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDashboardRenderJobs(t *testing.T) {
+	var sqlStore *SQLStore
+	var dashboards []*models.DashboardWithStaleThumbnail
+
+	setup := func() {
+		sqlStore = InitTestDB(t)
+		dashboards = nil
+		for _, title := range []string{"render 1", "render 2", "render 3"} {
+			dash := insertTestDashboard(t, sqlStore, title, 1, 0, false)
+			dashboards = append(dashboards, &models.DashboardWithStaleThumbnail{
+				Id: dash.Id, OrgId: dash.OrgId, Uid: dash.Uid, Version: dash.Version, Slug: dash.Slug,
+			})
+		}
+		enqueued, err := sqlStore.EnqueueDashboardRenderJobs(context.Background(), &models.EnqueueDashboardRenderJobsCommand{
+			Kind: kind, Theme: theme, Dashboards: dashboards,
+		})
+		require.NoError(t, err)
+		require.Equal(t, 3, enqueued)
+	}
+
+	claim := func(renderer string, concurrency int) []*models.DashboardRenderJob {
+		jobs, err := sqlStore.ClaimDashboardRenderJobs(context.Background(), &models.ClaimDashboardRenderJobsCommand{
+			Renderer: renderer, Concurrency: concurrency, Timeout: time.Minute,
+		})
+		require.NoError(t, err)
+		return jobs
+	}
+
+	t.Run("Should enqueue dashboards once", func(t *testing.T) {
+		setup()
+		enqueued, err := sqlStore.EnqueueDashboardRenderJobs(context.Background(), &models.EnqueueDashboardRenderJobsCommand{
+			Kind: kind, Theme: theme, Dashboards: dashboards,
+		})
+		require.NoError(t, err)
+		require.Equal(t, 0, enqueued)
+
+		jobs, err := sqlStore.FindDashboardRenderJobs(context.Background(), &models.FindDashboardRenderJobsQuery{State: models.RenderJobStatePending})
+		require.NoError(t, err)
+		require.Len(t, jobs, 3)
+	})
+
+	t.Run("Should claim recently viewed dashboards first within the renderer concurrency", func(t *testing.T) {
+		setup()
+		err := sqlStore.PrioritizeDashboardRenderJobs(context.Background(), &models.PrioritizeDashboardRenderJobsCommand{
+			OrgId: 1, DashboardUID: dashboards[2].Uid, Priority: time.Now().Unix(),
+		})
+		require.NoError(t, err)
+
+		jobs := claim("plugin", 2)
+		require.Len(t, jobs, 2)
+		require.Equal(t, dashboards[2].Uid, jobs[0].DashboardUID)
+		require.Equal(t, dashboards[0].Uid, jobs[1].DashboardUID)
+		require.Equal(t, models.RenderJobStateRunning, jobs[0].State)
+
+		require.Empty(t, claim("plugin", 2))
+		require.Len(t, claim("remote", 2), 1)
+
+		require.NoError(t, sqlStore.CompleteDashboardRenderJob(context.Background(), jobs[0].Id))
+		running, err := sqlStore.FindDashboardRenderJobs(context.Background(), &models.FindDashboardRenderJobsQuery{State: models.RenderJobStateRunning})
+		require.NoError(t, err)
+		require.Len(t, running, 2)
+	})
+
+	t.Run("Should retry failed jobs after their next attempt", func(t *testing.T) {
+		setup()
+		jobs := claim("plugin", 1)
+		require.Len(t, jobs, 1)
+
+		err := sqlStore.FailDashboardRenderJob(context.Background(), &models.FailDashboardRenderJobCommand{
+			Id: jobs[0].Id, Error: "timeout", State: models.RenderJobStatePending, NextAttempt: time.Now().Add(time.Hour),
+		})
+		require.NoError(t, err)
+
+		retried := claim("plugin", 3)
+		require.Len(t, retried, 2)
+		for _, job := range retried {
+			require.NotEqual(t, jobs[0].Id, job.Id)
+		}
+
+		err = sqlStore.FailDashboardRenderJob(context.Background(), &models.FailDashboardRenderJobCommand{
+			Id: jobs[0].Id, Error: "timeout again", State: models.RenderJobStateFailed, NextAttempt: time.Now(),
+		})
+		require.NoError(t, err)
+
+		failed, err := sqlStore.FindDashboardRenderJobs(context.Background(), &models.FindDashboardRenderJobsQuery{State: models.RenderJobStateFailed})
+		require.NoError(t, err)
+		require.Len(t, failed, 1)
+		require.Equal(t, 2, failed[0].Attempts)
+		require.Equal(t, "timeout again", failed[0].LastError)
+	})
+
+	t.Run("Should retry failed jobs when the dashboard changes", func(t *testing.T) {
+		setup()
+		jobs := claim("plugin", 1)
+		err := sqlStore.FailDashboardRenderJob(context.Background(), &models.FailDashboardRenderJobCommand{
+			Id: jobs[0].Id, Error: "timeout", State: models.RenderJobStateFailed, NextAttempt: time.Now(),
+		})
+		require.NoError(t, err)
+
+		changed := *dashboards[0]
+		changed.Version++
+		enqueued, err := sqlStore.EnqueueDashboardRenderJobs(context.Background(), &models.EnqueueDashboardRenderJobsCommand{
+			Kind: kind, Theme: theme, Dashboards: []*models.DashboardWithStaleThumbnail{&changed},
+		})
+		require.NoError(t, err)
+		require.Equal(t, 1, enqueued)
+
+		pending, err := sqlStore.FindDashboardRenderJobs(context.Background(), &models.FindDashboardRenderJobsQuery{State: models.RenderJobStatePending})
+		require.NoError(t, err)
+		require.Len(t, pending, 3)
+	})
+}
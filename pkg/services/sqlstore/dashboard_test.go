@@ -14,6 +14,7 @@ import (
 	"github.com/grafana/grafana/pkg/components/simplejson"
 	"github.com/grafana/grafana/pkg/models"
 	"github.com/grafana/grafana/pkg/services/search"
+	"github.com/grafana/grafana/pkg/services/search/fulltext"
 	"github.com/grafana/grafana/pkg/services/sqlstore/searchstore"
 	"github.com/grafana/grafana/pkg/setting"
 	"github.com/grafana/grafana/pkg/util"
@@ -464,13 +465,99 @@ func TestDashboard_SortingOptions(t *testing.T) {
 				searchstore.TitleSorter{Descending: true},
 			},
 		}
-		dashboards, err := sqlStore.findDashboards(context.Background(), q)
+		dashboards, _, err := sqlStore.findDashboards(context.Background(), q)
 		require.NoError(t, err)
 		require.Len(t, dashboards, 2)
 		assert.Equal(t, dashA.Id, dashboards[0].ID)
 		assert.Equal(t, dashB.Id, dashboards[1].ID)
 	})
 }
+
+func TestDashboard_FullTextSearch(t *testing.T) {
+	sqlStore := InitTestDB(t)
+	user := &models.SignedInUser{OrgId: 1, UserId: 1, OrgRole: models.ROLE_ADMIN}
+	saveDashboard := func(title string, panels ...interface{}) *models.Dashboard {
+		dash, err := sqlStore.SaveDashboard(models.SaveDashboardCommand{
+			OrgId: 1,
+			Dashboard: simplejson.NewFromAny(map[string]interface{}{
+				"title":  title,
+				"panels": panels,
+			}),
+		})
+		require.NoError(t, err)
+		return dash
+	}
+	panel := func(title string, dsUID string, expr string) map[string]interface{} {
+		return map[string]interface{}{
+			"title":      title,
+			"datasource": map[string]interface{}{"uid": dsUID},
+			"targets":    []interface{}{map[string]interface{}{"refId": "A", "expr": expr}},
+		}
+	}
+	find := func(q search.FindPersistedDashboardsQuery) search.HitList {
+		q.SignedInUser = user
+		require.NoError(t, sqlStore.SearchDashboards(context.Background(), &q))
+		return q.Result
+	}
+
+	api := saveDashboard("API", panel("Requests", "prom", `sum(rate(http_requests_total{job="api"}[5m]))`))
+	latency := saveDashboard("Latency", panel("Request latency", "prom", "histogram_quantile(0.99, http_request_duration_seconds_bucket)"))
+	saveDashboard("Logs", panel("Errors", "loki", `{job="api"} |= "error"`))
+
+	t.Run("Should match panel titles and rank by relevance", func(t *testing.T) {
+		hits := find(search.FindPersistedDashboardsQuery{FullText: "latency"})
+		require.Len(t, hits, 1)
+		require.Equal(t, latency.Id, hits[0].ID)
+		require.Positive(t, hits[0].Score)
+	})
+
+	t.Run("Should match queries", func(t *testing.T) {
+		hits := find(search.FindPersistedDashboardsQuery{FullText: "http_requests_total"})
+		require.Len(t, hits, 1)
+		require.Equal(t, api.Id, hits[0].ID)
+	})
+
+	t.Run("Should filter by datasource", func(t *testing.T) {
+		hits := find(search.FindPersistedDashboardsQuery{DatasourceUIDs: []string{"prom"}})
+		require.Len(t, hits, 2)
+
+		hits = find(search.FindPersistedDashboardsQuery{FullText: "api", DatasourceUIDs: []string{"loki"}})
+		require.Len(t, hits, 1)
+		require.Equal(t, "Logs", hits[0].Title)
+	})
+
+	t.Run("Should update index on save and delete", func(t *testing.T) {
+		api.Data.Set("id", api.Id)
+		api.Data.Set("panels", []interface{}{panel("Throughput", "prom", "sum(rate(http_requests_total[5m]))")})
+		_, err := sqlStore.SaveDashboard(models.SaveDashboardCommand{OrgId: 1, Dashboard: api.Data, Overwrite: true})
+		require.NoError(t, err)
+		hits := find(search.FindPersistedDashboardsQuery{FullText: "throughput"})
+		require.Len(t, hits, 1)
+		require.Equal(t, api.Id, hits[0].ID)
+
+		err = sqlStore.DeleteDashboard(context.Background(), &models.DeleteDashboardCommand{Id: api.Id, OrgId: 1})
+		require.NoError(t, err)
+		require.Empty(t, find(search.FindPersistedDashboardsQuery{FullText: "throughput"}))
+	})
+
+	t.Run("Should page results ordered by relevance", func(t *testing.T) {
+		hits := find(search.FindPersistedDashboardsQuery{DatasourceUIDs: []string{"prom", "loki"}, Limit: 1, Page: 2})
+		require.Len(t, hits, 1)
+	})
+
+	t.Run("Should remove dashboards of a deleted folder from the index", func(t *testing.T) {
+		folder := insertTestDashboard(t, sqlStore, "Full-text folder", 1, 0, true)
+		insertTestDashboard(t, sqlStore, "Full-text child", 1, folder.Id, false)
+		require.Len(t, find(search.FindPersistedDashboardsQuery{FullText: "child"}), 1)
+
+		err := sqlStore.DeleteDashboard(context.Background(), &models.DeleteDashboardCommand{Id: folder.Id, OrgId: 1})
+		require.NoError(t, err)
+		results, err := sqlStore.dashboardIndex.Search(context.Background(), 1, fulltext.Query{Text: "child"}, 0, fulltext.BatchSize)
+		require.NoError(t, err)
+		require.Empty(t, results)
+	})
+}
+
 func insertTestDashboard(t *testing.T, sqlStore *SQLStore, title string, orgId int64,
 	folderId int64, isFolder bool, tags ...interface{}) *models.Dashboard {
 	t.Helper()
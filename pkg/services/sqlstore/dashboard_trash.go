@@ -0,0 +1,513 @@
+package sqlstore
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/grafana/grafana/pkg/events"
+	"github.com/grafana/grafana/pkg/models"
+)
+
+// libraryElementConnectionKindDashboard is the kind of the connections of
+// library elements to dashboards.
+const libraryElementConnectionKindDashboard = 1
+
+// trashedLibraryElement is a row of the library_element table, which is
+// otherwise managed by the library elements service.
+type trashedLibraryElement struct {
+	ID          int64  `xorm:"pk autoincr 'id'"`
+	OrgID       int64  `xorm:"org_id"`
+	FolderID    int64  `xorm:"folder_id"`
+	UID         string `xorm:"uid"`
+	Name        string
+	Kind        int64
+	Type        string
+	Description string
+	Model       string
+	Version     int64
+	Created     time.Time
+	Updated     time.Time
+	CreatedBy   int64
+	UpdatedBy   int64
+}
+
+func (e trashedLibraryElement) TableName() string {
+	return "library_element"
+}
+
+// trashedLibraryElementConnection is a row of the library_element_connection table.
+type trashedLibraryElementConnection struct {
+	ID           int64 `xorm:"pk autoincr 'id'"`
+	ElementID    int64 `xorm:"element_id"`
+	Kind         int64 `xorm:"kind"`
+	ConnectionID int64 `xorm:"connection_id"`
+	Created      time.Time
+	CreatedBy    int64
+}
+
+func (c trashedLibraryElementConnection) TableName() string {
+	return models.LibraryElementConnectionTableName
+}
+
+// dashboardTrashMaxVersions is the number of most recent versions of each
+// dashboard kept in the recycle bin.
+const dashboardTrashMaxVersions = 10
+
+// Kinds of the snapshots of a trashed dashboard.
+const (
+	dashboardTrashItemDashboard      = "dashboard"
+	dashboardTrashItemVersion        = "version"
+	dashboardTrashItemLibraryElement = "library_element"
+)
+
+// dashboardTrashItem is the snapshot of a dashboard with its tags and
+// permissions, of a dashboard version, or of a library element of a trashed
+// folder. A dashboard is kept with one item per row so that a folder with many
+// dashboards doesn't exceed the size of a column.
+type dashboardTrashItem struct {
+	Id      int64
+	TrashId int64
+	Kind    string
+	Data    string
+}
+
+func (i dashboardTrashItem) TableName() string {
+	return "dashboard_trash_item"
+}
+
+// trashedDashboardData is the snapshot of the rows of a trashed dashboard.
+type trashedDashboardData struct {
+	Dashboard                 *models.Dashboard
+	Tags                      []*DashboardTag
+	Acl                       []*models.DashboardAcl
+	LibraryElementConnections []*trashedLibraryElementConnection
+}
+
+// dashboardTrashData is the snapshot of the rows of a trashed dashboard, or
+// of a trashed folder and its dashboards, that are inserted back on restore.
+// The first dashboard is the trashed one.
+type dashboardTrashData struct {
+	Dashboards      []*trashedDashboardData
+	Versions        []*models.DashboardVersion
+	LibraryElements []*trashedLibraryElement
+}
+
+// trashDashboard moves a dashboard or a folder with its dashboards to the
+// recycle bin. Annotations, stars, playlist items, alert rules, provisioning
+// data and all but the latest versions are deleted permanently, as for
+// deleteDashboard. Provisioned dashboards of a folder are deleted permanently
+// too, they are recreated by provisioning.
+func trashDashboard(cmd *models.DeleteDashboardCommand, sess *DBSession) error {
+	dashboard := models.Dashboard{Id: cmd.Id, OrgId: cmd.OrgId}
+	has, err := sess.Get(&dashboard)
+	if err != nil {
+		return err
+	} else if !has {
+		return models.ErrDashboardNotFound
+	}
+
+	dashboards := []*models.Dashboard{&dashboard}
+	// dashIDs are the IDs of all the deleted dashboards, provisioned ones included.
+	dashIDs := []int64{dashboard.Id}
+	if dashboard.IsFolder {
+		var children []*models.Dashboard
+		if err := sess.Where("org_id = ? AND folder_id = ?", dashboard.OrgId, dashboard.Id).Find(&children); err != nil {
+			return err
+		}
+
+		var provisioned []int64
+		if len(children) > 0 {
+			for _, child := range children {
+				dashIDs = append(dashIDs, child.Id)
+			}
+			if err := sess.Table("dashboard_provisioning").In("dashboard_id", dashIDs).Cols("dashboard_id").
+				Find(&provisioned); err != nil {
+				return err
+			}
+		}
+		for _, child := range children {
+			if !int64InSlice(child.Id, provisioned) {
+				dashboards = append(dashboards, child)
+			}
+		}
+	}
+
+	data := dashboardTrashData{}
+	for _, dash := range dashboards {
+		item := &trashedDashboardData{Dashboard: dash}
+		if err := sess.Where("dashboard_id = ?", dash.Id).Find(&item.Tags); err != nil {
+			return err
+		}
+		if err := sess.Where("dashboard_id = ?", dash.Id).Find(&item.Acl); err != nil {
+			return err
+		}
+		if err := sess.Where("kind = ? AND connection_id = ?", libraryElementConnectionKindDashboard, dash.Id).
+			Find(&item.LibraryElementConnections); err != nil {
+			return err
+		}
+		var versions []*models.DashboardVersion
+		if err := sess.Where("dashboard_id = ?", dash.Id).Desc("version").Limit(dashboardTrashMaxVersions).
+			Find(&versions); err != nil {
+			return err
+		}
+		data.Dashboards = append(data.Dashboards, item)
+		data.Versions = append(data.Versions, versions...)
+	}
+
+	if dashboard.IsFolder {
+		if err := sess.Where("org_id = ? AND folder_id = ?", dashboard.OrgId, dashboard.Id).Find(&data.LibraryElements); err != nil {
+			return err
+		}
+
+		if len(data.LibraryElements) > 0 {
+			elementIDs := make([]int64, 0, len(data.LibraryElements))
+			for _, element := range data.LibraryElements {
+				elementIDs = append(elementIDs, element.ID)
+			}
+
+			// Library elements in the folder can only be trashed with the dashboards using them.
+			connected, err := sess.In("element_id", elementIDs).NotIn("connection_id", dashIDs).
+				Count(&trashedLibraryElementConnection{})
+			if err != nil {
+				return err
+			}
+			if connected > 0 {
+				return models.ErrFolderHasConnectedLibraryElements
+			}
+
+			if _, err := sess.In("element_id", elementIDs).Delete(&trashedLibraryElementConnection{}); err != nil {
+				return err
+			}
+			if _, err := sess.In("id", elementIDs).Delete(&trashedLibraryElement{}); err != nil {
+				return err
+			}
+		}
+	}
+
+	if _, err := sess.Where("kind = ?", libraryElementConnectionKindDashboard).In("connection_id", dashIDs).
+		Delete(&trashedLibraryElementConnection{}); err != nil {
+		return err
+	}
+
+	if err := deleteDashboard(cmd, sess); err != nil {
+		return err
+	}
+
+	entry := models.TrashedDashboard{
+		OrgId:        dashboard.OrgId,
+		DashboardId:  dashboard.Id,
+		DashboardUid: dashboard.Uid,
+		Title:        dashboard.Title,
+		IsFolder:     dashboard.IsFolder,
+		FolderId:     dashboard.FolderId,
+		Dashboards:   len(data.Dashboards) - 1,
+		Deleted:      time.Now(),
+	}
+	if _, err := sess.Table("dashboard_trash").Insert(&entry); err != nil {
+		return err
+	}
+	return insertDashboardTrashItems(sess, entry.Id, &data)
+}
+
+// insertDashboardTrashItems inserts the snapshots of a trashed dashboard, the
+// trashed dashboard first.
+func insertDashboardTrashItems(sess *DBSession, trashID int64, data *dashboardTrashData) error {
+	insert := func(kind string, v interface{}) error {
+		raw, err := json.Marshal(v)
+		if err != nil {
+			return err
+		}
+		_, err = sess.Insert(&dashboardTrashItem{TrashId: trashID, Kind: kind, Data: string(raw)})
+		return err
+	}
+
+	for _, dash := range data.Dashboards {
+		if err := insert(dashboardTrashItemDashboard, dash); err != nil {
+			return err
+		}
+	}
+	for _, version := range data.Versions {
+		if err := insert(dashboardTrashItemVersion, version); err != nil {
+			return err
+		}
+	}
+	for _, element := range data.LibraryElements {
+		if err := insert(dashboardTrashItemLibraryElement, element); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// getDashboardTrashData loads the snapshots of a trashed dashboard.
+func getDashboardTrashData(sess *DBSession, trashID int64) (*dashboardTrashData, error) {
+	var items []*dashboardTrashItem
+	if err := sess.Where("trash_id = ?", trashID).Asc("id").Find(&items); err != nil {
+		return nil, err
+	}
+
+	data := &dashboardTrashData{}
+	for _, item := range items {
+		var err error
+		switch item.Kind {
+		case dashboardTrashItemDashboard:
+			dash := &trashedDashboardData{}
+			err = json.Unmarshal([]byte(item.Data), dash)
+			data.Dashboards = append(data.Dashboards, dash)
+		case dashboardTrashItemVersion:
+			version := &models.DashboardVersion{}
+			err = json.Unmarshal([]byte(item.Data), version)
+			data.Versions = append(data.Versions, version)
+		case dashboardTrashItemLibraryElement:
+			element := &trashedLibraryElement{}
+			err = json.Unmarshal([]byte(item.Data), element)
+			data.LibraryElements = append(data.LibraryElements, element)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	return data, nil
+}
+
+func int64InSlice(v int64, slice []int64) bool {
+	for _, s := range slice {
+		if s == v {
+			return true
+		}
+	}
+	return false
+}
+
+// GetTrashedDashboards lists the dashboards and folders of an organization in the recycle bin, the most recently
+// deleted first.
+func (ss *SQLStore) GetTrashedDashboards(ctx context.Context, query *models.GetTrashedDashboardsQuery) error {
+	return ss.WithDbSession(ctx, func(sess *DBSession) error {
+		query.Result = make([]*models.TrashedDashboard, 0)
+		err := sess.Table("dashboard_trash").
+			Where("org_id = ? AND deleted > ?", query.OrgId, time.Now().Add(-ss.Cfg.DashboardTrashRetention)).
+			Desc("deleted").Find(&query.Result)
+		if err != nil {
+			return err
+		}
+
+		for _, entry := range query.Result {
+			entry.Expires = entry.Deleted.Add(ss.Cfg.DashboardTrashRetention)
+		}
+		return nil
+	})
+}
+
+// RestoreTrashedDashboard restores a dashboard, or a folder and its dashboards, from the recycle bin with their
+// original IDs, versions, permissions and library element connections.
+func (ss *SQLStore) RestoreTrashedDashboard(ctx context.Context, cmd *models.RestoreTrashedDashboardCommand) error {
+	err := ss.WithTransactionalDbSession(ctx, func(sess *DBSession) error {
+		entry := models.TrashedDashboard{}
+		has, err := sess.Table("dashboard_trash").
+			Where("org_id = ? AND id = ? AND deleted > ?", cmd.OrgId, cmd.Id, time.Now().Add(-ss.Cfg.DashboardTrashRetention)).
+			Get(&entry)
+		if err != nil {
+			return err
+		}
+		if !has {
+			return models.ErrTrashedDashboardNotFound
+		}
+
+		data, err := getDashboardTrashData(sess, entry.Id)
+		if err != nil {
+			return err
+		}
+		if len(data.Dashboards) == 0 {
+			return models.ErrTrashedDashboardNotFound
+		}
+
+		if err := restoreDashboards(sess, data); err != nil {
+			return err
+		}
+		for _, dash := range data.Dashboards {
+			if err := restoreDashboardAcl(sess, dash.Acl); err != nil {
+				return err
+			}
+		}
+		if err := restoreLibraryElements(sess, data); err != nil {
+			return err
+		}
+
+		if err := deleteTrashedDashboard(sess, entry.Id); err != nil {
+			return err
+		}
+
+		cmd.Result = make([]*models.Dashboard, 0, len(data.Dashboards))
+		for _, dash := range data.Dashboards {
+			cmd.Result = append(cmd.Result, dash.Dashboard)
+			sess.publishAfterCommit(&events.DashboardSaved{
+				Timestamp: time.Now(),
+				ID:        dash.Dashboard.Id,
+				UID:       dash.Dashboard.Uid,
+				OrgID:     dash.Dashboard.OrgId,
+				IsFolder:  dash.Dashboard.IsFolder,
+			})
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, dash := range cmd.Result {
+		if !dash.IsFolder {
+			ss.dashboardIndex.Update(dash)
+		}
+	}
+	return nil
+}
+
+func restoreDashboards(sess *DBSession, data *dashboardTrashData) error {
+	root := data.Dashboards[0].Dashboard
+	if !root.IsFolder && root.FolderId > 0 {
+		exists, err := sess.Where("id = ? AND org_id = ? AND is_folder = ?", root.FolderId, root.OrgId, dialect.BooleanStr(true)).
+			Exist(&models.Dashboard{})
+		if err != nil {
+			return err
+		}
+		if !exists {
+			trashed, err := sess.Table("dashboard_trash").Where("org_id = ? AND dashboard_id = ?", root.OrgId, root.FolderId).Exist()
+			if err != nil {
+				return err
+			}
+			if trashed {
+				return models.ErrTrashedDashboardFolderDeleted
+			}
+			// The folder was deleted permanently, the dashboard is restored to the General folder.
+			root.FolderId = 0
+		}
+	}
+
+	for _, item := range data.Dashboards {
+		dash := item.Dashboard
+		if exists, err := sess.ID(dash.Id).Exist(&models.Dashboard{}); err != nil {
+			return err
+		} else if exists {
+			return models.ErrTrashedDashboardAlreadyExists
+		}
+		if exists, err := sess.Where("org_id = ? AND uid = ?", dash.OrgId, dash.Uid).Exist(&models.Dashboard{}); err != nil {
+			return err
+		} else if exists {
+			return models.ErrDashboardWithSameUIDExists
+		}
+	}
+	// The dashboards of a folder can't conflict with others as they are restored in the folder.
+	if _, err := getExistingDashboardByTitleAndFolder(sess, root, false, false); err != nil {
+		return err
+	}
+
+	for _, item := range data.Dashboards {
+		if _, err := sess.Insert(item.Dashboard); err != nil {
+			return err
+		}
+		for _, tag := range item.Tags {
+			if _, err := sess.Insert(tag); err != nil {
+				return err
+			}
+		}
+	}
+	for _, version := range data.Versions {
+		if _, err := sess.Insert(version); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// restoreDashboardAcl restores the permissions of dashboards, except the ones of users and teams deleted since.
+func restoreDashboardAcl(sess *DBSession, items []*models.DashboardAcl) error {
+	for _, item := range items {
+		if item.UserID > 0 {
+			if exists, err := sess.ID(item.UserID).Exist(&models.User{}); err != nil || !exists {
+				if err != nil {
+					return err
+				}
+				continue
+			}
+		}
+		if item.TeamID > 0 {
+			if exists, err := sess.ID(item.TeamID).Exist(&models.Team{}); err != nil || !exists {
+				if err != nil {
+					return err
+				}
+				continue
+			}
+		}
+		if _, err := sess.Insert(item); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// restoreLibraryElements restores the library elements of a folder, and the connections of the dashboards to library
+// elements that still exist.
+func restoreLibraryElements(sess *DBSession, data *dashboardTrashData) error {
+	for _, element := range data.LibraryElements {
+		if _, err := sess.Insert(element); err != nil {
+			return err
+		}
+	}
+
+	for _, dash := range data.Dashboards {
+		for _, connection := range dash.LibraryElementConnections {
+			exists, err := sess.ID(connection.ElementID).Exist(&trashedLibraryElement{})
+			if err != nil {
+				return err
+			}
+			if !exists {
+				continue
+			}
+			if _, err := sess.Insert(connection); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// DeleteTrashedDashboard permanently deletes a dashboard, or a folder and its dashboards, from the recycle bin.
+func (ss *SQLStore) DeleteTrashedDashboard(ctx context.Context, cmd *models.DeleteTrashedDashboardCommand) error {
+	return ss.WithTransactionalDbSession(ctx, func(sess *DBSession) error {
+		exists, err := sess.Table("dashboard_trash").Where("org_id = ? AND id = ?", cmd.OrgId, cmd.Id).Exist()
+		if err != nil {
+			return err
+		}
+		if !exists {
+			return models.ErrTrashedDashboardNotFound
+		}
+		return deleteTrashedDashboard(sess, cmd.Id)
+	})
+}
+
+func deleteTrashedDashboard(sess *DBSession, id int64) error {
+	if _, err := sess.Exec("DELETE FROM dashboard_trash_item WHERE trash_id = ?", id); err != nil {
+		return err
+	}
+	_, err := sess.Exec("DELETE FROM dashboard_trash WHERE id = ?", id)
+	return err
+}
+
+// DeleteExpiredTrashedDashboards permanently deletes the dashboards and folders kept in the recycle bin for longer
+// than the retention, or all of them when the recycle bin is disabled.
+func (ss *SQLStore) DeleteExpiredTrashedDashboards(ctx context.Context, cmd *models.DeleteExpiredTrashedDashboardsCommand) error {
+	return ss.WithTransactionalDbSession(ctx, func(sess *DBSession) error {
+		expired := time.Now().Add(-ss.Cfg.DashboardTrashRetention)
+		_, err := sess.Exec("DELETE FROM dashboard_trash_item WHERE trash_id IN (SELECT id FROM dashboard_trash WHERE deleted <= ?)", expired)
+		if err != nil {
+			return err
+		}
+		res, err := sess.Exec("DELETE FROM dashboard_trash WHERE deleted <= ?", expired)
+		if err != nil {
+			return err
+		}
+		cmd.DeletedRows, _ = res.RowsAffected()
+		return nil
+	})
+}
@@ -0,0 +1,238 @@
+//go:build integration
+// +build integration
+
+package sqlstore
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/events"
+	"github.com/grafana/grafana/pkg/models"
+)
+
+func TestDashboardTrash(t *testing.T) {
+	sqlStore := InitTestDB(t)
+	sqlStore.Cfg.DashboardTrashRetention = 24 * time.Hour
+	ctx := context.Background()
+
+	user := createUser(t, sqlStore, "trash", "Viewer", false)
+
+	countRows := func(table, where string, args ...interface{}) int64 {
+		t.Helper()
+		count, err := sqlStore.engine.Table(table).Where(where, args...).Count()
+		require.NoError(t, err)
+		return count
+	}
+
+	trashed := func() []*models.TrashedDashboard {
+		t.Helper()
+		query := models.GetTrashedDashboardsQuery{OrgId: 1}
+		require.NoError(t, sqlStore.GetTrashedDashboards(ctx, &query))
+		return query.Result
+	}
+
+	t.Run("Deleting a dashboard moves it to the trash and restores it with its permissions and library panels", func(t *testing.T) {
+		dash := insertTestDashboard(t, sqlStore, "trash dashboard", 1, 0, false, "prod")
+		require.NoError(t, sqlStore.UpdateDashboardACL(ctx, dash.Id, []*models.DashboardAcl{
+			{OrgID: 1, DashboardID: dash.Id, UserID: user.Id, Permission: models.PERMISSION_EDIT, Created: time.Now(), Updated: time.Now()},
+		}))
+		element := &trashedLibraryElement{OrgID: 1, UID: "trash-panel", Name: "trash panel", Kind: 1, Model: "{}", Created: time.Now(), Updated: time.Now()}
+		_, err := sqlStore.engine.Insert(element)
+		require.NoError(t, err)
+		_, err = sqlStore.engine.Insert(&trashedLibraryElementConnection{
+			ElementID: element.ID, Kind: libraryElementConnectionKindDashboard, ConnectionID: dash.Id, Created: time.Now(),
+		})
+		require.NoError(t, err)
+
+		err = sqlStore.DeleteDashboard(ctx, &models.DeleteDashboardCommand{Id: dash.Id, OrgId: 1, MoveToTrash: true})
+		require.NoError(t, err)
+
+		require.Equal(t, int64(0), countRows("dashboard", "id = ?", dash.Id))
+		require.Equal(t, int64(0), countRows("dashboard_acl", "dashboard_id = ?", dash.Id))
+		require.Equal(t, int64(0), countRows(models.LibraryElementConnectionTableName, "connection_id = ?", dash.Id))
+
+		entries := trashed()
+		require.Len(t, entries, 1)
+		require.Equal(t, dash.Id, entries[0].DashboardId)
+		require.Equal(t, dash.Uid, entries[0].DashboardUid)
+		require.Equal(t, "trash dashboard", entries[0].Title)
+		require.False(t, entries[0].IsFolder)
+		require.WithinDuration(t, entries[0].Deleted.Add(24*time.Hour), entries[0].Expires, time.Second)
+
+		cmd := models.RestoreTrashedDashboardCommand{Id: entries[0].Id, OrgId: 1}
+		require.NoError(t, sqlStore.RestoreTrashedDashboard(ctx, &cmd))
+		require.Len(t, cmd.Result, 1)
+		require.Empty(t, trashed())
+
+		query := models.GetDashboardQuery{Uid: dash.Uid, OrgId: 1}
+		require.NoError(t, sqlStore.GetDashboard(ctx, &query))
+		require.Equal(t, dash.Id, query.Result.Id)
+		require.Equal(t, "trash dashboard", query.Result.Title)
+		require.Equal(t, int64(1), countRows("dashboard_tag", "dashboard_id = ? AND term = ?", dash.Id, "prod"))
+		require.Equal(t, int64(1), countRows("dashboard_version", "dashboard_id = ?", dash.Id))
+		require.Equal(t, int64(1), countRows("dashboard_acl", "dashboard_id = ? AND user_id = ?", dash.Id, user.Id))
+		require.Equal(t, int64(1), countRows(models.LibraryElementConnectionTableName, "element_id = ? AND connection_id = ?", element.ID, dash.Id))
+	})
+
+	t.Run("Deleting a folder moves it to the trash with its dashboards and library elements", func(t *testing.T) {
+		folder := insertTestDashboard(t, sqlStore, "trash folder", 1, 0, true)
+		child := insertTestDashboard(t, sqlStore, "trash child", 1, folder.Id, false)
+		element := &trashedLibraryElement{OrgID: 1, FolderID: folder.Id, UID: "trash-folder-panel", Name: "trash folder panel", Kind: 1, Model: "{}", Created: time.Now(), Updated: time.Now()}
+		_, err := sqlStore.engine.Insert(element)
+		require.NoError(t, err)
+		_, err = sqlStore.engine.Insert(&trashedLibraryElementConnection{
+			ElementID: element.ID, Kind: libraryElementConnectionKindDashboard, ConnectionID: child.Id, Created: time.Now(),
+		})
+		require.NoError(t, err)
+
+		err = sqlStore.DeleteDashboard(ctx, &models.DeleteDashboardCommand{Id: folder.Id, OrgId: 1, MoveToTrash: true})
+		require.NoError(t, err)
+		require.Equal(t, int64(0), countRows("dashboard", "id IN (?, ?)", folder.Id, child.Id))
+		require.Equal(t, int64(0), countRows("library_element", "id = ?", element.ID))
+
+		entries := trashed()
+		require.Len(t, entries, 1)
+		require.True(t, entries[0].IsFolder)
+		require.Equal(t, 1, entries[0].Dashboards)
+
+		t.Run("Restoring the folder restores its dashboards", func(t *testing.T) {
+			cmd := models.RestoreTrashedDashboardCommand{Id: entries[0].Id, OrgId: 1}
+			require.NoError(t, sqlStore.RestoreTrashedDashboard(ctx, &cmd))
+			require.Len(t, cmd.Result, 2)
+			require.Equal(t, int64(0), countRows("dashboard_trash_item", "trash_id = ?", entries[0].Id))
+
+			require.Equal(t, int64(1), countRows("dashboard", "id = ? AND folder_id = ?", child.Id, folder.Id))
+			require.Equal(t, int64(1), countRows("library_element", "id = ? AND folder_id = ?", element.ID, folder.Id))
+			require.Equal(t, int64(1), countRows(models.LibraryElementConnectionTableName, "element_id = ? AND connection_id = ?", element.ID, child.Id))
+		})
+
+		t.Run("A dashboard can't be restored before its folder", func(t *testing.T) {
+			require.NoError(t, sqlStore.DeleteDashboard(ctx, &models.DeleteDashboardCommand{Id: child.Id, OrgId: 1, MoveToTrash: true}))
+			require.NoError(t, sqlStore.DeleteDashboard(ctx, &models.DeleteDashboardCommand{Id: folder.Id, OrgId: 1, MoveToTrash: true}))
+
+			entries := trashed()
+			require.Len(t, entries, 2)
+			require.Equal(t, child.Id, entries[1].DashboardId)
+
+			cmd := models.RestoreTrashedDashboardCommand{Id: entries[1].Id, OrgId: 1}
+			require.Equal(t, models.ErrTrashedDashboardFolderDeleted, sqlStore.RestoreTrashedDashboard(ctx, &cmd))
+
+			require.NoError(t, sqlStore.DeleteTrashedDashboard(ctx, &models.DeleteTrashedDashboardCommand{Id: entries[0].Id, OrgId: 1}))
+			require.NoError(t, sqlStore.RestoreTrashedDashboard(ctx, &cmd))
+			require.Equal(t, int64(1), countRows("dashboard", "id = ? AND folder_id = 0", child.Id))
+		})
+	})
+
+	t.Run("Provisioned dashboards of a folder are deleted permanently", func(t *testing.T) {
+		folder := insertTestDashboard(t, sqlStore, "trash provisioned folder", 1, 0, true)
+		child := insertTestDashboard(t, sqlStore, "trash child", 1, folder.Id, false)
+		provisioned, err := sqlStore.SaveProvisionedDashboard(models.SaveDashboardCommand{
+			OrgId:    1,
+			FolderId: folder.Id,
+			Dashboard: simplejson.NewFromAny(map[string]interface{}{
+				"title": "trash provisioned",
+			}),
+		}, &models.DashboardProvisioning{Name: "default", ExternalId: "/var/trash.json", Updated: time.Now().Unix()})
+		require.NoError(t, err)
+
+		require.NoError(t, sqlStore.DeleteDashboard(ctx, &models.DeleteDashboardCommand{Id: folder.Id, OrgId: 1, MoveToTrash: true}))
+		entries := trashed()
+		require.Equal(t, folder.Id, entries[0].DashboardId)
+		require.Equal(t, 1, entries[0].Dashboards)
+
+		var saved []*events.DashboardSaved
+		bus.AddEventListener(func(ctx context.Context, e *events.DashboardSaved) error {
+			saved = append(saved, e)
+			return nil
+		})
+
+		cmd := models.RestoreTrashedDashboardCommand{Id: entries[0].Id, OrgId: 1}
+		require.NoError(t, sqlStore.RestoreTrashedDashboard(ctx, &cmd))
+		require.Len(t, cmd.Result, 2)
+		require.Equal(t, int64(1), countRows("dashboard", "id = ?", child.Id))
+		require.Equal(t, int64(0), countRows("dashboard", "id = ?", provisioned.Id))
+		require.Len(t, saved, 2)
+		require.Equal(t, folder.Id, saved[0].ID)
+		require.Equal(t, child.Id, saved[1].ID)
+	})
+
+	t.Run("Only the latest versions of a dashboard are kept", func(t *testing.T) {
+		dash := insertTestDashboard(t, sqlStore, "trash versions", 1, 0, false)
+		for i := 0; i < dashboardTrashMaxVersions+2; i++ {
+			updateTestDashboard(t, sqlStore, dash, map[string]interface{}{"title": "trash versions", "tags": []string{fmt.Sprint(i)}})
+		}
+		query := models.GetDashboardQuery{Id: dash.Id, OrgId: 1}
+		require.NoError(t, sqlStore.GetDashboard(ctx, &query))
+		dash = query.Result
+		require.NoError(t, sqlStore.DeleteDashboard(ctx, &models.DeleteDashboardCommand{Id: dash.Id, OrgId: 1, MoveToTrash: true}))
+
+		entries := trashed()
+		require.Equal(t, dash.Id, entries[0].DashboardId)
+		cmd := models.RestoreTrashedDashboardCommand{Id: entries[0].Id, OrgId: 1}
+		require.NoError(t, sqlStore.RestoreTrashedDashboard(ctx, &cmd))
+		require.Equal(t, int64(dashboardTrashMaxVersions), countRows("dashboard_version", "dashboard_id = ?", dash.Id))
+		require.Equal(t, int64(1), countRows("dashboard_version", "dashboard_id = ? AND version = ?", dash.Id, dash.Version))
+	})
+
+	t.Run("A folder with library elements used by other dashboards can't be trashed", func(t *testing.T) {
+		folder := insertTestDashboard(t, sqlStore, "trash connected folder", 1, 0, true)
+		other := insertTestDashboard(t, sqlStore, "trash other dashboard", 1, 0, false)
+		element := &trashedLibraryElement{OrgID: 1, FolderID: folder.Id, UID: "trash-connected-panel", Name: "trash connected panel", Kind: 1, Model: "{}", Created: time.Now(), Updated: time.Now()}
+		_, err := sqlStore.engine.Insert(element)
+		require.NoError(t, err)
+		_, err = sqlStore.engine.Insert(&trashedLibraryElementConnection{
+			ElementID: element.ID, Kind: libraryElementConnectionKindDashboard, ConnectionID: other.Id, Created: time.Now(),
+		})
+		require.NoError(t, err)
+
+		err = sqlStore.DeleteDashboard(ctx, &models.DeleteDashboardCommand{Id: folder.Id, OrgId: 1, MoveToTrash: true})
+		require.ErrorIs(t, err, models.ErrFolderHasConnectedLibraryElements)
+		require.Equal(t, int64(1), countRows("dashboard", "id = ?", folder.Id))
+	})
+
+	t.Run("A dashboard conflicting with a new one isn't restored", func(t *testing.T) {
+		dash := insertTestDashboard(t, sqlStore, "trash conflict", 1, 0, false)
+		require.NoError(t, sqlStore.DeleteDashboard(ctx, &models.DeleteDashboardCommand{Id: dash.Id, OrgId: 1, MoveToTrash: true}))
+		insertTestDashboard(t, sqlStore, "trash conflict", 1, 0, false)
+
+		entries := trashed()
+		require.Equal(t, dash.Id, entries[0].DashboardId)
+		cmd := models.RestoreTrashedDashboardCommand{Id: entries[0].Id, OrgId: 1}
+		require.Equal(t, models.ErrDashboardWithSameNameInFolderExists, sqlStore.RestoreTrashedDashboard(ctx, &cmd))
+		require.Len(t, trashed(), len(entries))
+	})
+
+	t.Run("Dashboards are deleted permanently without MoveToTrash or retention", func(t *testing.T) {
+		before := len(trashed())
+		dash := insertTestDashboard(t, sqlStore, "trash permanent", 1, 0, false)
+		require.NoError(t, sqlStore.DeleteDashboard(ctx, &models.DeleteDashboardCommand{Id: dash.Id, OrgId: 1}))
+		require.Len(t, trashed(), before)
+
+		sqlStore.Cfg.DashboardTrashRetention = 0
+		t.Cleanup(func() { sqlStore.Cfg.DashboardTrashRetention = 24 * time.Hour })
+		dash = insertTestDashboard(t, sqlStore, "trash disabled", 1, 0, false)
+		require.NoError(t, sqlStore.DeleteDashboard(ctx, &models.DeleteDashboardCommand{Id: dash.Id, OrgId: 1, MoveToTrash: true}))
+		require.Equal(t, int64(before), countRows("dashboard_trash", "org_id = ?", 1))
+	})
+
+	t.Run("Expired dashboards are deleted permanently", func(t *testing.T) {
+		entries := trashed()
+		require.NotEmpty(t, entries)
+		_, err := sqlStore.engine.Exec("UPDATE dashboard_trash SET deleted = ? WHERE id = ?", time.Now().Add(-48*time.Hour), entries[0].Id)
+		require.NoError(t, err)
+		require.Len(t, trashed(), len(entries)-1)
+
+		cmd := models.DeleteExpiredTrashedDashboardsCommand{}
+		require.NoError(t, sqlStore.DeleteExpiredTrashedDashboards(ctx, &cmd))
+		require.Equal(t, int64(1), cmd.DeletedRows)
+		require.Equal(t, models.ErrTrashedDashboardNotFound,
+			sqlStore.DeleteTrashedDashboard(ctx, &models.DeleteTrashedDashboardCommand{Id: entries[0].Id, OrgId: 1}))
+	})
+}
@@ -53,6 +53,7 @@ func (ss *SQLStore) GetDashboardVersions(ctx context.Context, query *models.GetD
 				dashboard_version.created,
 				dashboard_version.created_by as created_by_id,
 				dashboard_version.message,
+				dashboard_version.changes,
 				dashboard_version.data,`+
 				dialect.Quote("user")+`.login as created_by`).
 			Join("LEFT", dialect.Quote("user"), `dashboard_version.created_by = `+dialect.Quote("user")+`.id`).
@@ -80,6 +81,12 @@ func (ss *SQLStore) DeleteExpiredVersions(ctx context.Context, cmd *models.Delet
 }
 
 func (ss *SQLStore) deleteExpiredVersions(ctx context.Context, cmd *models.DeleteExpiredVersionsCommand, perBatch int, maxBatches int) error {
+	deleted, err := ss.deleteVersionsByRetentionPolicies(ctx, perBatch)
+	cmd.DeletedRows += deleted
+	if err != nil {
+		return err
+	}
+
 	versionsToKeep := setting.DashboardVersionsToKeep
 	if versionsToKeep < 1 {
 		versionsToKeep = 1
@@ -93,10 +100,12 @@ func (ss *SQLStore) deleteExpiredVersions(ctx context.Context, cmd *models.Delet
 			// min_version_to_keep = min_version + (versions_count - versions_to_keep)
 			// where version stats is processed for each dashboard. This guarantees that we keep at least versions_to_keep
 			// versions, but in some cases (when versions are sparse) this number may be more.
+			// Dashboards with a retention policy are skipped.
 			versionIdsToDeleteQuery := `SELECT id
 				FROM dashboard_version, (
 					SELECT dashboard_id, count(version) as count, min(version) as min
 					FROM dashboard_version
+					WHERE dashboard_id NOT IN ` + retentionPolicyDashboards + `
 					GROUP BY dashboard_id
 				) AS vtd
 				WHERE dashboard_version.dashboard_id=vtd.dashboard_id
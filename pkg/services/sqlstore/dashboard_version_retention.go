@@ -0,0 +1,252 @@
+package sqlstore
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend/gtime"
+
+	"github.com/grafana/grafana/pkg/models"
+)
+
+// GetDashboardVersionRetentionPolicy gets the retention policy of a dashboard or folder.
+func (ss *SQLStore) GetDashboardVersionRetentionPolicy(ctx context.Context, query *models.GetDashboardVersionRetentionPolicyQuery) error {
+	return ss.WithDbSession(ctx, func(sess *DBSession) error {
+		policy := models.DashboardVersionRetentionPolicy{}
+		has, err := sess.Table("dashboard_version_retention").
+			Where("org_id=? AND dashboard_id=?", query.OrgId, query.DashboardId).
+			Get(&policy)
+		if err != nil {
+			return err
+		}
+		if !has {
+			return models.ErrDashboardVersionRetentionPolicyNotFound
+		}
+
+		query.Result = &policy
+		return nil
+	})
+}
+
+// SaveDashboardVersionRetentionPolicy creates or replaces the retention policy of a dashboard or folder.
+func (ss *SQLStore) SaveDashboardVersionRetentionPolicy(ctx context.Context, cmd *models.SaveDashboardVersionRetentionPolicyCommand) error {
+	if _, err := parseRetentionRules(cmd.Rules); err != nil {
+		return err
+	}
+
+	return ss.WithTransactionalDbSession(ctx, func(sess *DBSession) error {
+		policy := models.DashboardVersionRetentionPolicy{}
+		has, err := sess.Table("dashboard_version_retention").
+			Where("org_id=? AND dashboard_id=?", cmd.OrgId, cmd.DashboardId).
+			Get(&policy)
+		if err != nil {
+			return err
+		}
+
+		policy.Rules = cmd.Rules
+		policy.Updated = time.Now()
+		if has {
+			_, err = sess.Table("dashboard_version_retention").ID(policy.Id).Cols("rules", "updated").Update(&policy)
+		} else {
+			policy.OrgId = cmd.OrgId
+			policy.DashboardId = cmd.DashboardId
+			policy.Created = policy.Updated
+			_, err = sess.Table("dashboard_version_retention").Insert(&policy)
+		}
+		if err != nil {
+			return err
+		}
+
+		cmd.Result = &policy
+		return nil
+	})
+}
+
+// DeleteDashboardVersionRetentionPolicy deletes the retention policy of a dashboard or folder.
+func (ss *SQLStore) DeleteDashboardVersionRetentionPolicy(ctx context.Context, cmd *models.DeleteDashboardVersionRetentionPolicyCommand) error {
+	return ss.WithDbSession(ctx, func(sess *DBSession) error {
+		res, err := sess.Exec("DELETE FROM dashboard_version_retention WHERE org_id=? AND dashboard_id=?", cmd.OrgId, cmd.DashboardId)
+		if err != nil {
+			return err
+		}
+		if rows, err := res.RowsAffected(); err == nil && rows == 0 {
+			return models.ErrDashboardVersionRetentionPolicyNotFound
+		}
+		return nil
+	})
+}
+
+// retentionPolicyDashboards is the condition matching dashboards which have a
+// retention policy or are in a folder with a retention policy.
+const retentionPolicyDashboards = `(
+	SELECT dashboard_id FROM dashboard_version_retention
+	UNION
+	SELECT dashboard.id FROM dashboard
+	INNER JOIN dashboard_version_retention ON dashboard_version_retention.dashboard_id = dashboard.folder_id
+)`
+
+// deleteVersionsByRetentionPolicies deletes expired versions of dashboards
+// with a retention policy, or in a folder with a retention policy.
+func (ss *SQLStore) deleteVersionsByRetentionPolicies(ctx context.Context, perBatch int) (int64, error) {
+	policies := map[int64][]retentionRule{}
+	governed := map[int64]int64{}
+
+	err := ss.WithDbSession(ctx, func(sess *DBSession) error {
+		var rows []*models.DashboardVersionRetentionPolicy
+		if err := sess.Table("dashboard_version_retention").Find(&rows); err != nil {
+			return err
+		}
+		for _, p := range rows {
+			rules, err := parseRetentionRules(p.Rules)
+			if err != nil {
+				ss.log.Warn("Skipping invalid dashboard version retention policy", "dashboardId", p.DashboardId, "error", err)
+				continue
+			}
+			policies[p.DashboardId] = rules
+			governed[p.DashboardId] = p.DashboardId
+		}
+		if len(policies) == 0 {
+			return nil
+		}
+
+		var dashboards []struct {
+			Id       int64
+			FolderId int64
+		}
+		err := sess.SQL(`SELECT dashboard.id, dashboard.folder_id FROM dashboard
+			INNER JOIN dashboard_version_retention ON dashboard_version_retention.dashboard_id = dashboard.folder_id`).
+			Find(&dashboards)
+		if err != nil {
+			return err
+		}
+		for _, d := range dashboards {
+			// A policy of the dashboard replaces the policy of its folder.
+			if _, ok := policies[d.Id]; ok {
+				continue
+			}
+			if _, ok := policies[d.FolderId]; ok {
+				governed[d.Id] = d.FolderId
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	var deleted int64
+	now := time.Now()
+	for dashboardID, policyID := range governed {
+		var versions []*retentionVersion
+		err := ss.WithDbSession(ctx, func(sess *DBSession) error {
+			return sess.SQL("SELECT id, created FROM dashboard_version WHERE dashboard_id=? ORDER BY version DESC", dashboardID).
+				Find(&versions)
+		})
+		if err != nil {
+			return deleted, err
+		}
+
+		ids := expiredVersionIDs(policies[policyID], versions, now)
+		for len(ids) > 0 {
+			batch := ids
+			if len(batch) > perBatch {
+				batch = ids[:perBatch]
+			}
+			ids = ids[len(batch):]
+
+			err := ss.WithTransactionalDbSession(ctx, func(sess *DBSession) error {
+				args := make([]interface{}, 0, len(batch)+1)
+				args = append(args, `DELETE FROM dashboard_version WHERE id IN (?`+strings.Repeat(",?", len(batch)-1)+`)`)
+				for _, id := range batch {
+					args = append(args, id)
+				}
+				res, err := sess.Exec(args...)
+				if err != nil {
+					return err
+				}
+				rows, err := res.RowsAffected()
+				deleted += rows
+				return err
+			})
+			if err != nil {
+				return deleted, err
+			}
+		}
+	}
+	return deleted, nil
+}
+
+type retentionRule struct {
+	maxAge   time.Duration
+	interval time.Duration
+}
+
+type retentionVersion struct {
+	Id      int64
+	Created time.Time
+}
+
+// parseRetentionRules validates rules and returns them ordered by age.
+func parseRetentionRules(rules []*models.DashboardVersionRetentionRule) ([]retentionRule, error) {
+	if len(rules) == 0 {
+		return nil, models.ErrDashboardVersionRetentionRulesEmpty
+	}
+
+	parsed := make([]retentionRule, 0, len(rules))
+	for _, r := range rules {
+		maxAge, err := gtime.ParseDuration(r.MaxAge)
+		if err != nil || maxAge <= 0 {
+			return nil, fmt.Errorf("%w: invalid max age %q", models.ErrDashboardVersionRetentionRuleInvalid, r.MaxAge)
+		}
+		rule := retentionRule{maxAge: maxAge}
+		if r.Interval != "" {
+			rule.interval, err = gtime.ParseDuration(r.Interval)
+			if err != nil || rule.interval <= 0 {
+				return nil, fmt.Errorf("%w: invalid interval %q", models.ErrDashboardVersionRetentionRuleInvalid, r.Interval)
+			}
+		}
+		parsed = append(parsed, rule)
+	}
+
+	sort.Slice(parsed, func(i, j int) bool {
+		return parsed[i].maxAge < parsed[j].maxAge
+	})
+	return parsed, nil
+}
+
+// expiredVersionIDs returns the IDs of versions to delete, versions are
+// ordered from the latest. A version is kept by the first rule whose max age
+// it doesn't exceed, if it is the latest version of its interval.
+func expiredVersionIDs(rules []retentionRule, versions []*retentionVersion, now time.Time) []int64 {
+	type bucket struct {
+		rule  int
+		index int64
+	}
+	kept := map[bucket]bool{}
+
+	var ids []int64
+	for i, v := range versions {
+		age := now.Sub(v.Created)
+		rule := sort.Search(len(rules), func(r int) bool {
+			return age <= rules[r].maxAge
+		})
+
+		if rule < len(rules) && rules[rule].interval > 0 {
+			b := bucket{rule: rule, index: v.Created.UnixNano() / int64(rules[rule].interval)}
+			if kept[b] && i > 0 {
+				ids = append(ids, v.Id)
+				continue
+			}
+			kept[b] = true
+		}
+
+		// The latest version is always kept.
+		if rule == len(rules) && i > 0 {
+			ids = append(ids, v.Id)
+		}
+	}
+	return ids
+}
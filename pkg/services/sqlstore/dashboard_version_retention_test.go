@@ -0,0 +1,153 @@
+//go:build integration
+// +build integration
+
+package sqlstore
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+func TestExpiredVersionIDs(t *testing.T) {
+	rules, err := parseRetentionRules([]*models.DashboardVersionRetentionRule{
+		{MaxAge: "1y", Interval: "1d"},
+		{MaxAge: "30d"},
+	})
+	require.NoError(t, err)
+
+	now := time.Date(2022, 6, 1, 12, 0, 0, 0, time.UTC)
+	day := 24 * time.Hour
+	versions := []*retentionVersion{
+		{Id: 9, Created: now.Add(-time.Hour)},
+		{Id: 8, Created: now.Add(-2 * time.Hour)},
+		{Id: 7, Created: now.Add(-29 * day)},
+		{Id: 6, Created: now.Add(-40*day - time.Hour)},
+		{Id: 5, Created: now.Add(-40*day - 2*time.Hour)},
+		{Id: 4, Created: now.Add(-41 * day)},
+		{Id: 3, Created: now.Add(-400 * day)},
+	}
+
+	// Versions of the last 30 days are kept, then the latest version of each day.
+	require.Equal(t, []int64{5, 3}, expiredVersionIDs(rules, versions, now))
+
+	t.Run("keeps the latest version", func(t *testing.T) {
+		require.Empty(t, expiredVersionIDs(rules, []*retentionVersion{{Id: 1, Created: now.Add(-400 * day)}}, now))
+	})
+}
+
+func TestDashboardVersionRetentionPolicy(t *testing.T) {
+	sqlStore := InitTestDB(t)
+	ctx := context.Background()
+
+	t.Run("saves, updates and deletes a policy", func(t *testing.T) {
+		cmd := models.SaveDashboardVersionRetentionPolicyCommand{
+			OrgId:       1,
+			DashboardId: 1,
+			Rules:       []*models.DashboardVersionRetentionRule{{MaxAge: "30d"}},
+		}
+		require.NoError(t, sqlStore.SaveDashboardVersionRetentionPolicy(ctx, &cmd))
+
+		cmd.Rules = []*models.DashboardVersionRetentionRule{{MaxAge: "30d"}, {MaxAge: "1y", Interval: "1d"}}
+		require.NoError(t, sqlStore.SaveDashboardVersionRetentionPolicy(ctx, &cmd))
+
+		query := models.GetDashboardVersionRetentionPolicyQuery{OrgId: 1, DashboardId: 1}
+		require.NoError(t, sqlStore.GetDashboardVersionRetentionPolicy(ctx, &query))
+		require.Equal(t, cmd.Rules, query.Result.Rules)
+
+		require.NoError(t, sqlStore.DeleteDashboardVersionRetentionPolicy(ctx, &models.DeleteDashboardVersionRetentionPolicyCommand{OrgId: 1, DashboardId: 1}))
+		err := sqlStore.GetDashboardVersionRetentionPolicy(ctx, &query)
+		require.ErrorIs(t, err, models.ErrDashboardVersionRetentionPolicyNotFound)
+	})
+
+	t.Run("rejects invalid rules", func(t *testing.T) {
+		cmd := models.SaveDashboardVersionRetentionPolicyCommand{OrgId: 1, DashboardId: 1}
+		require.ErrorIs(t, sqlStore.SaveDashboardVersionRetentionPolicy(ctx, &cmd), models.ErrDashboardVersionRetentionRulesEmpty)
+
+		cmd.Rules = []*models.DashboardVersionRetentionRule{{MaxAge: "forever"}}
+		require.ErrorIs(t, sqlStore.SaveDashboardVersionRetentionPolicy(ctx, &cmd), models.ErrDashboardVersionRetentionRuleInvalid)
+	})
+}
+
+func TestDeleteExpiredVersions_RetentionPolicy(t *testing.T) {
+	versionsToKeep := setting.DashboardVersionsToKeep
+	setting.DashboardVersionsToKeep = 2
+	t.Cleanup(func() { setting.DashboardVersionsToKeep = versionsToKeep })
+	sqlStore := InitTestDB(t)
+	ctx := context.Background()
+
+	folder := insertTestDashboard(t, sqlStore, "retention folder", 1, 0, true)
+	inFolder := insertTestDashboard(t, sqlStore, "retention in folder", 1, folder.Id, false)
+	withPolicy := insertTestDashboard(t, sqlStore, "retention with policy", 1, folder.Id, false)
+	withoutPolicy := insertTestDashboard(t, sqlStore, "retention without policy", 1, 0, false)
+
+	now := time.Now()
+	for _, dash := range []*models.Dashboard{inFolder, withPolicy, withoutPolicy} {
+		for i := 0; i < 4; i++ {
+			_, err := sqlStore.SaveDashboard(models.SaveDashboardCommand{
+				OrgId:     1,
+				FolderId:  dash.FolderId,
+				Overwrite: true,
+				Dashboard: simplejson.NewFromAny(map[string]interface{}{
+					"id":    dash.Id,
+					"title": dash.Title,
+					"tags":  []interface{}{i},
+				}),
+			})
+			require.NoError(t, err)
+		}
+		// Versions 1 to 5 were created 50, 40, 30, 20 and 10 days ago.
+		for version := 1; version <= 5; version++ {
+			created := now.AddDate(0, 0, -60+version*10)
+			_, err := sqlStore.engine.Exec("UPDATE dashboard_version SET created=? WHERE dashboard_id=? AND version=?", created, dash.Id, version)
+			require.NoError(t, err)
+		}
+	}
+
+	for _, cmd := range []*models.SaveDashboardVersionRetentionPolicyCommand{
+		{OrgId: 1, DashboardId: folder.Id, Rules: []*models.DashboardVersionRetentionRule{{MaxAge: "35d"}}},
+		{OrgId: 1, DashboardId: withPolicy.Id, Rules: []*models.DashboardVersionRetentionRule{{MaxAge: "15d"}}},
+	} {
+		require.NoError(t, sqlStore.SaveDashboardVersionRetentionPolicy(ctx, cmd))
+	}
+
+	cmd := models.DeleteExpiredVersionsCommand{}
+	require.NoError(t, sqlStore.DeleteExpiredVersions(ctx, &cmd))
+
+	versions := func(dash *models.Dashboard) []int {
+		query := models.GetDashboardVersionsQuery{DashboardId: dash.Id, OrgId: 1}
+		require.NoError(t, sqlStore.GetDashboardVersions(ctx, &query))
+		var versions []int
+		for _, v := range query.Result {
+			versions = append(versions, v.Version)
+		}
+		return versions
+	}
+
+	require.Equal(t, []int{5, 4, 3}, versions(inFolder), "policy of the folder applies")
+	require.Equal(t, []int{5}, versions(withPolicy), "policy of the dashboard replaces the policy of the folder")
+	require.Equal(t, []int{5, 4}, versions(withoutPolicy), "versions_to_keep applies")
+	require.Equal(t, int64(9), cmd.DeletedRows)
+}
+
+func TestDashboardVersionChanges(t *testing.T) {
+	sqlStore := InitTestDB(t)
+	savedDash := insertTestDashboard(t, sqlStore, "changes", 1, 0, false)
+	updateTestDashboard(t, sqlStore, savedDash, map[string]interface{}{
+		"title":  "changes",
+		"panels": []interface{}{map[string]interface{}{"id": 1, "title": "Latency"}},
+	})
+
+	query := models.GetDashboardVersionsQuery{DashboardId: savedDash.Id, OrgId: 1}
+	require.NoError(t, sqlStore.GetDashboardVersions(context.Background(), &query))
+	require.Len(t, query.Result, 2)
+	require.Len(t, query.Result[0].Changes, 1)
+	require.Equal(t, "Panel 'Latency' added", query.Result[0].Changes[0].Summary)
+	require.Empty(t, query.Result[1].Changes)
+}
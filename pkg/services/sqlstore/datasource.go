@@ -256,10 +256,20 @@ func (ss *SQLStore) UpdateDataSource(ctx context.Context, cmd *models.UpdateData
 			return models.ErrDataSourceUpdatingOldVersion
 		}
 
-		err = updateIsDefaultFlag(ds, sess)
+		if err := updateIsDefaultFlag(ds, sess); err != nil {
+			return err
+		}
 
 		cmd.Result = ds
-		return err
+
+		sess.publishAfterCommit(&events.DataSourceUpdated{
+			Timestamp: time.Now(),
+			Name:      ds.Name,
+			ID:        ds.Id,
+			UID:       ds.Uid,
+			OrgID:     ds.OrgId,
+		})
+		return nil
 	})
 }
 
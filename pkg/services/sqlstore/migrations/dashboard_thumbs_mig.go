@@ -24,4 +24,34 @@ func addDashboardThumbsMigrations(mg *migrator.Migrator) {
 
 	mg.AddMigration("create dashboard_thumbnail table", migrator.NewAddTableMigration(dashThumbs))
 	mg.AddMigration("add unique indexes for dashboard_thumbnail", migrator.NewAddIndexMigration(dashThumbs, dashThumbs.Indices[0]))
+
+	renderJobs := migrator.Table{
+		Name: "dashboard_render_job",
+		Columns: []*migrator.Column{
+			{Name: "id", Type: migrator.DB_BigInt, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "org_id", Type: migrator.DB_BigInt, Nullable: false},
+			{Name: "dashboard_id", Type: migrator.DB_BigInt, Nullable: false},
+			{Name: "dashboard_uid", Type: migrator.DB_NVarchar, Length: 40, Nullable: false},
+			{Name: "dashboard_slug", Type: migrator.DB_NVarchar, Length: 189, Nullable: false},
+			{Name: "dashboard_version", Type: migrator.DB_Int, Nullable: false},
+			{Name: "kind", Type: migrator.DB_NVarchar, Length: 8, Nullable: false},
+			{Name: "theme", Type: migrator.DB_NVarchar, Length: 8, Nullable: false},
+			{Name: "state", Type: migrator.DB_NVarchar, Length: 10, Nullable: false}, // pending | running | failed
+			{Name: "priority", Type: migrator.DB_BigInt, Nullable: false, Default: "0"},
+			{Name: "renderer", Type: migrator.DB_NVarchar, Length: 255, Nullable: false, Default: "''"},
+			{Name: "attempts", Type: migrator.DB_Int, Nullable: false, Default: "0"},
+			{Name: "last_error", Type: migrator.DB_Text, Nullable: true},
+			{Name: "next_attempt", Type: migrator.DB_DateTime, Nullable: false},
+			{Name: "created", Type: migrator.DB_DateTime, Nullable: false},
+			{Name: "updated", Type: migrator.DB_DateTime, Nullable: false},
+		},
+		Indices: []*migrator.Index{
+			{Cols: []string{"dashboard_id", "kind", "theme"}, Type: migrator.UniqueIndex},
+			{Cols: []string{"state", "priority"}},
+		},
+	}
+
+	mg.AddMigration("create dashboard_render_job table", migrator.NewAddTableMigration(renderJobs))
+	mg.AddMigration("add unique index dashboard_render_job.dashboard_id_kind_theme", migrator.NewAddIndexMigration(renderJobs, renderJobs.Indices[0]))
+	mg.AddMigration("add index dashboard_render_job.state_priority", migrator.NewAddIndexMigration(renderJobs, renderJobs.Indices[1]))
 }
@@ -57,3 +57,27 @@ FROM dashboard;`
 	mg.AddMigration("alter dashboard_version.data to mediumtext v1", NewRawSQLMigration("").
 		Mysql("ALTER TABLE dashboard_version MODIFY data MEDIUMTEXT;"))
 }
+
+func addDashboardVersionRetentionMigrations(mg *Migrator) {
+	mg.AddMigration("Add column changes in dashboard_version", NewAddColumnMigration(Table{Name: "dashboard_version"}, &Column{
+		Name: "changes", Type: DB_Text, Nullable: true,
+	}))
+
+	retentionPolicy := Table{
+		Name: "dashboard_version_retention",
+		Columns: []*Column{
+			{Name: "id", Type: DB_BigInt, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "org_id", Type: DB_BigInt, Nullable: false},
+			{Name: "dashboard_id", Type: DB_BigInt, Nullable: false},
+			{Name: "rules", Type: DB_Text, Nullable: false},
+			{Name: "created", Type: DB_DateTime, Nullable: false},
+			{Name: "updated", Type: DB_DateTime, Nullable: false},
+		},
+		Indices: []*Index{
+			{Cols: []string{"org_id", "dashboard_id"}, Type: UniqueIndex},
+		},
+	}
+
+	mg.AddMigration("create dashboard_version_retention table", NewAddTableMigration(retentionPolicy))
+	mg.AddMigration("add unique index dashboard_version_retention.org_id_dashboard_id", NewAddIndexMigration(retentionPolicy, retentionPolicy.Indices[0]))
+}
@@ -22,3 +22,91 @@ func addLiveChannelMigrations(mg *migrator.Migrator) {
 	//mg.AddMigration("create live message table", migrator.NewAddTableMigration(liveMessage))
 	//mg.AddMigration("add index live_message.org_id_channel_unique", migrator.NewAddIndexMigration(liveMessage, liveMessage.Indices[0]))
 }
+
+func addLiveHistoryMigrations(mg *migrator.Migrator) {
+	liveHistory := migrator.Table{
+		Name: "live_history",
+		Columns: []*migrator.Column{
+			{Name: "id", Type: migrator.DB_BigInt, Nullable: false, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "org_id", Type: migrator.DB_BigInt, Nullable: false},
+			{Name: "channel", Type: migrator.DB_NVarchar, Length: 189, Nullable: false},
+			{Name: "message_offset", Type: migrator.DB_BigInt, Nullable: false},
+			{Name: "data", Type: migrator.DB_MediumText, Nullable: false},
+			{Name: "published", Type: migrator.DB_DateTime, Nullable: false},
+		},
+		Indices: []*migrator.Index{
+			{Cols: []string{"org_id", "channel", "message_offset"}, Type: migrator.UniqueIndex},
+		},
+	}
+
+	mg.AddMigration("create live history table", migrator.NewAddTableMigration(liveHistory))
+	mg.AddMigration("add unique index live_history.org_id_channel_message_offset", migrator.NewAddIndexMigration(liveHistory, liveHistory.Indices[0]))
+}
+
+func addLivePipelineMigrations(mg *migrator.Migrator) {
+	channelRule := migrator.Table{
+		Name: "live_channel_rule",
+		Columns: []*migrator.Column{
+			{Name: "id", Type: migrator.DB_BigInt, Nullable: false, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "org_id", Type: migrator.DB_BigInt, Nullable: false},
+			{Name: "pattern", Type: migrator.DB_NVarchar, Length: 189, Nullable: false},
+			{Name: "settings", Type: migrator.DB_MediumText, Nullable: false},
+			{Name: "version", Type: migrator.DB_BigInt, Nullable: false},
+			{Name: "created", Type: migrator.DB_DateTime, Nullable: false},
+			{Name: "updated", Type: migrator.DB_DateTime, Nullable: false},
+			{Name: "created_by", Type: migrator.DB_BigInt, Nullable: false},
+			{Name: "updated_by", Type: migrator.DB_BigInt, Nullable: false},
+		},
+		Indices: []*migrator.Index{
+			{Cols: []string{"org_id", "pattern"}, Type: migrator.UniqueIndex},
+		},
+	}
+
+	mg.AddMigration("create live channel rule table", migrator.NewAddTableMigration(channelRule))
+	mg.AddMigration("add unique index live_channel_rule.org_id_pattern", migrator.NewAddIndexMigration(channelRule, channelRule.Indices[0]))
+
+	writeConfig := migrator.Table{
+		Name: "live_write_config",
+		Columns: []*migrator.Column{
+			{Name: "id", Type: migrator.DB_BigInt, Nullable: false, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "org_id", Type: migrator.DB_BigInt, Nullable: false},
+			{Name: "uid", Type: migrator.DB_NVarchar, Length: 40, Nullable: false},
+			{Name: "settings", Type: migrator.DB_Text, Nullable: false},
+			{Name: "secure_settings", Type: migrator.DB_Text, Nullable: true},
+			{Name: "version", Type: migrator.DB_BigInt, Nullable: false},
+			{Name: "created", Type: migrator.DB_DateTime, Nullable: false},
+			{Name: "updated", Type: migrator.DB_DateTime, Nullable: false},
+			{Name: "created_by", Type: migrator.DB_BigInt, Nullable: false},
+			{Name: "updated_by", Type: migrator.DB_BigInt, Nullable: false},
+		},
+		Indices: []*migrator.Index{
+			{Cols: []string{"org_id", "uid"}, Type: migrator.UniqueIndex},
+		},
+	}
+
+	mg.AddMigration("create live write config table", migrator.NewAddTableMigration(writeConfig))
+	mg.AddMigration("add unique index live_write_config.org_id_uid", migrator.NewAddIndexMigration(writeConfig, writeConfig.Indices[0]))
+
+	// Versions of both channel rules and write configs, where name is a rule
+	// pattern or a write config UID. Secure settings are never kept here.
+	pipelineVersion := migrator.Table{
+		Name: "live_pipeline_version",
+		Columns: []*migrator.Column{
+			{Name: "id", Type: migrator.DB_BigInt, Nullable: false, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "org_id", Type: migrator.DB_BigInt, Nullable: false},
+			{Name: "kind", Type: migrator.DB_NVarchar, Length: 20, Nullable: false},
+			{Name: "name", Type: migrator.DB_NVarchar, Length: 189, Nullable: false},
+			{Name: "version", Type: migrator.DB_BigInt, Nullable: false},
+			{Name: "action", Type: migrator.DB_NVarchar, Length: 20, Nullable: false},
+			{Name: "data", Type: migrator.DB_MediumText, Nullable: false},
+			{Name: "created", Type: migrator.DB_DateTime, Nullable: false},
+			{Name: "created_by", Type: migrator.DB_BigInt, Nullable: false},
+		},
+		Indices: []*migrator.Index{
+			{Cols: []string{"org_id", "kind", "name", "version"}, Type: migrator.UniqueIndex},
+		},
+	}
+
+	mg.AddMigration("create live pipeline version table", migrator.NewAddTableMigration(pipelineVersion))
+	mg.AddMigration("add unique index live_pipeline_version.org_id_kind_name_version", migrator.NewAddIndexMigration(pipelineVersion, pipelineVersion.Indices[0]))
+}
@@ -72,6 +72,11 @@ func (*OSSMigrations) AddMigration(mg *Migrator) {
 	ualert.AddDashboardUIDPanelIDMigration(mg)
 	accesscontrol.AddMigration(mg)
 	addQueryHistoryMigrations(mg)
+	addLiveHistoryMigrations(mg)
+	addLivePipelineMigrations(mg)
+	addDashboardVersionRetentionMigrations(mg)
+	addReportMigrations(mg)
+	addDashboardTrashMigrations(mg)
 
 	if mg.Cfg != nil && mg.Cfg.IsFeatureToggleEnabled != nil {
 		if mg.Cfg.IsFeatureToggleEnabled(featuremgmt.FlagAccesscontrol) {
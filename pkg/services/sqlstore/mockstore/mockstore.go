@@ -13,23 +13,25 @@ type OrgListResponse []struct {
 	Response error
 }
 type SQLStoreMock struct {
-	LastGetAlertsQuery           *models.GetAlertsQuery
-	LatestUserId                 int64
-	ExpectedUser                 *models.User
-	ExpectedDatasource           *models.DataSource
-	ExpectedAlert                *models.Alert
-	ExpectedPluginSetting        *models.PluginSetting
-	ExpectedDashboard            *models.Dashboard
-	ExpectedDashboards           []*models.Dashboard
-	ExpectedDashboardVersions    []*models.DashboardVersion
-	ExpectedDashboardAclInfoList []*models.DashboardAclInfoDTO
-	ExpectedUserOrgList          []*models.UserOrgDTO
-	ExpectedOrgListResponse      OrgListResponse
-	ExpectedDashboardSnapshot    *models.DashboardSnapshot
-	ExpectedTeamsByUser          []*models.TeamDTO
-	ExpectedSearchOrgList        []*models.OrgDTO
-	ExpectedDatasources          []*models.DataSource
-	ExpectedOrg                  *models.Org
+	LastGetAlertsQuery                      *models.GetAlertsQuery
+	LatestUserId                            int64
+	ExpectedUser                            *models.User
+	ExpectedDatasource                      *models.DataSource
+	ExpectedAlert                           *models.Alert
+	ExpectedPluginSetting                   *models.PluginSetting
+	ExpectedDashboard                       *models.Dashboard
+	ExpectedDashboards                      []*models.Dashboard
+	ExpectedDashboardVersions               []*models.DashboardVersion
+	ExpectedDashboardVersionRetentionPolicy *models.DashboardVersionRetentionPolicy
+	ExpectedTrashedDashboards               []*models.TrashedDashboard
+	ExpectedDashboardAclInfoList            []*models.DashboardAclInfoDTO
+	ExpectedUserOrgList                     []*models.UserOrgDTO
+	ExpectedOrgListResponse                 OrgListResponse
+	ExpectedDashboardSnapshot               *models.DashboardSnapshot
+	ExpectedTeamsByUser                     []*models.TeamDTO
+	ExpectedSearchOrgList                   []*models.OrgDTO
+	ExpectedDatasources                     []*models.DataSource
+	ExpectedOrg                             *models.Org
 
 	ExpectedError error
 }
@@ -357,6 +359,38 @@ func (m *SQLStoreMock) DeleteExpiredVersions(ctx context.Context, cmd *models.De
 	return m.ExpectedError
 }
 
+func (m *SQLStoreMock) GetDashboardVersionRetentionPolicy(ctx context.Context, query *models.GetDashboardVersionRetentionPolicyQuery) error {
+	query.Result = m.ExpectedDashboardVersionRetentionPolicy
+	return m.ExpectedError
+}
+
+func (m *SQLStoreMock) SaveDashboardVersionRetentionPolicy(ctx context.Context, cmd *models.SaveDashboardVersionRetentionPolicyCommand) error {
+	cmd.Result = &models.DashboardVersionRetentionPolicy{DashboardId: cmd.DashboardId, OrgId: cmd.OrgId, Rules: cmd.Rules}
+	return m.ExpectedError
+}
+
+func (m *SQLStoreMock) DeleteDashboardVersionRetentionPolicy(ctx context.Context, cmd *models.DeleteDashboardVersionRetentionPolicyCommand) error {
+	return m.ExpectedError
+}
+
+func (m *SQLStoreMock) GetTrashedDashboards(ctx context.Context, query *models.GetTrashedDashboardsQuery) error {
+	query.Result = m.ExpectedTrashedDashboards
+	return m.ExpectedError
+}
+
+func (m *SQLStoreMock) RestoreTrashedDashboard(ctx context.Context, cmd *models.RestoreTrashedDashboardCommand) error {
+	cmd.Result = m.ExpectedDashboards
+	return m.ExpectedError
+}
+
+func (m *SQLStoreMock) DeleteTrashedDashboard(ctx context.Context, cmd *models.DeleteTrashedDashboardCommand) error {
+	return m.ExpectedError
+}
+
+func (m *SQLStoreMock) DeleteExpiredTrashedDashboards(ctx context.Context, cmd *models.DeleteExpiredTrashedDashboardsCommand) error {
+	return m.ExpectedError
+}
+
 func (m *SQLStoreMock) UpdateDashboardACL(ctx context.Context, dashboardID int64, items []*models.DashboardAcl) error {
 	return m.ExpectedError
 }
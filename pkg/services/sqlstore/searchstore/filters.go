@@ -2,6 +2,7 @@ package searchstore
 
 import (
 	"fmt"
+	"strconv"
 	"strings"
 
 	"github.com/grafana/grafana/pkg/services/sqlstore/migrator"
@@ -102,6 +103,30 @@ func (f DashboardFilter) Where() (string, []interface{}) {
 	return sqlIDin("dashboard.id", f.IDs)
 }
 
+// DashboardIDsFilter limits results to dashboards with the given IDs, like
+// DashboardFilter. IDs are inlined in the query instead of being bound, so
+// that long lists don't exceed the parameter limits of databases.
+type DashboardIDsFilter struct {
+	IDs []int64
+}
+
+func (f DashboardIDsFilter) Where() (string, []interface{}) {
+	if len(f.IDs) == 0 {
+		return "", nil
+	}
+
+	var sb strings.Builder
+	sb.WriteString("dashboard.id IN (")
+	for i, id := range f.IDs {
+		if i > 0 {
+			sb.WriteString(",")
+		}
+		sb.WriteString(strconv.FormatInt(id, 10))
+	}
+	sb.WriteString(")")
+	return sb.String(), nil
+}
+
 type TagsFilter struct {
 	Tags []string
 }
@@ -134,6 +159,26 @@ func (s TitleSorter) OrderBy() string {
 	return "dashboard.title ASC"
 }
 
+// RankSorter orders dashboards by their position in IDs, for example by the
+// relevance of full-text search results.
+type RankSorter struct {
+	IDs []int64
+}
+
+func (s RankSorter) OrderBy() string {
+	if len(s.IDs) == 0 {
+		return TitleSorter{}.OrderBy()
+	}
+
+	var sb strings.Builder
+	sb.WriteString("CASE dashboard.id")
+	for i, id := range s.IDs {
+		sb.WriteString(fmt.Sprintf(" WHEN %d THEN %d", id, i))
+	}
+	sb.WriteString(fmt.Sprintf(" ELSE %d END", len(s.IDs)))
+	return sb.String()
+}
+
 func sqlIDin(column string, ids []int64) (string, []interface{}) {
 	length := len(ids)
 	if length < 1 {
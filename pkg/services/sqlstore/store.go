@@ -83,6 +83,13 @@ type Store interface {
 	GetDashboardVersion(ctx context.Context, query *models.GetDashboardVersionQuery) error
 	GetDashboardVersions(ctx context.Context, query *models.GetDashboardVersionsQuery) error
 	DeleteExpiredVersions(ctx context.Context, cmd *models.DeleteExpiredVersionsCommand) error
+	GetDashboardVersionRetentionPolicy(ctx context.Context, query *models.GetDashboardVersionRetentionPolicyQuery) error
+	SaveDashboardVersionRetentionPolicy(ctx context.Context, cmd *models.SaveDashboardVersionRetentionPolicyCommand) error
+	DeleteDashboardVersionRetentionPolicy(ctx context.Context, cmd *models.DeleteDashboardVersionRetentionPolicyCommand) error
+	GetTrashedDashboards(ctx context.Context, query *models.GetTrashedDashboardsQuery) error
+	RestoreTrashedDashboard(ctx context.Context, cmd *models.RestoreTrashedDashboardCommand) error
+	DeleteTrashedDashboard(ctx context.Context, cmd *models.DeleteTrashedDashboardCommand) error
+	DeleteExpiredTrashedDashboards(ctx context.Context, cmd *models.DeleteExpiredTrashedDashboardsCommand) error
 	UpdateDashboardACL(ctx context.Context, dashboardID int64, items []*models.DashboardAcl) error
 	UpdateDashboardACLCtx(ctx context.Context, dashboardID int64, items []*models.DashboardAcl) error
 	GetDashboardAclInfoList(ctx context.Context, query *models.GetDashboardAclInfoListQuery) error
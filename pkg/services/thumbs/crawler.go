@@ -3,8 +3,6 @@ package thumbs
 import (
 	"context"
 	"encoding/json"
-	"os"
-	"strings"
 	"sync"
 	"time"
 
@@ -234,50 +232,13 @@ func (r *simpleCrawler) walk(ctx context.Context, id int) {
 		url := models.GetKioskModeDashboardUrl(item.Uid, item.Slug, r.opts.Theme)
 		r.log.Info("Getting dashboard thumbnail", "walkerId", id, "dashboardUID", item.Uid, "url", url)
 
-		res, err := r.renderService.Render(ctx, rendering.Opts{
-			Width:             320,
-			Height:            240,
-			Path:              strings.TrimPrefix(url, "/"),
-			AuthOpts:          r.opts.AuthOpts,
-			TimeoutOpts:       r.opts.TimeoutOpts,
-			ConcurrentLimit:   r.opts.ConcurrentLimit,
-			Theme:             r.opts.Theme,
-			DeviceScaleFactor: -5, // negative numbers will render larger and then scale down.
-		}, r.renderingSession)
+		thumbnailId, err := renderThumbnail(ctx, r.renderService, r.renderingSession, r.thumbnailRepo, r.log, r.opts, r.thumbnailKind, item)
 		if err != nil {
 			r.log.Warn("Error getting image", "walkerId", id, "dashboardUID", item.Uid, "url", url, "err", err)
 			r.newErrorResult()
-		} else if res.FilePath == "" {
-			r.log.Warn("Error getting image... no response", "walkerId", id, "dashboardUID", item.Uid, "url", url)
-			r.newErrorResult()
-		} else if strings.Contains(res.FilePath, "public/img") {
-			r.log.Warn("Error getting image... internal result", "walkerId", id, "dashboardUID", item.Uid, "url", url, "img", res.FilePath)
-			// rendering service returned a static error image - we should not remove that file
-			r.newErrorResult()
 		} else {
-			func() {
-				defer func() {
-					err := os.Remove(res.FilePath)
-					if err != nil {
-						r.log.Error("Failed to remove thumbnail temp file", "walkerId", id, "dashboardUID", item.Uid, "url", url, "err", err)
-					}
-				}()
-
-				thumbnailId, err := r.thumbnailRepo.saveFromFile(ctx, res.FilePath, models.DashboardThumbnailMeta{
-					DashboardUID: item.Uid,
-					OrgId:        item.OrgId,
-					Theme:        r.opts.Theme,
-					Kind:         r.thumbnailKind,
-				}, item.Version)
-
-				if err != nil {
-					r.log.Warn("Error saving image image", "walkerId", id, "dashboardUID", item.Uid, "url", url, "err", err)
-					r.newErrorResult()
-				} else {
-					r.log.Info("Saved thumbnail", "walkerId", id, "dashboardUID", item.Uid, "url", url, "thumbnailId", thumbnailId)
-					r.newSuccessResult()
-				}
-			}()
+			r.log.Info("Saved thumbnail", "walkerId", id, "dashboardUID", item.Uid, "url", url, "thumbnailId", thumbnailId)
+			r.newSuccessResult()
 		}
 		r.broadcastStatus()
 	}
@@ -44,6 +44,15 @@ func (ds *dummyService) CrawlerStatus(c *models.ReqContext) response.Response {
 	return response.JSON(200, result)
 }
 
+func (ds *dummyService) RenderJobs(c *models.ReqContext) response.Response {
+	result := make(map[string]string)
+	result["error"] = "Not enabled"
+	return response.JSON(200, result)
+}
+
+func (ds *dummyService) PrioritizeDashboard(ctx context.Context, orgID int64, dashboardUID string) {
+}
+
 func (ds *dummyService) Run(ctx context.Context) error {
 	return nil
 }
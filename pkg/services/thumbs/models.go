@@ -73,4 +73,11 @@ type thumbnailRepo interface {
 	saveFromBytes(ctx context.Context, bytes []byte, mimeType string, meta models.DashboardThumbnailMeta, dashboardVersion int) (int64, error)
 	getThumbnail(ctx context.Context, meta models.DashboardThumbnailMeta) (*models.DashboardThumbnail, error)
 	findDashboardsWithStaleThumbnails(ctx context.Context, theme models.Theme, thumbnailKind models.ThumbnailKind) ([]*models.DashboardWithStaleThumbnail, error)
+
+	enqueueRenderJobs(ctx context.Context, theme models.Theme, thumbnailKind models.ThumbnailKind, dashboards []*models.DashboardWithStaleThumbnail) (int, error)
+	prioritizeRenderJobs(ctx context.Context, orgID int64, dashboardUID string, priority int64) error
+	claimRenderJobs(ctx context.Context, renderer string, concurrency int, timeout time.Duration) ([]*models.DashboardRenderJob, error)
+	completeRenderJob(ctx context.Context, id int64) error
+	failRenderJob(ctx context.Context, id int64, err error, state models.RenderJobState, nextAttempt time.Time) error
+	findRenderJobs(ctx context.Context, state models.RenderJobState, limit int) ([]*models.DashboardRenderJob, error)
 }
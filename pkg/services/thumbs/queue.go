@@ -0,0 +1,242 @@
+package thumbs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/rendering"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+type renderQueueOptions struct {
+	// renderer identifies the renderer in claimed jobs. Instances using the same remote renderer share its concurrency,
+	// instances using the plugin have their own.
+	renderer     string
+	concurrency  int
+	maxAttempts  int
+	pollInterval time.Duration
+	// jobTimeout is the time after which running jobs are considered abandoned, e.g. if Grafana was restarted.
+	jobTimeout time.Duration
+	backoff    time.Duration
+	maxBackoff time.Duration
+}
+
+func newRenderQueueOptions(cfg *setting.Cfg) renderQueueOptions {
+	renderer := "plugin:"
+	if cfg.RendererUrl != "" {
+		renderer = "remote:" + cfg.RendererUrl
+	} else if hostname, err := os.Hostname(); err == nil {
+		renderer += hostname
+	}
+
+	return renderQueueOptions{
+		renderer:     renderer,
+		concurrency:  cfg.RendererThumbnailConcurrency,
+		maxAttempts:  cfg.RendererThumbnailMaxAttempts,
+		pollInterval: 10 * time.Second,
+		jobTimeout:   5 * time.Minute,
+		backoff:      time.Minute,
+		maxBackoff:   time.Hour,
+	}
+}
+
+type dashboardKey struct {
+	orgID int64
+	uid   string
+}
+
+// renderQueue renders the thumbnails of the jobs enqueued by scheduled crawls, recently viewed dashboards first.
+type renderQueue struct {
+	renderService rendering.Service
+	thumbnailRepo thumbnailRepo
+	options       renderQueueOptions
+	auth          rendering.AuthOpts
+	log           log.Logger
+
+	// viewed holds the dashboards viewed since the last poll with the time of their last view. Their jobs are
+	// prioritized in the database once per poll, rather than on every view.
+	viewedMu sync.Mutex
+	viewed   map[dashboardKey]int64
+}
+
+// prioritize records a view of a dashboard, its jobs are prioritized before claiming the next jobs.
+func (q *renderQueue) prioritize(orgID int64, dashboardUID string) {
+	q.viewedMu.Lock()
+	defer q.viewedMu.Unlock()
+	if q.viewed == nil {
+		q.viewed = map[dashboardKey]int64{}
+	}
+	q.viewed[dashboardKey{orgID: orgID, uid: dashboardUID}] = time.Now().Unix()
+}
+
+func (q *renderQueue) prioritizeViewed(ctx context.Context) {
+	q.viewedMu.Lock()
+	viewed := q.viewed
+	q.viewed = nil
+	q.viewedMu.Unlock()
+
+	for key, priority := range viewed {
+		if err := q.thumbnailRepo.prioritizeRenderJobs(ctx, key.orgID, key.uid, priority); err != nil {
+			q.log.Warn("Error prioritizing render jobs", "dashboardUID", key.uid, "err", err)
+		}
+	}
+}
+
+func (q *renderQueue) run(ctx context.Context) {
+	ticker := time.NewTicker(q.options.pollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			q.process(ctx)
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// process starts rendering as many jobs as the renderer can take. Jobs render in the background, and are accounted
+// for in the concurrency of the renderer until they complete or fail.
+func (q *renderQueue) process(ctx context.Context) {
+	if !q.renderService.IsAvailable() {
+		return
+	}
+
+	q.prioritizeViewed(ctx)
+
+	jobs, err := q.thumbnailRepo.claimRenderJobs(ctx, q.options.renderer, q.options.concurrency, q.options.jobTimeout)
+	if err != nil {
+		q.log.Error("Error claiming render jobs", "renderer", q.options.renderer, "err", err)
+		return
+	}
+	if len(jobs) == 0 {
+		return
+	}
+
+	session, err := q.renderService.CreateRenderingSession(ctx, q.auth, rendering.SessionOpts{
+		Expiry:                     5 * time.Minute,
+		RefreshExpiryOnEachRequest: true,
+	})
+	if err != nil {
+		q.log.Error("Error when creating rendering session", "err", err)
+		for _, job := range jobs {
+			q.fail(ctx, job, err)
+		}
+		return
+	}
+
+	for _, job := range jobs {
+		go q.render(ctx, session, job)
+	}
+}
+
+func (q *renderQueue) render(ctx context.Context, session rendering.Session, job *models.DashboardRenderJob) {
+	renderCtx, cancel := context.WithTimeout(ctx, q.options.jobTimeout)
+	defer cancel()
+
+	item := &models.DashboardWithStaleThumbnail{
+		Id:      job.DashboardId,
+		OrgId:   job.OrgId,
+		Uid:     job.DashboardUID,
+		Version: job.DashboardVersion,
+		Slug:    job.DashboardSlug,
+	}
+	thumbnailId, err := renderThumbnail(renderCtx, q.renderService, session, q.thumbnailRepo, q.log, q.renderOpts(job.Theme), job.Kind, item)
+	if err != nil {
+		q.fail(ctx, job, err)
+		return
+	}
+
+	if err := q.thumbnailRepo.completeRenderJob(ctx, job.Id); err != nil {
+		q.log.Error("Error completing render job", "jobId", job.Id, "dashboardUID", job.DashboardUID, "err", err)
+		return
+	}
+	q.log.Info("Saved thumbnail", "jobId", job.Id, "dashboardUID", job.DashboardUID, "theme", job.Theme, "thumbnailId", thumbnailId)
+}
+
+func (q *renderQueue) fail(ctx context.Context, job *models.DashboardRenderJob, err error) {
+	state, nextAttempt := q.nextAttempt(job.Attempts + 1)
+	q.log.Warn("Error rendering thumbnail", "jobId", job.Id, "dashboardUID", job.DashboardUID, "theme", job.Theme,
+		"attempts", job.Attempts+1, "state", state, "nextAttempt", nextAttempt, "err", err)
+
+	if err := q.thumbnailRepo.failRenderJob(ctx, job.Id, err, state, nextAttempt); err != nil {
+		q.log.Error("Error failing render job", "jobId", job.Id, "dashboardUID", job.DashboardUID, "err", err)
+	}
+}
+
+// nextAttempt returns when to retry a job after its failed attempts, with a delay doubling after each of them.
+func (q *renderQueue) nextAttempt(attempts int) (models.RenderJobState, time.Time) {
+	now := time.Now()
+	if attempts >= q.options.maxAttempts {
+		return models.RenderJobStateFailed, now
+	}
+
+	delay := q.options.backoff
+	for i := 1; i < attempts && delay < q.options.maxBackoff; i++ {
+		delay *= 2
+	}
+	if delay > q.options.maxBackoff {
+		delay = q.options.maxBackoff
+	}
+	return models.RenderJobStatePending, now.Add(delay)
+}
+
+func (q *renderQueue) renderOpts(theme models.Theme) rendering.Opts {
+	return rendering.Opts{
+		AuthOpts: q.auth,
+		TimeoutOpts: rendering.TimeoutOpts{
+			Timeout:                  10 * time.Second,
+			RequestTimeoutMultiplier: 3,
+		},
+		Theme:           theme,
+		ConcurrentLimit: q.options.concurrency,
+	}
+}
+
+// renderThumbnail renders the thumbnail of a dashboard and saves it.
+func renderThumbnail(ctx context.Context, renderService rendering.Service, session rendering.Session, repo thumbnailRepo, logger log.Logger,
+	opts rendering.Opts, kind models.ThumbnailKind, item *models.DashboardWithStaleThumbnail) (int64, error) {
+	url := models.GetKioskModeDashboardUrl(item.Uid, item.Slug, opts.Theme)
+
+	res, err := renderService.Render(ctx, rendering.Opts{
+		Width:             320,
+		Height:            240,
+		Path:              strings.TrimPrefix(url, "/"),
+		AuthOpts:          opts.AuthOpts,
+		TimeoutOpts:       opts.TimeoutOpts,
+		ConcurrentLimit:   opts.ConcurrentLimit,
+		Theme:             opts.Theme,
+		DeviceScaleFactor: -5, // negative numbers will render larger and then scale down.
+	}, session)
+	if err != nil {
+		return 0, err
+	}
+	if res.FilePath == "" {
+		return 0, errors.New("no image returned by the renderer")
+	}
+	if strings.Contains(res.FilePath, "public/img") {
+		// rendering service returned a static error image - we should not remove that file
+		return 0, fmt.Errorf("renderer returned an error image %s", res.FilePath)
+	}
+
+	defer func() {
+		if err := os.Remove(res.FilePath); err != nil {
+			logger.Error("Failed to remove thumbnail temp file", "dashboardUID", item.Uid, "url", url, "err", err)
+		}
+	}()
+
+	return repo.saveFromFile(ctx, res.FilePath, models.DashboardThumbnailMeta{
+		DashboardUID: item.Uid,
+		OrgId:        item.OrgId,
+		Theme:        opts.Theme,
+		Kind:         kind,
+	}, item.Version)
+}
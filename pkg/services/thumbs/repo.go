@@ -6,6 +6,7 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"time"
 
 	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/models"
@@ -91,3 +92,47 @@ func (r *sqlThumbnailRepository) findDashboardsWithStaleThumbnails(ctx context.C
 		Kind:                              kind,
 	})
 }
+
+func (r *sqlThumbnailRepository) enqueueRenderJobs(ctx context.Context, theme models.Theme, kind models.ThumbnailKind, dashboards []*models.DashboardWithStaleThumbnail) (int, error) {
+	return r.store.EnqueueDashboardRenderJobs(ctx, &models.EnqueueDashboardRenderJobsCommand{
+		Kind:       kind,
+		Theme:      theme,
+		Dashboards: dashboards,
+	})
+}
+
+func (r *sqlThumbnailRepository) prioritizeRenderJobs(ctx context.Context, orgID int64, dashboardUID string, priority int64) error {
+	return r.store.PrioritizeDashboardRenderJobs(ctx, &models.PrioritizeDashboardRenderJobsCommand{
+		OrgId:        orgID,
+		DashboardUID: dashboardUID,
+		Priority:     priority,
+	})
+}
+
+func (r *sqlThumbnailRepository) claimRenderJobs(ctx context.Context, renderer string, concurrency int, timeout time.Duration) ([]*models.DashboardRenderJob, error) {
+	return r.store.ClaimDashboardRenderJobs(ctx, &models.ClaimDashboardRenderJobsCommand{
+		Renderer:    renderer,
+		Concurrency: concurrency,
+		Timeout:     timeout,
+	})
+}
+
+func (r *sqlThumbnailRepository) completeRenderJob(ctx context.Context, id int64) error {
+	return r.store.CompleteDashboardRenderJob(ctx, id)
+}
+
+func (r *sqlThumbnailRepository) failRenderJob(ctx context.Context, id int64, err error, state models.RenderJobState, nextAttempt time.Time) error {
+	return r.store.FailDashboardRenderJob(ctx, &models.FailDashboardRenderJobCommand{
+		Id:          id,
+		Error:       err.Error(),
+		State:       state,
+		NextAttempt: nextAttempt,
+	})
+}
+
+func (r *sqlThumbnailRepository) findRenderJobs(ctx context.Context, state models.RenderJobState, limit int) ([]*models.DashboardRenderJob, error) {
+	return r.store.FindDashboardRenderJobs(ctx, &models.FindDashboardRenderJobsQuery{
+		State: state,
+		Limit: limit,
+	})
+}
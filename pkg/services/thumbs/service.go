@@ -32,15 +32,21 @@ type Service interface {
 	SetImage(c *models.ReqContext) // form post
 	UpdateThumbnailState(c *models.ReqContext)
 
+	// PrioritizeDashboard renders the thumbnail of a viewed dashboard before those of other dashboards. It doesn't
+	// query the database, views are applied to the render queue in the background.
+	PrioritizeDashboard(ctx context.Context, orgID int64, dashboardUID string)
+
 	// Must be admin
 	StartCrawler(c *models.ReqContext) response.Response
 	StopCrawler(c *models.ReqContext) response.Response
 	CrawlerStatus(c *models.ReqContext) response.Response
+	RenderJobs(c *models.ReqContext) response.Response
 }
 
 type thumbService struct {
 	scheduleOptions            crawlerScheduleOptions
 	renderer                   dashRenderer
+	renderQueue                *renderQueue
 	thumbnailRepo              thumbnailRepo
 	lockService                *serverlock.ServerLockService
 	features                   featuremgmt.FeatureToggles
@@ -52,7 +58,6 @@ type crawlerScheduleOptions struct {
 	crawlInterval    time.Duration
 	tickerInterval   time.Duration
 	maxCrawlDuration time.Duration
-	crawlerMode      CrawlerMode
 	thumbnailKind    models.ThumbnailKind
 	auth             rendering.AuthOpts
 	themes           []models.Theme
@@ -71,7 +76,14 @@ func ProvideService(cfg *setting.Cfg, features featuremgmt.FeatureToggles, lockS
 		OrgRole: models.ROLE_ADMIN,
 	}
 	return &thumbService{
-		renderer:                   newSimpleCrawler(renderService, gl, thumbnailRepo),
+		renderer: newSimpleCrawler(renderService, gl, thumbnailRepo),
+		renderQueue: &renderQueue{
+			renderService: renderService,
+			thumbnailRepo: thumbnailRepo,
+			options:       newRenderQueueOptions(cfg),
+			auth:          authOpts,
+			log:           log.New("thumbnails_queue"),
+		},
 		thumbnailRepo:              thumbnailRepo,
 		features:                   features,
 		lockService:                lockService,
@@ -82,7 +94,6 @@ func ProvideService(cfg *setting.Cfg, features featuremgmt.FeatureToggles, lockS
 			tickerInterval:   time.Hour,
 			crawlInterval:    time.Hour * 12,
 			maxCrawlDuration: time.Hour,
-			crawlerMode:      CrawlerModeThumbs,
 			thumbnailKind:    models.ThumbnailKindDefault,
 			themes:           []models.Theme{models.ThemeDark, models.ThemeLight},
 			auth:             authOpts,
@@ -296,6 +307,33 @@ func (hs *thumbService) CrawlerStatus(c *models.ReqContext) response.Response {
 	return response.JSON(200, msg)
 }
 
+// PrioritizeDashboard doesn't block viewing the dashboard, the render queue prioritizes the jobs of viewed dashboards
+// when it polls for jobs.
+func (hs *thumbService) PrioritizeDashboard(ctx context.Context, orgID int64, dashboardUID string) {
+	if !hs.features.IsEnabled(featuremgmt.FlagDashboardPreviewsScheduler) {
+		return
+	}
+	hs.renderQueue.prioritize(orgID, dashboardUID)
+}
+
+// RenderJobs lists the render jobs in a state, e.g. failed jobs with their errors.
+func (hs *thumbService) RenderJobs(c *models.ReqContext) response.Response {
+	state := models.RenderJobState(c.Query("state"))
+	if state != "" && !state.IsValid() {
+		return response.Error(400, "invalid state", nil)
+	}
+	limit := c.QueryInt("limit")
+	if limit <= 0 {
+		limit = 100
+	}
+
+	jobs, err := hs.thumbnailRepo.findRenderJobs(c.Req.Context(), state, limit)
+	if err != nil {
+		return response.Error(500, "error finding render jobs", err)
+	}
+	return response.JSON(200, jobs)
+}
+
 // Ideally this service would not require first looking up the full dashboard just to bet the id!
 func (hs *thumbService) getStatus(c *models.ReqContext, uid string, checkSave bool) int {
 	dashboardID, err := hs.getDashboardId(c, uid)
@@ -345,15 +383,25 @@ func (hs *thumbService) runOnDemandCrawl(parentCtx context.Context, theme models
 	}
 }
 
+// runScheduledCrawl enqueues render jobs for the dashboards with stale thumbnails, they are rendered by the render queue.
 func (hs *thumbService) runScheduledCrawl(parentCtx context.Context) {
 	crawlerCtx, cancel := context.WithTimeout(parentCtx, hs.scheduleOptions.maxCrawlDuration)
 	defer cancel()
 
 	err := hs.lockService.LockAndExecute(crawlerCtx, hs.crawlLockServiceActionName, hs.scheduleOptions.crawlInterval, func(ctx context.Context) {
 		for _, theme := range hs.scheduleOptions.themes {
-			if err := hs.renderer.Run(crawlerCtx, hs.scheduleOptions.auth, hs.scheduleOptions.crawlerMode, theme, hs.scheduleOptions.thumbnailKind); err != nil {
-				hs.log.Error("Scheduled crawl error", "theme", theme, "kind", hs.scheduleOptions.thumbnailKind, "err", err)
+			kind := hs.scheduleOptions.thumbnailKind
+			dashboards, err := hs.thumbnailRepo.findDashboardsWithStaleThumbnails(crawlerCtx, theme, kind)
+			if err != nil {
+				hs.log.Error("Scheduled crawl error", "theme", theme, "kind", kind, "err", err)
+				continue
 			}
+			enqueued, err := hs.thumbnailRepo.enqueueRenderJobs(crawlerCtx, theme, kind, dashboards)
+			if err != nil {
+				hs.log.Error("Scheduled crawl error", "theme", theme, "kind", kind, "err", err)
+				continue
+			}
+			hs.log.Info("Enqueued render jobs", "theme", theme, "kind", kind, "staleDashboards", len(dashboards), "enqueued", enqueued)
 		}
 	})
 
@@ -367,6 +415,8 @@ func (hs *thumbService) Run(ctx context.Context) error {
 		return nil
 	}
 
+	go hs.renderQueue.run(ctx)
+
 	gc := time.NewTicker(hs.scheduleOptions.tickerInterval)
 
 	for {
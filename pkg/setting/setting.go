@@ -237,6 +237,9 @@ type Cfg struct {
 	RendererUrl                    string
 	RendererCallbackUrl            string
 	RendererConcurrentRequestLimit int
+	// Dashboard thumbnails rendered at the same time by a renderer, and attempts to render each of them
+	RendererThumbnailConcurrency int
+	RendererThumbnailMaxAttempts int
 
 	// Security
 	DisableInitAdminCreation          bool
@@ -277,6 +280,9 @@ type Cfg struct {
 
 	// Dashboards
 	DefaultHomeDashboardPath string
+	// DashboardTrashRetention is how long deleted dashboards and folders are
+	// kept in the recycle bin, they are deleted permanently when it is 0.
+	DashboardTrashRetention time.Duration
 
 	// Auth
 	LoginCookieName              string
@@ -335,6 +341,7 @@ type Cfg struct {
 	DataProxyIdleConnTimeout       int
 	ResponseLimit                  int64
 	DataProxyRowLimit              int64
+	DataProxyRowBytesLimit         int64
 
 	// DistributedCache
 	RemoteCacheOptions *RemoteCacheOptions
@@ -359,6 +366,8 @@ type Cfg struct {
 	HiddenUsers           map[string]struct{}
 
 	// Annotations
+	AnnotationStore                    string
+	AnnotationLoki                     AnnotationLokiSettings
 	AnnotationCleanupJobBatchSize      int64
 	AlertingAnnotationCleanupSetting   AnnotationCleanupSettings
 	DashboardAnnotationCleanupSettings AnnotationCleanupSettings
@@ -414,6 +423,26 @@ type Cfg struct {
 	// LiveAllowedOrigins is a set of origins accepted by Live. If not provided
 	// then Live uses AppURL as the only allowed origin.
 	LiveAllowedOrigins []string
+	// LiveHistoryEngine is where Live keeps channel message history: memory,
+	// redis or database.
+	LiveHistoryEngine string
+	// LiveHistorySize is the default number of messages kept per channel.
+	// 0 disables history.
+	LiveHistorySize int
+	// LiveHistoryTTL is the default maximum age of kept messages. 0 keeps
+	// messages until LiveHistorySize is reached.
+	LiveHistoryTTL time.Duration
+	// LiveHistoryChannels overrides the history size and TTL for channels
+	// matching a pattern, configured in the [live.history] section.
+	LiveHistoryChannels []LiveHistoryChannel
+	// LivePipelineStorage is where Live pipeline channel rules and write
+	// configs are kept: file or database.
+	LivePipelineStorage string
+	// LiveInputs are the message brokers Live subscribes to.
+	LiveInputs []LiveInput
+	// LiveLimits limit the rate of messages pushed into managed streams, per
+	// Grafana server instance.
+	LiveLimits []LiveLimit
 
 	// Grafana.com URL
 	GrafanaComURL string
@@ -575,6 +604,19 @@ func (cfg *Cfg) readGrafanaEnvironmentMetrics() error {
 func (cfg *Cfg) readAnnotationSettings() {
 	section := cfg.Raw.Section("annotations")
 	cfg.AnnotationCleanupJobBatchSize = section.Key("cleanupjob_batchsize").MustInt64(100)
+	cfg.AnnotationStore = section.Key("store").In(AnnotationStoreSQL, []string{AnnotationStoreSQL, AnnotationStoreComposite, annotationStoreLoki})
+	if cfg.AnnotationStore == annotationStoreLoki {
+		// Annotations in Loki can't be updated or deleted, so annotations of users
+		// are kept in the database.
+		cfg.Logger.Warn("Storing all annotations in Loki is not supported, using the composite annotation store")
+		cfg.AnnotationStore = AnnotationStoreComposite
+	}
+
+	lokiSection := cfg.Raw.Section("annotations.loki")
+	cfg.AnnotationLoki = AnnotationLokiSettings{
+		DataSourceUID:   lokiSection.Key("datasource_uid").MustString(""),
+		DataSourceOrgID: lokiSection.Key("datasource_org_id").MustInt64(1),
+	}
 
 	dashboardAnnotation := cfg.Raw.Section("annotations.dashboard")
 	apiIAnnotation := cfg.Raw.Section("annotations.api")
@@ -607,6 +649,23 @@ type AnnotationCleanupSettings struct {
 	MaxCount int64
 }
 
+const (
+	AnnotationStoreSQL = "sql"
+	// AnnotationStoreComposite stores alert state annotations in Loki and
+	// other annotations in the database, and reads from both.
+	AnnotationStoreComposite = "composite"
+	// annotationStoreLoki is accepted as an alias of the composite store, a
+	// standalone Loki store isn't supported.
+	annotationStoreLoki = "loki"
+)
+
+// AnnotationLokiSettings is the Loki datasource storing alert state
+// annotations of the composite store.
+type AnnotationLokiSettings struct {
+	DataSourceUID   string
+	DataSourceOrgID int64
+}
+
 func EnvKey(sectionName string, keyName string) string {
 	sN := strings.ToUpper(strings.ReplaceAll(sectionName, ".", "_"))
 	sN = strings.ReplaceAll(sN, "-", "_")
@@ -906,6 +965,11 @@ func (cfg *Cfg) Load(args CommandLineArgs) error {
 	MinRefreshInterval = valueAsString(dashboards, "min_refresh_interval", "5s")
 
 	cfg.DefaultHomeDashboardPath = dashboards.Key("default_home_dashboard_path").MustString("")
+	trashRetention, err := gtime.ParseDuration(valueAsString(dashboards, "trash_retention", "0"))
+	if err != nil {
+		return err
+	}
+	cfg.DashboardTrashRetention = trashRetention
 
 	if err := readUserSettings(iniFile, cfg); err != nil {
 		return err
@@ -1388,6 +1452,8 @@ func (cfg *Cfg) readRenderingSettings(iniFile *ini.File) error {
 	}
 
 	cfg.RendererConcurrentRequestLimit = renderSec.Key("concurrent_render_request_limit").MustInt(30)
+	cfg.RendererThumbnailConcurrency = renderSec.Key("dashboard_thumbnail_concurrency").MustInt(4)
+	cfg.RendererThumbnailMaxAttempts = renderSec.Key("dashboard_thumbnail_max_attempts").MustInt(5)
 	cfg.ImagesDir = filepath.Join(cfg.DataPath, "png")
 	cfg.CSVsDir = filepath.Join(cfg.DataPath, "csv")
 
@@ -1548,5 +1614,84 @@ func (cfg *Cfg) readLiveSettings(iniFile *ini.File) error {
 		return err
 	}
 	cfg.LiveAllowedOrigins = originPatterns
+
+	cfg.LiveHistoryEngine = section.Key("history_engine").MustString("memory")
+	switch cfg.LiveHistoryEngine {
+	case "memory", "redis", "database":
+	default:
+		return fmt.Errorf("unsupported live history engine type: %s", cfg.LiveHistoryEngine)
+	}
+	cfg.LiveHistorySize = section.Key("history_size").MustInt(0)
+	if cfg.LiveHistorySize < 0 {
+		return fmt.Errorf("unexpected value %d for [live] history_size", cfg.LiveHistorySize)
+	}
+	cfg.LiveHistoryTTL = section.Key("history_ttl").MustDuration(0)
+
+	cfg.LivePipelineStorage = section.Key("pipeline_storage").MustString("file")
+	switch cfg.LivePipelineStorage {
+	case "file", "database":
+	default:
+		return fmt.Errorf("unsupported live pipeline storage type: %s", cfg.LivePipelineStorage)
+	}
+
+	cfg.LiveHistoryChannels = nil
+	for _, key := range iniFile.Section("live.history").Keys() {
+		channel, err := parseLiveHistoryChannel(key.Name(), key.Value())
+		if err != nil {
+			return err
+		}
+		cfg.LiveHistoryChannels = append(cfg.LiveHistoryChannels, channel)
+	}
+
+	inputs, err := readLiveInputSettings(iniFile, InstanceName)
+	if err != nil {
+		return err
+	}
+	cfg.LiveInputs = inputs
+
+	limits, err := readLiveLimitSettings(iniFile)
+	if err != nil {
+		return err
+	}
+	cfg.LiveLimits = limits
 	return nil
 }
+
+// LiveHistoryChannel is the history configuration of the Live channels matching
+// Pattern.
+type LiveHistoryChannel struct {
+	// Pattern is matched against channels without the org prefix, where "*"
+	// matches a single channel path segment.
+	Pattern string
+	Size    int
+	TTL     time.Duration
+}
+
+// parseLiveHistoryChannel parses a [live.history] entry, which has a channel
+// pattern as key and a "<size>" or "<size>, <ttl>" value.
+func parseLiveHistoryChannel(pattern string, value string) (LiveHistoryChannel, error) {
+	channel := LiveHistoryChannel{Pattern: pattern}
+	if _, err := path.Match(pattern, ""); err != nil {
+		return channel, fmt.Errorf("invalid [live.history] channel pattern %q: %w", pattern, err)
+	}
+
+	parts := strings.Split(value, ",")
+	if len(parts) > 2 {
+		return channel, fmt.Errorf("invalid [live.history] value %q for %s, expected \"<size>[, <ttl>]\"", value, pattern)
+	}
+
+	size, err := strconv.Atoi(strings.TrimSpace(parts[0]))
+	if err != nil || size < 0 {
+		return channel, fmt.Errorf("invalid [live.history] size %q for %s", parts[0], pattern)
+	}
+	channel.Size = size
+
+	if len(parts) == 2 {
+		ttl, err := gtime.ParseDuration(strings.TrimSpace(parts[1]))
+		if err != nil || ttl < 0 {
+			return channel, fmt.Errorf("invalid [live.history] ttl %q for %s", parts[1], pattern)
+		}
+		channel.TTL = ttl
+	}
+	return channel, nil
+}
@@ -0,0 +1,107 @@
+package setting
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"gopkg.in/ini.v1"
+)
+
+const liveInputSectionPrefix = "live.input."
+
+// LiveInput is a message broker Live subscribes to, configured in a
+// [live.input.<name>] section. Messages are processed by the Live pipeline.
+type LiveInput struct {
+	Name string
+	// Type is the broker type: mqtt or nats.
+	Type string
+	URL  string
+	// Topics are MQTT topic filters or NATS subjects, wildcards are allowed.
+	Topics []string
+	// Channel is the Live channel messages are published into, where ${topic} is
+	// replaced with the message topic converted to a channel path.
+	Channel  string
+	OrgID    int64
+	Username string
+	Password string
+	// ClientID is the MQTT client identifier or NATS connection name. The
+	// instance name is appended, so it's unique per Grafana instance.
+	ClientID string
+	// Group is the MQTT shared subscription group or NATS queue group the
+	// instances of a Grafana HA setup share, so each message is only processed
+	// by one of them. Every instance receives all messages if it's empty.
+	Group string
+	// QoS is the MQTT subscription quality of service.
+	QoS byte
+	// ReconnectMinBackoff and ReconnectMaxBackoff bound the exponential backoff
+	// between connection attempts.
+	ReconnectMinBackoff time.Duration
+	ReconnectMaxBackoff time.Duration
+}
+
+func readLiveInputSettings(iniFile *ini.File, instanceName string) ([]LiveInput, error) {
+	var inputs []LiveInput
+	for _, section := range iniFile.Sections() {
+		if !strings.HasPrefix(section.Name(), liveInputSectionPrefix) {
+			continue
+		}
+		input, err := readLiveInput(section, instanceName)
+		if err != nil {
+			return nil, err
+		}
+		inputs = append(inputs, input)
+	}
+	return inputs, nil
+}
+
+func readLiveInput(section *ini.Section, instanceName string) (LiveInput, error) {
+	name := strings.TrimPrefix(section.Name(), liveInputSectionPrefix)
+	clientID := section.Key("client_id").MustString("grafana-live-" + name)
+	input := LiveInput{
+		Name:                name,
+		Type:                section.Key("type").MustString(""),
+		URL:                 section.Key("url").MustString(""),
+		Channel:             section.Key("channel").MustString("stream/" + name + "/${topic}"),
+		OrgID:               section.Key("org_id").MustInt64(1),
+		Username:            section.Key("username").MustString(""),
+		Password:            section.Key("password").MustString(""),
+		ClientID:            clientID + "-" + instanceName,
+		ReconnectMinBackoff: section.Key("reconnect_min_backoff").MustDuration(time.Second),
+		ReconnectMaxBackoff: section.Key("reconnect_max_backoff").MustDuration(time.Minute),
+	}
+
+	switch input.Type {
+	case "mqtt", "nats":
+	default:
+		return input, fmt.Errorf("unsupported [%s] type %q, expected mqtt or nats", section.Name(), input.Type)
+	}
+	if input.URL == "" {
+		return input, fmt.Errorf("[%s] url is required", section.Name())
+	}
+
+	for _, topic := range strings.Split(section.Key("topics").MustString(""), ",") {
+		topic = strings.TrimSpace(topic)
+		if topic != "" {
+			input.Topics = append(input.Topics, topic)
+		}
+	}
+	if len(input.Topics) == 0 {
+		return input, fmt.Errorf("[%s] topics are required", section.Name())
+	}
+
+	if section.Key("shared").MustBool(true) {
+		input.Group = clientID
+	}
+
+	qos := section.Key("qos").MustInt(0)
+	if qos < 0 || qos > 2 {
+		return input, fmt.Errorf("unexpected value %d for [%s] qos", qos, section.Name())
+	}
+	input.QoS = byte(qos)
+
+	if input.ReconnectMinBackoff <= 0 || input.ReconnectMaxBackoff < input.ReconnectMinBackoff {
+		return input, fmt.Errorf("[%s] reconnect_max_backoff must be greater than reconnect_min_backoff", section.Name())
+	}
+	return input, nil
+}
@@ -0,0 +1,78 @@
+package setting
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"gopkg.in/ini.v1"
+)
+
+func TestReadLiveInputSettings(t *testing.T) {
+	f, err := ini.Load([]byte(`
+[live.input.sensors]
+type = mqtt
+url = tcp://localhost:1883
+topics = """sensors/#, devices/+/status"""
+qos = 1
+username = grafana
+password = secret
+
+[live.input.events]
+type = nats
+url = nats://localhost:4222
+topics = events.>
+channel = stream/events/${topic}
+org_id = 2
+client_id = grafana-events
+shared = false
+reconnect_min_backoff = 5s
+reconnect_max_backoff = 5m
+`))
+	require.NoError(t, err)
+
+	inputs, err := readLiveInputSettings(f, "grafana-1")
+	require.NoError(t, err)
+	require.Equal(t, []LiveInput{
+		{
+			Name:                "sensors",
+			Type:                "mqtt",
+			URL:                 "tcp://localhost:1883",
+			Topics:              []string{"sensors/#", "devices/+/status"},
+			Channel:             "stream/sensors/${topic}",
+			OrgID:               1,
+			Username:            "grafana",
+			Password:            "secret",
+			ClientID:            "grafana-live-sensors-grafana-1",
+			Group:               "grafana-live-sensors",
+			QoS:                 1,
+			ReconnectMinBackoff: time.Second,
+			ReconnectMaxBackoff: time.Minute,
+		},
+		{
+			Name:                "events",
+			Type:                "nats",
+			URL:                 "nats://localhost:4222",
+			Topics:              []string{"events.>"},
+			Channel:             "stream/events/${topic}",
+			OrgID:               2,
+			ClientID:            "grafana-events-grafana-1",
+			ReconnectMinBackoff: 5 * time.Second,
+			ReconnectMaxBackoff: 5 * time.Minute,
+		},
+	}, inputs)
+
+	for _, invalid := range []string{
+		"[live.input.a]\nurl = tcp://localhost:1883\ntopics = a",
+		"[live.input.a]\ntype = kafka\nurl = localhost:9092\ntopics = a",
+		"[live.input.a]\ntype = mqtt\ntopics = a",
+		"[live.input.a]\ntype = mqtt\nurl = tcp://localhost:1883",
+		"[live.input.a]\ntype = mqtt\nurl = tcp://localhost:1883\ntopics = a\nqos = 3",
+		"[live.input.a]\ntype = mqtt\nurl = tcp://localhost:1883\ntopics = a\nreconnect_min_backoff = 1m\nreconnect_max_backoff = 1s",
+	} {
+		f, err := ini.Load([]byte(invalid))
+		require.NoError(t, err)
+		_, err = readLiveInputSettings(f, "grafana-1")
+		require.Error(t, err, invalid)
+	}
+}
@@ -0,0 +1,99 @@
+package setting
+
+import (
+	"fmt"
+	"path"
+	"strconv"
+	"strings"
+
+	"gopkg.in/ini.v1"
+)
+
+// Levels of Live rate limits.
+const (
+	LiveLimitLevelOrg       = "org"
+	LiveLimitLevelNamespace = "namespace"
+	LiveLimitLevelChannel   = "channel"
+)
+
+// Policies applied to messages exceeding Live rate limits.
+const (
+	// LiveLimitPolicyReject returns an error to the publisher.
+	LiveLimitPolicyReject = "reject"
+	// LiveLimitPolicyDrop drops messages silently.
+	LiveLimitPolicyDrop = "drop"
+)
+
+// LiveLimit limits the rate of messages pushed into Live managed streams,
+// configured in the [live.limits] section.
+type LiveLimit struct {
+	// Pattern is "org" for all channels of an organization, a
+	// "<scope>/<namespace>" pattern for all channels of a namespace or a
+	// "<scope>/<namespace>/<path>" pattern for every matching channel, where
+	// "*" matches a single channel path segment.
+	Pattern string
+	Level   string
+	// MessagesPerSecond and BytesPerSecond are limits per second, 0 is
+	// unlimited.
+	MessagesPerSecond int
+	BytesPerSecond    int
+	Policy            string
+}
+
+func readLiveLimitSettings(iniFile *ini.File) ([]LiveLimit, error) {
+	var limits []LiveLimit
+	for _, key := range iniFile.Section("live.limits").Keys() {
+		limit, err := parseLiveLimit(key.Name(), key.Value())
+		if err != nil {
+			return nil, err
+		}
+		limits = append(limits, limit)
+	}
+	return limits, nil
+}
+
+// parseLiveLimit parses a [live.limits] entry, which has "org" or a channel
+// pattern as key and a "<messages per second>[, <bytes per second>[, <policy>]]"
+// value.
+func parseLiveLimit(pattern string, value string) (LiveLimit, error) {
+	limit := LiveLimit{Pattern: pattern, Policy: LiveLimitPolicyReject}
+	switch segments := len(strings.Split(pattern, "/")); {
+	case pattern == LiveLimitLevelOrg:
+		limit.Level = LiveLimitLevelOrg
+	case segments == 2:
+		limit.Level = LiveLimitLevelNamespace
+	case segments > 2:
+		limit.Level = LiveLimitLevelChannel
+	default:
+		return limit, fmt.Errorf("invalid [live.limits] key %q, expected org or a channel pattern", pattern)
+	}
+	if _, err := path.Match(pattern, ""); err != nil {
+		return limit, fmt.Errorf("invalid [live.limits] channel pattern %q: %w", pattern, err)
+	}
+
+	parts := strings.Split(value, ",")
+	if len(parts) > 3 {
+		return limit, fmt.Errorf("invalid [live.limits] value %q for %s, expected \"<messages>[, <bytes>[, <policy>]]\"", value, pattern)
+	}
+	messages, err := strconv.Atoi(strings.TrimSpace(parts[0]))
+	if err != nil || messages < 0 {
+		return limit, fmt.Errorf("invalid [live.limits] messages per second %q for %s", parts[0], pattern)
+	}
+	limit.MessagesPerSecond = messages
+	if len(parts) > 1 {
+		bytes, err := strconv.Atoi(strings.TrimSpace(parts[1]))
+		if err != nil || bytes < 0 {
+			return limit, fmt.Errorf("invalid [live.limits] bytes per second %q for %s", parts[1], pattern)
+		}
+		limit.BytesPerSecond = bytes
+	}
+	if len(parts) > 2 {
+		limit.Policy = strings.TrimSpace(parts[2])
+		switch limit.Policy {
+		case LiveLimitPolicyReject, LiveLimitPolicyDrop:
+		default:
+			return limit, fmt.Errorf("invalid [live.limits] policy %q for %s, expected reject or drop", limit.Policy, pattern)
+		}
+	}
+	return limit, nil
+}
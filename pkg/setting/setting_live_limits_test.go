@@ -0,0 +1,41 @@
+package setting
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"gopkg.in/ini.v1"
+)
+
+func TestReadLiveLimitSettings(t *testing.T) {
+	f, err := ini.Load([]byte(`
+[live.limits]
+org = 1000, 10000000
+stream/* = 100
+stream/telegraf/* = 10, 100000, drop
+`))
+	require.NoError(t, err)
+
+	limits, err := readLiveLimitSettings(f)
+	require.NoError(t, err)
+	require.Equal(t, []LiveLimit{
+		{Pattern: "org", Level: LiveLimitLevelOrg, MessagesPerSecond: 1000, BytesPerSecond: 10000000, Policy: LiveLimitPolicyReject},
+		{Pattern: "stream/*", Level: LiveLimitLevelNamespace, MessagesPerSecond: 100, Policy: LiveLimitPolicyReject},
+		{Pattern: "stream/telegraf/*", Level: LiveLimitLevelChannel, MessagesPerSecond: 10, BytesPerSecond: 100000, Policy: LiveLimitPolicyDrop},
+	}, limits)
+
+	for _, invalid := range []string{
+		"stream = 10",
+		"stream/* = many",
+		"stream/* = -1",
+		"stream/* = 10, 1KB",
+		"stream/* = 10, 100, block",
+		"stream/* = 10, 100, drop, 1",
+		"stream/[ = 10",
+	} {
+		f, err := ini.Load([]byte("[live.limits]\n" + invalid))
+		require.NoError(t, err)
+		_, err = readLiveLimitSettings(f)
+		require.Error(t, err, invalid)
+	}
+}
@@ -680,3 +680,61 @@ func TestAlertingEnabled(t *testing.T) {
 		})
 	}
 }
+
+func TestReadLiveHistorySettings(t *testing.T) {
+	f, err := ini.Load([]byte(`
+[live]
+history_engine = database
+history_size = 10
+history_ttl = 10m
+pipeline_storage = database
+
+[live.history]
+grafana/broadcast/* = 100, 1h
+stream/telegraf/cpu = 1000
+`))
+	require.NoError(t, err)
+
+	cfg := NewCfg()
+	require.NoError(t, cfg.readLiveSettings(f))
+	require.Equal(t, "database", cfg.LiveHistoryEngine)
+	require.Equal(t, 10, cfg.LiveHistorySize)
+	require.Equal(t, 10*time.Minute, cfg.LiveHistoryTTL)
+	require.Equal(t, "database", cfg.LivePipelineStorage)
+	require.Equal(t, []LiveHistoryChannel{
+		{Pattern: "grafana/broadcast/*", Size: 100, TTL: time.Hour},
+		{Pattern: "stream/telegraf/cpu", Size: 1000},
+	}, cfg.LiveHistoryChannels)
+
+	for _, invalid := range []string{
+		"[live]\nhistory_engine = file",
+		"[live]\nhistory_size = -1",
+		"[live]\npipeline_storage = redis",
+		"[live.history]\nstream/* = many",
+		"[live.history]\nstream/* = 10, 1h, 2h",
+		"[live.history]\nstream/[ = 10",
+	} {
+		f, err := ini.Load([]byte(invalid))
+		require.NoError(t, err)
+		require.Error(t, NewCfg().readLiveSettings(f), invalid)
+	}
+}
+
+func TestReadAnnotationStoreSettings(t *testing.T) {
+	for store, expected := range map[string]string{
+		"":          AnnotationStoreSQL,
+		"sql":       AnnotationStoreSQL,
+		"composite": AnnotationStoreComposite,
+		"loki":      AnnotationStoreComposite,
+		"unknown":   AnnotationStoreSQL,
+	} {
+		f, err := ini.Load([]byte("[annotations]\nstore = " + store + "\n\n[annotations.loki]\ndatasource_uid = loki"))
+		require.NoError(t, err)
+
+		cfg := NewCfg()
+		cfg.Raw = f
+		cfg.readAnnotationSettings()
+		require.Equal(t, expected, cfg.AnnotationStore, store)
+		require.Equal(t, AnnotationLokiSettings{DataSourceUID: "loki", DataSourceOrgID: 1}, cfg.AnnotationLoki)
+	}
+}
@@ -64,7 +64,27 @@ func (r *SearchRequest) MarshalJSON() ([]byte, error) {
 
 // SearchResponseHits represents search response hits
 type SearchResponseHits struct {
-	Hits []map[string]interface{}
+	Hits  []map[string]interface{}
+	Total *SearchResponseHitsTotal `json:"total"`
+}
+
+// SearchResponseHitsTotal represents the total number of hits of a search response
+type SearchResponseHitsTotal struct {
+	Value    int64  `json:"value"`
+	Relation string `json:"relation"`
+}
+
+// UnmarshalJSON decodes the total hits, which are a plain number before Elasticsearch 7.
+func (t *SearchResponseHitsTotal) UnmarshalJSON(b []byte) error {
+	var value int64
+	if err := json.Unmarshal(b, &value); err == nil {
+		t.Value = value
+		t.Relation = "eq"
+		return nil
+	}
+
+	type total SearchResponseHitsTotal
+	return json.Unmarshal(b, (*total)(t))
 }
 
 // SearchResponse represents a search response
@@ -74,6 +94,66 @@ type SearchResponse struct {
 	Hits         *SearchResponseHits    `json:"hits"`
 }
 
+// SQL query languages
+const (
+	SQLLanguageSQL = "sql"
+	SQLLanguagePPL = "ppl"
+)
+
+// SQLRequest represents an Elasticsearch SQL or OpenSearch PPL request
+type SQLRequest struct {
+	Language  string                 `json:"-"`
+	Query     string                 `json:"query"`
+	FetchSize int                    `json:"fetch_size,omitempty"`
+	Filter    map[string]interface{} `json:"filter,omitempty"`
+}
+
+// SQLColumn represents a column of a SQL response
+type SQLColumn struct {
+	Name string `json:"name"`
+	Type string `json:"type"`
+}
+
+// SQLResponse represents an Elasticsearch SQL or OpenSearch PPL response. Elasticsearch
+// returns the columns and rows, OpenSearch the schema and datarows. The error is an object,
+// or a string in some OpenSearch versions, see ErrorReason.
+type SQLResponse struct {
+	Status   int             `json:"-"`
+	Error    json.RawMessage `json:"error"`
+	Columns  []SQLColumn     `json:"columns"`
+	Rows     [][]interface{} `json:"rows"`
+	Schema   []SQLColumn     `json:"schema"`
+	DataRows [][]interface{} `json:"datarows"`
+}
+
+// ErrorReason returns the reason of the error of the response, or an empty string if it
+// has no error.
+func (r *SQLResponse) ErrorReason() string {
+	if len(r.Error) == 0 || string(r.Error) == "null" {
+		return ""
+	}
+
+	var reason string
+	if err := json.Unmarshal(r.Error, &reason); err == nil {
+		return reason
+	}
+
+	errObj, err := simplejson.NewJson(r.Error)
+	if err != nil {
+		return string(r.Error)
+	}
+	for _, reason := range []string{
+		errObj.Get("root_cause").GetIndex(0).Get("reason").MustString(),
+		errObj.Get("reason").MustString(),
+		errObj.Get("details").MustString(),
+	} {
+		if reason != "" {
+			return reason
+		}
+	}
+	return "unknown error"
+}
+
 // MultiSearchRequest represents a multi search request
 type MultiSearchRequest struct {
 	Requests []*SearchRequest
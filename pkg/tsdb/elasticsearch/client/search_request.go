@@ -70,6 +70,12 @@ func (b *SearchRequestBuilder) Size(size int) *SearchRequestBuilder {
 	return b
 }
 
+// From sets the offset of the first hit returned by the search request
+func (b *SearchRequestBuilder) From(from int) *SearchRequestBuilder {
+	b.customProps["from"] = from
+	return b
+}
+
 // SortDesc adds a sort to the search request
 func (b *SearchRequestBuilder) SortDesc(field, unmappedType string) *SearchRequestBuilder {
 	props := map[string]string{
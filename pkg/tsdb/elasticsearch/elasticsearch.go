@@ -49,8 +49,40 @@ func (s *Service) QueryData(ctx context.Context, req *backend.QueryDataRequest)
 		return &backend.QueryDataResponse{}, err
 	}
 
-	query := newTimeSeriesQuery(client, req.Queries, s.intervalCalculator)
-	return query.execute()
+	// SQL and PPL queries are sent to their own endpoints, everything else goes
+	// through a single multi search request.
+	var searchQueries, sqlQueries []backend.DataQuery
+	for _, q := range req.Queries {
+		if isSQLQuery(q) {
+			sqlQueries = append(sqlQueries, q)
+		} else {
+			searchQueries = append(searchQueries, q)
+		}
+	}
+
+	result := backend.NewQueryDataResponse()
+	if len(searchQueries) > 0 {
+		query := newTimeSeriesQuery(client, searchQueries, s.intervalCalculator)
+		res, err := query.execute()
+		if err != nil {
+			return &backend.QueryDataResponse{}, err
+		}
+		for refID, r := range res.Responses {
+			result.Responses[refID] = r
+		}
+	}
+
+	if len(sqlQueries) > 0 {
+		res, err := newSQLQuery(client, sqlQueries).execute()
+		if err != nil {
+			return &backend.QueryDataResponse{}, err
+		}
+		for refID, r := range res.Responses {
+			result.Responses[refID] = r
+		}
+	}
+
+	return result, nil
 }
 
 func newInstanceSettings() datasource.InstanceFactoryFunc {
@@ -110,6 +142,16 @@ func newInstanceSettings() datasource.InstanceFactoryFunc {
 			xpack = false
 		}
 
+		logMessageField, ok := jsonData["logMessageField"].(string)
+		if !ok {
+			logMessageField = ""
+		}
+
+		logLevelField, ok := jsonData["logLevelField"].(string)
+		if !ok {
+			logLevelField = ""
+		}
+
 		model := es.DatasourceInfo{
 			ID:                         settings.ID,
 			URL:                        settings.URL,
@@ -122,6 +164,8 @@ func newInstanceSettings() datasource.InstanceFactoryFunc {
 			TimeInterval:               timeInterval,
 			IncludeFrozen:              includeFrozen,
 			XPack:                      xpack,
+			LogMessageField:            logMessageField,
+			LogLevelField:              logLevelField,
 		}
 		return model, nil
 	}
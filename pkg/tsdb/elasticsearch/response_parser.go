@@ -1,6 +1,7 @@
 package elasticsearch
 
 import (
+	"encoding/json"
 	"errors"
 	"regexp"
 	"sort"
@@ -20,6 +21,9 @@ const (
 	percentilesType   = "percentiles"
 	extendedStatsType = "extended_stats"
 	topMetricsType    = "top_metrics"
+	rawDocumentType   = "raw_document"
+	rawDataType       = "raw_data"
+	logsType          = "logs"
 	// Bucket types
 	dateHistType    = "date_histogram"
 	histogramType   = "histogram"
@@ -29,16 +33,19 @@ const (
 )
 
 type responseParser struct {
-	Responses []*es.SearchResponse
-	Targets   []*Query
-	DebugInfo *es.SearchDebugInfo
+	Responses        []*es.SearchResponse
+	Targets          []*Query
+	DebugInfo        *es.SearchDebugInfo
+	ConfiguredFields es.ConfiguredFields
 }
 
-var newResponseParser = func(responses []*es.SearchResponse, targets []*Query, debugInfo *es.SearchDebugInfo) *responseParser {
+var newResponseParser = func(responses []*es.SearchResponse, targets []*Query, debugInfo *es.SearchDebugInfo,
+	configuredFields es.ConfiguredFields) *responseParser {
 	return &responseParser{
-		Responses: responses,
-		Targets:   targets,
-		DebugInfo: debugInfo,
+		Responses:        responses,
+		Targets:          targets,
+		DebugInfo:        debugInfo,
+		ConfiguredFields: configuredFields,
 	}
 }
 
@@ -74,6 +81,15 @@ func (rp *responseParser) getTimeSeries() (*backend.QueryDataResponse, error) {
 
 		queryRes := backend.DataResponse{}
 
+		if len(target.BucketAggs) == 0 && len(target.Metrics) > 0 && isDocumentQuery(target.Metrics[0].Type) {
+			frame := rp.processHits(res.Hits, target.Metrics[0].Type)
+			frame.RefID = target.RefID
+			frame.Meta.Custom = debugInfo
+			queryRes.Frames = data.Frames{frame}
+			result.Responses[target.RefID] = queryRes
+			continue
+		}
+
 		props := make(map[string]string)
 		err := rp.processBuckets(res.Aggregations, target, &queryRes, props, 0)
 		if err != nil {
@@ -719,3 +735,198 @@ func getErrorFromElasticResponse(response *es.SearchResponse) string {
 
 	return errorString
 }
+
+// processHits converts the documents returned by a raw data or logs query into a frame,
+// with one row per document. Nested source fields are flattened using dot notation
+// and the time field always comes first.
+func (rp *responseParser) processHits(hits *es.SearchResponseHits, metricType string) *data.Frame {
+	timeField := rp.ConfiguredFields.TimeField
+	messageField := rp.ConfiguredFields.LogMessageField
+	levelField := rp.ConfiguredFields.LogLevelField
+
+	docs := make([]map[string]interface{}, 0)
+	fieldNames := make(map[string]bool)
+	if hits != nil {
+		for _, hit := range hits.Hits {
+			doc := map[string]interface{}{
+				"_id":    hit["_id"],
+				"_index": hit["_index"],
+			}
+			source, _ := hit["_source"].(map[string]interface{})
+			flattenSource(source, "", doc)
+
+			// Fall back to the doc value of the time field, which is always requested.
+			if _, ok := doc[timeField]; !ok {
+				if fields, ok := hit["fields"].(map[string]interface{}); ok {
+					if values, ok := fields[timeField].([]interface{}); ok && len(values) > 0 {
+						doc[timeField] = values[0]
+					}
+				}
+			}
+
+			if metricType == logsType && messageField == "" {
+				if encoded, err := json.Marshal(source); err == nil {
+					doc["_source"] = string(encoded)
+				}
+			}
+			if metricType == logsType && levelField != "" && levelField != "level" {
+				doc["level"] = doc[levelField]
+			}
+
+			for name := range doc {
+				fieldNames[name] = true
+			}
+			docs = append(docs, doc)
+		}
+	}
+
+	// The time field comes first, then for logs the message and level, then the rest
+	// in alphabetical order.
+	leading := []string{timeField}
+	if metricType == logsType {
+		if messageField != "" {
+			leading = append(leading, messageField)
+		} else {
+			leading = append(leading, "_source")
+		}
+		leading = append(leading, "level")
+	}
+	names := make([]string, 0, len(fieldNames))
+	for _, name := range leading {
+		if fieldNames[name] {
+			names = append(names, name)
+			delete(fieldNames, name)
+		}
+	}
+	rest := make([]string, 0, len(fieldNames))
+	for name := range fieldNames {
+		rest = append(rest, name)
+	}
+	sort.Strings(rest)
+	names = append(names, rest...)
+
+	frame := data.NewFrame("")
+	for _, name := range names {
+		values := make([]interface{}, 0, len(docs))
+		for _, doc := range docs {
+			values = append(values, doc[name])
+		}
+		frame.Fields = append(frame.Fields, newFieldFromValues(name, values, name == timeField))
+	}
+
+	frame.Meta = &data.FrameMeta{PreferredVisualization: data.VisTypeTable}
+	if metricType == logsType {
+		frame.Meta.PreferredVisualization = data.VisTypeLogs
+	}
+	if hits != nil && hits.Total != nil {
+		frame.Meta.Stats = []data.QueryStat{
+			{FieldConfig: data.FieldConfig{DisplayName: "Total hits"}, Value: float64(hits.Total.Value)},
+		}
+	}
+
+	return frame
+}
+
+// flattenSource copies the fields of a document source into doc, joining the names of
+// nested objects with dots.
+func flattenSource(source map[string]interface{}, prefix string, doc map[string]interface{}) {
+	for key, value := range source {
+		name := key
+		if prefix != "" {
+			name = prefix + "." + key
+		}
+		if nested, ok := value.(map[string]interface{}); ok {
+			flattenSource(nested, name, doc)
+			continue
+		}
+		doc[name] = value
+	}
+}
+
+// newFieldFromValues creates a nullable field for JSON decoded values. Numbers, booleans
+// and strings keep their type when all values share it, anything else is JSON encoded.
+// Time fields accept RFC 3339 strings and epoch milliseconds.
+func newFieldFromValues(name string, values []interface{}, isTime bool) *data.Field {
+	if isTime {
+		field := data.NewField(name, nil, make([]*time.Time, len(values)))
+		for i, v := range values {
+			if t, ok := parseTimeValue(v); ok {
+				field.Set(i, &t)
+			}
+		}
+		return field
+	}
+
+	var kind string
+	for _, v := range values {
+		var valueKind string
+		switch v.(type) {
+		case nil:
+			continue
+		case float64:
+			valueKind = "number"
+		case bool:
+			valueKind = "bool"
+		default:
+			valueKind = "string"
+		}
+		if kind == "" {
+			kind = valueKind
+		} else if kind != valueKind {
+			kind = "string"
+		}
+	}
+
+	switch kind {
+	case "number":
+		field := data.NewField(name, nil, make([]*float64, len(values)))
+		for i, v := range values {
+			if f, ok := v.(float64); ok {
+				field.Set(i, &f)
+			}
+		}
+		return field
+	case "bool":
+		field := data.NewField(name, nil, make([]*bool, len(values)))
+		for i, v := range values {
+			if b, ok := v.(bool); ok {
+				field.Set(i, &b)
+			}
+		}
+		return field
+	default:
+		field := data.NewField(name, nil, make([]*string, len(values)))
+		for i, v := range values {
+			switch value := v.(type) {
+			case nil:
+			case string:
+				field.Set(i, &value)
+			default:
+				if encoded, err := json.Marshal(value); err == nil {
+					s := string(encoded)
+					field.Set(i, &s)
+				}
+			}
+		}
+		return field
+	}
+}
+
+func parseTimeValue(v interface{}) (time.Time, bool) {
+	switch value := v.(type) {
+	case float64:
+		return time.Unix(0, int64(value)*int64(time.Millisecond)).UTC(), true
+	case string:
+		if t, err := time.Parse(time.RFC3339Nano, value); err == nil {
+			return t.UTC(), true
+		}
+		// SQL and PPL return timestamps without a time zone, in UTC.
+		if t, err := time.Parse("2006-01-02 15:04:05.999999999", value); err == nil {
+			return t, true
+		}
+		if ms, err := strconv.ParseInt(value, 10, 64); err == nil {
+			return time.Unix(0, ms*int64(time.Millisecond)).UTC(), true
+		}
+	}
+	return time.Time{}, false
+}
@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
 	es "github.com/grafana/grafana/pkg/tsdb/elasticsearch/client"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -1061,6 +1062,101 @@ func TestResponseParser(t *testing.T) {
 	})
 }
 
+func TestProcessHits(t *testing.T) {
+	hitsResponse := `{
+		"responses": [
+			{
+				"hits": {
+					"total": { "value": 2, "relation": "eq" },
+					"hits": [
+						{
+							"_id": "1",
+							"_index": "logs-2018.05.15",
+							"_source": {
+								"@timestamp": "2018-05-15T17:50:00.000Z",
+								"message": "hello",
+								"lvl": "info",
+								"host": { "name": "a" },
+								"bytes": 10,
+								"tags": ["x"]
+							}
+						},
+						{
+							"_id": "2",
+							"_index": "logs-2018.05.15",
+							"_source": { "message": "world", "host": { "name": "b" }, "ok": true },
+							"fields": { "@timestamp": [1526406660000] }
+						}
+					]
+				}
+			}
+		]
+	}`
+
+	t.Run("Raw data", func(t *testing.T) {
+		targets := map[string]string{
+			"A": `{ "timeField": "@timestamp", "bucketAggs": [], "metrics": [{ "type": "raw_data", "id": "1" }] }`,
+		}
+		rp, err := newResponseParserForTest(targets, hitsResponse)
+		require.NoError(t, err)
+		result, err := rp.getTimeSeries()
+		require.NoError(t, err)
+
+		frames := result.Responses["A"].Frames
+		require.Len(t, frames, 1)
+		frame := frames[0]
+		require.Equal(t, data.VisTypeTable, string(frame.Meta.PreferredVisualization))
+		require.Equal(t, 2., frame.Meta.Stats[0].Value)
+
+		names := make([]string, 0, len(frame.Fields))
+		for _, f := range frame.Fields {
+			names = append(names, f.Name)
+		}
+		require.Equal(t, []string{"@timestamp", "_id", "_index", "bytes", "host.name", "lvl", "message", "ok", "tags"}, names)
+
+		require.Equal(t, time.Date(2018, 5, 15, 17, 50, 0, 0, time.UTC), *frame.Fields[0].At(0).(*time.Time))
+		require.Equal(t, time.Date(2018, 5, 15, 17, 51, 0, 0, time.UTC), *frame.Fields[0].At(1).(*time.Time))
+		require.Equal(t, 10., *frame.Fields[3].At(0).(*float64))
+		require.Nil(t, frame.Fields[3].At(1))
+		require.Equal(t, "b", *frame.Fields[4].At(1).(*string))
+		require.Equal(t, true, *frame.Fields[7].At(1).(*bool))
+		require.Equal(t, `["x"]`, *frame.Fields[8].At(0).(*string))
+	})
+
+	t.Run("Logs", func(t *testing.T) {
+		targets := map[string]string{
+			"A": `{ "timeField": "@timestamp", "bucketAggs": [], "metrics": [{ "type": "logs", "id": "1" }] }`,
+		}
+		rp, err := newResponseParserForTest(targets, hitsResponse)
+		require.NoError(t, err)
+		rp.ConfiguredFields.LogMessageField = "message"
+		rp.ConfiguredFields.LogLevelField = "lvl"
+		result, err := rp.getTimeSeries()
+		require.NoError(t, err)
+
+		frame := result.Responses["A"].Frames[0]
+		require.Equal(t, data.VisTypeLogs, string(frame.Meta.PreferredVisualization))
+		require.Equal(t, "@timestamp", frame.Fields[0].Name)
+		require.Equal(t, "message", frame.Fields[1].Name)
+		require.Equal(t, "level", frame.Fields[2].Name)
+		require.Equal(t, "info", *frame.Fields[2].At(0).(*string))
+	})
+
+	t.Run("Logs without message field", func(t *testing.T) {
+		targets := map[string]string{
+			"A": `{ "timeField": "@timestamp", "bucketAggs": [], "metrics": [{ "type": "logs", "id": "1" }] }`,
+		}
+		rp, err := newResponseParserForTest(targets, hitsResponse)
+		require.NoError(t, err)
+		result, err := rp.getTimeSeries()
+		require.NoError(t, err)
+
+		frame := result.Responses["A"].Frames[0]
+		require.Equal(t, "_source", frame.Fields[1].Name)
+		require.JSONEq(t, `{"message":"world","host":{"name":"b"},"ok":true}`, *frame.Fields[1].At(1).(*string))
+	})
+}
+
 func newResponseParserForTest(tsdbQueries map[string]string, responseBody string) (*responseParser, error) {
 	from := time.Date(2018, 5, 15, 17, 50, 0, 0, time.UTC)
 	to := time.Date(2018, 5, 15, 17, 55, 0, 0, time.UTC)
@@ -1092,5 +1188,5 @@ func newResponseParserForTest(tsdbQueries map[string]string, responseBody string
 		return nil, err
 	}
 
-	return newResponseParser(response.Responses, queries, nil), nil
+	return newResponseParser(response.Responses, queries, nil, es.ConfiguredFields{TimeField: "@timestamp"}), nil
 }
@@ -0,0 +1,139 @@
+package elasticsearch
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	es "github.com/grafana/grafana/pkg/tsdb/elasticsearch/client"
+)
+
+const defaultSQLFetchSize = 1000
+
+// sqlQueryModel is the query model of Elasticsearch SQL and OpenSearch PPL queries
+type sqlQueryModel struct {
+	QueryType string `json:"queryType"`
+	Query     string `json:"query"`
+	FetchSize int    `json:"fetchSize"`
+}
+
+func isSQLQuery(q backend.DataQuery) bool {
+	queryType := q.QueryType
+	if queryType == "" {
+		var model sqlQueryModel
+		if err := json.Unmarshal(q.JSON, &model); err != nil {
+			return false
+		}
+		queryType = model.QueryType
+	}
+	return queryType == es.SQLLanguageSQL || queryType == es.SQLLanguagePPL
+}
+
+type sqlQuery struct {
+	client      es.Client
+	dataQueries []backend.DataQuery
+}
+
+var newSQLQuery = func(client es.Client, dataQueries []backend.DataQuery) *sqlQuery {
+	return &sqlQuery{
+		client:      client,
+		dataQueries: dataQueries,
+	}
+}
+
+func (e *sqlQuery) execute() (*backend.QueryDataResponse, error) {
+	result := backend.NewQueryDataResponse()
+
+	for _, q := range e.dataQueries {
+		var model sqlQueryModel
+		if err := json.Unmarshal(q.JSON, &model); err != nil {
+			return &backend.QueryDataResponse{}, err
+		}
+		if q.QueryType != "" {
+			model.QueryType = q.QueryType
+		}
+
+		if model.Query == "" {
+			result.Responses[q.RefID] = backend.DataResponse{
+				Error: fmt.Errorf("invalid query, missing %s query", model.QueryType),
+			}
+			continue
+		}
+
+		req := &es.SQLRequest{
+			Language:  model.QueryType,
+			Query:     model.Query,
+			FetchSize: model.FetchSize,
+		}
+		if req.FetchSize == 0 {
+			req.FetchSize = defaultSQLFetchSize
+		}
+		// PPL queries select their source themselves, so the time range can only be
+		// applied to SQL queries.
+		if req.Language == es.SQLLanguageSQL {
+			req.Filter = map[string]interface{}{
+				"range": map[string]interface{}{
+					e.client.GetTimeField(): map[string]interface{}{
+						"gte":    q.TimeRange.From.UnixNano() / int64(time.Millisecond),
+						"lte":    q.TimeRange.To.UnixNano() / int64(time.Millisecond),
+						"format": es.DateFormatEpochMS,
+					},
+				},
+			}
+		}
+
+		res, err := e.client.ExecuteSQL(req)
+		if err != nil {
+			return &backend.QueryDataResponse{}, err
+		}
+
+		if reason := res.ErrorReason(); reason != "" {
+			result.Responses[q.RefID] = backend.DataResponse{
+				Error: fmt.Errorf("%s query failed: %s", model.QueryType, reason),
+			}
+			continue
+		}
+
+		frame := sqlResponseToFrame(res)
+		frame.RefID = q.RefID
+		frame.Meta = &data.FrameMeta{
+			ExecutedQueryString: model.Query,
+		}
+		result.Responses[q.RefID] = backend.DataResponse{Frames: data.Frames{frame}}
+	}
+
+	return result, nil
+}
+
+// sqlResponseToFrame converts the tabular result of a SQL or PPL query into a frame.
+func sqlResponseToFrame(res *es.SQLResponse) *data.Frame {
+	columns, rows := res.Columns, res.Rows
+	if len(columns) == 0 {
+		columns, rows = res.Schema, res.DataRows
+	}
+
+	frame := data.NewFrame("")
+	for i, column := range columns {
+		values := make([]interface{}, 0, len(rows))
+		for _, row := range rows {
+			var value interface{}
+			if i < len(row) {
+				value = row[i]
+			}
+			values = append(values, value)
+		}
+		frame.Fields = append(frame.Fields, newFieldFromValues(column.Name, values, isSQLTimeType(column.Type)))
+	}
+
+	return frame
+}
+
+func isSQLTimeType(columnType string) bool {
+	switch columnType {
+	case "datetime", "date", "timestamp", "time":
+		return true
+	}
+	return false
+}
@@ -0,0 +1,106 @@
+package elasticsearch
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	es "github.com/grafana/grafana/pkg/tsdb/elasticsearch/client"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSQLQuery(t *testing.T) {
+	from := time.Date(2018, 5, 15, 17, 50, 0, 0, time.UTC)
+	to := time.Date(2018, 5, 15, 17, 55, 0, 0, time.UTC)
+	timeRange := backend.TimeRange{From: from, To: to}
+
+	t.Run("isSQLQuery", func(t *testing.T) {
+		require.True(t, isSQLQuery(backend.DataQuery{QueryType: "sql"}))
+		require.True(t, isSQLQuery(backend.DataQuery{JSON: json.RawMessage(`{"queryType":"ppl"}`)}))
+		require.False(t, isSQLQuery(backend.DataQuery{JSON: json.RawMessage(`{"metrics":[]}`)}))
+	})
+
+	t.Run("SQL query is filtered on the time range", func(t *testing.T) {
+		c := newFakeClient("7.10.0")
+		c.sqlResponse = &es.SQLResponse{
+			Columns: []es.SQLColumn{{Name: "@timestamp", Type: "datetime"}, {Name: "host", Type: "keyword"}, {Name: "bytes", Type: "long"}},
+			Rows: [][]interface{}{
+				{"2018-05-15T17:51:00.000Z", "a", 10.},
+				{"2018-05-15T17:52:00.000Z", nil, 20.},
+			},
+		}
+		result, err := newSQLQuery(c, []backend.DataQuery{{
+			RefID:     "A",
+			TimeRange: timeRange,
+			JSON:      json.RawMessage(`{"queryType":"sql","query":"SELECT * FROM logs"}`),
+		}}).execute()
+		require.NoError(t, err)
+
+		require.Len(t, c.sqlRequests, 1)
+		req := c.sqlRequests[0]
+		require.Equal(t, es.SQLLanguageSQL, req.Language)
+		require.Equal(t, "SELECT * FROM logs", req.Query)
+		require.Equal(t, defaultSQLFetchSize, req.FetchSize)
+		rangeFilter := req.Filter["range"].(map[string]interface{})["@timestamp"].(map[string]interface{})
+		require.Equal(t, from.UnixNano()/int64(time.Millisecond), rangeFilter["gte"])
+		require.Equal(t, to.UnixNano()/int64(time.Millisecond), rangeFilter["lte"])
+
+		res := result.Responses["A"]
+		require.NoError(t, res.Error)
+		frame := res.Frames[0]
+		require.Equal(t, "SELECT * FROM logs", frame.Meta.ExecutedQueryString)
+		require.Len(t, frame.Fields, 3)
+		require.Equal(t, time.Date(2018, 5, 15, 17, 51, 0, 0, time.UTC), *frame.Fields[0].At(0).(*time.Time))
+		require.Equal(t, "a", *frame.Fields[1].At(0).(*string))
+		require.Nil(t, frame.Fields[1].At(1))
+		require.Equal(t, 20., *frame.Fields[2].At(1).(*float64))
+	})
+
+	t.Run("PPL query response uses schema and datarows", func(t *testing.T) {
+		c := newFakeClient("7.10.0")
+		c.sqlResponse = &es.SQLResponse{
+			Schema:   []es.SQLColumn{{Name: "ts", Type: "timestamp"}, {Name: "count()", Type: "integer"}},
+			DataRows: [][]interface{}{{"2018-05-15 17:51:00", 3.}},
+		}
+		result, err := newSQLQuery(c, []backend.DataQuery{{
+			RefID:     "B",
+			QueryType: "ppl",
+			TimeRange: timeRange,
+			JSON:      json.RawMessage(`{"query":"source=logs | stats count() by ts","fetchSize":10}`),
+		}}).execute()
+		require.NoError(t, err)
+
+		req := c.sqlRequests[0]
+		require.Equal(t, es.SQLLanguagePPL, req.Language)
+		require.Equal(t, 10, req.FetchSize)
+		require.Nil(t, req.Filter)
+
+		frame := result.Responses["B"].Frames[0]
+		require.Equal(t, time.Date(2018, 5, 15, 17, 51, 0, 0, time.UTC), *frame.Fields[0].At(0).(*time.Time))
+		require.Equal(t, 3., *frame.Fields[1].At(0).(*float64))
+	})
+
+	t.Run("Errors are returned per query", func(t *testing.T) {
+		c := newFakeClient("7.10.0")
+		c.sqlResponse = &es.SQLResponse{Error: json.RawMessage(`{"root_cause":[{"reason":"Unknown index [logs]"}],"reason":"Bad request"}`)}
+		result, err := newSQLQuery(c, []backend.DataQuery{
+			{RefID: "A", QueryType: "sql", JSON: json.RawMessage(`{"query":"SELECT * FROM logs"}`)},
+			{RefID: "B", QueryType: "sql", JSON: json.RawMessage(`{}`)},
+		}).execute()
+		require.NoError(t, err)
+
+		require.EqualError(t, result.Responses["A"].Error, "sql query failed: Unknown index [logs]")
+		require.EqualError(t, result.Responses["B"].Error, "invalid query, missing sql query")
+	})
+
+	t.Run("Errors can be strings", func(t *testing.T) {
+		c := newFakeClient("7.10.0")
+		c.sqlResponse = &es.SQLResponse{Error: json.RawMessage(`"Invalid Query"`)}
+		result, err := newSQLQuery(c, []backend.DataQuery{
+			{RefID: "A", QueryType: "ppl", JSON: json.RawMessage(`{"query":"source=logs | where"}`)},
+		}).execute()
+		require.NoError(t, err)
+		require.EqualError(t, result.Responses["A"].Error, "ppl query failed: Invalid Query")
+	})
+}
@@ -13,6 +13,8 @@ import (
 	"github.com/grafana/grafana/pkg/tsdb/intervalv2"
 )
 
+const defaultDocumentSize = 500
+
 type timeSeriesQuery struct {
 	client             es.Client
 	dataQueries        []backend.DataQuery
@@ -58,7 +60,7 @@ func (e *timeSeriesQuery) execute() (*backend.QueryDataResponse, error) {
 		return &backend.QueryDataResponse{}, err
 	}
 
-	rp := newResponseParser(res.Responses, queries, res.DebugInfo)
+	rp := newResponseParser(res.Responses, queries, res.DebugInfo, e.client.GetConfiguredFields())
 	return rp.getTimeSeries()
 }
 
@@ -80,16 +82,13 @@ func (e *timeSeriesQuery) processQuery(q *Query, ms *es.MultiSearchRequestBuilde
 	}
 
 	if len(q.BucketAggs) == 0 {
-		if len(q.Metrics) == 0 || q.Metrics[0].Type != "raw_document" {
+		if len(q.Metrics) == 0 || !isDocumentQuery(q.Metrics[0].Type) {
 			result.Responses[q.RefID] = backend.DataResponse{
 				Error: fmt.Errorf("invalid query, missing metrics and aggregations"),
 			}
 			return nil
 		}
-		metric := q.Metrics[0]
-		b.Size(metric.Settings.Get("size").MustInt(500))
-		b.SortDesc("@timestamp", "boolean")
-		b.AddDocValueField("@timestamp")
+		processDocumentQuery(q.Metrics[0], b, e.client.GetTimeField())
 		return nil
 	}
 
@@ -182,6 +181,29 @@ func (e *timeSeriesQuery) processQuery(q *Query, ms *es.MultiSearchRequestBuilde
 	return nil
 }
 
+// isDocumentQuery returns true if the metric returns the matching documents rather than
+// aggregations.
+func isDocumentQuery(metricType string) bool {
+	return metricType == rawDocumentType || metricType == rawDataType || metricType == logsType
+}
+
+// processDocumentQuery requests the most recent documents of the time range. Logs
+// queries take their size from the limit setting, and all document queries can be
+// paginated with the from setting. Documents are sorted by the configured time field,
+// raw document queries used to sort by @timestamp regardless of it.
+func processDocumentQuery(metric *MetricAgg, b *es.SearchRequestBuilder, timeField string) {
+	sizeSetting := "size"
+	if metric.Type == logsType {
+		sizeSetting = "limit"
+	}
+	b.Size(metric.Settings.Get(sizeSetting).MustInt(defaultDocumentSize))
+	if from := metric.Settings.Get("from").MustInt(0); from > 0 {
+		b.From(from)
+	}
+	b.SortDesc(timeField, "boolean")
+	b.AddDocValueField(timeField)
+}
+
 func setFloatPath(settings *simplejson.Json, path ...string) {
 	if stringValue, err := settings.GetPath(path...).String(); err == nil {
 		if value, err := strconv.ParseFloat(stringValue, 64); err == nil {
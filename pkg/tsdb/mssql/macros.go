@@ -22,8 +22,19 @@ func newMssqlMacroEngine() sqleng.SQLMacroEngine {
 	return &msSQLMacroEngine{SQLMacroEngineBase: sqleng.NewSQLMacroEngineBase()}
 }
 
-func (m *msSQLMacroEngine) Interpolate(query *backend.DataQuery, timeRange backend.TimeRange,
-	sql string) (string, error) {
+func (m *msSQLMacroEngine) Interpolate(query *backend.DataQuery, timeRange backend.TimeRange, sql string) (string, error) {
+	return m.interpolate(query, timeRange, sql, nil)
+}
+
+// InterpolateWithParameters interpolates macros like Interpolate, but binds the
+// time range values as query parameters.
+func (m *msSQLMacroEngine) InterpolateWithParameters(query *backend.DataQuery, timeRange backend.TimeRange, sql string,
+	params *sqleng.QueryParameters) (string, error) {
+	return m.interpolate(query, timeRange, sql, params)
+}
+
+func (m *msSQLMacroEngine) interpolate(query *backend.DataQuery, timeRange backend.TimeRange, sql string,
+	params *sqleng.QueryParameters) (string, error) {
 	// TODO: Return any error
 	rExp, _ := regexp.Compile(sExpr)
 	var macroError error
@@ -33,7 +44,7 @@ func (m *msSQLMacroEngine) Interpolate(query *backend.DataQuery, timeRange backe
 		for i, arg := range args {
 			args[i] = strings.Trim(arg, " ")
 		}
-		res, err := m.evaluateMacro(timeRange, query, groups[1], args)
+		res, err := m.evaluateMacro(timeRange, query, groups[1], args, params)
 		if err != nil && macroError == nil {
 			macroError = err
 			return "macro_error()"
@@ -48,7 +59,8 @@ func (m *msSQLMacroEngine) Interpolate(query *backend.DataQuery, timeRange backe
 	return sql, nil
 }
 
-func (m *msSQLMacroEngine) evaluateMacro(timeRange backend.TimeRange, query *backend.DataQuery, name string, args []string) (string, error) {
+func (m *msSQLMacroEngine) evaluateMacro(timeRange backend.TimeRange, query *backend.DataQuery, name string, args []string,
+	params *sqleng.QueryParameters) (string, error) {
 	switch name {
 	case "__time":
 		if len(args) == 0 {
@@ -65,11 +77,11 @@ func (m *msSQLMacroEngine) evaluateMacro(timeRange backend.TimeRange, query *bac
 			return "", fmt.Errorf("missing time column argument for macro %v", name)
 		}
 
-		return fmt.Sprintf("%s BETWEEN '%s' AND '%s'", args[0], timeRange.From.UTC().Format(time.RFC3339), timeRange.To.UTC().Format(time.RFC3339)), nil
+		return fmt.Sprintf("%s BETWEEN %s AND %s", args[0], timeValue(timeRange.From, params), timeValue(timeRange.To, params)), nil
 	case "__timeFrom":
-		return fmt.Sprintf("'%s'", timeRange.From.UTC().Format(time.RFC3339)), nil
+		return timeValue(timeRange.From, params), nil
 	case "__timeTo":
-		return fmt.Sprintf("'%s'", timeRange.To.UTC().Format(time.RFC3339)), nil
+		return timeValue(timeRange.To, params), nil
 	case "__timeGroup":
 		if len(args) < 2 {
 			return "", fmt.Errorf("macro %v needs time column and interval", name)
@@ -86,7 +98,7 @@ func (m *msSQLMacroEngine) evaluateMacro(timeRange backend.TimeRange, query *bac
 		}
 		return fmt.Sprintf("FLOOR(DATEDIFF(second, '1970-01-01', %s)/%.0f)*%.0f", args[0], interval.Seconds(), interval.Seconds()), nil
 	case "__timeGroupAlias":
-		tg, err := m.evaluateMacro(timeRange, query, "__timeGroup", args)
+		tg, err := m.evaluateMacro(timeRange, query, "__timeGroup", args, params)
 		if err == nil {
 			return tg + " AS [time]", nil
 		}
@@ -121,7 +133,7 @@ func (m *msSQLMacroEngine) evaluateMacro(timeRange backend.TimeRange, query *bac
 		}
 		return fmt.Sprintf("FLOOR(%s/%v)*%v", args[0], interval.Seconds(), interval.Seconds()), nil
 	case "__unixEpochGroupAlias":
-		tg, err := m.evaluateMacro(timeRange, query, "__unixEpochGroup", args)
+		tg, err := m.evaluateMacro(timeRange, query, "__unixEpochGroup", args, params)
 		if err == nil {
 			return tg + " AS [time]", nil
 		}
@@ -130,3 +142,9 @@ func (m *msSQLMacroEngine) evaluateMacro(timeRange backend.TimeRange, query *bac
 		return "", fmt.Errorf("unknown macro %q", name)
 	}
 }
+
+func timeValue(t time.Time, params *sqleng.QueryParameters) string {
+	// The literal has second precision, keep the bound value consistent with it.
+	t = t.UTC().Truncate(time.Second)
+	return params.Bind(t, fmt.Sprintf("'%s'", t.Format(time.RFC3339)))
+}
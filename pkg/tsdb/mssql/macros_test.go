@@ -8,6 +8,7 @@ import (
 	"time"
 
 	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana/pkg/tsdb/sqleng"
 
 	"github.com/stretchr/testify/require"
 )
@@ -242,3 +243,22 @@ func TestMacroEngineConcurrency(t *testing.T) {
 
 	wg.Wait()
 }
+
+func TestMacroEngineWithParameters(t *testing.T) {
+	engine := newMssqlMacroEngine().(sqleng.SQLParameterizedMacroEngine)
+	from := time.Date(2018, 4, 12, 18, 0, 0, 0, time.UTC)
+	to := from.Add(5 * time.Minute)
+	timeRange := backend.TimeRange{From: from, To: to}
+
+	params := sqleng.NewQueryParameters()
+	sql, err := engine.InterpolateWithParameters(&backend.DataQuery{}, timeRange, "WHERE $__timeFilter(time_column)", params)
+	require.NoError(t, err)
+	sql, bound, err := params.Finalize(sql, sqleng.AtPPlaceholder)
+	require.NoError(t, err)
+
+	require.Equal(t, "WHERE time_column BETWEEN @p1 AND @p2", sql)
+	require.Equal(t, []sqleng.QueryParameter{
+		{Placeholder: "@p1", Value: from},
+		{Placeholder: "@p2", Value: to},
+	}, bound)
+}
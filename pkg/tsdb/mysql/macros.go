@@ -4,7 +4,9 @@ import (
 	"errors"
 	"fmt"
 	"regexp"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/grafana/grafana-plugin-sdk-go/backend"
 	"github.com/grafana/grafana-plugin-sdk-go/backend/gtime"
@@ -27,6 +29,18 @@ func newMysqlMacroEngine(logger log.Logger) sqleng.SQLMacroEngine {
 }
 
 func (m *mySQLMacroEngine) Interpolate(query *backend.DataQuery, timeRange backend.TimeRange, sql string) (string, error) {
+	return m.interpolate(query, timeRange, sql, nil)
+}
+
+// InterpolateWithParameters interpolates macros like Interpolate, but binds the
+// time range values as query parameters.
+func (m *mySQLMacroEngine) InterpolateWithParameters(query *backend.DataQuery, timeRange backend.TimeRange, sql string,
+	params *sqleng.QueryParameters) (string, error) {
+	return m.interpolate(query, timeRange, sql, params)
+}
+
+func (m *mySQLMacroEngine) interpolate(query *backend.DataQuery, timeRange backend.TimeRange, sql string,
+	params *sqleng.QueryParameters) (string, error) {
 	matches := restrictedRegExp.FindAllStringSubmatch(sql, 1)
 	if len(matches) > 0 {
 		m.logger.Error("show grants, session_user(), current_user(), system_user() or user() not allowed in query")
@@ -42,7 +56,7 @@ func (m *mySQLMacroEngine) Interpolate(query *backend.DataQuery, timeRange backe
 		for i, arg := range args {
 			args[i] = strings.Trim(arg, " ")
 		}
-		res, err := m.evaluateMacro(timeRange, query, groups[1], args)
+		res, err := m.evaluateMacro(timeRange, query, groups[1], args, params)
 		if err != nil && macroError == nil {
 			macroError = err
 			return "macro_error()"
@@ -57,7 +71,8 @@ func (m *mySQLMacroEngine) Interpolate(query *backend.DataQuery, timeRange backe
 	return sql, nil
 }
 
-func (m *mySQLMacroEngine) evaluateMacro(timeRange backend.TimeRange, query *backend.DataQuery, name string, args []string) (string, error) {
+func (m *mySQLMacroEngine) evaluateMacro(timeRange backend.TimeRange, query *backend.DataQuery, name string, args []string,
+	params *sqleng.QueryParameters) (string, error) {
 	switch name {
 	case "__timeEpoch", "__time":
 		if len(args) == 0 {
@@ -69,11 +84,11 @@ func (m *mySQLMacroEngine) evaluateMacro(timeRange backend.TimeRange, query *bac
 			return "", fmt.Errorf("missing time column argument for macro %v", name)
 		}
 
-		return fmt.Sprintf("%s BETWEEN FROM_UNIXTIME(%d) AND FROM_UNIXTIME(%d)", args[0], timeRange.From.UTC().Unix(), timeRange.To.UTC().Unix()), nil
+		return fmt.Sprintf("%s BETWEEN %s AND %s", args[0], fromUnixTime(timeRange.From, params), fromUnixTime(timeRange.To, params)), nil
 	case "__timeFrom":
-		return fmt.Sprintf("FROM_UNIXTIME(%d)", timeRange.From.UTC().Unix()), nil
+		return fromUnixTime(timeRange.From, params), nil
 	case "__timeTo":
-		return fmt.Sprintf("FROM_UNIXTIME(%d)", timeRange.To.UTC().Unix()), nil
+		return fromUnixTime(timeRange.To, params), nil
 	case "__timeGroup":
 		if len(args) < 2 {
 			return "", fmt.Errorf("macro %v needs time column and interval", name)
@@ -90,7 +105,7 @@ func (m *mySQLMacroEngine) evaluateMacro(timeRange backend.TimeRange, query *bac
 		}
 		return fmt.Sprintf("UNIX_TIMESTAMP(%s) DIV %.0f * %.0f", args[0], interval.Seconds(), interval.Seconds()), nil
 	case "__timeGroupAlias":
-		tg, err := m.evaluateMacro(timeRange, query, "__timeGroup", args)
+		tg, err := m.evaluateMacro(timeRange, query, "__timeGroup", args, params)
 		if err == nil {
 			return tg + " AS \"time\"", nil
 		}
@@ -125,7 +140,7 @@ func (m *mySQLMacroEngine) evaluateMacro(timeRange backend.TimeRange, query *bac
 		}
 		return fmt.Sprintf("%s DIV %v * %v", args[0], interval.Seconds(), interval.Seconds()), nil
 	case "__unixEpochGroupAlias":
-		tg, err := m.evaluateMacro(timeRange, query, "__unixEpochGroup", args)
+		tg, err := m.evaluateMacro(timeRange, query, "__unixEpochGroup", args, params)
 		if err == nil {
 			return tg + " AS \"time\"", nil
 		}
@@ -134,3 +149,8 @@ func (m *mySQLMacroEngine) evaluateMacro(timeRange backend.TimeRange, query *bac
 		return "", fmt.Errorf("unknown macro %v", name)
 	}
 }
+
+func fromUnixTime(t time.Time, params *sqleng.QueryParameters) string {
+	epoch := t.UTC().Unix()
+	return fmt.Sprintf("FROM_UNIXTIME(%s)", params.Bind(epoch, strconv.FormatInt(epoch, 10)))
+}
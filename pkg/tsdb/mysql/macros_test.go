@@ -8,6 +8,7 @@ import (
 
 	"github.com/grafana/grafana-plugin-sdk-go/backend"
 	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/tsdb/sqleng"
 
 	"github.com/stretchr/testify/require"
 )
@@ -221,3 +222,27 @@ func TestMacroEngineConcurrency(t *testing.T) {
 
 	wg.Wait()
 }
+
+func TestMacroEngineWithParameters(t *testing.T) {
+	engine := newMysqlMacroEngine(log.New("test")).(sqleng.SQLParameterizedMacroEngine)
+	from := time.Date(2018, 4, 12, 18, 0, 0, 0, time.UTC)
+	to := from.Add(5 * time.Minute)
+	timeRange := backend.TimeRange{From: from, To: to}
+
+	params := sqleng.NewQueryParameters()
+	sql, err := engine.InterpolateWithParameters(&backend.DataQuery{}, timeRange, "WHERE $__timeFilter(time_column) AND $__timeFrom() < $__timeTo()", params)
+	require.NoError(t, err)
+	sql, bound, err := params.Finalize(sql, sqleng.QuestionPlaceholder)
+	require.NoError(t, err)
+
+	require.Equal(t, "WHERE time_column BETWEEN FROM_UNIXTIME(?) AND FROM_UNIXTIME(?) AND FROM_UNIXTIME(?) < FROM_UNIXTIME(?)", sql)
+	require.Equal(t, []interface{}{from.Unix(), to.Unix(), from.Unix(), to.Unix()}, boundValues(bound))
+}
+
+func boundValues(params []sqleng.QueryParameter) []interface{} {
+	values := make([]interface{}, 0, len(params))
+	for _, p := range params {
+		values = append(values, p.Value)
+	}
+	return values
+}
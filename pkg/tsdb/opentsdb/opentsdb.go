@@ -8,6 +8,7 @@ import (
 	"net/http"
 	"net/url"
 	"path"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
@@ -15,6 +16,7 @@ import (
 	"github.com/grafana/grafana-plugin-sdk-go/backend"
 	"github.com/grafana/grafana-plugin-sdk-go/backend/datasource"
 	"github.com/grafana/grafana-plugin-sdk-go/backend/instancemgmt"
+	"github.com/grafana/grafana-plugin-sdk-go/backend/resource/httpadapter"
 	"github.com/grafana/grafana-plugin-sdk-go/data"
 	"github.com/grafana/grafana/pkg/components/simplejson"
 	"github.com/grafana/grafana/pkg/infra/httpclient"
@@ -23,16 +25,28 @@ import (
 	"golang.org/x/net/context/ctxhttp"
 )
 
+const annotationQueryType = "annotation"
+
 type Service struct {
 	logger log.Logger
 	im     instancemgmt.InstanceManager
+
+	resourceHandler backend.CallResourceHandler
 }
 
 func ProvideService(httpClientProvider httpclient.Provider) *Service {
-	return &Service{
+	s := &Service{
 		logger: log.New("tsdb.opentsdb"),
 		im:     datasource.NewInstanceManager(newInstanceSettings(httpClientProvider)),
 	}
+
+	s.resourceHandler = httpadapter.New(s.newResourceMux())
+
+	return s
+}
+
+func (s *Service) CallResource(ctx context.Context, req *backend.CallResourceRequest, sender backend.CallResourceResponseSender) error {
+	return s.resourceHandler.CallResource(ctx, req, sender)
 }
 
 type datasourceInfo struct {
@@ -70,10 +84,17 @@ func (s *Service) QueryData(ctx context.Context, req *backend.QueryDataRequest)
 
 	tsdbQuery.Start = q.TimeRange.From.UnixNano() / int64(time.Millisecond)
 	tsdbQuery.End = q.TimeRange.To.UnixNano() / int64(time.Millisecond)
+	// All queries of the request are sent in a single call, showQuery makes
+	// OpenTSDB echo the index of the sub query each series belongs to.
+	tsdbQuery.ShowQuery = true
 
 	for _, query := range req.Queries {
 		metric := s.buildMetric(query)
 		tsdbQuery.Queries = append(tsdbQuery.Queries, metric)
+
+		if query.QueryType == annotationQueryType && isGlobalAnnotationQuery(query) {
+			tsdbQuery.GlobalAnnotations = true
+		}
 	}
 
 	// TODO: Don't use global variable
@@ -96,7 +117,7 @@ func (s *Service) QueryData(ctx context.Context, req *backend.QueryDataRequest)
 		return &backend.QueryDataResponse{}, err
 	}
 
-	result, err := s.parseResponse(res)
+	result, err := s.parseResponse(res, req.Queries)
 	if err != nil {
 		return &backend.QueryDataResponse{}, err
 	}
@@ -127,7 +148,7 @@ func (s *Service) createRequest(dsInfo *datasourceInfo, data OpenTsdbQuery) (*ht
 	return req, nil
 }
 
-func (s *Service) parseResponse(res *http.Response) (*backend.QueryDataResponse, error) {
+func (s *Service) parseResponse(res *http.Response, queries []backend.DataQuery) (*backend.QueryDataResponse, error) {
 	resp := backend.NewQueryDataResponse()
 
 	body, err := ioutil.ReadAll(res.Body)
@@ -152,31 +173,113 @@ func (s *Service) parseResponse(res *http.Response) (*backend.QueryDataResponse,
 		return nil, err
 	}
 
-	frames := data.Frames{}
 	for _, val := range responseData {
-		timeVector := make([]time.Time, 0, len(val.DataPoints))
-		values := make([]float64, 0, len(val.DataPoints))
-		name := val.Metric
+		query, ok := queryForResponse(val, queries)
+		if !ok {
+			s.logger.Warn("Received series for unknown opentsdb query", "metric", val.Metric, "index", val.Query.Index)
+			continue
+		}
 
-		for timeString, value := range val.DataPoints {
-			timestamp, err := strconv.ParseInt(timeString, 10, 64)
+		var frame *data.Frame
+		if query.QueryType == annotationQueryType {
+			frame = annotationsToFrame(val, isGlobalAnnotationQuery(query))
+		} else {
+			frame, err = dataPointsToFrame(val)
 			if err != nil {
-				s.logger.Info("Failed to unmarshal opentsdb timestamp", "timestamp", timeString)
+				s.logger.Info("Failed to unmarshal opentsdb timestamp", "error", err)
 				return nil, err
 			}
-			timeVector = append(timeVector, time.Unix(timestamp, 0).UTC())
-			values = append(values, value)
 		}
-		frames = append(frames, data.NewFrame(name,
-			data.NewField("time", nil, timeVector),
-			data.NewField("value", nil, values)))
+
+		result := resp.Responses[query.RefID]
+		result.Frames = append(result.Frames, frame)
+		resp.Responses[query.RefID] = result
 	}
-	result := resp.Responses["A"]
-	result.Frames = frames
-	resp.Responses["A"] = result
+
 	return resp, nil
 }
 
+// queryForResponse returns the query a series of a batched request belongs to.
+func queryForResponse(val OpenTsdbResponse, queries []backend.DataQuery) (backend.DataQuery, bool) {
+	if val.Query == nil {
+		// Older OpenTSDB versions don't echo the query, so there is no way to
+		// tell series apart and everything is attributed to the first query.
+		if len(queries) == 0 {
+			return backend.DataQuery{RefID: "A"}, true
+		}
+		return queries[0], true
+	}
+
+	if val.Query.Index < 0 || val.Query.Index >= len(queries) {
+		return backend.DataQuery{}, false
+	}
+
+	return queries[val.Query.Index], true
+}
+
+func dataPointsToFrame(val OpenTsdbResponse) (*data.Frame, error) {
+	timestamps := make([]string, 0, len(val.DataPoints))
+	for timeString := range val.DataPoints {
+		timestamps = append(timestamps, timeString)
+	}
+	sort.Strings(timestamps)
+
+	timeVector := make([]time.Time, 0, len(val.DataPoints))
+	values := make([]float64, 0, len(val.DataPoints))
+	for _, timeString := range timestamps {
+		timestamp, err := strconv.ParseInt(timeString, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid timestamp %q: %w", timeString, err)
+		}
+		timeVector = append(timeVector, time.Unix(timestamp, 0).UTC())
+		values = append(values, val.DataPoints[timeString])
+	}
+
+	// Series of the same metric can only be told apart by their tags.
+	var labels data.Labels
+	if len(val.Tags) > 0 {
+		labels = data.Labels(val.Tags)
+	}
+
+	return data.NewFrame(val.Metric,
+		data.NewField("time", nil, timeVector),
+		data.NewField("value", labels, values)), nil
+}
+
+func annotationsToFrame(val OpenTsdbResponse, includeGlobal bool) *data.Frame {
+	annotations := val.Annotations
+	if includeGlobal {
+		annotations = append(annotations, val.GlobalAnnotations...)
+	}
+
+	times := make([]time.Time, 0, len(annotations))
+	timeEnds := make([]time.Time, 0, len(annotations))
+	texts := make([]string, 0, len(annotations))
+	for _, annotation := range annotations {
+		start := time.Unix(annotation.StartTime, 0).UTC()
+		end := start
+		if annotation.EndTime > 0 {
+			end = time.Unix(annotation.EndTime, 0).UTC()
+		}
+		times = append(times, start)
+		timeEnds = append(timeEnds, end)
+		texts = append(texts, annotation.Description)
+	}
+
+	return data.NewFrame(val.Metric,
+		data.NewField("time", nil, times),
+		data.NewField("timeEnd", nil, timeEnds),
+		data.NewField("text", nil, texts))
+}
+
+func isGlobalAnnotationQuery(query backend.DataQuery) bool {
+	model, err := simplejson.NewJson(query.JSON)
+	if err != nil {
+		return false
+	}
+	return model.Get("isGlobal").MustBool()
+}
+
 func (s *Service) buildMetric(query backend.DataQuery) map[string]interface{} {
 	metric := make(map[string]interface{})
 
@@ -32,10 +32,80 @@ func TestOpenTsdbExecutor(t *testing.T) {
 		assert.Equal(t, testBody, string(body))
 	})
 
+	t.Run("Parse response should map batched series to their queries", func(t *testing.T) {
+		response := `
+		[
+			{
+				"metric": "cpu",
+				"tags": {"host": "a"},
+				"dps": {"1405544147": 2.0, "1405544146": 1.0},
+				"query": {"index": 1}
+			},
+			{
+				"metric": "mem",
+				"dps": {"1405544146": 3.0},
+				"query": {"index": 0}
+			}
+		]`
+
+		resp := http.Response{Body: ioutil.NopCloser(strings.NewReader(response)), StatusCode: 200}
+		result, err := service.parseResponse(&resp, []backend.DataQuery{{RefID: "A"}, {RefID: "B"}})
+		require.NoError(t, err)
+
+		require.Len(t, result.Responses["A"].Frames, 1)
+		require.Equal(t, "mem", result.Responses["A"].Frames[0].Name)
+
+		require.Len(t, result.Responses["B"].Frames, 1)
+		expected := data.NewFrame("cpu",
+			data.NewField("time", nil, []time.Time{
+				time.Date(2014, 7, 16, 20, 55, 46, 0, time.UTC),
+				time.Date(2014, 7, 16, 20, 55, 47, 0, time.UTC),
+			}),
+			data.NewField("value", data.Labels{"host": "a"}, []float64{1, 2}),
+		)
+		if diff := cmp.Diff(expected, result.Responses["B"].Frames[0], data.FrameTestCompareOptions()...); diff != "" {
+			t.Errorf("Result mismatch (-want +got):\n%s", diff)
+		}
+	})
+
+	t.Run("Parse response should return annotations as frames", func(t *testing.T) {
+		response := `
+		[
+			{
+				"metric": "cpu",
+				"dps": {},
+				"annotations": [{"description": "deploy", "startTime": 1405544146}],
+				"globalAnnotations": [{"description": "outage", "startTime": 1405544100, "endTime": 1405544200}],
+				"query": {"index": 0}
+			}
+		]`
+
+		resp := http.Response{Body: ioutil.NopCloser(strings.NewReader(response)), StatusCode: 200}
+		result, err := service.parseResponse(&resp, []backend.DataQuery{
+			{RefID: "A", QueryType: annotationQueryType, JSON: []byte(`{"isGlobal": true}`)},
+		})
+		require.NoError(t, err)
+
+		expected := data.NewFrame("cpu",
+			data.NewField("time", nil, []time.Time{
+				time.Date(2014, 7, 16, 20, 55, 46, 0, time.UTC),
+				time.Date(2014, 7, 16, 20, 55, 0, 0, time.UTC),
+			}),
+			data.NewField("timeEnd", nil, []time.Time{
+				time.Date(2014, 7, 16, 20, 55, 46, 0, time.UTC),
+				time.Date(2014, 7, 16, 20, 56, 40, 0, time.UTC),
+			}),
+			data.NewField("text", nil, []string{"deploy", "outage"}),
+		)
+		if diff := cmp.Diff(expected, result.Responses["A"].Frames[0], data.FrameTestCompareOptions()...); diff != "" {
+			t.Errorf("Result mismatch (-want +got):\n%s", diff)
+		}
+	})
+
 	t.Run("Parse response should handle invalid JSON", func(t *testing.T) {
 		response := `{ invalid }`
 
-		result, err := service.parseResponse(&http.Response{Body: ioutil.NopCloser(strings.NewReader(response))})
+		result, err := service.parseResponse(&http.Response{Body: ioutil.NopCloser(strings.NewReader(response))}, []backend.DataQuery{{RefID: "A"}})
 		require.Nil(t, result)
 		require.Error(t, err)
 	})
@@ -61,7 +131,7 @@ func TestOpenTsdbExecutor(t *testing.T) {
 
 		resp := http.Response{Body: ioutil.NopCloser(strings.NewReader(response))}
 		resp.StatusCode = 200
-		result, err := service.parseResponse(&resp)
+		result, err := service.parseResponse(&resp, []backend.DataQuery{{RefID: "A"}})
 		require.NoError(t, err)
 
 		frame := result.Responses["A"]
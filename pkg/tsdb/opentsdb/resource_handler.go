@@ -0,0 +1,198 @@
+package opentsdb
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend/resource/httpadapter"
+	"golang.org/x/net/context/ctxhttp"
+)
+
+const lookupLimit = "1000"
+
+func (s *Service) newResourceMux() *http.ServeMux {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/suggest", s.handleSuggest)
+	mux.HandleFunc("/tag-keys", s.handleTagKeys)
+	mux.HandleFunc("/tag-values", s.handleTagValues)
+	return mux
+}
+
+// handleSuggest proxies metric, tag key and tag value suggestions to OpenTSDB.
+func (s *Service) handleSuggest(rw http.ResponseWriter, req *http.Request) {
+	params := url.Values{}
+	for _, name := range []string{"type", "q", "max"} {
+		if value := req.URL.Query().Get(name); value != "" {
+			params.Set(name, value)
+		}
+	}
+	if params.Get("type") == "" {
+		s.writeResponse(rw, http.StatusBadRequest, "missing required parameter type")
+		return
+	}
+
+	body, code, err := s.get(req, "api/suggest", params)
+	if err != nil {
+		s.writeResponse(rw, code, fmt.Sprintf("unexpected error %v", err))
+		return
+	}
+
+	var suggestions []string
+	if err := json.Unmarshal(body, &suggestions); err != nil {
+		s.writeResponse(rw, http.StatusInternalServerError, fmt.Sprintf("failed to parse suggest response %v", err))
+		return
+	}
+
+	s.writeJSON(rw, suggestions)
+}
+
+// handleTagKeys returns the tag keys in use for the given metric.
+func (s *Service) handleTagKeys(rw http.ResponseWriter, req *http.Request) {
+	metric := req.URL.Query().Get("metric")
+	if metric == "" {
+		s.writeResponse(rw, http.StatusBadRequest, "missing required parameter metric")
+		return
+	}
+
+	results, code, err := s.lookup(req, metric)
+	if err != nil {
+		s.writeResponse(rw, code, fmt.Sprintf("unexpected error %v", err))
+		return
+	}
+
+	keys := map[string]struct{}{}
+	for _, result := range results {
+		for key := range result.Tags {
+			keys[key] = struct{}{}
+		}
+	}
+
+	s.writeJSON(rw, sortedKeys(keys))
+}
+
+// handleTagValues returns the values of a tag key for the given metric. The
+// optional filter parameter takes comma separated key=value pairs that the
+// series must also match.
+func (s *Service) handleTagValues(rw http.ResponseWriter, req *http.Request) {
+	metric := req.URL.Query().Get("metric")
+	key := req.URL.Query().Get("key")
+	if metric == "" || key == "" {
+		s.writeResponse(rw, http.StatusBadRequest, "missing required parameters metric and key")
+		return
+	}
+
+	tags := []string{key + "=*"}
+	if filter := req.URL.Query().Get("filter"); filter != "" {
+		for _, pair := range strings.Split(filter, ",") {
+			if pair = strings.TrimSpace(pair); pair != "" {
+				tags = append(tags, pair)
+			}
+		}
+	}
+
+	results, code, err := s.lookup(req, metric+"{"+strings.Join(tags, ",")+"}")
+	if err != nil {
+		s.writeResponse(rw, code, fmt.Sprintf("unexpected error %v", err))
+		return
+	}
+
+	values := map[string]struct{}{}
+	for _, result := range results {
+		if value, ok := result.Tags[key]; ok {
+			values[value] = struct{}{}
+		}
+	}
+
+	s.writeJSON(rw, sortedKeys(values))
+}
+
+func (s *Service) lookup(req *http.Request, m string) ([]OpenTsdbLookupResult, int, error) {
+	params := url.Values{}
+	params.Set("m", m)
+	params.Set("limit", lookupLimit)
+
+	body, code, err := s.get(req, "api/search/lookup", params)
+	if err != nil {
+		return nil, code, err
+	}
+
+	var response OpenTsdbLookupResponse
+	if err := json.Unmarshal(body, &response); err != nil {
+		return nil, http.StatusInternalServerError, fmt.Errorf("failed to parse lookup response: %w", err)
+	}
+
+	return response.Results, http.StatusOK, nil
+}
+
+func (s *Service) get(req *http.Request, apiPath string, params url.Values) ([]byte, int, error) {
+	dsInfo, err := s.getDSInfo(httpadapter.PluginConfigFromContext(req.Context()))
+	if err != nil {
+		return nil, http.StatusInternalServerError, err
+	}
+
+	u, err := url.Parse(dsInfo.URL)
+	if err != nil {
+		return nil, http.StatusBadRequest, err
+	}
+	u.Path = path.Join(u.Path, apiPath)
+	u.RawQuery = params.Encode()
+
+	res, err := ctxhttp.Get(req.Context(), dsInfo.HTTPClient, u.String())
+	if err != nil {
+		return nil, http.StatusBadGateway, err
+	}
+	defer func() {
+		if err := res.Body.Close(); err != nil {
+			s.logger.Warn("Failed to close response body", "err", err)
+		}
+	}()
+
+	body, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		return nil, http.StatusBadGateway, err
+	}
+
+	if res.StatusCode/100 != 2 {
+		s.logger.Info("Request failed", "status", res.Status, "body", string(body))
+		return nil, res.StatusCode, fmt.Errorf("request failed, status: %s", res.Status)
+	}
+
+	return body, http.StatusOK, nil
+}
+
+func sortedKeys(set map[string]struct{}) []string {
+	keys := make([]string, 0, len(set))
+	for key := range set {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func (s *Service) writeJSON(rw http.ResponseWriter, v interface{}) {
+	body, err := json.Marshal(v)
+	if err != nil {
+		s.writeResponse(rw, http.StatusInternalServerError, fmt.Sprintf("failed to marshal response %v", err))
+		return
+	}
+	rw.Header().Set("Content-Type", "application/json")
+	s.writeResponseBytes(rw, http.StatusOK, body)
+}
+
+func (s *Service) writeResponseBytes(rw http.ResponseWriter, code int, msg []byte) {
+	rw.WriteHeader(code)
+	_, err := rw.Write(msg)
+	if err != nil {
+		s.logger.Error("Unable to write HTTP response", "error", err)
+	}
+}
+
+func (s *Service) writeResponse(rw http.ResponseWriter, code int, msg string) {
+	s.writeResponseBytes(rw, code, []byte(msg))
+}
@@ -0,0 +1,94 @@
+package opentsdb
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/backend/datasource"
+	"github.com/grafana/grafana-plugin-sdk-go/backend/instancemgmt"
+	"github.com/grafana/grafana-plugin-sdk-go/backend/resource/httpadapter"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResourceHandler(t *testing.T) {
+	var gotPath, gotQuery string
+	srv := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		gotPath = req.URL.Path
+		gotQuery = req.URL.Query().Get("m")
+		switch req.URL.Path {
+		case "/api/suggest":
+			_, _ = rw.Write([]byte(`["cpu.idle","cpu.user"]`))
+		case "/api/search/lookup":
+			_, _ = rw.Write([]byte(`{"results":[
+				{"metric":"cpu","tags":{"host":"b","dc":"eu"}},
+				{"metric":"cpu","tags":{"host":"a","dc":"eu"}}
+			]}`))
+		default:
+			rw.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(srv.Close)
+
+	service := &Service{
+		logger: log.New("test"),
+		im: datasource.NewInstanceManager(func(settings backend.DataSourceInstanceSettings) (instancemgmt.Instance, error) {
+			return &datasourceInfo{HTTPClient: srv.Client(), URL: srv.URL}, nil
+		}),
+	}
+	service.resourceHandler = httpadapter.New(service.newResourceMux())
+
+	call := func(t *testing.T, url string) *backend.CallResourceResponse {
+		t.Helper()
+		sender := &fakeSender{}
+		err := service.CallResource(context.Background(), &backend.CallResourceRequest{
+			PluginContext: backend.PluginContext{DataSourceInstanceSettings: &backend.DataSourceInstanceSettings{}},
+			Method:        http.MethodGet,
+			Path:          strings.SplitN(url, "?", 2)[0],
+			URL:           url,
+		}, sender)
+		require.NoError(t, err)
+		require.NotNil(t, sender.res)
+		return sender.res
+	}
+
+	t.Run("suggest is proxied", func(t *testing.T) {
+		res := call(t, "api/suggest?type=metrics&q=cpu")
+		require.Equal(t, http.StatusOK, res.Status)
+		assert.Equal(t, "/api/suggest", gotPath)
+		assert.JSONEq(t, `["cpu.idle","cpu.user"]`, string(res.Body))
+	})
+
+	t.Run("suggest requires a type", func(t *testing.T) {
+		res := call(t, "api/suggest?q=cpu")
+		require.Equal(t, http.StatusBadRequest, res.Status)
+	})
+
+	t.Run("tag keys are looked up by metric", func(t *testing.T) {
+		res := call(t, "tag-keys?metric=cpu")
+		require.Equal(t, http.StatusOK, res.Status)
+		assert.Equal(t, "cpu", gotQuery)
+		assert.JSONEq(t, `["dc","host"]`, string(res.Body))
+	})
+
+	t.Run("tag values are looked up by metric, key and filter", func(t *testing.T) {
+		res := call(t, "tag-values?metric=cpu&key=host&filter=dc%3Deu")
+		require.Equal(t, http.StatusOK, res.Status)
+		assert.Equal(t, "cpu{host=*,dc=eu}", gotQuery)
+		assert.JSONEq(t, `["a","b"]`, string(res.Body))
+	})
+}
+
+type fakeSender struct {
+	res *backend.CallResourceResponse
+}
+
+func (s *fakeSender) Send(res *backend.CallResourceResponse) error {
+	s.res = res
+	return nil
+}
@@ -1,12 +1,40 @@
 package opentsdb
 
 type OpenTsdbQuery struct {
-	Start   int64                    `json:"start"`
-	End     int64                    `json:"end"`
-	Queries []map[string]interface{} `json:"queries"`
+	Start             int64                    `json:"start"`
+	End               int64                    `json:"end"`
+	Queries           []map[string]interface{} `json:"queries"`
+	ShowQuery         bool                     `json:"showQuery,omitempty"`
+	GlobalAnnotations bool                     `json:"globalAnnotations,omitempty"`
 }
 
 type OpenTsdbResponse struct {
-	Metric     string             `json:"metric"`
-	DataPoints map[string]float64 `json:"dps"`
+	Metric            string               `json:"metric"`
+	Tags              map[string]string    `json:"tags"`
+	DataPoints        map[string]float64   `json:"dps"`
+	Annotations       []OpenTsdbAnnotation `json:"annotations"`
+	GlobalAnnotations []OpenTsdbAnnotation `json:"globalAnnotations"`
+	Query             *OpenTsdbSubQuery    `json:"query"`
+}
+
+type OpenTsdbSubQuery struct {
+	Index int `json:"index"`
+}
+
+type OpenTsdbAnnotation struct {
+	TSUID       string                 `json:"tsuid"`
+	Description string                 `json:"description"`
+	Notes       string                 `json:"notes"`
+	StartTime   int64                  `json:"startTime"`
+	EndTime     int64                  `json:"endTime"`
+	Custom      map[string]interface{} `json:"custom"`
+}
+
+type OpenTsdbLookupResponse struct {
+	Results []OpenTsdbLookupResult `json:"results"`
+}
+
+type OpenTsdbLookupResult struct {
+	Metric string            `json:"metric"`
+	Tags   map[string]string `json:"tags"`
 }
@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana/pkg/tsdb/sqleng"
 	"github.com/stretchr/testify/require"
 )
 
@@ -247,3 +248,24 @@ func TestMacroEngineConcurrency(t *testing.T) {
 
 	wg.Wait()
 }
+
+func TestMacroEngineWithParameters(t *testing.T) {
+	engine := newPostgresMacroEngine(false).(sqleng.SQLParameterizedMacroEngine)
+	from := time.Date(2018, 4, 12, 18, 0, 0, 0, time.UTC)
+	to := from.Add(5 * time.Minute)
+	timeRange := backend.TimeRange{From: from, To: to}
+
+	params := sqleng.NewQueryParameters()
+	sql, err := engine.InterpolateWithParameters(&backend.DataQuery{}, timeRange, "WHERE $__timeFilter(time_column) AND $__timeFrom() < $__timeTo()", params)
+	require.NoError(t, err)
+	sql, bound, err := params.Finalize(sql, sqleng.DollarPlaceholder)
+	require.NoError(t, err)
+
+	require.Equal(t, "WHERE time_column BETWEEN $1 AND $2 AND $3 < $4", sql)
+	require.Equal(t, []sqleng.QueryParameter{
+		{Placeholder: "$1", Value: from},
+		{Placeholder: "$2", Value: to},
+		{Placeholder: "$3", Value: from},
+		{Placeholder: "$4", Value: to},
+	}, bound)
+}
@@ -0,0 +1,214 @@
+package sqleng
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// PlaceholderFormat returns the driver specific placeholder of the n-th (1 based) bound parameter.
+type PlaceholderFormat func(n int) string
+
+// QuestionPlaceholder formats positional placeholders as used by MySQL.
+func QuestionPlaceholder(int) string {
+	return "?"
+}
+
+// DollarPlaceholder formats ordinal placeholders as used by Postgres.
+func DollarPlaceholder(n int) string {
+	return "$" + strconv.Itoa(n)
+}
+
+// AtPPlaceholder formats ordinal placeholders as used by MSSQL.
+func AtPPlaceholder(n int) string {
+	return "@p" + strconv.Itoa(n)
+}
+
+// QueryParameter is a value bound to a placeholder of an executed query.
+type QueryParameter struct {
+	Placeholder string      `json:"placeholder"`
+	Value       interface{} `json:"value"`
+}
+
+// QueryParameters collects the values that are bound to a query instead of being
+// interpolated into the SQL text. Values are referenced by markers while the query
+// is being interpolated, and only turned into driver placeholders by Finalize, so that
+// macros and template variables can be bound in any order.
+//
+// A nil *QueryParameters is valid and binds nothing, so macro engines can use
+// the same code path for both modes.
+type QueryParameters struct {
+	values []interface{}
+}
+
+// NewQueryParameters returns an empty set of query parameters.
+func NewQueryParameters() *QueryParameters {
+	return &QueryParameters{}
+}
+
+// paramMarkerExp matches the markers returned by QueryParameters.Bind.
+var paramMarkerExp = regexp.MustCompile("\x00param([0-9]+)\x00")
+
+// Bind registers value as a bound parameter and returns the marker referencing it.
+// On a nil receiver the literal is returned unchanged.
+func (p *QueryParameters) Bind(value interface{}, literal string) string {
+	if p == nil {
+		return literal
+	}
+	p.values = append(p.values, value)
+	return fmt.Sprintf("\x00param%d\x00", len(p.values)-1)
+}
+
+// Finalize replaces the markers in sql with driver placeholders and returns the
+// resulting query along with the parameters in placeholder order.
+func (p *QueryParameters) Finalize(sql string, format PlaceholderFormat) (string, []QueryParameter, error) {
+	var params []QueryParameter
+	var finalizeErr error
+
+	sql = paramMarkerExp.ReplaceAllStringFunc(sql, func(marker string) string {
+		index, err := strconv.Atoi(paramMarkerExp.FindStringSubmatch(marker)[1])
+		if err != nil || p == nil || index >= len(p.values) {
+			if finalizeErr == nil {
+				finalizeErr = fmt.Errorf("unknown query parameter reference")
+			}
+			return marker
+		}
+		placeholder := format(len(params) + 1)
+		params = append(params, QueryParameter{Placeholder: placeholder, Value: p.values[index]})
+		return placeholder
+	})
+
+	if finalizeErr != nil {
+		return "", nil, finalizeErr
+	}
+
+	return sql, params, nil
+}
+
+// variableExp matches template variable references in the $var, ${var} and [[var]]
+// syntaxes. Format specifiers like ${var:csv} are accepted but ignored since the
+// values are never rendered into the query.
+var variableExp = regexp.MustCompile(`\$(\w+)|\$\{(\w+)(?::\w+)?\}|\[\[(\w+)(?::\w+)?\]\]`)
+
+// quotedVariableExp matches a string literal that only holds a variable reference.
+var quotedVariableExp = regexp.MustCompile(`^'(?:\$\w+|\$\{\w+(?::\w+)?\}|\[\[\w+(?::\w+)?\]\])'$`)
+
+// dollarQuoteExp matches the opening tag of a Postgres dollar quoted string.
+var dollarQuoteExp = regexp.MustCompile(`^\$(?:[A-Za-z_][A-Za-z0-9_]*)?\$`)
+
+// BindVariables replaces references to the given template variables with bound
+// parameters. A multi-value variable is expanded to a comma separated list of
+// placeholders, so it can be used as `col IN ($var)`. A string literal holding
+// only a reference, like '$var', is replaced as a whole since the driver takes
+// care of quoting. References to unknown variables are left untouched.
+//
+// References inside string literals, quoted identifiers, Postgres dollar quoted
+// strings and comments are not replaced, bound variables can only be used where
+// the database accepts a value. The driver name selects the dialect specific
+// quoting rules, MySQL string literals escape quotes with a backslash.
+func BindVariables(sql string, driverName string, variables map[string][]string, params *QueryParameters) (string, error) {
+	backslashEscapes := driverName == "mysql"
+	var bindErr error
+	bind := func(match string) string {
+		var name string
+		for _, group := range variableExp.FindStringSubmatch(match)[1:] {
+			if group != "" {
+				name = group
+				break
+			}
+		}
+
+		values, ok := variables[name]
+		if !ok {
+			return match
+		}
+		if len(values) == 0 {
+			if bindErr == nil {
+				bindErr = fmt.Errorf("template variable %q has no value", name)
+			}
+			return match
+		}
+
+		placeholders := make([]string, 0, len(values))
+		for _, value := range values {
+			placeholders = append(placeholders, params.Bind(value, value))
+		}
+		return strings.Join(placeholders, ", ")
+	}
+
+	var b strings.Builder
+	code := 0
+	for i := 0; i < len(sql); {
+		end := quotedRegionEnd(sql, i, backslashEscapes)
+		if end == i {
+			i++
+			continue
+		}
+
+		b.WriteString(variableExp.ReplaceAllStringFunc(sql[code:i], bind))
+		region := sql[i:end]
+		if quotedVariableExp.MatchString(region) {
+			if bound := bind(region[1 : len(region)-1]); bound != region[1:len(region)-1] {
+				region = bound
+			}
+		}
+		b.WriteString(region)
+		i, code = end, end
+	}
+	b.WriteString(variableExp.ReplaceAllStringFunc(sql[code:], bind))
+
+	if bindErr != nil {
+		return "", bindErr
+	}
+
+	return b.String(), nil
+}
+
+// quotedRegionEnd returns the end of the string literal, quoted identifier,
+// dollar quoted string or comment starting at i, or i when there is none.
+// Unterminated regions extend to the end of the query. With backslashEscapes a
+// backslash escapes the next character in string literals, but not in
+// backtick quoted identifiers.
+func quotedRegionEnd(sql string, i int, backslashEscapes bool) int {
+	closingIndex := func(from int, closing string) int {
+		if n := strings.Index(sql[from:], closing); n >= 0 {
+			return from + n + len(closing)
+		}
+		return len(sql)
+	}
+
+	switch c := sql[i]; {
+	case c == '\'' || c == '"' || c == '`':
+		for j := i + 1; j < len(sql); j++ {
+			if backslashEscapes && c != '`' && sql[j] == '\\' {
+				j++
+				continue
+			}
+			if sql[j] != c {
+				continue
+			}
+			// A doubled quote is an escaped quote.
+			if j+1 < len(sql) && sql[j+1] == c {
+				j++
+				continue
+			}
+			return j + 1
+		}
+		return len(sql)
+	case c == '-' && strings.HasPrefix(sql[i:], "--"):
+		return closingIndex(i, "\n")
+	case c == '/' && strings.HasPrefix(sql[i:], "/*"):
+		return closingIndex(i+2, "*/")
+	case c == '$':
+		tag := dollarQuoteExp.FindString(sql[i:])
+		if tag == "" {
+			return i
+		}
+		// Without a closing tag it's not a dollar quoted string.
+		if n := strings.Index(sql[i+len(tag):], tag); n >= 0 {
+			return i + len(tag) + n + len(tag)
+		}
+	}
+	return i
+}
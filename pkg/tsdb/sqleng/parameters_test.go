@@ -0,0 +1,108 @@
+package sqleng
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestQueryParameters(t *testing.T) {
+	t.Run("nil parameters return the literal", func(t *testing.T) {
+		var params *QueryParameters
+		require.Equal(t, "'a'", params.Bind("a", "'a'"))
+	})
+
+	t.Run("finalize numbers placeholders in query order", func(t *testing.T) {
+		params := NewQueryParameters()
+		second := params.Bind(2, "2")
+		first := params.Bind(1, "1")
+
+		sql, bound, err := params.Finalize("a = "+first+" AND b = "+second, DollarPlaceholder)
+		require.NoError(t, err)
+		require.Equal(t, "a = $1 AND b = $2", sql)
+		require.Equal(t, []QueryParameter{
+			{Placeholder: "$1", Value: 1},
+			{Placeholder: "$2", Value: 2},
+		}, bound)
+	})
+
+	t.Run("finalize rejects unknown markers", func(t *testing.T) {
+		_, _, err := NewQueryParameters().Finalize("a = \x00param3\x00", QuestionPlaceholder)
+		require.Error(t, err)
+	})
+}
+
+func TestBindVariables(t *testing.T) {
+	variables := map[string][]string{
+		"host":   {"web-1"},
+		"region": {"eu", "us'; DROP TABLE users; --"},
+		"empty":  {},
+	}
+
+	bindDriver := func(t *testing.T, driverName string, sql string) (string, []QueryParameter) {
+		t.Helper()
+		params := NewQueryParameters()
+		sql, err := BindVariables(sql, driverName, variables, params)
+		require.NoError(t, err)
+		sql, bound, err := params.Finalize(sql, AtPPlaceholder)
+		require.NoError(t, err)
+		return sql, bound
+	}
+	bind := func(t *testing.T, sql string) (string, []QueryParameter) {
+		t.Helper()
+		return bindDriver(t, "mssql", sql)
+	}
+
+	t.Run("binds all variable syntaxes", func(t *testing.T) {
+		sql, bound := bind(t, "WHERE a = $host AND b = ${host} AND c = [[host]] AND d = ${host:sqlstring}")
+		require.Equal(t, "WHERE a = @p1 AND b = @p2 AND c = @p3 AND d = @p4", sql)
+		require.Len(t, bound, 4)
+	})
+
+	t.Run("drops quotes around references", func(t *testing.T) {
+		sql, bound := bind(t, "WHERE host = '$host'")
+		require.Equal(t, "WHERE host = @p1", sql)
+		require.Equal(t, []QueryParameter{{Placeholder: "@p1", Value: "web-1"}}, bound)
+	})
+
+	t.Run("expands multi-value variables", func(t *testing.T) {
+		sql, bound := bind(t, "WHERE region IN ($region)")
+		require.Equal(t, "WHERE region IN (@p1, @p2)", sql)
+		require.Equal(t, "us'; DROP TABLE users; --", bound[1].Value)
+	})
+
+	t.Run("skips references in string literals and quoted identifiers", func(t *testing.T) {
+		sql, bound := bind(t, `SELECT 'host: $host', "col$host", 'it''s $host' FROM t WHERE a = $host`)
+		require.Equal(t, `SELECT 'host: $host', "col$host", 'it''s $host' FROM t WHERE a = @p1`, sql)
+		require.Len(t, bound, 1)
+	})
+
+	t.Run("skips references in string literals with backslash escapes in mysql", func(t *testing.T) {
+		sql, bound := bindDriver(t, "mysql", `SELECT "say \"$host\"" FROM t WHERE name = 'O\'Brien' AND host IN ($host) AND c = 'a\\' AND d = $host`)
+		require.Equal(t, `SELECT "say \"$host\"" FROM t WHERE name = 'O\'Brien' AND host IN (@p1) AND c = 'a\\' AND d = @p2`, sql)
+		require.Len(t, bound, 2)
+	})
+
+	t.Run("backslashes don't escape quotes in other dialects", func(t *testing.T) {
+		sql, bound := bind(t, `SELECT 'C:\' AS path FROM t WHERE host IN ($host)`)
+		require.Equal(t, `SELECT 'C:\' AS path FROM t WHERE host IN (@p1)`, sql)
+		require.Len(t, bound, 1)
+	})
+
+	t.Run("skips references in dollar quoted strings and comments", func(t *testing.T) {
+		sql, bound := bind(t, "SELECT $$ $host $$, $fn$ '${host}' $fn$ -- $host\nFROM t /* [[host]] */ WHERE a = ${host}")
+		require.Equal(t, "SELECT $$ $host $$, $fn$ '${host}' $fn$ -- $host\nFROM t /* [[host]] */ WHERE a = @p1", sql)
+		require.Len(t, bound, 1)
+	})
+
+	t.Run("leaves unknown variables untouched", func(t *testing.T) {
+		sql, bound := bind(t, "WHERE a = $unknown AND b = '$unknown'")
+		require.Equal(t, "WHERE a = $unknown AND b = '$unknown'", sql)
+		require.Empty(t, bound)
+	})
+
+	t.Run("rejects variables without value", func(t *testing.T) {
+		_, err := BindVariables("WHERE a IN ($empty)", "mssql", variables, NewQueryParameters())
+		require.Error(t, err)
+	})
+}
@@ -0,0 +1,118 @@
+package sqleng
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/grafana/grafana-plugin-sdk-go/data/sqlutil"
+)
+
+// fixedValueSize is the estimated size of a non variable-length value, e.g. a number or a time.
+const fixedValueSize = 8
+
+// frameFromRows scans rows into a new frame, one row at a time. It stops scanning
+// and attaches a warning notice to the frame once either rowLimit rows or
+// bytesLimit bytes have been read. A limit less than or equal to 0 disables it.
+//
+// The context is checked between rows, so a cancelled request stops reading the
+// database cursor instead of draining it.
+func frameFromRows(ctx context.Context, rows *sql.Rows, rowLimit int64, bytesLimit int64,
+	converters ...sqlutil.Converter) (*data.Frame, error) {
+	types, err := rows.ColumnTypes()
+	if err != nil {
+		return nil, err
+	}
+
+	names, err := rows.Columns()
+	if err != nil {
+		return nil, err
+	}
+
+	scanner, converters, err := sqlutil.MakeScanRow(types, names, converters...)
+	if err != nil {
+		return nil, err
+	}
+
+	frame := sqlutil.NewFrame(names, converters...)
+
+	var rowCount, byteCount int64
+	for rows.Next() {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		if rowLimit > 0 && rowCount == rowLimit {
+			frame.AppendNotices(data.Notice{
+				Severity: data.NoticeSeverityWarning,
+				Text:     fmt.Sprintf("Results have been limited to %v because the SQL row limit was reached", rowLimit),
+			})
+			break
+		}
+
+		if bytesLimit > 0 && byteCount >= bytesLimit {
+			frame.AppendNotices(data.Notice{
+				Severity: data.NoticeSeverityWarning,
+				Text: fmt.Sprintf("Results have been limited to %v rows because the SQL result size limit of %v bytes was reached",
+					rowCount, bytesLimit),
+			})
+			break
+		}
+
+		r := scanner.NewScannableRow()
+		if err := rows.Scan(r...); err != nil {
+			return nil, err
+		}
+
+		if err := sqlutil.Append(frame, r, converters...); err != nil {
+			return nil, err
+		}
+
+		rowCount++
+		byteCount += lastRowSize(frame)
+	}
+
+	if err := rows.Err(); err != nil {
+		return frame, err
+	}
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	return frame, nil
+}
+
+// lastRowSize estimates the size in bytes of the last row of frame.
+func lastRowSize(frame *data.Frame) int64 {
+	var size int64
+	for _, field := range frame.Fields {
+		size += valueSize(field.At(field.Len() - 1))
+	}
+	return size
+}
+
+func valueSize(v interface{}) int64 {
+	switch value := v.(type) {
+	case string:
+		return int64(len(value))
+	case *string:
+		if value == nil {
+			return fixedValueSize
+		}
+		return int64(len(*value))
+	case []byte:
+		return int64(len(value))
+	case json.RawMessage:
+		return int64(len(value))
+	case *json.RawMessage:
+		if value == nil {
+			return fixedValueSize
+		}
+		return int64(len(*value))
+	default:
+		return fixedValueSize
+	}
+}
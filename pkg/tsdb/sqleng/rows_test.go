@@ -0,0 +1,89 @@
+package sqleng
+
+import (
+	"context"
+	"database/sql"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/grafana/grafana-plugin-sdk-go/data/sqlutil"
+	_ "github.com/mattn/go-sqlite3"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFrameFromRows(t *testing.T) {
+	db, err := sql.Open("sqlite3", ":memory:")
+	require.NoError(t, err)
+	t.Cleanup(func() { require.NoError(t, db.Close()) })
+
+	_, err = db.Exec("CREATE TABLE test (value TEXT NOT NULL)")
+	require.NoError(t, err)
+	for i := 0; i < 10; i++ {
+		_, err = db.Exec("INSERT INTO test (value) VALUES (?)", strings.Repeat("x", 100))
+		require.NoError(t, err)
+	}
+
+	query := func(t *testing.T, ctx context.Context, rowLimit, bytesLimit int64) (*data.Frame, error) {
+		t.Helper()
+		rows, err := db.QueryContext(context.Background(), "SELECT value FROM test")
+		require.NoError(t, err)
+		t.Cleanup(func() { require.NoError(t, rows.Close()) })
+		// The sqlite driver doesn't report scan types, so the column is read as a string.
+		converter := sqlutil.Converter{
+			Name:          "text",
+			InputScanType: reflect.TypeOf(sql.NullString{}),
+			InputTypeName: "TEXT",
+			FrameConverter: sqlutil.FrameConverter{
+				FieldType: data.FieldTypeNullableString,
+				ConverterFunc: func(in interface{}) (interface{}, error) {
+					v := in.(*sql.NullString)
+					if !v.Valid {
+						return (*string)(nil), nil
+					}
+					return &v.String, nil
+				},
+			},
+		}
+		return frameFromRows(ctx, rows, rowLimit, bytesLimit, converter)
+	}
+
+	t.Run("reads all rows without limits", func(t *testing.T) {
+		frame, err := query(t, context.Background(), 0, 0)
+		require.NoError(t, err)
+		require.Equal(t, 10, frame.Rows())
+		require.Nil(t, frame.Meta)
+	})
+
+	t.Run("truncates at the row limit", func(t *testing.T) {
+		frame, err := query(t, context.Background(), 3, 0)
+		require.NoError(t, err)
+		require.Equal(t, 3, frame.Rows())
+		require.Len(t, frame.Meta.Notices, 1)
+		require.Equal(t, data.NoticeSeverityWarning, frame.Meta.Notices[0].Severity)
+	})
+
+	t.Run("truncates at the bytes limit", func(t *testing.T) {
+		frame, err := query(t, context.Background(), 0, 250)
+		require.NoError(t, err)
+		require.Equal(t, 3, frame.Rows())
+		require.Len(t, frame.Meta.Notices, 1)
+		require.Contains(t, frame.Meta.Notices[0].Text, "250 bytes")
+	})
+
+	t.Run("stops on cancelled context", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		_, err := query(t, ctx, 0, 0)
+		require.ErrorIs(t, err, context.Canceled)
+	})
+}
+
+func TestMinLimit(t *testing.T) {
+	require.Equal(t, int64(10), minLimit(10, 0))
+	require.Equal(t, int64(10), minLimit(0, 10))
+	require.Equal(t, int64(5), minLimit(10, 5))
+	require.Equal(t, int64(5), minLimit(5, 10))
+	require.Equal(t, int64(0), minLimit(0, 0))
+}
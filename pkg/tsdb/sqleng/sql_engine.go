@@ -34,6 +34,13 @@ type SQLMacroEngine interface {
 	Interpolate(query *backend.DataQuery, timeRange backend.TimeRange, sql string) (string, error)
 }
 
+// SQLParameterizedMacroEngine is implemented by macro engines that can bind the
+// values of their macros as query parameters instead of interpolating them.
+type SQLParameterizedMacroEngine interface {
+	SQLMacroEngine
+	InterpolateWithParameters(query *backend.DataQuery, timeRange backend.TimeRange, sql string, params *QueryParameters) (string, error)
+}
+
 // SqlQueryResultTransformer transforms a query result row to RowValues with proper types.
 type SqlQueryResultTransformer interface {
 	// TransformQueryError transforms a query error.
@@ -64,6 +71,13 @@ type JsonData struct {
 	Encrypt             string `json:"encrypt"`
 	Servername          string `json:"servername"`
 	TimeInterval        string `json:"timeInterval"`
+	// ParameterizeVariables binds template variables as query parameters instead of
+	// interpolating them into the query text.
+	ParameterizeVariables bool `json:"parameterizeVariables"`
+	// RowLimit and RowBytesLimit cap the result size of a query for this datasource,
+	// in addition to the server wide limits. 0 means no datasource specific limit.
+	RowLimit      int64 `json:"rowLimit"`
+	RowBytesLimit int64 `json:"rowBytesLimit"`
 }
 
 type DataSourceInfo struct {
@@ -84,6 +98,10 @@ type DataPluginConfiguration struct {
 	TimeColumnNames   []string
 	MetricColumnTypes []string
 	RowLimit          int64
+	RowBytesLimit     int64
+	// PlaceholderFormat is the driver specific placeholder syntax. Queries can
+	// only be parameterized when it's set.
+	PlaceholderFormat PlaceholderFormat
 }
 type DataSourceHandler struct {
 	macroEngine            SQLMacroEngine
@@ -94,6 +112,9 @@ type DataSourceHandler struct {
 	log                    log.Logger
 	dsInfo                 DataSourceInfo
 	rowLimit               int64
+	rowBytesLimit          int64
+	placeholderFormat      PlaceholderFormat
+	driverName             string
 }
 type QueryJson struct {
 	RawSql       string  `json:"rawSql"`
@@ -102,6 +123,10 @@ type QueryJson struct {
 	FillMode     string  `json:"fillMode"`
 	FillValue    float64 `json:"fillValue"`
 	Format       string  `json:"format"`
+	// Variables holds the values of the template variables referenced by RawSql.
+	// They are only used when the datasource parameterizes variables, otherwise
+	// the query is expected to be interpolated already.
+	Variables map[string][]string `json:"variables"`
 }
 
 func (e *DataSourceHandler) transformQueryError(err error) error {
@@ -131,7 +156,10 @@ func NewQueryDataHandler(config DataPluginConfiguration, queryResultTransformer
 		timeColumnNames:        []string{"time"},
 		log:                    log,
 		dsInfo:                 config.DSInfo,
-		rowLimit:               config.RowLimit,
+		rowLimit:               minLimit(config.RowLimit, config.DSInfo.JsonData.RowLimit),
+		rowBytesLimit:          minLimit(config.RowBytesLimit, config.DSInfo.JsonData.RowBytesLimit),
+		placeholderFormat:      config.PlaceholderFormat,
+		driverName:             config.DriverName,
 	}
 
 	if len(config.TimeColumnNames) > 0 {
@@ -232,10 +260,13 @@ func (e *DataSourceHandler) executeQuery(query backend.DataQuery, wg *sync.WaitG
 
 	timeRange := query.TimeRange
 
+	var queryParams []QueryParameter
+
 	errAppendDebug := func(frameErr string, err error, query string) {
 		var emptyFrame data.Frame
 		emptyFrame.SetMeta(&data.FrameMeta{
 			ExecutedQueryString: query,
+			Custom:              executedQueryMeta(queryParams),
 		})
 		queryResult.dataResponse.Error = fmt.Errorf("%s: %w", frameErr, err)
 		queryResult.dataResponse.Frames = data.Frames{&emptyFrame}
@@ -249,18 +280,46 @@ func (e *DataSourceHandler) executeQuery(query backend.DataQuery, wg *sync.WaitG
 		return
 	}
 
+	var params *QueryParameters
+	if e.parameterizeVariables() {
+		params = NewQueryParameters()
+	}
+
 	// data source specific substitutions
-	interpolatedQuery, err = e.macroEngine.Interpolate(&query, timeRange, interpolatedQuery)
+	if paramMacroEngine, ok := e.macroEngine.(SQLParameterizedMacroEngine); ok && params != nil {
+		interpolatedQuery, err = paramMacroEngine.InterpolateWithParameters(&query, timeRange, interpolatedQuery, params)
+	} else {
+		interpolatedQuery, err = e.macroEngine.Interpolate(&query, timeRange, interpolatedQuery)
+	}
 	if err != nil {
 		errAppendDebug("interpolation failed", e.transformQueryError(err), interpolatedQuery)
 		return
 	}
 
+	if params != nil {
+		interpolatedQuery, err = BindVariables(interpolatedQuery, e.driverName, queryJson.Variables, params)
+		if err != nil {
+			errAppendDebug("binding variables failed", err, queryJson.RawSql)
+			return
+		}
+
+		interpolatedQuery, queryParams, err = params.Finalize(interpolatedQuery, e.placeholderFormat)
+		if err != nil {
+			errAppendDebug("binding variables failed", err, queryJson.RawSql)
+			return
+		}
+	}
+
+	args := make([]interface{}, 0, len(queryParams))
+	for _, param := range queryParams {
+		args = append(args, param.Value)
+	}
+
 	session := e.engine.NewSession()
 	defer session.Close()
 	db := session.DB()
 
-	rows, err := db.QueryContext(queryContext, interpolatedQuery)
+	rows, err := db.QueryContext(queryContext, interpolatedQuery, args...)
 	if err != nil {
 		errAppendDebug("db query error", e.transformQueryError(err), interpolatedQuery)
 		return
@@ -279,7 +338,7 @@ func (e *DataSourceHandler) executeQuery(query backend.DataQuery, wg *sync.WaitG
 
 	// Convert row.Rows to dataframe
 	stringConverters := e.queryResultTransformer.GetConverterList()
-	frame, err := sqlutil.FrameFromRows(rows.Rows, e.rowLimit, sqlutil.ToConverters(stringConverters...)...)
+	frame, err := frameFromRows(queryContext, rows.Rows, e.rowLimit, e.rowBytesLimit, sqlutil.ToConverters(stringConverters...)...)
 	if err != nil {
 		errAppendDebug("convert frame from rows error", err, interpolatedQuery)
 		return
@@ -290,6 +349,9 @@ func (e *DataSourceHandler) executeQuery(query backend.DataQuery, wg *sync.WaitG
 	}
 
 	frame.Meta.ExecutedQueryString = interpolatedQuery
+	if custom := executedQueryMeta(queryParams); custom != nil {
+		frame.Meta.Custom = custom
+	}
 
 	// If no rows were returned, no point checking anything else.
 	if frame.Rows() == 0 {
@@ -368,6 +430,31 @@ func (e *DataSourceHandler) executeQuery(query backend.DataQuery, wg *sync.WaitG
 	ch <- queryResult
 }
 
+// minLimit returns the smaller of two limits, where a limit <= 0 means unlimited.
+func minLimit(a, b int64) int64 {
+	if a <= 0 || (b > 0 && b < a) {
+		return b
+	}
+	return a
+}
+
+func (e *DataSourceHandler) parameterizeVariables() bool {
+	return e.dsInfo.JsonData.ParameterizeVariables && e.placeholderFormat != nil
+}
+
+// ExecutedQueryMeta is stored as custom frame metadata of parameterized queries,
+// so the query inspector can show the parameters next to the executed query.
+type ExecutedQueryMeta struct {
+	Parameters []QueryParameter `json:"parameters"`
+}
+
+func executedQueryMeta(params []QueryParameter) interface{} {
+	if len(params) == 0 {
+		return nil
+	}
+	return &ExecutedQueryMeta{Parameters: params}
+}
+
 // Interpolate provides global macros/substitutions for all sql datasources.
 var Interpolate = func(query backend.DataQuery, timeRange backend.TimeRange, timeInterval string, sql string) (string, error) {
 	minInterval, err := intervalv2.GetIntervalFrom(timeInterval, query.Interval.String(), query.Interval.Milliseconds(), time.Second*60)
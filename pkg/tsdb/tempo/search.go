@@ -0,0 +1,160 @@
+package tempo
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+)
+
+const defaultSearchLimit = 20
+
+type SearchResponse struct {
+	Traces []*TraceSearchMetadata `json:"traces"`
+}
+
+type TraceSearchMetadata struct {
+	TraceID           string `json:"traceID"`
+	RootServiceName   string `json:"rootServiceName"`
+	RootTraceName     string `json:"rootTraceName"`
+	StartTimeUnixNano string `json:"startTimeUnixNano"`
+	DurationMs        uint32 `json:"durationMs"`
+}
+
+// search queries the Tempo search API and returns the matching traces as a table frame.
+func (s *Service) search(ctx context.Context, dsInfo *datasourceInfo, query backend.DataQuery, model *QueryModel) (backend.DataResponse, error) {
+	queryRes := backend.DataResponse{}
+
+	params, err := buildSearchParams(query.TimeRange, model)
+	if err != nil {
+		queryRes.Error = err
+		return queryRes, nil
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, dsInfo.URL+"/api/search?"+params.Encode(), nil)
+	if err != nil {
+		return queryRes, err
+	}
+
+	s.tlog.Debug("Tempo search request", "url", req.URL.String())
+
+	resp, err := dsInfo.HTTPClient.Do(req)
+	if err != nil {
+		return queryRes, fmt.Errorf("failed get to tempo: %w", err)
+	}
+
+	defer func() {
+		if err := resp.Body.Close(); err != nil {
+			s.tlog.Warn("failed to close response body", "err", err)
+		}
+	}()
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return queryRes, err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		queryRes.Error = fmt.Errorf("failed to search traces Status: %s Body: %s", resp.Status, string(body))
+		return queryRes, nil
+	}
+
+	var searchResp SearchResponse
+	if err := json.Unmarshal(body, &searchResp); err != nil {
+		return queryRes, fmt.Errorf("failed to parse tempo search response: %w", err)
+	}
+
+	frame, err := SearchResponseToFrame(searchResp)
+	if err != nil {
+		return queryRes, err
+	}
+	queryRes.Frames = data.Frames{frame}
+	return queryRes, nil
+}
+
+// buildSearchParams turns a search query into the parameters of the Tempo search API.
+// The service and span name filters are added to the logfmt encoded tags.
+func buildSearchParams(timeRange backend.TimeRange, model *QueryModel) (url.Values, error) {
+	tags := strings.TrimSpace(model.Search)
+	if model.ServiceName != "" {
+		tags = strings.TrimSpace(tags + fmt.Sprintf(" service.name=%q", model.ServiceName))
+	}
+	if model.SpanName != "" {
+		tags = strings.TrimSpace(tags + fmt.Sprintf(" name=%q", model.SpanName))
+	}
+
+	params := url.Values{}
+	params.Set("tags", tags)
+
+	for name, duration := range map[string]string{"minDuration": model.MinDuration, "maxDuration": model.MaxDuration} {
+		duration = strings.ReplaceAll(duration, " ", "")
+		if duration == "" {
+			continue
+		}
+		if _, err := time.ParseDuration(duration); err != nil {
+			return nil, fmt.Errorf("invalid %s %q", name, duration)
+		}
+		params.Set(name, duration)
+	}
+
+	limit := model.Limit
+	if limit == 0 {
+		limit = defaultSearchLimit
+	}
+	if limit < 0 {
+		return nil, fmt.Errorf("invalid limit %d", limit)
+	}
+	params.Set("limit", strconv.Itoa(limit))
+
+	if !timeRange.From.IsZero() && !timeRange.To.IsZero() {
+		params.Set("start", strconv.FormatInt(timeRange.From.Unix(), 10))
+		params.Set("end", strconv.FormatInt(timeRange.To.Unix(), 10))
+	}
+
+	return params, nil
+}
+
+// SearchResponseToFrame converts the traces found by a search into a table frame,
+// with the most recent traces first.
+func SearchResponseToFrame(resp SearchResponse) (*data.Frame, error) {
+	traces := make([]*TraceSearchMetadata, len(resp.Traces))
+	copy(traces, resp.Traces)
+
+	startTimes := make(map[*TraceSearchMetadata]time.Time, len(traces))
+	for _, trace := range traces {
+		nanos, err := strconv.ParseInt(trace.StartTimeUnixNano, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid start time %q of trace %s", trace.StartTimeUnixNano, trace.TraceID)
+		}
+		startTimes[trace] = time.Unix(0, nanos).UTC()
+	}
+	sort.SliceStable(traces, func(i, j int) bool {
+		return startTimes[traces[i]].After(startTimes[traces[j]])
+	})
+
+	frame := data.NewFrame("Traces",
+		data.NewField("traceID", nil, []string{}).SetConfig(&data.FieldConfig{DisplayNameFromDS: "Trace ID"}),
+		data.NewField("traceName", nil, []string{}).SetConfig(&data.FieldConfig{DisplayNameFromDS: "Trace name"}),
+		data.NewField("startTime", nil, []time.Time{}).SetConfig(&data.FieldConfig{DisplayNameFromDS: "Start time"}),
+		data.NewField("duration", nil, []float64{}).SetConfig(&data.FieldConfig{DisplayNameFromDS: "Duration", Unit: "ms"}),
+	)
+	frame.Meta = &data.FrameMeta{
+		PreferredVisualization: data.VisTypeTable,
+	}
+
+	for _, trace := range traces {
+		traceName := strings.TrimSpace(trace.RootServiceName + " " + trace.RootTraceName)
+		frame.AppendRow(trace.TraceID, traceName, startTimes[trace], float64(trace.DurationMs))
+	}
+
+	return frame, nil
+}
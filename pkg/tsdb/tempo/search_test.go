@@ -0,0 +1,51 @@
+package tempo
+
+import (
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuildSearchParams(t *testing.T) {
+	timeRange := backend.TimeRange{From: time.Unix(1000, 0), To: time.Unix(2000, 0)}
+
+	t.Run("adds service and span name to the tags", func(t *testing.T) {
+		params, err := buildSearchParams(timeRange, &QueryModel{
+			Search:      "http.status_code=500",
+			ServiceName: "frontend",
+			SpanName:    "GET /",
+			MinDuration: "100 ms",
+		})
+		require.NoError(t, err)
+		assert.Equal(t, `http.status_code=500 service.name="frontend" name="GET /"`, params.Get("tags"))
+		assert.Equal(t, "100ms", params.Get("minDuration"))
+		assert.Equal(t, "", params.Get("maxDuration"))
+		assert.Equal(t, "20", params.Get("limit"))
+		assert.Equal(t, "1000", params.Get("start"))
+		assert.Equal(t, "2000", params.Get("end"))
+	})
+
+	t.Run("rejects invalid durations", func(t *testing.T) {
+		_, err := buildSearchParams(timeRange, &QueryModel{MaxDuration: "10 parsecs"})
+		require.Error(t, err)
+	})
+}
+
+func TestSearchResponseToFrame(t *testing.T) {
+	frame, err := SearchResponseToFrame(SearchResponse{Traces: []*TraceSearchMetadata{
+		{TraceID: "a", RootServiceName: "frontend", RootTraceName: "GET /", StartTimeUnixNano: "1000000000", DurationMs: 5},
+		{TraceID: "b", RootServiceName: "backend", StartTimeUnixNano: "2000000000", DurationMs: 10},
+	}})
+	require.NoError(t, err)
+
+	require.Equal(t, 2, frame.Rows())
+	// most recent first
+	assert.Equal(t, "b", frame.Fields[0].At(0))
+	assert.Equal(t, "backend", frame.Fields[1].At(0))
+	assert.Equal(t, time.Unix(2, 0).UTC(), frame.Fields[2].At(0))
+	assert.Equal(t, "frontend GET /", frame.Fields[1].At(1))
+	assert.Equal(t, 5.0, frame.Fields[3].At(1))
+}
@@ -0,0 +1,289 @@
+package tempo
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"math"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+)
+
+// actionDatasourcesQuery is the action required to query the linked Prometheus
+// datasource, as for querying it directly.
+const actionDatasourcesQuery = "datasources:query"
+
+// Metrics written by the Tempo/Grafana Agent service graph processor.
+const (
+	secondsMetric = "traces_service_graph_request_server_seconds_sum"
+	totalsMetric  = "traces_service_graph_request_total"
+	failedMetric  = "traces_service_graph_request_failed_total"
+)
+
+// serviceGraphEdge is the traffic between a client and a server service.
+type serviceGraphEdge struct {
+	Client string
+	Server string
+	Total  float64
+	Failed float64
+	// Seconds is the total time the server spent handling the requests.
+	Seconds float64
+}
+
+// serviceMap queries the service graph metrics from the linked Prometheus datasource
+// and returns them as node graph frames. The linked datasource is resolved in the
+// organization of the plugin context. If the plugin context has a user, the user needs
+// to be allowed to query the linked datasource. Queries without a user, such as those
+// of alerting, expressions and background jobs, are run by Grafana itself.
+func (s *Service) serviceMap(ctx context.Context, pluginCtx backend.PluginContext, dsInfo *datasourceInfo,
+	query backend.DataQuery, model *QueryModel) (backend.DataResponse, error) {
+	queryRes := backend.DataResponse{}
+
+	if dsInfo.ServiceMapDatasourceUID == "" {
+		queryRes.Error = errors.New("no service map datasource configured")
+		return queryRes, nil
+	}
+	if s.dataSources == nil {
+		return queryRes, errors.New("datasource service is not available")
+	}
+
+	if pluginCtx.User != nil && s.accessControl != nil && !s.accessControl.IsDisabled() {
+		evaluator := accesscontrol.EvalPermission(actionDatasourcesQuery,
+			accesscontrol.Scope("datasources", "uid", dsInfo.ServiceMapDatasourceUID))
+		hasAccess, err := s.accessControl.Evaluate(ctx, signedInUser(pluginCtx), evaluator)
+		if err != nil {
+			return queryRes, err
+		}
+		if !hasAccess {
+			queryRes.Error = fmt.Errorf("access to service map datasource %s denied", dsInfo.ServiceMapDatasourceUID)
+			return queryRes, nil
+		}
+	}
+
+	dsQuery := &models.GetDataSourceQuery{Uid: dsInfo.ServiceMapDatasourceUID, OrgId: pluginCtx.OrgID}
+	if err := s.dataSources.GetDataSource(ctx, dsQuery); err != nil {
+		if errors.Is(err, models.ErrDataSourceNotFound) {
+			queryRes.Error = fmt.Errorf("service map datasource %s not found", dsInfo.ServiceMapDatasourceUID)
+			return queryRes, nil
+		}
+		return queryRes, err
+	}
+
+	client, err := s.dataSources.GetHTTPClient(dsQuery.Result, s.httpClientProvider)
+	if err != nil {
+		return queryRes, err
+	}
+
+	edges := map[string]*serviceGraphEdge{}
+	for _, metric := range []string{totalsMetric, failedMetric, secondsMetric} {
+		samples, err := s.queryPrometheus(ctx, client, dsQuery.Result.Url, serviceGraphExpr(metric, model.ServiceMapQuery, query.TimeRange), query.TimeRange.To)
+		if err != nil {
+			queryRes.Error = err
+			return queryRes, nil
+		}
+		for _, sample := range samples {
+			collectServiceGraphSample(edges, metric, sample)
+		}
+	}
+
+	nodes, edgesFrame := ServiceGraphToFrames(edges, query.TimeRange)
+	queryRes.Frames = data.Frames{nodes, edgesFrame}
+	return queryRes, nil
+}
+
+// signedInUser returns the user of a plugin context with their role in the
+// organization, which grants the permissions to query datasources.
+func signedInUser(pluginCtx backend.PluginContext) *models.SignedInUser {
+	return &models.SignedInUser{
+		OrgId:   pluginCtx.OrgID,
+		Login:   pluginCtx.User.Login,
+		Name:    pluginCtx.User.Name,
+		Email:   pluginCtx.User.Email,
+		OrgRole: models.RoleType(pluginCtx.User.Role),
+	}
+}
+
+// serviceGraphExpr builds the PromQL expression returning the increase of a service
+// graph metric over the time range, per client and server.
+func serviceGraphExpr(metric string, selector string, timeRange backend.TimeRange) string {
+	rangeSeconds := int64(math.Max(timeRange.To.Sub(timeRange.From).Seconds(), 1))
+	return fmt.Sprintf("sum by (client, server) (increase(%s%s[%ds]))", metric, selector, rangeSeconds)
+}
+
+type promSample struct {
+	Metric map[string]string `json:"metric"`
+	Value  [2]interface{}    `json:"value"`
+}
+
+type promResponse struct {
+	Status string `json:"status"`
+	Error  string `json:"error"`
+	Data   struct {
+		ResultType string       `json:"resultType"`
+		Result     []promSample `json:"result"`
+	} `json:"data"`
+}
+
+// queryPrometheus runs an instant query against the Prometheus HTTP API.
+func (s *Service) queryPrometheus(ctx context.Context, client *http.Client, promURL string, expr string, ts time.Time) ([]promSample, error) {
+	params := url.Values{}
+	params.Set("query", expr)
+	params.Set("time", strconv.FormatInt(ts.Unix(), 10))
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, promURL+"/api/v1/query?"+params.Encode(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query service graph metrics: %w", err)
+	}
+
+	defer func() {
+		if err := resp.Body.Close(); err != nil {
+			s.tlog.Warn("failed to close response body", "err", err)
+		}
+	}()
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	var promResp promResponse
+	if err := json.Unmarshal(body, &promResp); err != nil {
+		return nil, fmt.Errorf("failed to query service graph metrics Status: %s Body: %s", resp.Status, string(body))
+	}
+	if promResp.Status != "success" {
+		return nil, fmt.Errorf("failed to query service graph metrics: %s", promResp.Error)
+	}
+	if promResp.Data.ResultType != "vector" {
+		return nil, fmt.Errorf("unexpected service graph metrics result type %q", promResp.Data.ResultType)
+	}
+
+	return promResp.Data.Result, nil
+}
+
+func collectServiceGraphSample(edges map[string]*serviceGraphEdge, metric string, sample promSample) {
+	valueString, ok := sample.Value[1].(string)
+	if !ok {
+		return
+	}
+	value, err := strconv.ParseFloat(valueString, 64)
+	if err != nil || math.IsNaN(value) {
+		return
+	}
+
+	client, server := sample.Metric["client"], sample.Metric["server"]
+	id := client + "_" + server
+	edge, ok := edges[id]
+	if !ok {
+		edge = &serviceGraphEdge{Client: client, Server: server}
+		edges[id] = edge
+	}
+
+	switch metric {
+	case totalsMetric:
+		edge.Total += value
+	case failedMetric:
+		edge.Failed += value
+	case secondsMetric:
+		edge.Seconds += value
+	}
+}
+
+// ServiceGraphToFrames converts the service graph edges into node graph frames. The
+// stats of an edge are attributed to its server node, so a node shows the requests
+// it handled rather than the ones it made.
+func ServiceGraphToFrames(edges map[string]*serviceGraphEdge, timeRange backend.TimeRange) (*data.Frame, *data.Frame) {
+	edgeIDs := make([]string, 0, len(edges))
+	for id := range edges {
+		edgeIDs = append(edgeIDs, id)
+	}
+	sort.Strings(edgeIDs)
+
+	nodeStats := map[string]*serviceGraphEdge{}
+	for _, id := range edgeIDs {
+		edge := edges[id]
+		server, ok := nodeStats[edge.Server]
+		if !ok {
+			server = &serviceGraphEdge{}
+			nodeStats[edge.Server] = server
+		}
+		server.Total += edge.Total
+		server.Failed += edge.Failed
+		server.Seconds += edge.Seconds
+
+		if _, ok := nodeStats[edge.Client]; !ok {
+			nodeStats[edge.Client] = &serviceGraphEdge{}
+		}
+	}
+
+	nodeIDs := make([]string, 0, len(nodeStats))
+	for id := range nodeStats {
+		nodeIDs = append(nodeIDs, id)
+	}
+	sort.Strings(nodeIDs)
+
+	nodes := data.NewFrame("Nodes",
+		data.NewField("id", nil, []string{}),
+		data.NewField("title", nil, []string{}).SetConfig(&data.FieldConfig{DisplayName: "Service name"}),
+		data.NewField("mainStat", nil, []float64{}).SetConfig(&data.FieldConfig{DisplayName: "Average response time", Unit: "ms/r"}),
+		data.NewField("secondaryStat", nil, []float64{}).SetConfig(&data.FieldConfig{DisplayName: "Requests per second", Unit: "r/sec"}),
+		data.NewField("arc__success", nil, []float64{}).SetConfig(&data.FieldConfig{DisplayName: "Success", Color: fixedColor("green")}),
+		data.NewField("arc__failed", nil, []float64{}).SetConfig(&data.FieldConfig{DisplayName: "Failed", Color: fixedColor("red")}),
+	)
+	nodes.Meta = &data.FrameMeta{PreferredVisualization: data.VisTypeNodeGraph}
+
+	rangeSeconds := timeRange.To.Sub(timeRange.From).Seconds()
+	for _, id := range nodeIDs {
+		stats := nodeStats[id]
+		// NaN isn't shown in the node graph, which is what we want for root clients
+		// that didn't handle any requests.
+		avgResponseTime, requestsPerSecond, success, failed := math.NaN(), math.NaN(), 1.0, 0.0
+		if stats.Total > 0 {
+			avgResponseTime = stats.Seconds / stats.Total * 1000
+			if rangeSeconds > 0 {
+				requestsPerSecond = math.Round(stats.Total/rangeSeconds*100) / 100
+			}
+			failed = math.Min(stats.Failed, stats.Total) / stats.Total
+			success = 1 - failed
+		}
+		nodes.AppendRow(id, id, avgResponseTime, requestsPerSecond, success, failed)
+	}
+
+	edgesFrame := data.NewFrame("Edges",
+		data.NewField("id", nil, []string{}),
+		data.NewField("source", nil, []string{}),
+		data.NewField("target", nil, []string{}),
+		data.NewField("mainStat", nil, []float64{}).SetConfig(&data.FieldConfig{DisplayName: "Requests", Unit: "r"}),
+		data.NewField("secondaryStat", nil, []float64{}).SetConfig(&data.FieldConfig{DisplayName: "Average response time", Unit: "ms/r"}),
+	)
+	edgesFrame.Meta = &data.FrameMeta{PreferredVisualization: data.VisTypeNodeGraph}
+
+	for _, id := range edgeIDs {
+		edge := edges[id]
+		avgResponseTime := math.NaN()
+		if edge.Total > 0 {
+			avgResponseTime = edge.Seconds / edge.Total * 1000
+		}
+		edgesFrame.AppendRow(id, edge.Client, edge.Server, edge.Total, avgResponseTime)
+	}
+
+	return nodes, edgesFrame
+}
+
+func fixedColor(color string) map[string]interface{} {
+	return map[string]interface{}{"mode": "fixed", "fixedColor": color}
+}
@@ -0,0 +1,145 @@
+package tempo
+
+import (
+	"context"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana/pkg/infra/httpclient"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	accesscontrolmock "github.com/grafana/grafana/pkg/services/accesscontrol/mock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestServiceMap(t *testing.T) {
+	prom, queries := newFakePrometheus(t)
+	dataSources := &fakeDataSources{ds: &models.DataSource{Uid: "prom", OrgId: 2, Url: prom.URL}}
+	service := &Service{
+		tlog:               log.New("tempo-test"),
+		httpClientProvider: httpclient.NewProvider(),
+		dataSources:        dataSources,
+		accessControl:      accesscontrolmock.New(),
+	}
+
+	timeRange := backend.TimeRange{From: time.Unix(0, 0), To: time.Unix(100, 0)}
+	res, err := service.serviceMap(context.Background(), backend.PluginContext{OrgID: 2},
+		&datasourceInfo{ServiceMapDatasourceUID: "prom"}, backend.DataQuery{TimeRange: timeRange},
+		&QueryModel{ServiceMapQuery: `{cluster="eu"}`})
+	require.NoError(t, err)
+	require.NoError(t, res.Error, "queries without a user, like those of alerting, are run by Grafana")
+
+	require.Equal(t, int64(2), dataSources.orgID)
+	require.Len(t, *queries, 3)
+	assert.Equal(t, `sum by (client, server) (increase(traces_service_graph_request_total{cluster="eu"}[100s]))`, (*queries)[0])
+
+	require.Len(t, res.Frames, 2)
+	nodes, edges := res.Frames[0], res.Frames[1]
+
+	require.Equal(t, 2, nodes.Rows())
+	// app only made requests, so it has no stats of its own
+	assert.Equal(t, "app", nodes.Fields[0].At(0))
+	assert.True(t, math.IsNaN(nodes.Fields[2].At(0).(float64)))
+	assert.Equal(t, "db", nodes.Fields[0].At(1))
+	assert.Equal(t, 50.0, nodes.Fields[2].At(1))
+	assert.Equal(t, 1.0, nodes.Fields[3].At(1))
+	assert.Equal(t, 0.9, nodes.Fields[4].At(1))
+	assert.Equal(t, 0.1, nodes.Fields[5].At(1))
+
+	require.Equal(t, 1, edges.Rows())
+	assert.Equal(t, "app_db", edges.Fields[0].At(0))
+	assert.Equal(t, "app", edges.Fields[1].At(0))
+	assert.Equal(t, "db", edges.Fields[2].At(0))
+	assert.Equal(t, 100.0, edges.Fields[3].At(0))
+	assert.Equal(t, 50.0, edges.Fields[4].At(0))
+}
+
+func TestServiceMapWithoutDatasource(t *testing.T) {
+	service := &Service{tlog: log.New("tempo-test")}
+	res, err := service.serviceMap(context.Background(), backend.PluginContext{}, &datasourceInfo{},
+		backend.DataQuery{}, &QueryModel{})
+	require.NoError(t, err)
+	require.Error(t, res.Error)
+}
+
+func TestServiceMapAccess(t *testing.T) {
+	prom, queries := newFakePrometheus(t)
+	pluginCtx := backend.PluginContext{OrgID: 1, User: &backend.User{Login: "viewer", Role: string(models.ROLE_VIEWER)}}
+	newService := func(permissions ...*accesscontrol.Permission) *Service {
+		return &Service{
+			tlog:               log.New("tempo-test"),
+			httpClientProvider: httpclient.NewProvider(),
+			dataSources:        &fakeDataSources{ds: &models.DataSource{Uid: "prom", Url: prom.URL}},
+			accessControl:      accesscontrolmock.New().WithPermissions(permissions),
+		}
+	}
+
+	t.Run("requires the user to be allowed to query the linked datasource", func(t *testing.T) {
+		service := newService(&accesscontrol.Permission{Action: actionDatasourcesQuery, Scope: "datasources:uid:other"})
+		res, err := service.serviceMap(context.Background(), pluginCtx, &datasourceInfo{ServiceMapDatasourceUID: "prom"},
+			backend.DataQuery{}, &QueryModel{})
+		require.NoError(t, err)
+		require.EqualError(t, res.Error, "access to service map datasource prom denied")
+		require.Empty(t, *queries)
+	})
+
+	t.Run("queries the linked datasource for an allowed user", func(t *testing.T) {
+		service := newService(&accesscontrol.Permission{Action: actionDatasourcesQuery, Scope: "datasources:uid:prom"})
+		res, err := service.serviceMap(context.Background(), pluginCtx, &datasourceInfo{ServiceMapDatasourceUID: "prom"},
+			backend.DataQuery{}, &QueryModel{})
+		require.NoError(t, err)
+		require.NoError(t, res.Error)
+		require.Len(t, *queries, 3)
+	})
+}
+
+// newFakePrometheus returns a Prometheus API responding with a single app -> db
+// series, with a value per service graph metric, and the queries it received.
+func newFakePrometheus(t *testing.T) (*httptest.Server, *[]string) {
+	t.Helper()
+	var queries []string
+	prom := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		query := req.URL.Query().Get("query")
+		queries = append(queries, query)
+
+		value := "0"
+		switch {
+		case strings.Contains(query, totalsMetric):
+			value = "100"
+		case strings.Contains(query, failedMetric):
+			value = "10"
+		case strings.Contains(query, secondsMetric):
+			value = "5"
+		}
+		_, _ = rw.Write([]byte(`{"status":"success","data":{"resultType":"vector","result":[
+			{"metric":{"client":"app","server":"db"},"value":[1000,"` + value + `"]}
+		]}}`))
+	}))
+	t.Cleanup(prom.Close)
+	return prom, &queries
+}
+
+type fakeDataSources struct {
+	ds    *models.DataSource
+	orgID int64
+}
+
+func (f *fakeDataSources) GetDataSource(ctx context.Context, query *models.GetDataSourceQuery) error {
+	f.orgID = query.OrgId
+	if f.ds.Uid != query.Uid {
+		return models.ErrDataSourceNotFound
+	}
+	query.Result = f.ds
+	return nil
+}
+
+func (f *fakeDataSources) GetHTTPClient(ds *models.DataSource, provider httpclient.Provider) (*http.Client, error) {
+	return http.DefaultClient, nil
+}
@@ -13,28 +13,73 @@ import (
 	"github.com/grafana/grafana-plugin-sdk-go/data"
 	"github.com/grafana/grafana/pkg/infra/httpclient"
 	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/datasources"
 	"go.opentelemetry.io/collector/model/otlp"
 )
 
+const (
+	nativeSearchQueryType = "nativeSearch"
+	serviceMapQueryType   = "serviceMap"
+)
+
+// dataSourceService gives access to the datasources linked from Tempo, like
+// the Prometheus datasource holding the service graph metrics.
+type dataSourceService interface {
+	GetDataSource(ctx context.Context, query *models.GetDataSourceQuery) error
+	GetHTTPClient(ds *models.DataSource, provider httpclient.Provider) (*http.Client, error)
+}
+
 type Service struct {
-	im   instancemgmt.InstanceManager
-	tlog log.Logger
+	im                 instancemgmt.InstanceManager
+	tlog               log.Logger
+	httpClientProvider httpclient.Provider
+	dataSources        dataSourceService
+	accessControl      accesscontrol.AccessControl
 }
 
-func ProvideService(httpClientProvider httpclient.Provider) *Service {
-	return &Service{
-		tlog: log.New("tsdb.tempo"),
-		im:   datasource.NewInstanceManager(newInstanceSettings(httpClientProvider)),
+func ProvideService(httpClientProvider httpclient.Provider, dataSourcesService *datasources.Service,
+	accessControl accesscontrol.AccessControl) *Service {
+	s := &Service{
+		tlog:               log.New("tsdb.tempo"),
+		im:                 datasource.NewInstanceManager(newInstanceSettings(httpClientProvider)),
+		httpClientProvider: httpClientProvider,
+		accessControl:      accessControl,
 	}
+	if dataSourcesService != nil {
+		s.dataSources = dataSourcesService
+	}
+	return s
 }
 
 type datasourceInfo struct {
 	HTTPClient *http.Client
 	URL        string
+	// ServiceMapDatasourceUID is the uid of the Prometheus datasource holding
+	// the service graph metrics.
+	ServiceMapDatasourceUID string
 }
 
 type QueryModel struct {
 	TraceID string `json:"query"`
+
+	// Search query properties.
+	Search      string `json:"search"`
+	ServiceName string `json:"serviceName"`
+	SpanName    string `json:"spanName"`
+	MinDuration string `json:"minDuration"`
+	MaxDuration string `json:"maxDuration"`
+	Limit       int    `json:"limit"`
+
+	// ServiceMapQuery is a Prometheus label selector applied to the service graph metrics.
+	ServiceMapQuery string `json:"serviceMapQuery"`
+}
+
+type jsonData struct {
+	ServiceMap struct {
+		DatasourceUID string `json:"datasourceUid"`
+	} `json:"serviceMap"`
 }
 
 func newInstanceSettings(httpClientProvider httpclient.Provider) datasource.InstanceFactoryFunc {
@@ -49,9 +94,17 @@ func newInstanceSettings(httpClientProvider httpclient.Provider) datasource.Inst
 			return nil, err
 		}
 
+		var jd jsonData
+		if len(settings.JSONData) > 0 {
+			if err := json.Unmarshal(settings.JSONData, &jd); err != nil {
+				return nil, fmt.Errorf("error reading settings: %w", err)
+			}
+		}
+
 		model := &datasourceInfo{
-			HTTPClient: client,
-			URL:        settings.URL,
+			HTTPClient:              client,
+			URL:                     settings.URL,
+			ServiceMapDatasourceUID: jd.ServiceMap.DatasourceUID,
 		}
 		return model, nil
 	}
@@ -59,28 +112,52 @@ func newInstanceSettings(httpClientProvider httpclient.Provider) datasource.Inst
 
 func (s *Service) QueryData(ctx context.Context, req *backend.QueryDataRequest) (*backend.QueryDataResponse, error) {
 	result := backend.NewQueryDataResponse()
-	queryRes := backend.DataResponse{}
-	refID := req.Queries[0].RefID
-
-	model := &QueryModel{}
-	err := json.Unmarshal(req.Queries[0].JSON, model)
-	if err != nil {
-		return result, err
-	}
 
 	dsInfo, err := s.getDSInfo(req.PluginContext)
 	if err != nil {
 		return nil, err
 	}
 
+	for _, query := range req.Queries {
+		model := &QueryModel{}
+		err := json.Unmarshal(query.JSON, model)
+		if err != nil {
+			return result, err
+		}
+
+		var queryRes backend.DataResponse
+		switch query.QueryType {
+		case nativeSearchQueryType:
+			queryRes, err = s.search(ctx, dsInfo, query, model)
+		case serviceMapQueryType:
+			queryRes, err = s.serviceMap(ctx, req.PluginContext, dsInfo, query, model)
+		default:
+			queryRes, err = s.trace(ctx, dsInfo, query, model)
+		}
+		if err != nil {
+			return &backend.QueryDataResponse{}, err
+		}
+
+		for _, frame := range queryRes.Frames {
+			frame.RefID = query.RefID
+		}
+		result.Responses[query.RefID] = queryRes
+	}
+
+	return result, nil
+}
+
+func (s *Service) trace(ctx context.Context, dsInfo *datasourceInfo, query backend.DataQuery, model *QueryModel) (backend.DataResponse, error) {
+	queryRes := backend.DataResponse{}
+
 	request, err := s.createRequest(ctx, dsInfo, model.TraceID)
 	if err != nil {
-		return result, err
+		return queryRes, err
 	}
 
 	resp, err := dsInfo.HTTPClient.Do(request)
 	if err != nil {
-		return result, fmt.Errorf("failed get to tempo: %w", err)
+		return queryRes, fmt.Errorf("failed get to tempo: %w", err)
 	}
 
 	defer func() {
@@ -91,30 +168,28 @@ func (s *Service) QueryData(ctx context.Context, req *backend.QueryDataRequest)
 
 	body, err := ioutil.ReadAll(resp.Body)
 	if err != nil {
-		return &backend.QueryDataResponse{}, err
+		return queryRes, err
 	}
 
 	if resp.StatusCode != http.StatusOK {
 		queryRes.Error = fmt.Errorf("failed to get trace with id: %s Status: %s Body: %s", model.TraceID, resp.Status, string(body))
-		result.Responses[refID] = queryRes
-		return result, nil
+		return queryRes, nil
 	}
 
 	otTrace, err := otlp.NewProtobufTracesUnmarshaler().UnmarshalTraces(body)
 
 	if err != nil {
-		return &backend.QueryDataResponse{}, fmt.Errorf("failed to convert tempo response to Otlp: %w", err)
+		return queryRes, fmt.Errorf("failed to convert tempo response to Otlp: %w", err)
 	}
 
 	frame, err := TraceToFrame(otTrace)
 	if err != nil {
-		return &backend.QueryDataResponse{}, fmt.Errorf("failed to transform trace %v to data frame: %w", model.TraceID, err)
+		return queryRes, fmt.Errorf("failed to transform trace %v to data frame: %w", model.TraceID, err)
 	}
-	frame.RefID = refID
-	frames := []*data.Frame{frame}
-	queryRes.Frames = frames
-	result.Responses[refID] = queryRes
-	return result, nil
+	if frame != nil {
+		queryRes.Frames = []*data.Frame{frame}
+	}
+	return queryRes, nil
 }
 
 func (s *Service) createRequest(ctx context.Context, dsInfo *datasourceInfo, traceID string) (*http.Request, error) {
@@ -4,7 +4,7 @@ import { GrafanaTheme } from '@grafana/data';
 import { css } from '@emotion/css';
 import { DashboardModel } from 'app/features/dashboard/state';
 import { useDashboardSave } from './useDashboardSave';
-import { SaveDashboardModalProps } from './types';
+import { MergeConflict, MergeResolution, SaveDashboardModalProps } from './types';
 import { SaveDashboardAsButton } from './SaveDashboardButton';
 
 interface SaveDashboardErrorProxyProps {
@@ -22,7 +22,8 @@ export const SaveDashboardErrorProxy: React.FC<SaveDashboardErrorProxyProps> = (
   error,
   onDismiss,
 }) => {
-  const { onDashboardSave } = useDashboardSave(dashboard);
+  const { state, onDashboardSave } = useDashboardSave(dashboard);
+  const mergeError: any = state.error;
 
   useEffect(() => {
     if (error.data && isHandledError(error.data.status)) {
@@ -30,15 +31,29 @@ export const SaveDashboardErrorProxy: React.FC<SaveDashboardErrorProxyProps> = (
     }
   }, [error]);
 
+  useEffect(() => {
+    if (mergeError?.data?.status === 'conflict') {
+      mergeError.isHandled = true;
+    }
+  }, [mergeError]);
+
+  const onMerge = async (resolutions?: Record<string, MergeResolution>) => {
+    const result = await onDashboardSave(dashboardSaveModel, { merge: true, resolutions }, dashboard);
+    if (result?.status === 'success') {
+      onDismiss();
+    }
+  };
+
   return (
     <>
-      {error.data && error.data.status === 'version-mismatch' && (
+      {error.data && error.data.status === 'version-mismatch' && mergeError?.data?.status !== 'conflict' && (
         <ConfirmModal
           isOpen={true}
           title="Conflict"
           body={
             <div>
-              Someone else has updated this dashboard <br /> <small>Would you still like to save this dashboard?</small>
+              Someone else has updated this dashboard <br />
+              <small>Would you like to merge your changes with theirs or overwrite them?</small>
             </div>
           }
           confirmText="Save and overwrite"
@@ -46,9 +61,14 @@ export const SaveDashboardErrorProxy: React.FC<SaveDashboardErrorProxyProps> = (
             await onDashboardSave(dashboardSaveModel, { overwrite: true }, dashboard);
             onDismiss();
           }}
+          alternativeText="Merge changes"
+          onAlternative={() => onMerge()}
           onDismiss={onDismiss}
         />
       )}
+      {mergeError?.data?.status === 'conflict' && (
+        <MergeConflictsModal conflicts={mergeError.data.conflicts} onResolve={onMerge} onDismiss={onDismiss} />
+      )}
       {error.data && error.data.status === 'name-exists' && (
         <ConfirmModal
           isOpen={true}
@@ -107,6 +127,48 @@ const ConfirmPluginDashboardSaveModal: React.FC<SaveDashboardModalProps> = ({ on
   );
 };
 
+interface MergeConflictsModalProps {
+  conflicts: MergeConflict[];
+  onResolve: (resolutions: Record<string, MergeResolution>) => void;
+  onDismiss: () => void;
+}
+
+const MergeConflictsModal: React.FC<MergeConflictsModalProps> = ({ conflicts, onResolve, onDismiss }) => {
+  const resolveAll = (resolution: MergeResolution) => {
+    onResolve(Object.fromEntries(conflicts.map((c) => [c.path, resolution])));
+  };
+
+  return (
+    <Modal title="Conflicting changes" icon="exclamation-triangle" isOpen={true} onDismiss={onDismiss}>
+      <p>Your changes and the changes saved by someone else conflict in:</p>
+      <ul>
+        {conflicts.map((c) => (
+          <li key={c.path}>{conflictName(c)}</li>
+        ))}
+      </ul>
+      <Modal.ButtonRow>
+        <Button variant="secondary" onClick={onDismiss} fill="outline">
+          Cancel
+        </Button>
+        <Button variant="secondary" onClick={() => resolveAll('theirs')}>
+          Keep their changes
+        </Button>
+        <Button variant="destructive" onClick={() => resolveAll('mine')}>
+          Keep my changes
+        </Button>
+      </Modal.ButtonRow>
+    </Modal>
+  );
+};
+
+const conflictName = (conflict: MergeConflict) => {
+  if (!conflict.panelId) {
+    return conflict.path;
+  }
+  const panel = conflict.mine ?? conflict.theirs ?? conflict.base;
+  return `Panel ${panel?.title || conflict.panelId}`;
+};
+
 const isHandledError = (errorStatus: string) => {
   switch (errorStatus) {
     case 'version-mismatch':
@@ -5,6 +5,20 @@ export interface SaveDashboardOptions extends CloneOptions {
   overwrite?: boolean;
   message?: string;
   makeEditable?: boolean;
+  /** merge with the changes saved by someone else since the dashboard version */
+  merge?: boolean;
+  /** resolutions of merge conflicts by their path */
+  resolutions?: Record<string, MergeResolution>;
+}
+
+export type MergeResolution = 'mine' | 'theirs';
+
+export interface MergeConflict {
+  path: string;
+  panelId?: number;
+  base: any;
+  theirs: any;
+  mine: any;
 }
 
 export interface SaveDashboardFormProps {
@@ -4,7 +4,10 @@ import { AppEvents, locationUtil } from '@grafana/data';
 import { SaveDashboardOptions } from './types';
 import appEvents from 'app/core/app_events';
 import { DashboardModel } from 'app/features/dashboard/state';
-import { saveDashboard as saveDashboardApiCall } from 'app/features/manage-dashboards/state/actions';
+import {
+  mergeDashboard as mergeDashboardApiCall,
+  saveDashboard as saveDashboardApiCall,
+} from 'app/features/manage-dashboards/state/actions';
 import { locationService, reportInteraction } from '@grafana/runtime';
 import { DashboardSavedEvent } from 'app/types/events';
 
@@ -14,6 +17,15 @@ const saveDashboard = (saveModel: any, options: SaveDashboardOptions, dashboard:
     folderId = dashboard.meta.folderId ?? saveModel.folderId;
   }
 
+  if (options.merge) {
+    return mergeDashboardApiCall(dashboard.id, {
+      baseVersion: dashboard.version,
+      dashboard: saveModel,
+      message: options.message,
+      resolutions: options.resolutions,
+    });
+  }
+
   return saveDashboardApiCall({ ...options, folderId, dashboard: saveModel });
 };
 
@@ -16,6 +16,15 @@ export interface RevisionsModel {
   created: Date;
   createdBy: string;
   message: string;
+  changes?: RevisionChange[] | null;
+}
+
+export interface RevisionChange {
+  kind: 'added' | 'removed' | 'changed';
+  element: string;
+  name?: string;
+  field?: string;
+  summary: string;
 }
 
 export interface DiffTarget {
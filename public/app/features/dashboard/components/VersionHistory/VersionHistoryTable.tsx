@@ -36,7 +36,20 @@ export const VersionHistoryTable: React.FC<VersionsTableProps> = ({ versions, on
           <td>{version.version}</td>
           <td>{version.createdDateString}</td>
           <td>{version.createdBy}</td>
-          <td>{version.message}</td>
+          <td>
+            {version.message}
+            {version.changes && version.changes.length > 0 && (
+              <ul
+                className={css`
+                  list-style: none;
+                `}
+              >
+                {version.changes.map((change, i) => (
+                  <li key={i}>{change.summary}</li>
+                ))}
+              </ul>
+            )}
+          </td>
           <td className="text-right">
             {idx === 0 ? (
               <Tag name="Latest" colorIndex={17} />
@@ -23,6 +23,7 @@ import { getNavModel } from 'app/core/selectors/navModel';
 import { StoreState, AccessControlAction } from 'app/types/';
 import { DataSourceSettings, urlUtil } from '@grafana/data';
 import { Alert, Button } from '@grafana/ui';
+import { getDataSourceUsage, usageSummary } from '../state/usage';
 import { getDataSourceLoadingNav, buildNavModel, getDataSourceNav } from '../state/navModel';
 import { PluginStateInfo } from 'app/features/plugins/components/PluginStateInfo';
 import { dataSourceLoaded, setDataSourceName, setIsDefault } from '../state/reducers';
@@ -107,11 +108,15 @@ export class DataSourceSettingsPage extends PureComponent<Props> {
     this.testDataSource();
   };
 
-  onDelete = () => {
+  onDelete = async () => {
+    const { dataSource } = this.props;
+    const usage = await getDataSourceUsage(dataSource.uid);
+
     appEvents.publish(
       new ShowConfirmModalEvent({
         title: 'Delete',
-        text: `Are you sure you want to delete the "${this.props.dataSource.name}" data source?`,
+        text: `Are you sure you want to delete the "${dataSource.name}" data source?`,
+        text2: usage ? `It is used by ${usageSummary(usage)}, which will stop working.` : undefined,
         yesText: 'Delete',
         icon: 'trash-alt',
         onConfirm: () => {
@@ -242,7 +242,8 @@ export function deleteDataSource(): ThunkResult<void> {
   return async (dispatch, getStore) => {
     const dataSource = getStore().dataSources.dataSource;
 
-    await getBackendSrv().delete(`/api/datasources/${dataSource.id}`);
+    // Usage of the data source is confirmed before deleting it.
+    await getBackendSrv().delete(`/api/datasources/${dataSource.id}?force=true`);
     await getDatasourceSrv().reload();
 
     locationService.push('/datasources');
@@ -0,0 +1,15 @@
+import { DataSourceUsage, usageSummary } from './usage';
+
+const item = { uid: 'uid', title: 'title', name: 'name', url: '/url' };
+
+describe('usageSummary', () => {
+  it('should describe a single kind of dependents', () => {
+    const usage: DataSourceUsage = { dashboards: [item], libraryPanels: [], alertRules: [] };
+    expect(usageSummary(usage)).toBe('1 dashboard');
+  });
+
+  it('should describe all kinds of dependents', () => {
+    const usage: DataSourceUsage = { dashboards: [item, item], libraryPanels: [item], alertRules: [item, item] };
+    expect(usageSummary(usage)).toBe('2 dashboards, 1 library panel and 2 alert rules');
+  });
+});
@@ -0,0 +1,43 @@
+import { getBackendSrv } from 'app/core/services/backend_srv';
+
+export interface DataSourceUsage {
+  dashboards: Array<{ uid: string; title: string; url: string }>;
+  libraryPanels: Array<{ uid: string; name: string }>;
+  alertRules: Array<{ uid: string; title: string; url: string }>;
+}
+
+/**
+ * Returns the dashboards, library panels and alert rules using a data source,
+ * or undefined if it isn't used or its usage can't be read.
+ */
+export async function getDataSourceUsage(uid: string): Promise<DataSourceUsage | undefined> {
+  try {
+    const usage: DataSourceUsage = await getBackendSrv().get(`/api/datasources/uid/${uid}/usage`);
+    if (usage.dashboards.length + usage.libraryPanels.length + usage.alertRules.length === 0) {
+      return undefined;
+    }
+    return usage;
+  } catch (err) {
+    return undefined;
+  }
+}
+
+export function usageSummary(usage: DataSourceUsage): string {
+  const parts = [
+    count(usage.dashboards.length, 'dashboard'),
+    count(usage.libraryPanels.length, 'library panel'),
+    count(usage.alertRules.length, 'alert rule'),
+  ].filter((part) => part !== '');
+
+  if (parts.length === 1) {
+    return parts[0];
+  }
+  return `${parts.slice(0, -1).join(', ')} and ${parts[parts.length - 1]}`;
+}
+
+function count(n: number, noun: string): string {
+  if (n === 0) {
+    return '';
+  }
+  return n === 1 ? `1 ${noun}` : `${n} ${noun}s`;
+}
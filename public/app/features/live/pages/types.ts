@@ -24,6 +24,7 @@ export interface RuleSettings {
 export interface Rule {
   pattern: string;
   settings: RuleSettings;
+  version?: number;
 }
 
 export interface Pipeline {
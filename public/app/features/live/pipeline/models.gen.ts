@@ -46,6 +46,24 @@ export interface MultipleOutputterConfig {
   outputs: FrameOutputterConfig[];
 }
 export interface ManagedStreamOutputConfig {}
+export interface AlertOutputConfig {
+  fieldName?: string;
+  alertName?: string;
+  labels?: { [key: string]: string };
+  annotations?: { [key: string]: string };
+  resolveTimeoutSeconds?: number;
+}
+export interface KafkaOutputConfig {
+  uid: string;
+  topic?: string;
+  key?: string;
+}
+export interface WebhookOutputConfig {
+  uid: string;
+  batchSize?: number;
+  flushIntervalMilliseconds?: number;
+  maxRetries?: number;
+}
 export interface FrameOutputterConfig {
   type: Omit<keyof FrameOutputterConfig, 'type'>;
   managedStream?: ManagedStreamOutputConfig;
@@ -56,6 +74,9 @@ export interface FrameOutputterConfig {
   remoteWrite?: RemoteWriteOutputConfig;
   loki?: LokiOutputConfig;
   changeLog?: ChangeLogOutputConfig;
+  alert?: AlertOutputConfig;
+  kafka?: KafkaOutputConfig;
+  webhook?: WebhookOutputConfig;
 }
 export interface MultipleFrameProcessorConfig {
   processors: FrameProcessorConfig[];
@@ -66,11 +87,44 @@ export interface KeepFieldsFrameProcessorConfig {
 export interface DropFieldsFrameProcessorConfig {
   fieldNames: string[];
 }
+export interface CalculatedFieldFrameProcessorConfig {
+  fieldName: string;
+  type: number;
+  expression: string;
+  config?: FieldConfig;
+}
+export interface DerivativeFrameProcessorConfig {
+  fieldNames: string[];
+  counter?: boolean;
+}
+export interface AggregateField {
+  fieldName: string;
+  reducers: string[];
+}
+export interface AggregateFrameProcessorConfig {
+  intervalMilliseconds: number;
+  fields: AggregateField[];
+}
 export interface FrameProcessorConfig {
   type: Omit<keyof FrameProcessorConfig, 'type'>;
   dropFields?: DropFieldsFrameProcessorConfig;
   keepFields?: KeepFieldsFrameProcessorConfig;
   multiple?: MultipleFrameProcessorConfig;
+  aggregate?: AggregateFrameProcessorConfig;
+  derivative?: DerivativeFrameProcessorConfig;
+  calculatedField?: CalculatedFieldFrameProcessorConfig;
+}
+export interface ProtobufConverterConfig {
+  descriptorSet: string;
+  messageType: string;
+  fieldTips?: { [key: string]: Field };
+}
+export interface PrometheusRemoteWriteConverterConfig {
+  frameFormat?: string;
+}
+export interface PrometheusTextConverterConfig {
+  openMetrics?: boolean;
+  frameFormat?: string;
 }
 export interface JsonFrameConverterConfig {}
 export interface AutoInfluxConverterConfig {
@@ -99,6 +153,9 @@ export interface ConverterConfig {
   jsonExact?: ExactJsonConverterConfig;
   influxAuto?: AutoInfluxConverterConfig;
   jsonFrame?: JsonFrameConverterConfig;
+  prometheusText?: PrometheusTextConverterConfig;
+  prometheusRemoteWrite?: PrometheusRemoteWriteConverterConfig;
+  protobuf?: ProtobufConverterConfig;
 }
 export interface LokiOutputConfig {
   uid: string;
@@ -110,6 +167,8 @@ export interface DataOutputterConfig {
   type: Omit<keyof DataOutputterConfig, 'type'>;
   redirect?: RedirectDataOutputConfig;
   loki?: LokiOutputConfig;
+  kafka?: KafkaOutputConfig;
+  webhook?: WebhookOutputConfig;
 }
 export interface MultipleSubscriberConfig {
   subscribers: SubscriberConfig[];
@@ -118,6 +177,11 @@ export interface SubscriberConfig {
   type: Omit<keyof SubscriberConfig, 'type'>;
   multiple?: MultipleSubscriberConfig;
 }
+export interface BackfillConfig {
+  datasourceUid: string;
+  query: { [key: string]: any };
+  maxDataPoints?: number;
+}
 export interface ChannelRuleSettings {
   auth?: ChannelAuthConfig;
   subscribers?: SubscriberConfig[];
@@ -125,8 +189,14 @@ export interface ChannelRuleSettings {
   converter?: ConverterConfig;
   frameProcessors?: FrameProcessorConfig[];
   frameOutputs?: FrameOutputterConfig[];
+  backfill?: BackfillConfig;
 }
 export interface ChannelRule {
   pattern: string;
   settings: ChannelRuleSettings;
+  version?: number;
+  created?: Date;
+  updated?: Date;
+  createdBy?: number;
+  updatedBy?: number;
 }
@@ -273,6 +273,24 @@ export function saveDashboard(options: SaveDashboardOptions) {
   });
 }
 
+export interface MergeDashboardOptions {
+  baseVersion: number;
+  dashboard: any;
+  message?: string;
+  resolutions?: Record<string, 'mine' | 'theirs'>;
+}
+
+export function mergeDashboard(dashboardId: number, options: MergeDashboardOptions) {
+  dashboardWatcher.ignoreNextSave();
+
+  return getBackendSrv().post(`/api/dashboards/id/${dashboardId}/merge`, {
+    baseVersion: options.baseVersion,
+    dashboard: options.dashboard,
+    message: options.message ?? '',
+    resolutions: options.resolutions,
+  });
+}
+
 function deleteFolder(uid: string, showSuccessAlert: boolean) {
   return getBackendSrv().request({
     method: 'DELETE',
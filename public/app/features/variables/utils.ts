@@ -24,6 +24,46 @@ export const variableRegexExec = (variableString: string) => {
   return variableRegex.exec(variableString);
 };
 
+/**
+ * Returns the values of the template variables referenced in target, so they can be sent to a data source
+ * that binds them as query parameters instead of having them interpolated into the query.
+ * Multi-value variables and the All value return all selected values, unknown variables are left out.
+ */
+export const getVariableValues = (
+  target: string,
+  scopedVars?: ScopedVars,
+  templateSrv = getTemplateSrv()
+): Record<string, string[]> => {
+  const values: Record<string, string[]> = {};
+  if (!target) {
+    return values;
+  }
+
+  const references = target.match(variableRegex) ?? [];
+  for (const reference of references) {
+    const [, var1, var2, , var3] = variableRegexExec(reference) ?? [];
+    const name = var1 || var2 || var3;
+    if (!name || name in values) {
+      continue;
+    }
+
+    const expression = '${' + name + '}';
+    let value: string[] | undefined;
+    const replaced = templateSrv.replace(expression, scopedVars, (v: string | string[] | number) => {
+      value = isArray(v) ? v.map(String) : [String(v)];
+      return '';
+    });
+    if (value === undefined && replaced !== expression) {
+      // custom All values are returned without formatting
+      value = [replaced];
+    }
+    if (value !== undefined) {
+      values[name] = value;
+    }
+  }
+  return values;
+};
+
 export const SEARCH_FILTER_VARIABLE = '__searchFilter';
 
 export const containsSearchFilter = (query: string | unknown): boolean =>
@@ -9,12 +9,14 @@ import { getTemplateSrv, TemplateSrv } from 'app/features/templating/template_sr
 import { MssqlOptions, MssqlQuery, MssqlQueryForInterpolation } from './types';
 import { getTimeSrv, TimeSrv } from 'app/features/dashboard/services/TimeSrv';
 import { toTestingStatus } from '@grafana/runtime/src/utils/queryResponse';
+import { getVariableValues } from 'app/features/variables/utils';
 
 export class MssqlDatasource extends DataSourceWithBackend<MssqlQuery, MssqlOptions> {
   id: any;
   name: any;
   responseParser: ResponseParser;
   interval: string;
+  parameterizeVariables: boolean;
 
   constructor(
     instanceSettings: DataSourceInstanceSettings<MssqlOptions>,
@@ -27,6 +29,7 @@ export class MssqlDatasource extends DataSourceWithBackend<MssqlQuery, MssqlOpti
     this.responseParser = new ResponseParser();
     const settingsData = instanceSettings.jsonData || ({} as MssqlOptions);
     this.interval = settingsData.timeInterval || '1m';
+    this.parameterizeVariables = settingsData.parameterizeVariables ?? false;
   }
 
   interpolateVariable(value: any, variable: any) {
@@ -72,6 +75,16 @@ export class MssqlDatasource extends DataSourceWithBackend<MssqlQuery, MssqlOpti
   }
 
   applyTemplateVariables(target: MssqlQuery, scopedVars: ScopedVars): Record<string, any> {
+    if (this.parameterizeVariables) {
+      // variables are bound as query parameters by the backend
+      return {
+        refId: target.refId,
+        datasource: this.getRef(),
+        rawSql: target.rawSql,
+        format: target.format,
+        variables: getVariableValues(target.rawSql, scopedVars, this.templateSrv),
+      };
+    }
     return {
       refId: target.refId,
       datasource: this.getRef(),
@@ -361,4 +361,33 @@ describe('MSSQLDatasource', () => {
       expect(ctx.ds.targetContainsTemplate(query)).toBeFalsy();
     });
   });
+
+  describe('When applying template variables with bound variables', () => {
+    beforeEach(() => {
+      ctx.ds = new MssqlDatasource(
+        { ...ctx.instanceSettings, jsonData: { parameterizeVariables: true } },
+        templateSrv,
+        ctx.timeSrv
+      );
+      templateSrv.init([
+        { type: 'query', name: 'host', current: { value: ['a', "b'c"] } },
+        { type: 'query', name: 'summarize', current: { value: '1m' } },
+      ]);
+    });
+
+    it('should send the query uninterpolated with the variable values', () => {
+      const rawSql = "SELECT * FROM metric WHERE host IN ($host) AND interval = '${summarize}' AND $__timeFilter(time)";
+      const query = ctx.ds.applyTemplateVariables({ refId: 'A', rawSql, format: 'table' }, {});
+
+      expect(query.rawSql).toEqual(rawSql);
+      expect(query.variables).toEqual({ host: ['a', "b'c"], summarize: ['1m'] });
+    });
+
+    it('should use scoped variables', () => {
+      const scopedVars = { host: { value: 'x', text: 'x' } };
+      const query = ctx.ds.applyTemplateVariables({ refId: 'A', rawSql: 'SELECT $host' }, scopedVars);
+
+      expect(query.variables).toEqual({ host: ['x'] });
+    });
+  });
 });
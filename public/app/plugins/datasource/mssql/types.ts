@@ -18,4 +18,7 @@ export interface MssqlQuery extends DataQuery {
 
 export interface MssqlOptions extends DataSourceJsonData {
   timeInterval: string;
+  parameterizeVariables?: boolean;
+  rowLimit?: number;
+  rowBytesLimit?: number;
 }
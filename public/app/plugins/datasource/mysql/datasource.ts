@@ -8,7 +8,7 @@ import MySQLQueryModel from 'app/plugins/datasource/mysql/mysql_query_model';
 import ResponseParser from './response_parser';
 import { MySQLOptions, MySQLQuery, MysqlQueryForInterpolation } from './types';
 import { getTemplateSrv, TemplateSrv } from 'app/features/templating/template_srv';
-import { getSearchFilterScopedVar } from '../../../features/variables/utils';
+import { getSearchFilterScopedVar, getVariableValues } from '../../../features/variables/utils';
 import { getTimeSrv, TimeSrv } from 'app/features/dashboard/services/TimeSrv';
 import { toTestingStatus } from '@grafana/runtime/src/utils/queryResponse';
 
@@ -18,6 +18,7 @@ export class MysqlDatasource extends DataSourceWithBackend<MySQLQuery, MySQLOpti
   responseParser: ResponseParser;
   queryModel: MySQLQueryModel;
   interval: string;
+  parameterizeVariables: boolean;
 
   constructor(
     instanceSettings: DataSourceInstanceSettings<MySQLOptions>,
@@ -31,6 +32,7 @@ export class MysqlDatasource extends DataSourceWithBackend<MySQLQuery, MySQLOpti
     this.queryModel = new MySQLQueryModel({});
     const settingsData = instanceSettings.jsonData || ({} as MySQLOptions);
     this.interval = settingsData.timeInterval || '1m';
+    this.parameterizeVariables = settingsData.parameterizeVariables ?? false;
   }
 
   interpolateVariable = (value: string | string[] | number, variable: any) => {
@@ -78,6 +80,17 @@ export class MysqlDatasource extends DataSourceWithBackend<MySQLQuery, MySQLOpti
 
   applyTemplateVariables(target: MySQLQuery, scopedVars: ScopedVars): Record<string, any> {
     const queryModel = new MySQLQueryModel(target, this.templateSrv, scopedVars);
+    if (this.parameterizeVariables) {
+      // variables are bound as query parameters by the backend
+      const rawSql = queryModel.render(false);
+      return {
+        refId: target.refId,
+        datasource: this.getRef(),
+        rawSql,
+        format: target.format,
+        variables: getVariableValues(rawSql, scopedVars, this.templateSrv),
+      };
+    }
     return {
       refId: target.refId,
       datasource: this.getRef(),
@@ -9,6 +9,9 @@ export interface MysqlQueryForInterpolation {
 
 export interface MySQLOptions extends DataSourceJsonData {
   timeInterval: string;
+  parameterizeVariables?: boolean;
+  rowLimit?: number;
+  rowBytesLimit?: number;
 }
 
 export type ResultFormat = 'time_series' | 'table';
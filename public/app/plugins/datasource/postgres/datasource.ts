@@ -10,7 +10,7 @@ import { getTemplateSrv, TemplateSrv } from 'app/features/templating/template_sr
 import { getTimeSrv, TimeSrv } from 'app/features/dashboard/services/TimeSrv';
 //Types
 import { PostgresOptions, PostgresQuery, PostgresQueryForInterpolation } from './types';
-import { getSearchFilterScopedVar } from '../../../features/variables/utils';
+import { getSearchFilterScopedVar, getVariableValues } from '../../../features/variables/utils';
 import { toTestingStatus } from '@grafana/runtime/src/utils/queryResponse';
 
 export class PostgresDatasource extends DataSourceWithBackend<PostgresQuery, PostgresOptions> {
@@ -20,6 +20,7 @@ export class PostgresDatasource extends DataSourceWithBackend<PostgresQuery, Pos
   responseParser: ResponseParser;
   queryModel: PostgresQueryModel;
   interval: string;
+  parameterizeVariables: boolean;
 
   constructor(
     instanceSettings: DataSourceInstanceSettings<PostgresOptions>,
@@ -34,6 +35,7 @@ export class PostgresDatasource extends DataSourceWithBackend<PostgresQuery, Pos
     this.queryModel = new PostgresQueryModel({});
     const settingsData = instanceSettings.jsonData || ({} as PostgresOptions);
     this.interval = settingsData.timeInterval || '1m';
+    this.parameterizeVariables = settingsData.parameterizeVariables ?? false;
   }
 
   interpolateVariable = (value: string | string[], variable: { multi: any; includeAll: any }) => {
@@ -80,6 +82,17 @@ export class PostgresDatasource extends DataSourceWithBackend<PostgresQuery, Pos
 
   applyTemplateVariables(target: PostgresQuery, scopedVars: ScopedVars): Record<string, any> {
     const queryModel = new PostgresQueryModel(target, this.templateSrv, scopedVars);
+    if (this.parameterizeVariables) {
+      // variables are bound as query parameters by the backend
+      const rawSql = queryModel.render(false);
+      return {
+        refId: target.refId,
+        datasource: this.getRef(),
+        rawSql,
+        format: target.format,
+        variables: getVariableValues(rawSql, scopedVars, this.templateSrv),
+      };
+    }
     return {
       refId: target.refId,
       datasource: this.getRef(),
@@ -10,6 +10,9 @@ export interface PostgresQueryForInterpolation {
 
 export interface PostgresOptions extends DataSourceJsonData {
   timeInterval: string;
+  parameterizeVariables?: boolean;
+  rowLimit?: number;
+  rowBytesLimit?: number;
 }
 
 export type ResultFormat = 'time_series' | 'table';